@@ -1,11 +1,11 @@
 parameter: {
 	// +usage=Specify the image hub of velaux, eg. "acr.kubevela.net"
 	repo?: string
-	// +usage=Specify the database type, current support KubeAPI(default) and MongoDB.
-	dbType: *"kubeapi" | "mongodb"
+	// +usage=Specify the database type, current support KubeAPI(default), MongoDB and MySQL.
+	dbType: *"kubeapi" | "mongodb" | "mysql"
 	// +usage=Specify the database name, for the kubeapi db type, it represents namespace.
 	database?: string
-	// +usage=Specify the MongoDB URL. it only enabled where DB type is MongoDB.
+	// +usage=Specify the MongoDB URL or the MySQL DSN without the database. it only enabled where DB type is MongoDB or MySQL.
 	dbURL?: string
 	// +usage=Specify the domain, if set, ingress will be created if the gateway driver is nginx.
 	domain?: string
@@ -25,4 +25,6 @@ parameter: {
 	nodePort: *30000 | int
 	// +usage=Enable impersonation means impersonating the login user to request the KubeAPI.
 	enableImpersonation: true | *false
+	// +usage=Reconcile the VelaUXPolicy resources into the roles, the permissions and the project members of VelaUX.
+	enablePolicyBundles: true | *false
 }
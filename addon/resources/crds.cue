@@ -0,0 +1,88 @@
+package main
+
+crds: {
+	type: "k8s-objects"
+	name: "velaux-crds"
+	properties: objects: [
+		{
+			apiVersion: "apiextensions.k8s.io/v1"
+			kind:       "CustomResourceDefinition"
+			metadata: name: "velauxpolicies.velaux.oam.dev"
+			spec: {
+				group: "velaux.oam.dev"
+				scope: "Cluster"
+				names: {
+					kind:     "VelaUXPolicy"
+					listKind: "VelaUXPolicyList"
+					plural:   "velauxpolicies"
+					singular: "velauxpolicy"
+				}
+				versions: [{
+					name:    "v1alpha1"
+					served:  true
+					storage: true
+					subresources: status: {}
+					schema: openAPIV3Schema: {
+						type: "object"
+						properties: {
+							spec: {
+								type: "object"
+								properties: {
+									permissions: {
+										type: "array"
+										items: {
+											type: "object"
+											required: ["name", "resources", "actions"]
+											properties: {
+												name: type:    "string"
+												alias: type:   "string"
+												project: type: "string"
+												resources: {type: "array", items: type: "string"}
+												actions: {type: "array", items: type: "string"}
+												effect: {type: "string", enum: ["Allow", "Deny"]}
+											}
+										}
+									}
+									roles: {
+										type: "array"
+										items: {
+											type: "object"
+											required: ["name", "permissions"]
+											properties: {
+												name: type:    "string"
+												alias: type:   "string"
+												project: type: "string"
+												permissions: {type: "array", items: type: "string"}
+												projects: {type: "array", items: type: "string"}
+												projectSelector: {type: "object", additionalProperties: type: "string"}
+											}
+										}
+									}
+									bindings: {
+										type: "array"
+										items: {
+											type: "object"
+											required: ["project", "user", "roles"]
+											properties: {
+												project: type: "string"
+												user: type:    "string"
+												roles: {type: "array", items: type: "string"}
+											}
+										}
+									}
+								}
+							}
+							status: {
+								type: "object"
+								properties: {
+									observedGeneration: {type: "integer", format: "int64"}
+									message: type: "string"
+								}
+							}
+						}
+					}
+				}]
+			}
+		},
+	]
+}
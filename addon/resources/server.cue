@@ -14,6 +14,10 @@ enableImpersonation: *[ if parameter["enableImpersonation"] {
 	"--feature-gates=EnableImpersonation=true"
 }] | []
 
+enablePolicyBundles: *[ if parameter["enablePolicyBundles"] {
+	"--enable-policy-bundles=true"
+}] | []
+
 _nginxTrait: *[
 		if parameter["domain"] != _|_ && parameter["gatewayDriver"] == "nginx" {
 		{
@@ -71,7 +75,7 @@ server: {
 			exposeType: parameter["serviceType"]
 		}
 
-		cmd: ["apiserver", "--datastore-type=" + parameter["dbType"]] + database + dbURL + enableImpersonation
+		cmd: ["apiserver", "--datastore-type=" + parameter["dbType"]] + database + dbURL + enableImpersonation + enablePolicyBundles
 		ports: [
 			{
 				port:     8000
@@ -82,8 +86,23 @@ server: {
 				}
 			},
 		]
+		livenessProbe: {
+			httpGet: {
+				path: "/healthz"
+				port: 8000
+			}
+			initialDelaySeconds: 10
+			timeoutSeconds:      5
+		}
+		readinessProbe: {
+			httpGet: {
+				path: "/readyz"
+				port: 8000
+			}
+			timeoutSeconds: 5
+		}
 	}
-	dependsOn: ["velaux-additional-privileges"]
+	dependsOn: ["velaux-additional-privileges", "velaux-crds"]
 	traits: [
 		{
 			type: "service-account"
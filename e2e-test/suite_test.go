@@ -105,6 +105,10 @@ var _ = BeforeSuite(func() {
 				err = json.NewDecoder(resp.Body).Decode(loginResp)
 				Expect(err).Should(BeNil())
 				token = "Bearer " + loginResp.AccessToken
+				if loginResp.User != nil && loginResp.User.MustChangePassword {
+					res := put("/users/me/password", apisv1.ChangePasswordRequest{OldPassword: password, NewPassword: "VelaUX54321"})
+					Expect(res.StatusCode).Should(Equal(200))
+				}
 				var req = apisv1.CreateProjectRequest{
 					Name:        appProject,
 					Description: "test project",
@@ -7,12 +7,12 @@ import {
 } from './productionLink';
 import { post, get, put } from './request';
 
-export function loginSSO(params: { code: string }) {
+export function loginSSO(params: { code: string; consentVersion?: number }) {
   const url = authenticationLogin;
   return post(url, { ...params }, true).then((res) => res);
 }
 
-export function loginLocal(params: { username: string; password: string }) {
+export function loginLocal(params: { username: string; password: string; consentVersion?: number }) {
   const url = authenticationLogin;
   return post(url, { ...params }, true).then((res) => res);
 }
@@ -36,6 +36,13 @@ export interface ApplicationBase {
   readOnly?: boolean;
   icon?: string;
   labels?: Record<string, string>;
+  ownership?: Ownership;
+}
+
+export interface Ownership {
+  team?: string;
+  onCallURL?: string;
+  slackChannel?: string;
 }
 
 export interface DefinitionDetail {
@@ -1,4 +1,4 @@
-import type { Condition, InputItem, OutputItem, WorkflowStepStatus } from './application';
+import type { Condition, InputItem, OutputItem, Ownership, WorkflowStepStatus } from './application';
 import type { NameAlias } from './env';
 
 export interface CreatePipelineRequest {
@@ -18,6 +18,7 @@ interface PipelineMeta {
   description?: string;
   project: NameAlias;
   createTime?: string;
+  ownership?: Ownership;
 }
 
 export interface PipelineListItem extends PipelineMeta {
@@ -33,6 +33,13 @@ export interface UpdateSystemInfo {
   }>;
 }
 
+export interface LoginBanner {
+  title?: string;
+  content: string;
+  requireConsent: boolean;
+  version: number;
+}
+
 export interface DexConfig {
   clientID: string;
   clientSecret: string;
@@ -10,6 +10,7 @@ export type User = {
   enable?: boolean;
   password?: string;
   disabled?: boolean;
+  avatarURL?: string;
   roles?: NameAlias[];
 };
 
@@ -20,6 +21,8 @@ export interface LoginUserInfo {
   createTime?: string;
   lastLoginTime?: string;
   disabled?: boolean;
+  mustChangePassword?: boolean;
+  avatarURL?: string;
   projects: Project[];
   platformPermissions?: PermissionBase[];
   projectPermissions?: Record<string, PermissionBase[]>;
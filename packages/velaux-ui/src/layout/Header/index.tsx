@@ -223,7 +223,7 @@ class Header extends Component<Props, State> {
   isEditPlatForm = () => {
     const { userInfo } = this.props;
     const isAdminUser = isAdminUserCheck(userInfo);
-    if (isAdminUser && userInfo && !userInfo.email) {
+    if (isAdminUser && userInfo && (!userInfo.email || userInfo.mustChangePassword)) {
       this.setState({
         isEditAdminUser: true,
       });
@@ -33,7 +33,9 @@ export default class CallBackPage extends React.Component<Props> {
   };
 
   onLogonSSO = (code: any) => {
-    loginSSO({ code })
+    const consentVersion = Number(sessionStorage.getItem('consentVersion')) || undefined;
+    sessionStorage.removeItem('consentVersion');
+    loginSSO({ code, consentVersion })
       .then((res: any) => {
         if (res && res.accessToken) {
           localStorage.setItem('token', res.accessToken);
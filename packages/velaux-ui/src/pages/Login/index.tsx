@@ -1,4 +1,4 @@
-import { Card, Button, Input, Form, Field, Icon, Grid } from '@alifd/next';
+import { Card, Button, Input, Form, Field, Icon, Grid, Checkbox } from '@alifd/next';
 import React, { Component, Fragment } from 'react';
 
 import { getDexConfig, loginLocal, getLoginType } from '../../api/authentication';
@@ -9,7 +9,7 @@ import SwitchLanguage from '../../components/SwitchButton/index';
 import Translation from '../../components/Translation';
 import i18n from '../../i18n';
 import './index.less';
-import type { DexConfig } from '../../interface/system';
+import type { DexConfig, LoginBanner } from '../../interface/system';
 import { checkName, checkUserPassword } from '../../utils/common';
 import { AiOutlineQuestionCircle } from 'react-icons/ai';
 
@@ -30,6 +30,8 @@ type State = {
   loginType: string;
   loginErrorMessage: string;
   loginLoading: boolean;
+  loginBanner?: LoginBanner;
+  consent: boolean;
 };
 export default class LoginPage extends Component<Props, State> {
   field: Field;
@@ -40,6 +42,7 @@ export default class LoginPage extends Component<Props, State> {
       loginType: '',
       loginErrorMessage: '',
       loginLoading: false,
+      consent: false,
     };
   }
   componentDidMount() {
@@ -52,10 +55,12 @@ export default class LoginPage extends Component<Props, State> {
           this.setState(
             {
               loginType: res.loginType,
+              loginBanner: res.loginBanner,
             },
             () => {
               const { loginType } = this.state;
-              if (loginType === 'dex') {
+              // the users are redirected to dex after acknowledging the notice
+              if (loginType === 'dex' && !this.consentRequired()) {
                 this.ontDexConfig();
               }
             }
@@ -86,11 +91,17 @@ export default class LoginPage extends Component<Props, State> {
       if (error) {
         return;
       }
+      if (this.consentRequired() && !this.state.consent) {
+        this.setState({ loginErrorMessage: 'Please acknowledge the notice before signing in' });
+        return;
+      }
       this.setState({ loginLoading: true, loginErrorMessage: '' });
       const { username, password } = values;
+      const { loginBanner } = this.state;
       const params = {
         username: username,
         password,
+        consentVersion: this.consentRequired() ? loginBanner?.version : undefined,
       };
       loginLocal(params)
         .then((res: any) => {
@@ -114,11 +125,44 @@ export default class LoginPage extends Component<Props, State> {
         });
     });
   };
+  consentRequired = () => {
+    const { loginBanner } = this.state;
+    return !!(loginBanner && loginBanner.content && loginBanner.requireConsent);
+  };
+  onDexConsent = () => {
+    const { loginBanner } = this.state;
+    if (!this.state.consent) {
+      this.setState({ loginErrorMessage: 'Please acknowledge the notice before signing in' });
+      return;
+    }
+    // the callback page sends the acknowledged version with the code
+    sessionStorage.setItem('consentVersion', `${loginBanner?.version}`);
+    this.ontDexConfig();
+  };
+  renderBanner = () => {
+    const { loginBanner, consent } = this.state;
+    if (!loginBanner || !loginBanner.content) {
+      return null;
+    }
+    return (
+      <div className="login-banner">
+        <If condition={loginBanner.title}>
+          <h4>{loginBanner.title}</h4>
+        </If>
+        <div className="login-banner-content">{loginBanner.content}</div>
+        <If condition={loginBanner.requireConsent}>
+          <Checkbox checked={consent} onChange={(checked: boolean) => this.setState({ consent: checked })}>
+            <Translation>I have read and acknowledge the notice</Translation>
+          </Checkbox>
+        </If>
+      </div>
+    );
+  };
   onGetDexCode = () => {
     if (this.state.dexConfig) {
       const { clientID, issuer, redirectURL } = this.state.dexConfig;
       const newRedirectURl = encodeURIComponent(redirectURL);
-      const dexClientURL = `${issuer}/auth?client_id=${clientID}&redirect_uri=${newRedirectURl}&response_type=code&scope=openid+profile+email+offline_access&state=velaux`;
+      const dexClientURL = `${issuer}/auth?client_id=${clientID}&redirect_uri=${newRedirectURl}&response_type=code&scope=openid+profile+email+groups+offline_access&state=velaux`;
       window.location.href = dexClientURL;
     }
   };
@@ -159,7 +203,21 @@ export default class LoginPage extends Component<Props, State> {
         <div className="full">
           <div className="login-wrapper">
             <If condition={loginType === 'dex'}>
-              <div />
+              <If condition={this.consentRequired()}>
+                <div className="login-card-wrapper">
+                  <Card contentHeight={'auto'}>
+                    {this.renderBanner()}
+                    <Button type="primary" onClick={this.onDexConsent}>
+                      <Translation>Continue</Translation>
+                    </Button>
+                    <If condition={loginErrorMessage}>
+                      <div className="logo-error-wrapper">
+                        <Icon type="warning1" /> <Translation>{loginErrorMessage}</Translation>
+                      </div>
+                    </If>
+                  </Card>
+                </div>
+              </If>
             </If>
             <If condition={loginType === 'local'}>
               <div className="login-card-wrapper">
@@ -170,6 +228,7 @@ export default class LoginPage extends Component<Props, State> {
                   <h3 className="login-title-description">
                     <Translation>Make shipping applications more enjoyable</Translation>
                   </h3>
+                  {this.renderBanner()}
                   <Form
                     onSubmitCapture={(e) => {
                       e.preventDefault();
@@ -75,7 +75,7 @@ class ProjectRoles extends Component<Props, State> {
     const param = { projectName };
     getProjectPermissions(param).then((res) => {
       this.setState({
-        projectPermissions: (res && res) || [],
+        projectPermissions: (res && res.permissions) || [],
       });
     });
   };
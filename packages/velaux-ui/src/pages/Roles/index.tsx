@@ -69,7 +69,7 @@ class Roles extends Component<Props, State> {
   listPermissions = async () => {
     getPlatformPermissions().then((res) => {
       this.setState({
-        permissions: (res && res) || [],
+        permissions: (res && res.permissions) || [],
       });
     });
   };
@@ -18,13 +18,25 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
 	"time"
 
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/google/uuid"
 
+	"github.com/kubevela/velaux/pkg/server/infrastructure/cloudevent"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/cache"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/encryption"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/email"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/searchindex"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/securityevent"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/slack"
 )
 
 // Config config for server
@@ -36,6 +48,10 @@ type Config struct {
 
 	// Datastore config
 	Datastore datastore.Config
+	// DatastoreCache the read-through cache of the users, the projects and the permissions
+	DatastoreCache cache.Config
+	// DatastoreEncryption the key encryption keys of the sensitive fields of the entities, such as the OAuth client secrets and the SCM tokens
+	DatastoreEncryption encryption.Config
 
 	// LeaderConfig for leader election
 	LeaderConfig leaderConfig
@@ -57,6 +73,139 @@ type Config struct {
 
 	// WorkflowVersion is the version of workflow
 	WorkflowVersion string
+
+	// ProjectQuotaWarningThreshold the percentage of the project quota to warn the users
+	ProjectQuotaWarningThreshold int
+
+	// EnableKubeRBACPropagation grants the Kubernetes privileges of the project namespaces to the project members
+	EnableKubeRBACPropagation bool
+
+	// ProjectMemberWebhook the URL to receive the changes of the project members and roles
+	ProjectMemberWebhook string
+
+	// RedactionPatterns the regular expressions of the secrets to mask in the workflow logs and step parameters
+	RedactionPatterns []string
+
+	// RedactionFields the names of the fields whose values are masked in the workflow logs and step parameters
+	RedactionFields []string
+
+	// ServiceCatalogApproval requires another user to approve the service instances before they are provisioned
+	ServiceCatalogApproval bool
+
+	// RBACBootstrapFile the YAML file of the users, projects, permissions and roles to reconcile at the startup
+	RBACBootstrapFile string
+
+	// RBACBootstrapConfigMap the ConfigMap that contains the RBAC bootstrap file, in the format of namespace/name
+	RBACBootstrapConfigMap string
+
+	// EnablePolicyBundles reconciles the VelaUXPolicy resources into the roles, the permissions and the project members
+	EnablePolicyBundles bool
+
+	// StepRegressionThreshold the percentage that a pipeline step could be slower than its baseline before it is flagged as a regression
+	StepRegressionThreshold int
+
+	// LoginMaxFailures the count of the failed login attempts before the user or the client IP is locked, 0 means never
+	LoginMaxFailures int
+
+	// LoginLockoutDuration how long the user or the client IP is locked
+	LoginLockoutDuration time.Duration
+
+	// TrustedProxies the CIDRs of the reverse proxies whose X-Forwarded-For headers are trusted to get the client IP
+	TrustedProxies []string
+
+	// Email the SMTP server to send the emails, such as the password reset token
+	Email email.Config
+
+	// Slack the Slack app to send the notifications to the Slack handles of the users
+	Slack slack.Config
+
+	// LogStore the object storage to archive the large logs of the workflow and pipeline steps
+	LogStore logstore.Config
+
+	// EnableBootstrapToken initializes the admin user with a one-time token printed in the log instead of the default password
+	EnableBootstrapToken bool
+
+	// BootstrapTokenSecret the Secret that contains the bootstrap token, in the format of namespace/name
+	BootstrapTokenSecret string
+
+	// RandomAdminPassword initializes the admin user with a random password printed in the log instead of the default password
+	RandomAdminPassword bool
+
+	// LintRuleSeverities overrides the severities of the lint rules of the application components
+	LintRuleSeverities map[string]string
+
+	// PermissionSnapshotInterval how often the effective permissions of the users are snapshotted
+	PermissionSnapshotInterval time.Duration
+
+	// UserDeactivationNotice how long before the scheduled deactivation the user and the project admins are notified
+	UserDeactivationNotice time.Duration
+
+	// SecurityEvents the webhook and syslog targets to stream the login and security events to
+	SecurityEvents securityevent.Config
+
+	// SyncExternalApplications imports the applications created outside VelaUX, such as by kubectl or the GitOps tools
+	SyncExternalApplications bool
+	// ExternalApplicationNamespaces only the applications in the namespaces are imported, all namespaces if it is empty
+	ExternalApplicationNamespaces []string
+	// ExternalApplicationsReadOnly the imported applications could only be updated by their sources
+	ExternalApplicationsReadOnly bool
+	// SyncExcludeNamespaces the external applications in the namespaces are not imported
+	SyncExcludeNamespaces []string
+	// SyncIncludeClusters only the external applications of the clusters are imported, the hub cluster is named local
+	SyncIncludeClusters []string
+	// SyncExcludeClusters the external applications of the clusters are not imported
+	SyncExcludeClusters []string
+	// SyncLabelSelector only the external applications matching the selector are imported
+	SyncLabelSelector string
+	// SyncManagedClusters imports the applications and their workflow records from the managed clusters as well as the hub cluster
+	SyncManagedClusters bool
+
+	// SyncEvents the sinks to publish the deploys and the workflow results detected by the sync workers to as the CloudEvents
+	SyncEvents cloudevent.Config
+
+	// WebhookSignatureTolerance the max difference between the timestamp of a signed trigger delivery and the server time
+	WebhookSignatureTolerance time.Duration
+
+	// EnableGravatar uses the Gravatar of the email as the avatar if the user has not uploaded one
+	EnableGravatar bool
+
+	// LoginHistoryRetention how long the login records of the users are kept
+	LoginHistoryRetention time.Duration
+
+	// TrashRetention how long the deleted applications, environments, pipelines and roles are kept in the trash, 0 disables the trash
+	TrashRetention time.Duration
+
+	// SpecAuditRetention how long the spec audits are kept, 0 keeps them forever
+	SpecAuditRetention time.Duration
+
+	// StepLogRetention how long the archived step logs are kept, 0 keeps them forever
+	StepLogRetention time.Duration
+	// CollectStepLogs archives the logs of the workflow steps once the workflow finishes, otherwise they are archived
+	// when they are viewed
+	CollectStepLogs bool
+
+	// SearchIndex the OpenSearch or Elasticsearch cluster to index the audits and the step logs
+	SearchIndex searchindex.Config
+	// SearchIndexInterval how often the new audits and step logs are indexed
+	SearchIndexInterval time.Duration
+
+	// MigrationTargetVersion the version of the datastore migrations to migrate to at the start, -1 means the latest version
+	MigrationTargetVersion int
+
+	// WarmUpResyncQPS how many applications and workflow records are synced per second after the restart, 0 disables the throttling
+	WarmUpResyncQPS float64
+
+	// WorkflowRecordResyncInterval how often all unfinished workflow records are synced, the changes of the applications are synced when they are watched
+	WorkflowRecordResyncInterval time.Duration
+
+	// SyncWorkers how many applications are synced at the same time, by the application sync and by the workflow record sync each
+	SyncWorkers int
+
+	// OrphanGCPolicy what to do with the records of the applications and the pipeline runs deleted directly in the cluster,
+	// "trash" moves the synced applications to the trash, "purge" deletes them permanently and "disabled" keeps them
+	OrphanGCPolicy string
+	// OrphanGCGracePeriod how long the records must stay orphaned before they are collected
+	OrphanGCGracePeriod time.Duration
 }
 
 type leaderConfig struct {
@@ -75,16 +224,50 @@ func NewConfig() *Config {
 			Database: "kubevela",
 			URL:      "",
 		},
+		DatastoreCache: cache.Config{
+			Tables: cache.DefaultTables,
+			TTL:    time.Second * 30,
+		},
+		DatastoreEncryption: encryption.Config{
+			SecretNamespace: "vela-system",
+			SecretName:      "velaux-encryption-keys",
+			SecretKey:       "key",
+		},
 		LeaderConfig: leaderConfig{
 			ID:       uuid.New().String(),
 			LockName: "apiserver-lock",
 			Duration: time.Second * 5,
 		},
-		AddonCacheTime:          time.Minute * 10,
-		DisableStatisticCronJob: false,
-		PprofAddr:               "",
-		KubeQPS:                 100,
-		KubeBurst:               300,
+		AddonCacheTime:               time.Minute * 10,
+		DisableStatisticCronJob:      false,
+		PprofAddr:                    "",
+		KubeQPS:                      100,
+		KubeBurst:                    300,
+		ProjectQuotaWarningThreshold: 80,
+		StepRegressionThreshold:      50,
+		LoginMaxFailures:             5,
+		LoginLockoutDuration:         time.Minute * 15,
+		PermissionSnapshotInterval:   time.Hour,
+		UserDeactivationNotice:       time.Hour * 72,
+		SyncExternalApplications:     true,
+		WebhookSignatureTolerance:    time.Minute * 5,
+		EnableGravatar:               true,
+		CollectStepLogs:              true,
+		LoginHistoryRetention:        time.Hour * 24 * 90,
+		TrashRetention:               time.Hour * 24 * 7,
+		SearchIndexInterval:          time.Minute,
+		MigrationTargetVersion:       -1,
+		WarmUpResyncQPS:              10,
+		WorkflowRecordResyncInterval: time.Minute * 5,
+		SyncWorkers:                  4,
+		OrphanGCPolicy:               "trash",
+		OrphanGCGracePeriod:          time.Hour,
+		LogStore: logstore.Config{
+			Threshold: logstore.DefaultThreshold,
+		},
+		SearchIndex: searchindex.Config{
+			IndexPrefix: searchindex.DefaultIndexPrefix,
+		},
 	}
 }
 
@@ -92,10 +275,180 @@ func NewConfig() *Config {
 func (s *Config) Validate() []error {
 	var errs []error
 
-	if s.Datastore.Type != "mongodb" && s.Datastore.Type != "kubeapi" {
+	if s.Datastore.Type != "mongodb" && s.Datastore.Type != "mysql" && s.Datastore.Type != "kubeapi" && s.Datastore.Type != "sqlite" {
 		errs = append(errs, fmt.Errorf("not support datastore type %s", s.Datastore.Type))
 	}
 
+	if s.Datastore.Shards < 0 {
+		errs = append(errs, fmt.Errorf("the datastore shards must not be negative, got %d", s.Datastore.Shards))
+	}
+
+	tenants := map[string]bool{}
+	for _, tenant := range s.Datastore.Tenants {
+		if !datastore.ValidTenant(tenant) {
+			errs = append(errs, fmt.Errorf("the datastore tenant %s must be a lowercase DNS label of at most 32 characters", tenant))
+		}
+		if tenants[tenant] {
+			errs = append(errs, fmt.Errorf("the datastore tenant %s is duplicated", tenant))
+		}
+		tenants[tenant] = true
+	}
+
+	switch s.DatastoreCache.Type {
+	case "", cache.TypeMemory:
+	case cache.TypeRedis:
+		if s.DatastoreCache.RedisAddr == "" {
+			errs = append(errs, fmt.Errorf("the redis address is required by the redis cache"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("not support cache type %s", s.DatastoreCache.Type))
+	}
+	if s.DatastoreCache.Type != "" {
+		if s.DatastoreCache.TTL <= 0 {
+			errs = append(errs, fmt.Errorf("the TTL of the datastore cache must be positive, got %s", s.DatastoreCache.TTL))
+		} else if _, err := cache.ParseTables(s.DatastoreCache.Tables, s.DatastoreCache.TTL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch s.DatastoreEncryption.Provider {
+	case "":
+	case encryption.ProviderSecret:
+		if s.DatastoreEncryption.SecretName == "" || s.DatastoreEncryption.SecretKey == "" {
+			errs = append(errs, fmt.Errorf("the secret name and the key are required by the secret encryption provider"))
+		}
+	case encryption.ProviderKMS:
+		if s.DatastoreEncryption.KMSEndpoint == "" {
+			errs = append(errs, fmt.Errorf("the endpoint of the KMS plugin is required by the kms encryption provider"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("not support encryption provider %s", s.DatastoreEncryption.Provider))
+	}
+
+	if s.ProjectQuotaWarningThreshold <= 0 || s.ProjectQuotaWarningThreshold > 100 {
+		errs = append(errs, fmt.Errorf("the project quota warning threshold must be in (0, 100], got %d", s.ProjectQuotaWarningThreshold))
+	}
+
+	if s.ProjectMemberWebhook != "" {
+		if u, err := url.Parse(s.ProjectMemberWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("the project member webhook must be a http or https URL, got %s", s.ProjectMemberWebhook))
+		}
+	}
+
+	for _, pattern := range s.RedactionPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid redaction pattern %s: %w", pattern, err))
+		}
+	}
+
+	if s.RBACBootstrapFile != "" && s.RBACBootstrapConfigMap != "" {
+		errs = append(errs, fmt.Errorf("only one of the RBAC bootstrap file and ConfigMap could be set"))
+	}
+
+	if s.StepRegressionThreshold <= 0 {
+		errs = append(errs, fmt.Errorf("the step regression threshold must be positive, got %d", s.StepRegressionThreshold))
+	}
+
+	if s.LoginMaxFailures < 0 {
+		errs = append(errs, fmt.Errorf("the login max failures must not be negative, got %d", s.LoginMaxFailures))
+	}
+
+	if s.LoginMaxFailures > 0 && s.LoginLockoutDuration <= 0 {
+		errs = append(errs, fmt.Errorf("the login lockout duration must be positive, got %s", s.LoginLockoutDuration))
+	}
+
+	if s.WebhookSignatureTolerance <= 0 {
+		errs = append(errs, fmt.Errorf("the webhook signature tolerance must be positive, got %s", s.WebhookSignatureTolerance))
+	}
+
+	if s.LoginHistoryRetention <= 0 {
+		errs = append(errs, fmt.Errorf("the login history retention must be positive, got %s", s.LoginHistoryRetention))
+	}
+
+	if s.TrashRetention < 0 {
+		errs = append(errs, fmt.Errorf("the trash retention must not be negative, got %s", s.TrashRetention))
+	}
+
+	if s.SpecAuditRetention < 0 {
+		errs = append(errs, fmt.Errorf("the spec audit retention must not be negative, got %s", s.SpecAuditRetention))
+	}
+
+	if s.StepLogRetention < 0 {
+		errs = append(errs, fmt.Errorf("the step log retention must not be negative, got %s", s.StepLogRetention))
+	}
+
+	if s.MigrationTargetVersion < -1 {
+		errs = append(errs, fmt.Errorf("the migration target version must be -1 or a version, got %d", s.MigrationTargetVersion))
+	}
+
+	if s.WarmUpResyncQPS < 0 {
+		errs = append(errs, fmt.Errorf("the warm-up resync qps must not be negative, got %v", s.WarmUpResyncQPS))
+	}
+
+	if s.WorkflowRecordResyncInterval <= 0 {
+		errs = append(errs, fmt.Errorf("the workflow record resync interval must be positive, got %s", s.WorkflowRecordResyncInterval))
+	}
+
+	if s.SyncWorkers <= 0 {
+		errs = append(errs, fmt.Errorf("the sync workers must be positive, got %d", s.SyncWorkers))
+	}
+
+	if _, err := labels.Parse(s.SyncLabelSelector); err != nil {
+		errs = append(errs, fmt.Errorf("the sync label selector is invalid: %w", err))
+	}
+
+	if s.OrphanGCPolicy != "trash" && s.OrphanGCPolicy != "purge" && s.OrphanGCPolicy != "disabled" {
+		errs = append(errs, fmt.Errorf("the orphan gc policy must be trash, purge or disabled, got %s", s.OrphanGCPolicy))
+	}
+
+	if s.OrphanGCGracePeriod <= 0 {
+		errs = append(errs, fmt.Errorf("the orphan gc grace period must be positive, got %s", s.OrphanGCGracePeriod))
+	}
+
+	if s.SearchIndexInterval <= 0 {
+		errs = append(errs, fmt.Errorf("the search index interval must be positive, got %s", s.SearchIndexInterval))
+	}
+
+	for _, cidr := range s.TrustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid trusted proxy CIDR %s: %w", cidr, err))
+		}
+	}
+
+	if s.Email.Address != "" && s.Email.From == "" {
+		errs = append(errs, fmt.Errorf("the sender address must be set when the SMTP server is configured"))
+	}
+
+	for rule, severity := range s.LintRuleSeverities {
+		if severity != "error" && severity != "warning" && severity != "info" && severity != "off" {
+			errs = append(errs, fmt.Errorf("invalid severity %s of the lint rule %s", severity, rule))
+		}
+	}
+
+	if s.LogStore.Endpoint != "" && s.LogStore.Bucket == "" {
+		errs = append(errs, fmt.Errorf("the bucket must be set when the log object storage is configured"))
+	}
+
+	if s.PermissionSnapshotInterval <= 0 {
+		errs = append(errs, fmt.Errorf("the permission snapshot interval must be positive, got %s", s.PermissionSnapshotInterval))
+	}
+
+	if s.UserDeactivationNotice < 0 {
+		errs = append(errs, fmt.Errorf("the user deactivation notice must not be negative, got %s", s.UserDeactivationNotice))
+	}
+
+	if err := s.SecurityEvents.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := s.SyncEvents.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if s.LogStore.Threshold <= 0 {
+		errs = append(errs, fmt.Errorf("the log store threshold must be positive, got %d", s.LogStore.Threshold))
+	}
+
 	return errs
 }
 
@@ -103,9 +456,23 @@ func (s *Config) Validate() []error {
 func (s *Config) AddFlags(fs *pflag.FlagSet, c *Config) {
 	fs.StringVar(&s.BindAddr, "bind-addr", c.BindAddr, "The bind address used to serve the http APIs.")
 	fs.StringVar(&s.MetricPath, "metrics-path", c.MetricPath, "The path to expose the metrics.")
-	fs.StringVar(&s.Datastore.Type, "datastore-type", c.Datastore.Type, "Metadata storage driver type, support kubeapi and mongodb")
-	fs.StringVar(&s.Datastore.Database, "datastore-database", c.Datastore.Database, "Metadata storage database name, takes effect when the storage driver is mongodb.")
-	fs.StringVar(&s.Datastore.URL, "datastore-url", c.Datastore.URL, "Metadata storage database url,takes effect when the storage driver is mongodb.")
+	fs.StringVar(&s.Datastore.Type, "datastore-type", c.Datastore.Type, "Metadata storage driver type, support kubeapi, mongodb, mysql and sqlite")
+	fs.StringVar(&s.Datastore.Database, "datastore-database", c.Datastore.Database, "Metadata storage database name, takes effect when the storage driver is mongodb, mysql or sqlite.")
+	fs.StringVar(&s.Datastore.URL, "datastore-url", c.Datastore.URL, "Metadata storage database url,takes effect when the storage driver is mongodb or mysql. The mysql url is the DSN without the database, such as user:password@tcp(127.0.0.1:3306)/. The sqlite url is the path of the database file, it is <database>.db in the working directory if empty, the sqlite driver requires the binary built with cgo.")
+	fs.IntVar(&s.Datastore.Shards, "datastore-shards", c.Datastore.Shards, "the number of the namespaces the kubeapi storage spreads the metadata across, the namespaces are <database>, <database>-shard-1 and so on. The metadata is moved to the new shards at the start when it is changed, the other replicas should be stopped before.")
+	fs.StringSliceVar(&s.Datastore.Tenants, "datastore-tenants", c.Datastore.Tenants, "the organizations hosted in the isolated partitions of the datastore, the organization of a request is set by the X-VelaUX-Org header and the requests without it use the default partition. The partitions are the namespaces <database>-<org> of kubeapi, the databases <database>_<org> of MongoDB and the tables <org>__<table> of MySQL and SQLite.")
+	fs.StringVar(&s.DatastoreCache.Type, "datastore-cache-type", c.DatastoreCache.Type, "the read-through cache of the metadata, support memory and redis, the metadata is not cached if it is empty. The memory cache of a replica only sees the writes of the other replicas after the TTL.")
+	fs.StringSliceVar(&s.DatastoreCache.Tables, "datastore-cache-tables", c.DatastoreCache.Tables, "the tables to cache, each one is <table> or <table>=<ttl> to override the default TTL, such as vela_user=10s.")
+	fs.DurationVar(&s.DatastoreCache.TTL, "datastore-cache-ttl", c.DatastoreCache.TTL, "the default time to live of the cached entities and lists.")
+	fs.IntVar(&s.DatastoreCache.Size, "datastore-cache-size", c.DatastoreCache.Size, "the max number of the entries of the memory cache.")
+	fs.StringVar(&s.DatastoreCache.RedisAddr, "datastore-cache-redis-addr", c.DatastoreCache.RedisAddr, "the address of the redis cache, such as redis:6379.")
+	fs.StringVar(&s.DatastoreCache.RedisPassword, "datastore-cache-redis-password", c.DatastoreCache.RedisPassword, "the password of the redis cache.")
+	fs.IntVar(&s.DatastoreCache.RedisDB, "datastore-cache-redis-db", c.DatastoreCache.RedisDB, "the database number of the redis cache.")
+	fs.StringVar(&s.DatastoreEncryption.Provider, "datastore-encryption-provider", c.DatastoreEncryption.Provider, "the provider of the keys to encrypt the sensitive fields of the metadata, support secret and kms, the fields are not encrypted if it is empty.")
+	fs.StringVar(&s.DatastoreEncryption.SecretNamespace, "datastore-encryption-secret-namespace", c.DatastoreEncryption.SecretNamespace, "the namespace of the secret of the encryption keys.")
+	fs.StringVar(&s.DatastoreEncryption.SecretName, "datastore-encryption-secret-name", c.DatastoreEncryption.SecretName, "the secret of the encryption keys, each key of the secret is a 32 bytes key.")
+	fs.StringVar(&s.DatastoreEncryption.SecretKey, "datastore-encryption-secret-key", c.DatastoreEncryption.SecretKey, "the key of the secret to encrypt the new values, the other keys of the secret still decrypt the existing values after the rotation.")
+	fs.StringVar(&s.DatastoreEncryption.KMSEndpoint, "datastore-encryption-kms-endpoint", c.DatastoreEncryption.KMSEndpoint, "the gRPC endpoint of the Kubernetes KMS v1 plugin, such as unix:///var/run/kms-plugin/socket.sock.")
 	fs.StringVar(&s.LeaderConfig.ID, "id", c.LeaderConfig.ID, "the holder identity name")
 	fs.StringVar(&s.LeaderConfig.LockName, "lock-name", c.LeaderConfig.LockName, "the lease lock resource name")
 	fs.DurationVar(&s.LeaderConfig.Duration, "duration", c.LeaderConfig.Duration, "the lease lock resource name")
@@ -115,4 +482,65 @@ func (s *Config) AddFlags(fs *pflag.FlagSet, c *Config) {
 	fs.Float64Var(&s.KubeQPS, "kube-api-qps", c.KubeQPS, "the qps for kube clients. Low qps may lead to low throughput. High qps may give stress to api-server.")
 	fs.IntVar(&s.KubeBurst, "kube-api-burst", c.KubeBurst, "the burst for kube clients. Recommend setting it qps*3.")
 	fs.StringVar(&s.WorkflowVersion, "workflow-version", c.WorkflowVersion, "the version of workflow to meet controller requirement.")
+	fs.IntVar(&s.ProjectQuotaWarningThreshold, "project-quota-warning-threshold", c.ProjectQuotaWarningThreshold, "the percentage of the project quota to warn the users, the quota is soft and never blocks the requests.")
+	fs.BoolVar(&s.EnableKubeRBACPropagation, "enable-kube-rbac-propagation", c.EnableKubeRBACPropagation, "project the project roles of the users to the Kubernetes RBAC, so the kubectl access of the users matches their access in VelaUX.")
+	fs.StringVar(&s.ProjectMemberWebhook, "project-member-webhook", c.ProjectMemberWebhook, "the URL to post the changes of the project members and roles to, so the external systems could track who has access to what.")
+	fs.StringSliceVar(&s.RedactionPatterns, "redaction-patterns", c.RedactionPatterns, "the regular expressions of the secrets to mask when storing and serving the workflow logs and step parameters.")
+	fs.StringSliceVar(&s.RedactionFields, "redaction-fields", c.RedactionFields, "the names of the fields whose values are masked when storing and serving the workflow logs and step parameters, such as password and token.")
+	fs.BoolVar(&s.ServiceCatalogApproval, "service-catalog-approval", c.ServiceCatalogApproval, "require another user of the project to approve the service instances requested from the catalog before they are provisioned.")
+	fs.StringVar(&s.RBACBootstrapFile, "rbac-bootstrap-file", c.RBACBootstrapFile, "the YAML file of the users, projects, permissions and roles to reconcile on every start, so the RBAC could be managed in version control.")
+	fs.StringVar(&s.RBACBootstrapConfigMap, "rbac-bootstrap-configmap", c.RBACBootstrapConfigMap, "the ConfigMap(namespace/name) whose rbac.yaml key is the RBAC bootstrap file, the namespace defaults to vela-system.")
+	fs.BoolVar(&s.EnablePolicyBundles, "enable-policy-bundles", c.EnablePolicyBundles, "watch the VelaUXPolicy resources and reconcile their roles, permissions and project bindings, so the RBAC of VelaUX could be managed by GitOps together with the cluster RBAC.")
+	fs.IntVar(&s.StepRegressionThreshold, "step-regression-threshold", c.StepRegressionThreshold, "the percentage that a pipeline step could be slower than the median of its recent executions before it is flagged as a regression.")
+	fs.IntVar(&s.LoginMaxFailures, "login-max-failures", c.LoginMaxFailures, "the count of the failed local login attempts before the user or the client IP is locked, set it to 0 to disable the lockout.")
+	fs.DurationVar(&s.LoginLockoutDuration, "login-lockout-duration", c.LoginLockoutDuration, "how long the user or the client IP is locked after too many failed login attempts.")
+	fs.StringSliceVar(&s.TrustedProxies, "trusted-proxies", c.TrustedProxies, "the CIDRs of the reverse proxies whose X-Forwarded-For headers are trusted to get the client IP for the login lockout and the rate limits, the peer address is used if it is empty.")
+	fs.StringVar(&s.Email.Address, "smtp-address", c.Email.Address, "the address(host:port) of the SMTP server to send the emails, the self-service password reset is disabled if it is empty.")
+	fs.StringVar(&s.Email.Username, "smtp-username", c.Email.Username, "the username to authenticate with the SMTP server.")
+	fs.StringVar(&s.Email.Password, "smtp-password", c.Email.Password, "the password to authenticate with the SMTP server.")
+	fs.StringVar(&s.Email.From, "smtp-from", c.Email.From, "the sender address of the emails.")
+	fs.StringVar(&s.Slack.Token, "slack-bot-token", c.Slack.Token, "the bot token(with the chat:write scope) of the Slack app to send the notifications to the Slack handles of the users, nothing is sent in the offline mode.")
+	fs.StringVar(&s.LogStore.Endpoint, "log-store-endpoint", c.LogStore.Endpoint, "the URL of the S3 compatible object storage(such as MinIO) to archive the large step logs, the large logs are not archived if it is empty.")
+	fs.StringVar(&s.LogStore.Bucket, "log-store-bucket", c.LogStore.Bucket, "the bucket to store the step logs.")
+	fs.StringVar(&s.LogStore.Region, "log-store-region", c.LogStore.Region, "the region of the bucket, defaults to us-east-1.")
+	fs.StringVar(&s.LogStore.AccessKey, "log-store-access-key", c.LogStore.AccessKey, "the access key of the object storage.")
+	fs.StringVar(&s.LogStore.SecretKey, "log-store-secret-key", c.LogStore.SecretKey, "the secret key of the object storage.")
+	fs.IntVar(&s.LogStore.Threshold, "log-store-threshold", c.LogStore.Threshold, "the logs of the finished steps larger than the threshold(in bytes) are stored in the object storage, the smaller ones are stored in the datastore.")
+	fs.BoolVar(&s.EnableBootstrapToken, "enable-bootstrap-token", c.EnableBootstrapToken, "create the admin user disabled and print a one-time bootstrap token in the log on the first start, the token is used to set the admin password and the SSO by the API.")
+	fs.BoolVar(&s.RandomAdminPassword, "random-admin-password", c.RandomAdminPassword, "initialize the admin user with a random password printed in the log on the first start instead of the default password, it must be changed at the first login.")
+	fs.StringVar(&s.BootstrapTokenSecret, "bootstrap-token-secret", c.BootstrapTokenSecret, "the Secret(namespace/name) whose token key is the bootstrap token, it enables the bootstrap token and the token is not printed, the namespace defaults to vela-system.")
+	fs.StringToStringVar(&s.LintRuleSeverities, "lint-rule-severities", c.LintRuleSeverities, "override the severities(error, warning, info or off) of the lint rules of the application components, such as image-latest-tag=error,missing-probes=off. The components violating the error rules could not be saved.")
+	fs.DurationVar(&s.PermissionSnapshotInterval, "permission-snapshot-interval", c.PermissionSnapshotInterval, "how often the effective permissions of the users are snapshotted for the point-in-time audits, a snapshot is stored only if the permissions of the user changed.")
+	fs.BoolVar(&s.SyncExternalApplications, "sync-external-applications", c.SyncExternalApplications, "import the applications created outside VelaUX, such as by kubectl or the GitOps tools, and keep their status updated.")
+	fs.StringSliceVar(&s.ExternalApplicationNamespaces, "external-application-namespaces", c.ExternalApplicationNamespaces, "only import the external applications in these namespaces, all namespaces are imported if it is empty.")
+	fs.BoolVar(&s.ExternalApplicationsReadOnly, "external-applications-read-only", c.ExternalApplicationsReadOnly, "mark the imported external applications as managed externally, they could not be modified in VelaUX.")
+	fs.StringSliceVar(&s.SyncExcludeNamespaces, "sync-exclude-namespaces", c.SyncExcludeNamespaces, "never import the external applications in these namespaces, such as the namespaces of the other platforms sharing the cluster.")
+	fs.StringSliceVar(&s.SyncIncludeClusters, "sync-include-clusters", c.SyncIncludeClusters, "only import the external applications of these clusters, the hub cluster is named local. All clusters are imported if it is empty.")
+	fs.StringSliceVar(&s.SyncExcludeClusters, "sync-exclude-clusters", c.SyncExcludeClusters, "never import the external applications of these clusters, the hub cluster is named local.")
+	fs.StringVar(&s.SyncLabelSelector, "sync-label-selector", c.SyncLabelSelector, "only import the external applications matching the label selector, such as team=payments. The scope set by the API replaces all the sync scope flags and --external-application-namespaces.")
+	fs.BoolVar(&s.SyncManagedClusters, "sync-managed-clusters", c.SyncManagedClusters, "import the applications and their workflow records from all managed clusters as well as the hub cluster. The applications of the managed clusters are named with the cluster suffix and could only be modified in their clusters.")
+	fs.StringSliceVar(&s.SyncEvents.Sinks, "sync-event-sinks", c.SyncEvents.Sinks, "the sinks to publish the application deploys and the workflow results to as the CloudEvents, such as https://tracker/events, nats://nats:4222/subject or kafka+http://kafka-rest-proxy:8082/topic.")
+	fs.StringVar(&s.SyncEvents.Source, "sync-event-source", c.SyncEvents.Source, "the source attribute of the published CloudEvents, it identifies this VelaUX instance. Defaults to /velaux.")
+	fs.DurationVar(&s.UserDeactivationNotice, "user-deactivation-notice", c.UserDeactivationNotice, "how long before the scheduled deactivation of a user the user and the admins of the user's projects are notified, 0 disables the notice.")
+	fs.StringSliceVar(&s.SecurityEvents.Webhooks, "security-event-webhooks", c.SecurityEvents.Webhooks, "the URLs to post the login, password and API token events to as JSON, so the security teams could stream them into the SIEM.")
+	fs.StringVar(&s.SecurityEvents.WebhookSecret, "security-event-webhook-secret", c.SecurityEvents.WebhookSecret, "the secret to sign the security event webhook requests, the HMAC-SHA256 signature is set in the X-VelaUX-Signature header.")
+	fs.BoolVar(&s.EnableGravatar, "enable-gravatar", c.EnableGravatar, "use the Gravatar of the email as the avatar if the user has not uploaded one, the browsers load the images from gravatar.com with the hashes of the emails.")
+	fs.DurationVar(&s.LoginHistoryRetention, "login-history-retention", c.LoginHistoryRetention, "how long the logins of the users are kept in the login history, the older records are purged.")
+	fs.DurationVar(&s.TrashRetention, "trash-retention", c.TrashRetention, "how long the deleted applications, environments, pipelines and roles are kept in the trash for the restore, the older ones are purged, 0 deletes them permanently.")
+	fs.DurationVar(&s.SpecAuditRetention, "spec-audit-retention", c.SpecAuditRetention, "how long the spec audits are kept, the older ones are deleted by the datastore, 0 keeps them forever.")
+	fs.DurationVar(&s.StepLogRetention, "step-log-retention", c.StepLogRetention, "how long the step logs archived in the datastore are kept, the older ones are deleted by the datastore, 0 keeps them forever. The logs put to the object storage are kept, they should be expired by the lifecycle rule of the bucket.")
+	fs.BoolVar(&s.CollectStepLogs, "collect-step-logs", c.CollectStepLogs, "archive the logs of the workflow steps once the workflow of the application finishes, so they survive the restarts of the pods. The logs are archived when they are viewed if it is false.")
+	fs.DurationVar(&s.WebhookSignatureTolerance, "webhook-signature-tolerance", c.WebhookSignatureTolerance, "the max difference between the timestamp of a signed trigger delivery and the server time, the older deliveries are rejected as the replays.")
+	fs.IntVar(&s.MigrationTargetVersion, "migration-target-version", c.MigrationTargetVersion, "the version of the datastore migrations to migrate to at the start, the applied migrations newer than it are reverted, -1 means the latest version.")
+	fs.Float64Var(&s.WarmUpResyncQPS, "warm-up-resync-qps", c.WarmUpResyncQPS, "how many applications and workflow records are synced per second after the restart, the throttling stops once the existing ones are synced, 0 disables the throttling.")
+	fs.DurationVar(&s.WorkflowRecordResyncInterval, "workflow-record-resync-interval", c.WorkflowRecordResyncInterval, "how often all unfinished workflow records are synced in case a change of the applications is missed, the watched changes are synced within seconds.")
+	fs.IntVar(&s.SyncWorkers, "sync-workers", c.SyncWorkers, "how many applications are synced from the cluster at the same time, the applications are queued by their clusters so a slow cluster does not hold up the others, and the failed ones are retried with the exponential backoff. A cluster takes all workers but one, so there are 2 workers at least.")
+	fs.StringVar(&s.OrphanGCPolicy, "orphan-gc-policy", c.OrphanGCPolicy, "what to do with the synced applications and the archived pipeline run logs whose resources are deleted directly in the cluster, trash moves the applications to the trash, purge deletes them permanently and disabled keeps them.")
+	fs.DurationVar(&s.OrphanGCGracePeriod, "orphan-gc-grace-period", c.OrphanGCGracePeriod, "how long the records must stay orphaned before they are collected, so the resources recreated shortly or missed by a lagging cache are not collected.")
+	fs.StringVar(&s.SearchIndex.Endpoint, "search-index-endpoint", c.SearchIndex.Endpoint, "the URL of the OpenSearch or Elasticsearch cluster to index the audits and the step logs, the search is disabled if it is empty.")
+	fs.StringVar(&s.SearchIndex.Username, "search-index-username", c.SearchIndex.Username, "the username to authenticate with the search cluster.")
+	fs.StringVar(&s.SearchIndex.Password, "search-index-password", c.SearchIndex.Password, "the password to authenticate with the search cluster.")
+	fs.StringVar(&s.SearchIndex.IndexPrefix, "search-index-prefix", c.SearchIndex.IndexPrefix, "the prefix of the indices, each project has its own indices named as <prefix>-<audit|log>-<project>.")
+	fs.DurationVar(&s.SearchIndexInterval, "search-index-interval", c.SearchIndexInterval, "how often the new audits and step logs are shipped to the search cluster.")
+	fs.StringVar(&s.SecurityEvents.Syslog, "security-event-syslog", c.SecurityEvents.Syslog, "the syslog server(udp://host:port or tcp://host:port) to send the security events to in the RFC 5424 format.")
 }
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "time"
+
+func init() {
+	RegisterModel(&AddonRollout{})
+}
+
+const (
+	// AddonRolloutActionEnable enables the addon on the clusters
+	AddonRolloutActionEnable = "enable"
+	// AddonRolloutActionUpgrade upgrades the enabled addon and extends it to the clusters
+	AddonRolloutActionUpgrade = "upgrade"
+)
+
+const (
+	// AddonRolloutPhasePending the cluster is waiting to be processed
+	AddonRolloutPhasePending = "pending"
+	// AddonRolloutPhaseRunning the rollout or the cluster is in progress
+	AddonRolloutPhaseRunning = "running"
+	// AddonRolloutPhaseSucceeded the addon is applied to all the clusters or the cluster
+	AddonRolloutPhaseSucceeded = "succeeded"
+	// AddonRolloutPhaseFailed some clusters of the rollout or the cluster are failed
+	AddonRolloutPhaseFailed = "failed"
+)
+
+// AddonRollout enables or upgrades an addon across the clusters one by one, the progress of each cluster is recorded
+// so the rollout could be resumed after the restart and the failed clusters could be retried.
+type AddonRollout struct {
+	BaseModel
+	Name         string                 `json:"name"`
+	Addon        string                 `json:"addon"`
+	Action       string                 `json:"action"`
+	Version      string                 `json:"version,omitempty"`
+	RegistryName string                 `json:"registryName,omitempty"`
+	Args         map[string]interface{} `json:"args,omitempty"`
+	// BaseClusters the clusters that the addon is enabled on before the rollout, they are kept
+	BaseClusters []string              `json:"baseClusters,omitempty"`
+	Clusters     []AddonRolloutCluster `json:"clusters"`
+	Phase        string                `json:"phase"`
+	Creator      string                `json:"creator,omitempty"`
+}
+
+// AddonRolloutCluster the progress of a cluster in the addon rollout
+type AddonRolloutCluster struct {
+	Name       string    `json:"name"`
+	Phase      string    `json:"phase"`
+	Message    string    `json:"message,omitempty"`
+	UpdateTime time.Time `json:"updateTime,omitempty"`
+}
+
+// TableName return custom table name
+func (a *AddonRollout) TableName() string {
+	return tableNamePrefix + "addon_rollout"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (a *AddonRollout) ShortTableName() string {
+	return "adn_rlt"
+}
+
+// PrimaryKey return custom primary key
+func (a *AddonRollout) PrimaryKey() string {
+	return a.Name
+}
+
+// Index return custom index
+func (a *AddonRollout) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if a.Name != "" {
+		index["name"] = a.Name
+	}
+	if a.Addon != "" {
+		index["addon"] = a.Addon
+	}
+	if a.Phase != "" {
+		index["phase"] = a.Phase
+	}
+	return index
+}
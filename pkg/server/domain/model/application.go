@@ -27,7 +27,7 @@ import (
 )
 
 func init() {
-	RegisterModel(&ApplicationComponent{}, &ApplicationPolicy{}, &Application{}, &ApplicationRevision{}, &ApplicationTrigger{})
+	RegisterModel(&ApplicationComponent{}, &ApplicationPolicy{}, &Application{}, &ApplicationRevision{}, &ApplicationTrigger{}, &TriggerNonce{}, &TriggerRejectedDelivery{})
 }
 
 // Application application delivery model
@@ -39,6 +39,67 @@ type Application struct {
 	Description string            `json:"description"`
 	Icon        string            `json:"icon"`
 	Labels      map[string]string `json:"labels,omitempty"`
+	// Notice the maintenance notice surfaced when deploying the application
+	Notice *ApplicationNotice `json:"notice,omitempty"`
+	// SyncedStatus the status of the application imported from the cluster, it is kept updated by the sync
+	SyncedStatus *SyncedApplicationStatus `json:"syncedStatus,omitempty"`
+	// Ownership who to contact about the application
+	Ownership *Ownership `json:"ownership,omitempty"`
+	// Conflicts the environments whose application CR diverged from the revision deployed by VelaUX, it is kept updated by the sync
+	Conflicts []ApplicationConflict `json:"conflicts,omitempty"`
+}
+
+// ApplicationConflict means the application CR in the environment is modified outside VelaUX, such as by kubectl
+type ApplicationConflict struct {
+	EnvName string `json:"envName"`
+	// Revision the version of the revision deployed by VelaUX
+	Revision   string    `json:"revision"`
+	DetectTime time.Time `json:"detectTime"`
+}
+
+// GetConflict returns the conflict in the environment, it is nil if the environment has no conflict
+func (a *Application) GetConflict(envName string) *ApplicationConflict {
+	for i := range a.Conflicts {
+		if a.Conflicts[i].EnvName == envName {
+			return &a.Conflicts[i]
+		}
+	}
+	return nil
+}
+
+// RemoveConflict removes the conflict in the environment, it returns false if the environment has no conflict
+func (a *Application) RemoveConflict(envName string) bool {
+	for i := range a.Conflicts {
+		if a.Conflicts[i].EnvName == envName {
+			a.Conflicts = append(a.Conflicts[:i], a.Conflicts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Ownership who owns an application or a pipeline, it is included in the alerts so the responders know who to contact
+type Ownership struct {
+	Team string `json:"team,omitempty"`
+	// OnCallURL the link to the on-call rotation of the team
+	OnCallURL string `json:"onCallURL,omitempty"`
+	// SlackChannel the channel name or ID, the deploy failures are posted to it if Slack is configured
+	SlackChannel string `json:"slackChannel,omitempty"`
+}
+
+const (
+	// ApplicationNoticeWarning the notice is returned by the deploy API, the deploy is not blocked
+	ApplicationNoticeWarning = "warning"
+	// ApplicationNoticeBlocking the deploy is rejected unless the notice is acknowledged
+	ApplicationNoticeBlocking = "blocking"
+)
+
+// ApplicationNotice is a notice attached to the application, such as "DB migration in progress"
+type ApplicationNotice struct {
+	Message    string    `json:"message"`
+	Level      string    `json:"level"`
+	Author     string    `json:"author"`
+	CreateTime time.Time `json:"createTime"`
 }
 
 // TableName return custom table name
@@ -71,6 +132,16 @@ func (a *Application) Index() map[string]interface{} {
 	return index
 }
 
+// Indexes return custom indexes
+func (a *Application) Indexes() []string {
+	return []string{"project"}
+}
+
+// SearchFields the fields matched by the global search
+func (a *Application) SearchFields() []string {
+	return []string{"name", "alias", "description"}
+}
+
 // GetAppNamespaceForSynced will return the namespace of synced CR
 func (a *Application) GetAppNamespaceForSynced() string {
 	if a.Labels == nil {
@@ -79,6 +150,14 @@ func (a *Application) GetAppNamespaceForSynced() string {
 	return a.Labels[LabelSyncNamespace]
 }
 
+// GetSyncedCluster will return the managed cluster of synced CR, it is empty if the CR is in the hub cluster
+func (a *Application) GetSyncedCluster() string {
+	if a.Labels == nil {
+		return ""
+	}
+	return a.Labels[LabelSyncCluster]
+}
+
 // IsSynced answer if the app is synced one
 func (a *Application) IsSynced() bool {
 	if a.Labels == nil {
@@ -92,13 +171,25 @@ func (a *Application) IsSynced() bool {
 }
 
 // IsReadOnly is readonly app
-// Only the source is inner, the app is readonly
+// The source is inner or the app is managed externally, the app is readonly
 func (a *Application) IsReadOnly() bool {
 	if a.Labels == nil {
 		return false
 	}
 	sot := a.Labels[types.LabelSourceOfTruth]
-	return sot == types.FromInner
+	return sot == types.FromInner || a.IsManagedExternally()
+}
+
+// IsManagedExternally answer if the synced app could only be updated by its source, such as kubectl or the GitOps tools
+func (a *Application) IsManagedExternally() bool {
+	return a.Labels != nil && a.Labels[LabelSyncManagedExternally] == "true"
+}
+
+// SyncedApplicationStatus the status of the synced application in the cluster
+type SyncedApplicationStatus struct {
+	Phase      string    `json:"phase"`
+	Healthy    bool      `json:"healthy"`
+	UpdateTime time.Time `json:"updateTime"`
 }
 
 // ClusterSelector cluster selector
@@ -169,9 +260,17 @@ func (a *ApplicationComponent) Index() map[string]interface{} {
 	if a.WorkloadType.Type != "" {
 		index["workflowType"] = a.WorkloadType.Type
 	}
+	if a.Creator != "" {
+		index["creator"] = a.Creator
+	}
 	return index
 }
 
+// Indexes return custom indexes
+func (a *ApplicationComponent) Indexes() []string {
+	return []string{"appPrimaryKey,-createTime"}
+}
+
 // ApplicationPolicy app policy
 type ApplicationPolicy struct {
 	BaseModel
@@ -217,9 +316,17 @@ func (a *ApplicationPolicy) Index() map[string]interface{} {
 	if a.EnvName != "" {
 		index["envName"] = a.EnvName
 	}
+	if a.Creator != "" {
+		index["creator"] = a.Creator
+	}
 	return index
 }
 
+// Indexes return custom indexes
+func (a *ApplicationPolicy) Indexes() []string {
+	return []string{"appPrimaryKey"}
+}
+
 // ApplicationTrait application trait
 type ApplicationTrait struct {
 	Alias       string      `json:"alias"`
@@ -283,6 +390,10 @@ type ApplicationRevision struct {
 	CodeInfo *CodeInfo `json:"codeInfo,omitempty"`
 	// ImageInfo is the image info of this application revision
 	ImageInfo *ImageInfo `json:"imageInfo,omitempty"`
+	// RequestID the ID of the API request that deploys the revision
+	RequestID string `json:"requestID,omitempty"`
+	// AcknowledgedNotice the blocking notice of the application acknowledged by the deploy user
+	AcknowledgedNotice string `json:"acknowledgedNotice,omitempty"`
 }
 
 // CodeInfo is the code info for webhook request
@@ -375,6 +486,11 @@ func (a *ApplicationRevision) Index() map[string]interface{} {
 	return index
 }
 
+// Indexes return custom indexes
+func (a *ApplicationRevision) Indexes() []string {
+	return []string{"appPrimaryKey,-createTime"}
+}
+
 // ApplicationTrigger is the model for trigger
 type ApplicationTrigger struct {
 	BaseModel
@@ -388,6 +504,8 @@ type ApplicationTrigger struct {
 	PayloadType   string `json:"payloadType"`
 	ComponentName string `json:"componentName"`
 	Registry      string `json:"registry,omitempty"`
+	// Secret the shared secret to sign the deliveries, the signature, the timestamp and the nonce of the deliveries are not verified if it is empty
+	Secret string `json:"secret,omitempty" encrypted:"true"`
 }
 
 const (
@@ -451,3 +569,78 @@ func (w *ApplicationTrigger) Index() map[string]interface{} {
 	}
 	return index
 }
+
+// TriggerNonce is a nonce used by a signed delivery of the trigger, the reused nonces are rejected as the replays
+type TriggerNonce struct {
+	BaseModel
+	// Key the hash of the trigger token and the nonce
+	Key   string `json:"key"`
+	Token string `json:"token"`
+}
+
+// TableName return custom table name
+func (t *TriggerNonce) TableName() string {
+	return tableNamePrefix + "trigger_nonce"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (t *TriggerNonce) ShortTableName() string {
+	return "app_tg_nonce"
+}
+
+// PrimaryKey return custom primary key
+func (t *TriggerNonce) PrimaryKey() string {
+	return t.Key
+}
+
+// Index return custom index
+func (t *TriggerNonce) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if t.Key != "" {
+		index["key"] = t.Key
+	}
+	if t.Token != "" {
+		index["token"] = t.Token
+	}
+	return index
+}
+
+// TriggerRejectedDelivery is a delivery of the trigger rejected by the signature verification or the replay protection
+type TriggerRejectedDelivery struct {
+	BaseModel
+	Name          string `json:"name"`
+	AppPrimaryKey string `json:"appPrimaryKey"`
+	Token         string `json:"token"`
+	Reason        string `json:"reason"`
+	ClientIP      string `json:"clientIP,omitempty"`
+}
+
+// TableName return custom table name
+func (t *TriggerRejectedDelivery) TableName() string {
+	return tableNamePrefix + "trigger_rejected_delivery"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (t *TriggerRejectedDelivery) ShortTableName() string {
+	return "app_tg_rjd"
+}
+
+// PrimaryKey return custom primary key
+func (t *TriggerRejectedDelivery) PrimaryKey() string {
+	return t.Name
+}
+
+// Index return custom index
+func (t *TriggerRejectedDelivery) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if t.Name != "" {
+		index["name"] = t.Name
+	}
+	if t.AppPrimaryKey != "" {
+		index["appPrimaryKey"] = t.AppPrimaryKey
+	}
+	if t.Token != "" {
+		index["token"] = t.Token
+	}
+	return index
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "time"
+
+func init() {
+	RegisterModel(&SpecAudit{})
+	RegisterModel(&SearchIndexCheckpoint{})
+}
+
+const (
+	// SpecChangeAdd the field is added
+	SpecChangeAdd = "add"
+	// SpecChangeRemove the field is removed
+	SpecChangeRemove = "remove"
+	// SpecChangeReplace the value of the field is changed
+	SpecChangeReplace = "replace"
+)
+
+// SpecAudit records an update of the spec of an application or a pipeline with the structured diff
+type SpecAudit struct {
+	BaseModel
+	Name string `json:"name"`
+	// Resource the type of the entity, application or pipeline
+	Resource string `json:"resource"`
+	Project  string `json:"project"`
+	// Entity the name of the application or the pipeline
+	Entity string `json:"entity"`
+	// SubResource the changed part of the entity, such as component/<name>, it is empty if the entity itself is changed
+	SubResource string       `json:"subResource,omitempty"`
+	Changes     []SpecChange `json:"changes"`
+	Operator    string       `json:"operator,omitempty"`
+}
+
+// SpecChange a changed field of the spec, the values are encoded as JSON
+type SpecChange struct {
+	// Path the path of the field, such as properties.ports[0].port
+	Path      string `json:"path"`
+	Operation string `json:"operation"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+}
+
+// TableName return custom table name
+func (s *SpecAudit) TableName() string {
+	return tableNamePrefix + "spec_audit"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (s *SpecAudit) ShortTableName() string {
+	return "spec_adt"
+}
+
+// PrimaryKey return custom primary key
+func (s *SpecAudit) PrimaryKey() string {
+	return s.Name
+}
+
+// Index return custom index
+func (s *SpecAudit) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if s.Name != "" {
+		index["name"] = s.Name
+	}
+	if s.Resource != "" {
+		index["resource"] = s.Resource
+	}
+	if s.Project != "" {
+		index["project"] = s.Project
+	}
+	if s.Entity != "" {
+		index["entity"] = s.Entity
+	}
+	return index
+}
+
+// Indexes return custom indexes
+func (s *SpecAudit) Indexes() []string {
+	return []string{"resource,project,entity,-createTime"}
+}
+
+// SearchIndexCheckpoint the create time of the latest record shipped to the search index, the newer records are indexed by the next run
+type SearchIndexCheckpoint struct {
+	BaseModel
+	// Kind the kind of the indexed records, audit or log
+	Kind     string    `json:"kind"`
+	LastTime time.Time `json:"lastTime"`
+}
+
+// TableName return custom table name
+func (s *SearchIndexCheckpoint) TableName() string {
+	return tableNamePrefix + "search_index_checkpoint"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (s *SearchIndexCheckpoint) ShortTableName() string {
+	return "srch_ckpt"
+}
+
+// PrimaryKey return custom primary key
+func (s *SearchIndexCheckpoint) PrimaryKey() string {
+	return s.Kind
+}
+
+// Index return custom index
+func (s *SearchIndexCheckpoint) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if s.Kind != "" {
+		index["kind"] = s.Kind
+	}
+	return index
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+func init() {
+	RegisterModel(&StatusBadge{})
+}
+
+// StatusBadge is the public badge of the health of an application or the status of a pipeline.
+// The badge is requested without the login, only the hash of its token is stored.
+type StatusBadge struct {
+	BaseModel
+	ID string `json:"id"`
+	// Resource the type of the entity, application or pipeline
+	Resource string `json:"resource"`
+	Project  string `json:"project"`
+	// Entity the name of the application or the pipeline
+	Entity string `json:"entity"`
+	// EnvName the environment of the application that the health is shown
+	EnvName   string `json:"envName,omitempty"`
+	Enabled   bool   `json:"enabled"`
+	TokenHash string `json:"tokenHash"`
+	Creator   string `json:"creator,omitempty"`
+}
+
+// TableName return custom table name
+func (s *StatusBadge) TableName() string {
+	return tableNamePrefix + "status_badge"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (s *StatusBadge) ShortTableName() string {
+	return "stbdg"
+}
+
+// PrimaryKey return custom primary key
+func (s *StatusBadge) PrimaryKey() string {
+	return s.ID
+}
+
+// Index return custom index
+func (s *StatusBadge) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if s.ID != "" {
+		index["id"] = s.ID
+	}
+	if s.Resource != "" {
+		index["resource"] = s.Resource
+	}
+	if s.Project != "" {
+		index["project"] = s.Project
+	}
+	if s.Entity != "" {
+		index["entity"] = s.Entity
+	}
+	if s.Creator != "" {
+		index["creator"] = s.Creator
+	}
+	return index
+}
@@ -45,6 +45,13 @@ const (
 	ClusterStatusUnhealthy = "Unhealthy"
 )
 
+const (
+	// ClusterProviderCAPI the provider of the clusters provisioned by the Cluster API
+	ClusterProviderCAPI = "capi"
+	// LabelCAPICluster marks the Cluster API clusters managed by VelaUX
+	LabelCAPICluster = "ux.oam.dev/capi-cluster"
+)
+
 var (
 	// LocalClusterCreatedTime create time for local cluster, set to late date in order to ensure it is sorted to first
 	LocalClusterCreatedTime = time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC)
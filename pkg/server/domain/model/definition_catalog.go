@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "time"
+
+func init() {
+	RegisterModel(&DefinitionCatalog{})
+}
+
+const (
+	// DefinitionCatalogStatusSynced all definitions of the catalog are imported
+	DefinitionCatalogStatusSynced = "synced"
+	// DefinitionCatalogStatusFailed the last sync of the catalog is failed
+	DefinitionCatalogStatusFailed = "failed"
+)
+
+// DefinitionCatalog is a Git repository that distributes the curated definitions
+type DefinitionCatalog struct {
+	BaseModel
+	Name        string `json:"name"`
+	Alias       string `json:"alias"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	Branch      string `json:"branch"`
+	// Path the directory of the definitions in the repository, the whole repository is imported if empty
+	Path     string `json:"path"`
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty" encrypted:"true"`
+	// VerifyKeys the armored PGP public keys, the commit must be signed by one of them if set
+	VerifyKeys string `json:"verifyKeys,omitempty"`
+	// Revision the commit of the last successful sync
+	Revision     string              `json:"revision"`
+	Definitions  []CatalogDefinition `json:"definitions"`
+	Status       string              `json:"status"`
+	Message      string              `json:"message"`
+	LastSyncTime time.Time           `json:"lastSyncTime"`
+}
+
+// CatalogDefinition is a definition imported from the catalog
+type CatalogDefinition struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Version the blob hash of the definition file in the repository
+	Version string `json:"version"`
+	// Revision the commit that changes the definition last time
+	Revision string `json:"revision"`
+}
+
+// TableName return custom table name
+func (d *DefinitionCatalog) TableName() string {
+	return tableNamePrefix + "definition_catalog"
+}
+
+// ShortTableName return custom table name
+func (d *DefinitionCatalog) ShortTableName() string {
+	return "def_ctg"
+}
+
+// PrimaryKey return custom primary key
+func (d *DefinitionCatalog) PrimaryKey() string {
+	return d.Name
+}
+
+// Index return custom index
+func (d *DefinitionCatalog) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if d.Name != "" {
+		index["name"] = d.Name
+	}
+	return index
+}
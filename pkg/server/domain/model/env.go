@@ -66,3 +66,8 @@ func (p *Env) Index() map[string]interface{} {
 	}
 	return index
 }
+
+// Indexes return custom indexes, a namespace is bound to one environment
+func (p *Env) Indexes() []string {
+	return []string{"project", "unique:namespace"}
+}
@@ -29,6 +29,8 @@ type EnvBinding struct {
 	AppDeployName   string           `json:"appDeployName"`
 	Name            string           `json:"name"`
 	ComponentsPatch []ComponentPatch `json:"componentsPatchs"`
+	// ComponentReplicas the replicas of the components set by the scale API, they override the replicas of the scaler traits in the env
+	ComponentReplicas map[string]int `json:"componentReplicas,omitempty"`
 }
 
 // ComponentPatch Define differential patches for components in the environment.
@@ -72,3 +74,8 @@ func (e *EnvBinding) Index() map[string]interface{} {
 	}
 	return index
 }
+
+// Indexes return custom indexes
+func (e *EnvBinding) Indexes() []string {
+	return []string{"appPrimaryKey"}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+func init() {
+	RegisterModel(&StepLog{})
+}
+
+// StepLog the archived logs of a finished workflow or pipeline step, the logs larger than the threshold are stored
+// in the object storage and only the object key is saved
+type StepLog struct {
+	BaseModel
+	Name string `json:"name"`
+	// Resource the type of the entity, application or pipeline
+	Resource string `json:"resource"`
+	Project  string `json:"project"`
+	// Entity the name of the application or the pipeline
+	Entity string `json:"entity"`
+	// Record the name of the workflow record or the pipeline run
+	Record string `json:"record"`
+	Step   string `json:"step"`
+	// Source where the logs are read from, such as Resource and URL
+	Source  string `json:"source,omitempty"`
+	Content string `json:"content,omitempty"`
+	// ObjectKey the key of the logs in the object storage, the content is empty if it is set
+	ObjectKey string `json:"objectKey,omitempty"`
+	Size      int    `json:"size"`
+}
+
+// TableName return custom table name
+func (s *StepLog) TableName() string {
+	return tableNamePrefix + "step_log"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (s *StepLog) ShortTableName() string {
+	return "step_log"
+}
+
+// PrimaryKey return custom primary key
+func (s *StepLog) PrimaryKey() string {
+	return s.Name
+}
+
+// Index return custom index
+func (s *StepLog) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if s.Name != "" {
+		index["name"] = s.Name
+	}
+	if s.Resource != "" {
+		index["resource"] = s.Resource
+	}
+	if s.Project != "" {
+		index["project"] = s.Project
+	}
+	if s.Entity != "" {
+		index["entity"] = s.Entity
+	}
+	if s.Record != "" {
+		index["record"] = s.Record
+	}
+	if s.Step != "" {
+		index["step"] = s.Step
+	}
+	return index
+}
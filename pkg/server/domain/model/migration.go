@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+func init() {
+	RegisterModel(&SchemaMigration{})
+}
+
+const (
+	// MigrationStatusApplying the migration is started but not finished, it is applied again at the next start
+	MigrationStatusApplying = "applying"
+	// MigrationStatusApplied the migration is finished
+	MigrationStatusApplied = "applied"
+)
+
+// SchemaMigration the record of a migration of the stored data
+type SchemaMigration struct {
+	BaseModel
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	AppliedAt time.Time `json:"appliedAt,omitempty"`
+}
+
+// TableName return custom table name
+func (s *SchemaMigration) TableName() string {
+	return tableNamePrefix + "schema_migration"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (s *SchemaMigration) ShortTableName() string {
+	return "schema_mig"
+}
+
+// PrimaryKey return custom primary key
+func (s *SchemaMigration) PrimaryKey() string {
+	if s.Version <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%06d", s.Version)
+}
+
+// Index return custom index
+func (s *SchemaMigration) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if s.Status != "" {
+		index["status"] = s.Status
+	}
+	return index
+}
@@ -133,6 +133,20 @@ func (j *JSONStruct) RawExtension() *runtime.RawExtension {
 type BaseModel struct {
 	CreateTime time.Time `json:"createTime"`
 	UpdateTime time.Time `json:"updateTime"`
+	// ResourceVersion is increased by every update, the update with a stale version is rejected
+	ResourceVersion int64 `json:"resourceVersion,omitempty"`
+	// ExpireAt the entity is deleted by the datastore after it, the entity never expires if it is nil
+	ExpireAt *time.Time `json:"expireAt,omitempty"`
+}
+
+// GetExpireAt get the time the entity expires
+func (m *BaseModel) GetExpireAt() *time.Time {
+	return m.ExpireAt
+}
+
+// SetExpireAt set the time the entity expires
+func (m *BaseModel) SetExpireAt(expireAt time.Time) {
+	m.ExpireAt = &expireAt
 }
 
 // SetCreateTime set create time
@@ -145,6 +159,16 @@ func (m *BaseModel) SetUpdateTime(time time.Time) {
 	m.UpdateTime = time
 }
 
+// GetResourceVersion get the version of the stored entity
+func (m *BaseModel) GetResourceVersion() int64 {
+	return m.ResourceVersion
+}
+
+// SetResourceVersion set resource version
+func (m *BaseModel) SetResourceVersion(version int64) {
+	m.ResourceVersion = version
+}
+
 func deepCopy(src interface{}) interface{} {
 	dst := reflect.New(reflect.TypeOf(src).Elem())
 
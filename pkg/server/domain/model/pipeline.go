@@ -18,6 +18,7 @@ package model
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kubevela/workflow/api/v1alpha1"
 )
@@ -25,6 +26,7 @@ import (
 func init() {
 	RegisterModel(&PipelineContext{})
 	RegisterModel(&Pipeline{})
+	RegisterModel(&PipelineStepBaseline{})
 }
 
 // Structs copied from workflow/api/v1alpha1/types.go
@@ -43,6 +45,8 @@ type Pipeline struct {
 	Project     string `json:"project"`
 	Alias       string `json:"alias"`
 	Description string `json:"description"`
+	// Ownership who to contact about the pipeline
+	Ownership *Ownership `json:"ownership,omitempty"`
 }
 
 // PrimaryKey return custom primary key
@@ -72,6 +76,61 @@ func (p Pipeline) Index() map[string]interface{} {
 	return index
 }
 
+// SearchFields the fields matched by the global search
+func (p Pipeline) SearchFields() []string {
+	return []string{"name", "alias", "description"}
+}
+
+// PipelineStepBaseline is the recent durations of the pipeline steps, the runs that regressed from the baseline are recorded
+type PipelineStepBaseline struct {
+	BaseModel
+	Project      string `json:"project"`
+	PipelineName string `json:"pipelineName"`
+	// LastRunTime the end time of the latest run counted in the baseline
+	LastRunTime time.Time `json:"lastRunTime"`
+	// Durations the durations in seconds of the recent succeeded executions of every step, the latest is the last
+	Durations   map[string][]int64 `json:"durations"`
+	Regressions []StepRegression   `json:"regressions,omitempty"`
+}
+
+// StepRegression is a step execution that is slower than the baseline beyond the threshold
+type StepRegression struct {
+	RunName  string `json:"runName"`
+	StepName string `json:"stepName"`
+	// Duration the duration of the execution in seconds
+	Duration int64 `json:"duration"`
+	// Baseline the median duration of the step in seconds when the regression is detected
+	Baseline   int64     `json:"baseline"`
+	DetectTime time.Time `json:"detectTime"`
+}
+
+// PrimaryKey return custom primary key
+func (b *PipelineStepBaseline) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s", b.Project, b.PipelineName)
+}
+
+// TableName return custom table name
+func (b *PipelineStepBaseline) TableName() string {
+	return tableNamePrefix + "pipeline_step_baseline"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (b *PipelineStepBaseline) ShortTableName() string {
+	return "pp-stp-bl"
+}
+
+// Index return custom index
+func (b *PipelineStepBaseline) Index() map[string]interface{} {
+	var index = make(map[string]interface{})
+	if b.Project != "" {
+		index["project"] = b.Project
+	}
+	if b.PipelineName != "" {
+		index["pipelineName"] = b.PipelineName
+	}
+	return index
+}
+
 // Value is a k-v pair
 type Value struct {
 	Key   string `json:"key"`
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+func init() {
+	RegisterModel(&PolicyBundle{})
+}
+
+// PolicyBundle the record of the roles, the permissions and the project members reconciled from a VelaUXPolicy,
+// the records no longer declared by the policy are deleted by the next reconciliation
+type PolicyBundle struct {
+	BaseModel
+	Name string `json:"name"`
+	// Generation the generation of the policy reconciled last time
+	Generation int64 `json:"generation"`
+	// Permissions the reconciled permissions as <project>/<name>, the project is empty for the platform permissions
+	Permissions []string `json:"permissions,omitempty"`
+	// Roles the reconciled roles as <project>/<name>
+	Roles []string `json:"roles,omitempty"`
+	// Bindings the reconciled project members as <project>/<user>
+	Bindings []string `json:"bindings,omitempty"`
+	// Message the error of the last reconciliation, it is empty if the policy is reconciled
+	Message string `json:"message,omitempty"`
+}
+
+// TableName return custom table name
+func (p *PolicyBundle) TableName() string {
+	return tableNamePrefix + "policy_bundle"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (p *PolicyBundle) ShortTableName() string {
+	return "plc_bundle"
+}
+
+// PrimaryKey return custom primary key
+func (p *PolicyBundle) PrimaryKey() string {
+	return p.Name
+}
+
+// Index return custom index
+func (p *PolicyBundle) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if p.Name != "" {
+		index["name"] = p.Name
+	}
+	return index
+}
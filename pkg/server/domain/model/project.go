@@ -16,8 +16,12 @@ limitations under the License.
 
 package model
 
+import "time"
+
 func init() {
 	RegisterModel(&Project{})
+	RegisterModel(&ProjectMemberEvent{})
+	RegisterModel(&ProjectLockEvent{})
 }
 
 // Project basic model
@@ -28,6 +32,67 @@ type Project struct {
 	Owner       string `json:"owner"`
 	Description string `json:"description,omitempty"`
 	Namespace   string `json:"namespace"`
+	// Labels the labels to select the project, such as by the cross-project roles
+	Labels map[string]string `json:"labels,omitempty"`
+	// Quota the soft limits of the resources in this project, the users will be warned when the usage reaches the threshold.
+	Quota *ProjectQuota `json:"quota,omitempty"`
+	// Lock blocks the deploys and the spec changes of the project until it expires, it is used during the incidents
+	Lock *ProjectLock `json:"lock,omitempty"`
+	// ApplicationDefaults the defaults applied to every new application created in the project
+	ApplicationDefaults *ApplicationDefaults `json:"applicationDefaults,omitempty"`
+	// RecordRetention prunes the finished workflow records of the applications, the records are kept forever if it is nil
+	RecordRetention *WorkflowRecordRetention `json:"recordRetention,omitempty"`
+}
+
+// WorkflowRecordRetention the retention policy of the workflow records of a project, the zero value means unlimited.
+// The finished records beyond either limit are pruned, the unfinished records are never pruned.
+type WorkflowRecordRetention struct {
+	// KeepRecords how many finished records of an application are kept
+	KeepRecords int `json:"keepRecords,omitempty"`
+	// KeepDays how many days the finished records are kept
+	KeepDays int `json:"keepDays,omitempty"`
+	// Archive exports the records to the object storage before they are deleted
+	Archive bool `json:"archive,omitempty"`
+}
+
+// ApplicationDefaults the labels and the main component settings applied to the new applications of a project,
+// the values in the request of the application take precedence over the defaults
+type ApplicationDefaults struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the main component by the annotations trait
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Env the environment variables added to the main component by the env trait
+	Env map[string]string `json:"env,omitempty"`
+	// Traits the base traits added to the main component if it has no trait of the same type
+	Traits []ApplicationDefaultTrait `json:"traits,omitempty"`
+}
+
+// ApplicationDefaultTrait the base trait of the new applications
+type ApplicationDefaultTrait struct {
+	Type       string      `json:"type"`
+	Properties *JSONStruct `json:"properties,omitempty"`
+}
+
+// ProjectLock the temporary read-only lock of a project
+type ProjectLock struct {
+	Reason     string    `json:"reason"`
+	Operator   string    `json:"operator"`
+	LockTime   time.Time `json:"lockTime"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// IsLocked checks whether the project is locked at the time, the expired lock is ignored
+func (p *Project) IsLocked(now time.Time) bool {
+	return p.Lock != nil && now.Before(p.Lock.ExpireTime)
+}
+
+// ProjectQuota the soft limits of the resources in a project, the zero value means unlimited
+type ProjectQuota struct {
+	Applications int64 `json:"applications,omitempty"`
+	Pipelines    int64 `json:"pipelines,omitempty"`
+	Targets      int64 `json:"targets,omitempty"`
+	// ServiceInstances unlike the other resources, the quota of the service instances is enforced because they cost money
+	ServiceInstances int64 `json:"serviceInstances,omitempty"`
 }
 
 // GetNamespace get the namespace name of this project.
@@ -64,3 +129,107 @@ func (p *Project) Index() map[string]interface{} {
 	}
 	return index
 }
+
+const (
+	// ProjectMemberAdded a user is added to the project
+	ProjectMemberAdded = "MemberAdded"
+	// ProjectMemberUpdated the roles of a project member are changed
+	ProjectMemberUpdated = "MemberUpdated"
+	// ProjectMemberRemoved a user is removed from the project
+	ProjectMemberRemoved = "MemberRemoved"
+	// ProjectRoleUpdated the permissions of a project role are changed
+	ProjectRoleUpdated = "RoleUpdated"
+)
+
+// ProjectMemberEvent records a change of the project members or the project roles
+type ProjectMemberEvent struct {
+	BaseModel
+	Name    string `json:"name"`
+	Project string `json:"project"`
+	Type    string `json:"type"`
+	// Username the member whose access is changed, it is empty if the event type is RoleUpdated
+	Username string `json:"username,omitempty"`
+	// Roles the project roles of the member, or the changed role if the event type is RoleUpdated
+	Roles []string `json:"roles,omitempty"`
+	// Permissions the permissions of the changed role
+	Permissions []string `json:"permissions,omitempty"`
+	Operator    string   `json:"operator,omitempty"`
+}
+
+// TableName return custom table name
+func (p *ProjectMemberEvent) TableName() string {
+	return tableNamePrefix + "project_member_event"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (p *ProjectMemberEvent) ShortTableName() string {
+	return "pj_mevt"
+}
+
+// PrimaryKey return custom primary key
+func (p *ProjectMemberEvent) PrimaryKey() string {
+	return p.Name
+}
+
+// Index return custom index
+func (p *ProjectMemberEvent) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if p.Name != "" {
+		index["name"] = p.Name
+	}
+	if p.Project != "" {
+		index["project"] = p.Project
+	}
+	if p.Username != "" {
+		index["username"] = p.Username
+	}
+	return index
+}
+
+const (
+	// ProjectLocked the project is locked
+	ProjectLocked = "Locked"
+	// ProjectUnlocked the project is unlocked by the user
+	ProjectUnlocked = "Unlocked"
+	// ProjectLockExpired the lock of the project is expired
+	ProjectLockExpired = "LockExpired"
+)
+
+// ProjectLockEvent records the lock and the unlock of a project
+type ProjectLockEvent struct {
+	BaseModel
+	Name       string    `json:"name"`
+	Project    string    `json:"project"`
+	Type       string    `json:"type"`
+	Reason     string    `json:"reason,omitempty"`
+	ExpireTime time.Time `json:"expireTime,omitempty"`
+	// Operator the user who locks or unlocks the project, it is empty if the lock is expired
+	Operator string `json:"operator,omitempty"`
+}
+
+// TableName return custom table name
+func (p *ProjectLockEvent) TableName() string {
+	return tableNamePrefix + "project_lock_event"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (p *ProjectLockEvent) ShortTableName() string {
+	return "pj_levt"
+}
+
+// PrimaryKey return custom primary key
+func (p *ProjectLockEvent) PrimaryKey() string {
+	return p.Name
+}
+
+// Index return custom index
+func (p *ProjectLockEvent) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if p.Name != "" {
+		index["name"] = p.Name
+	}
+	if p.Project != "" {
+		index["project"] = p.Project
+	}
+	return index
+}
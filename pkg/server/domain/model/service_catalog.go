@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "fmt"
+
+func init() {
+	RegisterModel(&ServiceInstance{})
+}
+
+const (
+	// ServiceInstanceStatusPending the service instance is waiting for the approval
+	ServiceInstanceStatusPending = "pending"
+	// ServiceInstanceStatusProvisioned the service instance is added to the application as a component
+	ServiceInstanceStatusProvisioned = "provisioned"
+	// ServiceInstanceStatusRejected the service instance is rejected
+	ServiceInstanceStatusRejected = "rejected"
+)
+
+// ServiceInstance is a managed service, such as a database or a queue, requested from the service catalog.
+// It is provisioned as a component of the application, the type of the component is the service class.
+type ServiceInstance struct {
+	BaseModel
+	Name    string `json:"name"`
+	Project string `json:"project"`
+	// ServiceClass the name of the component definition based on terraform or crossplane
+	ServiceClass  string `json:"serviceClass"`
+	AppPrimaryKey string `json:"appPrimaryKey"`
+	// Properties the JSON properties of the component
+	Properties string `json:"properties,omitempty"`
+	Status     string `json:"status"`
+	Requester  string `json:"requester"`
+	Reviewer   string `json:"reviewer,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// TableName return custom table name
+func (s *ServiceInstance) TableName() string {
+	return tableNamePrefix + "service_instance"
+}
+
+// ShortTableName return custom table name
+func (s *ServiceInstance) ShortTableName() string {
+	return "svc_ins"
+}
+
+// PrimaryKey return custom primary key
+func (s *ServiceInstance) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s", s.Project, s.Name)
+}
+
+// Index return custom index
+func (s *ServiceInstance) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if s.Name != "" {
+		index["name"] = s.Name
+	}
+	if s.Project != "" {
+		index["project"] = s.Project
+	}
+	if s.AppPrimaryKey != "" {
+		index["appPrimaryKey"] = s.AppPrimaryKey
+	}
+	if s.Status != "" {
+		index["status"] = s.Status
+	}
+	return index
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "fmt"
+
+func init() {
+	RegisterModel(&SyncCheckpoint{}, &SyncScope{})
+}
+
+// SyncCheckpoint the resource version of an object processed by a sync worker, the object unchanged since then is not
+// processed again after the restart
+type SyncCheckpoint struct {
+	BaseModel
+	// Worker the sync worker processed the object, application or workflow-record
+	Worker string `json:"worker"`
+	// Cluster the managed cluster of the object, it is empty for the hub cluster
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Scope the options of the worker filtering the objects, the checkpoints of another scope are ignored
+	Scope           string `json:"scope,omitempty"`
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+// TableName return custom table name
+func (s *SyncCheckpoint) TableName() string {
+	return tableNamePrefix + "sync_checkpoint"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (s *SyncCheckpoint) ShortTableName() string {
+	return "sync_ckpt"
+}
+
+// PrimaryKey return custom primary key
+func (s *SyncCheckpoint) PrimaryKey() string {
+	if s.Cluster == "" {
+		return fmt.Sprintf("%s-%s-%s", s.Worker, s.Namespace, s.Name)
+	}
+	return fmt.Sprintf("%s-%s-%s-%s", s.Worker, s.Cluster, s.Namespace, s.Name)
+}
+
+// Index return custom index
+func (s *SyncCheckpoint) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if s.Worker != "" {
+		index["worker"] = s.Worker
+	}
+	if s.Cluster != "" {
+		index["cluster"] = s.Cluster
+	}
+	return index
+}
+
+// SyncScope filters the applications created outside VelaUX that are synced from the clusters, it replaces the
+// scope of the server config once it is set. The empty lists match all, and the exclusions take precedence.
+type SyncScope struct {
+	BaseModel
+	Name              string   `json:"name"`
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty"`
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+	// IncludeClusters the hub cluster is named local
+	IncludeClusters []string `json:"includeClusters,omitempty"`
+	ExcludeClusters []string `json:"excludeClusters,omitempty"`
+	// LabelSelector only the applications matching the selector are synced
+	LabelSelector string `json:"labelSelector,omitempty"`
+	UpdatedBy     string `json:"updatedBy,omitempty"`
+}
+
+// TableName return custom table name
+func (s *SyncScope) TableName() string {
+	return tableNamePrefix + "sync_scope"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (s *SyncScope) ShortTableName() string {
+	return "sync_scope"
+}
+
+// PrimaryKey return custom primary key
+func (s *SyncScope) PrimaryKey() string {
+	return s.Name
+}
+
+// Index return custom index
+func (s *SyncScope) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if s.Name != "" {
+		index["name"] = s.Name
+	}
+	return index
+}
@@ -32,13 +32,57 @@ const (
 // SystemInfo systemInfo model
 type SystemInfo struct {
 	BaseModel
-	SignedKey                   string        `json:"signedKey"`
+	SignedKey                   string        `json:"signedKey" encrypted:"true"`
 	InstallID                   string        `json:"installID"`
 	EnableCollection            bool          `json:"enableCollection"`
 	StatisticInfo               StatisticInfo `json:"statisticInfo,omitempty"`
 	LoginType                   string        `json:"loginType"`
 	DexUserDefaultProjects      []ProjectRef  `json:"projects"`
 	DexUserDefaultPlatformRoles []string      `json:"dexUserDefaultPlatformRoles"`
+	// MaintenanceMode rejects all write requests except the users who have the maintenance-override permission
+	MaintenanceMode bool `json:"maintenanceMode"`
+	// OfflineMode disables the outbound internet calls, such as the addon registries and the usage collection
+	OfflineMode bool `json:"offlineMode"`
+	// OAuthConnectors the GitHub and GitLab login connectors, they are added to the dex config with the connectors of the config secrets
+	OAuthConnectors []OAuthConnector `json:"oauthConnectors,omitempty"`
+	// OAuthGroupMappings grants the projects and the platform roles to the users by their organizations, teams or groups
+	OAuthGroupMappings []OAuthGroupMapping `json:"oauthGroupMappings,omitempty"`
+	// SessionSettings the lifetime of the tokens and the idle timeout of the sessions, the defaults are used if it is empty
+	SessionSettings *SessionSettings `json:"sessionSettings,omitempty"`
+	// VelaAddress the address of VelaUX that the links in the emails point to, such as the invite links
+	VelaAddress string `json:"velaAddress,omitempty"`
+	// LoginBanner the legal notice shown on the login page
+	LoginBanner *LoginBanner `json:"loginBanner,omitempty"`
+}
+
+// LoginBanner the notice shown before the login, the users must acknowledge its current version to login if the consent is required
+type LoginBanner struct {
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content"`
+	// RequireConsent the session is only issued if the user acknowledges the current version of the notice
+	RequireConsent bool `json:"requireConsent"`
+	// Version is increased whenever the title or the content changes, so the users acknowledge the new notice again
+	Version int `json:"version"`
+}
+
+// SessionSettings the lifetime of the login sessions, the zero values mean the defaults
+type SessionSettings struct {
+	AccessTokenTTLMinutes  int `json:"accessTokenTTLMinutes,omitempty"`
+	RefreshTokenTTLMinutes int `json:"refreshTokenTTLMinutes,omitempty"`
+	// IdleTimeoutMinutes the session is revoked if it is not used for the duration, 0 means never
+	IdleTimeoutMinutes int `json:"idleTimeoutMinutes,omitempty"`
+	// MaxConcurrentSessions how many active sessions a user could have, 0 means unlimited
+	MaxConcurrentSessions int `json:"maxConcurrentSessions,omitempty"`
+	// EvictOldestSession revokes the oldest sessions on the new login if the limit is reached, otherwise the new login is rejected
+	EvictOldestSession bool `json:"evictOldestSession,omitempty"`
+}
+
+// GetMaxConcurrentSessions returns 0 if the settings are not set
+func (s *SessionSettings) GetMaxConcurrentSessions() int {
+	if s == nil {
+		return 0
+	}
+	return s.MaxConcurrentSessions
 }
 
 // ProjectRef set the project name and roles
@@ -47,6 +91,34 @@ type ProjectRef struct {
 	Roles []string `json:"roles"`
 }
 
+const (
+	// OAuthConnectorGitHub the login connector of GitHub or GitHub Enterprise
+	OAuthConnectorGitHub = "github"
+	// OAuthConnectorGitLab the login connector of GitLab
+	OAuthConnectorGitLab = "gitlab"
+)
+
+// OAuthConnector the OAuth app of GitHub or GitLab used to log in
+type OAuthConnector struct {
+	Type         string `json:"type"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	ClientID     string `json:"clientID"`
+	ClientSecret string `json:"clientSecret" encrypted:"true"`
+	// BaseURL the address of GitHub Enterprise or the self-hosted GitLab, the public service is used if it is empty
+	BaseURL string `json:"baseURL,omitempty"`
+	// Orgs only the members of the GitHub organizations or the GitLab groups could log in, anyone could log in if it is empty
+	Orgs []string `json:"orgs,omitempty"`
+}
+
+// OAuthGroupMapping grants the projects and the platform roles to the members of the group.
+// The group is the organization or the "organization:team" of GitHub, or the full path of the GitLab group.
+type OAuthGroupMapping struct {
+	Group         string       `json:"group"`
+	Projects      []ProjectRef `json:"projects,omitempty"`
+	PlatformRoles []string     `json:"platformRoles,omitempty"`
+}
+
 // UpdateDexConfig update dex config
 type UpdateDexConfig struct {
 	Connectors      []map[string]interface{}
@@ -30,6 +30,8 @@ type Target struct {
 	Description string                 `json:"description,omitempty"`
 	Cluster     *ClusterTarget         `json:"cluster,omitempty"`
 	Variable    map[string]interface{} `json:"variable,omitempty"`
+	// NamespacePolicy is applied to the namespace of the target on the creation and kept reconciled
+	NamespacePolicy *TargetNamespacePolicy `json:"namespacePolicy,omitempty"`
 }
 
 // TableName return custom table name
@@ -59,8 +61,23 @@ func (d *Target) Index() map[string]interface{} {
 	return index
 }
 
+// Indexes return custom indexes
+func (d *Target) Indexes() []string {
+	return []string{"project"}
+}
+
 // ClusterTarget one kubernetes cluster delivery target
 type ClusterTarget struct {
 	ClusterName string `json:"clusterName" validate:"checkname"`
 	Namespace   string `json:"namespace" optional:"true"`
 }
+
+// TargetNamespacePolicy the labels, the annotations and the security presets of the namespace of the target
+type TargetNamespacePolicy struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// PodSecurity the Pod Security Standard enforced in the namespace, privileged, baseline or restricted
+	PodSecurity string `json:"podSecurity,omitempty"`
+	// NetworkPolicy the preset of the network policy created in the namespace, deny-all-ingress, deny-all or allow-same-namespace
+	NetworkPolicy string `json:"networkPolicy,omitempty"`
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+func init() {
+	RegisterModel(&TrashItem{})
+}
+
+// TrashItem is a deleted application, environment, pipeline or role kept for the restore until the retention,
+// the records deleted with the entity are kept in the item as well, so they are restored together
+type TrashItem struct {
+	BaseModel
+	Name string `json:"name"`
+	// Kind the kind of the deleted entity, application, environment, pipeline or role
+	Kind string `json:"kind"`
+	// Project the project of the deleted entity, it is empty for the platform roles
+	Project   string    `json:"project"`
+	Entity    string    `json:"entity"`
+	Alias     string    `json:"alias,omitempty"`
+	DeletedAt time.Time `json:"deletedAt"`
+	DeletedBy string    `json:"deletedBy,omitempty"`
+	// Records the deleted entity at first, then the records deleted with it
+	Records []TrashRecord `json:"records"`
+}
+
+// TrashRecord a deleted record of the table, the data is the entity encoded as JSON
+type TrashRecord struct {
+	Table string          `json:"table"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// TableName return custom table name
+func (t *TrashItem) TableName() string {
+	return tableNamePrefix + "trash_item"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (t *TrashItem) ShortTableName() string {
+	return "trash"
+}
+
+// PrimaryKey return custom primary key
+func (t *TrashItem) PrimaryKey() string {
+	return t.Name
+}
+
+// Index return custom index
+func (t *TrashItem) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if t.Name != "" {
+		index["name"] = t.Name
+	}
+	if t.Kind != "" {
+		index["kind"] = t.Kind
+	}
+	if t.Project != "" {
+		index["project"] = t.Project
+	}
+	if t.Entity != "" {
+		index["entity"] = t.Entity
+	}
+	return index
+}
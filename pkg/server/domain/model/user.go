@@ -29,6 +29,20 @@ func init() {
 	RegisterModel(&Role{})
 	RegisterModel(&Permission{})
 	RegisterModel(&PermissionTemplate{})
+	RegisterModel(&ProjectRoleTemplate{})
+	RegisterModel(&RBACApproval{})
+	RegisterModel(&PasswordResetToken{})
+	RegisterModel(&UserInvitation{})
+	RegisterModel(&LoginAttempt{})
+	RegisterModel(&APIToken{})
+	RegisterModel(&Session{})
+	RegisterModel(&BootstrapToken{})
+	RegisterModel(&UserPreference{})
+	RegisterModel(&EmailVerification{})
+	RegisterModel(&EmailChangeRecord{})
+	RegisterModel(&PermissionSnapshot{})
+	RegisterModel(&UserAvatar{})
+	RegisterModel(&LoginRecord{})
 }
 
 // DefaultAdminUserName default admin user name
@@ -49,6 +63,17 @@ type User struct {
 	// UserRoles binding the platform level roles
 	UserRoles []string `json:"userRoles"`
 	DexSub    string   `json:"dexSub,omitempty"`
+	// DeactivateAt the user is disabled automatically at the time, such as the end of the engagement of a contractor
+	DeactivateAt *time.Time `json:"deactivateAt,omitempty"`
+	// DeactivationNotified the user and the project admins are notified of the upcoming deactivation
+	DeactivationNotified bool `json:"deactivationNotified,omitempty"`
+	// MustChangePassword the user could not use the other APIs until the password is changed by the user
+	MustChangePassword bool `json:"mustChangePassword,omitempty"`
+	// AvatarID the identifier of the uploaded avatar, the Gravatar of the email is used if it is empty
+	AvatarID string `json:"avatarID,omitempty"`
+	// ConsentVersion and ConsentTime the version of the login notice that the user acknowledged at the last login
+	ConsentVersion int        `json:"consentVersion,omitempty"`
+	ConsentTime    *time.Time `json:"consentTime,omitempty"`
 }
 
 // TableName return custom table name
@@ -81,6 +106,327 @@ func (u *User) Index() map[string]interface{} {
 	return index
 }
 
+// SearchFields the fields matched by the global search
+func (u *User) SearchFields() []string {
+	return []string{"name", "alias", "email"}
+}
+
+// PasswordResetToken is the token to reset the password of the user, only the hash of the token is stored
+type PasswordResetToken struct {
+	BaseModel
+	Username   string    `json:"username"`
+	TokenHash  string    `json:"tokenHash"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// TableName return custom table name
+func (p *PasswordResetToken) TableName() string {
+	return tableNamePrefix + "password_reset_token"
+}
+
+// ShortTableName return custom table name
+func (p *PasswordResetToken) ShortTableName() string {
+	return "pwdrst"
+}
+
+// PrimaryKey return custom primary key
+func (p *PasswordResetToken) PrimaryKey() string {
+	return p.Username
+}
+
+// Index return custom index
+func (p *PasswordResetToken) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if p.Username != "" {
+		index["username"] = p.Username
+	}
+	return index
+}
+
+// EmailVerification is the pending change of the user email, the token is sent to the new email and only its hash is stored
+type EmailVerification struct {
+	BaseModel
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	TokenHash  string    `json:"tokenHash"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// TableName return custom table name
+func (e *EmailVerification) TableName() string {
+	return tableNamePrefix + "email_verification"
+}
+
+// ShortTableName return custom table name
+func (e *EmailVerification) ShortTableName() string {
+	return "emlvrf"
+}
+
+// PrimaryKey return custom primary key
+func (e *EmailVerification) PrimaryKey() string {
+	return e.Username
+}
+
+// Index return custom index
+func (e *EmailVerification) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if e.Username != "" {
+		index["username"] = e.Username
+	}
+	if e.Email != "" {
+		index["email"] = e.Email
+	}
+	return index
+}
+
+// EmailChangeRecord is the audit record of a verified change of the user email
+type EmailChangeRecord struct {
+	BaseModel
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	OldEmail string `json:"oldEmail,omitempty"`
+	NewEmail string `json:"newEmail"`
+	// RequestTime when the change was requested, the CreateTime is when the new email was verified
+	RequestTime time.Time `json:"requestTime"`
+}
+
+// TableName return custom table name
+func (e *EmailChangeRecord) TableName() string {
+	return tableNamePrefix + "email_change_record"
+}
+
+// ShortTableName return custom table name
+func (e *EmailChangeRecord) ShortTableName() string {
+	return "emlchg"
+}
+
+// PrimaryKey return custom primary key
+func (e *EmailChangeRecord) PrimaryKey() string {
+	return e.Name
+}
+
+// Index return custom index
+func (e *EmailChangeRecord) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if e.Name != "" {
+		index["name"] = e.Name
+	}
+	if e.Username != "" {
+		index["username"] = e.Username
+	}
+	return index
+}
+
+// UserInvitation is the invitation sent to the email of a new user, only the hash of the token is stored.
+// The roles are granted to the user when the invitation is accepted.
+type UserInvitation struct {
+	BaseModel
+	ID            string                   `json:"id"`
+	Email         string                   `json:"email"`
+	Alias         string                   `json:"alias,omitempty"`
+	PlatformRoles []string                 `json:"platformRoles,omitempty"`
+	ProjectRoles  []InvitationProjectRoles `json:"projectRoles,omitempty"`
+	TokenHash     string                   `json:"tokenHash"`
+	ExpireTime    time.Time                `json:"expireTime"`
+	Inviter       string                   `json:"inviter,omitempty"`
+}
+
+// InvitationProjectRoles the roles of a project granted to the invited user
+type InvitationProjectRoles struct {
+	Project string   `json:"project"`
+	Roles   []string `json:"roles"`
+}
+
+// TableName return custom table name
+func (u *UserInvitation) TableName() string {
+	return tableNamePrefix + "user_invitation"
+}
+
+// ShortTableName return custom table name
+func (u *UserInvitation) ShortTableName() string {
+	return "usrinv"
+}
+
+// PrimaryKey return custom primary key
+func (u *UserInvitation) PrimaryKey() string {
+	return u.ID
+}
+
+// Index return custom index
+func (u *UserInvitation) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if u.ID != "" {
+		index["id"] = u.ID
+	}
+	if u.Email != "" {
+		index["email"] = u.Email
+	}
+	return index
+}
+
+// LoginAttempt is the failed login attempts of a user or a client IP
+type LoginAttempt struct {
+	BaseModel
+	// Key the user or the client IP, such as user:admin or ip:10.0.0.1
+	Key         string    `json:"key"`
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"lastFailure"`
+	LockedUntil time.Time `json:"lockedUntil,omitempty"`
+}
+
+// TableName return custom table name
+func (l *LoginAttempt) TableName() string {
+	return tableNamePrefix + "login_attempt"
+}
+
+// ShortTableName return custom table name
+func (l *LoginAttempt) ShortTableName() string {
+	return "lgnatt"
+}
+
+// PrimaryKey return custom primary key
+func (l *LoginAttempt) PrimaryKey() string {
+	return l.Key
+}
+
+// Index return custom index
+func (l *LoginAttempt) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if l.Key != "" {
+		index["key"] = l.Key
+	}
+	return index
+}
+
+// APIToken is the personal API token of the user, only the hash of the secret is stored
+type APIToken struct {
+	BaseModel
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Username   string    `json:"username"`
+	SecretHash string    `json:"secretHash"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// TableName return custom table name
+func (a *APIToken) TableName() string {
+	return tableNamePrefix + "api_token"
+}
+
+// ShortTableName return custom table name
+func (a *APIToken) ShortTableName() string {
+	return "apitkn"
+}
+
+// PrimaryKey return custom primary key
+func (a *APIToken) PrimaryKey() string {
+	return a.ID
+}
+
+// Index return custom index
+func (a *APIToken) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if a.Name != "" {
+		index["name"] = a.Name
+	}
+	if a.Username != "" {
+		index["username"] = a.Username
+	}
+	return index
+}
+
+// Indexes return custom indexes
+func (a *APIToken) Indexes() []string {
+	return []string{"username"}
+}
+
+// Session is a login of the user, the JWT tokens issued by the login carry the session ID
+// and are rejected after the session is revoked
+type Session struct {
+	BaseModel
+	ID         string    `json:"id"`
+	Username   string    `json:"username"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	LastSeen   time.Time `json:"lastSeen"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// TableName return custom table name
+func (s *Session) TableName() string {
+	return tableNamePrefix + "session"
+}
+
+// ShortTableName return custom table name
+func (s *Session) ShortTableName() string {
+	return "sess"
+}
+
+// PrimaryKey return custom primary key
+func (s *Session) PrimaryKey() string {
+	return s.ID
+}
+
+// Index return custom index
+func (s *Session) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if s.Username != "" {
+		index["username"] = s.Username
+	}
+	return index
+}
+
+// Indexes return custom indexes
+func (s *Session) Indexes() []string {
+	return []string{"username,-createTime"}
+}
+
+// LoginMethodAPIToken the method of the logins with the personal API tokens
+const LoginMethodAPIToken = "token"
+
+// LoginRecord is a login of the user, it is kept for the login history retention
+type LoginRecord struct {
+	BaseModel
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	// Method the login method, local, dex or token
+	Method    string `json:"method"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"userAgent"`
+}
+
+// TableName return custom table name
+func (l *LoginRecord) TableName() string {
+	return tableNamePrefix + "login_record"
+}
+
+// ShortTableName return custom table name
+func (l *LoginRecord) ShortTableName() string {
+	return "lgnrec"
+}
+
+// PrimaryKey return custom primary key
+func (l *LoginRecord) PrimaryKey() string {
+	return l.Name
+}
+
+// Index return custom index
+func (l *LoginRecord) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if l.Username != "" {
+		index["username"] = l.Username
+	}
+	if l.Method != "" {
+		index["method"] = l.Method
+	}
+	return index
+}
+
+// Indexes return custom indexes
+func (l *LoginRecord) Indexes() []string {
+	return []string{"username,-createTime"}
+}
+
 // ProjectUser is the model of user in project
 type ProjectUser struct {
 	BaseModel
@@ -117,10 +463,18 @@ func (u *ProjectUser) Index() map[string]interface{} {
 	return index
 }
 
+// Indexes return custom indexes
+func (u *ProjectUser) Indexes() []string {
+	return []string{"projectName", "username"}
+}
+
 // CustomClaims is the custom claims
 type CustomClaims struct {
 	Username  string `json:"username"`
 	GrantType string `json:"grantType"`
+	SessionID string `json:"sid,omitempty"`
+	// Org the tenant the token is issued in, the token is rejected by the other tenants
+	Org string `json:"org,omitempty"`
 	jwt.StandardClaims
 }
 
@@ -131,6 +485,38 @@ type Role struct {
 	Alias       string   `json:"alias"`
 	Project     string   `json:"project,omitempty"`
 	Permissions []string `json:"permissions"`
+	// Projects the projects that the cross-project role could be bound in
+	Projects []string `json:"projects,omitempty"`
+	// ProjectSelector the labels of the projects that the cross-project role could be bound in
+	ProjectSelector map[string]string `json:"projectSelector,omitempty"`
+}
+
+// RoleScopeCrossProject is the project of the roles that could be bound in several projects.
+// It is not a valid project name, so it never conflicts with the project roles.
+const RoleScopeCrossProject = "cross.projects"
+
+// MatchProject checks whether the role could be bound in the project
+func (r *Role) MatchProject(project *Project) bool {
+	if r.Project == project.Name {
+		return true
+	}
+	if r.Project != RoleScopeCrossProject {
+		return false
+	}
+	for _, name := range r.Projects {
+		if name == project.Name {
+			return true
+		}
+	}
+	if len(r.ProjectSelector) == 0 {
+		return false
+	}
+	for k, v := range r.ProjectSelector {
+		if value, ok := project.Labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
 }
 
 // Permission is a model for a new RBAC mode.
@@ -142,7 +528,9 @@ type Permission struct {
 	Resources []string `json:"resources"`
 	Actions   []string `json:"actions"`
 	// Effect option values: Allow,Deny
-	Effect    string     `json:"effect"`
+	Effect string `json:"effect"`
+	// Priority the policies with the higher priority are evaluated first, the Deny wins in the same priority
+	Priority  int        `json:"priority,omitempty"`
 	Principal *Principal `json:"principal,omitempty"`
 	Condition *Condition `json:"condition,omitempty"`
 }
@@ -188,6 +576,11 @@ func (r *Role) Index() map[string]interface{} {
 	return index
 }
 
+// Indexes return custom indexes
+func (r *Role) Indexes() []string {
+	return []string{"project"}
+}
+
 // TableName return custom table name
 func (p *Permission) TableName() string {
 	return tableNamePrefix + "perm"
@@ -221,6 +614,11 @@ func (p *Permission) Index() map[string]interface{} {
 	return index
 }
 
+// Indexes return custom indexes
+func (p *Permission) Indexes() []string {
+	return []string{"project"}
+}
+
 // PermissionTemplate is a model for a new RBAC mode.
 type PermissionTemplate struct {
 	BaseModel
@@ -260,3 +658,310 @@ func (p *PermissionTemplate) Index() map[string]interface{} {
 	}
 	return index
 }
+
+// ProjectRoleTemplate is the template of the project level role, the role will be created in every new project.
+type ProjectRoleTemplate struct {
+	BaseModel
+	Name  string `json:"name"`
+	Alias string `json:"alias"`
+	// Permissions the names of the project level permissions
+	Permissions []string `json:"permissions"`
+}
+
+// TableName return custom table name
+func (p *ProjectRoleTemplate) TableName() string {
+	return tableNamePrefix + "project_role_temp"
+}
+
+// ShortTableName return custom table name
+func (p *ProjectRoleTemplate) ShortTableName() string {
+	return "prole_temp"
+}
+
+// PrimaryKey return custom primary key
+func (p *ProjectRoleTemplate) PrimaryKey() string {
+	return p.Name
+}
+
+// Index return custom index
+func (p *ProjectRoleTemplate) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if p.Name != "" {
+		index["name"] = p.Name
+	}
+	return index
+}
+
+const (
+	// RBACApprovalStatusPending the change is waiting for the approval
+	RBACApprovalStatusPending = "pending"
+	// RBACApprovalStatusApproved the change is approved and has taken effect
+	RBACApprovalStatusApproved = "approved"
+	// RBACApprovalStatusRejected the change is rejected
+	RBACApprovalStatusRejected = "rejected"
+)
+
+// RBACApproval is a sensitive change of the platform roles or permissions, it takes effect after a second user approves it.
+type RBACApproval struct {
+	BaseModel
+	Name string `json:"name"`
+	// Kind option values: role, permission
+	Kind string `json:"kind"`
+	// Operation option values: create, update
+	Operation string `json:"operation"`
+	// ResourceName the name of the role or the permission
+	ResourceName string `json:"resourceName"`
+	// Payload the request body of the change
+	Payload   string `json:"payload"`
+	Requester string `json:"requester"`
+	Status    string `json:"status"`
+	Reviewer  string `json:"reviewer,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// TableName return custom table name
+func (r *RBACApproval) TableName() string {
+	return tableNamePrefix + "rbac_approval"
+}
+
+// ShortTableName return custom table name
+func (r *RBACApproval) ShortTableName() string {
+	return "rbac_appr"
+}
+
+// PrimaryKey return custom primary key
+func (r *RBACApproval) PrimaryKey() string {
+	return r.Name
+}
+
+// Index return custom index
+func (r *RBACApproval) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if r.Name != "" {
+		index["name"] = r.Name
+	}
+	if r.Status != "" {
+		index["status"] = r.Status
+	}
+	if r.Requester != "" {
+		index["requester"] = r.Requester
+	}
+	return index
+}
+
+// BootstrapToken is the one-time token to initialize the admin user, only the hash of the token is stored.
+// It exists until the admin user is initialized.
+type BootstrapToken struct {
+	BaseModel
+	Name      string `json:"name"`
+	TokenHash string `json:"tokenHash,omitempty"`
+}
+
+// TableName return custom table name
+func (b *BootstrapToken) TableName() string {
+	return tableNamePrefix + "bootstrap_token"
+}
+
+// ShortTableName return custom table name
+func (b *BootstrapToken) ShortTableName() string {
+	return "btstrp"
+}
+
+// PrimaryKey return custom primary key
+func (b *BootstrapToken) PrimaryKey() string {
+	return b.Name
+}
+
+// Index return custom index
+func (b *BootstrapToken) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if b.Name != "" {
+		index["name"] = b.Name
+	}
+	return index
+}
+
+// NotificationEventStepRegression the notification to the project owner when the pipeline steps regress
+const NotificationEventStepRegression = "stepRegression"
+
+// NotificationEventUserDeactivation the notification to the user and the project admins before the user is deactivated
+const NotificationEventUserDeactivation = "userDeactivation"
+
+const (
+	// NotificationSeverityInfo the notification for the information
+	NotificationSeverityInfo = "info"
+	// NotificationSeverityWarning the notification that may need the attention
+	NotificationSeverityWarning = "warning"
+	// NotificationSeverityCritical the notification that needs the action
+	NotificationSeverityCritical = "critical"
+)
+
+const (
+	// NotificationDestinationEmail sends the notifications to the email address
+	NotificationDestinationEmail = "email"
+	// NotificationDestinationSlack sends the notifications to the Slack member or channel
+	NotificationDestinationSlack = "slack"
+	// NotificationDestinationDefault the name of the built-in destination that is the email of the user account
+	NotificationDestinationDefault = "default"
+)
+
+// UserPreference is the preferences of the user managed by the user self, such as the language and the theme of the UI
+type UserPreference struct {
+	BaseModel
+	Username       string `json:"username"`
+	DefaultProject string `json:"defaultProject,omitempty"`
+	Language       string `json:"language,omitempty"`
+	Theme          string `json:"theme,omitempty"`
+	ItemsPerPage   int    `json:"itemsPerPage,omitempty"`
+	// Notifications the zero value receives all the notifications
+	Notifications NotificationPreference `json:"notifications"`
+}
+
+// NotificationPreference where the user receives the notifications
+type NotificationPreference struct {
+	// DisableEmail stops all the notification emails, the password reset and the invitation emails are not affected
+	DisableEmail   bool     `json:"disableEmail"`
+	DisabledEvents []string `json:"disabledEvents,omitempty"`
+	// Destinations the extra emails and the Slack handles of the user
+	Destinations []NotificationDestination `json:"destinations,omitempty"`
+	// Rules routes the notifications to the destinations, all the notifications go to the default destination if there is no rule
+	Rules []NotificationRule `json:"rules,omitempty"`
+}
+
+// NotificationDestination an email address or a Slack handle to receive the notifications
+type NotificationDestination struct {
+	Name string `json:"name"`
+	// Type is email or slack
+	Type string `json:"type"`
+	// Address the email address, or the member ID or the channel ID of Slack
+	Address string `json:"address"`
+}
+
+// NotificationRule sends the notifications of the category to the destinations if they are not less severe than the severity
+type NotificationRule struct {
+	// Category the notification event, * matches all the events
+	Category string `json:"category"`
+	// Severity the minimum severity, all the severities are matched if it is empty
+	Severity     string   `json:"severity,omitempty"`
+	Destinations []string `json:"destinations"`
+}
+
+// TableName return custom table name
+func (u *UserPreference) TableName() string {
+	return tableNamePrefix + "user_preference"
+}
+
+// ShortTableName return custom table name
+func (u *UserPreference) ShortTableName() string {
+	return "usrpref"
+}
+
+// PrimaryKey return custom primary key
+func (u *UserPreference) PrimaryKey() string {
+	return u.Username
+}
+
+// Index return custom index
+func (u *UserPreference) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if u.Username != "" {
+		index["username"] = u.Username
+	}
+	return index
+}
+
+// PermissionSnapshot is the effective roles and permissions of a user since the snapshot time,
+// they are effective until the next snapshot of the user. A new snapshot is stored only if they changed.
+type PermissionSnapshot struct {
+	BaseModel
+	Name         string    `json:"name"`
+	Username     string    `json:"username"`
+	SnapshotTime time.Time `json:"snapshotTime"`
+	// Digest the hash of the roles and permissions to detect the changes
+	Digest   string `json:"digest"`
+	Disabled bool   `json:"disabled,omitempty"`
+	// Removed means the user is deleted since the snapshot time
+	Removed       bool                   `json:"removed,omitempty"`
+	PlatformRoles []string               `json:"platformRoles,omitempty"`
+	ProjectRoles  []ProjectRolesSnapshot `json:"projectRoles,omitempty"`
+	Permissions   []PermissionRecord     `json:"permissions,omitempty"`
+}
+
+// ProjectRolesSnapshot the roles of a user in a project
+type ProjectRolesSnapshot struct {
+	Project string   `json:"project"`
+	Roles   []string `json:"roles"`
+}
+
+// PermissionRecord a permission granted to a user, the project is empty for the platform permissions
+type PermissionRecord struct {
+	Project   string   `json:"project,omitempty"`
+	Name      string   `json:"name"`
+	Resources []string `json:"resources"`
+	Actions   []string `json:"actions"`
+	Effect    string   `json:"effect"`
+	Priority  int      `json:"priority,omitempty"`
+}
+
+// TableName return custom table name
+func (p *PermissionSnapshot) TableName() string {
+	return tableNamePrefix + "permission_snapshot"
+}
+
+// ShortTableName return custom table name
+func (p *PermissionSnapshot) ShortTableName() string {
+	return "permsnp"
+}
+
+// PrimaryKey return custom primary key
+func (p *PermissionSnapshot) PrimaryKey() string {
+	return p.Name
+}
+
+// Index return custom index
+func (p *PermissionSnapshot) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if p.Name != "" {
+		index["name"] = p.Name
+	}
+	if p.Username != "" {
+		index["username"] = p.Username
+	}
+	return index
+}
+
+// UserAvatar is the image uploaded by the user as the avatar, a new ID is generated for every upload
+type UserAvatar struct {
+	BaseModel
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	ContentType string `json:"contentType"`
+	Data        []byte `json:"data"`
+}
+
+// TableName return custom table name
+func (u *UserAvatar) TableName() string {
+	return tableNamePrefix + "user_avatar"
+}
+
+// ShortTableName return custom table name
+func (u *UserAvatar) ShortTableName() string {
+	return "usr_avt"
+}
+
+// PrimaryKey return custom primary key
+func (u *UserAvatar) PrimaryKey() string {
+	return u.ID
+}
+
+// Index return custom index
+func (u *UserAvatar) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if u.ID != "" {
+		index["id"] = u.ID
+	}
+	if u.Username != "" {
+		index["username"] = u.Username
+	}
+	return index
+}
@@ -42,6 +42,10 @@ const (
 	LabelSyncRevision = "ux.oam.dev/synced-revision"
 	// LabelSyncNamespace describes the namespace synced from
 	LabelSyncNamespace = "ux.oam.dev/from-namespace"
+	// LabelSyncManagedExternally describes the synced application could only be updated by its source
+	LabelSyncManagedExternally = "ux.oam.dev/managed-externally"
+	// LabelSyncCluster describes the managed cluster synced from, it is empty if the application is synced from the hub cluster
+	LabelSyncCluster = "ux.oam.dev/from-cluster"
 )
 
 const (
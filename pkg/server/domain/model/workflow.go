@@ -34,6 +34,10 @@ const Finished = "true"
 // UnFinished means the workflow record is not finished
 const UnFinished = "false"
 
+// AnnotationRequestID the ID of the API request that triggers the workflow, it is set to the application CR
+// so that the events of the controller could be traced back to the request
+const AnnotationRequestID = "ux.oam.dev/request-id"
+
 // Workflow application delivery database model
 type Workflow struct {
 	BaseModel
@@ -105,6 +109,11 @@ func (w *Workflow) Index() map[string]interface{} {
 	return index
 }
 
+// Indexes return custom indexes
+func (w *Workflow) Indexes() []string {
+	return []string{"appPrimaryKey"}
+}
+
 // WorkflowRecord is the workflow record database model
 type WorkflowRecord struct {
 	BaseModel
@@ -123,6 +132,33 @@ type WorkflowRecord struct {
 	Message            string               `json:"message"`
 	Mode               string               `json:"mode"`
 	ContextValue       map[string]string    `json:"contextValue,omitempty"`
+	// RequestID the ID of the API request that triggers the workflow
+	RequestID string `json:"requestID,omitempty"`
+	// BuildMetadata the metadata attached by the external CI system
+	BuildMetadata *BuildMetadata `json:"buildMetadata,omitempty"`
+	// Cluster the managed cluster running the workflow, it is empty if the workflow runs in the hub cluster
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// BuildMetadata is the external build metadata of the workflow record
+type BuildMetadata struct {
+	Provider    string            `json:"provider,omitempty"`
+	Commit      string            `json:"commit,omitempty"`
+	Branch      string            `json:"branch,omitempty"`
+	BuildURL    string            `json:"buildURL,omitempty"`
+	TestResults *BuildTestResults `json:"testResults,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	UpdateUser  string            `json:"updateUser,omitempty"`
+	UpdateTime  time.Time         `json:"updateTime"`
+}
+
+// BuildTestResults is the summary of the test results of the external build
+type BuildTestResults struct {
+	Total     int    `json:"total"`
+	Passed    int    `json:"passed"`
+	Failed    int    `json:"failed"`
+	Skipped   int    `json:"skipped"`
+	ReportURL string `json:"reportURL,omitempty"`
 }
 
 // WorkflowStepStatus is the workflow step status database model
@@ -168,6 +204,9 @@ func (w *WorkflowRecord) Index() map[string]interface{} {
 	if w.Namespace != "" {
 		index["namespace"] = w.Namespace
 	}
+	if w.Cluster != "" {
+		index["cluster"] = w.Cluster
+	}
 	if w.WorkflowName != "" {
 		index["workflowName"] = w.WorkflowName
 	}
@@ -185,3 +224,8 @@ func (w *WorkflowRecord) Index() map[string]interface{} {
 	}
 	return index
 }
+
+// Indexes return custom indexes
+func (w *WorkflowRecord) Indexes() []string {
+	return []string{"appPrimaryKey,-createTime"}
+}
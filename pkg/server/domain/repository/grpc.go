@@ -23,18 +23,21 @@ import (
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 )
 
-// ListRoles list roles from store
-func ListRoles(ctx context.Context, store datastore.DataStore, projectName string, page, pageSize int) ([]*model.Role, int64, error) {
+// ListRoles list roles from store, the roles are sorted by the create time in descending order if the sortBy is empty
+func ListRoles(ctx context.Context, store datastore.DataStore, projectName string, page, pageSize int, queries []datastore.FuzzyQueryOption, sortBy []datastore.SortOption) ([]*model.Role, int64, error) {
 	var role = model.Role{
 		Project: projectName,
 	}
-	var filter datastore.FilterOptions
+	if len(sortBy) == 0 {
+		sortBy = []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}
+	}
+	var filter = datastore.FilterOptions{Queries: queries}
 	if projectName == "" {
 		filter.IsNotExist = append(filter.IsNotExist, datastore.IsNotExistQueryOption{
 			Key: "project",
 		})
 	}
-	entities, err := store.List(ctx, &role, &datastore.ListOptions{FilterOptions: filter, Page: page, PageSize: pageSize, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	entities, err := store.List(ctx, &role, &datastore.ListOptions{FilterOptions: filter, Page: page, PageSize: pageSize, SortBy: sortBy})
 	if err != nil {
 		return nil, 0, err
 	}
@@ -50,6 +50,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/utils/schema"
 
 	"github.com/kubevela/velaux/pkg/server/infrastructure/clients"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
@@ -68,6 +69,11 @@ type AddonService interface {
 	DisableAddon(ctx context.Context, name string, force bool) error
 	ListEnabledAddon(ctx context.Context) ([]*apis.AddonBaseStatus, error)
 	UpdateAddon(ctx context.Context, name string, args apis.EnableAddonRequest) error
+	CreateAddonRollout(ctx context.Context, name string, req apis.CreateAddonRolloutRequest) (*apis.AddonRolloutBase, error)
+	ListAddonRollouts(ctx context.Context, name string) (*apis.ListAddonRolloutsResponse, error)
+	GetAddonRollout(ctx context.Context, name, rolloutName string) (*apis.AddonRolloutBase, error)
+	RetryAddonRollout(ctx context.Context, name, rolloutName string) (*apis.AddonRolloutBase, error)
+	ProcessAddonRollouts(ctx context.Context) error
 	Init(ctx context.Context) error
 }
 
@@ -116,6 +122,9 @@ func AddonImpl2AddonRes(impl *pkgaddon.UIData, config *rest.Config) (*apis.Detai
 	}, nil
 }
 
+// offlineModeCheckInterval is how often the registry cache refresh checks the offline mode
+const offlineModeCheckInterval = time.Minute
+
 // NewAddonService returns an addon service
 func NewAddonService(cacheTime time.Duration) AddonService {
 	dc, err := clients.GetDiscoveryClient()
@@ -134,23 +143,58 @@ type addonServiceImpl struct {
 	cacheTime          time.Duration
 	addonRegistryCache *pkgaddon.Cache
 	RegistryDS         pkgaddon.RegistryDataStore `inject:"registryDatastore"`
+	Store              datastore.DataStore        `inject:"datastore"`
 	KubeClient         client.Client              `inject:"kubeClient"`
 	KubeConfig         *rest.Config               `inject:"kubeConfig"`
 	Apply              apply.Applicator           `inject:"apply"`
+	SysService         SystemInfoService          `inject:""`
 	discoveryClient    *discovery.DiscoveryClient
 	mutex              *sync.RWMutex
 }
 
 func (u *addonServiceImpl) Init(ctx context.Context) error {
 	cache := pkgaddon.NewCache(u.RegistryDS)
-	// TODO(@wonderflow): it's better to add a close channel here, but it should be fine as it's only invoke once in APIServer.
-	go cache.DiscoverAndRefreshLoop(ctx, u.cacheTime)
+	go u.refreshRegistryCache(ctx, cache)
 	u.addonRegistryCache = cache
 	return nil
 }
 
+// refreshRegistryCache runs the cache refresh loop only while the offline mode is disabled
+func (u *addonServiceImpl) refreshRegistryCache(ctx context.Context, cache *pkgaddon.Cache) {
+	var cancel context.CancelFunc
+	check := func() {
+		offline := isOfflineMode(ctx, u.SysService)
+		if offline && cancel != nil {
+			cancel()
+			cancel = nil
+		}
+		if !offline && cancel == nil {
+			var loopCtx context.Context
+			loopCtx, cancel = context.WithCancel(ctx)
+			go cache.DiscoverAndRefreshLoop(loopCtx, u.cacheTime)
+		}
+	}
+	check()
+	ticker := time.NewTicker(offlineModeCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			check()
+		case <-ctx.Done():
+			if cancel != nil {
+				cancel()
+			}
+			return
+		}
+	}
+}
+
 // GetAddon will get addon information
 func (u *addonServiceImpl) GetAddon(ctx context.Context, name string, registry string, version string) (*apis.DetailAddonResponse, error) {
+	if isOfflineMode(ctx, u.SysService) {
+		return nil, bcode.ErrAddonRegistryOffline
+	}
 	var addon *pkgaddon.UIData
 	var err error
 	if registry == "" {
@@ -247,6 +291,9 @@ func (u *addonServiceImpl) StatusAddon(ctx context.Context, name string) (*apis.
 }
 
 func (u *addonServiceImpl) ListAddons(ctx context.Context, registry, query string) ([]*apis.DetailAddonResponse, error) {
+	if isOfflineMode(ctx, u.SysService) {
+		return nil, bcode.ErrAddonRegistryOffline
+	}
 	var addons []*pkgaddon.UIData
 	rs, err := u.RegistryDS.ListRegistries(ctx)
 	if err != nil {
@@ -388,6 +435,9 @@ func (u *addonServiceImpl) ListAddonRegistries(ctx context.Context) ([]*apis.Add
 }
 
 func (u *addonServiceImpl) EnableAddon(ctx context.Context, name string, args apis.EnableAddonRequest) error {
+	if isOfflineMode(ctx, u.SysService) {
+		return bcode.ErrAddonRegistryOffline
+	}
 	var err error
 	registries, err := u.RegistryDS.ListRegistries(ctx)
 	if err != nil {
@@ -463,6 +513,9 @@ func (u *addonServiceImpl) ListEnabledAddon(ctx context.Context) ([]*apis.AddonB
 }
 
 func (u *addonServiceImpl) UpdateAddon(ctx context.Context, name string, args apis.EnableAddonRequest) error {
+	if isOfflineMode(ctx, u.SysService) {
+		return bcode.ErrAddonRegistryOffline
+	}
 	var app v1beta1.Application
 	// check addon application whether exist
 	err := u.KubeClient.Get(ctx, client.ObjectKey{
@@ -0,0 +1,330 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	pkgaddon "github.com/oam-dev/kubevela/pkg/addon"
+	addonutil "github.com/oam-dev/kubevela/pkg/utils/addon"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// CreateAddonRollout selects the clusters and records the rollout, the clusters are processed by the addon rollout worker
+func (u *addonServiceImpl) CreateAddonRollout(ctx context.Context, name string, req apis.CreateAddonRolloutRequest) (*apis.AddonRolloutBase, error) {
+	if isOfflineMode(ctx, u.SysService) {
+		return nil, bcode.ErrAddonRegistryOffline
+	}
+	running, err := u.Store.Count(ctx, &model.AddonRollout{Addon: name, Phase: model.AddonRolloutPhaseRunning}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if running > 0 {
+		return nil, bcode.ErrAddonRolloutInProgress
+	}
+	clusters, err := u.selectRolloutClusters(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	args, baseClusters, err := u.getEnabledAddonArgs(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if req.Action == model.AddonRolloutActionUpgrade && args == nil {
+		return nil, bcode.ErrAddonNotEnabled
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	for k, v := range req.Args {
+		args[k] = v
+	}
+	delete(args, types.ClustersArg)
+	creator, _ := ctx.Value(&apis.CtxKeyUser).(string)
+	rollout := &model.AddonRollout{
+		Name:         utils.GenerateVersion(name) + "-" + rand.String(4),
+		Addon:        name,
+		Action:       req.Action,
+		Version:      req.Version,
+		RegistryName: req.RegistryName,
+		Args:         args,
+		BaseClusters: baseClusters,
+		Phase:        model.AddonRolloutPhaseRunning,
+		Creator:      creator,
+	}
+	for _, cluster := range clusters {
+		rollout.Clusters = append(rollout.Clusters, model.AddonRolloutCluster{Name: cluster, Phase: model.AddonRolloutPhasePending})
+	}
+	if err := u.Store.Add(ctx, rollout); err != nil {
+		return nil, err
+	}
+	return convertAddonRollout(rollout), nil
+}
+
+// ListAddonRollouts lists the rollouts of the addon, the latest first
+func (u *addonServiceImpl) ListAddonRollouts(ctx context.Context, name string) (*apis.ListAddonRolloutsResponse, error) {
+	entities, err := u.Store.List(ctx, &model.AddonRollout{Addon: name}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &apis.ListAddonRolloutsResponse{Rollouts: []*apis.AddonRolloutBase{}}
+	for _, entity := range entities {
+		resp.Rollouts = append(resp.Rollouts, convertAddonRollout(entity.(*model.AddonRollout)))
+	}
+	return resp, nil
+}
+
+// GetAddonRollout gets the rollout of the addon with the progress of each cluster
+func (u *addonServiceImpl) GetAddonRollout(ctx context.Context, name, rolloutName string) (*apis.AddonRolloutBase, error) {
+	rollout, err := u.getAddonRollout(ctx, name, rolloutName)
+	if err != nil {
+		return nil, err
+	}
+	return convertAddonRollout(rollout), nil
+}
+
+// RetryAddonRollout resets the failed clusters to pending, the succeeded clusters are not processed again
+func (u *addonServiceImpl) RetryAddonRollout(ctx context.Context, name, rolloutName string) (*apis.AddonRolloutBase, error) {
+	rollout, err := u.getAddonRollout(ctx, name, rolloutName)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.Phase == model.AddonRolloutPhaseRunning {
+		return nil, bcode.ErrAddonRolloutInProgress
+	}
+	if rollout.Phase != model.AddonRolloutPhaseFailed {
+		return nil, bcode.ErrAddonRolloutNotFailed
+	}
+	running, err := u.Store.Count(ctx, &model.AddonRollout{Addon: name, Phase: model.AddonRolloutPhaseRunning}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if running > 0 {
+		return nil, bcode.ErrAddonRolloutInProgress
+	}
+	for i := range rollout.Clusters {
+		if rollout.Clusters[i].Phase == model.AddonRolloutPhaseFailed {
+			rollout.Clusters[i].Phase = model.AddonRolloutPhasePending
+			rollout.Clusters[i].Message = ""
+			rollout.Clusters[i].UpdateTime = time.Now()
+		}
+	}
+	rollout.Phase = model.AddonRolloutPhaseRunning
+	if err := u.Store.Put(ctx, rollout); err != nil {
+		return nil, err
+	}
+	return convertAddonRollout(rollout), nil
+}
+
+// ProcessAddonRollouts processes the pending clusters of the running rollouts in the creation order
+func (u *addonServiceImpl) ProcessAddonRollouts(ctx context.Context) error {
+	entities, err := u.Store.List(ctx, &model.AddonRollout{Phase: model.AddonRolloutPhaseRunning}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		rollout := entity.(*model.AddonRollout)
+		if err := u.processAddonRollout(ctx, rollout); err != nil {
+			klog.Errorf("failed to process the addon rollout %s: %s", rollout.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// processAddonRollout adds the clusters to the addon one by one. A failed cluster is excluded from the following clusters,
+// so it does not block them. The progress is saved after each cluster, the interrupted cluster is processed again on resuming.
+func (u *addonServiceImpl) processAddonRollout(ctx context.Context, rollout *model.AddonRollout) error {
+	applied := append([]string{}, rollout.BaseClusters...)
+	for _, cluster := range rollout.Clusters {
+		if cluster.Phase == model.AddonRolloutPhaseSucceeded {
+			applied = appendCluster(applied, cluster.Name)
+		}
+	}
+	for i := range rollout.Clusters {
+		cluster := &rollout.Clusters[i]
+		if cluster.Phase == model.AddonRolloutPhaseSucceeded || cluster.Phase == model.AddonRolloutPhaseFailed {
+			continue
+		}
+		cluster.Phase, cluster.UpdateTime = model.AddonRolloutPhaseRunning, time.Now()
+		if err := u.Store.Put(ctx, rollout); err != nil {
+			return err
+		}
+		err := u.applyAddonRollout(ctx, rollout, appendCluster(applied, cluster.Name))
+		if errors.Is(err, bcode.ErrAddonRegistryOffline) {
+			// the registries are unreachable for all the clusters, resume after the offline mode is disabled
+			return err
+		}
+		cluster.UpdateTime = time.Now()
+		if err != nil {
+			cluster.Phase, cluster.Message = model.AddonRolloutPhaseFailed, err.Error()
+		} else {
+			cluster.Phase, cluster.Message = model.AddonRolloutPhaseSucceeded, ""
+			applied = appendCluster(applied, cluster.Name)
+		}
+		if err := u.Store.Put(ctx, rollout); err != nil {
+			return err
+		}
+	}
+	rollout.Phase = model.AddonRolloutPhaseSucceeded
+	for _, cluster := range rollout.Clusters {
+		if cluster.Phase == model.AddonRolloutPhaseFailed {
+			rollout.Phase = model.AddonRolloutPhaseFailed
+		}
+	}
+	return u.Store.Put(ctx, rollout)
+}
+
+func (u *addonServiceImpl) applyAddonRollout(ctx context.Context, rollout *model.AddonRollout, clusters []string) error {
+	args := make(map[string]interface{}, len(rollout.Args)+1)
+	for k, v := range rollout.Args {
+		args[k] = v
+	}
+	args[types.ClustersArg] = clusters
+	req := apis.EnableAddonRequest{Args: args, Clusters: clusters, Version: rollout.Version, RegistryName: rollout.RegistryName}
+	if rollout.Action == model.AddonRolloutActionUpgrade {
+		return u.UpdateAddon(ctx, rollout.Addon, req)
+	}
+	return u.EnableAddon(ctx, rollout.Addon, req)
+}
+
+// selectRolloutClusters merges the specified clusters and the clusters matching the selector, they must be managed by VelaUX
+func (u *addonServiceImpl) selectRolloutClusters(ctx context.Context, req apis.CreateAddonRolloutRequest) ([]string, error) {
+	entities, err := u.Store.List(ctx, &model.Cluster{}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*model.Cluster, len(entities))
+	for _, entity := range entities {
+		cluster := entity.(*model.Cluster)
+		existing[cluster.Name] = cluster
+	}
+	var selected []string
+	for _, name := range req.Clusters {
+		if _, ok := existing[name]; !ok {
+			return nil, bcode.ErrClusterNotFoundInDataStore
+		}
+		selected = appendCluster(selected, name)
+	}
+	if len(req.ClusterSelector) > 0 {
+		for _, entity := range entities {
+			cluster := entity.(*model.Cluster)
+			if matchClusterLabels(cluster.Labels, req.ClusterSelector) {
+				selected = appendCluster(selected, cluster.Name)
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil, bcode.ErrAddonRolloutNoCluster
+	}
+	return selected, nil
+}
+
+// getEnabledAddonArgs returns the args and the clusters of the enabled addon, the args is nil if the addon is not enabled
+func (u *addonServiceImpl) getEnabledAddonArgs(ctx context.Context, name string) (map[string]interface{}, []string, error) {
+	status, err := pkgaddon.GetAddonStatus(ctx, u.KubeClient, name)
+	if err != nil {
+		return nil, nil, bcode.ErrGetAddonApplication
+	}
+	if status.AddonPhase == string(apis.AddonPhaseDisabled) {
+		return nil, nil, nil
+	}
+	args := map[string]interface{}{}
+	var sec v1.Secret
+	if err := u.KubeClient.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: addonutil.Addon2SecName(name)}, &sec); err != nil {
+		if !errors2.IsNotFound(err) {
+			return nil, nil, bcode.ErrAddonSecretGet
+		}
+		return args, nil, nil
+	}
+	if args, err = pkgaddon.FetchArgsFromSecret(&sec); err != nil {
+		return nil, nil, err
+	}
+	var clusters []string
+	if list, ok := args[types.ClustersArg].([]interface{}); ok {
+		for _, c := range list {
+			if cluster, ok := c.(string); ok {
+				clusters = append(clusters, cluster)
+			}
+		}
+	}
+	return args, clusters, nil
+}
+
+func (u *addonServiceImpl) getAddonRollout(ctx context.Context, name, rolloutName string) (*model.AddonRollout, error) {
+	rollout := &model.AddonRollout{Name: rolloutName}
+	if err := u.Store.Get(ctx, rollout); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrAddonRolloutNotExist
+		}
+		return nil, err
+	}
+	if rollout.Addon != name {
+		return nil, bcode.ErrAddonRolloutNotExist
+	}
+	return rollout, nil
+}
+
+func matchClusterLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func appendCluster(clusters []string, cluster string) []string {
+	for _, c := range clusters {
+		if c == cluster {
+			return clusters
+		}
+	}
+	return append(clusters, cluster)
+}
+
+func convertAddonRollout(rollout *model.AddonRollout) *apis.AddonRolloutBase {
+	return &apis.AddonRolloutBase{
+		Name:         rollout.Name,
+		Addon:        rollout.Addon,
+		Action:       rollout.Action,
+		Version:      rollout.Version,
+		RegistryName: rollout.RegistryName,
+		Clusters:     rollout.Clusters,
+		Phase:        rollout.Phase,
+		Creator:      rollout.Creator,
+		CreateTime:   rollout.CreateTime,
+		UpdateTime:   rollout.UpdateTime,
+	}
+}
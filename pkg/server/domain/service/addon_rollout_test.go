@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+func TestMatchClusterLabels(t *testing.T) {
+	labels := map[string]string{"region": "east", "env": "prod"}
+	assert.True(t, matchClusterLabels(labels, map[string]string{"region": "east"}))
+	assert.True(t, matchClusterLabels(labels, map[string]string{}))
+	assert.False(t, matchClusterLabels(labels, map[string]string{"region": "west"}))
+	assert.False(t, matchClusterLabels(nil, map[string]string{"region": "east"}))
+	assert.Equal(t, []string{"a", "b"}, appendCluster(appendCluster([]string{"a"}, "b"), "a"))
+}
+
+var _ = Describe("Test the addon rollout", func() {
+	var (
+		ds           datastore.DataStore
+		addonService *addonServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "addon-rollout-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		addonService = &addonServiceImpl{Store: ds, KubeClient: k8sClient, SysService: &systemInfoServiceImpl{Store: ds}}
+		Expect(ds.Add(context.TODO(), &model.Cluster{Name: "east-1", Labels: map[string]string{"region": "east"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Cluster{Name: "east-2", Labels: map[string]string{"region": "east"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Cluster{Name: "west-1", Labels: map[string]string{"region": "west"}})).Should(BeNil())
+	})
+
+	It("Test create and retry the rollout", func() {
+		ctx := context.WithValue(context.TODO(), &apis.CtxKeyUser, "admin")
+		_, err := addonService.CreateAddonRollout(ctx, "rollout-addon", apis.CreateAddonRolloutRequest{Action: model.AddonRolloutActionEnable, Clusters: []string{"not-exist"}})
+		Expect(err).Should(Equal(bcode.ErrClusterNotFoundInDataStore))
+		_, err = addonService.CreateAddonRollout(ctx, "rollout-addon", apis.CreateAddonRolloutRequest{Action: model.AddonRolloutActionEnable, ClusterSelector: map[string]string{"region": "north"}})
+		Expect(err).Should(Equal(bcode.ErrAddonRolloutNoCluster))
+		_, err = addonService.CreateAddonRollout(ctx, "rollout-addon", apis.CreateAddonRolloutRequest{Action: model.AddonRolloutActionUpgrade, Clusters: []string{"west-1"}})
+		Expect(err).Should(Equal(bcode.ErrAddonNotEnabled))
+
+		rollout, err := addonService.CreateAddonRollout(ctx, "rollout-addon", apis.CreateAddonRolloutRequest{
+			Action:          model.AddonRolloutActionEnable,
+			Clusters:        []string{"west-1"},
+			ClusterSelector: map[string]string{"region": "east"},
+			Args:            map[string]interface{}{"replicas": 2},
+		})
+		Expect(err).Should(BeNil())
+		Expect(rollout.Phase).Should(Equal(model.AddonRolloutPhaseRunning))
+		Expect(rollout.Creator).Should(Equal("admin"))
+		var clusters []string
+		for _, c := range rollout.Clusters {
+			Expect(c.Phase).Should(Equal(model.AddonRolloutPhasePending))
+			clusters = append(clusters, c.Name)
+		}
+		Expect(clusters).Should(Equal([]string{"west-1", "east-1", "east-2"}))
+
+		_, err = addonService.CreateAddonRollout(ctx, "rollout-addon", apis.CreateAddonRolloutRequest{Action: model.AddonRolloutActionEnable, Clusters: []string{"west-1"}})
+		Expect(err).Should(Equal(bcode.ErrAddonRolloutInProgress))
+		_, err = addonService.RetryAddonRollout(ctx, "rollout-addon", rollout.Name)
+		Expect(err).Should(Equal(bcode.ErrAddonRolloutInProgress))
+
+		By("only the failed clusters are retried")
+		record := &model.AddonRollout{Name: rollout.Name}
+		Expect(ds.Get(ctx, record)).Should(BeNil())
+		record.Clusters[0].Phase = model.AddonRolloutPhaseSucceeded
+		record.Clusters[1].Phase, record.Clusters[1].Message = model.AddonRolloutPhaseFailed, "unreachable"
+		record.Clusters[2].Phase = model.AddonRolloutPhaseSucceeded
+		record.Phase = model.AddonRolloutPhaseFailed
+		Expect(ds.Put(ctx, record)).Should(BeNil())
+		retried, err := addonService.RetryAddonRollout(ctx, "rollout-addon", rollout.Name)
+		Expect(err).Should(BeNil())
+		Expect(retried.Phase).Should(Equal(model.AddonRolloutPhaseRunning))
+		Expect(retried.Clusters[0].Phase).Should(Equal(model.AddonRolloutPhaseSucceeded))
+		Expect(retried.Clusters[1].Phase).Should(Equal(model.AddonRolloutPhasePending))
+		Expect(retried.Clusters[1].Message).Should(BeEmpty())
+
+		_, err = addonService.GetAddonRollout(ctx, "other-addon", rollout.Name)
+		Expect(err).Should(Equal(bcode.ErrAddonRolloutNotExist))
+		list, err := addonService.ListAddonRollouts(ctx, "rollout-addon")
+		Expect(err).Should(BeNil())
+		Expect(len(list.Rollouts)).Should(Equal(1))
+	})
+})
@@ -0,0 +1,237 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/securityevent"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	apiutils "github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// APITokenPrefix is the prefix of the personal API tokens, it distinguishes them from the JWT tokens
+	APITokenPrefix = "velaux_"
+	// defaultAPITokenExpireDays how many days the API token is valid if it is not specified
+	defaultAPITokenExpireDays = 30
+)
+
+// apiTokenAuthenticator verifies the API tokens for the auth filter, it is set when the service is initialized
+var apiTokenAuthenticator *apiTokenServiceImpl
+
+// APITokenService manages the personal API tokens of the login user
+type APITokenService interface {
+	CreateAPIToken(ctx context.Context, req apisv1.CreateAPITokenRequest) (*apisv1.CreateAPITokenResponse, error)
+	ListAPITokens(ctx context.Context) (*apisv1.ListAPITokensResponse, error)
+	RevokeAPIToken(ctx context.Context, name string) error
+	Init(ctx context.Context) error
+}
+
+type apiTokenServiceImpl struct {
+	Store datastore.DataStore `inject:"datastore"`
+	// loginRecorded the tokens whose logins are recorded in the interval
+	loginRecorded *apiutils.LRUCache
+}
+
+// NewAPITokenService new API token service
+func NewAPITokenService() APITokenService {
+	return &apiTokenServiceImpl{loginRecorded: apiutils.NewLRUCache(1024, apiTokenLoginInterval)}
+}
+
+// Init cleans the expired tokens and enables the API token authentication
+func (a *apiTokenServiceImpl) Init(ctx context.Context) error {
+	tokens, err := a.Store.List(ctx, &model.APIToken{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, v := range tokens {
+		token := v.(*model.APIToken)
+		if time.Now().After(token.ExpireTime) {
+			if err := a.Store.Delete(ctx, token); err != nil {
+				klog.Warningf("failed to delete the expired API token %s: %s", token.Name, err.Error())
+			}
+		}
+	}
+	apiTokenAuthenticator = a
+	return nil
+}
+
+// CreateAPIToken creates a token for the login user, the token is only returned in the response
+func (a *apiTokenServiceImpl) CreateAPIToken(ctx context.Context, req apisv1.CreateAPITokenRequest) (*apisv1.CreateAPITokenResponse, error) {
+	username, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return nil, bcode.ErrUnauthorized
+	}
+	if tokenValue, _ := ctx.Value(&apisv1.CtxKeyToken).(string); IsAPIToken(tokenValue) {
+		return nil, bcode.ErrAPITokenNotAllowed
+	}
+	exist, err := a.Store.List(ctx, &model.APIToken{Username: username, Name: req.Name}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(exist) > 0 {
+		return nil, bcode.ErrAPITokenExist
+	}
+	id, err := generateAPITokenID()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	expireDays := req.ExpireDays
+	if expireDays == 0 {
+		expireDays = defaultAPITokenExpireDays
+	}
+	token := &model.APIToken{
+		ID:         id,
+		Name:       req.Name,
+		Username:   username,
+		SecretHash: hashSecretToken(secret),
+		ExpireTime: time.Now().AddDate(0, 0, expireDays),
+	}
+	if err := a.Store.Add(ctx, token); err != nil {
+		return nil, err
+	}
+	emitSecurityEvent(ctx, securityevent.Event{Type: securityevent.TypeAPITokenCreate, Outcome: securityevent.OutcomeSuccess, Username: username, Details: map[string]string{
+		"tokenID":    token.ID,
+		"tokenName":  token.Name,
+		"expireTime": token.ExpireTime.Format(time.RFC3339),
+	}})
+	return &apisv1.CreateAPITokenResponse{
+		APITokenBase: *convertAPITokenBase(token),
+		Token:        APITokenPrefix + id + "_" + secret,
+	}, nil
+}
+
+// ListAPITokens lists the tokens of the login user
+func (a *apiTokenServiceImpl) ListAPITokens(ctx context.Context) (*apisv1.ListAPITokensResponse, error) {
+	username, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return nil, bcode.ErrUnauthorized
+	}
+	entities, err := a.Store.List(ctx, &model.APIToken{Username: username}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListAPITokensResponse{Tokens: []*apisv1.APITokenBase{}}
+	for _, entity := range entities {
+		res.Tokens = append(res.Tokens, convertAPITokenBase(entity.(*model.APIToken)))
+	}
+	return res, nil
+}
+
+// RevokeAPIToken deletes the token of the login user
+func (a *apiTokenServiceImpl) RevokeAPIToken(ctx context.Context, name string) error {
+	username, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return bcode.ErrUnauthorized
+	}
+	entities, err := a.Store.List(ctx, &model.APIToken{Username: username, Name: name}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	if len(entities) == 0 {
+		return bcode.ErrAPITokenNotExist
+	}
+	for _, entity := range entities {
+		if err := a.Store.Delete(ctx, entity); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// authenticate verifies the token and returns the name of the user who owns it
+func (a *apiTokenServiceImpl) authenticate(ctx context.Context, tokenValue string) (string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(tokenValue, APITokenPrefix), "_", 2)
+	if len(parts) != 2 {
+		return "", bcode.ErrAPITokenInvalid
+	}
+	token := &model.APIToken{ID: parts[0]}
+	if err := a.Store.Get(ctx, token); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return "", bcode.ErrAPITokenInvalid
+		}
+		return "", err
+	}
+	if subtle.ConstantTimeCompare([]byte(token.SecretHash), []byte(hashSecretToken(parts[1]))) != 1 {
+		return "", bcode.ErrAPITokenInvalid
+	}
+	if time.Now().After(token.ExpireTime) {
+		return "", bcode.ErrAPITokenExpired
+	}
+	user := &model.User{Name: token.Username}
+	if err := a.Store.Get(ctx, user); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return "", bcode.ErrAPITokenInvalid
+		}
+		return "", err
+	}
+	if user.Disabled {
+		return "", bcode.ErrUserAlreadyDisabled
+	}
+	if _, recorded := a.loginRecorded.Get(token.ID); !recorded {
+		a.loginRecorded.Put(token.ID, true)
+		recordLogin(ctx, a.Store, user.Name, model.LoginMethodAPIToken)
+	}
+	return user.Name, nil
+}
+
+// IsAPIToken checks whether the token is a personal API token
+func IsAPIToken(tokenValue string) bool {
+	return strings.HasPrefix(tokenValue, APITokenPrefix)
+}
+
+// ParseAPIToken verifies the personal API token and returns the name of the user who owns it
+func ParseAPIToken(ctx context.Context, tokenValue string) (string, error) {
+	if apiTokenAuthenticator == nil {
+		return "", bcode.ErrAPITokenInvalid
+	}
+	return apiTokenAuthenticator.authenticate(ctx, tokenValue)
+}
+
+func generateAPITokenID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func convertAPITokenBase(token *model.APIToken) *apisv1.APITokenBase {
+	return &apisv1.APITokenBase{
+		Name:       token.Name,
+		CreateTime: token.CreateTime,
+		ExpireTime: token.ExpireTime,
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the personal API tokens", func() {
+	var (
+		ds           datastore.DataStore
+		tokenService *apiTokenServiceImpl
+		ctx          context.Context
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "api-token-test-kubevela"})
+		Expect(err).Should(BeNil())
+		tokenService = &apiTokenServiceImpl{Store: ds}
+		Expect(tokenService.Init(context.TODO())).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.User{Name: "automation", Email: "automation@example.com"})).Should(BeNil())
+		ctx = context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "automation")
+	})
+	AfterEach(func() {
+		Expect(ds.Delete(context.TODO(), &model.User{Name: "automation"})).Should(BeNil())
+	})
+
+	It("Test create, use and revoke the token", func() {
+		created, err := tokenService.CreateAPIToken(ctx, apisv1.CreateAPITokenRequest{Name: "ci"})
+		Expect(err).Should(BeNil())
+		Expect(IsAPIToken(created.Token)).Should(BeTrue())
+		Expect(created.ExpireTime.After(time.Now().AddDate(0, 0, 29))).Should(BeTrue())
+		_, err = tokenService.CreateAPIToken(ctx, apisv1.CreateAPITokenRequest{Name: "ci"})
+		Expect(err).Should(Equal(bcode.ErrAPITokenExist))
+
+		// the secret is not stored
+		tokens, err := ds.List(context.TODO(), &model.APIToken{Username: "automation"}, &datastore.ListOptions{})
+		Expect(err).Should(BeNil())
+		Expect(len(tokens)).Should(Equal(1))
+		Expect(created.Token).ShouldNot(ContainSubstring(tokens[0].(*model.APIToken).SecretHash))
+
+		username, err := ParseAPIToken(context.TODO(), created.Token)
+		Expect(err).Should(BeNil())
+		Expect(username).Should(Equal("automation"))
+		_, err = ParseAPIToken(context.TODO(), created.Token+"x")
+		Expect(err).Should(Equal(bcode.ErrAPITokenInvalid))
+
+		// the token could not create another token
+		_, err = tokenService.CreateAPIToken(context.WithValue(ctx, &apisv1.CtxKeyToken, created.Token), apisv1.CreateAPITokenRequest{Name: "nested"})
+		Expect(err).Should(Equal(bcode.ErrAPITokenNotAllowed))
+
+		list, err := tokenService.ListAPITokens(ctx)
+		Expect(err).Should(BeNil())
+		Expect(len(list.Tokens)).Should(Equal(1))
+		Expect(list.Tokens[0].Name).Should(Equal("ci"))
+
+		Expect(tokenService.RevokeAPIToken(ctx, "ci")).Should(BeNil())
+		Expect(tokenService.RevokeAPIToken(ctx, "ci")).Should(Equal(bcode.ErrAPITokenNotExist))
+		_, err = ParseAPIToken(context.TODO(), created.Token)
+		Expect(err).Should(Equal(bcode.ErrAPITokenInvalid))
+	})
+
+	It("Test reject the expired token and the disabled user", func() {
+		created, err := tokenService.CreateAPIToken(ctx, apisv1.CreateAPITokenRequest{Name: "expired", ExpireDays: 1})
+		Expect(err).Should(BeNil())
+		tokens, err := ds.List(context.TODO(), &model.APIToken{Username: "automation", Name: "expired"}, &datastore.ListOptions{})
+		Expect(err).Should(BeNil())
+		token := tokens[0].(*model.APIToken)
+		token.ExpireTime = time.Now().Add(-time.Minute)
+		Expect(ds.Put(context.TODO(), token)).Should(BeNil())
+		_, err = ParseAPIToken(context.TODO(), created.Token)
+		Expect(err).Should(Equal(bcode.ErrAPITokenExpired))
+		Expect(tokenService.Init(context.TODO())).Should(BeNil())
+		_, err = ParseAPIToken(context.TODO(), created.Token)
+		Expect(err).Should(Equal(bcode.ErrAPITokenInvalid))
+
+		created, err = tokenService.CreateAPIToken(ctx, apisv1.CreateAPITokenRequest{Name: "disabled"})
+		Expect(err).Should(BeNil())
+		Expect(ds.Put(context.TODO(), &model.User{Name: "automation", Disabled: true})).Should(BeNil())
+		_, err = ParseAPIToken(context.TODO(), created.Token)
+		Expect(err).Should(Equal(bcode.ErrUserAlreadyDisabled))
+		Expect(tokenService.RevokeAPIToken(ctx, "disabled")).Should(BeNil())
+	})
+})
@@ -19,6 +19,7 @@ package service
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -27,6 +28,7 @@ import (
 	"time"
 
 	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,6 +43,7 @@ import (
 	velatypes "github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	"github.com/oam-dev/kubevela/pkg/appfile/dryrun"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	pkgUtils "github.com/oam-dev/kubevela/pkg/utils"
@@ -51,7 +54,9 @@ import (
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/domain/repository"
 	"github.com/kubevela/velaux/pkg/server/event/sync/convert"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/changefeed"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
 	assembler "github.com/kubevela/velaux/pkg/server/interfaces/api/assembler/v1"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	"github.com/kubevela/velaux/pkg/server/utils"
@@ -70,14 +75,24 @@ type ApplicationService interface {
 	ListApplications(ctx context.Context, listOptions apisv1.ListApplicationOptions) ([]*apisv1.ApplicationBase, error)
 	GetApplication(ctx context.Context, appName string) (*model.Application, error)
 	GetApplicationStatus(ctx context.Context, app *model.Application, envName string) (*common.AppStatus, error)
+	GetChangeReport(ctx context.Context, app *model.Application, envName string) (*apisv1.ApplicationChangeReport, error)
+	ScaleApplication(ctx context.Context, app *model.Application, envName string, req apisv1.ScaleApplicationRequest) (*apisv1.ApplicationDeployResponse, error)
+	ListApplicationConflicts(ctx context.Context) (*apisv1.ListApplicationConflictsResponse, error)
+	AcceptApplicationConflict(ctx context.Context, app *model.Application, envName string) error
+	OverwriteApplicationConflict(ctx context.Context, app *model.Application, envName string) error
 	DetailApplication(ctx context.Context, app *model.Application) (*apisv1.DetailApplicationResponse, error)
 	PublishApplicationTemplate(ctx context.Context, app *model.Application) (*apisv1.ApplicationTemplateBase, error)
 	CreateApplication(context.Context, apisv1.CreateApplicationRequest) (*apisv1.ApplicationBase, error)
+	ListUnmanagedWorkloads(ctx context.Context, projectName, targetName string) (*apisv1.ListWorkloadsResponse, error)
+	ImportWorkloads(ctx context.Context, projectName, targetName string, req apisv1.ImportWorkloadsRequest) (*apisv1.ApplicationBase, error)
 	UpdateApplication(context.Context, *model.Application, apisv1.UpdateApplicationRequest) (*apisv1.ApplicationBase, error)
 	DeleteApplication(ctx context.Context, app *model.Application) error
+	SetApplicationNotice(ctx context.Context, app *model.Application, req apisv1.UpdateApplicationNoticeRequest) (*model.ApplicationNotice, error)
+	DeleteApplicationNotice(ctx context.Context, app *model.Application) error
 	Deploy(ctx context.Context, app *model.Application, req apisv1.ApplicationDeployRequest) (*apisv1.ApplicationDeployResponse, error)
 	GetApplicationComponent(ctx context.Context, app *model.Application, componentName string) (*model.ApplicationComponent, error)
 	ListComponents(ctx context.Context, app *model.Application, op apisv1.ListApplicationComponentOptions) ([]*apisv1.ComponentBase, error)
+	LintApplication(ctx context.Context, app *model.Application) (*apisv1.ApplicationLintResponse, error)
 	CreateComponent(ctx context.Context, app *model.Application, com apisv1.CreateComponentRequest) (*apisv1.ComponentBase, error)
 	DetailComponent(ctx context.Context, app *model.Application, componentName string) (*apisv1.DetailComponentResponse, error)
 	DeleteComponent(ctx context.Context, app *model.Application, component *model.ApplicationComponent) error
@@ -102,6 +117,7 @@ type ApplicationService interface {
 	ListApplicationTriggers(ctx context.Context, app *model.Application) ([]*apisv1.ApplicationTriggerBase, error)
 	DeleteApplicationTrigger(ctx context.Context, app *model.Application, triggerName string) error
 	UpdateApplicationTrigger(ctx context.Context, app *model.Application, token string, req apisv1.UpdateApplicationTriggerRequest) (*apisv1.ApplicationTriggerBase, error)
+	PreviewApplicationDefaults(ctx context.Context, projectName string, req apisv1.PreviewApplicationDefaultsRequest) (*apisv1.PreviewApplicationDefaultsResponse, error)
 }
 
 type applicationServiceImpl struct {
@@ -116,6 +132,7 @@ type applicationServiceImpl struct {
 	DefinitionService DefinitionService   `inject:""`
 	ProjectService    ProjectService      `inject:""`
 	UserService       UserService         `inject:""`
+	ChangeFeed        *changefeed.Feed    `inject:"changeFeed"`
 }
 
 // NewApplicationService new application service
@@ -353,12 +370,17 @@ func (c *applicationServiceImpl) PublishApplicationTemplate(ctx context.Context,
 
 // CreateApplication create application
 func (c *applicationServiceImpl) CreateApplication(ctx context.Context, req apisv1.CreateApplicationRequest) (*apisv1.ApplicationBase, error) {
+	ownership, err := checkOwnership(req.Ownership)
+	if err != nil {
+		return nil, err
+	}
 	application := model.Application{
 		Name:        req.Name,
 		Alias:       req.Alias,
 		Description: req.Description,
 		Icon:        req.Icon,
 		Labels:      req.Labels,
+		Ownership:   ownership,
 	}
 	// check app name.
 	exist, err := c.Store.IsExist(ctx, &application)
@@ -375,37 +397,48 @@ func (c *applicationServiceImpl) CreateApplication(ctx context.Context, req apis
 		return nil, bcode.ErrProjectIsNotExist
 	}
 	application.Project = project.Name
-
-	if req.Component != nil {
-		_, err = c.createComponent(ctx, &application, *req.Component, true)
-		if err != nil {
-			return nil, err
-		}
+	projectModel, err := c.ProjectService.GetProject(ctx, project.Name)
+	if err != nil {
+		return nil, err
 	}
+	application.Labels = mergeDefaultLabels(projectModel.ApplicationDefaults, application.Labels)
 
-	// build-in create env binding, it must after component added
-	if len(req.EnvBinding) > 0 {
-		err := c.saveApplicationEnvBinding(ctx, application, req.EnvBinding)
-		if err != nil {
-			return nil, err
+	// the application is created with its component, env bindings, workflows and trigger, or none of them
+	if err := datastore.InTransaction(ctx, c.Store, func(ctx context.Context) error {
+		if req.Component != nil {
+			if _, err := c.createComponent(ctx, &application, *req.Component, true, projectModel.ApplicationDefaults); err != nil {
+				return err
+			}
 		}
-		// For the custom payload, no need assign the component name
-		if _, err := c.CreateApplicationTrigger(ctx, &application, apisv1.CreateApplicationTriggerRequest{
-			Name:         fmt.Sprintf("%s-%s", application.Name, "default"),
-			PayloadType:  model.PayloadTypeCustom,
-			Type:         apisv1.TriggerTypeWebhook,
-			WorkflowName: repository.ConvertWorkflowName(req.EnvBinding[0].Name),
-		}); err != nil {
-			return nil, err
+
+		// build-in create env binding, it must after component added
+		if len(req.EnvBinding) > 0 {
+			if err := c.saveApplicationEnvBinding(ctx, application, req.EnvBinding); err != nil {
+				return err
+			}
+			// For the custom payload, no need assign the component name
+			if _, err := c.CreateApplicationTrigger(ctx, &application, apisv1.CreateApplicationTriggerRequest{
+				Name:         fmt.Sprintf("%s-%s", application.Name, "default"),
+				PayloadType:  model.PayloadTypeCustom,
+				Type:         apisv1.TriggerTypeWebhook,
+				WorkflowName: repository.ConvertWorkflowName(req.EnvBinding[0].Name),
+			}); err != nil {
+				return err
+			}
 		}
-	}
-	// add application to db.
-	if err := c.Store.Add(ctx, &application); err != nil {
-		if errors.Is(err, datastore.ErrRecordExist) {
-			return nil, bcode.ErrApplicationExist
+		// add application to db.
+		if err := c.Store.Add(ctx, &application); err != nil {
+			if errors.Is(err, datastore.ErrRecordExist) {
+				return bcode.ErrApplicationExist
+			}
+			return err
 		}
+		return nil
+	}); err != nil {
 		return nil, err
 	}
+	warnProjectQuotaUsage(ctx, c.Store, project.Name)
+	c.publishApplicationChange(ctx, &application, changefeed.ActionCreate)
 	// render app base info.
 	base := assembler.ConvertAppModelToBase(&application, []*apisv1.ProjectBase{project})
 	return base, nil
@@ -424,6 +457,9 @@ func (c *applicationServiceImpl) CreateApplicationTrigger(ctx context.Context, a
 			return nil, err
 		}
 	}
+	if err := checkWebhookSecret(req.Secret); err != nil {
+		return nil, err
+	}
 
 	trigger := &model.ApplicationTrigger{
 		AppPrimaryKey: app.Name,
@@ -436,6 +472,7 @@ func (c *applicationServiceImpl) CreateApplicationTrigger(ctx context.Context, a
 		ComponentName: req.ComponentName,
 		Registry:      req.Registry,
 		Token:         genWebhookToken(),
+		Secret:        req.Secret,
 	}
 	if err := c.Store.Add(ctx, trigger); err != nil {
 		klog.Errorf("failed to create application trigger, %s", err.Error())
@@ -491,6 +528,12 @@ func (c *applicationServiceImpl) UpdateApplicationTrigger(ctx context.Context, a
 	trigger.WorkflowName = req.WorkflowName
 	trigger.Registry = req.Registry
 	trigger.PayloadType = req.PayloadType
+	if req.Secret != nil {
+		if err := checkWebhookSecret(*req.Secret); err != nil {
+			return nil, err
+		}
+		trigger.Secret = *req.Secret
+	}
 	if err := c.Store.Put(ctx, &trigger); err != nil {
 		return nil, err
 	}
@@ -515,16 +558,17 @@ func (c *applicationServiceImpl) ListApplicationTriggers(ctx context.Context, ap
 		trigger, ok := raw.(*model.ApplicationTrigger)
 		if ok {
 			resp = append(resp, &apisv1.ApplicationTriggerBase{
-				WorkflowName:  trigger.WorkflowName,
-				Name:          trigger.Name,
-				Alias:         trigger.Alias,
-				Description:   trigger.Description,
-				Type:          trigger.Type,
-				PayloadType:   trigger.PayloadType,
-				Token:         trigger.Token,
-				UpdateTime:    trigger.UpdateTime,
-				CreateTime:    trigger.CreateTime,
-				ComponentName: trigger.ComponentName,
+				WorkflowName:      trigger.WorkflowName,
+				Name:              trigger.Name,
+				Alias:             trigger.Alias,
+				Description:       trigger.Description,
+				Type:              trigger.Type,
+				PayloadType:       trigger.PayloadType,
+				Token:             trigger.Token,
+				UpdateTime:        trigger.UpdateTime,
+				CreateTime:        trigger.CreateTime,
+				ComponentName:     trigger.ComponentName,
+				SignatureRequired: trigger.Secret != "",
 			})
 		}
 	}
@@ -548,8 +592,16 @@ func (c *applicationServiceImpl) UpdateApplication(ctx context.Context, app *mod
 			return nil, bcode.ErrProjectIsNotExist
 		}
 	}
+	ownership, err := checkOwnership(req.Ownership)
+	if err != nil {
+		return nil, err
+	}
+	before := specSnapshot(app)
 	app.Alias = req.Alias
 	app.Description = req.Description
+	if req.Ownership != nil {
+		app.Ownership = ownership
+	}
 
 	// Some built-in labels can not be updated
 	if app.Labels != nil && req.Labels != nil {
@@ -570,9 +622,35 @@ func (c *applicationServiceImpl) UpdateApplication(ctx context.Context, app *mod
 	if err := c.Store.Put(ctx, app); err != nil {
 		return nil, err
 	}
+	recordSpecAudit(ctx, c.Store, &model.SpecAudit{Resource: SpecAuditResourceApplication, Project: app.Project, Entity: app.Name}, before, specSnapshot(app))
+	c.publishApplicationChange(ctx, app, changefeed.ActionUpdate)
 	return assembler.ConvertAppModelToBase(app, []*apisv1.ProjectBase{project}), nil
 }
 
+// SetApplicationNotice attaches the notice to the application, the previous one is replaced
+func (c *applicationServiceImpl) SetApplicationNotice(ctx context.Context, app *model.Application, req apisv1.UpdateApplicationNoticeRequest) (*model.ApplicationNotice, error) {
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	app.Notice = &model.ApplicationNotice{
+		Message:    req.Message,
+		Level:      req.Level,
+		Author:     userName,
+		CreateTime: time.Now(),
+	}
+	if err := c.Store.Put(ctx, app); err != nil {
+		return nil, err
+	}
+	return app.Notice, nil
+}
+
+// DeleteApplicationNotice removes the notice of the application
+func (c *applicationServiceImpl) DeleteApplicationNotice(ctx context.Context, app *model.Application) error {
+	if app.Notice == nil {
+		return bcode.ErrApplicationNoticeNotExist
+	}
+	app.Notice = nil
+	return c.Store.Put(ctx, app)
+}
+
 // ListRecords list application record
 func (c *applicationServiceImpl) ListRecords(ctx context.Context, appName string) (*apisv1.ListWorkflowRecordsResponse, error) {
 	var record = model.WorkflowRecord{
@@ -702,6 +780,16 @@ func (c *applicationServiceImpl) Deploy(ctx context.Context, app *model.Applicat
 		}
 	}
 
+	// the blocking notice must be acknowledged explicitly, the warning notice is only surfaced in the response
+	var acknowledgedNotice string
+	if app.Notice != nil && app.Notice.Level == model.ApplicationNoticeBlocking {
+		if !req.AcknowledgeNotice {
+			return nil, bcode.ErrApplicationNoticeNotAcknowledged.SetMessage(
+				fmt.Sprintf("the application has a blocking notice: %s, acknowledge it to deploy", app.Notice.Message))
+		}
+		acknowledgedNotice = app.Notice.Message
+	}
+
 	// TODO: rollback to handle all the error case
 	// step1: Render oam application
 	version := utils.GenerateVersion("")
@@ -753,16 +841,18 @@ func (c *applicationServiceImpl) Deploy(ctx context.Context, app *model.Applicat
 		AppPrimaryKey: app.PrimaryKey(),
 		Version:       version,
 		// Setting it when syncing the workflow status
-		RevisionCRName: "",
-		ApplyAppConfig: string(configByte),
-		Status:         model.RevisionStatusInit,
-		DeployUser:     userName,
-		Note:           req.Note,
-		TriggerType:    req.TriggerType,
-		WorkflowName:   oamApp.Annotations[oam.AnnotationWorkflowName],
-		EnvName:        workflow.EnvName,
-		CodeInfo:       req.CodeInfo,
-		ImageInfo:      req.ImageInfo,
+		RevisionCRName:     "",
+		ApplyAppConfig:     string(configByte),
+		Status:             model.RevisionStatusInit,
+		DeployUser:         userName,
+		Note:               req.Note,
+		TriggerType:        req.TriggerType,
+		WorkflowName:       oamApp.Annotations[oam.AnnotationWorkflowName],
+		EnvName:            workflow.EnvName,
+		CodeInfo:           req.CodeInfo,
+		ImageInfo:          req.ImageInfo,
+		RequestID:          oamApp.Annotations[model.AnnotationRequestID],
+		AcknowledgedNotice: acknowledgedNotice,
 	}
 	if err := c.Store.Add(ctx, appRevision); err != nil {
 		return nil, err
@@ -812,6 +902,7 @@ func (c *applicationServiceImpl) Deploy(ctx context.Context, app *model.Applicat
 
 	res := &apisv1.ApplicationDeployResponse{
 		ApplicationRevisionBase: c.convertRevisionModelToBase(ctx, appRevision),
+		Notice:                  app.Notice,
 	}
 	if record != nil {
 		res.WorkflowRecord = assembler.ConvertFromRecordModel(record).WorkflowRecordBase
@@ -879,6 +970,9 @@ func (c *applicationServiceImpl) renderOAMApplication(ctx context.Context, appMo
 			},
 		},
 	}
+	if requestID, ok := utils.RequestIDFrom(ctx); ok {
+		app.Annotations[model.AnnotationRequestID] = requestID
+	}
 	originalApp := &v1beta1.Application{}
 	if err := c.KubeClient.Get(ctx, types.NamespacedName{
 		Name:      appModel.Name,
@@ -921,6 +1015,9 @@ func (c *applicationServiceImpl) renderOAMApplication(ctx context.Context, appMo
 			}
 			traits = append(traits, aTrait)
 		}
+		if replicas, ok := envbinding.ComponentReplicas[component.Name]; ok {
+			traits = setScalerReplicas(traits, replicas)
+		}
 		bc := common.ApplicationComponent{
 			Name:             component.Name,
 			Type:             component.Type,
@@ -1004,67 +1101,56 @@ func (c *applicationServiceImpl) DeleteApplication(ctx context.Context, app *mod
 	if len(crs) > 0 {
 		return bcode.ErrApplicationRefusedDelete
 	}
-	// query all components to deleted
-	components, err := c.ListComponents(ctx, app, apisv1.ListApplicationComponentOptions{})
-	if err != nil {
-		return err
-	}
-	// query all policies to deleted
-	policies, err := c.ListPolicies(ctx, app)
-	if err != nil {
-		return err
-	}
-
-	var revision = model.ApplicationRevision{
-		AppPrimaryKey: app.PrimaryKey(),
-	}
-	revisions, err := c.Store.List(ctx, &revision, &datastore.ListOptions{})
-	if err != nil {
-		return err
-	}
-
-	triggers, err := c.ListApplicationTriggers(ctx, app)
+	// the revisions and the workflow records are the history, they are not kept in the trash
+	records, err := listTrashRecords(ctx, c.Store,
+		&model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()},
+		&model.ApplicationPolicy{AppPrimaryKey: app.PrimaryKey()},
+		&model.ApplicationTrigger{AppPrimaryKey: app.PrimaryKey()},
+		&model.Workflow{AppPrimaryKey: app.PrimaryKey()},
+		&model.EnvBinding{AppPrimaryKey: app.PrimaryKey()},
+	)
 	if err != nil {
 		return err
 	}
-
-	// delete workflow
-	if err := c.WorkflowService.DeleteWorkflowByApp(ctx, app); err != nil && !errors.Is(err, bcode.ErrWorkflowNotExist) {
-		klog.Errorf("delete workflow %s failure %s", app.Name, err.Error())
-	}
-
-	for _, component := range components {
-		err := c.Store.Delete(ctx, &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey(), Name: component.Name})
-		if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
-			klog.Errorf("delete component %s in app %s failure %s", component.Name, app.Name, err.Error())
+	// the trash item is rolled back with the application if it is not deleted
+	if err := datastore.InTransaction(ctx, c.Store, func(ctx context.Context) error {
+		if _, err := moveToTrash(ctx, c.Store, TrashKindApplication, app.Project, app.Alias, app, records...); err != nil {
+			return err
 		}
-	}
 
-	for _, policy := range policies {
-		err := c.Store.Delete(ctx, &model.ApplicationPolicy{AppPrimaryKey: app.PrimaryKey(), Name: policy.Name})
-		if err != nil && errors.Is(err, datastore.ErrRecordNotExist) {
-			klog.Errorf("delete policy %s in app %s failure %s", policy.Name, app.Name, err.Error())
+		// delete workflow
+		if err := c.WorkflowService.DeleteWorkflowByApp(ctx, app); err != nil && !errors.Is(err, bcode.ErrWorkflowNotExist) {
+			klog.Errorf("delete workflow %s failure %s", app.Name, err.Error())
 		}
-	}
 
-	for _, entity := range revisions {
-		revision := entity.(*model.ApplicationRevision)
-		if err := c.Store.Delete(ctx, &model.ApplicationRevision{AppPrimaryKey: app.PrimaryKey(), Version: revision.Version}); err != nil {
-			klog.Errorf("delete revision %s in app %s failure %s", revision.Version, app.Name, err.Error())
+		// the components, the policies, the revisions and the triggers of the application are deleted by their app primary key
+		for _, query := range []datastore.Entity{
+			&model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()},
+			&model.ApplicationPolicy{AppPrimaryKey: app.PrimaryKey()},
+			&model.ApplicationRevision{AppPrimaryKey: app.PrimaryKey()},
+			&model.ApplicationTrigger{AppPrimaryKey: app.PrimaryKey()},
+		} {
+			if _, err := c.Store.DeleteByFilter(ctx, query, nil); err != nil {
+				klog.Errorf("delete %s in app %s failure %s", query.TableName(), app.Name, err.Error())
+			}
 		}
-	}
 
-	for _, trigger := range triggers {
-		if err := c.Store.Delete(ctx, &model.ApplicationTrigger{AppPrimaryKey: app.PrimaryKey(), Name: trigger.Name, Token: trigger.Token}); err != nil {
-			klog.Errorf("delete trigger %s in app %s failure %s", trigger.Name, app.Name, err.Error())
+		if err := c.EnvBindingService.BatchDeleteEnvBinding(ctx, app); err != nil {
+			klog.Errorf("delete envbindings in app %s failure %s", app.Name, err.Error())
 		}
-	}
 
-	if err := c.EnvBindingService.BatchDeleteEnvBinding(ctx, app); err != nil {
-		klog.Errorf("delete envbindings in app %s failure %s", app.Name, err.Error())
+		return c.Store.Delete(ctx, app)
+	}); err != nil {
+		return err
 	}
+	c.publishApplicationChange(ctx, app, changefeed.ActionDelete)
+	return nil
+}
 
-	return c.Store.Delete(ctx, app)
+// publishApplicationChange pushes the change of the application to the subscribers
+func (c *applicationServiceImpl) publishApplicationChange(ctx context.Context, app *model.Application, action string) {
+	operator, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	c.ChangeFeed.Publish(changefeed.Event{Resource: "application", Name: app.Name, Project: app.Project, Action: action, Operator: operator, Org: datastore.TenantFromContext(ctx)})
 }
 
 func (c *applicationServiceImpl) GetApplicationComponent(ctx context.Context, app *model.Application, componentName string) (*model.ApplicationComponent, error) {
@@ -1083,6 +1169,7 @@ func (c *applicationServiceImpl) GetApplicationComponent(ctx context.Context, ap
 }
 
 func (c *applicationServiceImpl) UpdateComponent(ctx context.Context, app *model.Application, component *model.ApplicationComponent, req apisv1.UpdateApplicationComponentRequest) (*apisv1.ComponentBase, error) {
+	before := specSnapshot(component)
 	if req.Alias != nil {
 		component.Alias = *req.Alias
 	}
@@ -1105,13 +1192,25 @@ func (c *applicationServiceImpl) UpdateComponent(ctx context.Context, app *model
 		}
 		component.Properties = properties
 	}
+	issues := lintComponent(component)
+	if err := checkLintIssues(issues); err != nil {
+		return nil, err
+	}
 	if err := c.Store.Put(ctx, component); err != nil {
 		return nil, err
 	}
-	return assembler.ConvertComponentModelToBase(component), nil
+	recordSpecAudit(ctx, c.Store, &model.SpecAudit{
+		Resource:    SpecAuditResourceApplication,
+		Project:     app.Project,
+		Entity:      app.Name,
+		SubResource: "component/" + component.Name,
+	}, before, specSnapshot(component))
+	base := assembler.ConvertComponentModelToBase(component)
+	base.LintIssues = issues
+	return base, nil
 }
 
-func (c *applicationServiceImpl) createComponent(ctx context.Context, app *model.Application, com apisv1.CreateComponentRequest, main bool) (*apisv1.ComponentBase, error) {
+func (c *applicationServiceImpl) createComponent(ctx context.Context, app *model.Application, com apisv1.CreateComponentRequest, main bool, defaults *model.ApplicationDefaults) (*apisv1.ComponentBase, error) {
 	var cd v1beta1.ComponentDefinition
 	loadCtx := utils.WithProject(ctx, "")
 	if err := c.KubeClient.Get(loadCtx, types.NamespacedName{Name: com.ComponentType, Namespace: velatypes.DefaultKubeVelaNS}, &cd); err != nil {
@@ -1165,6 +1264,11 @@ func (c *applicationServiceImpl) createComponent(ctx context.Context, app *model
 	if len(componentModel.Traits) == 0 {
 		c.initCreateDefaultTrait(&componentModel)
 	}
+	applyComponentDefaults(defaults, &componentModel)
+	issues := lintComponent(&componentModel)
+	if err := checkLintIssues(issues); err != nil {
+		return nil, err
+	}
 
 	if err := c.Store.Add(ctx, &componentModel); err != nil {
 		if errors.Is(err, datastore.ErrRecordExist) {
@@ -1178,11 +1282,46 @@ func (c *applicationServiceImpl) createComponent(ctx context.Context, app *model
 		return nil, bcode.ErrEnvBindingUpdateWorkflow
 	}
 
-	return assembler.ConvertComponentModelToBase(&componentModel), nil
+	base := assembler.ConvertComponentModelToBase(&componentModel)
+	base.LintIssues = issues
+	return base, nil
+}
+
+// LintApplication evaluates the lint rules against the components of the application
+func (c *applicationServiceImpl) LintApplication(ctx context.Context, app *model.Application) (*apisv1.ApplicationLintResponse, error) {
+	return lintApplication(ctx, c.Store, app)
 }
 
 func (c *applicationServiceImpl) CreateComponent(ctx context.Context, app *model.Application, com apisv1.CreateComponentRequest) (*apisv1.ComponentBase, error) {
-	return c.createComponent(ctx, app, com, false)
+	return c.createComponent(ctx, app, com, false, nil)
+}
+
+// PreviewApplicationDefaults returns the labels and the main component traits of a new application with the defaults of the project
+func (c *applicationServiceImpl) PreviewApplicationDefaults(ctx context.Context, projectName string, req apisv1.PreviewApplicationDefaultsRequest) (*apisv1.PreviewApplicationDefaultsResponse, error) {
+	project, err := c.ProjectService.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	component := model.ApplicationComponent{Type: req.ComponentType}
+	for _, trait := range req.Traits {
+		properties, err := model.NewJSONStructByString(trait.Properties)
+		if err != nil {
+			return nil, bcode.ErrInvalidProperties
+		}
+		component.Traits = append(component.Traits, model.ApplicationTrait{Type: trait.Type, Alias: trait.Alias, Description: trait.Description, Properties: properties})
+	}
+	if len(component.Traits) == 0 {
+		c.initCreateDefaultTrait(&component)
+	}
+	applyComponentDefaults(project.ApplicationDefaults, &component)
+	res := &apisv1.PreviewApplicationDefaultsResponse{
+		Labels: mergeDefaultLabels(project.ApplicationDefaults, req.Labels),
+		Traits: []*apisv1.ApplicationTrait{},
+	}
+	for _, trait := range component.Traits {
+		res.Traits = append(res.Traits, &apisv1.ApplicationTrait{Type: trait.Type, Alias: trait.Alias, Description: trait.Description, Properties: trait.Properties, CreateTime: trait.CreateTime, UpdateTime: trait.UpdateTime})
+	}
+	return res, nil
 }
 
 func (c *applicationServiceImpl) initCreateDefaultTrait(component *model.ApplicationComponent) {
@@ -1286,6 +1425,7 @@ func (c *applicationServiceImpl) UpdatePolicy(ctx context.Context, app *model.Ap
 		klog.Warningf("update app policy %s failure %s", app.PrimaryKey(), err.Error())
 		return nil, err
 	}
+	before := specSnapshot(&policy)
 	policy.Type = policyUpdate.Type
 	properties, err := model.NewJSONStructByString(policyUpdate.Properties)
 	if err != nil {
@@ -1299,6 +1439,12 @@ func (c *applicationServiceImpl) UpdatePolicy(ctx context.Context, app *model.Ap
 	if err := c.Store.Put(ctx, &policy); err != nil {
 		return nil, err
 	}
+	recordSpecAudit(ctx, c.Store, &model.SpecAudit{
+		Resource:    SpecAuditResourceApplication,
+		Project:     app.Project,
+		Entity:      app.Name,
+		SubResource: "policy/" + policyName,
+	}, before, specSnapshot(&policy))
 	if err = c.handlePolicyBindingWorkflowStep(ctx, app, policyName, policyUpdate.WorkflowPolicyBindings); err != nil {
 		return nil, err
 	}
@@ -1441,6 +1587,18 @@ func (c *applicationServiceImpl) DetailRevision(ctx context.Context, appName, re
 		}
 	}
 
+	previous, err := c.getPreviousRevision(ctx, &revision)
+	if err != nil {
+		klog.Warningf("failed to get the previous revision of %s: %s", revision.Version, err.Error())
+	}
+	if previous != nil {
+		diff, err := diffRevisions(previous, &revision)
+		if err != nil {
+			klog.Warningf("failed to diff the revision %s: %s", revision.Version, err.Error())
+		}
+		resp.Diff = diff
+	}
+
 	return resp, nil
 }
 
@@ -1882,6 +2040,7 @@ func NewTestApplicationService(ds datastore.DataStore, c client.Client, cfg *res
 		KubeClient: c,
 		Apply:      apply.NewAPIApplicator(c),
 		EnvService: envImpl,
+		LogStore:   logstore.New(logstore.Config{}),
 	}
 	def := &definitionServiceImpl{KubeClient: c}
 	envbinding := &envBindingServiceImpl{
@@ -1903,6 +2062,7 @@ func NewTestApplicationService(ds datastore.DataStore, c client.Client, cfg *res
 			Apply:             apply.NewAPIApplicator(c),
 			EnvService:        envImpl,
 			EnvBindingService: envbinding,
+			LogStore:          logstore.New(logstore.Config{}),
 		},
 		EnvService:        envImpl,
 		EnvBindingService: envbinding,
@@ -2008,3 +2168,242 @@ func (c *applicationServiceImpl) findAllBindingPolicyWorkflowStep(ctx context.Co
 	}
 	return res, nil
 }
+
+// getProjectTarget gets the target and checks it belongs to the project
+func (c *applicationServiceImpl) getProjectTarget(ctx context.Context, projectName, targetName string) (*model.Target, error) {
+	target := &model.Target{Name: targetName}
+	if err := c.Store.Get(ctx, target); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrTargetNotExist
+		}
+		return nil, err
+	}
+	if target.Project != projectName || target.Cluster == nil {
+		return nil, bcode.ErrTargetNotExist
+	}
+	return target, nil
+}
+
+// ListUnmanagedWorkloads lists the Deployments and StatefulSets in the target namespace that are not managed by any application
+func (c *applicationServiceImpl) ListUnmanagedWorkloads(ctx context.Context, projectName, targetName string) (*apisv1.ListWorkloadsResponse, error) {
+	target, err := c.getProjectTarget(ctx, projectName, targetName)
+	if err != nil {
+		return nil, err
+	}
+	clusterCtx := multicluster.ContextWithClusterName(utils.WithProject(ctx, ""), target.Cluster.ClusterName)
+	var workloads = []apisv1.WorkloadBase{}
+	var deployments appsv1.DeploymentList
+	if err := c.KubeClient.List(clusterCtx, &deployments, client.InNamespace(target.Cluster.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		deploy := &deployments.Items[i]
+		if isManagedWorkload(deploy) {
+			continue
+		}
+		workloads = append(workloads, convertWorkload2Base("Deployment", deploy, target.Cluster.ClusterName, deploy.Spec.Replicas, deploy.Spec.Template.Spec))
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.KubeClient.List(clusterCtx, &statefulSets, client.InNamespace(target.Cluster.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		sts := &statefulSets.Items[i]
+		if isManagedWorkload(sts) {
+			continue
+		}
+		workloads = append(workloads, convertWorkload2Base("StatefulSet", sts, target.Cluster.ClusterName, sts.Spec.Replicas, sts.Spec.Template.Spec))
+	}
+	return &apisv1.ListWorkloadsResponse{Workloads: workloads}, nil
+}
+
+// ImportWorkloads creates an application that is bound to the environment and converts the workloads into its components
+func (c *applicationServiceImpl) ImportWorkloads(ctx context.Context, projectName, targetName string, req apisv1.ImportWorkloadsRequest) (*apisv1.ApplicationBase, error) {
+	target, err := c.getProjectTarget(ctx, projectName, targetName)
+	if err != nil {
+		return nil, err
+	}
+	env := &model.Env{Name: req.EnvName}
+	if err := c.Store.Get(ctx, env); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrEnvNotExisted
+		}
+		return nil, err
+	}
+	if env.Project != projectName {
+		return nil, bcode.ErrEnvNotExisted
+	}
+	if !pkgUtils.StringsContain(env.Targets, targetName) {
+		return nil, bcode.ErrTargetNotBelongToEnv
+	}
+
+	clusterCtx := multicluster.ContextWithClusterName(utils.WithProject(ctx, ""), target.Cluster.ClusterName)
+	var components []apisv1.CreateComponentRequest
+	for _, ref := range req.Workloads {
+		component, err := c.convertWorkload2Component(clusterCtx, target.Cluster.Namespace, ref)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, *component)
+	}
+
+	base, err := c.CreateApplication(ctx, apisv1.CreateApplicationRequest{
+		Name:        req.Name,
+		Alias:       req.Alias,
+		Description: req.Description,
+		Project:     projectName,
+		EnvBinding:  []*apisv1.EnvBinding{{Name: env.Name}},
+		Component:   &components[0],
+	})
+	if err != nil {
+		return nil, err
+	}
+	app := &model.Application{Name: base.Name}
+	if err := c.Store.Get(ctx, app); err != nil {
+		return nil, err
+	}
+	for _, component := range components[1:] {
+		if _, err := c.CreateComponent(ctx, app, component); err != nil {
+			return nil, err
+		}
+	}
+	return base, nil
+}
+
+// convertWorkload2Component converts the workload to a component, the Deployment with one container is converted to
+// the webservice component with the scaler trait, others are kept as they are by the k8s-objects component.
+func (c *applicationServiceImpl) convertWorkload2Component(ctx context.Context, namespace string, ref apisv1.WorkloadRef) (*apisv1.CreateComponentRequest, error) {
+	var obj client.Object
+	switch ref.Kind {
+	case "Deployment":
+		obj = &appsv1.Deployment{}
+	case "StatefulSet":
+		obj = &appsv1.StatefulSet{}
+	default:
+		return nil, bcode.ErrWorkloadNotExist
+	}
+	if err := c.KubeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, bcode.ErrWorkloadNotExist
+		}
+		return nil, err
+	}
+	if isManagedWorkload(obj) {
+		return nil, bcode.ErrWorkloadAlreadyManaged
+	}
+	component := &apisv1.CreateComponentRequest{
+		Name:        ref.Name,
+		Description: fmt.Sprintf("imported from the %s %s/%s", ref.Kind, namespace, ref.Name),
+	}
+	if deploy, ok := obj.(*appsv1.Deployment); ok && len(deploy.Spec.Template.Spec.Containers) == 1 {
+		component.ComponentType = "webservice"
+		properties, err := json.Marshal(convertContainer2Properties(deploy.Spec.Template.Spec.Containers[0]))
+		if err != nil {
+			return nil, err
+		}
+		component.Properties = string(properties)
+		var replicas int32 = 1
+		if deploy.Spec.Replicas != nil {
+			replicas = *deploy.Spec.Replicas
+		}
+		component.Traits = []*apisv1.CreateApplicationTraitRequest{{
+			Type:       "scaler",
+			Alias:      "Set Replicas",
+			Properties: fmt.Sprintf(`{"replicas":%d}`, replicas),
+		}}
+		return component, nil
+	}
+	component.ComponentType = "k8s-objects"
+	obj.GetObjectKind().SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(ref.Kind))
+	cleanWorkloadObject(obj)
+	properties, err := json.Marshal(map[string]interface{}{"objects": []interface{}{obj}})
+	if err != nil {
+		return nil, err
+	}
+	component.Properties = string(properties)
+	return component, nil
+}
+
+// isManagedWorkload checks whether the workload is rendered by an application or owned by other resources
+func isManagedWorkload(obj metav1.Object) bool {
+	if _, exist := obj.GetLabels()[oam.LabelAppName]; exist {
+		return true
+	}
+	return len(obj.GetOwnerReferences()) > 0
+}
+
+func convertWorkload2Base(kind string, obj metav1.Object, cluster string, replicas *int32, pod corev1.PodSpec) apisv1.WorkloadBase {
+	base := apisv1.WorkloadBase{
+		Kind:       kind,
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		Cluster:    cluster,
+		Replicas:   1,
+		CreateTime: obj.GetCreationTimestamp().Time,
+	}
+	if replicas != nil {
+		base.Replicas = *replicas
+	}
+	for _, container := range pod.Containers {
+		base.Images = append(base.Images, container.Image)
+	}
+	return base
+}
+
+// convertContainer2Properties converts the container to the properties of the webservice component
+func convertContainer2Properties(container corev1.Container) map[string]interface{} {
+	properties := map[string]interface{}{
+		"image": container.Image,
+	}
+	if container.ImagePullPolicy != "" {
+		properties["imagePullPolicy"] = string(container.ImagePullPolicy)
+	}
+	if len(container.Command) > 0 {
+		properties["cmd"] = container.Command
+	}
+	var ports []map[string]interface{}
+	for _, port := range container.Ports {
+		p := map[string]interface{}{"port": port.ContainerPort, "expose": false}
+		if port.Protocol != "" {
+			p["protocol"] = string(port.Protocol)
+		}
+		if port.Name != "" {
+			p["name"] = port.Name
+		}
+		ports = append(ports, p)
+	}
+	if len(ports) > 0 {
+		properties["ports"] = ports
+	}
+	var envs []map[string]interface{}
+	for _, env := range container.Env {
+		// the values from the secrets and the config maps are skipped, they could be added back by the users.
+		if env.ValueFrom == nil {
+			envs = append(envs, map[string]interface{}{"name": env.Name, "value": env.Value})
+		}
+	}
+	if len(envs) > 0 {
+		properties["env"] = envs
+	}
+	return properties
+}
+
+// cleanWorkloadObject removes the status and the fields generated by the cluster
+func cleanWorkloadObject(obj client.Object) {
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	obj.SetGeneration(0)
+	obj.SetManagedFields(nil)
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetSelfLink("")
+	annotations := obj.GetAnnotations()
+	delete(annotations, "deployment.kubernetes.io/revision")
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+	obj.SetAnnotations(annotations)
+	if sts, ok := obj.(*appsv1.StatefulSet); ok {
+		sts.Status = appsv1.StatefulSetStatus{}
+	}
+	if deploy, ok := obj.(*appsv1.Deployment); ok {
+		deploy.Status = appsv1.DeploymentStatus{}
+	}
+}
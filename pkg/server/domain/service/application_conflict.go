@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	assembler "github.com/kubevela/velaux/pkg/server/interfaces/api/assembler/v1"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// ListApplicationConflicts lists the applications whose CRs are modified outside VelaUX, the conflicts are detected by the sync
+func (c *applicationServiceImpl) ListApplicationConflicts(ctx context.Context) (*apisv1.ListApplicationConflictsResponse, error) {
+	apps, err := c.Store.List(ctx, &model.Application{}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListApplicationConflictsResponse{Applications: []*apisv1.ApplicationBase{}}
+	for _, entity := range apps {
+		app := entity.(*model.Application)
+		if len(app.Conflicts) > 0 {
+			res.Applications = append(res.Applications, assembler.ConvertAppModelToBase(app, nil))
+		}
+	}
+	sort.Slice(res.Applications, func(i, j int) bool {
+		return res.Applications[i].Name < res.Applications[j].Name
+	})
+	return res, nil
+}
+
+// AcceptApplicationConflict takes the components of the application CR in the env as the ones of the application,
+// and the CR becomes the deployed revision, so the conflict is resolved without deploying.
+func (c *applicationServiceImpl) AcceptApplicationConflict(ctx context.Context, app *model.Application, envName string) error {
+	revision, appCR, err := c.getConflict(ctx, app, envName)
+	if err != nil {
+		return err
+	}
+	accepted := appCR.DeepCopy()
+	// the components are reset by the primary key of the application
+	accepted.Name = app.Name
+	if _, err := c.resetApp(ctx, accepted); err != nil {
+		return err
+	}
+	config, err := yaml.Marshal(appCR)
+	if err != nil {
+		return err
+	}
+	revision.ApplyAppConfig = string(config)
+	if err := c.Store.Put(ctx, revision); err != nil {
+		return err
+	}
+	app.RemoveConflict(envName)
+	return c.Store.Put(ctx, app)
+}
+
+// OverwriteApplicationConflict restores the spec of the application CR in the env to the deployed revision
+func (c *applicationServiceImpl) OverwriteApplicationConflict(ctx context.Context, app *model.Application, envName string) error {
+	revision, appCR, err := c.getConflict(ctx, app, envName)
+	if err != nil {
+		return err
+	}
+	var deployed v1beta1.Application
+	if err := yaml.Unmarshal([]byte(revision.ApplyAppConfig), &deployed); err != nil {
+		return err
+	}
+	appCR.Spec = deployed.Spec
+	if err := c.KubeClient.Update(ctx, appCR); err != nil {
+		return err
+	}
+	klog.Infof("the application %s in the env %s is restored to the revision %s", app.Name, envName, revision.Version)
+	app.RemoveConflict(envName)
+	return c.Store.Put(ctx, app)
+}
+
+// getConflict returns the deployed revision and the running CR of the application in the conflicted env
+func (c *applicationServiceImpl) getConflict(ctx context.Context, app *model.Application, envName string) (*model.ApplicationRevision, *v1beta1.Application, error) {
+	conflict := app.GetConflict(envName)
+	if conflict == nil {
+		return nil, nil, bcode.ErrApplicationConflictNotExist
+	}
+	revision := &model.ApplicationRevision{AppPrimaryKey: app.Name, Version: conflict.Revision}
+	if err := c.Store.Get(ctx, revision); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, nil, bcode.ErrApplicationRevisionNotExist
+		}
+		return nil, nil, err
+	}
+	appCR, err := c.GetApplicationCRInEnv(ctx, app, envName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if appCR == nil {
+		return nil, nil, bcode.ErrApplicationNotDeployedInEnv
+	}
+	return revision, appCR, nil
+}
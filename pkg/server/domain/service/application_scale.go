@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const scalerTraitType = "scaler"
+
+// ScaleApplication sets the replicas of the components in the env and deploys the workflow of the env only,
+// the replicas are kept in the envbinding so that the later deploys of the env keep them.
+func (c *applicationServiceImpl) ScaleApplication(ctx context.Context, app *model.Application, envName string, req apisv1.ScaleApplicationRequest) (*apisv1.ApplicationDeployResponse, error) {
+	if len(req.Components) == 0 {
+		return nil, bcode.ErrInvalidScaleRequest
+	}
+	replicas := map[string]int{}
+	var summary []string
+	for _, component := range req.Components {
+		if component.Replicas < 0 {
+			return nil, bcode.ErrInvalidScaleRequest
+		}
+		if _, err := c.GetApplicationComponent(ctx, app, component.Name); err != nil {
+			return nil, err
+		}
+		replicas[component.Name] = component.Replicas
+		summary = append(summary, fmt.Sprintf("%s=%d", component.Name, component.Replicas))
+	}
+	envBinding, err := c.EnvBindingService.GetEnvBinding(ctx, app, envName)
+	if err != nil {
+		return nil, err
+	}
+	if envBinding.ComponentReplicas == nil {
+		envBinding.ComponentReplicas = map[string]int{}
+	}
+	for name, count := range replicas {
+		envBinding.ComponentReplicas[name] = count
+	}
+	if err := c.Store.Put(ctx, envBinding); err != nil {
+		return nil, err
+	}
+	note := req.Note
+	if note == "" {
+		note = "scale " + strings.Join(summary, ", ")
+	}
+	return c.Deploy(ctx, app, apisv1.ApplicationDeployRequest{
+		WorkflowName: repository.ConvertWorkflowName(envName),
+		Note:         note,
+		TriggerType:  apisv1.TriggerTypeAPI,
+	})
+}
+
+// setScalerReplicas sets the replicas of the scaler trait, the trait is added if the component has no scaler
+func setScalerReplicas(traits []common.ApplicationTrait, replicas int) []common.ApplicationTrait {
+	for i, trait := range traits {
+		if trait.Type != scalerTraitType {
+			continue
+		}
+		properties, err := model.NewJSONStruct(trait.Properties)
+		if err != nil || properties == nil {
+			properties = &model.JSONStruct{}
+		}
+		(*properties)["replicas"] = replicas
+		traits[i].Properties = properties.RawExtension()
+		return traits
+	}
+	return append(traits, common.ApplicationTrait{
+		Type:       scalerTraitType,
+		Properties: (&model.JSONStruct{"replicas": replicas}).RawExtension(),
+	})
+}
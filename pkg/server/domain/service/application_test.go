@@ -30,6 +30,7 @@ import (
 	wfTypes "github.com/kubevela/workflow/pkg/types"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -151,6 +152,27 @@ var _ = Describe("Test application service function", func() {
 		Expect(err).Should(BeNil())
 		Expect(len(triggers)).Should(Equal(1))
 
+		By("the component is rolled back if the application fails to be created")
+		txAppService := *appService
+		txAppService.Store = datastore.JoinContextTransaction(appService.Store)
+		_, err = txAppService.CreateApplication(context.TODO(), v1.CreateApplicationRequest{
+			Name:       "test-rollback-app",
+			Project:    testProject,
+			EnvBinding: []*v1.EnvBinding{{Name: "app-missing"}},
+			Component: &v1.CreateComponentRequest{
+				Name:          "rollback-component",
+				ComponentType: "webservice",
+				Properties:    "{\"image\":\"nginx\"}",
+			},
+		})
+		Expect(err).ShouldNot(BeNil())
+		exist, err := appService.Store.IsExist(context.TODO(), &model.ApplicationComponent{AppPrimaryKey: "test-rollback-app", Name: "rollback-component"})
+		Expect(err).Should(BeNil())
+		Expect(exist).Should(BeFalse())
+		exist, err = appService.Store.IsExist(context.TODO(), &model.Application{Name: "test-rollback-app"})
+		Expect(err).Should(BeNil())
+		Expect(exist).Should(BeFalse())
+
 		By("test creating a cloud service application")
 
 		rds, err := os.ReadFile("./testdata/terraform-alibaba-rds.yaml")
@@ -486,6 +508,34 @@ var _ = Describe("Test application service function", func() {
 		Expect(err).Should(BeNil())
 	})
 
+	It("Test the application notice", func() {
+		ctx := context.WithValue(context.TODO(), &v1.CtxKeyUser, model.DefaultAdminUserName)
+		appModel, err := appService.GetApplication(ctx, testApp)
+		Expect(err).Should(BeNil())
+		notice, err := appService.SetApplicationNotice(ctx, appModel, v1.UpdateApplicationNoticeRequest{
+			Message: "DB migration in progress",
+			Level:   model.ApplicationNoticeBlocking,
+		})
+		Expect(err).Should(BeNil())
+		Expect(notice.Author).Should(Equal(model.DefaultAdminUserName))
+
+		By("the blocking notice must be acknowledged")
+		appModel, err = appService.GetApplication(ctx, testApp)
+		Expect(err).Should(BeNil())
+		Expect(appModel.Notice).ShouldNot(BeNil())
+		_, err = appService.Deploy(ctx, appModel, v1.ApplicationDeployRequest{WorkflowName: repository.ConvertWorkflowName("app-dev")})
+		Expect(err).ShouldNot(BeNil())
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrApplicationNoticeNotAcknowledged.BusinessCode))
+		Expect(err.Error()).Should(ContainSubstring("DB migration in progress"))
+
+		By("remove the notice")
+		Expect(appService.DeleteApplicationNotice(ctx, appModel)).Should(BeNil())
+		appModel, err = appService.GetApplication(ctx, testApp)
+		Expect(err).Should(BeNil())
+		Expect(appModel.Notice).Should(BeNil())
+		Expect(appService.DeleteApplicationNotice(ctx, appModel)).Should(Equal(bcode.ErrApplicationNoticeNotExist))
+	})
+
 	It("Test ListRecords function", func() {
 		By("no running records in application")
 		ctx := context.TODO()
@@ -710,6 +760,43 @@ var _ = Describe("Test application service function", func() {
 		Expect(strings.Contains(resetResponse.YAML, "# Application(test-app) -- Component(component-name)")).Should(BeTrue())
 	})
 
+	It("Test scale the components in the env", func() {
+		ctx := context.TODO()
+		appModel, err := appService.GetApplication(ctx, testApp)
+		Expect(err).Should(BeNil())
+		_, err = appService.ScaleApplication(ctx, appModel, "app-dev", v1.ScaleApplicationRequest{Components: []v1.ComponentReplicas{{Name: "component-name", Replicas: -1}}})
+		Expect(err).Should(Equal(bcode.ErrInvalidScaleRequest))
+		_, err = appService.ScaleApplication(ctx, appModel, "app-dev", v1.ScaleApplicationRequest{Components: []v1.ComponentReplicas{{Name: "not-exist", Replicas: 1}}})
+		Expect(err).Should(Equal(bcode.ErrApplicationComponentNotExist))
+
+		By("the replicas of the env override the scaler trait")
+		envBinding, err := envBindingService.GetEnvBinding(ctx, appModel, "app-dev")
+		Expect(err).Should(BeNil())
+		envBinding.ComponentReplicas = map[string]int{"component-name": 3}
+		Expect(appService.Store.Put(ctx, envBinding)).Should(BeNil())
+		oamApp, err := appService.renderOAMApplication(ctx, appModel, repository.ConvertWorkflowName("app-dev"), "app-dev", "scale-test")
+		Expect(err).Should(BeNil())
+		var replicas interface{}
+		for _, component := range oamApp.Spec.Components {
+			if component.Name != "component-name" {
+				continue
+			}
+			for _, trait := range component.Traits {
+				if trait.Type == "scaler" {
+					properties := map[string]interface{}{}
+					Expect(json.Unmarshal(trait.Properties.Raw, &properties)).Should(BeNil())
+					replicas = properties["replicas"]
+				}
+			}
+		}
+		Expect(replicas).Should(BeEquivalentTo(3))
+
+		By("the other properties of the scaler are kept")
+		traits := setScalerReplicas([]common.ApplicationTrait{{Type: "scaler", Properties: &runtime.RawExtension{Raw: []byte(`{"replicas":1,"cpu":"100m"}`)}}}, 0)
+		Expect(traits).Should(HaveLen(1))
+		Expect(string(traits[0].Properties.Raw)).Should(Equal(`{"cpu":"100m","replicas":0}`))
+	})
+
 	It("Test DeleteApplication function", func() {
 		appModel, err := appService.GetApplication(context.TODO(), testApp)
 		Expect(err).Should(BeNil())
@@ -723,6 +810,70 @@ var _ = Describe("Test application service function", func() {
 		Expect(err).Should(BeNil())
 		Expect(cmp.Diff(len(policies), 0)).Should(BeEmpty())
 	})
+
+	It("Test import the workloads function", func() {
+		var replicas int32 = 2
+		labels := map[string]string{"app": "legacy-server"}
+		deploy := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy-server", Namespace: namespace1},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{
+						Name:  "main",
+						Image: "nginx:1.21",
+						Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+						Env:   []corev1.EnvVar{{Name: "MODE", Value: "legacy"}},
+					}}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), deploy)).Should(BeNil())
+		managed := deploy.DeepCopy()
+		managed.ResourceVersion = ""
+		managed.Name = "managed-server"
+		managed.Labels = map[string]string{oam.LabelAppName: "some-app"}
+		Expect(k8sClient.Create(context.TODO(), managed)).Should(BeNil())
+
+		workloads, err := appService.ListUnmanagedWorkloads(context.TODO(), testProject, defaultTarget)
+		Expect(err).Should(BeNil())
+		Expect(len(workloads.Workloads)).Should(Equal(1))
+		Expect(workloads.Workloads[0].Name).Should(Equal("legacy-server"))
+		Expect(workloads.Workloads[0].Replicas).Should(Equal(int32(2)))
+		Expect(workloads.Workloads[0].Images).Should(Equal([]string{"nginx:1.21"}))
+
+		_, err = appService.ListUnmanagedWorkloads(context.TODO(), "other-project", defaultTarget)
+		Expect(err).Should(Equal(bcode.ErrTargetNotExist))
+
+		_, err = appService.ImportWorkloads(context.TODO(), testProject, defaultTarget, v1.ImportWorkloadsRequest{
+			Name: "legacy-app", EnvName: "app-test", Workloads: []v1.WorkloadRef{{Kind: "Deployment", Name: "legacy-server"}},
+		})
+		Expect(err).Should(Equal(bcode.ErrTargetNotBelongToEnv))
+
+		_, err = appService.ImportWorkloads(context.TODO(), testProject, defaultTarget, v1.ImportWorkloadsRequest{
+			Name: "legacy-app", EnvName: "app-dev", Workloads: []v1.WorkloadRef{{Kind: "Deployment", Name: "managed-server"}},
+		})
+		Expect(err).Should(Equal(bcode.ErrWorkloadAlreadyManaged))
+
+		base, err := appService.ImportWorkloads(context.TODO(), testProject, defaultTarget, v1.ImportWorkloadsRequest{
+			Name: "legacy-app", EnvName: "app-dev", Workloads: []v1.WorkloadRef{{Kind: "Deployment", Name: "legacy-server"}},
+		})
+		Expect(err).Should(BeNil())
+		Expect(base.Project.Name).Should(Equal(testProject))
+		component, err := appService.GetApplicationComponent(context.TODO(), &model.Application{Name: "legacy-app"}, "legacy-server")
+		Expect(err).Should(BeNil())
+		Expect(component.Type).Should(Equal("webservice"))
+		Expect((*component.Properties)["image"]).Should(Equal("nginx:1.21"))
+		Expect(len(component.Traits)).Should(Equal(1))
+		Expect(component.Traits[0].Type).Should(Equal("scaler"))
+		Expect((*component.Traits[0].Properties)["replicas"]).Should(BeEquivalentTo(2))
+
+		app, err := appService.GetApplication(context.TODO(), "legacy-app")
+		Expect(err).Should(BeNil())
+		Expect(appService.DeleteApplication(context.TODO(), app)).Should(BeNil())
+	})
 })
 
 var _ = Describe("Test application component service function", func() {
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,6 +43,7 @@ import (
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/securityevent"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	apiutils "github.com/kubevela/velaux/pkg/server/utils"
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
@@ -151,15 +153,41 @@ func (a *authenticationServiceImpl) newLocalHandler(req apisv1.LoginRequest) (*l
 	}, nil
 }
 
-func (a *authenticationServiceImpl) Login(ctx context.Context, loginReq apisv1.LoginRequest) (*apisv1.LoginResponse, error) {
+func (a *authenticationServiceImpl) Login(ctx context.Context, loginReq apisv1.LoginRequest) (resp *apisv1.LoginResponse, err error) {
+	defer func() {
+		event := securityevent.Event{Type: securityevent.TypeLogin, Outcome: securityevent.OutcomeSuccess, Username: loginReq.Username}
+		if err != nil {
+			event.Outcome = securityevent.OutcomeFailure
+			event.Reason = err.Error()
+		} else if resp.User != nil {
+			event.Username = resp.User.Name
+		}
+		event.Details = map[string]string{"method": model.LoginTypeLocal}
+		if loginReq.Code != "" {
+			event.Details["method"] = model.LoginTypeDex
+		}
+		if loginReq.ConsentVersion > 0 {
+			event.Details["consentVersion"] = strconv.Itoa(loginReq.ConsentVersion)
+		}
+		emitSecurityEvent(ctx, event)
+	}()
 	var handler authHandler
-	var err error
 	sysInfo, err := a.SysService.Get(ctx)
 	if err != nil {
 		return nil, err
 	}
 	loginType := sysInfo.LoginType
+	// the notice is acknowledged on the login page, the credentials are not checked until it is acknowledged
+	consentBanner := sysInfo.LoginBanner
+	if consentBanner != nil && (consentBanner.Content == "" || !consentBanner.RequireConsent) {
+		consentBanner = nil
+	}
+	if consentBanner != nil && loginReq.ConsentVersion != consentBanner.Version {
+		return nil, bcode.ErrLoginConsentRequired
+	}
 
+	// attemptKeys the keys to count the failed attempts, only the local login is throttled
+	var attemptKeys []string
 	switch {
 	case loginType == model.LoginTypeDex || (loginReq.Code != "" && loginReq.Username == ""):
 		handler, err = a.newDexHandler(ctx, loginReq)
@@ -171,24 +199,55 @@ func (a *authenticationServiceImpl) Login(ctx context.Context, loginReq apisv1.L
 		if err != nil {
 			return nil, err
 		}
+		clientIP, _ := apiutils.ClientIPFrom(ctx)
+		attemptKeys = loginAttemptKeys(loginReq.Username, clientIP)
+		if err := checkLoginLocked(ctx, a.Store, attemptKeys); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, bcode.ErrUnsupportedLoginType
 	}
 	userBase, err := handler.login(ctx)
 	if err != nil {
+		if len(attemptKeys) > 0 && (errors.Is(err, bcode.ErrUserInconsistentPassword) || errors.Is(err, bcode.ErrUsernameNotExist)) {
+			recordLoginFailure(ctx, a.Store, attemptKeys)
+		}
 		return nil, err
 	}
+	if len(attemptKeys) > 0 {
+		if err := resetLoginFailures(ctx, a.Store, attemptKeys[0]); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Warningf("failed to reset the login attempts of the user %s: %s", userBase.Name, err.Error())
+		}
+	}
 	if userBase.Disabled {
 		return nil, bcode.ErrUserAlreadyDisabled
 	}
-	accessToken, err := a.generateJWTToken(userBase.Name, GrantTypeAccess, time.Hour)
+	if consentBanner != nil {
+		if err := recordLoginConsent(ctx, a.Store, userBase, consentBanner.Version); err != nil {
+			return nil, err
+		}
+	}
+	if err := enforceSessionLimit(ctx, a.Store, userBase.Name, sysInfo.SessionSettings); err != nil {
+		return nil, err
+	}
+	accessTTL, refreshTTL, _ := sessionLifetime(sysInfo.SessionSettings)
+	session, err := createSession(ctx, a.Store, userBase.Name, refreshTTL)
 	if err != nil {
 		return nil, err
 	}
-	refreshToken, err := a.generateJWTToken(userBase.Name, GrantTypeRefresh, time.Hour*24)
+	accessToken, err := a.generateJWTToken(ctx, userBase.Name, GrantTypeAccess, session.ID, accessTTL)
 	if err != nil {
 		return nil, err
 	}
+	refreshToken, err := a.generateJWTToken(ctx, userBase.Name, GrantTypeRefresh, session.ID, refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	method := model.LoginTypeLocal
+	if _, ok := handler.(*dexHandlerImpl); ok {
+		method = model.LoginTypeDex
+	}
+	recordLogin(ctx, a.Store, userBase.Name, method)
 	return &apisv1.LoginResponse{
 		User:         userBase,
 		AccessToken:  accessToken,
@@ -196,7 +255,7 @@ func (a *authenticationServiceImpl) Login(ctx context.Context, loginReq apisv1.L
 	}, nil
 }
 
-func (a *authenticationServiceImpl) generateJWTToken(username, grantType string, expireDuration time.Duration) (string, error) {
+func (a *authenticationServiceImpl) generateJWTToken(ctx context.Context, username, grantType, sessionID string, expireDuration time.Duration) (string, error) {
 	expire := time.Now().Add(expireDuration)
 	claims := model.CustomClaims{
 		StandardClaims: jwt.StandardClaims{
@@ -206,6 +265,8 @@ func (a *authenticationServiceImpl) generateJWTToken(username, grantType string,
 		},
 		Username:  username,
 		GrantType: grantType,
+		SessionID: sessionID,
+		Org:       datastore.TenantFromContext(ctx),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(signedKey))
@@ -220,7 +281,19 @@ func (a *authenticationServiceImpl) RefreshToken(ctx context.Context, refreshTok
 		return nil, err
 	}
 	if claim.GrantType == GrantTypeRefresh {
-		accessToken, err := a.generateJWTToken(claim.Username, GrantTypeAccess, time.Hour)
+		// the refresh tokens issued before the sessions are tracked could not be revoked, they are rejected
+		if claim.SessionID == "" {
+			return nil, bcode.ErrSessionRevoked
+		}
+		if err := CheckSession(ctx, claim); err != nil {
+			return nil, err
+		}
+		sysInfo, err := a.SysService.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		accessTTL, _, _ := sessionLifetime(sysInfo.SessionSettings)
+		accessToken, err := a.generateJWTToken(ctx, claim.Username, GrantTypeAccess, claim.SessionID, accessTTL)
 		if err != nil {
 			return nil, err
 		}
@@ -424,9 +497,30 @@ func (a *authenticationServiceImpl) GetLoginType(ctx context.Context) (*apisv1.G
 	if loginType == "" {
 		loginType = model.LoginTypeLocal
 	}
-	return &apisv1.GetLoginTypeResponse{
+	res := &apisv1.GetLoginTypeResponse{
 		LoginType: loginType,
-	}, nil
+	}
+	if sysInfo.LoginBanner != nil && sysInfo.LoginBanner.Content != "" {
+		res.LoginBanner = sysInfo.LoginBanner
+	}
+	return res, nil
+}
+
+// recordLoginConsent records the version of the login notice acknowledged by the user, the session is not issued if it fails
+func recordLoginConsent(ctx context.Context, store datastore.DataStore, userBase *apisv1.UserBase, version int) error {
+	user := &model.User{Name: userBase.Name}
+	if err := store.Get(ctx, user); err != nil {
+		return err
+	}
+	now := time.Now()
+	user.ConsentVersion = version
+	user.ConsentTime = &now
+	if err := store.Put(ctx, user); err != nil {
+		return err
+	}
+	userBase.ConsentVersion = version
+	userBase.ConsentTime = &now
+	return nil
 }
 
 func (d *dexHandlerImpl) login(ctx context.Context) (*apisv1.UserBase, error) {
@@ -436,6 +530,8 @@ func (d *dexHandlerImpl) login(ctx context.Context) (*apisv1.UserBase, error) {
 		Name string `json:"name"`
 		// Subject - Identifier for the End-User at the Issuer.
 		Sub string `json:"sub"`
+		// Groups the organizations and the teams of GitHub, or the groups of GitLab, it requires the groups scope
+		Groups []string `json:"groups"`
 	}
 	if err := d.idToken.Claims(&claims); err != nil {
 		return nil, err
@@ -457,6 +553,10 @@ func (d *dexHandlerImpl) login(ctx context.Context) (*apisv1.UserBase, error) {
 			return nil, err
 		}
 	}
+	systemInfo, err := d.systemInfoService.GetSystemInfo(ctx)
+	if err != nil {
+		klog.Errorf("failed to get the system info %s", err.Error())
+	}
 	var userBase *apisv1.UserBase
 	if len(users) > 0 {
 		u := users[0].(*model.User)
@@ -465,12 +565,11 @@ func (d *dexHandlerImpl) login(ctx context.Context) (*apisv1.UserBase, error) {
 		if err := d.Store.Put(ctx, u); err != nil {
 			return nil, err
 		}
-		userBase = convertUserBase(u)
-	} else {
-		systemInfo, err := d.systemInfoService.GetSystemInfo(ctx)
-		if err != nil {
-			klog.Errorf("failed to get the system info %s", err.Error())
+		if systemInfo != nil {
+			d.applyOAuthGroupMappings(ctx, u, systemInfo.OAuthGroupMappings, claims.Groups)
 		}
+		userBase = convertUserBase(u, useGravatar(ctx, d.systemInfoService))
+	} else {
 		user := &model.User{
 			Email:         claims.Email,
 			Name:          strings.ToLower(claims.Sub),
@@ -495,8 +594,9 @@ func (d *dexHandlerImpl) login(ctx context.Context) (*apisv1.UserBase, error) {
 					klog.Errorf("failed to add a user to project %s", err.Error())
 				}
 			}
+			d.applyOAuthGroupMappings(ctx, user, systemInfo.OAuthGroupMappings, claims.Groups)
 		}
-		userBase = convertUserBase(user)
+		userBase = convertUserBase(user, useGravatar(ctx, d.systemInfoService))
 	}
 
 	return userBase, nil
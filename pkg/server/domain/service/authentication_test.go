@@ -45,6 +45,7 @@ import (
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
 
 var _ = Describe("Test authentication service functions", func() {
@@ -171,6 +172,86 @@ var _ = Describe("Test authentication service functions", func() {
 		Expect(resp.Name).Should(Equal("test-login"))
 	})
 
+	It("Test lock the local login after too many failures", func() {
+		Expect(sysService.Init(context.TODO())).Should(BeNil())
+		authService.SysService = sysService
+		_, err := userService.CreateUser(context.Background(), apisv1.CreateUserRequest{
+			Name:     "test-lockout",
+			Email:    "lockout@example.com",
+			Password: "password1",
+		})
+		Expect(err).Should(BeNil())
+		authService.UserService = userService
+
+		ctx := utils.WithClientIP(context.Background(), "10.0.0.1")
+		for i := 0; i < loginMaxFailures; i++ {
+			_, err = authService.Login(ctx, apisv1.LoginRequest{Username: "test-lockout", Password: "wrong-password1"})
+			Expect(err).Should(Equal(bcode.ErrUserInconsistentPassword))
+		}
+		_, err = authService.Login(ctx, apisv1.LoginRequest{Username: "test-lockout", Password: "password1"})
+		Expect(err).ShouldNot(BeNil())
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrLoginLocked.BusinessCode))
+
+		By("the client IP is locked too")
+		_, err = authService.Login(ctx, apisv1.LoginRequest{Username: "admin", Password: "password1"})
+		Expect(err).ShouldNot(BeNil())
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrLoginLocked.BusinessCode))
+
+		By("the admin unlocks the user")
+		user, err := userService.GetUser(context.Background(), "test-lockout")
+		Expect(err).Should(BeNil())
+		Expect(userService.UnlockUser(context.Background(), user)).Should(BeNil())
+		Expect(userService.UnlockUser(context.Background(), user)).Should(Equal(bcode.ErrUserNotLocked))
+		resp, err := authService.Login(utils.WithClientIP(context.Background(), "10.0.0.2"), apisv1.LoginRequest{Username: "test-lockout", Password: "password1"})
+		Expect(err).Should(BeNil())
+		Expect(resp.User.Name).Should(Equal("test-lockout"))
+	})
+
+	It("Test acknowledge the login notice", func() {
+		info, err := sysService.Get(context.TODO())
+		Expect(err).Should(BeNil())
+		info.LoginBanner = &model.LoginBanner{Title: "Notice", Content: "Authorized use only.", RequireConsent: true, Version: 2}
+		Expect(ds.Put(context.TODO(), info)).Should(BeNil())
+		authService.SysService = sysService
+		authService.UserService = userService
+		_, err = userService.CreateUser(context.Background(), apisv1.CreateUserRequest{
+			Name:     "test-consent",
+			Email:    "consent@example.com",
+			Password: "password1",
+		})
+		Expect(err).Should(BeNil())
+
+		loginType, err := authService.GetLoginType(context.TODO())
+		Expect(err).Should(BeNil())
+		Expect(loginType.LoginBanner.Version).Should(Equal(2))
+
+		_, err = authService.Login(context.TODO(), apisv1.LoginRequest{Username: "test-consent", Password: "password1"})
+		Expect(err).Should(Equal(bcode.ErrLoginConsentRequired))
+		_, err = authService.Login(context.TODO(), apisv1.LoginRequest{Username: "test-consent", Password: "password1", ConsentVersion: 1})
+		Expect(err).Should(Equal(bcode.ErrLoginConsentRequired))
+		resp, err := authService.Login(context.TODO(), apisv1.LoginRequest{Username: "test-consent", Password: "password1", ConsentVersion: 2})
+		Expect(err).Should(BeNil())
+		Expect(resp.User.ConsentVersion).Should(Equal(2))
+		user, err := userService.GetUser(context.Background(), "test-consent")
+		Expect(err).Should(BeNil())
+		Expect(user.ConsentVersion).Should(Equal(2))
+		Expect(user.ConsentTime).ShouldNot(BeNil())
+
+		By("the version is only increased if the notice changes")
+		banner, err := mergeLoginBanner(info.LoginBanner, &model.LoginBanner{Title: "Notice", Content: "Authorized use only.", RequireConsent: false})
+		Expect(err).Should(BeNil())
+		Expect(banner.Version).Should(Equal(2))
+		banner, err = mergeLoginBanner(banner, &model.LoginBanner{Title: "Notice", Content: "Authorized use only, monitored.", RequireConsent: true})
+		Expect(err).Should(BeNil())
+		Expect(banner.Version).Should(Equal(3))
+		banner, err = mergeLoginBanner(banner, &model.LoginBanner{})
+		Expect(err).Should(BeNil())
+		Expect(banner.Version).Should(Equal(4))
+		Expect(banner.Content).Should(BeEmpty())
+		_, err = mergeLoginBanner(banner, &model.LoginBanner{RequireConsent: true})
+		Expect(err).Should(Equal(bcode.ErrInvalidLoginBanner))
+	})
+
 	It("Test update dex config", func() {
 		err := k8sClient.Create(context.Background(), &corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
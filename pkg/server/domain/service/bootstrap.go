@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// bootstrapTokenName the primary key of the bootstrap token, there is at most one token
+	bootstrapTokenName = "admin"
+	// bootstrapTokenSecretKey the key of the token in the bootstrap token Secret
+	bootstrapTokenSecretKey = "token"
+	// minBootstrapTokenLength the minimum length of the token provisioned by the Secret
+	minBootstrapTokenLength = 16
+)
+
+// the bootstrap token replaces the initial admin password, they are set from the server config
+var (
+	bootstrapTokenEnabled bool
+	bootstrapTokenSecret  string
+)
+
+// BootstrapService initializes the admin user with the one-time bootstrap token instead of the default password
+type BootstrapService interface {
+	GetBootstrapStatus(ctx context.Context) (*apisv1.BootstrapStatusResponse, error)
+	Bootstrap(ctx context.Context, req apisv1.BootstrapRequest) (*apisv1.UserBase, error)
+	Init(ctx context.Context) error
+}
+
+type bootstrapServiceImpl struct {
+	Store      datastore.DataStore `inject:"datastore"`
+	KubeClient client.Client       `inject:"kubeClient"`
+	SysService SystemInfoService   `inject:""`
+}
+
+// NewBootstrapService new bootstrap service
+func NewBootstrapService() BootstrapService {
+	return &bootstrapServiceImpl{}
+}
+
+// Init issues the bootstrap token if the admin user is not initialized. The token is read from the Secret if it is
+// configured, otherwise a new token is generated and printed on every start until the bootstrap is completed.
+func (b *bootstrapServiceImpl) Init(ctx context.Context) error {
+	if !bootstrapTokenEnabled {
+		return nil
+	}
+	record := &model.BootstrapToken{Name: bootstrapTokenName}
+	if err := b.Store.Get(ctx, record); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil
+		}
+		return err
+	}
+	var token string
+	if bootstrapTokenSecret != "" {
+		t, err := b.loadSecretToken(ctx)
+		if err != nil {
+			return err
+		}
+		token = t
+	} else {
+		t, err := generateSecretToken()
+		if err != nil {
+			return err
+		}
+		token = t
+	}
+	record.TokenHash = hashSecretToken(token)
+	if err := b.Store.Put(ctx, record); err != nil {
+		return err
+	}
+	if bootstrapTokenSecret != "" {
+		klog.Infof("the admin user waits to be initialized with the bootstrap token in the secret %s", bootstrapTokenSecret)
+	} else {
+		klog.Infof("the admin user waits to be initialized, the bootstrap token: %s", token)
+	}
+	return nil
+}
+
+func (b *bootstrapServiceImpl) loadSecretToken(ctx context.Context) (string, error) {
+	namespace, name := types.DefaultKubeVelaNS, bootstrapTokenSecret
+	if strings.Contains(bootstrapTokenSecret, "/") {
+		namespace, name, _ = strings.Cut(bootstrapTokenSecret, "/")
+	}
+	var secret corev1.Secret
+	if err := b.KubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &secret); err != nil {
+		return "", fmt.Errorf("failed to get the bootstrap token secret %w", err)
+	}
+	token := strings.TrimSpace(string(secret.Data[bootstrapTokenSecretKey]))
+	if len(token) < minBootstrapTokenLength {
+		return "", fmt.Errorf("the bootstrap token in the secret %s must have at least %d characters", bootstrapTokenSecret, minBootstrapTokenLength)
+	}
+	return token, nil
+}
+
+// GetBootstrapStatus returns whether the admin user waits to be initialized
+func (b *bootstrapServiceImpl) GetBootstrapStatus(ctx context.Context) (*apisv1.BootstrapStatusResponse, error) {
+	if !bootstrapTokenEnabled {
+		return &apisv1.BootstrapStatusResponse{}, nil
+	}
+	count, err := b.Store.Count(ctx, &model.BootstrapToken{Name: bootstrapTokenName}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &apisv1.BootstrapStatusResponse{Pending: count > 0}, nil
+}
+
+// Bootstrap sets the password and email of the admin user and enables it, the SSO is configured if it is requested.
+// The token is consumed once the bootstrap succeeds.
+func (b *bootstrapServiceImpl) Bootstrap(ctx context.Context, req apisv1.BootstrapRequest) (*apisv1.UserBase, error) {
+	record := &model.BootstrapToken{Name: bootstrapTokenName}
+	if err := b.Store.Get(ctx, record); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrBootstrapCompleted
+		}
+		return nil, err
+	}
+	if record.TokenHash == "" || subtle.ConstantTimeCompare([]byte(record.TokenHash), []byte(hashSecretToken(req.Token))) != 1 {
+		return nil, bcode.ErrBootstrapTokenInvalid
+	}
+	admin := &model.User{Name: model.DefaultAdminUserName}
+	if err := b.Store.Get(ctx, admin); err != nil {
+		return nil, err
+	}
+	encrypted, err := GeneratePasswordHash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	if req.Alias != "" {
+		admin.Alias = req.Alias
+	}
+	admin.Email = req.Email
+	admin.Password = encrypted
+	admin.Disabled = false
+	if err := b.Store.Put(ctx, admin); err != nil {
+		return nil, err
+	}
+	if req.SSO != nil {
+		info, err := b.SysService.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ctx = context.WithValue(ctx, &apisv1.CtxKeyUser, model.DefaultAdminUserName)
+		if _, err := b.SysService.UpdateSystemInfo(ctx, apisv1.SystemInfoRequest{
+			EnableCollection:       info.EnableCollection,
+			LoginType:              model.LoginTypeDex,
+			VelaAddress:            req.SSO.VelaAddress,
+			DexUserDefaultProjects: req.SSO.DexUserDefaultProjects,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.Store.Delete(ctx, record); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	klog.Info("the admin user is initialized with the bootstrap token")
+	return convertUserBase(admin, useGravatar(ctx, b.SysService)), nil
+}
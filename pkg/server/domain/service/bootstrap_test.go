@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the bootstrap token", func() {
+	var (
+		ds               datastore.DataStore
+		userService      *userServiceImpl
+		bootstrapService *bootstrapServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "bootstrap-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		sysService := &systemInfoServiceImpl{Store: ds, KubeClient: k8sClient}
+		userService = &userServiceImpl{Store: ds, SysService: sysService}
+		bootstrapService = &bootstrapServiceImpl{Store: ds, KubeClient: k8sClient, SysService: sysService}
+		bootstrapTokenEnabled = true
+		bootstrapTokenSecret = "default/bootstrap-token"
+	})
+	AfterEach(func() {
+		bootstrapTokenEnabled = false
+		bootstrapTokenSecret = ""
+	})
+
+	It("Test initialize the admin user with the token in the secret", func() {
+		ctx := context.TODO()
+		token := "bootstrap-token-for-test"
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "bootstrap-token", Namespace: "default"},
+			Data:       map[string][]byte{bootstrapTokenSecretKey: []byte(token)},
+		}
+		Expect(k8sClient.Create(ctx, secret)).Should(BeNil())
+
+		Expect(userService.Init(ctx)).Should(BeNil())
+		admin := &model.User{Name: model.DefaultAdminUserName}
+		Expect(ds.Get(ctx, admin)).Should(BeNil())
+		Expect(admin.Disabled).Should(BeTrue())
+		Expect(bootstrapService.Init(ctx)).Should(BeNil())
+
+		status, err := bootstrapService.GetBootstrapStatus(ctx)
+		Expect(err).Should(BeNil())
+		Expect(status.Pending).Should(BeTrue())
+
+		By("the wrong token is rejected")
+		_, err = bootstrapService.Bootstrap(ctx, apisv1.BootstrapRequest{Token: "wrong", Email: "admin@example.com", Password: "Bootstrap123"})
+		Expect(err).Should(Equal(bcode.ErrBootstrapTokenInvalid))
+
+		user, err := bootstrapService.Bootstrap(ctx, apisv1.BootstrapRequest{Token: token, Email: "admin@example.com", Password: "Bootstrap123"})
+		Expect(err).Should(BeNil())
+		Expect(user.Email).Should(Equal("admin@example.com"))
+		Expect(ds.Get(ctx, admin)).Should(BeNil())
+		Expect(admin.Disabled).Should(BeFalse())
+		Expect(compareHashWithPassword(admin.Password, "Bootstrap123")).Should(BeNil())
+
+		By("the token could only be used once")
+		_, err = bootstrapService.Bootstrap(ctx, apisv1.BootstrapRequest{Token: token, Email: "admin@example.com", Password: "Bootstrap123"})
+		Expect(err).Should(Equal(bcode.ErrBootstrapCompleted))
+		status, err = bootstrapService.GetBootstrapStatus(ctx)
+		Expect(err).Should(BeNil())
+		Expect(status.Pending).Should(BeFalse())
+		Expect(bootstrapService.Init(ctx)).Should(BeNil())
+		Expect(k8sClient.Delete(ctx, secret)).Should(BeNil())
+	})
+})
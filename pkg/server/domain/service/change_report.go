@@ -0,0 +1,271 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// ApplicationChangeKindRevision a revision deployed to the env
+	ApplicationChangeKindRevision = "revision"
+	// ApplicationChangeKindConfig an update of the spec of the application
+	ApplicationChangeKindConfig = "config"
+	// ApplicationChangeKindDefinition an upgrade of the definition of the components or the traits
+	ApplicationChangeKindDefinition = "definition"
+	// ApplicationChangeKindEvent a warning event of the application or its resources in the cluster
+	ApplicationChangeKindEvent = "event"
+
+	// maxReportEvents only the latest events are reported, the failing resources emit the same events repeatedly
+	maxReportEvents = 100
+)
+
+// GetChangeReport correlates the revisions, the spec updates, the definition upgrades and the warning events since the application
+// was healthy in the env last time. The last healthy time is when the latest completed revision finished, the events are only
+// available within the TTL of the events of the cluster.
+func (c *applicationServiceImpl) GetChangeReport(ctx context.Context, app *model.Application, envName string) (*apisv1.ApplicationChangeReport, error) {
+	appCR, err := c.GetApplicationCRInEnv(ctx, app, envName)
+	if err != nil {
+		return nil, err
+	}
+	if appCR == nil {
+		return nil, bcode.ErrApplicationNotDeployedInEnv
+	}
+	report := &apisv1.ApplicationChangeReport{
+		EnvName: envName,
+		Phase:   appCR.Status.Phase,
+		Healthy: isApplicationHealthy(&appCR.Status),
+		Changes: []*apisv1.ApplicationChange{},
+	}
+	revisions, err := c.Store.List(ctx, &model.ApplicationRevision{AppPrimaryKey: app.PrimaryKey(), EnvName: envName}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range revisions {
+		revision := entity.(*model.ApplicationRevision)
+		if revision.Status == model.RevisionStatusComplete {
+			report.LastHealthyRevision = revision.Version
+			report.Since = revision.UpdateTime
+			break
+		}
+	}
+	if report.Healthy {
+		return report, nil
+	}
+	if report.LastHealthyRevision == "" {
+		report.Since = app.CreateTime
+	}
+
+	for _, entity := range revisions {
+		revision := entity.(*model.ApplicationRevision)
+		if !revision.CreateTime.After(report.Since) {
+			break
+		}
+		message := fmt.Sprintf("revision %s is %s", revision.Version, revision.Status)
+		if revision.Reason != "" {
+			message += ": " + revision.Reason
+		}
+		report.Changes = append(report.Changes, &apisv1.ApplicationChange{
+			Time:     revision.CreateTime,
+			Kind:     ApplicationChangeKindRevision,
+			Name:     revision.Version,
+			Message:  message,
+			Operator: revision.DeployUser,
+		})
+	}
+	configChanges, err := c.configChangesSince(ctx, app, report.Since)
+	if err != nil {
+		return nil, err
+	}
+	report.Changes = append(report.Changes, configChanges...)
+	definitionChanges, err := c.definitionChangesSince(ctx, app, report.Since)
+	if err != nil {
+		return nil, err
+	}
+	report.Changes = append(report.Changes, definitionChanges...)
+	report.Changes = append(report.Changes, c.eventChangesSince(ctx, appCR, report.Since)...)
+	sort.SliceStable(report.Changes, func(i, j int) bool {
+		return report.Changes[i].Time.Before(report.Changes[j].Time)
+	})
+	return report, nil
+}
+
+// isApplicationHealthy the application is healthy if it is running and all its services are healthy
+func isApplicationHealthy(status *common.AppStatus) bool {
+	if status.Phase != common.ApplicationRunning {
+		return false
+	}
+	for _, service := range status.Services {
+		if !service.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *applicationServiceImpl) configChangesSince(ctx context.Context, app *model.Application, since time.Time) ([]*apisv1.ApplicationChange, error) {
+	audits, err := c.Store.List(ctx, &model.SpecAudit{Resource: SpecAuditResourceApplication, Project: app.Project, Entity: app.Name}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var changes []*apisv1.ApplicationChange
+	for _, entity := range audits {
+		audit := entity.(*model.SpecAudit)
+		if !audit.CreateTime.After(since) {
+			break
+		}
+		target := "the application"
+		if audit.SubResource != "" {
+			target = audit.SubResource
+		}
+		changes = append(changes, &apisv1.ApplicationChange{
+			Time:     audit.CreateTime,
+			Kind:     ApplicationChangeKindConfig,
+			Name:     audit.Name,
+			Message:  fmt.Sprintf("%d fields of %s are changed", len(audit.Changes), target),
+			Operator: audit.Operator,
+			Changes:  audit.Changes,
+		})
+	}
+	return changes, nil
+}
+
+// definitionChangesSince reports the new revisions of the definitions of the components and the traits used by the application
+func (c *applicationServiceImpl) definitionChangesSince(ctx context.Context, app *model.Application, since time.Time) ([]*apisv1.ApplicationChange, error) {
+	components, err := c.Store.List(ctx, &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	definitions := map[string]string{}
+	for _, entity := range components {
+		component := entity.(*model.ApplicationComponent)
+		definitions["component/"+component.Type] = oam.LabelComponentDefinitionName
+		for _, trait := range component.Traits {
+			definitions["trait/"+trait.Type] = oam.LabelTraitDefinitionName
+		}
+	}
+	keys := make([]string, 0, len(definitions))
+	for key := range definitions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var changes []*apisv1.ApplicationChange
+	for _, key := range keys {
+		defType, name, _ := strings.Cut(key, "/")
+		var revisions v1beta1.DefinitionRevisionList
+		if err := c.KubeClient.List(ctx, &revisions, client.InNamespace(types.DefaultKubeVelaNS), client.MatchingLabels{definitions[key]: name}); err != nil {
+			return nil, err
+		}
+		for _, revision := range revisions.Items {
+			if !revision.CreationTimestamp.Time.After(since) {
+				continue
+			}
+			changes = append(changes, &apisv1.ApplicationChange{
+				Time:    revision.CreationTimestamp.Time,
+				Kind:    ApplicationChangeKindDefinition,
+				Name:    revision.Name,
+				Message: fmt.Sprintf("the %s definition %s is upgraded to the revision %d", defType, name, revision.Spec.Revision),
+			})
+		}
+	}
+	return changes, nil
+}
+
+// eventChangesSince reports the warning events of the application and its resources in the local cluster.
+// The resources owned by the applied resources are matched by the name prefix, such as the pods of a deployment.
+func (c *applicationServiceImpl) eventChangesSince(ctx context.Context, appCR *v1beta1.Application, since time.Time) []*apisv1.ApplicationChange {
+	names := map[string][]string{appCR.Namespace: {appCR.Name}}
+	for _, resource := range appCR.Status.AppliedResources {
+		if resource.Cluster != "" && resource.Cluster != multicluster.ClusterLocalName {
+			continue
+		}
+		namespace := resource.Namespace
+		if namespace == "" {
+			namespace = appCR.Namespace
+		}
+		names[namespace] = append(names[namespace], resource.Name)
+	}
+	matched := func(event corev1.Event) bool {
+		for _, name := range names[event.Namespace] {
+			if event.InvolvedObject.Name == name || strings.HasPrefix(event.InvolvedObject.Name, name+"-") {
+				return true
+			}
+		}
+		return false
+	}
+	var changes []*apisv1.ApplicationChange
+	for namespace := range names {
+		var events corev1.EventList
+		if err := c.KubeClient.List(ctx, &events, client.InNamespace(namespace), client.MatchingFields{"type": corev1.EventTypeWarning}); err != nil {
+			klog.Warningf("failed to list the events in the namespace %s: %s", namespace, err.Error())
+			continue
+		}
+		for _, event := range events.Items {
+			eventTime := eventLastTime(event)
+			if !eventTime.After(since) || !matched(event) {
+				continue
+			}
+			changes = append(changes, &apisv1.ApplicationChange{
+				Time:    eventTime,
+				Kind:    ApplicationChangeKindEvent,
+				Name:    fmt.Sprintf("%s/%s", strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name),
+				Message: fmt.Sprintf("%s: %s", event.Reason, event.Message),
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Time.After(changes[j].Time)
+	})
+	if len(changes) > maxReportEvents {
+		changes = changes[:maxReportEvents]
+	}
+	return changes
+}
+
+func eventLastTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.FirstTimestamp.Time
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the change report of the applications", func() {
+	var (
+		ds         datastore.DataStore
+		appService *applicationServiceImpl
+		namespace  = "change-report"
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "change-report-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		envService := &envServiceImpl{Store: ds, KubeClient: k8sClient}
+		appService = &applicationServiceImpl{
+			Store:             ds,
+			KubeClient:        k8sClient,
+			EnvService:        envService,
+			EnvBindingService: &envBindingServiceImpl{Store: ds, EnvService: envService, KubeClient: k8sClient},
+		}
+		for _, ns := range []string{namespace, types.DefaultKubeVelaNS} {
+			Expect(k8sClient.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
+		}
+	})
+
+	It("Test report the changes since the last healthy revision", func() {
+		ctx := context.TODO()
+		app := &model.Application{Name: "change-app", Project: "change-project"}
+		Expect(ds.Add(ctx, app)).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Env{Name: "change-dev", Namespace: namespace})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.EnvBinding{AppPrimaryKey: app.PrimaryKey(), Name: "change-dev", AppDeployName: "change-app"})).Should(BeNil())
+
+		_, err := appService.GetChangeReport(ctx, app, "change-dev")
+		Expect(err).Should(Equal(bcode.ErrApplicationNotDeployedInEnv))
+
+		appCR := &v1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "change-app", Namespace: namespace},
+			Spec:       v1beta1.ApplicationSpec{Components: []common.ApplicationComponent{}},
+		}
+		Expect(k8sClient.Create(ctx, appCR)).Should(BeNil())
+		appCR.Status.Phase = common.ApplicationRunning
+		appCR.Status.Services = []common.ApplicationComponentStatus{{Name: "web", Healthy: true}}
+		appCR.Status.AppliedResources = []common.ClusterObjectReference{{ObjectReference: corev1.ObjectReference{Kind: "Deployment", Name: "change-web", Namespace: namespace}}}
+		Expect(k8sClient.Status().Update(ctx, appCR)).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ApplicationRevision{AppPrimaryKey: app.PrimaryKey(), Version: "v1", EnvName: "change-dev", Status: model.RevisionStatusComplete})).Should(BeNil())
+
+		report, err := appService.GetChangeReport(ctx, app, "change-dev")
+		Expect(err).Should(BeNil())
+		Expect(report.Healthy).Should(BeTrue())
+		Expect(report.LastHealthyRevision).Should(Equal("v1"))
+		Expect(report.Changes).Should(BeEmpty())
+
+		By("the application gets unhealthy after some changes")
+		// the timestamps of the objects in the cluster are in seconds
+		time.Sleep(1100 * time.Millisecond)
+		Expect(ds.Add(ctx, &model.SpecAudit{Name: "change-audit", Resource: SpecAuditResourceApplication, Project: "change-project", Entity: "change-app", SubResource: "component/web",
+			Changes: []model.SpecChange{{Path: "properties.image", Operation: "replace", From: `"nginx:1.20"`, To: `"nginx:1.21"`}}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey(), Name: "web", Type: "change-webservice"})).Should(BeNil())
+		Expect(k8sClient.Create(ctx, &v1beta1.DefinitionRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: "change-webservice-v2", Namespace: types.DefaultKubeVelaNS, Labels: map[string]string{oam.LabelComponentDefinitionName: "change-webservice"}},
+			Spec: v1beta1.DefinitionRevisionSpec{
+				Revision:       2,
+				RevisionHash:   "change-webservice-hash",
+				DefinitionType: common.ComponentType,
+				ComponentDefinition: v1beta1.ComponentDefinition{
+					Spec: v1beta1.ComponentDefinitionSpec{Workload: common.WorkloadTypeDescriptor{Definition: common.WorkloadGVK{APIVersion: "apps/v1", Kind: "Deployment"}}},
+				},
+			},
+		})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ApplicationRevision{AppPrimaryKey: app.PrimaryKey(), Version: "v2", EnvName: "change-dev", Status: model.RevisionStatusFail, Reason: "the step deploy is failed", DeployUser: "admin"})).Should(BeNil())
+		now := metav1.Now()
+		for _, event := range []corev1.Event{
+			{ObjectMeta: metav1.ObjectMeta{Name: "change-web-backoff"}, InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "change-web-5d8f-abcde", Namespace: namespace}, Type: corev1.EventTypeWarning, Reason: "BackOff", Message: "Back-off restarting failed container"},
+			{ObjectMeta: metav1.ObjectMeta{Name: "change-web-pulled"}, InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "change-web-5d8f-abcde", Namespace: namespace}, Type: corev1.EventTypeNormal, Reason: "Pulled"},
+			{ObjectMeta: metav1.ObjectMeta{Name: "other-app-backoff"}, InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "other-app-abcde", Namespace: namespace}, Type: corev1.EventTypeWarning, Reason: "BackOff"},
+		} {
+			event := event
+			event.Namespace = namespace
+			event.Source = corev1.EventSource{Component: "kubelet"}
+			event.FirstTimestamp, event.LastTimestamp = now, now
+			Expect(k8sClient.Create(ctx, &event)).Should(BeNil())
+		}
+		appCR.Status.Phase = common.ApplicationUnhealthy
+		appCR.Status.Services = []common.ApplicationComponentStatus{{Name: "web", Healthy: false}}
+		Expect(k8sClient.Status().Update(ctx, appCR)).Should(BeNil())
+
+		report, err = appService.GetChangeReport(ctx, app, "change-dev")
+		Expect(err).Should(BeNil())
+		Expect(report.Healthy).Should(BeFalse())
+		Expect(report.Phase).Should(Equal(common.ApplicationUnhealthy))
+		Expect(report.LastHealthyRevision).Should(Equal("v1"))
+		var kinds, names []string
+		for _, change := range report.Changes {
+			kinds = append(kinds, change.Kind)
+			names = append(names, change.Name)
+			Expect(change.Time.Before(report.Since)).Should(BeFalse())
+		}
+		Expect(kinds).Should(ConsistOf(ApplicationChangeKindConfig, ApplicationChangeKindDefinition, ApplicationChangeKindRevision, ApplicationChangeKindEvent))
+		Expect(names).Should(ContainElements("change-audit", "change-webservice-v2", "v2", "pod/change-web-5d8f-abcde"))
+		Expect(report.Changes[0].Time.After(report.Changes[len(report.Changes)-1].Time)).Should(BeFalse())
+	})
+})
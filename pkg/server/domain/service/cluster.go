@@ -26,8 +26,11 @@ import (
 	"github.com/pkg/errors"
 	v12 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -66,6 +69,12 @@ type ClusterService interface {
 	GetCloudClusterCreationStatus(context.Context, string, string) (*apis.CreateCloudClusterResponse, error)
 	ListCloudClusterCreation(context.Context, string) (*apis.ListCloudClusterCreationResponse, error)
 	DeleteCloudClusterCreation(context.Context, string, string) (*apis.CreateCloudClusterResponse, error)
+
+	CreateCAPICluster(context.Context, apis.CreateCAPIClusterRequest) (*apis.CAPIClusterBase, error)
+	ListCAPIClusters(context.Context) (*apis.ListCAPIClustersResponse, error)
+	ScaleCAPICluster(context.Context, string, apis.ScaleCAPIClusterRequest) (*apis.CAPIClusterBase, error)
+	DeleteCAPICluster(context.Context, string) (*apis.CAPIClusterBase, error)
+	SyncCAPIClusters(context.Context) error
 	Init(ctx context.Context) error
 }
 
@@ -73,7 +82,9 @@ type clusterServiceImpl struct {
 	Store      datastore.DataStore `inject:"datastore"`
 	K8sClient  client.Client       `inject:"kubeClient"`
 	KubeConfig *rest.Config        `inject:"kubeConfig"`
-	caches     *utils2.MemoryCacheStore
+	// TargetService creates the targets of the joined cluster api clusters
+	TargetService TargetService `inject:""`
+	caches        *utils2.MemoryCacheStore
 }
 
 // NewClusterService new cluster service
@@ -183,6 +194,15 @@ func (c *clusterServiceImpl) ListKubeClusters(ctx context.Context, query string,
 			clusterModel = newClusterModelFromPrismCluster(cluster.DeepCopy())
 		}
 		resp.Clusters = append(resp.Clusters, *newClusterBaseFromCluster(clusterModel))
+		delete(clustersInfoMap, cluster.Name)
+	}
+	// the cluster api clusters are listed with their lifecycle phases before they are joined
+	for _, clusterInfo := range clustersInfoMap {
+		if clusterInfo.Provider.Provider != model.ClusterProviderCAPI || !strings.Contains(clusterInfo.Name, query) {
+			continue
+		}
+		resp.Total++
+		resp.Clusters = append(resp.Clusters, *newClusterBaseFromCluster(clusterInfo))
 	}
 	if page <= 0 {
 		return resp, nil
@@ -665,3 +685,314 @@ func newClusterModelFromPrismCluster(cluster *prismclusterv1alpha1.Cluster) *mod
 		APIServerURL: cluster.Spec.Endpoint,
 	}
 }
+
+const (
+	capiPhaseProvisioned = "Provisioned"
+	capiPhasePending     = "Pending"
+	// annotationCAPITargetProject the project of the target created when the cluster is joined
+	annotationCAPITargetProject = "ux.oam.dev/target-project"
+	// annotationCAPITargetNamespace the namespace of the target created when the cluster is joined
+	annotationCAPITargetNamespace = "ux.oam.dev/target-namespace"
+)
+
+var capiClusterGVK = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Cluster"}
+
+func newCAPICluster() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(capiClusterGVK)
+	return obj
+}
+
+func (c *clusterServiceImpl) listCAPIClusters(ctx context.Context) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(capiClusterGVK.GroupVersion().WithKind(capiClusterGVK.Kind + "List"))
+	if err := c.K8sClient.List(ctx, list, client.HasLabels{model.LabelCAPICluster}); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, bcode.ErrCAPINotInstalled
+		}
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c *clusterServiceImpl) getCAPICluster(ctx context.Context, clusterName string) (*unstructured.Unstructured, error) {
+	items, err := c.listCAPIClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].GetName() == clusterName {
+			return &items[i], nil
+		}
+	}
+	return nil, bcode.ErrCAPIClusterNotFound
+}
+
+// CreateCAPICluster creates a workload cluster from the cluster class, it is joined by SyncCAPIClusters once provisioned
+func (c *clusterServiceImpl) CreateCAPICluster(ctx context.Context, req apis.CreateCAPIClusterRequest) (*apis.CAPIClusterBase, error) {
+	if req.Name == multicluster.ClusterLocalName {
+		return nil, bcode.ErrLocalClusterReserved
+	}
+	if err := c.Store.Get(ctx, &model.Cluster{Name: req.Name}); err == nil {
+		return nil, bcode.ErrClusterAlreadyExistInDataStore
+	} else if !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	if req.Target != nil {
+		if err := c.Store.Get(ctx, &model.Project{Name: req.Target.Project}); err != nil {
+			return nil, bcode.ErrProjectIsNotExist
+		}
+	}
+	if req.Namespace == "" {
+		req.Namespace = v1.NamespaceDefault
+	}
+	obj := newCAPICluster()
+	obj.SetName(req.Name)
+	obj.SetNamespace(req.Namespace)
+	obj.SetLabels(map[string]string{model.LabelCAPICluster: "true"})
+	if req.Target != nil {
+		obj.SetAnnotations(map[string]string{
+			annotationCAPITargetProject:   req.Target.Project,
+			annotationCAPITargetNamespace: req.Target.Namespace,
+		})
+	}
+	topology := map[string]interface{}{
+		"class":   req.ClusterClass,
+		"version": req.KubernetesVersion,
+	}
+	if req.ControlPlaneReplicas > 0 {
+		topology["controlPlane"] = map[string]interface{}{"replicas": req.ControlPlaneReplicas}
+	}
+	var machineDeployments []interface{}
+	for _, worker := range req.Workers {
+		class := worker.Class
+		if class == "" {
+			class = "default-worker"
+		}
+		machineDeployments = append(machineDeployments, map[string]interface{}{
+			"class":    class,
+			"name":     worker.Name,
+			"replicas": worker.Replicas,
+		})
+	}
+	if len(machineDeployments) > 0 {
+		topology["workers"] = map[string]interface{}{"machineDeployments": machineDeployments}
+	}
+	if err := unstructured.SetNestedMap(obj.Object, topology, "spec", "topology"); err != nil {
+		return nil, err
+	}
+	if err := c.K8sClient.Create(ctx, obj); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, bcode.ErrCAPINotInstalled
+		}
+		if kerrors.IsAlreadyExists(err) {
+			return nil, bcode.ErrClusterExistsInKubernetes
+		}
+		return nil, errors.Wrapf(err, "failed to create the cluster api cluster %s", req.Name)
+	}
+	cluster := &model.Cluster{
+		Name:        req.Name,
+		Alias:       req.Alias,
+		Description: req.Description,
+		Labels:      req.Labels,
+		Provider: model.ProviderInfo{
+			Provider:    model.ClusterProviderCAPI,
+			ClusterName: req.Name,
+			ClusterID:   fmt.Sprintf("%s/%s", req.Namespace, req.Name),
+		},
+		Status: capiPhasePending,
+	}
+	if err := c.Store.Add(ctx, cluster); err != nil {
+		if err := c.K8sClient.Delete(ctx, obj); err != nil {
+			klog.Errorf("failed to rollback the cluster api cluster %s: %s", req.Name, err.Error())
+		}
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrClusterAlreadyExistInDataStore
+		}
+		return nil, err
+	}
+	return convertCAPICluster2Base(obj, false), nil
+}
+
+// ListCAPIClusters lists the workload clusters managed by VelaUX
+func (c *clusterServiceImpl) ListCAPIClusters(ctx context.Context) (*apis.ListCAPIClustersResponse, error) {
+	items, err := c.listCAPIClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &apis.ListCAPIClustersResponse{Clusters: []apis.CAPIClusterBase{}}
+	for i := range items {
+		cluster, err := c.getClusterFromDataStore(ctx, items[i].GetName())
+		joined := err == nil && cluster.APIServerURL != ""
+		resp.Clusters = append(resp.Clusters, *convertCAPICluster2Base(&items[i], joined))
+	}
+	return resp, nil
+}
+
+// ScaleCAPICluster changes the replicas of the control plane or the worker pools
+func (c *clusterServiceImpl) ScaleCAPICluster(ctx context.Context, clusterName string, req apis.ScaleCAPIClusterRequest) (*apis.CAPIClusterBase, error) {
+	obj, err := c.getCAPICluster(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if req.ControlPlaneReplicas != nil {
+		if err := unstructured.SetNestedField(obj.Object, *req.ControlPlaneReplicas, "spec", "topology", "controlPlane", "replicas"); err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Workers) > 0 {
+		machineDeployments, _, err := unstructured.NestedSlice(obj.Object, "spec", "topology", "workers", "machineDeployments")
+		if err != nil {
+			return nil, err
+		}
+		for _, worker := range req.Workers {
+			found := false
+			for _, md := range machineDeployments {
+				if m, ok := md.(map[string]interface{}); ok && m["name"] == worker.Name {
+					m["replicas"] = worker.Replicas
+					found = true
+				}
+			}
+			if !found {
+				return nil, bcode.ErrCAPIClusterWorkerPoolNotFound
+			}
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, machineDeployments, "spec", "topology", "workers", "machineDeployments"); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.K8sClient.Update(ctx, obj); err != nil {
+		return nil, errors.Wrapf(err, "failed to scale the cluster api cluster %s", clusterName)
+	}
+	cluster, err := c.getClusterFromDataStore(ctx, clusterName)
+	return convertCAPICluster2Base(obj, err == nil && cluster.APIServerURL != ""), nil
+}
+
+// DeleteCAPICluster detaches the workload cluster and deletes it from the infrastructure
+func (c *clusterServiceImpl) DeleteCAPICluster(ctx context.Context, clusterName string) (*apis.CAPIClusterBase, error) {
+	obj, err := c.getCAPICluster(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := c.getClusterFromDataStore(ctx, clusterName)
+	if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	if cluster != nil {
+		if cluster.APIServerURL != "" {
+			if _, err := c.DeleteKubeCluster(ctx, clusterName); err != nil {
+				return nil, err
+			}
+		} else if err := c.Store.Delete(ctx, cluster); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, err
+		}
+	}
+	if err := c.K8sClient.Delete(ctx, obj); err != nil && !kerrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to delete the cluster api cluster %s", clusterName)
+	}
+	return convertCAPICluster2Base(obj, false), nil
+}
+
+// SyncCAPIClusters surfaces the lifecycle phases of the workload clusters and joins the provisioned ones
+func (c *clusterServiceImpl) SyncCAPIClusters(ctx context.Context) error {
+	items, err := c.listCAPIClusters(ctx)
+	if err != nil {
+		if errors.Is(err, bcode.ErrCAPINotInstalled) {
+			return nil
+		}
+		return err
+	}
+	for i := range items {
+		obj := &items[i]
+		cluster, err := c.getClusterFromDataStore(ctx, obj.GetName())
+		if err != nil {
+			if !errors.Is(err, datastore.ErrRecordNotExist) {
+				klog.Errorf("failed to get the cluster %s: %s", obj.GetName(), err.Error())
+			}
+			continue
+		}
+		if cluster.APIServerURL != "" {
+			continue
+		}
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		ready, _, _ := unstructured.NestedBool(obj.Object, "status", "controlPlaneReady")
+		if phase == capiPhaseProvisioned && ready {
+			if err := c.joinCAPICluster(ctx, obj, cluster); err != nil {
+				klog.Errorf("failed to join the cluster api cluster %s: %s", obj.GetName(), err.Error())
+			}
+			continue
+		}
+		if phase != "" && phase != cluster.Status {
+			cluster.Status = phase
+			if err := c.Store.Put(ctx, cluster); err != nil {
+				klog.Errorf("failed to update the status of the cluster %s: %s", obj.GetName(), err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (c *clusterServiceImpl) joinCAPICluster(ctx context.Context, obj *unstructured.Unstructured, cluster *model.Cluster) error {
+	var secret v12.Secret
+	if err := c.K8sClient.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName() + "-kubeconfig"}, &secret); err != nil {
+		return errors.Wrapf(err, "failed to get the kubeconfig secret")
+	}
+	kubeConfig := string(secret.Data["value"])
+	if kubeConfig == "" {
+		return fmt.Errorf("the kubeconfig secret of the cluster %s is empty", obj.GetName())
+	}
+	apiServerURL, err := joinClusterByKubeConfigString(context.WithValue(ctx, multicluster.KubeConfigContext, c.KubeConfig), c.K8sClient, cluster.Name, kubeConfig)
+	if err != nil {
+		return err
+	}
+	cluster.APIServerURL = apiServerURL
+	cluster.KubeConfig = kubeConfig
+	c.setClusterStatusAndResourceInfo(ctx, cluster)
+	if err := c.Store.Put(ctx, cluster); err != nil {
+		c.rollbackJoinedKubeCluster(ctx, cluster)
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if project := annotations[annotationCAPITargetProject]; project != "" && c.TargetService != nil {
+		_, err := c.TargetService.CreateTarget(ctx, apis.CreateTargetRequest{
+			Name:    cluster.Name,
+			Alias:   cluster.Alias,
+			Project: project,
+			Cluster: &apis.ClusterTarget{ClusterName: cluster.Name, Namespace: annotations[annotationCAPITargetNamespace]},
+		})
+		if err != nil {
+			klog.Errorf("failed to create the target of the cluster %s: %s", cluster.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func convertCAPICluster2Base(obj *unstructured.Unstructured, joined bool) *apis.CAPIClusterBase {
+	base := &apis.CAPIClusterBase{
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		Joined:     joined,
+		CreateTime: obj.GetCreationTimestamp().Time,
+		Workers:    []apis.CAPIWorkerPool{},
+	}
+	base.ClusterClass, _, _ = unstructured.NestedString(obj.Object, "spec", "topology", "class")
+	base.KubernetesVersion, _, _ = unstructured.NestedString(obj.Object, "spec", "topology", "version")
+	base.ControlPlaneReplicas, _, _ = unstructured.NestedInt64(obj.Object, "spec", "topology", "controlPlane", "replicas")
+	base.Phase, _, _ = unstructured.NestedString(obj.Object, "status", "phase")
+	if base.Phase == "" {
+		base.Phase = capiPhasePending
+	}
+	machineDeployments, _, _ := unstructured.NestedSlice(obj.Object, "spec", "topology", "workers", "machineDeployments")
+	for _, md := range machineDeployments {
+		m, ok := md.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		worker := apis.CAPIWorkerPool{}
+		worker.Name, _, _ = unstructured.NestedString(m, "name")
+		worker.Class, _, _ = unstructured.NestedString(m, "class")
+		worker.Replicas, _, _ = unstructured.NestedInt64(m, "replicas")
+		base.Workers = append(base.Workers, worker)
+	}
+	return base
+}
@@ -35,6 +35,7 @@ import (
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	"github.com/kubevela/velaux/pkg/server/utils"
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
@@ -150,6 +151,42 @@ var _ = Describe("Test cluster service function", func() {
 		Expect(err).Should(Succeed())
 		Expect(len(resp.Clusters)).Should(Equal(0))
 	})
+
+	It("Test the cluster api clusters", func() {
+		service := clusterServiceImpl{
+			Store:     ds,
+			caches:    cache,
+			K8sClient: k8sClient,
+		}
+		_, err := service.CreateCAPICluster(ctx, apisv1.CreateCAPIClusterRequest{Name: "capi-cluster", ClusterClass: "quick-start", KubernetesVersion: "v1.26.0"})
+		Expect(err).Should(Equal(bcode.ErrCAPINotInstalled))
+		Expect(service.SyncCAPIClusters(ctx)).Should(Succeed())
+
+		Expect(ds.Add(ctx, &model.Cluster{Name: "capi-cluster", Status: "Provisioning", Provider: model.ProviderInfo{Provider: model.ClusterProviderCAPI}})).Should(Succeed())
+		resp, err := service.ListKubeClusters(ctx, "capi", 0, 0)
+		Expect(err).Should(Succeed())
+		Expect(len(resp.Clusters)).Should(Equal(1))
+		Expect(resp.Clusters[0].Status).Should(Equal("Provisioning"))
+
+		obj := newCAPICluster()
+		obj.SetName("capi-cluster")
+		obj.SetNamespace("default")
+		obj.Object["spec"] = map[string]interface{}{"topology": map[string]interface{}{
+			"class":        "quick-start",
+			"version":      "v1.26.0",
+			"controlPlane": map[string]interface{}{"replicas": int64(3)},
+			"workers": map[string]interface{}{"machineDeployments": []interface{}{
+				map[string]interface{}{"class": "default-worker", "name": "md-0", "replicas": int64(2)},
+			}},
+		}}
+		obj.Object["status"] = map[string]interface{}{"phase": "Provisioned"}
+		base := convertCAPICluster2Base(obj, true)
+		Expect(base.ClusterClass).Should(Equal("quick-start"))
+		Expect(base.ControlPlaneReplicas).Should(Equal(int64(3)))
+		Expect(base.Workers).Should(Equal([]apisv1.CAPIWorkerPool{{Name: "md-0", Class: "default-worker", Replicas: 2}}))
+		Expect(base.Phase).Should(Equal("Provisioned"))
+		Expect(base.Joined).Should(BeTrue())
+	})
 })
 
 //type fakePrismClusterClient struct {
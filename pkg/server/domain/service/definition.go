@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/oam-dev/kubevela/pkg/utils/addon"
 	"github.com/oam-dev/kubevela/pkg/utils/filters"
@@ -43,6 +44,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/utils"
 
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	apiutils "github.com/kubevela/velaux/pkg/server/utils"
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
 
@@ -63,6 +65,8 @@ const DefinitionHidden = "true"
 
 type definitionServiceImpl struct {
 	KubeClient client.Client `inject:"kubeClient"`
+	// schemaCache caches the parsed API schemas of the definitions, they only change when the definitions are upgraded
+	schemaCache *apiutils.LRUCache
 }
 
 // DefinitionQueryOption define a set of query options
@@ -89,7 +93,7 @@ const (
 
 // NewDefinitionService new definition service
 func NewDefinitionService() DefinitionService {
-	return &definitionServiceImpl{}
+	return &definitionServiceImpl{schemaCache: apiutils.NewLRUCache(512, time.Minute)}
 }
 
 func (d *definitionServiceImpl) ListDefinitions(ctx context.Context, ops DefinitionQueryOption) ([]*apisv1.DefinitionBase, error) {
@@ -253,26 +257,18 @@ func (d *definitionServiceImpl) DetailDefinition(ctx context.Context, name, defT
 	if err != nil {
 		return nil, err
 	}
-	var cm v1.ConfigMap
-	if err := d.KubeClient.Get(ctx, k8stypes.NamespacedName{
-		Namespace: types.DefaultKubeVelaNS,
-		Name:      fmt.Sprintf("%s-schema-%s", defType, name),
-	}, &cm); err != nil && !apierrors.IsNotFound(err) {
+	apiSchema, err := d.getAPISchema(ctx, name, defType)
+	if err != nil {
 		return nil, err
 	}
 
 	definition := &apisv1.DetailDefinitionResponse{
 		DefinitionBase: *base,
 	}
-	data, ok := cm.Data[types.OpenapiV3JSONSchema]
-	if ok {
-		schema := &openapi3.Schema{}
-		if err := schema.UnmarshalJSON([]byte(data)); err != nil {
-			return nil, err
-		}
-		definition.APISchema = schema
+	if apiSchema != nil {
+		definition.APISchema = apiSchema
 		// render default ui schema
-		defaultUISchema := renderDefaultUISchema(schema)
+		defaultUISchema := renderDefaultUISchema(apiSchema)
 		// patch from custom ui schema
 		definition.UISchema = renderCustomUISchema(ctx, d.KubeClient, name, defType, defaultUISchema)
 	}
@@ -280,6 +276,30 @@ func (d *definitionServiceImpl) DetailDefinition(ctx context.Context, name, defT
 	return definition, nil
 }
 
+// getAPISchema returns the API schema of the definition, it is nil if the schema is not generated
+func (d *definitionServiceImpl) getAPISchema(ctx context.Context, name, defType string) (*openapi3.Schema, error) {
+	cmName := fmt.Sprintf("%s-schema-%s", defType, name)
+	if cached, ok := d.schemaCache.Get(cmName); ok {
+		return cached.(*openapi3.Schema), nil
+	}
+	var cm v1.ConfigMap
+	if err := d.KubeClient.Get(ctx, k8stypes.NamespacedName{
+		Namespace: types.DefaultKubeVelaNS,
+		Name:      cmName,
+	}, &cm); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	var apiSchema *openapi3.Schema
+	if data, ok := cm.Data[types.OpenapiV3JSONSchema]; ok {
+		apiSchema = &openapi3.Schema{}
+		if err := apiSchema.UnmarshalJSON([]byte(data)); err != nil {
+			return nil, err
+		}
+	}
+	d.schemaCache.Put(cmName, apiSchema)
+	return apiSchema, nil
+}
+
 func renderCustomUISchema(ctx context.Context, cli client.Client, name, defType string, defaultSchema []*schema.UIParameter) []*schema.UIParameter {
 	var cm v1.ConfigMap
 	if err := cli.Get(ctx, k8stypes.NamespacedName{
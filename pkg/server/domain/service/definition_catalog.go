@@ -0,0 +1,445 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/definition"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// LabelDefinitionCatalog marks the definitions imported from the definition catalog
+	LabelDefinitionCatalog = "ux.oam.dev/definition-catalog"
+	// AnnotationCatalogVersion the version of the definition in the catalog
+	AnnotationCatalogVersion = "ux.oam.dev/catalog-version"
+	// AnnotationCatalogRevision the commit of the catalog that imports the definition
+	AnnotationCatalogRevision = "ux.oam.dev/catalog-revision"
+)
+
+// DefinitionCatalogService manages the Git repositories that distribute the definitions
+type DefinitionCatalogService interface {
+	ListDefinitionCatalogs(ctx context.Context) (*apisv1.ListDefinitionCatalogsResponse, error)
+	GetDefinitionCatalog(ctx context.Context, name string) (*apisv1.DefinitionCatalogBase, error)
+	CreateDefinitionCatalog(ctx context.Context, req apisv1.CreateDefinitionCatalogRequest) (*apisv1.DefinitionCatalogBase, error)
+	UpdateDefinitionCatalog(ctx context.Context, name string, req apisv1.UpdateDefinitionCatalogRequest) (*apisv1.DefinitionCatalogBase, error)
+	DeleteDefinitionCatalog(ctx context.Context, name string) error
+	// SyncDefinitionCatalog imports the definitions from the latest commit of the catalog
+	SyncDefinitionCatalog(ctx context.Context, name string) (*apisv1.DefinitionCatalogBase, error)
+	// SyncDefinitionCatalogs syncs all catalogs, it is called by the sync worker
+	SyncDefinitionCatalogs(ctx context.Context) error
+}
+
+type definitionCatalogServiceImpl struct {
+	Store      datastore.DataStore `inject:"datastore"`
+	KubeClient client.Client       `inject:"kubeClient"`
+	KubeConfig *rest.Config        `inject:"kubeConfig"`
+	SysService SystemInfoService   `inject:""`
+}
+
+// NewDefinitionCatalogService new definition catalog service
+func NewDefinitionCatalogService() DefinitionCatalogService {
+	return &definitionCatalogServiceImpl{}
+}
+
+// catalogSnapshot is the definitions read from a commit of the catalog
+type catalogSnapshot struct {
+	revision    string
+	definitions []*definition.Definition
+	versions    map[string]string
+}
+
+func (d *definitionCatalogServiceImpl) getCatalog(ctx context.Context, name string) (*model.DefinitionCatalog, error) {
+	catalog := &model.DefinitionCatalog{Name: name}
+	if err := d.Store.Get(ctx, catalog); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrDefinitionCatalogNotExist
+		}
+		return nil, err
+	}
+	return catalog, nil
+}
+
+func (d *definitionCatalogServiceImpl) ListDefinitionCatalogs(ctx context.Context) (*apisv1.ListDefinitionCatalogsResponse, error) {
+	entities, err := d.Store.List(ctx, &model.DefinitionCatalog{}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListDefinitionCatalogsResponse{Catalogs: []*apisv1.DefinitionCatalogBase{}}
+	for _, entity := range entities {
+		res.Catalogs = append(res.Catalogs, convertDefinitionCatalog2Base(entity.(*model.DefinitionCatalog)))
+	}
+	return res, nil
+}
+
+func (d *definitionCatalogServiceImpl) GetDefinitionCatalog(ctx context.Context, name string) (*apisv1.DefinitionCatalogBase, error) {
+	catalog, err := d.getCatalog(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return convertDefinitionCatalog2Base(catalog), nil
+}
+
+func (d *definitionCatalogServiceImpl) CreateDefinitionCatalog(ctx context.Context, req apisv1.CreateDefinitionCatalogRequest) (*apisv1.DefinitionCatalogBase, error) {
+	catalog := &model.DefinitionCatalog{
+		Name:        req.Name,
+		Alias:       req.Alias,
+		Description: req.Description,
+		URL:         req.URL,
+		Branch:      req.Branch,
+		Path:        req.Path,
+		Username:    req.Username,
+		Token:       req.Token,
+		VerifyKeys:  req.VerifyKeys,
+	}
+	if err := d.Store.Add(ctx, catalog); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrDefinitionCatalogExist
+		}
+		return nil, err
+	}
+	return convertDefinitionCatalog2Base(catalog), nil
+}
+
+func (d *definitionCatalogServiceImpl) UpdateDefinitionCatalog(ctx context.Context, name string, req apisv1.UpdateDefinitionCatalogRequest) (*apisv1.DefinitionCatalogBase, error) {
+	catalog, err := d.getCatalog(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	// the definitions are imported again from the new source
+	if catalog.URL != req.URL || catalog.Branch != req.Branch || catalog.Path != req.Path || catalog.VerifyKeys != req.VerifyKeys {
+		catalog.Revision = ""
+	}
+	catalog.Alias = req.Alias
+	catalog.Description = req.Description
+	catalog.URL = req.URL
+	catalog.Branch = req.Branch
+	catalog.Path = req.Path
+	catalog.Username = req.Username
+	catalog.VerifyKeys = req.VerifyKeys
+	if req.Token != "" {
+		catalog.Token = req.Token
+	}
+	if err := d.Store.Put(ctx, catalog); err != nil {
+		return nil, err
+	}
+	return convertDefinitionCatalog2Base(catalog), nil
+}
+
+// DeleteDefinitionCatalog deletes the catalog, the imported definitions are kept because the applications may use them
+func (d *definitionCatalogServiceImpl) DeleteDefinitionCatalog(ctx context.Context, name string) error {
+	catalog, err := d.getCatalog(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, def := range catalog.Definitions {
+		if err := d.releaseDefinition(ctx, name, def); err != nil {
+			return err
+		}
+	}
+	if err := d.Store.Delete(ctx, catalog); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrDefinitionCatalogNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *definitionCatalogServiceImpl) SyncDefinitionCatalogs(ctx context.Context) error {
+	if isOfflineMode(ctx, d.SysService) {
+		return nil
+	}
+	entities, err := d.Store.List(ctx, &model.DefinitionCatalog{}, nil)
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		catalog := entity.(*model.DefinitionCatalog)
+		if err := d.syncCatalog(ctx, catalog); err != nil {
+			klog.Errorf("failed to sync the definition catalog %s: %s", catalog.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func (d *definitionCatalogServiceImpl) SyncDefinitionCatalog(ctx context.Context, name string) (*apisv1.DefinitionCatalogBase, error) {
+	if isOfflineMode(ctx, d.SysService) {
+		return nil, bcode.ErrDefinitionCatalogOffline
+	}
+	catalog, err := d.getCatalog(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.syncCatalog(ctx, catalog); err != nil {
+		return nil, err
+	}
+	return convertDefinitionCatalog2Base(catalog), nil
+}
+
+// syncCatalog imports the definitions of the catalog and records the result to the catalog
+func (d *definitionCatalogServiceImpl) syncCatalog(ctx context.Context, catalog *model.DefinitionCatalog) error {
+	syncErr := d.importCatalog(ctx, catalog)
+	catalog.LastSyncTime = time.Now()
+	if syncErr != nil {
+		catalog.Status = model.DefinitionCatalogStatusFailed
+		catalog.Message = syncErr.Error()
+	} else {
+		catalog.Status = model.DefinitionCatalogStatusSynced
+	}
+	if err := d.Store.Put(ctx, catalog); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+func (d *definitionCatalogServiceImpl) importCatalog(ctx context.Context, catalog *model.DefinitionCatalog) error {
+	snapshot, err := fetchCatalogSnapshot(ctx, catalog, d.KubeConfig)
+	if err != nil {
+		return err
+	}
+	if snapshot.revision == catalog.Revision {
+		return nil
+	}
+	var imported []model.CatalogDefinition
+	var messages []string
+	for _, def := range snapshot.definitions {
+		version := snapshot.versions[def.GetKind()+"/"+def.GetName()]
+		catalogDef := model.CatalogDefinition{Name: def.GetName(), Kind: def.GetKind(), Version: version, Revision: snapshot.revision}
+		for _, previous := range catalog.Definitions {
+			if previous.Name == catalogDef.Name && previous.Kind == catalogDef.Kind && previous.Version == version {
+				catalogDef.Revision = previous.Revision
+			}
+		}
+		if err := d.applyDefinition(ctx, catalog.Name, def, catalogDef); err != nil {
+			messages = append(messages, err.Error())
+			continue
+		}
+		imported = append(imported, catalogDef)
+	}
+	// prune the definitions that are removed from the catalog
+	for _, previous := range catalog.Definitions {
+		removed := true
+		for _, def := range imported {
+			if def.Name == previous.Name && def.Kind == previous.Kind {
+				removed = false
+			}
+		}
+		if removed {
+			if err := d.deleteDefinition(ctx, catalog.Name, previous); err != nil {
+				messages = append(messages, err.Error())
+			}
+		}
+	}
+	catalog.Definitions = imported
+	catalog.Revision = snapshot.revision
+	catalog.Message = strings.Join(messages, "; ")
+	return nil
+}
+
+// applyDefinition creates or upgrades the definition, the definitions not imported by this catalog are not overwritten
+func (d *definitionCatalogServiceImpl) applyDefinition(ctx context.Context, catalogName string, def *definition.Definition, catalogDef model.CatalogDefinition) error {
+	if def.GetNamespace() == "" {
+		def.SetNamespace(types.DefaultKubeVelaNS)
+	}
+	labels := def.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[LabelDefinitionCatalog] = catalogName
+	def.SetLabels(labels)
+	annotations := def.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationCatalogVersion] = catalogDef.Version
+	annotations[AnnotationCatalogRevision] = catalogDef.Revision
+	def.SetAnnotations(annotations)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(def.GroupVersionKind())
+	err := d.KubeClient.Get(ctx, client.ObjectKey{Namespace: def.GetNamespace(), Name: def.GetName()}, existing)
+	if apierrors.IsNotFound(err) {
+		return d.KubeClient.Create(ctx, &def.Unstructured)
+	}
+	if err != nil {
+		return err
+	}
+	if existing.GetLabels()[LabelDefinitionCatalog] != catalogName {
+		return fmt.Errorf("the %s %s is not imported by this catalog", def.GetKind(), def.GetName())
+	}
+	if existing.GetAnnotations()[AnnotationCatalogVersion] == catalogDef.Version {
+		return nil
+	}
+	def.SetResourceVersion(existing.GetResourceVersion())
+	return d.KubeClient.Update(ctx, &def.Unstructured)
+}
+
+func (d *definitionCatalogServiceImpl) getImportedDefinition(ctx context.Context, catalogName string, def model.CatalogDefinition) (*unstructured.Unstructured, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion(definitionAPIVersion)
+	existing.SetKind(def.Kind)
+	if err := d.KubeClient.Get(ctx, client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: def.Name}, existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if existing.GetLabels()[LabelDefinitionCatalog] != catalogName {
+		return nil, nil
+	}
+	return existing, nil
+}
+
+func (d *definitionCatalogServiceImpl) deleteDefinition(ctx context.Context, catalogName string, def model.CatalogDefinition) error {
+	existing, err := d.getImportedDefinition(ctx, catalogName, def)
+	if err != nil || existing == nil {
+		return err
+	}
+	if err := d.KubeClient.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// releaseDefinition removes the catalog label so that the definition is not managed by the catalog anymore
+func (d *definitionCatalogServiceImpl) releaseDefinition(ctx context.Context, catalogName string, def model.CatalogDefinition) error {
+	existing, err := d.getImportedDefinition(ctx, catalogName, def)
+	if err != nil || existing == nil {
+		return err
+	}
+	labels := existing.GetLabels()
+	delete(labels, LabelDefinitionCatalog)
+	existing.SetLabels(labels)
+	return d.KubeClient.Update(ctx, existing)
+}
+
+// fetchCatalogSnapshot clones the catalog repository in memory and reads the definitions of the head commit
+func fetchCatalogSnapshot(ctx context.Context, catalog *model.DefinitionCatalog, config *rest.Config) (*catalogSnapshot, error) {
+	options := &git.CloneOptions{URL: catalog.URL, Depth: 1, SingleBranch: true, NoCheckout: true}
+	if catalog.Branch != "" {
+		options.ReferenceName = plumbing.NewBranchReferenceName(catalog.Branch)
+	}
+	if catalog.Token != "" {
+		username := catalog.Username
+		if username == "" {
+			username = "oauth2"
+		}
+		options.Auth = &githttp.BasicAuth{Username: username, Password: catalog.Token}
+	}
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, options)
+	if err != nil {
+		return nil, bcode.ErrDefinitionCatalogFetch.SetMessage(err.Error())
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, bcode.ErrDefinitionCatalogFetch.SetMessage(err.Error())
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if catalog.VerifyKeys != "" {
+		if _, err := commit.Verify(catalog.VerifyKeys); err != nil {
+			return nil, bcode.ErrDefinitionCatalogSignature
+		}
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &catalogSnapshot{revision: head.Hash().String(), versions: map[string]string{}}
+	prefix := strings.Trim(catalog.Path, "/")
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if prefix != "" && !strings.HasPrefix(f.Name, prefix+"/") {
+			return nil
+		}
+		ext := path.Ext(f.Name)
+		if ext != ".yaml" && ext != ".yml" && ext != ".cue" {
+			return nil
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		def := &definition.Definition{}
+		if ext == ".cue" {
+			err = def.FromCUEString(content, config)
+		} else {
+			err = def.FromYAML([]byte(content))
+		}
+		if err != nil {
+			return fmt.Errorf("invalid definition %s: %w", f.Name, err)
+		}
+		switch def.GetKind() {
+		case kindComponentDefinition, kindTraitDefinition, kindPolicyDefinition, kindWorkflowStepDefinition:
+		default:
+			klog.Warningf("skip the file %s of the definition catalog %s, it is not a definition", f.Name, catalog.Name)
+			return nil
+		}
+		snapshot.definitions = append(snapshot.definitions, def)
+		snapshot.versions[def.GetKind()+"/"+def.GetName()] = f.Hash.String()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func convertDefinitionCatalog2Base(catalog *model.DefinitionCatalog) *apisv1.DefinitionCatalogBase {
+	return &apisv1.DefinitionCatalogBase{
+		Name:         catalog.Name,
+		Alias:        catalog.Alias,
+		Description:  catalog.Description,
+		URL:          catalog.URL,
+		Branch:       catalog.Branch,
+		Path:         catalog.Path,
+		Signed:       catalog.VerifyKeys != "",
+		Revision:     catalog.Revision,
+		Definitions:  catalog.Definitions,
+		Status:       catalog.Status,
+		Message:      catalog.Message,
+		LastSyncTime: catalog.LastSyncTime,
+		CreateTime:   catalog.CreateTime,
+		UpdateTime:   catalog.UpdateTime,
+	}
+}
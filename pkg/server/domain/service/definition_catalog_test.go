@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/definition"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var catalogTraitYAML = `apiVersion: core.oam.dev/v1beta1
+kind: TraitDefinition
+metadata:
+  name: catalog-test-trait
+spec:
+  schematic:
+    cue:
+      template: |
+        patch: spec: replicas: parameter.replicas
+        parameter: replicas: *1 | int
+`
+
+var _ = Describe("Test the definition catalog", func() {
+	var catalogService *definitionCatalogServiceImpl
+	BeforeEach(func() {
+		ds, err := NewDatastore(datastore.Config{Type: "kubeapi", Database: "definition-catalog-test-kubevela"})
+		Expect(err).Should(BeNil())
+		catalogService = &definitionCatalogServiceImpl{Store: ds, KubeClient: k8sClient}
+	})
+
+	It("Test manage the definition catalogs", func() {
+		created, err := catalogService.CreateDefinitionCatalog(context.TODO(), apisv1.CreateDefinitionCatalogRequest{
+			Name:  "platform",
+			URL:   "https://github.com/kubevela/catalog.git",
+			Path:  "definitions",
+			Token: "secret",
+		})
+		Expect(err).Should(BeNil())
+		Expect(created.Signed).Should(BeFalse())
+		_, err = catalogService.CreateDefinitionCatalog(context.TODO(), apisv1.CreateDefinitionCatalogRequest{Name: "platform", URL: "https://github.com/kubevela/catalog.git"})
+		Expect(err).Should(Equal(bcode.ErrDefinitionCatalogExist))
+
+		updated, err := catalogService.UpdateDefinitionCatalog(context.TODO(), "platform", apisv1.UpdateDefinitionCatalogRequest{
+			URL:        "https://github.com/kubevela/catalog.git",
+			Path:       "definitions",
+			VerifyKeys: "-----BEGIN PGP PUBLIC KEY BLOCK-----",
+		})
+		Expect(err).Should(BeNil())
+		Expect(updated.Signed).Should(BeTrue())
+		catalog, err := catalogService.getCatalog(context.TODO(), "platform")
+		Expect(err).Should(BeNil())
+		Expect(catalog.Token).Should(Equal("secret"))
+
+		catalogs, err := catalogService.ListDefinitionCatalogs(context.TODO())
+		Expect(err).Should(BeNil())
+		Expect(len(catalogs.Catalogs)).Should(Equal(1))
+
+		Expect(catalogService.DeleteDefinitionCatalog(context.TODO(), "platform")).Should(BeNil())
+		_, err = catalogService.GetDefinitionCatalog(context.TODO(), "platform")
+		Expect(err).Should(Equal(bcode.ErrDefinitionCatalogNotExist))
+	})
+
+	It("Test import the definitions of the catalog", func() {
+		newDef := func() *definition.Definition {
+			def := &definition.Definition{}
+			Expect(def.FromYAML([]byte(catalogTraitYAML))).Should(BeNil())
+			return def
+		}
+		catalogDef := model.CatalogDefinition{Name: "catalog-test-trait", Kind: kindTraitDefinition, Version: "v1", Revision: "c1"}
+		Expect(catalogService.applyDefinition(context.TODO(), "platform", newDef(), catalogDef)).Should(BeNil())
+
+		trait := &unstructured.Unstructured{}
+		trait.SetAPIVersion(definitionAPIVersion)
+		trait.SetKind(kindTraitDefinition)
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: "catalog-test-trait"}, trait)).Should(BeNil())
+		Expect(trait.GetLabels()[LabelDefinitionCatalog]).Should(Equal("platform"))
+		Expect(trait.GetAnnotations()[AnnotationCatalogRevision]).Should(Equal("c1"))
+
+		catalogDef.Version, catalogDef.Revision = "v2", "c2"
+		Expect(catalogService.applyDefinition(context.TODO(), "platform", newDef(), catalogDef)).Should(BeNil())
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: "catalog-test-trait"}, trait)).Should(BeNil())
+		Expect(trait.GetAnnotations()[AnnotationCatalogVersion]).Should(Equal("v2"))
+
+		// the definitions imported by another catalog are not overwritten
+		Expect(catalogService.applyDefinition(context.TODO(), "another", newDef(), catalogDef)).ShouldNot(BeNil())
+
+		Expect(catalogService.deleteDefinition(context.TODO(), "another", catalogDef)).Should(BeNil())
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: "catalog-test-trait"}, trait)).Should(BeNil())
+		Expect(catalogService.deleteDefinition(context.TODO(), "platform", catalogDef)).Should(BeNil())
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: types.DefaultKubeVelaNS, Name: "catalog-test-trait"}, trait)).ShouldNot(BeNil())
+	})
+
+	It("Test skip the sync in the offline mode", func() {
+		sysService := systemInfoServiceImpl{Store: catalogService.Store}
+		info, err := sysService.Get(context.TODO())
+		Expect(err).Should(BeNil())
+		_, err = sysService.UpdateSystemInfo(context.TODO(), apisv1.SystemInfoRequest{LoginType: info.LoginType, EnableCollection: true, OfflineMode: pointer.BoolPtr(true)})
+		Expect(err).Should(BeNil())
+		res, err := sysService.GetSystemInfo(context.TODO())
+		Expect(err).Should(BeNil())
+		Expect(res.EnableCollection).Should(BeFalse())
+		Expect(res.DegradedFeatures).Should(ContainElement(FeatureDefinitionCatalogSync))
+
+		catalogService.SysService = sysService
+		_, err = catalogService.CreateDefinitionCatalog(context.TODO(), apisv1.CreateDefinitionCatalogRequest{Name: "offline", URL: "https://github.com/kubevela/catalog.git"})
+		Expect(err).Should(BeNil())
+		_, err = catalogService.SyncDefinitionCatalog(context.TODO(), "offline")
+		Expect(err).Should(Equal(bcode.ErrDefinitionCatalogOffline))
+		Expect(catalogService.SyncDefinitionCatalogs(context.TODO())).Should(BeNil())
+
+		By("the offline mode is kept if it is not set")
+		_, err = sysService.UpdateSystemInfo(context.TODO(), apisv1.SystemInfoRequest{LoginType: info.LoginType, EnableCollection: true})
+		Expect(err).Should(BeNil())
+		res, err = sysService.GetSystemInfo(context.TODO())
+		Expect(err).Should(BeNil())
+		Expect(res.OfflineMode).Should(BeTrue())
+
+		_, err = sysService.UpdateSystemInfo(context.TODO(), apisv1.SystemInfoRequest{LoginType: info.LoginType, EnableCollection: true, OfflineMode: pointer.BoolPtr(false)})
+		Expect(err).Should(BeNil())
+		res, err = sysService.GetSystemInfo(context.TODO())
+		Expect(err).Should(BeNil())
+		Expect(res.DegradedFeatures).Should(BeEmpty())
+		Expect(catalogService.DeleteDefinitionCatalog(context.TODO(), "offline")).Should(BeNil())
+	})
+})
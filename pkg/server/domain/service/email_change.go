@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/email"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	apiutils "github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// emailVerificationTokenExpiration how long the email verification token is valid
+const emailVerificationTokenExpiration = 24 * time.Hour
+
+// EmailChangeService changes the email of the users after the new email is verified
+type EmailChangeService interface {
+	RequestEmailChange(ctx context.Context, username string, req apisv1.ChangeEmailRequest) error
+	VerifyEmailChange(ctx context.Context, username string, req apisv1.VerifyEmailRequest) (*apisv1.UserBase, error)
+	ListEmailChanges(ctx context.Context, username string) (*apisv1.ListEmailChangeRecordsResponse, error)
+}
+
+type emailChangeServiceImpl struct {
+	Store       datastore.DataStore `inject:"datastore"`
+	K8sClient   client.Client       `inject:"kubeClient"`
+	EmailSender email.Sender        `inject:"emailSender"`
+	SysService  SystemInfoService   `inject:""`
+}
+
+// NewEmailChangeService new email change service
+func NewEmailChangeService() EmailChangeService {
+	return &emailChangeServiceImpl{}
+}
+
+// RequestEmailChange sends the verification token to the new email, the previous pending change of the user is replaced
+func (e *emailChangeServiceImpl) RequestEmailChange(ctx context.Context, username string, req apisv1.ChangeEmailRequest) error {
+	if !e.EmailSender.Enabled() {
+		return bcode.ErrEmailChangeDisabled
+	}
+	user := &model.User{Name: username}
+	if err := e.Store.Get(ctx, user); err != nil {
+		return err
+	}
+	// the email of the SSO users is managed by the identity provider
+	if user.DexSub != "" {
+		return bcode.ErrUserCannotModified
+	}
+	if err := e.checkEmailUnused(ctx, req.Email); err != nil {
+		return err
+	}
+	token, err := generateSecretToken()
+	if err != nil {
+		return err
+	}
+	verification := &model.EmailVerification{
+		Username:   user.Name,
+		Email:      req.Email,
+		TokenHash:  hashSecretToken(token),
+		ExpireTime: time.Now().Add(emailVerificationTokenExpiration),
+	}
+	// the datastore deletes the verification after it expires
+	verification.SetExpireAt(verification.ExpireTime)
+	if err := e.Store.Delete(ctx, verification); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return err
+	}
+	if err := e.Store.Add(ctx, verification); err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Hi %s,\n\nUse the token below to verify the new email of your VelaUX account, it expires in %s.\n\n%s\n\nIf you did not request the email change, please ignore this email.\n",
+		user.Name, emailVerificationTokenExpiration, token)
+	if err := e.EmailSender.Send(ctx, req.Email, "Verify your new VelaUX email", body); err != nil {
+		klog.Errorf("failed to send the email verification token to the user %s: %s", user.Name, err.Error())
+		if err := e.Store.Delete(ctx, verification); err != nil {
+			klog.Warningf("failed to delete the email verification that is not sent: %s", err.Error())
+		}
+		return bcode.ErrEmailVerificationFailure
+	}
+	return nil
+}
+
+// VerifyEmailChange sets the new email if the token is valid and records the change, the token could only be used once
+func (e *emailChangeServiceImpl) VerifyEmailChange(ctx context.Context, username string, req apisv1.VerifyEmailRequest) (*apisv1.UserBase, error) {
+	verification := &model.EmailVerification{Username: username}
+	if err := e.Store.Get(ctx, verification); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrEmailVerificationTokenInvalid
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(verification.TokenHash), []byte(hashSecretToken(req.Token))) != 1 {
+		return nil, bcode.ErrEmailVerificationTokenInvalid
+	}
+	if time.Now().After(verification.ExpireTime) {
+		return nil, bcode.ErrEmailVerificationTokenExpired
+	}
+	user := &model.User{Name: username}
+	if err := e.Store.Get(ctx, user); err != nil {
+		return nil, err
+	}
+	// another user may take the email after the token is sent
+	if err := e.checkEmailUnused(ctx, verification.Email); err != nil {
+		return nil, err
+	}
+	record := &model.EmailChangeRecord{
+		Name:        apiutils.GenerateVersion(user.Name) + "-" + rand.String(4),
+		Username:    user.Name,
+		OldEmail:    user.Email,
+		NewEmail:    verification.Email,
+		RequestTime: verification.CreateTime,
+	}
+	user.Email = verification.Email
+	if err := datastore.InTransaction(ctx, e.Store, func(ctx context.Context) error {
+		if err := e.Store.Put(ctx, user); err != nil {
+			return err
+		}
+		if err := e.Store.Add(ctx, record); err != nil {
+			klog.Errorf("failed to record the email change of the user %s: %s", user.Name, err.Error())
+		}
+		if err := e.Store.Delete(ctx, verification); err != nil {
+			klog.Warningf("failed to delete the used email verification: %s", err.Error())
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if user.Name == model.DefaultAdminUserName {
+		if err := generateDexConfig(ctx, e.K8sClient, &model.UpdateDexConfig{
+			StaticPasswords: []model.StaticPassword{
+				{
+					Email:    user.Email,
+					Hash:     user.Password,
+					Username: user.Name,
+				},
+			},
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return convertUserBase(user, useGravatar(ctx, e.SysService)), nil
+}
+
+// ListEmailChanges lists the verified email changes of the user, the latest first
+func (e *emailChangeServiceImpl) ListEmailChanges(ctx context.Context, username string) (*apisv1.ListEmailChangeRecordsResponse, error) {
+	entities, err := e.Store.List(ctx, &model.EmailChangeRecord{Username: username}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &apisv1.ListEmailChangeRecordsResponse{Records: []*apisv1.EmailChangeRecordBase{}}
+	for _, entity := range entities {
+		record := entity.(*model.EmailChangeRecord)
+		resp.Records = append(resp.Records, &apisv1.EmailChangeRecordBase{
+			Username:    record.Username,
+			OldEmail:    record.OldEmail,
+			NewEmail:    record.NewEmail,
+			RequestTime: record.RequestTime,
+			VerifyTime:  record.CreateTime,
+		})
+	}
+	return resp, nil
+}
+
+func (e *emailChangeServiceImpl) checkEmailUnused(ctx context.Context, email string) error {
+	users, err := e.Store.List(ctx, &model.User{Email: email}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return bcode.ErrUserEmailExist
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/email"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the email change", func() {
+	var (
+		ds                 datastore.DataStore
+		sender             *fakeEmailSender
+		emailChangeService *emailChangeServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "email-change-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		sender = &fakeEmailSender{}
+		emailChangeService = &emailChangeServiceImpl{Store: ds, K8sClient: k8sClient, EmailSender: sender}
+		Expect(ds.Add(context.TODO(), &model.User{Name: "email-user", Email: "old@example.com"})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.User{Name: "other-user", Email: "other@example.com"})).Should(BeNil())
+	})
+
+	It("Test change the email with the verification token", func() {
+		ctx := context.TODO()
+		err := emailChangeService.RequestEmailChange(ctx, "email-user", apisv1.ChangeEmailRequest{Email: "other@example.com"})
+		Expect(err).Should(Equal(bcode.ErrUserEmailExist))
+
+		Expect(emailChangeService.RequestEmailChange(ctx, "email-user", apisv1.ChangeEmailRequest{Email: "new@example.com"})).Should(BeNil())
+		Expect(sender.to).Should(Equal("new@example.com"))
+		token := regexp.MustCompile(`[0-9a-f]{64}`).FindString(sender.body)
+		Expect(token).ShouldNot(BeEmpty())
+
+		_, err = emailChangeService.VerifyEmailChange(ctx, "email-user", apisv1.VerifyEmailRequest{Token: "invalid"})
+		Expect(err).Should(Equal(bcode.ErrEmailVerificationTokenInvalid))
+		By("the token only verifies the email of the user that requested the change")
+		_, err = emailChangeService.VerifyEmailChange(ctx, "other-user", apisv1.VerifyEmailRequest{Token: token})
+		Expect(err).Should(Equal(bcode.ErrEmailVerificationTokenInvalid))
+
+		user, err := emailChangeService.VerifyEmailChange(ctx, "email-user", apisv1.VerifyEmailRequest{Token: token})
+		Expect(err).Should(BeNil())
+		Expect(user.Email).Should(Equal("new@example.com"))
+		_, err = emailChangeService.VerifyEmailChange(ctx, "email-user", apisv1.VerifyEmailRequest{Token: token})
+		Expect(err).Should(Equal(bcode.ErrEmailVerificationTokenInvalid))
+
+		changes, err := emailChangeService.ListEmailChanges(ctx, "email-user")
+		Expect(err).Should(BeNil())
+		Expect(len(changes.Records)).Should(Equal(1))
+		Expect(changes.Records[0].OldEmail).Should(Equal("old@example.com"))
+		Expect(changes.Records[0].NewEmail).Should(Equal("new@example.com"))
+	})
+
+	It("Test the expired token and the email taken after the request", func() {
+		ctx := context.TODO()
+		Expect(emailChangeService.RequestEmailChange(ctx, "email-user", apisv1.ChangeEmailRequest{Email: "taken@example.com"})).Should(BeNil())
+		token := regexp.MustCompile(`[0-9a-f]{64}`).FindString(sender.body)
+		Expect(ds.Add(ctx, &model.User{Name: "taken-user", Email: "taken@example.com"})).Should(BeNil())
+		_, err := emailChangeService.VerifyEmailChange(ctx, "email-user", apisv1.VerifyEmailRequest{Token: token})
+		Expect(err).Should(Equal(bcode.ErrUserEmailExist))
+
+		verification := &model.EmailVerification{Username: "email-user"}
+		Expect(ds.Get(ctx, verification)).Should(BeNil())
+		verification.ExpireTime = time.Now().Add(-time.Minute)
+		Expect(ds.Put(ctx, verification)).Should(BeNil())
+		_, err = emailChangeService.VerifyEmailChange(ctx, "email-user", apisv1.VerifyEmailRequest{Token: token})
+		Expect(err).Should(Equal(bcode.ErrEmailVerificationTokenExpired))
+	})
+
+	It("Test the email change is disabled without the email sender", func() {
+		emailChangeService.EmailSender = email.New(email.Config{})
+		err := emailChangeService.RequestEmailChange(context.TODO(), "email-user", apisv1.ChangeEmailRequest{Email: "new@example.com"})
+		Expect(err).Should(Equal(bcode.ErrEmailChangeDisabled))
+	})
+})
@@ -89,7 +89,12 @@ func (p *envServiceImpl) DeleteEnv(ctx context.Context, envName string) error {
 		return err
 	}
 
-	if err = p.Store.Delete(ctx, env); err != nil {
+	if err := datastore.InTransaction(ctx, p.Store, func(ctx context.Context) error {
+		if _, err := moveToTrash(ctx, p.Store, TrashKindEnvironment, env.Project, env.Alias, env); err != nil {
+			return err
+		}
+		return p.Store.Delete(ctx, env)
+	}); err != nil {
 		if errors.Is(err, datastore.ErrRecordNotExist) {
 			return nil
 		}
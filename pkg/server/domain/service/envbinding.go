@@ -238,17 +238,15 @@ func (e *envBindingServiceImpl) createEnvWorkflow(ctx context.Context, app *mode
 		AppPrimaryKey: app.PrimaryKey(),
 	}
 	klog.Infof("create workflow %s for app %s", pkgUtils.Sanitize(workflow.Name), pkgUtils.Sanitize(app.PrimaryKey()))
-	if err := e.Store.Add(ctx, workflow); err != nil {
-		return err
-	}
-	err := e.Store.BatchAdd(ctx, policies)
-	if err != nil {
-		if err := e.WorkflowService.DeleteWorkflow(ctx, app, repository.ConvertWorkflowName(env.Name)); err != nil {
-			klog.Errorf("fail to rollback the workflow after fail to create policies, %s", err.Error())
+	return e.Store.Transaction(ctx, func(tx datastore.DataStore) error {
+		if err := tx.Add(ctx, workflow); err != nil {
+			return err
 		}
-		return fmt.Errorf("fail to create policies %w", err)
-	}
-	return nil
+		if err := tx.BatchAdd(ctx, policies); err != nil {
+			return fmt.Errorf("fail to create policies %w", err)
+		}
+		return nil
+	})
 }
 
 func (e *envBindingServiceImpl) deleteEnvWorkflow(ctx context.Context, app *model.Application, workflowName string) error {
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	pkgaddon "github.com/oam-dev/kubevela/pkg/addon"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+const (
+	// HealthStatusOK the dependency works
+	HealthStatusOK = "ok"
+	// HealthStatusDegraded some features are unavailable
+	HealthStatusDegraded = "degraded"
+	// HealthStatusDown the dependency does not work
+	HealthStatusDown = "down"
+	// HealthStatusDisabled the dependency is not used
+	HealthStatusDisabled = "disabled"
+)
+
+const (
+	// healthCheckTimeout the timeout of checking a dependency, the checks run in parallel
+	healthCheckTimeout = 2 * time.Second
+	// healthCacheDuration the report is reused in the duration, the probes of many replicas and load balancers do not stress the dependencies
+	healthCacheDuration = 5 * time.Second
+)
+
+// HealthService checks the dependencies for the probes of the load balancers and the operators
+type HealthService interface {
+	CheckHealth(ctx context.Context) *apisv1.HealthResponse
+}
+
+type healthServiceImpl struct {
+	Store      datastore.DataStore        `inject:"datastore"`
+	KubeClient client.Client              `inject:"kubeClient"`
+	RegistryDS pkgaddon.RegistryDataStore `inject:"registryDatastore"`
+	SysService SystemInfoService          `inject:""`
+
+	lock   sync.Mutex
+	report *apisv1.HealthResponse
+}
+
+// NewHealthService new health service
+func NewHealthService() HealthService {
+	return &healthServiceImpl{}
+}
+
+type dependencyChecker struct {
+	name     string
+	critical bool
+	check    func(ctx context.Context, info *model.SystemInfo) (string, string)
+}
+
+// CheckHealth checks the datastore, the kube API, Dex and the addon registries
+func (h *healthServiceImpl) CheckHealth(ctx context.Context) *apisv1.HealthResponse {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.report != nil && time.Since(h.report.CheckTime) < healthCacheDuration {
+		return h.report
+	}
+	report := &apisv1.HealthResponse{Status: HealthStatusOK, CheckTime: time.Now()}
+	info, err := h.SysService.Get(ctx)
+	if err != nil {
+		info = nil
+	}
+	if info != nil {
+		report.MaintenanceMode = info.MaintenanceMode
+		report.OfflineMode = info.OfflineMode
+		report.DegradedFeatures = degradedFeatures(info)
+	}
+	checkers := []dependencyChecker{
+		{name: "datastore", critical: true, check: h.checkDatastore},
+		{name: "kubeAPI", critical: true, check: h.checkKubeAPI},
+		{name: "dex", check: h.checkDex},
+		{name: "addonRegistry", check: h.checkAddonRegistry},
+	}
+	report.Checks = make([]apisv1.DependencyCheck, len(checkers))
+	var wg sync.WaitGroup
+	for i, c := range checkers {
+		wg.Add(1)
+		go func(i int, c dependencyChecker) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+			start := time.Now()
+			status, message := c.check(checkCtx, info)
+			report.Checks[i] = apisv1.DependencyCheck{Name: c.name, Status: status, Critical: c.critical, Message: message, LatencyMS: time.Since(start).Milliseconds()}
+		}(i, c)
+	}
+	wg.Wait()
+	for _, c := range report.Checks {
+		switch {
+		case c.Status == HealthStatusDown && c.Critical:
+			report.Status = HealthStatusDown
+		case (c.Status == HealthStatusDown || c.Status == HealthStatusDegraded) && report.Status == HealthStatusOK:
+			report.Status = HealthStatusDegraded
+		}
+	}
+	if report.Status == HealthStatusOK && (report.MaintenanceMode || report.OfflineMode) {
+		report.Status = HealthStatusDegraded
+	}
+	h.report = report
+	return report
+}
+
+func (h *healthServiceImpl) checkDatastore(ctx context.Context, _ *model.SystemInfo) (string, string) {
+	if _, err := h.Store.Count(ctx, &model.SystemInfo{}, nil); err != nil {
+		return dependencyDown("datastore", err)
+	}
+	return HealthStatusOK, ""
+}
+
+func (h *healthServiceImpl) checkKubeAPI(ctx context.Context, _ *model.SystemInfo) (string, string) {
+	if err := h.KubeClient.Get(ctx, client.ObjectKey{Name: types.DefaultKubeVelaNS}, &corev1.Namespace{}); err != nil {
+		return dependencyDown("kubeAPI", err)
+	}
+	return HealthStatusOK, ""
+}
+
+// checkDex requests the discovery document of the issuer, Dex is only used by the dex login type
+func (h *healthServiceImpl) checkDex(ctx context.Context, info *model.SystemInfo) (string, string) {
+	if info == nil || info.LoginType != model.LoginTypeDex {
+		return HealthStatusDisabled, ""
+	}
+	config, err := getDexConfig(ctx, h.KubeClient)
+	if err != nil {
+		return dependencyDown("dex", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, config.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return dependencyDown("dex", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return dependencyDown("dex", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return HealthStatusDown, fmt.Sprintf("the discovery endpoint of the issuer returns %d", resp.StatusCode)
+	}
+	return HealthStatusOK, ""
+}
+
+// checkAddonRegistry only lists the registries, the remote registries are not requested by the frequent probes
+func (h *healthServiceImpl) checkAddonRegistry(ctx context.Context, info *model.SystemInfo) (string, string) {
+	if info != nil && info.OfflineMode {
+		return HealthStatusDegraded, "the remote registries are not requested in the offline mode"
+	}
+	registries, err := h.RegistryDS.ListRegistries(ctx)
+	if err != nil {
+		return dependencyDown("addonRegistry", err)
+	}
+	if len(registries) == 0 {
+		return HealthStatusDegraded, "there is no addon registry"
+	}
+	return HealthStatusOK, ""
+}
+
+// dependencyDown logs the error of the dependency, the health endpoints need no login so the error that may
+// contain the internal hosts and the connection details is not returned
+func dependencyDown(name string, err error) (string, string) {
+	klog.Warningf("the health check of the %s fails: %s", name, err.Error())
+	return HealthStatusDown, fmt.Sprintf("the %s is unavailable, see the server log for the details", name)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	pkgaddon "github.com/oam-dev/kubevela/pkg/addon"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+var _ = Describe("Test the health checks", func() {
+	var (
+		ds            datastore.DataStore
+		healthService *healthServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "health-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		healthService = &healthServiceImpl{
+			Store:      ds,
+			KubeClient: k8sClient,
+			RegistryDS: pkgaddon.NewRegistryDataStore(k8sClient),
+			SysService: &systemInfoServiceImpl{Store: ds, KubeClient: k8sClient},
+		}
+	})
+
+	It("Test check the dependencies", func() {
+		report := healthService.CheckHealth(context.TODO())
+		Expect(report.Status).ShouldNot(Equal(HealthStatusDown))
+		statuses := map[string]string{}
+		for _, c := range report.Checks {
+			statuses[c.Name] = c.Status
+		}
+		Expect(statuses["datastore"]).Should(Equal(HealthStatusOK))
+		Expect(statuses["kubeAPI"]).Should(Equal(HealthStatusOK))
+		Expect(statuses["dex"]).Should(Equal(HealthStatusDisabled))
+
+		By("the report is reused in the cache duration")
+		Expect(healthService.CheckHealth(context.TODO())).Should(BeIdenticalTo(report))
+
+		By("the offline mode is reported as degraded")
+		info, err := healthService.SysService.Get(context.TODO())
+		Expect(err).Should(BeNil())
+		info.OfflineMode = true
+		Expect(ds.Put(context.TODO(), info)).Should(BeNil())
+		healthService.report = nil
+		report = healthService.CheckHealth(context.TODO())
+		Expect(report.OfflineMode).Should(BeTrue())
+		Expect(report.Status).Should(Equal(HealthStatusDegraded))
+		Expect(report.DegradedFeatures).ShouldNot(BeEmpty())
+	})
+
+	It("Test the errors of the dependencies are not exposed", func() {
+		status, message := dependencyDown("datastore", errors.New("dial tcp 10.0.0.3:27017: connect: connection refused"))
+		Expect(status).Should(Equal(HealthStatusDown))
+		Expect(message).ShouldNot(ContainSubstring("10.0.0.3"))
+	})
+})
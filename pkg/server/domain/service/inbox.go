@@ -0,0 +1,290 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// InboxKindRBACApproval a pending change of the platform roles or permissions
+	InboxKindRBACApproval = "rbacApproval"
+	// InboxKindServiceInstance a pending request of the service catalog
+	InboxKindServiceInstance = "serviceInstance"
+	// InboxKindWorkflowRecord a suspended workflow of the application that waits for the approval
+	InboxKindWorkflowRecord = "workflowRecord"
+	// InboxKindPipelineRun a suspended pipeline run that waits for the manual input
+	InboxKindPipelineRun = "pipelineRun"
+	// InboxKindInvitation an invitation sent by the user that is not accepted yet
+	InboxKindInvitation = "invitation"
+)
+
+// InboxService collects the pending work of the login user across all projects
+type InboxService interface {
+	ListInbox(ctx context.Context) (*apisv1.InboxResponse, error)
+}
+
+type inboxServiceImpl struct {
+	Store          datastore.DataStore `inject:"datastore"`
+	KubeClient     client.Client       `inject:"kubeClient"`
+	RbacService    RBACService         `inject:""`
+	ProjectService ProjectService      `inject:""`
+}
+
+// NewInboxService new inbox service
+func NewInboxService() InboxService {
+	return &inboxServiceImpl{}
+}
+
+// inbox lists the pending work of the user, the items are checked by the actions that resolve them
+type inbox struct {
+	*inboxServiceImpl
+	userName    string
+	projects    []string
+	permissions func(projectName string) ([]*model.Permission, error)
+}
+
+func (i *inbox) allowed(project, resource string, labels map[string]string, action string) (bool, error) {
+	perms, err := i.permissions(project)
+	if err != nil {
+		return false, err
+	}
+	return matchPermission(perms, resource, labels, action), nil
+}
+
+// ListInbox lists the approvals, the suspended workflows and pipeline runs that the login user could resume,
+// and the invitations sent by the user. The requests of the user are not listed because they must be reviewed by others.
+func (s *inboxServiceImpl) ListInbox(ctx context.Context) (*apisv1.InboxResponse, error) {
+	userName, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return nil, bcode.ErrUnauthorized
+	}
+	permissions, err := loginUserPermissions(ctx, s.Store, s.RbacService)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.ProjectService.ListUserProjects(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+	i := &inbox{inboxServiceImpl: s, userName: userName, permissions: permissions}
+	for _, project := range projects {
+		i.projects = append(i.projects, project.Name)
+	}
+	res := &apisv1.InboxResponse{Items: []*apisv1.InboxItem{}}
+	for _, collect := range []func(ctx context.Context) ([]*apisv1.InboxItem, error){
+		i.rbacApprovals, i.serviceInstances, i.workflowRecords, i.pipelineRuns, i.invitations,
+	} {
+		items, err := collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res.Items = append(res.Items, items...)
+	}
+	sort.SliceStable(res.Items, func(a, b int) bool {
+		return res.Items[a].CreateTime.After(res.Items[b].CreateTime)
+	})
+	res.Total = int64(len(res.Items))
+	return res, nil
+}
+
+func (i *inbox) rbacApprovals(ctx context.Context) ([]*apisv1.InboxItem, error) {
+	entities, err := i.Store.List(ctx, &model.RBACApproval{Status: model.RBACApprovalStatusPending}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var items []*apisv1.InboxItem
+	for _, entity := range entities {
+		approval := entity.(*model.RBACApproval)
+		if approval.Requester == i.userName {
+			continue
+		}
+		allowed, err := i.allowed("", "rbacApproval:"+approval.Name, nil, "approve")
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+		items = append(items, &apisv1.InboxItem{
+			Kind:       InboxKindRBACApproval,
+			Name:       approval.Name,
+			Entity:     approval.ResourceName,
+			Message:    fmt.Sprintf("%s the %s %s", approval.Operation, approval.Kind, approval.ResourceName),
+			Requester:  approval.Requester,
+			CreateTime: approval.CreateTime,
+		})
+	}
+	return items, nil
+}
+
+func (i *inbox) serviceInstances(ctx context.Context) ([]*apisv1.InboxItem, error) {
+	if len(i.projects) == 0 {
+		return nil, nil
+	}
+	entities, err := i.Store.List(ctx, &model.ServiceInstance{Status: model.ServiceInstanceStatusPending}, &datastore.ListOptions{
+		FilterOptions: datastore.FilterOptions{In: []datastore.InQueryOption{{Key: "project", Values: i.projects}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var items []*apisv1.InboxItem
+	for _, entity := range entities {
+		instance := entity.(*model.ServiceInstance)
+		if instance.Requester == i.userName {
+			continue
+		}
+		allowed, err := i.allowed(instance.Project, fmt.Sprintf("project:%s/serviceInstance:%s", instance.Project, instance.Name), nil, "approve")
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+		items = append(items, &apisv1.InboxItem{
+			Kind:       InboxKindServiceInstance,
+			Project:    instance.Project,
+			Name:       instance.Name,
+			Entity:     instance.AppPrimaryKey,
+			Message:    fmt.Sprintf("provision the %s service", instance.ServiceClass),
+			Requester:  instance.Requester,
+			CreateTime: instance.CreateTime,
+		})
+	}
+	return items, nil
+}
+
+func (i *inbox) workflowRecords(ctx context.Context) ([]*apisv1.InboxItem, error) {
+	if len(i.projects) == 0 {
+		return nil, nil
+	}
+	appEntities, err := i.Store.List(ctx, &model.Application{}, &datastore.ListOptions{
+		FilterOptions: datastore.FilterOptions{In: []datastore.InQueryOption{{Key: "project", Values: i.projects}}},
+	})
+	if err != nil || len(appEntities) == 0 {
+		return nil, err
+	}
+	apps := map[string]*model.Application{}
+	var appNames []string
+	for _, entity := range appEntities {
+		app := entity.(*model.Application)
+		apps[app.PrimaryKey()] = app
+		appNames = append(appNames, app.PrimaryKey())
+	}
+	entities, err := i.Store.List(ctx, &model.WorkflowRecord{Status: string(workflowv1alpha1.WorkflowStateSuspending)}, &datastore.ListOptions{
+		FilterOptions: datastore.FilterOptions{In: []datastore.InQueryOption{{Key: "appPrimaryKey", Values: appNames}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var items []*apisv1.InboxItem
+	for _, entity := range entities {
+		record := entity.(*model.WorkflowRecord)
+		app := apps[record.AppPrimaryKey]
+		allowed, err := i.allowed(app.Project, fmt.Sprintf("project:%s/application:%s/workflow:%s/record:%s", app.Project, app.Name, record.WorkflowName, record.Name), app.Labels, "resume")
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+		items = append(items, &apisv1.InboxItem{
+			Kind:       InboxKindWorkflowRecord,
+			Project:    app.Project,
+			Name:       record.Name,
+			Entity:     app.Name,
+			Message:    fmt.Sprintf("the workflow %s is waiting for the approval", record.WorkflowName),
+			CreateTime: record.CreateTime,
+		})
+	}
+	return items, nil
+}
+
+func (i *inbox) pipelineRuns(ctx context.Context) ([]*apisv1.InboxItem, error) {
+	var items []*apisv1.InboxItem
+	for _, projectName := range i.projects {
+		project := &model.Project{Name: projectName}
+		if err := i.Store.Get(ctx, project); err != nil {
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		runs := workflowv1alpha1.WorkflowRunList{}
+		if err := i.KubeClient.List(utils.WithProject(ctx, projectName), &runs, client.InNamespace(project.GetNamespace()), client.HasLabels{labelPipeline}); err != nil {
+			return nil, err
+		}
+		for _, run := range runs.Items {
+			if !run.Status.Suspend || run.Status.Finished || run.Status.Terminated {
+				continue
+			}
+			pipelineName := run.Labels[labelPipeline]
+			allowed, err := i.allowed(projectName, fmt.Sprintf("project:%s/pipeline:%s/pipelineRun:%s", projectName, pipelineName, run.Name), nil, "resume")
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				continue
+			}
+			items = append(items, &apisv1.InboxItem{
+				Kind:       InboxKindPipelineRun,
+				Project:    projectName,
+				Name:       run.Name,
+				Entity:     pipelineName,
+				Message:    fmt.Sprintf("the run of the pipeline %s is waiting for the input", pipelineName),
+				CreateTime: run.CreationTimestamp.Time,
+			})
+		}
+	}
+	return items, nil
+}
+
+func (i *inbox) invitations(ctx context.Context) ([]*apisv1.InboxItem, error) {
+	entities, err := i.Store.List(ctx, &model.UserInvitation{}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var items []*apisv1.InboxItem
+	for _, entity := range entities {
+		invitation := entity.(*model.UserInvitation)
+		if invitation.Inviter != i.userName || now.After(invitation.ExpireTime) {
+			continue
+		}
+		items = append(items, &apisv1.InboxItem{
+			Kind:       InboxKindInvitation,
+			Name:       invitation.ID,
+			Entity:     invitation.Email,
+			Message:    fmt.Sprintf("the invitation expires at %s", invitation.ExpireTime.Format(time.RFC3339)),
+			CreateTime: invitation.CreateTime,
+		})
+	}
+	return items, nil
+}
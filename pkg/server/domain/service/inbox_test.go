@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+var _ = Describe("Test the inbox of the pending work", func() {
+	var (
+		ds           datastore.DataStore
+		inboxService *inboxServiceImpl
+	)
+	const projectName = "inbox-project"
+
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "inbox-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		inboxService = &inboxServiceImpl{Store: ds, KubeClient: k8sClient, RbacService: &rbacServiceImpl{Store: ds}, ProjectService: NewTestProjectService(ds, k8sClient)}
+	})
+
+	It("Test list the pending work by the permissions of the user", func() {
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Project{Name: projectName})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Permission{Name: "inbox-review", Project: projectName, Resources: []string{"project:inbox-project/serviceInstance:*", "project:inbox-project/application:*"}, Actions: []string{"approve", "resume"}, Effect: "Allow"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Role{Name: "inbox-reviewer", Project: projectName, Permissions: []string{"inbox-review"}})).Should(BeNil())
+		for _, user := range []string{"inbox-owner", "inbox-dev"} {
+			Expect(ds.Add(ctx, &model.User{Name: user})).Should(BeNil())
+			Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: projectName, Username: user, UserRoles: []string{"inbox-reviewer"}})).Should(BeNil())
+		}
+		Expect(ds.Add(ctx, &model.ServiceInstance{Name: "orders-db", Project: projectName, ServiceClass: "alibaba-rds", Status: model.ServiceInstanceStatusPending, Requester: "inbox-dev"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Application{Name: "inbox-app", Project: projectName})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.WorkflowRecord{Name: "inbox-app-v1", AppPrimaryKey: "inbox-app", WorkflowName: "workflow-dev", Status: "suspending"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.WorkflowRecord{Name: "inbox-app-v0", AppPrimaryKey: "inbox-app", WorkflowName: "workflow-dev", Status: "succeeded"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.RBACApproval{Name: "inbox-approval", Kind: "role", Operation: "create", ResourceName: "admin-copy", Status: model.RBACApprovalStatusPending, Requester: "inbox-dev"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.UserInvitation{ID: "invitation-1", Email: "new@example.com", Inviter: "inbox-owner", ExpireTime: time.Now().Add(time.Hour)})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.UserInvitation{ID: "invitation-2", Email: "old@example.com", Inviter: "inbox-owner", ExpireTime: time.Now().Add(-time.Hour)})).Should(BeNil())
+
+		res, err := inboxService.ListInbox(context.WithValue(ctx, &apisv1.CtxKeyUser, "inbox-owner"))
+		Expect(err).Should(BeNil())
+		kinds := map[string]string{}
+		for _, item := range res.Items {
+			kinds[item.Kind] = item.Name
+		}
+		Expect(res.Total).Should(Equal(int64(3)))
+		Expect(kinds).Should(Equal(map[string]string{
+			InboxKindServiceInstance: "orders-db",
+			InboxKindWorkflowRecord:  "inbox-app-v1",
+			InboxKindInvitation:      "invitation-1",
+		}))
+
+		By("the requests of the user are reviewed by others")
+		res, err = inboxService.ListInbox(context.WithValue(ctx, &apisv1.CtxKeyUser, "inbox-dev"))
+		Expect(err).Should(BeNil())
+		Expect(res.Items).Should(HaveLen(1))
+		Expect(res.Items[0].Kind).Should(Equal(InboxKindWorkflowRecord))
+	})
+})
@@ -0,0 +1,199 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// LintSeverityError the component violates the rule could not be saved
+	LintSeverityError = "error"
+	// LintSeverityWarning the issue is returned but the component is saved
+	LintSeverityWarning = "warning"
+	// LintSeverityInfo the issue is a suggestion
+	LintSeverityInfo = "info"
+	// LintSeverityOff disables the rule
+	LintSeverityOff = "off"
+)
+
+const (
+	// LintRuleImageLatestTag the image should be pinned to a version instead of the latest tag
+	LintRuleImageLatestTag = "image-latest-tag"
+	// LintRuleMissingProbes the long-running workload should have the liveness or readiness probe
+	LintRuleMissingProbes = "missing-probes"
+	// LintRuleMissingResourceLimits the long-running workload should set the CPU and memory resources
+	LintRuleMissingResourceLimits = "missing-resource-limits"
+)
+
+// lintRuleSeverities the severities of the lint rules, they are overridden by the server config
+var lintRuleSeverities = map[string]string{
+	LintRuleImageLatestTag:        LintSeverityWarning,
+	LintRuleMissingProbes:         LintSeverityWarning,
+	LintRuleMissingResourceLimits: LintSeverityWarning,
+}
+
+// lintWorkloadTypes the component types of the long-running workloads that are checked by the probe and resource rules
+var lintWorkloadTypes = map[string]bool{
+	"webservice": true,
+	"worker":     true,
+}
+
+type lintRule struct {
+	name  string
+	check func(component *model.ApplicationComponent) []apisv1.LintIssue
+}
+
+var lintRules = []lintRule{
+	{name: LintRuleImageLatestTag, check: lintImageLatestTag},
+	{name: LintRuleMissingProbes, check: lintMissingProbes},
+	{name: LintRuleMissingResourceLimits, check: lintMissingResourceLimits},
+}
+
+// setLintRuleSeverities overrides the severities of the lint rules
+func setLintRuleSeverities(severities map[string]string) error {
+	for rule, severity := range severities {
+		if _, ok := lintRuleSeverities[rule]; !ok {
+			return fmt.Errorf("unknown lint rule %s", rule)
+		}
+		switch severity {
+		case LintSeverityError, LintSeverityWarning, LintSeverityInfo, LintSeverityOff:
+			lintRuleSeverities[rule] = severity
+		default:
+			return fmt.Errorf("unknown severity %s of the lint rule %s", severity, rule)
+		}
+	}
+	return nil
+}
+
+// lintComponent evaluates the enabled rules against the component
+func lintComponent(component *model.ApplicationComponent) []apisv1.LintIssue {
+	var issues []apisv1.LintIssue
+	for _, rule := range lintRules {
+		severity := lintRuleSeverities[rule.name]
+		if severity == LintSeverityOff {
+			continue
+		}
+		for _, issue := range rule.check(component) {
+			issue.Rule = rule.name
+			issue.Severity = severity
+			issue.Component = component.Name
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// checkLintIssues rejects the issues with the error severity
+func checkLintIssues(issues []apisv1.LintIssue) error {
+	var messages []string
+	for _, issue := range issues {
+		if issue.Severity == LintSeverityError {
+			messages = append(messages, fmt.Sprintf("%s: %s", issue.Rule, issue.Message))
+		}
+	}
+	if len(messages) > 0 {
+		return bcode.ErrApplicationLintFailed.SetMessage("the component violates the lint rules, " + strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// lintApplication lints all the components of the application, the issues are sorted by the component
+func lintApplication(ctx context.Context, store datastore.DataStore, app *model.Application) (*apisv1.ApplicationLintResponse, error) {
+	components, err := store.List(ctx, &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ApplicationLintResponse{Issues: []apisv1.LintIssue{}}
+	for _, entity := range components {
+		res.Issues = append(res.Issues, lintComponent(entity.(*model.ApplicationComponent))...)
+	}
+	sort.SliceStable(res.Issues, func(i, j int) bool { return res.Issues[i].Component < res.Issues[j].Component })
+	return res, nil
+}
+
+func componentProperty(component *model.ApplicationComponent, key string) (interface{}, bool) {
+	if component.Properties == nil {
+		return nil, false
+	}
+	value, ok := (*component.Properties)[key]
+	return value, ok && value != nil
+}
+
+func lintImageLatestTag(component *model.ApplicationComponent) []apisv1.LintIssue {
+	value, _ := componentProperty(component, "image")
+	image, ok := value.(string)
+	if !ok || image == "" {
+		return nil
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil
+	}
+	if tag, ok := ref.(name.Tag); ok && tag.TagStr() == "latest" {
+		return []apisv1.LintIssue{{
+			Path:    "properties.image",
+			Message: fmt.Sprintf("the image %s uses the latest tag, pin a version or a digest to make the deployment repeatable", image),
+		}}
+	}
+	return nil
+}
+
+func lintMissingProbes(component *model.ApplicationComponent) []apisv1.LintIssue {
+	if !lintWorkloadTypes[component.Type] {
+		return nil
+	}
+	_, liveness := componentProperty(component, "livenessProbe")
+	_, readiness := componentProperty(component, "readinessProbe")
+	if liveness || readiness {
+		return nil
+	}
+	return []apisv1.LintIssue{{
+		Path:    "properties",
+		Message: "neither the liveness probe nor the readiness probe is set, the unhealthy instances are not restarted or removed from the service",
+	}}
+}
+
+func lintMissingResourceLimits(component *model.ApplicationComponent) []apisv1.LintIssue {
+	if !lintWorkloadTypes[component.Type] {
+		return nil
+	}
+	for _, key := range []string{"cpu", "memory", "limit"} {
+		if _, ok := componentProperty(component, key); ok {
+			return nil
+		}
+	}
+	for _, trait := range component.Traits {
+		if trait.Type == "resource" {
+			return nil
+		}
+	}
+	return []apisv1.LintIssue{{
+		Path:    "properties",
+		Message: "the CPU and memory resources are not set, the instances could exhaust the resources of the node",
+	}}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+func TestLintComponent(t *testing.T) {
+	testCases := map[string]struct {
+		component *model.ApplicationComponent
+		rules     []string
+	}{
+		"the implicit latest tag": {
+			component: &model.ApplicationComponent{Name: "task", Type: "task", Properties: &model.JSONStruct{"image": "busybox"}},
+			rules:     []string{LintRuleImageLatestTag},
+		},
+		"the pinned image by the digest": {
+			component: &model.ApplicationComponent{Name: "task", Type: "task", Properties: &model.JSONStruct{"image": "busybox@sha256:2376a0c12759aa1214ba83e771ff252c7b1663216b192fbe5e0fb364e952f85c"}},
+		},
+		"the webservice without the probes and resources": {
+			component: &model.ApplicationComponent{Name: "web", Type: "webservice", Properties: &model.JSONStruct{"image": "nginx:latest"}},
+			rules:     []string{LintRuleImageLatestTag, LintRuleMissingProbes, LintRuleMissingResourceLimits},
+		},
+		"the webservice with the probe and the resource trait": {
+			component: &model.ApplicationComponent{
+				Name:       "web",
+				Type:       "webservice",
+				Properties: &model.JSONStruct{"image": "nginx:1.21", "readinessProbe": map[string]interface{}{"httpGet": map[string]interface{}{"port": 80}}},
+				Traits:     []model.ApplicationTrait{{Type: "resource", Properties: &model.JSONStruct{"cpu": 1}}},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var rules []string
+			for _, issue := range lintComponent(tc.component) {
+				assert.Equal(t, issue.Component, tc.component.Name)
+				assert.Equal(t, issue.Severity, LintSeverityWarning)
+				rules = append(rules, issue.Rule)
+			}
+			assert.DeepEqual(t, rules, tc.rules)
+		})
+	}
+}
+
+func TestLintRuleSeverities(t *testing.T) {
+	defer func() {
+		assert.NilError(t, setLintRuleSeverities(map[string]string{LintRuleImageLatestTag: LintSeverityWarning, LintRuleMissingProbes: LintSeverityWarning}))
+	}()
+	assert.ErrorContains(t, setLintRuleSeverities(map[string]string{"unknown": LintSeverityError}), "unknown lint rule")
+	assert.ErrorContains(t, setLintRuleSeverities(map[string]string{LintRuleImageLatestTag: "fatal"}), "unknown severity")
+	assert.NilError(t, setLintRuleSeverities(map[string]string{LintRuleImageLatestTag: LintSeverityError, LintRuleMissingProbes: LintSeverityOff}))
+
+	issues := lintComponent(&model.ApplicationComponent{Name: "web", Type: "webservice", Properties: &model.JSONStruct{"image": "nginx", "cpu": "0.5"}})
+	assert.DeepEqual(t, issues, []apisv1.LintIssue{{
+		Rule:      LintRuleImageLatestTag,
+		Severity:  LintSeverityError,
+		Component: "web",
+		Path:      "properties.image",
+		Message:   "the image nginx uses the latest tag, pin a version or a digest to make the deployment repeatable",
+	}})
+	err := checkLintIssues(issues)
+	assert.ErrorContains(t, err, "image-latest-tag: the image nginx uses the latest tag")
+	assert.Equal(t, err.(*bcode.Bcode).BusinessCode, bcode.ErrApplicationLintFailed.BusinessCode)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+
+	pkgUtils "github.com/oam-dev/kubevela/pkg/utils"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	apiutils "github.com/kubevela/velaux/pkg/server/utils"
+)
+
+// apiTokenLoginInterval the API tokens authenticate every request, their logins are recorded once per interval
+const apiTokenLoginInterval = time.Hour
+
+// loginHistoryRetention how long the login records are kept, it is set by the server config, the datastore deletes the records after it
+var loginHistoryRetention = time.Hour * 24 * 90
+
+// recordLogin saves the login of the user with the client IP and the user agent in the context,
+// the failure is only logged because the login should not be blocked by the history
+func recordLogin(ctx context.Context, store datastore.DataStore, username, method string) {
+	record := &model.LoginRecord{
+		Name:     apiutils.GenerateVersion(username) + "-" + rand.String(4),
+		Username: username,
+		Method:   method,
+	}
+	record.SetExpireAt(time.Now().Add(loginHistoryRetention))
+	record.IP, _ = apiutils.ClientIPFrom(ctx)
+	record.UserAgent, _ = apiutils.UserAgentFrom(ctx)
+	if len(record.UserAgent) > maxDeviceLength {
+		record.UserAgent = record.UserAgent[:maxDeviceLength]
+	}
+	if err := store.Add(ctx, record); err != nil {
+		klog.Warningf("failed to record the login of the user %s: %s", pkgUtils.Sanitize(username), err.Error())
+	}
+}
+
+// ListLoginHistory lists the logins of the user in the retention, the latest first
+func (u *userServiceImpl) ListLoginHistory(ctx context.Context, user *model.User, page, pageSize int) (*apisv1.ListLoginHistoryResponse, error) {
+	var record = model.LoginRecord{Username: user.Name}
+	entities, err := u.Store.List(ctx, &record, &datastore.ListOptions{Page: page, PageSize: pageSize, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil {
+		return nil, err
+	}
+	var res = apisv1.ListLoginHistoryResponse{Records: []*apisv1.LoginRecordBase{}}
+	for _, entity := range entities {
+		r := entity.(*model.LoginRecord)
+		res.Records = append(res.Records, &apisv1.LoginRecordBase{
+			Time:      r.CreateTime,
+			Method:    r.Method,
+			IP:        r.IP,
+			UserAgent: r.UserAgent,
+		})
+	}
+	count, err := u.Store.Count(ctx, &record, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Total = count
+	return &res, nil
+}
+
+// deleteLoginHistory deletes the login records of the deleted user, so they are not inherited by a new user of the same name
+func deleteLoginHistory(ctx context.Context, store datastore.DataStore, username string) {
+	records, err := store.List(ctx, &model.LoginRecord{Username: username}, &datastore.ListOptions{})
+	if err != nil {
+		klog.Errorf("failed to list the login records of the user %s: %s", pkgUtils.Sanitize(username), err.Error())
+		return
+	}
+	for _, entity := range records {
+		if err := store.Delete(ctx, entity); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to delete the login record of the user %s: %s", pkgUtils.Sanitize(username), err.Error())
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+)
+
+var _ = Describe("Test the login history", func() {
+	var (
+		ds          datastore.DataStore
+		userService *userServiceImpl
+		authService *authenticationServiceImpl
+	)
+
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "login-history-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		sysService := &systemInfoServiceImpl{Store: ds, KubeClient: k8sClient}
+		userService = &userServiceImpl{Store: ds, SysService: sysService}
+		authService = &authenticationServiceImpl{KubeClient: k8sClient, Store: ds, SysService: sysService, UserService: userService}
+	})
+
+	It("Test record and list the logins", func() {
+		_, err := userService.CreateUser(context.Background(), apisv1.CreateUserRequest{
+			Name:     "history-user",
+			Email:    "history@example.com",
+			Password: "password1",
+		})
+		Expect(err).Should(BeNil())
+		ctx := utils.WithUserAgent(utils.WithClientIP(context.Background(), "10.0.0.3"), "Mozilla/5.0")
+		_, err = authService.Login(ctx, apisv1.LoginRequest{Username: "history-user", Password: "password1"})
+		Expect(err).Should(BeNil())
+
+		By("the API token logins are only recorded once per interval")
+		tokenService := &apiTokenServiceImpl{Store: ds, loginRecorded: utils.NewLRUCache(10, apiTokenLoginInterval)}
+		tokenCtx := context.WithValue(context.Background(), &apisv1.CtxKeyUser, "history-user")
+		token, err := tokenService.CreateAPIToken(tokenCtx, apisv1.CreateAPITokenRequest{Name: "ci"})
+		Expect(err).Should(BeNil())
+		for i := 0; i < 3; i++ {
+			_, err = tokenService.authenticate(context.Background(), token.Token)
+			Expect(err).Should(BeNil())
+		}
+
+		user, err := userService.GetUser(context.Background(), "history-user")
+		Expect(err).Should(BeNil())
+		history, err := userService.ListLoginHistory(context.Background(), user, 0, 0)
+		Expect(err).Should(BeNil())
+		Expect(history.Total).Should(Equal(int64(2)))
+		var methods []string
+		for _, r := range history.Records {
+			methods = append(methods, r.Method)
+			if r.Method == model.LoginTypeLocal {
+				Expect(r.IP).Should(Equal("10.0.0.3"))
+				Expect(r.UserAgent).Should(Equal("Mozilla/5.0"))
+			}
+		}
+		Expect(methods).Should(ConsistOf(model.LoginTypeLocal, model.LoginMethodAPIToken))
+	})
+
+	It("Test the login records expire after the retention", func() {
+		ctx := context.TODO()
+		recordLogin(ctx, ds, "purge-user", model.LoginTypeLocal)
+		entities, err := ds.List(ctx, &model.LoginRecord{Username: "purge-user"}, nil)
+		Expect(err).Should(BeNil())
+		Expect(len(entities)).Should(Equal(1))
+		record := entities[0].(*model.LoginRecord)
+		Expect(record.ExpireAt).ShouldNot(BeNil())
+		Expect(record.ExpireAt.Sub(record.CreateTime)).Should(BeNumerically("~", loginHistoryRetention, time.Minute))
+
+		deleteLoginHistory(ctx, ds, "purge-user")
+		count, err := ds.Count(ctx, &model.LoginRecord{Username: "purge-user"}, nil)
+		Expect(err).Should(BeNil())
+		Expect(count).Should(Equal(int64(0)))
+	})
+})
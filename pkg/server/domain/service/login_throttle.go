@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// the lockout of the local login, they are set from the server config
+var (
+	loginMaxFailures     = 5
+	loginLockoutDuration = time.Minute * 15
+)
+
+func userLoginAttemptKey(username string) string {
+	return "user:" + username
+}
+
+// loginAttemptKeys the failures are counted for both the user and the client IP,
+// so guessing the passwords of many users from one client is throttled too
+func loginAttemptKeys(username, clientIP string) []string {
+	keys := []string{userLoginAttemptKey(username)}
+	if clientIP != "" {
+		keys = append(keys, "ip:"+clientIP)
+	}
+	return keys
+}
+
+// checkLoginLocked returns the error if any of the keys is locked
+func checkLoginLocked(ctx context.Context, store datastore.DataStore, keys []string) error {
+	if loginMaxFailures <= 0 {
+		return nil
+	}
+	for _, key := range keys {
+		attempt := &model.LoginAttempt{Key: key}
+		if err := store.Get(ctx, attempt); err != nil {
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				continue
+			}
+			return err
+		}
+		if time.Now().Before(attempt.LockedUntil) {
+			return bcode.ErrLoginLocked.SetMessage(fmt.Sprintf("too many failed login attempts, please try again after %s", attempt.LockedUntil.Format(time.RFC3339)))
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure counts the failure, the key is locked once the failures reach the limit.
+// The failures older than the lockout duration are forgotten.
+func recordLoginFailure(ctx context.Context, store datastore.DataStore, keys []string) {
+	if loginMaxFailures <= 0 {
+		return
+	}
+	now := time.Now()
+	for _, key := range keys {
+		attempt := &model.LoginAttempt{Key: key}
+		err := store.Get(ctx, attempt)
+		if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to get the login attempts of %s: %s", key, err.Error())
+			continue
+		}
+		exist := err == nil
+		if now.Sub(attempt.LastFailure) > loginLockoutDuration {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailure = now
+		if attempt.Failures >= loginMaxFailures {
+			attempt.Failures = 0
+			attempt.LockedUntil = now.Add(loginLockoutDuration)
+			klog.Warningf("%s is locked until %s because of too many failed login attempts", key, attempt.LockedUntil.Format(time.RFC3339))
+		}
+		if exist {
+			err = store.Put(ctx, attempt)
+		} else {
+			err = store.Add(ctx, attempt)
+		}
+		if err != nil {
+			klog.Errorf("failed to save the login attempts of %s: %s", key, err.Error())
+		}
+	}
+}
+
+// resetLoginFailures forgets the failures of the key, it returns the ErrRecordNotExist if there is no failure
+func resetLoginFailures(ctx context.Context, store datastore.DataStore, key string) error {
+	return store.Delete(ctx, &model.LoginAttempt{Key: key})
+}
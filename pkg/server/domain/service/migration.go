@@ -0,0 +1,225 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// LatestMigrationVersion migrates to the latest version
+const LatestMigrationVersion = -1
+
+// migrationTargetVersion the applied migrations newer than it are reverted at the start
+var migrationTargetVersion = LatestMigrationVersion
+
+// Migration is a versioned change of the stored data. The migrations are applied in the order of the versions
+// at the start, they must be idempotent because a migration interrupted by a restart is applied again.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, store datastore.DataStore) error
+	// Down reverts the migration, the migration could not be reverted if it is nil
+	Down func(ctx context.Context, store datastore.DataStore) error
+}
+
+// migrations the migrations of the stored data, the new migrations are appended with the increasing versions
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "add-rbac-approver-and-maintenance-override-permissions",
+		Up: func(ctx context.Context, store datastore.DataStore) error {
+			for _, policy := range []*model.PermissionTemplate{rbacApproverPermission, maintenanceOverridePermission} {
+				if err := store.Add(ctx, &model.Permission{
+					Name:      policy.Name,
+					Alias:     policy.Alias,
+					Resources: policy.Resources,
+					Actions:   policy.Actions,
+					Effect:    policy.Effect,
+				}); err != nil && !errors.Is(err, datastore.ErrRecordExist) {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, store datastore.DataStore) error {
+			for _, policy := range []*model.PermissionTemplate{rbacApproverPermission, maintenanceOverridePermission} {
+				if err := store.Delete(ctx, &model.Permission{Name: policy.Name}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2,
+		Name:    "expire-the-sessions-tokens-and-login-records",
+		Up: func(ctx context.Context, store datastore.DataStore) error {
+			return setExpireAt(ctx, store, false)
+		},
+		Down: func(ctx context.Context, store datastore.DataStore) error {
+			return setExpireAt(ctx, store, true)
+		},
+	},
+}
+
+// setExpireAt sets the expiry of the sessions, the tokens and the login records stored before the datastore deletes the expired entities,
+// the expiry is cleared if reset is true
+func setExpireAt(ctx context.Context, store datastore.DataStore, reset bool) error {
+	for _, query := range []datastore.Entity{&model.Session{}, &model.PasswordResetToken{}, &model.EmailVerification{}, &model.UserInvitation{}, &model.LoginRecord{}} {
+		entities, err := store.List(ctx, query, &datastore.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, entity := range entities {
+			var base *model.BaseModel
+			var expireAt time.Time
+			switch e := entity.(type) {
+			case *model.Session:
+				base, expireAt = &e.BaseModel, e.ExpireTime
+			case *model.PasswordResetToken:
+				base, expireAt = &e.BaseModel, e.ExpireTime
+			case *model.EmailVerification:
+				base, expireAt = &e.BaseModel, e.ExpireTime
+			case *model.UserInvitation:
+				base, expireAt = &e.BaseModel, e.ExpireTime
+			case *model.LoginRecord:
+				base, expireAt = &e.BaseModel, e.CreateTime.Add(loginHistoryRetention)
+			default:
+				continue
+			}
+			if reset {
+				base.ExpireAt = nil
+			} else {
+				base.SetExpireAt(expireAt)
+			}
+			if err := store.Put(ctx, entity); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MigrationService migrates the stored data
+type MigrationService interface {
+	Init(ctx context.Context) error
+	Migrate(ctx context.Context, targetVersion int) error
+}
+
+type migrationServiceImpl struct {
+	Store      datastore.DataStore `inject:"datastore"`
+	migrations []Migration
+}
+
+// NewMigrationService new migration service
+func NewMigrationService() MigrationService {
+	return &migrationServiceImpl{migrations: migrations}
+}
+
+// Init migrates to the target version, it runs after the other services initialize the default data
+func (m *migrationServiceImpl) Init(ctx context.Context) error {
+	return m.Migrate(ctx, migrationTargetVersion)
+}
+
+// Migrate applies the migrations up to the target version and reverts the applied migrations newer than it
+func (m *migrationServiceImpl) Migrate(ctx context.Context, targetVersion int) error {
+	sorted := append([]Migration{}, m.migrations...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i := range sorted {
+		if sorted[i].Version <= 0 || (i > 0 && sorted[i].Version == sorted[i-1].Version) {
+			return fmt.Errorf("the version %d of the migration %s is invalid or duplicated", sorted[i].Version, sorted[i].Name)
+		}
+	}
+	if targetVersion == LatestMigrationVersion && len(sorted) > 0 {
+		targetVersion = sorted[len(sorted)-1].Version
+	}
+	entities, err := m.Store.List(ctx, &model.SchemaMigration{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	records := map[int]*model.SchemaMigration{}
+	for _, entity := range entities {
+		record := entity.(*model.SchemaMigration)
+		records[record.Version] = record
+	}
+
+	// revert from the newest one
+	for i := len(sorted) - 1; i >= 0; i-- {
+		migration := sorted[i]
+		record, applied := records[migration.Version]
+		if migration.Version <= targetVersion || !applied {
+			continue
+		}
+		if migration.Down == nil {
+			return fmt.Errorf("the migration %d %s could not be reverted", migration.Version, migration.Name)
+		}
+		klog.Infof("reverting the migration %d %s", migration.Version, migration.Name)
+		if err := m.Store.Transaction(ctx, func(tx datastore.DataStore) error {
+			if err := migration.Down(ctx, tx); err != nil {
+				return err
+			}
+			return tx.Delete(ctx, record)
+		}); err != nil {
+			return fmt.Errorf("revert the migration %d %s failure %w", migration.Version, migration.Name, err)
+		}
+		delete(records, migration.Version)
+	}
+	for version := range records {
+		if version > targetVersion {
+			return fmt.Errorf("the applied migration %d is unknown, the data is migrated by a newer version", version)
+		}
+	}
+
+	for _, migration := range sorted {
+		if migration.Version > targetVersion {
+			break
+		}
+		record, exist := records[migration.Version]
+		if exist && record.Status == model.MigrationStatusApplied {
+			continue
+		}
+		if !exist {
+			record = &model.SchemaMigration{Version: migration.Version, Name: migration.Name, Status: model.MigrationStatusApplying}
+			if err := m.Store.Add(ctx, record); err != nil && !errors.Is(err, datastore.ErrRecordExist) {
+				return err
+			}
+		} else {
+			klog.Warningf("the migration %d %s was interrupted, applying it again", migration.Version, migration.Name)
+		}
+		klog.Infof("applying the migration %d %s", migration.Version, migration.Name)
+		if err := m.Store.Transaction(ctx, func(tx datastore.DataStore) error {
+			if err := migration.Up(ctx, tx); err != nil {
+				return err
+			}
+			record.Status = model.MigrationStatusApplied
+			record.AppliedAt = time.Now()
+			return tx.Put(ctx, record)
+		}); err != nil {
+			return fmt.Errorf("apply the migration %d %s failure %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+var _ = Describe("Test the datastore migrations", func() {
+	var ds datastore.DataStore
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "migration-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+	})
+
+	It("Test apply and revert the migrations in order", func() {
+		ctx := context.TODO()
+		var applied []string
+		step := func(name string) func(ctx context.Context, store datastore.DataStore) error {
+			return func(ctx context.Context, store datastore.DataStore) error {
+				applied = append(applied, name)
+				return nil
+			}
+		}
+		migrationService := &migrationServiceImpl{Store: ds, migrations: []Migration{
+			{Version: 2, Name: "second", Up: step("up-2"), Down: step("down-2")},
+			{Version: 1, Name: "first", Up: step("up-1"), Down: step("down-1")},
+			{Version: 3, Name: "third", Up: step("up-3")},
+		}}
+		Expect(migrationService.Migrate(ctx, 2)).Should(BeNil())
+		Expect(applied).Should(Equal([]string{"up-1", "up-2"}))
+
+		By("the applied migrations are not applied again")
+		applied = nil
+		Expect(migrationService.Migrate(ctx, LatestMigrationVersion)).Should(BeNil())
+		Expect(applied).Should(Equal([]string{"up-3"}))
+		record := &model.SchemaMigration{Version: 3}
+		Expect(ds.Get(ctx, record)).Should(BeNil())
+		Expect(record.Status).Should(Equal(model.MigrationStatusApplied))
+
+		By("the migrations without the down could not be reverted")
+		Expect(migrationService.Migrate(ctx, 1)).ShouldNot(BeNil())
+		Expect(ds.Delete(ctx, &model.SchemaMigration{Version: 3})).Should(BeNil())
+		applied = nil
+		Expect(migrationService.Migrate(ctx, 1)).Should(BeNil())
+		Expect(applied).Should(Equal([]string{"down-2"}))
+		exist, err := ds.IsExist(ctx, &model.SchemaMigration{Version: 2})
+		Expect(err).Should(BeNil())
+		Expect(exist).Should(BeFalse())
+	})
+
+	It("Test the failed migration is applied again", func() {
+		ctx := context.TODO()
+		failed := true
+		migrationService := &migrationServiceImpl{Store: ds, migrations: []Migration{{
+			Version: 1,
+			Name:    "flaky",
+			Up: func(ctx context.Context, store datastore.DataStore) error {
+				if err := store.Add(ctx, &model.Target{Name: "migrated-target"}); err != nil {
+					return err
+				}
+				if failed {
+					return errors.New("interrupted")
+				}
+				return nil
+			},
+		}}}
+		Expect(migrationService.Migrate(ctx, LatestMigrationVersion)).ShouldNot(BeNil())
+		By("the writes of the failed migration are rolled back")
+		exist, err := ds.IsExist(ctx, &model.Target{Name: "migrated-target"})
+		Expect(err).Should(BeNil())
+		Expect(exist).Should(BeFalse())
+		record := &model.SchemaMigration{Version: 1}
+		Expect(ds.Get(ctx, record)).Should(BeNil())
+		Expect(record.Status).Should(Equal(model.MigrationStatusApplying))
+
+		failed = false
+		Expect(migrationService.Migrate(ctx, LatestMigrationVersion)).Should(BeNil())
+		Expect(ds.Get(ctx, record)).Should(BeNil())
+		Expect(record.Status).Should(Equal(model.MigrationStatusApplied))
+	})
+
+	It("Test the built-in migrations", func() {
+		ctx := context.TODO()
+		Expect(NewMigrationService().(*migrationServiceImpl).migrations).ShouldNot(BeEmpty())
+		session := &model.Session{ID: "migration-session", Username: "migration-user", ExpireTime: time.Now().Add(time.Hour)}
+		Expect(ds.Add(ctx, session)).Should(BeNil())
+		migrationService := &migrationServiceImpl{Store: ds, migrations: migrations}
+		Expect(migrationService.Migrate(ctx, LatestMigrationVersion)).Should(BeNil())
+		exist, err := ds.IsExist(ctx, &model.Permission{Name: rbacApproverPermission.Name})
+		Expect(err).Should(BeNil())
+		Expect(exist).Should(BeTrue())
+		Expect(ds.Get(ctx, session)).Should(BeNil())
+		Expect(session.ExpireAt).ShouldNot(BeNil())
+		Expect(session.ExpireAt.Equal(session.ExpireTime)).Should(BeTrue())
+
+		Expect(migrationService.Migrate(ctx, 1)).Should(BeNil())
+		session = &model.Session{ID: "migration-session"}
+		Expect(ds.Get(ctx, session)).Should(BeNil())
+		Expect(session.ExpireAt).Should(BeNil())
+	})
+})
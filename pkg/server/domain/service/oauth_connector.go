@@ -0,0 +1,199 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// mergeOAuthConnectors converts the requested connectors to the models, the empty client secret keeps the secret of the existing connector
+func mergeOAuthConnectors(existing []model.OAuthConnector, req []apisv1.OAuthConnector) ([]model.OAuthConnector, error) {
+	secrets := make(map[string]string, len(existing))
+	for _, c := range existing {
+		secrets[c.ID] = c.ClientSecret
+	}
+	ids := make(map[string]struct{}, len(req))
+	connectors := make([]model.OAuthConnector, 0, len(req))
+	for _, c := range req {
+		if _, exist := ids[c.ID]; exist {
+			return nil, bcode.ErrOAuthConnectorExist
+		}
+		ids[c.ID] = struct{}{}
+		secret := c.ClientSecret
+		if secret == "" {
+			secret = secrets[c.ID]
+		}
+		if secret == "" {
+			return nil, bcode.ErrOAuthConnectorSecretRequired
+		}
+		name := c.Name
+		if name == "" {
+			name = c.ID
+		}
+		connectors = append(connectors, model.OAuthConnector{
+			Type:         c.Type,
+			ID:           c.ID,
+			Name:         name,
+			ClientID:     c.ClientID,
+			ClientSecret: secret,
+			BaseURL:      strings.TrimSuffix(c.BaseURL, "/"),
+			Orgs:         c.Orgs,
+		})
+	}
+	return connectors, nil
+}
+
+func validateOAuthGroupMappings(mappings []model.OAuthGroupMapping) error {
+	for _, m := range mappings {
+		if m.Group == "" {
+			return bcode.ErrInvalidOAuthGroupMapping
+		}
+	}
+	return nil
+}
+
+// convertOAuthConnectors2DTO converts the connectors without the client secrets
+func convertOAuthConnectors2DTO(connectors []model.OAuthConnector) []apisv1.OAuthConnector {
+	var res []apisv1.OAuthConnector
+	for _, c := range connectors {
+		res = append(res, apisv1.OAuthConnector{Type: c.Type, ID: c.ID, Name: c.Name, ClientID: c.ClientID, BaseURL: c.BaseURL, Orgs: c.Orgs})
+	}
+	return res
+}
+
+// buildDexOAuthConnectors generates the dex connectors, the groups claim includes the organizations and the teams of GitHub
+// in the "org:team" format, or the full paths of the GitLab groups
+func buildDexOAuthConnectors(connectors []model.OAuthConnector, issuer string) []map[string]interface{} {
+	var res []map[string]interface{}
+	for _, c := range connectors {
+		config := map[string]interface{}{
+			"clientID":     c.ClientID,
+			"clientSecret": c.ClientSecret,
+			"redirectURI":  issuer + "/callback",
+		}
+		switch c.Type {
+		case model.OAuthConnectorGitHub:
+			if c.BaseURL != "" {
+				config["hostName"] = strings.TrimPrefix(strings.TrimPrefix(c.BaseURL, "https://"), "http://")
+			}
+			var orgs []map[string]interface{}
+			for _, org := range c.Orgs {
+				orgs = append(orgs, map[string]interface{}{"name": org})
+			}
+			if len(orgs) > 0 {
+				config["orgs"] = orgs
+			}
+			config["loadAllGroups"] = true
+			config["teamNameField"] = "slug"
+		case model.OAuthConnectorGitLab:
+			if c.BaseURL != "" {
+				config["baseURL"] = c.BaseURL
+			}
+			if len(c.Orgs) > 0 {
+				config["groups"] = c.Orgs
+			}
+		default:
+			continue
+		}
+		res = append(res, map[string]interface{}{
+			"type":   c.Type,
+			"id":     c.ID,
+			"name":   c.Name,
+			"config": config,
+		})
+	}
+	return res
+}
+
+// oauthGroupGrants collects the projects and the platform roles mapped from the groups of the user
+func oauthGroupGrants(mappings []model.OAuthGroupMapping, groups []string) (map[string][]string, []string) {
+	member := make(map[string]struct{}, len(groups))
+	for _, g := range groups {
+		member[g] = struct{}{}
+	}
+	projects := map[string][]string{}
+	var platformRoles []string
+	for _, m := range mappings {
+		if _, ok := member[m.Group]; !ok {
+			continue
+		}
+		for _, p := range m.Projects {
+			projects[p.Name] = mergeRoles(projects[p.Name], p.Roles)
+		}
+		platformRoles = mergeRoles(platformRoles, m.PlatformRoles)
+	}
+	return projects, platformRoles
+}
+
+func mergeRoles(roles []string, added []string) []string {
+	for _, role := range added {
+		exist := false
+		for _, r := range roles {
+			if r == role {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// applyOAuthGroupMappings grants the mapped projects and platform roles at every login, the grants are only added,
+// the roles removed from the mappings or the groups left by the user should be revoked by the administrators
+func (d *dexHandlerImpl) applyOAuthGroupMappings(ctx context.Context, user *model.User, mappings []model.OAuthGroupMapping, groups []string) {
+	projects, platformRoles := oauthGroupGrants(mappings, groups)
+	if roles := mergeRoles(user.UserRoles, platformRoles); len(roles) != len(user.UserRoles) {
+		user.UserRoles = roles
+		if err := d.Store.Put(ctx, user); err != nil {
+			klog.Errorf("failed to grant the platform roles to the user %s: %s", user.Name, err.Error())
+		}
+	}
+	for project, roles := range projects {
+		_, err := d.projectService.AddProjectUser(ctx, project, apisv1.AddProjectUserRequest{UserName: user.Name, UserRoles: roles})
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, bcode.ErrProjectUserExist) {
+			klog.Errorf("failed to add the user %s to the project %s by the group mappings: %s", user.Name, project, err.Error())
+			continue
+		}
+		projectUser := &model.ProjectUser{ProjectName: project, Username: user.Name}
+		if err := d.Store.Get(ctx, projectUser); err != nil {
+			if !errors.Is(err, datastore.ErrRecordNotExist) {
+				klog.Errorf("failed to get the user %s of the project %s: %s", user.Name, project, err.Error())
+			}
+			continue
+		}
+		if merged := mergeRoles(projectUser.UserRoles, roles); len(merged) != len(projectUser.UserRoles) {
+			if _, err := d.projectService.UpdateProjectUser(ctx, project, user.Name, apisv1.UpdateProjectUserRequest{UserRoles: merged}); err != nil {
+				klog.Errorf("failed to grant the roles of the project %s to the user %s: %s", project, user.Name, err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+func TestMergeOAuthConnectors(t *testing.T) {
+	existing := []model.OAuthConnector{{Type: model.OAuthConnectorGitHub, ID: "github", ClientID: "old", ClientSecret: "kept"}}
+	connectors, err := mergeOAuthConnectors(existing, []apisv1.OAuthConnector{
+		{Type: model.OAuthConnectorGitHub, ID: "github", ClientID: "new"},
+		{Type: model.OAuthConnectorGitLab, ID: "gitlab", ClientID: "lab", ClientSecret: "secret", BaseURL: "https://gitlab.example.com/"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, connectors[0].ClientSecret, "kept")
+	assert.Equal(t, connectors[0].Name, "github")
+	assert.Equal(t, connectors[1].BaseURL, "https://gitlab.example.com")
+
+	_, err = mergeOAuthConnectors(nil, []apisv1.OAuthConnector{{Type: model.OAuthConnectorGitHub, ID: "github", ClientID: "new"}})
+	assert.Equal(t, err, bcode.ErrOAuthConnectorSecretRequired)
+	_, err = mergeOAuthConnectors(existing, []apisv1.OAuthConnector{{ID: "github"}, {ID: "github"}})
+	assert.Equal(t, err, bcode.ErrOAuthConnectorExist)
+}
+
+func TestBuildDexOAuthConnectors(t *testing.T) {
+	connectors := buildDexOAuthConnectors([]model.OAuthConnector{
+		{Type: model.OAuthConnectorGitHub, ID: "github", Name: "GitHub", ClientID: "id", ClientSecret: "secret", BaseURL: "https://github.example.com", Orgs: []string{"kubevela"}},
+		{Type: model.OAuthConnectorGitLab, ID: "gitlab", Name: "GitLab", ClientID: "id", ClientSecret: "secret", Orgs: []string{"platform/infra"}},
+	}, "https://velaux.example.com/dex")
+	assert.Equal(t, len(connectors), 2)
+	github := connectors[0]["config"].(map[string]interface{})
+	assert.Equal(t, github["redirectURI"], "https://velaux.example.com/dex/callback")
+	assert.Equal(t, github["hostName"], "github.example.com")
+	assert.DeepEqual(t, github["orgs"], []map[string]interface{}{{"name": "kubevela"}})
+	gitlab := connectors[1]["config"].(map[string]interface{})
+	assert.DeepEqual(t, gitlab["groups"], []string{"platform/infra"})
+	assert.Equal(t, gitlab["baseURL"], nil)
+}
+
+func TestOAuthGroupGrants(t *testing.T) {
+	mappings := []model.OAuthGroupMapping{
+		{Group: "kubevela", PlatformRoles: []string{"platform-viewer"}},
+		{Group: "kubevela:sre", Projects: []model.ProjectRef{{Name: "ops", Roles: []string{"project-admin"}}}, PlatformRoles: []string{"platform-viewer", "admin"}},
+		{Group: "kubevela:dev", Projects: []model.ProjectRef{{Name: "ops", Roles: []string{"app-developer"}}}},
+	}
+	projects, roles := oauthGroupGrants(mappings, []string{"kubevela", "kubevela:sre"})
+	assert.DeepEqual(t, projects, map[string][]string{"ops": {"project-admin"}})
+	assert.DeepEqual(t, roles, []string{"platform-viewer", "admin"})
+
+	projects, roles = oauthGroupGrants(mappings, nil)
+	assert.Equal(t, len(projects), 0)
+	assert.Equal(t, len(roles), 0)
+}
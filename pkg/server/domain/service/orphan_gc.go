@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kubevela/workflow/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
+)
+
+const (
+	// orphanGCPolicyTrash the orphaned applications are moved to the trash, so they could be restored
+	orphanGCPolicyTrash = "trash"
+	// orphanGCPolicyPurge the orphaned applications are deleted permanently
+	orphanGCPolicyPurge = "purge"
+	// orphanGCPolicyDisabled the orphans are kept
+	orphanGCPolicyDisabled = "disabled"
+)
+
+var (
+	// orphanGCPolicy what to do with the orphans, it is set by the server config
+	orphanGCPolicy = orphanGCPolicyTrash
+	// orphanGCGracePeriod how long the records must stay orphaned before they are collected
+	orphanGCGracePeriod = time.Hour
+)
+
+// OrphanGCService collects the records of the resources deleted directly in the cluster
+type OrphanGCService interface {
+	// CollectOrphans collects the synced applications whose CRs are deleted and the archived logs of the deleted
+	// pipeline runs, once they are orphaned longer than the grace period
+	CollectOrphans(ctx context.Context) error
+}
+
+type orphanGCServiceImpl struct {
+	Store              datastore.DataStore `inject:"datastore"`
+	KubeClient         client.Client       `inject:"kubeClient"`
+	ApplicationService ApplicationService  `inject:""`
+	EnvBindingService  EnvBindingService   `inject:""`
+	LogStore           logstore.Store      `inject:"logStore"`
+	// orphans when the orphans are found at first, the ones found back are forgotten by the next pass. They are
+	// kept in memory, so the grace period restarts with the server.
+	orphans map[string]time.Time
+}
+
+// NewOrphanGCService new orphan gc service
+func NewOrphanGCService() OrphanGCService {
+	return &orphanGCServiceImpl{orphans: map[string]time.Time{}}
+}
+
+// CollectOrphans collects the orphans by the policy, the archived logs of the pipeline runs are always deleted as
+// the pipeline runs deleted by VelaUX
+func (o *orphanGCServiceImpl) CollectOrphans(ctx context.Context) error {
+	if orphanGCPolicy == orphanGCPolicyDisabled {
+		return nil
+	}
+	now := time.Now()
+	found := map[string]time.Time{}
+	// expired marks the record orphaned and returns whether it is orphaned longer than the grace period
+	expired := func(key string) bool {
+		since, ok := o.orphans[key]
+		if !ok {
+			since = now
+		}
+		found[key] = since
+		return now.Sub(since) >= orphanGCGracePeriod
+	}
+	defer func() {
+		o.orphans = found
+	}()
+	if err := o.collectApplications(ctx, expired); err != nil {
+		return err
+	}
+	return o.collectPipelineRunLogs(ctx, expired)
+}
+
+// collectApplications deletes the synced applications whose CRs are deleted in all envs
+func (o *orphanGCServiceImpl) collectApplications(ctx context.Context, expired func(key string) bool) error {
+	apps, err := o.Store.List(ctx, &model.Application{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range apps {
+		app := entity.(*model.Application)
+		if !app.IsSynced() || app.Labels[model.LabelSyncNamespace] == "" {
+			continue
+		}
+		orphaned, err := o.isApplicationOrphaned(ctx, app)
+		if err != nil {
+			klog.Warningf("failed to check whether the CR of the application %s exists: %s", app.Name, err.Error())
+			continue
+		}
+		if !orphaned || !expired("application/"+app.Name) {
+			continue
+		}
+		deleteCtx := ctx
+		if orphanGCPolicy == orphanGCPolicyPurge {
+			deleteCtx = withoutTrash(ctx)
+		}
+		if err := o.ApplicationService.DeleteApplication(deleteCtx, app); err != nil {
+			klog.Errorf("failed to collect the orphaned application %s: %s", app.Name, err.Error())
+			continue
+		}
+		klog.Infof("the application %s is collected by the policy %s, its CR was deleted in the cluster", app.Name, orphanGCPolicy)
+	}
+	return nil
+}
+
+// isApplicationOrphaned returns true if the CRs of the synced application are not found. The applications of the
+// detached clusters are never orphaned, they are kept like the sync does.
+func (o *orphanGCServiceImpl) isApplicationOrphaned(ctx context.Context, app *model.Application) (bool, error) {
+	bindings, err := o.EnvBindingService.GetEnvBindings(ctx, app)
+	if err != nil || len(bindings) == 0 {
+		return false, err
+	}
+	kubeCtx := ctx
+	if cluster := app.Labels[model.LabelSyncCluster]; cluster != "" {
+		if _, err := multicluster.GetVirtualCluster(ctx, o.KubeClient, cluster); err != nil {
+			return false, nil
+		}
+		kubeCtx = multicluster.ContextWithClusterName(ctx, cluster)
+	}
+	for _, binding := range bindings {
+		var appCR v1beta1.Application
+		err := o.KubeClient.Get(kubeCtx, types.NamespacedName{Namespace: binding.AppDeployNamespace, Name: binding.AppDeployName}, &appCR)
+		if err == nil {
+			return false, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// collectPipelineRunLogs deletes the archived step logs of the pipeline runs deleted in the cluster
+func (o *orphanGCServiceImpl) collectPipelineRunLogs(ctx context.Context, expired func(key string) bool) error {
+	logs, err := o.Store.List(ctx, &model.StepLog{Resource: stepLogResourcePipeline}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	namespaces := map[string]string{}
+	checked := map[string]bool{}
+	for _, entity := range logs {
+		stepLog := entity.(*model.StepLog)
+		key := "pipelinerun/" + stepLog.Project + "/" + stepLog.Record
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+		namespace, ok := namespaces[stepLog.Project]
+		if !ok {
+			project := &model.Project{Name: stepLog.Project}
+			if err := o.Store.Get(ctx, project); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+				klog.Warningf("failed to get the project %s: %s", stepLog.Project, err.Error())
+				continue
+			}
+			namespace = project.GetNamespace()
+			namespaces[stepLog.Project] = namespace
+		}
+		err := o.KubeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: stepLog.Record}, &v1alpha1.WorkflowRun{})
+		if err == nil || !apierrors.IsNotFound(err) {
+			continue
+		}
+		if !expired(key) {
+			continue
+		}
+		deleteStepLogs(ctx, o.Store, o.LogStore, &model.StepLog{Resource: stepLogResourcePipeline, Project: stepLog.Project, Entity: stepLog.Entity, Record: stepLog.Record})
+		klog.Infof("the archived logs of the pipeline run %s are collected, the run was deleted in the cluster", stepLog.Record)
+	}
+	return nil
+}
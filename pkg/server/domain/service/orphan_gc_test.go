@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	velatypes "github.com/oam-dev/kubevela/apis/types"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
+)
+
+// deletingAppService records the applications deleted by the gc
+type deletingAppService struct {
+	ApplicationService
+	deleted []string
+	trashed []bool
+}
+
+func (d *deletingAppService) DeleteApplication(ctx context.Context, app *model.Application) error {
+	skip, _ := ctx.Value(skipTrashKey{}).(bool)
+	d.deleted = append(d.deleted, app.Name)
+	d.trashed = append(d.trashed, !skip)
+	return nil
+}
+
+var _ = Describe("Test the gc of the orphaned records", func() {
+	It("Test collect the orphans out of the grace period", func() {
+		ctx := context.Background()
+		ds, err := NewDatastore(datastore.Config{Type: "kubeapi", Database: "orphan-gc-test"})
+		Expect(err).Should(BeNil())
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "orphan-gc"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Project{Name: "orphan-gc"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Env{Name: "orphan-gc-env", Project: "orphan-gc", Namespace: "orphan-gc"})).Should(BeNil())
+		for _, name := range []string{"running-app", "deleted-app"} {
+			Expect(ds.Add(ctx, &model.Application{Name: name, Project: "orphan-gc", Labels: map[string]string{
+				velatypes.LabelSourceOfTruth: velatypes.FromCR,
+				model.LabelSyncNamespace:     "orphan-gc",
+			}})).Should(BeNil())
+			Expect(ds.Add(ctx, &model.EnvBinding{AppPrimaryKey: name, Name: "orphan-gc-env", AppDeployName: name})).Should(BeNil())
+		}
+		Expect(k8sClient.Create(ctx, &v1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "running-app", Namespace: "orphan-gc"},
+			Spec:       v1beta1.ApplicationSpec{Components: []common.ApplicationComponent{{Name: "web", Type: "webservice"}}},
+		})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.StepLog{Name: "deleted-run-deploy", Resource: stepLogResourcePipeline, Project: "orphan-gc", Entity: "release", Record: "deleted-run", Step: "deploy", Content: "done"})).Should(BeNil())
+
+		appService := &deletingAppService{}
+		gc := &orphanGCServiceImpl{
+			Store:              ds,
+			KubeClient:         k8sClient,
+			ApplicationService: appService,
+			EnvBindingService:  &envBindingServiceImpl{Store: ds},
+			LogStore:           logstore.New(logstore.Config{}),
+			orphans:            map[string]time.Time{},
+		}
+
+		By("the orphans are kept in the grace period")
+		Expect(gc.CollectOrphans(ctx)).Should(BeNil())
+		Expect(appService.deleted).Should(BeEmpty())
+		Expect(gc.orphans).Should(HaveLen(1))
+
+		By("the orphans out of the grace period are collected")
+		for key := range gc.orphans {
+			gc.orphans[key] = time.Now().Add(-orphanGCGracePeriod)
+		}
+		Expect(gc.CollectOrphans(ctx)).Should(BeNil())
+		Expect(appService.deleted).Should(Equal([]string{"deleted-app"}))
+		Expect(appService.trashed).Should(Equal([]bool{true}))
+
+		By("the logs are kept if the pipeline runs could not be checked, the WorkflowRun CRD is not installed")
+		exist, err := ds.IsExist(ctx, &model.StepLog{Name: "deleted-run-deploy"})
+		Expect(err).Should(BeNil())
+		Expect(exist).Should(BeTrue())
+
+		By("the purged applications skip the trash")
+		orphanGCPolicy = orphanGCPolicyPurge
+		defer func() { orphanGCPolicy = orphanGCPolicyTrash }()
+		gc.orphans["application/deleted-app"] = time.Now().Add(-orphanGCGracePeriod)
+		Expect(gc.CollectOrphans(ctx)).Should(BeNil())
+		Expect(appService.trashed).Should(Equal([]bool{true, false}))
+
+		By("nothing is collected if the gc is disabled")
+		orphanGCPolicy = orphanGCPolicyDisabled
+		Expect(gc.CollectOrphans(ctx)).Should(BeNil())
+		Expect(appService.deleted).Should(HaveLen(2))
+	})
+})
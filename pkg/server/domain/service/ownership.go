@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/slack"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const maxOwnershipTeamLength = 64
+
+// slackChannelRegexp matches the channel names with or without the leading #, and the channel IDs
+var slackChannelRegexp = regexp.MustCompile(`^(#?[a-z0-9][a-z0-9._-]{0,79}|[CG][A-Z0-9]{8,20})$`)
+
+// checkOwnership trims and validates the ownership, nil is returned if all fields are empty
+func checkOwnership(ownership *model.Ownership) (*model.Ownership, error) {
+	if ownership == nil {
+		return nil, nil
+	}
+	res := &model.Ownership{
+		Team:         strings.TrimSpace(ownership.Team),
+		OnCallURL:    strings.TrimSpace(ownership.OnCallURL),
+		SlackChannel: strings.TrimSpace(ownership.SlackChannel),
+	}
+	if res.Team == "" && res.OnCallURL == "" && res.SlackChannel == "" {
+		return nil, nil
+	}
+	if len(res.Team) > maxOwnershipTeamLength {
+		return nil, bcode.ErrInvalidOwnership
+	}
+	if res.OnCallURL != "" {
+		u, err := url.Parse(res.OnCallURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, bcode.ErrInvalidOwnership
+		}
+	}
+	if res.SlackChannel != "" && !slackChannelRegexp.MatchString(res.SlackChannel) {
+		return nil, bcode.ErrInvalidOwnership
+	}
+	return res, nil
+}
+
+// ownershipContact renders the ownership as the lines appended to the alerts, it is empty if there is no ownership
+func ownershipContact(ownership *model.Ownership) string {
+	if ownership == nil {
+		return ""
+	}
+	var lines []string
+	if ownership.Team != "" {
+		lines = append(lines, "Owner team: "+ownership.Team)
+	}
+	if ownership.OnCallURL != "" {
+		lines = append(lines, "On-call: "+ownership.OnCallURL)
+	}
+	if ownership.SlackChannel != "" {
+		channel := ownership.SlackChannel
+		// the channel IDs are upper case, only the names are prefixed
+		if !strings.HasPrefix(channel, "#") && channel == strings.ToLower(channel) {
+			channel = "#" + channel
+		}
+		lines = append(lines, "Slack: "+channel)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\n" + strings.Join(lines, "\n")
+}
+
+// alertDeployFailure posts the failed deploy to the Slack channel of the application owners, the failure is only logged
+func alertDeployFailure(ctx context.Context, sysService SystemInfoService, slackSender slack.Sender, app *model.Application, record *model.WorkflowRecord) {
+	if app.Ownership == nil || app.Ownership.SlackChannel == "" || slackSender == nil || !slackSender.Enabled() {
+		return
+	}
+	text := fmt.Sprintf("The deploy %s of the application %s in the project %s failed: %s", record.Name, app.Name, app.Project, record.Message)
+	if err := sendSlackMessage(ctx, sysService, slackSender, app.Ownership.SlackChannel, text+ownershipContact(app.Ownership)); err != nil {
+		klog.Errorf("failed to alert the owners of the application %s: %s", app.Name, err.Error())
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/slack"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+type fakeSlackSender struct {
+	channel string
+	text    string
+}
+
+func (f *fakeSlackSender) Enabled() bool {
+	return true
+}
+
+func (f *fakeSlackSender) Send(_ context.Context, channel, text string) error {
+	f.channel = channel
+	f.text = text
+	return nil
+}
+
+var _ = Describe("Test the ownership of the applications and pipelines", func() {
+	It("Test check the ownership", func() {
+		ownership, err := checkOwnership(&model.Ownership{Team: " payments ", OnCallURL: "https://oncall.example.com/payments", SlackChannel: "#payments-oncall"})
+		Expect(err).Should(BeNil())
+		Expect(ownership.Team).Should(Equal("payments"))
+
+		ownership, err = checkOwnership(&model.Ownership{Team: "  "})
+		Expect(err).Should(BeNil())
+		Expect(ownership).Should(BeNil())
+
+		_, err = checkOwnership(&model.Ownership{OnCallURL: "javascript:alert(1)"})
+		Expect(err).Should(Equal(bcode.ErrInvalidOwnership))
+		_, err = checkOwnership(&model.Ownership{SlackChannel: "#Payments OnCall"})
+		Expect(err).Should(Equal(bcode.ErrInvalidOwnership))
+		_, err = checkOwnership(&model.Ownership{SlackChannel: "C0123ABCDE"})
+		Expect(err).Should(BeNil())
+	})
+
+	It("Test alert the owners of the failed deploys", func() {
+		sender := &fakeSlackSender{}
+		app := &model.Application{Name: "payments", Project: "default", Ownership: &model.Ownership{Team: "payments", OnCallURL: "https://oncall.example.com/payments", SlackChannel: "payments-oncall"}}
+		alertDeployFailure(context.TODO(), nil, sender, app, &model.WorkflowRecord{Name: "payments-v2", Message: "step deploy failed"})
+		Expect(sender.channel).Should(Equal("payments-oncall"))
+		Expect(sender.text).Should(ContainSubstring("payments-v2"))
+		Expect(sender.text).Should(ContainSubstring("Owner team: payments\nOn-call: https://oncall.example.com/payments\nSlack: #payments-oncall"))
+
+		By("the applications without the Slack channel are not alerted")
+		sender = &fakeSlackSender{}
+		alertDeployFailure(context.TODO(), nil, sender, &model.Application{Name: "orphan", Ownership: &model.Ownership{Team: "payments"}}, &model.WorkflowRecord{Name: "orphan-v1"})
+		Expect(sender.text).Should(BeEmpty())
+		Expect(ownershipContact(nil)).Should(BeEmpty())
+
+		By("nothing is sent to the Slack API in the offline mode")
+		ds, err := NewDatastore(datastore.Config{Type: "kubeapi", Database: "ownership-offline-test"})
+		Expect(err).Should(BeNil())
+		sysService := systemInfoServiceImpl{Store: ds}
+		info, err := sysService.Get(context.TODO())
+		Expect(err).Should(BeNil())
+		_, err = sysService.UpdateSystemInfo(context.TODO(), apisv1.SystemInfoRequest{LoginType: info.LoginType, OfflineMode: pointer.BoolPtr(true)})
+		Expect(err).Should(BeNil())
+		sender = &fakeSlackSender{}
+		alertDeployFailure(context.TODO(), sysService, sender, app, &model.WorkflowRecord{Name: "payments-v3", Message: "step deploy failed"})
+		Expect(sender.text).Should(BeEmpty())
+		Expect(sendSlackMessage(context.TODO(), sysService, sender, "payments-oncall", "hello")).Should(Equal(slack.ErrOffline))
+		Expect(sender.text).Should(BeEmpty())
+	})
+})
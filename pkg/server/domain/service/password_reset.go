@@ -0,0 +1,213 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/email"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/securityevent"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// passwordResetTokenExpiration how long the password reset token is valid
+const passwordResetTokenExpiration = 30 * time.Minute
+
+var (
+	// passwordResetUserLimiter limits the reset emails sent to each user, so the users could not be flooded
+	passwordResetUserLimiter = utils.NewRateLimiter(1.0/300, 3, 10000)
+	// passwordResetIPLimiter limits the reset requests of each client IP
+	passwordResetIPLimiter = utils.NewRateLimiter(1.0/60, 10, 10000)
+)
+
+// PasswordResetService resets the password of the local users by the token sent to their email
+type PasswordResetService interface {
+	RequestPasswordReset(ctx context.Context, req apisv1.PasswordResetRequest) error
+	ConfirmPasswordReset(ctx context.Context, req apisv1.ConfirmPasswordResetRequest) error
+}
+
+type passwordResetServiceImpl struct {
+	Store       datastore.DataStore `inject:"datastore"`
+	SysService  SystemInfoService   `inject:""`
+	EmailSender email.Sender        `inject:"emailSender"`
+}
+
+// NewPasswordResetService new password reset service
+func NewPasswordResetService() PasswordResetService {
+	return &passwordResetServiceImpl{}
+}
+
+// RequestPasswordReset sends the token to the email of the user.
+// It succeeds even if the user does not exist or the email fails to send, so the users could not be enumerated.
+func (p *passwordResetServiceImpl) RequestPasswordReset(ctx context.Context, req apisv1.PasswordResetRequest) error {
+	if err := p.checkLocalLogin(ctx); err != nil {
+		return err
+	}
+	if !p.EmailSender.Enabled() {
+		return bcode.ErrPasswordResetDisabled
+	}
+	if ip, ok := utils.ClientIPFrom(ctx); ok && !passwordResetIPLimiter.Allow(ip) {
+		return bcode.ErrPasswordResetRateLimited
+	}
+	user, err := p.findUser(ctx, req)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Disabled || user.Email == "" {
+		klog.Infof("skip the password reset request of the user %s%s", req.Username, req.Email)
+		return nil
+	}
+	if !passwordResetUserLimiter.Allow(user.Name) {
+		klog.Infof("skip the password reset request of the user %s, too many requests", user.Name)
+		return nil
+	}
+	token, err := generateSecretToken()
+	if err != nil {
+		return err
+	}
+	resetToken := &model.PasswordResetToken{
+		Username:   user.Name,
+		TokenHash:  hashSecretToken(token),
+		ExpireTime: time.Now().Add(passwordResetTokenExpiration),
+	}
+	// the datastore deletes the token after it expires
+	resetToken.SetExpireAt(resetToken.ExpireTime)
+	if err := p.Store.Delete(ctx, resetToken); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return err
+	}
+	if err := p.Store.Add(ctx, resetToken); err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Hi %s,\n\nUse the token below to reset your VelaUX password, it expires in %s.\n\n%s\n\nIf you did not request the password reset, please ignore this email.\n",
+		user.Name, passwordResetTokenExpiration, token)
+	if err := p.EmailSender.Send(ctx, user.Email, "Reset your VelaUX password", body); err != nil {
+		klog.Errorf("failed to send the password reset email to the user %s: %s", user.Name, err.Error())
+	}
+	return nil
+}
+
+// ConfirmPasswordReset sets the new password if the token is valid, the token could only be used once
+func (p *passwordResetServiceImpl) ConfirmPasswordReset(ctx context.Context, req apisv1.ConfirmPasswordResetRequest) (err error) {
+	defer func() {
+		event := securityevent.Event{Type: securityevent.TypePasswordReset, Outcome: securityevent.OutcomeSuccess, Username: req.Username}
+		if err != nil {
+			event.Outcome = securityevent.OutcomeFailure
+			event.Reason = err.Error()
+		}
+		emitSecurityEvent(ctx, event)
+	}()
+	if err := p.checkLocalLogin(ctx); err != nil {
+		return err
+	}
+	resetToken := &model.PasswordResetToken{Username: req.Username}
+	if err := p.Store.Get(ctx, resetToken); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrPasswordResetTokenInvalid
+		}
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(resetToken.TokenHash), []byte(hashSecretToken(req.Token))) != 1 {
+		return bcode.ErrPasswordResetTokenInvalid
+	}
+	if time.Now().After(resetToken.ExpireTime) {
+		return bcode.ErrPasswordResetTokenExpired
+	}
+	user := &model.User{Name: req.Username}
+	if err := p.Store.Get(ctx, user); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrPasswordResetTokenInvalid
+		}
+		return err
+	}
+	if user.Disabled {
+		return bcode.ErrUserAlreadyDisabled
+	}
+	hash, err := GeneratePasswordHash(req.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+	user.MustChangePassword = false
+	// the token is used up only if the password is reset and the sessions are revoked
+	return datastore.InTransaction(ctx, p.Store, func(ctx context.Context) error {
+		if err := p.Store.Put(ctx, user); err != nil {
+			return err
+		}
+		if err := revokeUserSessions(ctx, p.Store, user.Name, ""); err != nil {
+			return err
+		}
+		return p.Store.Delete(ctx, resetToken)
+	})
+}
+
+func (p *passwordResetServiceImpl) checkLocalLogin(ctx context.Context) error {
+	sysInfo, err := p.SysService.Get(ctx)
+	if err != nil {
+		return err
+	}
+	if sysInfo.LoginType == model.LoginTypeDex {
+		return bcode.ErrUnsupportedLoginType
+	}
+	return nil
+}
+
+func (p *passwordResetServiceImpl) findUser(ctx context.Context, req apisv1.PasswordResetRequest) (*model.User, error) {
+	if req.Username != "" {
+		user := &model.User{Name: req.Username}
+		if err := p.Store.Get(ctx, user); err != nil {
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return user, nil
+	}
+	users, err := p.Store.List(ctx, &model.User{Email: req.Email}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		return nil, nil
+	}
+	return users[0].(*model.User), nil
+}
+
+func generateSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/email"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+type fakeEmailSender struct {
+	to   string
+	body string
+}
+
+func (f *fakeEmailSender) Enabled() bool {
+	return true
+}
+
+func (f *fakeEmailSender) Send(_ context.Context, to, _, body string) error {
+	f.to = to
+	f.body = body
+	return nil
+}
+
+var _ = Describe("Test the password reset", func() {
+	var (
+		ds           datastore.DataStore
+		sender       *fakeEmailSender
+		resetService *passwordResetServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "password-reset-test-kubevela"})
+		Expect(err).Should(BeNil())
+		sender = &fakeEmailSender{}
+		resetService = &passwordResetServiceImpl{Store: ds, SysService: &systemInfoServiceImpl{Store: ds}, EmailSender: sender}
+		hash, err := GeneratePasswordHash("Reset12345")
+		Expect(err).Should(BeNil())
+		err = ds.Add(context.TODO(), &model.User{Name: "reset-user", Email: "reset@example.com", Password: hash})
+		Expect(err == nil || err == datastore.ErrRecordExist).Should(BeTrue())
+	})
+
+	It("Test reset the password with the token", func() {
+		ctx := context.TODO()
+		By("the unknown user is not revealed")
+		Expect(resetService.RequestPasswordReset(ctx, apisv1.PasswordResetRequest{Username: "not-exist"})).Should(BeNil())
+		Expect(sender.to).Should(BeEmpty())
+
+		Expect(resetService.RequestPasswordReset(ctx, apisv1.PasswordResetRequest{Email: "reset@example.com"})).Should(BeNil())
+		Expect(sender.to).Should(Equal("reset@example.com"))
+		token := regexp.MustCompile(`[0-9a-f]{64}`).FindString(sender.body)
+		Expect(token).ShouldNot(BeEmpty())
+
+		err := resetService.ConfirmPasswordReset(ctx, apisv1.ConfirmPasswordResetRequest{Username: "reset-user", Token: "invalid", Password: "NewPassword1"})
+		Expect(err).Should(Equal(bcode.ErrPasswordResetTokenInvalid))
+
+		err = resetService.ConfirmPasswordReset(ctx, apisv1.ConfirmPasswordResetRequest{Username: "reset-user", Token: token, Password: "NewPassword1"})
+		Expect(err).Should(BeNil())
+		user := &model.User{Name: "reset-user"}
+		Expect(ds.Get(ctx, user)).Should(BeNil())
+		Expect(compareHashWithPassword(user.Password, "NewPassword1")).Should(BeNil())
+
+		By("the token could only be used once")
+		err = resetService.ConfirmPasswordReset(ctx, apisv1.ConfirmPasswordResetRequest{Username: "reset-user", Token: token, Password: "NewPassword2"})
+		Expect(err).Should(Equal(bcode.ErrPasswordResetTokenInvalid))
+	})
+
+	It("Test the expired token", func() {
+		ctx := context.TODO()
+		Expect(resetService.RequestPasswordReset(ctx, apisv1.PasswordResetRequest{Username: "reset-user"})).Should(BeNil())
+		token := regexp.MustCompile(`[0-9a-f]{64}`).FindString(sender.body)
+		resetToken := &model.PasswordResetToken{Username: "reset-user"}
+		Expect(ds.Get(ctx, resetToken)).Should(BeNil())
+		Expect(resetToken.ExpireAt).ShouldNot(BeNil())
+		Expect(resetToken.ExpireAt.Equal(resetToken.ExpireTime)).Should(BeTrue())
+		resetToken.ExpireTime = time.Now().Add(-time.Minute)
+		Expect(ds.Put(ctx, resetToken)).Should(BeNil())
+
+		err := resetService.ConfirmPasswordReset(ctx, apisv1.ConfirmPasswordResetRequest{Username: "reset-user", Token: token, Password: "NewPassword1"})
+		Expect(err).Should(Equal(bcode.ErrPasswordResetTokenExpired))
+	})
+
+	It("Test the password reset requests are rate limited", func() {
+		userLimiter, ipLimiter := passwordResetUserLimiter, passwordResetIPLimiter
+		defer func() {
+			passwordResetUserLimiter, passwordResetIPLimiter = userLimiter, ipLimiter
+		}()
+		passwordResetUserLimiter = utils.NewRateLimiter(1.0/300, 1, 10)
+		passwordResetIPLimiter = utils.NewRateLimiter(1.0/60, 2, 10)
+		ctx := utils.WithClientIP(context.TODO(), "10.0.0.9")
+		Expect(resetService.RequestPasswordReset(ctx, apisv1.PasswordResetRequest{Username: "reset-user"})).Should(BeNil())
+		Expect(sender.to).Should(Equal("reset@example.com"))
+
+		By("the user is not flooded with the emails and the limit is not revealed")
+		sender.to = ""
+		Expect(resetService.RequestPasswordReset(ctx, apisv1.PasswordResetRequest{Email: "reset@example.com"})).Should(BeNil())
+		Expect(sender.to).Should(BeEmpty())
+
+		By("the client IP is limited")
+		err := resetService.RequestPasswordReset(ctx, apisv1.PasswordResetRequest{Username: "not-exist"})
+		Expect(err).Should(Equal(bcode.ErrPasswordResetRateLimited))
+	})
+
+	It("Test the password reset is disabled without the email sender", func() {
+		resetService.EmailSender = email.New(email.Config{})
+		err := resetService.RequestPasswordReset(context.TODO(), apisv1.PasswordResetRequest{Username: "reset-user"})
+		Expect(err).Should(Equal(bcode.ErrPasswordResetDisabled))
+	})
+})
@@ -0,0 +1,213 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// PermissionSnapshotService records the effective permissions of the users to answer what a user could do at a point in time
+type PermissionSnapshotService interface {
+	SnapshotPermissions(ctx context.Context) error
+	GetUserPermissionsAt(ctx context.Context, username string, at time.Time) (*apisv1.UserPermissionSnapshotResponse, error)
+}
+
+type permissionSnapshotServiceImpl struct {
+	Store       datastore.DataStore `inject:"datastore"`
+	RbacService RBACService         `inject:""`
+}
+
+// NewPermissionSnapshotService new permission snapshot service
+func NewPermissionSnapshotService() PermissionSnapshotService {
+	return &permissionSnapshotServiceImpl{}
+}
+
+// SnapshotPermissions stores the roles and permissions of the users that changed since their latest snapshots,
+// and marks the deleted users as removed.
+func (p *permissionSnapshotServiceImpl) SnapshotPermissions(ctx context.Context) error {
+	latest, err := p.listLatestSnapshots(ctx)
+	if err != nil {
+		return err
+	}
+	users, err := p.Store.List(ctx, &model.User{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	existing := make(map[string]bool, len(users))
+	for _, entity := range users {
+		user := entity.(*model.User)
+		existing[user.Name] = true
+		snapshot, err := p.buildSnapshot(ctx, user)
+		if err != nil {
+			klog.Errorf("failed to snapshot the permissions of the user %s: %s", user.Name, err.Error())
+			continue
+		}
+		if last, ok := latest[user.Name]; ok && !last.Removed && last.Digest == snapshot.Digest {
+			continue
+		}
+		if err := p.addSnapshot(ctx, snapshot, now); err != nil {
+			return err
+		}
+	}
+	for username, last := range latest {
+		if existing[username] || last.Removed {
+			continue
+		}
+		if err := p.addSnapshot(ctx, &model.PermissionSnapshot{Username: username, Removed: true}, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetUserPermissionsAt returns the snapshot of the user that was effective at the time
+func (p *permissionSnapshotServiceImpl) GetUserPermissionsAt(ctx context.Context, username string, at time.Time) (*apisv1.UserPermissionSnapshotResponse, error) {
+	entities, err := p.Store.List(ctx, &model.PermissionSnapshot{Username: username}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var effective *model.PermissionSnapshot
+	for _, entity := range entities {
+		snapshot := entity.(*model.PermissionSnapshot)
+		if snapshot.SnapshotTime.After(at) {
+			continue
+		}
+		if effective == nil || snapshot.SnapshotTime.After(effective.SnapshotTime) {
+			effective = snapshot
+		}
+	}
+	if effective == nil {
+		return nil, bcode.ErrPermissionSnapshotNotExist
+	}
+	resp := &apisv1.UserPermissionSnapshotResponse{
+		Username:      username,
+		Time:          at,
+		SnapshotTime:  effective.SnapshotTime,
+		Disabled:      effective.Disabled,
+		Removed:       effective.Removed,
+		PlatformRoles: effective.PlatformRoles,
+		ProjectRoles:  effective.ProjectRoles,
+		Permissions:   effective.Permissions,
+	}
+	if resp.PlatformRoles == nil {
+		resp.PlatformRoles = []string{}
+	}
+	if resp.ProjectRoles == nil {
+		resp.ProjectRoles = []model.ProjectRolesSnapshot{}
+	}
+	if resp.Permissions == nil {
+		resp.Permissions = []model.PermissionRecord{}
+	}
+	return resp, nil
+}
+
+func (p *permissionSnapshotServiceImpl) listLatestSnapshots(ctx context.Context) (map[string]*model.PermissionSnapshot, error) {
+	entities, err := p.Store.List(ctx, &model.PermissionSnapshot{}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]*model.PermissionSnapshot)
+	for _, entity := range entities {
+		snapshot := entity.(*model.PermissionSnapshot)
+		if last, ok := latest[snapshot.Username]; !ok || snapshot.SnapshotTime.After(last.SnapshotTime) {
+			latest[snapshot.Username] = snapshot
+		}
+	}
+	return latest, nil
+}
+
+// buildSnapshot collects the platform permissions and the permissions of each project that the user is a member of
+func (p *permissionSnapshotServiceImpl) buildSnapshot(ctx context.Context, user *model.User) (*model.PermissionSnapshot, error) {
+	snapshot := &model.PermissionSnapshot{
+		Username:      user.Name,
+		Disabled:      user.Disabled,
+		PlatformRoles: append([]string{}, user.UserRoles...),
+	}
+	sort.Strings(snapshot.PlatformRoles)
+	perms, err := p.RbacService.GetUserPermissions(ctx, user, "", true)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Permissions = append(snapshot.Permissions, convertPermissionRecords("", perms)...)
+	projectUsers, err := p.Store.List(ctx, &model.ProjectUser{Username: user.Name}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range projectUsers {
+		projectUser := entity.(*model.ProjectUser)
+		roles := append([]string{}, projectUser.UserRoles...)
+		sort.Strings(roles)
+		snapshot.ProjectRoles = append(snapshot.ProjectRoles, model.ProjectRolesSnapshot{Project: projectUser.ProjectName, Roles: roles})
+		perms, err := p.RbacService.GetUserPermissions(ctx, user, projectUser.ProjectName, false)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Permissions = append(snapshot.Permissions, convertPermissionRecords(projectUser.ProjectName, perms)...)
+	}
+	sort.Slice(snapshot.ProjectRoles, func(i, j int) bool {
+		return snapshot.ProjectRoles[i].Project < snapshot.ProjectRoles[j].Project
+	})
+	sort.SliceStable(snapshot.Permissions, func(i, j int) bool {
+		if snapshot.Permissions[i].Project != snapshot.Permissions[j].Project {
+			return snapshot.Permissions[i].Project < snapshot.Permissions[j].Project
+		}
+		return snapshot.Permissions[i].Name < snapshot.Permissions[j].Name
+	})
+	digest, err := json.Marshal([]interface{}{snapshot.Disabled, snapshot.PlatformRoles, snapshot.ProjectRoles, snapshot.Permissions})
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(digest)
+	snapshot.Digest = hex.EncodeToString(sum[:])
+	return snapshot, nil
+}
+
+func (p *permissionSnapshotServiceImpl) addSnapshot(ctx context.Context, snapshot *model.PermissionSnapshot, now time.Time) error {
+	snapshot.Name = utils.GenerateVersion(snapshot.Username) + "-" + rand.String(4)
+	snapshot.SnapshotTime = now
+	return p.Store.Add(ctx, snapshot)
+}
+
+func convertPermissionRecords(project string, perms []*model.Permission) []model.PermissionRecord {
+	var records []model.PermissionRecord
+	for _, perm := range perms {
+		records = append(records, model.PermissionRecord{
+			Project:   project,
+			Name:      perm.Name,
+			Resources: perm.Resources,
+			Actions:   perm.Actions,
+			Effect:    perm.Effect,
+			Priority:  perm.Priority,
+		})
+	}
+	return records
+}
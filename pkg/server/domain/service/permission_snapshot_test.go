@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the permission snapshots", func() {
+	var (
+		ds              datastore.DataStore
+		snapshotService *permissionSnapshotServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "permission-snapshot-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		snapshotService = &permissionSnapshotServiceImpl{Store: ds, RbacService: &rbacServiceImpl{Store: ds}}
+	})
+
+	It("Test query the permissions at a point in time", func() {
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Permission{Name: "snapshot-view", Project: "snapshot-project", Resources: []string{"project:snapshot-project/application:*"}, Actions: []string{"detail"}, Effect: "Allow"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Role{Name: "snapshot-viewer", Project: "snapshot-project", Permissions: []string{"snapshot-view"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.User{Name: "snapshot-user"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: "snapshot-project", Username: "snapshot-user", UserRoles: []string{"snapshot-viewer"}})).Should(BeNil())
+
+		before := time.Now()
+		Expect(snapshotService.SnapshotPermissions(ctx)).Should(BeNil())
+		_, err := snapshotService.GetUserPermissionsAt(ctx, "snapshot-user", before.Add(-time.Hour))
+		Expect(err).Should(Equal(bcode.ErrPermissionSnapshotNotExist))
+		granted, err := snapshotService.GetUserPermissionsAt(ctx, "snapshot-user", time.Now())
+		Expect(err).Should(BeNil())
+		Expect(granted.ProjectRoles).Should(Equal([]model.ProjectRolesSnapshot{{Project: "snapshot-project", Roles: []string{"snapshot-viewer"}}}))
+		Expect(granted.Permissions).Should(ContainElement(WithTransform(func(p model.PermissionRecord) string { return p.Project + "/" + p.Name }, Equal("snapshot-project/snapshot-view"))))
+
+		By("the unchanged permissions are not stored again")
+		Expect(snapshotService.SnapshotPermissions(ctx)).Should(BeNil())
+		count, err := ds.Count(ctx, &model.PermissionSnapshot{Username: "snapshot-user"}, nil)
+		Expect(err).Should(BeNil())
+		Expect(count).Should(Equal(int64(1)))
+
+		time.Sleep(10 * time.Millisecond)
+		revokedAt := time.Now()
+		time.Sleep(10 * time.Millisecond)
+		Expect(ds.Delete(ctx, &model.ProjectUser{ProjectName: "snapshot-project", Username: "snapshot-user"})).Should(BeNil())
+		Expect(snapshotService.SnapshotPermissions(ctx)).Should(BeNil())
+		revoked, err := snapshotService.GetUserPermissionsAt(ctx, "snapshot-user", time.Now())
+		Expect(err).Should(BeNil())
+		Expect(revoked.ProjectRoles).Should(BeEmpty())
+		By("the earlier time still answers the granted permissions")
+		previous, err := snapshotService.GetUserPermissionsAt(ctx, "snapshot-user", revokedAt)
+		Expect(err).Should(BeNil())
+		Expect(previous.ProjectRoles).ShouldNot(BeEmpty())
+
+		Expect(ds.Delete(ctx, &model.User{Name: "snapshot-user"})).Should(BeNil())
+		Expect(snapshotService.SnapshotPermissions(ctx)).Should(BeNil())
+		removed, err := snapshotService.GetUserPermissionsAt(ctx, "snapshot-user", time.Now())
+		Expect(err).Should(BeNil())
+		Expect(removed.Removed).Should(BeTrue())
+		Expect(removed.Permissions).Should(BeEmpty())
+	})
+})
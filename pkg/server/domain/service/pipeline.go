@@ -46,11 +46,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	velatypes "github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 	pkgutils "github.com/oam-dev/kubevela/pkg/utils"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/email"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/slack"
 	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	"github.com/kubevela/velaux/pkg/server/utils"
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
@@ -69,6 +73,7 @@ type PipelineService interface {
 	UpdatePipeline(ctx context.Context, name string, req apis.UpdatePipelineRequest) (*apis.PipelineBase, error)
 	DeletePipeline(ctx context.Context, base apis.PipelineBase) error
 	RunPipeline(ctx context.Context, pipeline apis.PipelineBase, req apis.RunPipelineRequest) (*apis.PipelineRun, error)
+	SyncStepDurations(ctx context.Context) error
 }
 
 type pipelineServiceImpl struct {
@@ -78,6 +83,9 @@ type pipelineServiceImpl struct {
 	KubeClient         client.Client       `inject:"kubeClient"`
 	KubeConfig         *rest.Config        `inject:"kubeConfig"`
 	PipelineRunService PipelineRunService  `inject:""`
+	EmailSender        email.Sender        `inject:"emailSender"`
+	SlackSender        slack.Sender        `inject:"slackSender"`
+	SysService         SystemInfoService   `inject:""`
 	Version            string
 }
 
@@ -100,6 +108,7 @@ type pipelineRunServiceImpl struct {
 	KubeConfig     *rest.Config        `inject:"kubeConfig"`
 	ContextService ContextService      `inject:""`
 	ProjectService ProjectService      `inject:""`
+	LogStore       logstore.Store      `inject:"logStore"`
 }
 
 // ContextService is the interface for context service
@@ -138,12 +147,17 @@ func (p pipelineServiceImpl) CreatePipeline(ctx context.Context, req apis.Create
 	if err := checkPipelineSpec(req.Spec); err != nil {
 		return nil, err
 	}
+	ownership, err := checkOwnership(req.Ownership)
+	if err != nil {
+		return nil, err
+	}
 	pipeline := &model.Pipeline{
 		Name:        req.Name,
 		Description: req.Description,
 		Alias:       req.Alias,
 		Project:     project.Name,
 		Spec:        req.Spec,
+		Ownership:   ownership,
 	}
 	if err := p.Store.Add(ctx, pipeline); err != nil {
 		if errors.Is(err, datastore.ErrRecordExist) {
@@ -151,6 +165,7 @@ func (p pipelineServiceImpl) CreatePipeline(ctx context.Context, req apis.Create
 		}
 		return nil, err
 	}
+	warnProjectQuotaUsage(ctx, p.Store, project.Name)
 	return &apis.PipelineBase{
 		PipelineMeta: apis.PipelineMeta{
 			Name:  req.Name,
@@ -160,6 +175,7 @@ func (p pipelineServiceImpl) CreatePipeline(ctx context.Context, req apis.Create
 				Alias: project.Alias,
 			},
 			Description: req.Description,
+			Ownership:   pipeline.Ownership,
 		},
 		Spec: pipeline.Spec,
 	}, nil
@@ -282,6 +298,10 @@ func (p pipelineServiceImpl) UpdatePipeline(ctx context.Context, name string, re
 	if err := checkPipelineSpec(req.Spec); err != nil {
 		return nil, err
 	}
+	ownership, err := checkOwnership(req.Ownership)
+	if err != nil {
+		return nil, err
+	}
 	pipeline := &model.Pipeline{
 		Name:    name,
 		Project: project.Name,
@@ -293,13 +313,18 @@ func (p pipelineServiceImpl) UpdatePipeline(ctx context.Context, name string, re
 		return nil, err
 	}
 
+	before := specSnapshot(pipeline)
 	pipeline.Spec = req.Spec
 	pipeline.Description = req.Description
 	pipeline.Alias = req.Alias
+	if req.Ownership != nil {
+		pipeline.Ownership = ownership
+	}
 
 	if err := p.Store.Put(ctx, pipeline); err != nil {
 		return nil, err
 	}
+	recordSpecAudit(ctx, p.Store, &model.SpecAudit{Resource: SpecAuditResourcePipeline, Project: project.Name, Entity: name}, before, specSnapshot(pipeline))
 	return pipeline2PipelineBase(pipeline, *project), nil
 }
 
@@ -316,20 +341,32 @@ func (p pipelineServiceImpl) DeletePipeline(ctx context.Context, pl apis.Pipelin
 		}
 		return err
 	}
-	// Clean up pipeline: 1. delete pipeline runs 2. delete contexts 3. delete pipeline
-	if err := p.PipelineRunService.CleanPipelineRuns(ctx, pl); err != nil {
-		klog.Errorf("delete pipeline all pipeline-runs failure: %s", err.Error())
+	var records []datastore.Entity
+	pipelineCtx := &model.PipelineContext{ProjectName: project.Name, PipelineName: pl.Name}
+	if err := p.Store.Get(ctx, pipelineCtx); err == nil {
+		records = append(records, pipelineCtx)
+	} else if !errors.Is(err, datastore.ErrRecordNotExist) {
 		return err
 	}
-	if err := p.ContextService.DeleteAllContexts(ctx, pl.Project.Name, pl.Name); err != nil {
-		klog.Errorf("delete pipeline all context failure: %s", err.Error())
-		return err
-	}
-	if err := p.Store.Delete(ctx, pipeline); err != nil {
-		return err
-	}
-
-	return nil
+	// the pipeline runs in the clusters are not restored if the deletion is rolled back
+	return datastore.InTransaction(ctx, p.Store, func(ctx context.Context) error {
+		if _, err := moveToTrash(ctx, p.Store, TrashKindPipeline, project.Name, pipeline.Alias, pipeline, records...); err != nil {
+			return err
+		}
+		// Clean up pipeline: 1. delete pipeline runs 2. delete contexts 3. delete pipeline
+		if err := p.PipelineRunService.CleanPipelineRuns(ctx, pl); err != nil {
+			klog.Errorf("delete pipeline all pipeline-runs failure: %s", err.Error())
+			return err
+		}
+		if err := p.ContextService.DeleteAllContexts(ctx, pl.Project.Name, pl.Name); err != nil {
+			klog.Errorf("delete pipeline all context failure: %s", err.Error())
+			return err
+		}
+		if err := p.Store.Delete(ctx, &model.PipelineStepBaseline{Project: project.Name, PipelineName: pl.Name}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("delete pipeline step baseline failure: %s", err.Error())
+		}
+		return p.Store.Delete(ctx, pipeline)
+	})
 }
 
 func (p pipelineRunServiceImpl) GetPipelineRunOutput(ctx context.Context, pipelineRun apis.PipelineRun, stepName string) (apis.GetPipelineRunOutputResponse, error) {
@@ -477,12 +514,20 @@ func (p pipelineRunServiceImpl) GetPipelineRunLog(ctx context.Context, pipelineR
 	if pipelineRun.Status.ContextBackend == nil {
 		return apis.GetPipelineRunLogResponse{}, nil
 	}
+	stepBase := getStepBase(pipelineRun, step)
+	archived := &model.StepLog{Resource: stepLogResourcePipeline, Project: project.Name, Entity: pipelineRun.PipelineName, Record: pipelineRun.PipelineRunName, Step: step}
+	if logs, ok := loadStepLog(ctx, p.Store, p.LogStore, archived); ok {
+		return apis.GetPipelineRunLogResponse{
+			StepBase: stepBase,
+			Log:      logs,
+		}, nil
+	}
 
 	logConfig, err := wfUtils.GetLogConfigFromStep(ctx, p.KubeClient, pipelineRun.Status.ContextBackend.Name, pipelineRun.PipelineName, project.GetNamespace(), step)
 	if err != nil {
 		if strings.Contains(err.Error(), "no log config found") {
 			return apis.GetPipelineRunLogResponse{
-				StepBase: getStepBase(pipelineRun, step),
+				StepBase: stepBase,
 				Log:      "",
 			}, nil
 		}
@@ -521,8 +566,12 @@ func (p pipelineRunServiceImpl) GetPipelineRunLog(ctx context.Context, pipelineR
 			logs = logsBuilder.String()
 		}
 	}
+	logs = redactor.Redact(logs)
+	if logs != "" && stepFinished(stepBase.Phase) {
+		archiveStepLog(ctx, p.Store, p.LogStore, archived, logs)
+	}
 	return apis.GetPipelineRunLogResponse{
-		StepBase: getStepBase(pipelineRun, step),
+		StepBase: stepBase,
 		Log:      logs,
 	}, nil
 }
@@ -563,7 +612,7 @@ func getStepOutputs(step v1alpha1.StepStatus, outputsSpec map[string]v1alpha1.St
 		values = append(values, apis.OutputVar{
 			Name:      output.Name,
 			ValueFrom: output.ValueFrom,
-			Value:     s,
+			Value:     redactor.RedactField(output.Name, s),
 		})
 	}
 	o.Values = values
@@ -590,7 +639,7 @@ func getStepInputs(step v1alpha1.StepStatus, inputsSpec map[string]v1alpha1.Step
 			continue
 		}
 		values = append(values, apis.InputVar{
-			Value:        s,
+			Value:        redactor.RedactField(input.ParameterKey, s),
 			From:         input.From,
 			FromStep:     valueFromStep[input.From],
 			ParameterKey: input.ParameterKey,
@@ -776,6 +825,13 @@ func (p pipelineServiceImpl) RunPipeline(ctx context.Context, pipeline apis.Pipe
 			return nil, err
 		}
 	}
+	runner, err := ensurePipelineRunner(ctx, p.KubeClient, p.Store, project)
+	if err != nil {
+		return nil, err
+	}
+	if err := k8s.AddAnnotation(&run, oam.AnnotationApplicationServiceAccountName, runner); err != nil {
+		return nil, err
+	}
 	// process the context
 	if req.ContextName != "" {
 		ppContext, err := p.ContextService.GetContext(ctx, pipeline.Project.Name, pipeline.Name, req.ContextName)
@@ -828,6 +884,13 @@ func (p pipelineServiceImpl) getPipelineInfo(ctx context.Context, wf *model.Pipe
 	pi := &apis.PipelineInfo{
 		RunStat: runStat,
 	}
+	baseline := &model.PipelineStepBaseline{Project: wf.Project, PipelineName: wf.Name}
+	if err := p.Store.Get(ctx, baseline); err == nil {
+		pi.StepDurations = getStepDurationStats(baseline)
+		pi.Regressions = baseline.Regressions
+	} else if !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
 	if run != nil {
 		projectName := wf.Project
 		project, err := p.ProjectService.GetProject(ctx, projectName)
@@ -968,8 +1031,11 @@ func (p pipelineRunServiceImpl) DeletePipelineRun(ctx context.Context, meta apis
 			Namespace: project.GetNamespace(),
 		},
 	}
-	err := p.KubeClient.Delete(ctx, &run)
-	return client.IgnoreNotFound(err)
+	if err := p.KubeClient.Delete(ctx, &run); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	deleteStepLogs(ctx, p.Store, p.LogStore, &model.StepLog{Resource: stepLogResourcePipeline, Project: project.Name, Entity: meta.PipelineName, Record: meta.PipelineRunName})
+	return nil
 }
 
 // CleanPipelineRuns will clean all pipeline runs, it equals to call ListPipelineRuns and multiple DeletePipelineRun
@@ -984,6 +1050,7 @@ func (p pipelineRunServiceImpl) CleanPipelineRuns(ctx context.Context, base apis
 			return client.IgnoreNotFound(err)
 		}
 	}
+	deleteStepLogs(ctx, p.Store, p.LogStore, &model.StepLog{Resource: stepLogResourcePipeline, Project: project.Name, Entity: base.Name})
 	return nil
 }
 
@@ -1129,6 +1196,7 @@ func pipeline2PipelineBase(wf *model.Pipeline, project model.Project) *apis.Pipe
 			Description: wf.Description,
 			Alias:       wf.Alias,
 			CreateTime:  wf.CreateTime,
+			Ownership:   wf.Ownership,
 		},
 		Spec: wf.Spec,
 	}
@@ -1230,7 +1298,7 @@ func (p pipelineRunServiceImpl) workflowRun2runBriefing(ctx context.Context, run
 		PipelineRunName: run.Name,
 		Finished:        run.Status.Finished,
 		Phase:           run.Status.Phase,
-		Message:         run.Status.Message,
+		Message:         redactor.Redact(run.Status.Message),
 		StartTime:       run.Status.StartTime,
 		EndTime:         run.Status.EndTime,
 	}
@@ -1313,10 +1381,12 @@ func NewTestPipelineRunService(ds datastore.DataStore, c client.Client, cfg *res
 	contextService := NewTestContextService(ds)
 	projectService := NewTestProjectService(ds, c)
 	return &pipelineRunServiceImpl{
+		Store:          ds,
 		KubeClient:     c,
 		KubeConfig:     cfg,
 		ContextService: contextService,
 		ProjectService: projectService,
+		LogStore:       logstore.New(logstore.Config{}),
 	}
 }
 
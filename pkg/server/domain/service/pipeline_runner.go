@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	velatypes "github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/auth"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// pipelineRunnerServiceAccount the service account that runs the pipelines in the namespace of each project
+const pipelineRunnerServiceAccount = "velaux-pipeline-runner"
+
+// ensurePipelineRunner creates the runner service account of the project and grants it the privileges of the namespaces
+// that belong to the project, so a pipeline could not touch the resources of the other projects.
+func ensurePipelineRunner(ctx context.Context, cli client.Client, store datastore.DataStore, project *model.Project) (string, error) {
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+		Name:      pipelineRunnerServiceAccount,
+		Namespace: project.GetNamespace(),
+		Labels:    map[string]string{velatypes.LabelSourceOfTruth: velatypes.FromUX},
+	}}
+	if err := cli.Create(ctx, sa); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", err
+	}
+	pds, err := listProjectPrivileges(ctx, store, project, false)
+	if err != nil {
+		return "", err
+	}
+	identity := &auth.Identity{ServiceAccount: sa.Name, ServiceAccountNamespace: sa.Namespace}
+	writer := &bytes.Buffer{}
+	if err := auth.GrantPrivileges(ctx, cli, pds, identity, writer, auth.WithReplace); err != nil {
+		return "", err
+	}
+	klog.V(4).Infof("GrantPrivileges: %s", writer.String())
+	return sa.Name, nil
+}
+
+// revokePipelineRunner revokes the privileges of the runner service account of the deleted project and deletes it.
+// The project could only be deleted without targets and environments, so only the project namespace is revoked.
+func revokePipelineRunner(ctx context.Context, cli client.Client, project *model.Project) error {
+	pds := []auth.PrivilegeDescription{&auth.ApplicationPrivilege{Cluster: velatypes.ClusterLocalName, Namespace: project.GetNamespace()}}
+	identity := &auth.Identity{ServiceAccount: pipelineRunnerServiceAccount, ServiceAccountNamespace: project.GetNamespace()}
+	writer := &bytes.Buffer{}
+	if err := auth.RevokePrivileges(ctx, cli, pds, identity, writer); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: pipelineRunnerServiceAccount, Namespace: project.GetNamespace()}}
+	return client.IgnoreNotFound(cli.Delete(ctx, sa))
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+var _ = Describe("Test the pipeline runner of the project", func() {
+	It("Test grant and revoke the privileges of the runner", func() {
+		ctx := context.TODO()
+		ds, err := NewDatastore(datastore.Config{Type: "kubeapi", Database: "pipeline-runner-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		project := &model.Project{Name: "runner-project", Namespace: "runner-project-ns"}
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: project.Namespace}})).Should(BeNil())
+
+		runner, err := ensurePipelineRunner(ctx, k8sClient, ds, project)
+		Expect(err).Should(BeNil())
+		Expect(runner).Should(Equal(pipelineRunnerServiceAccount))
+		By("the runner could be ensured repeatedly")
+		_, err = ensurePipelineRunner(ctx, k8sClient, ds, project)
+		Expect(err).Should(BeNil())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: runner, Namespace: project.Namespace}, &corev1.ServiceAccount{})).Should(BeNil())
+		bound := func() bool {
+			var bindings rbacv1.RoleBindingList
+			Expect(k8sClient.List(ctx, &bindings, client.InNamespace(project.Namespace))).Should(BeNil())
+			for _, binding := range bindings.Items {
+				for _, subject := range binding.Subjects {
+					if subject.Kind == rbacv1.ServiceAccountKind && subject.Name == runner && subject.Namespace == project.Namespace {
+						return true
+					}
+				}
+			}
+			return false
+		}
+		Expect(bound()).Should(BeTrue())
+
+		Expect(revokePipelineRunner(ctx, k8sClient, project)).Should(BeNil())
+		Expect(bound()).Should(BeFalse())
+		err = k8sClient.Get(ctx, client.ObjectKey{Name: runner, Namespace: project.Namespace}, &corev1.ServiceAccount{})
+		Expect(client.IgnoreNotFound(err)).Should(BeNil())
+		Expect(err).ShouldNot(BeNil())
+	})
+})
@@ -0,0 +1,203 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubevela/workflow/api/v1alpha1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+const (
+	// maxStepDurationSamples the count of the recent executions to compute the baseline of a step
+	maxStepDurationSamples = 20
+	// minStepDurationSamples the baseline is not used until the step has run enough times
+	minStepDurationSamples = 5
+	// minStepRegression the steps shorter than the baseline plus it are never flagged, so the short steps are not noisy
+	minStepRegression = 10
+	// maxStepRegressions the count of the recent regressions kept for a pipeline
+	maxStepRegressions = 20
+)
+
+// stepRegressionThreshold the percentage that a step could be slower than its baseline, it is set from the server config
+var stepRegressionThreshold = 50
+
+// SyncStepDurations counts the finished pipeline runs into the step baselines and flags the regressed steps
+func (p pipelineServiceImpl) SyncStepDurations(ctx context.Context) error {
+	entities, err := p.Store.List(ctx, &model.Pipeline{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	projects := map[string]*model.Project{}
+	for _, entity := range entities {
+		pipeline := entity.(*model.Pipeline)
+		project, ok := projects[pipeline.Project]
+		if !ok {
+			project = &model.Project{Name: pipeline.Project}
+			if err := p.Store.Get(ctx, project); err != nil {
+				klog.Warningf("failed to get the project of the pipeline %s: %s", pipeline.Name, err.Error())
+				continue
+			}
+			projects[pipeline.Project] = project
+		}
+		if err := p.syncPipelineStepDurations(ctx, project, pipeline); err != nil {
+			klog.Errorf("failed to sync the step durations of the pipeline %s/%s: %s", pipeline.Project, pipeline.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func (p pipelineServiceImpl) syncPipelineStepDurations(ctx context.Context, project *model.Project, pipeline *model.Pipeline) error {
+	var runs v1alpha1.WorkflowRunList
+	if err := p.KubeClient.List(ctx, &runs, client.InNamespace(project.GetNamespace()), client.MatchingLabels{labelPipeline: pipeline.Name}); err != nil {
+		return err
+	}
+	baseline := &model.PipelineStepBaseline{Project: pipeline.Project, PipelineName: pipeline.Name}
+	exist := true
+	if err := p.Store.Get(ctx, baseline); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		exist = false
+	}
+	var finished []v1alpha1.WorkflowRun
+	for _, run := range runs.Items {
+		if run.Status.Finished && run.Status.EndTime.Time.After(baseline.LastRunTime) {
+			finished = append(finished, run)
+		}
+	}
+	if len(finished) == 0 {
+		return nil
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].Status.EndTime.Time.Before(finished[j].Status.EndTime.Time)
+	})
+	var regressions []model.StepRegression
+	for _, run := range finished {
+		regressions = append(regressions, countStepDurations(baseline, run)...)
+		baseline.LastRunTime = run.Status.EndTime.Time
+	}
+	if exist {
+		if err := p.Store.Put(ctx, baseline); err != nil {
+			return err
+		}
+	} else if err := p.Store.Add(ctx, baseline); err != nil {
+		return err
+	}
+	if len(regressions) > 0 {
+		p.notifyStepRegressions(ctx, project, pipeline, regressions)
+	}
+	return nil
+}
+
+// countStepDurations adds the durations of the succeeded steps to the baseline, and returns the regressed steps
+func countStepDurations(baseline *model.PipelineStepBaseline, run v1alpha1.WorkflowRun) []model.StepRegression {
+	if baseline.Durations == nil {
+		baseline.Durations = map[string][]int64{}
+	}
+	var steps []v1alpha1.StepStatus
+	for _, step := range run.Status.Steps {
+		steps = append(steps, step.StepStatus)
+		steps = append(steps, step.SubStepsStatus...)
+	}
+	var regressions []model.StepRegression
+	for _, step := range steps {
+		if step.Phase != v1alpha1.WorkflowStepPhaseSucceeded || step.FirstExecuteTime.IsZero() {
+			continue
+		}
+		duration := int64(step.LastExecuteTime.Sub(step.FirstExecuteTime.Time).Seconds())
+		samples := baseline.Durations[step.Name]
+		if median, ok := medianDuration(samples); ok &&
+			duration-median >= minStepRegression && duration*100 > median*int64(100+stepRegressionThreshold) {
+			regressions = append(regressions, model.StepRegression{
+				RunName:    run.Name,
+				StepName:   step.Name,
+				Duration:   duration,
+				Baseline:   median,
+				DetectTime: time.Now(),
+			})
+		}
+		samples = append(samples, duration)
+		if len(samples) > maxStepDurationSamples {
+			samples = samples[len(samples)-maxStepDurationSamples:]
+		}
+		baseline.Durations[step.Name] = samples
+	}
+	baseline.Regressions = append(baseline.Regressions, regressions...)
+	if len(baseline.Regressions) > maxStepRegressions {
+		baseline.Regressions = baseline.Regressions[len(baseline.Regressions)-maxStepRegressions:]
+	}
+	return regressions
+}
+
+func medianDuration(samples []int64) (int64, bool) {
+	if len(samples) < minStepDurationSamples {
+		return 0, false
+	}
+	sorted := make([]int64, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2], true
+}
+
+func getStepDurationStats(baseline *model.PipelineStepBaseline) []apis.StepDurationStat {
+	var stats []apis.StepDurationStat
+	for name, samples := range baseline.Durations {
+		if len(samples) == 0 {
+			continue
+		}
+		median, _ := medianDuration(samples)
+		stats = append(stats, apis.StepDurationStat{
+			Name:         name,
+			Baseline:     median,
+			LastDuration: samples[len(samples)-1],
+			Samples:      len(samples),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// notifyStepRegressions sends the regressions to the owner of the project by the notification preferences of the owner
+func (p pipelineServiceImpl) notifyStepRegressions(ctx context.Context, project *model.Project, pipeline *model.Pipeline, regressions []model.StepRegression) {
+	var lines []string
+	for _, r := range regressions {
+		lines = append(lines, fmt.Sprintf("- run %s, step %s took %ds, the baseline is %ds", r.RunName, r.StepName, r.Duration, r.Baseline))
+	}
+	klog.Warningf("the steps of the pipeline %s/%s regressed:\n%s", pipeline.Project, pipeline.Name, strings.Join(lines, "\n"))
+	if project.Owner == "" {
+		return
+	}
+	notifyUser(ctx, p.Store, p.SysService, p.EmailSender, p.SlackSender, project.Owner, userNotification{
+		Event:    model.NotificationEventStepRegression,
+		Severity: model.NotificationSeverityWarning,
+		Subject:  fmt.Sprintf("The steps of the pipeline %s regressed", pipeline.Name),
+		Body: fmt.Sprintf("The following steps of the pipeline %s in the project %s are slower than their baselines by more than %d%%:\n\n%s%s\n",
+			pipeline.Name, project.Name, stepRegressionThreshold, strings.Join(lines, "\n"), ownershipContact(pipeline.Ownership)),
+	})
+}
@@ -18,10 +18,13 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/kubevela/workflow/api/v1alpha1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 
@@ -148,3 +151,45 @@ var _ = Describe("Test pipeline service functions", func() {
 		Expect(len(context.Contexts)).Should(Equal(1))
 	})
 })
+
+var _ = Describe("Test the step duration baselines", func() {
+	newRun := func(name string, seconds int64) v1alpha1.WorkflowRun {
+		start := time.Now().Add(-time.Hour)
+		return v1alpha1.WorkflowRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1alpha1.WorkflowRunStatus{
+				Finished: true,
+				EndTime:  metav1.NewTime(start.Add(time.Duration(seconds) * time.Second)),
+				Steps: []v1alpha1.WorkflowStepStatus{{
+					StepStatus: v1alpha1.StepStatus{
+						Name:             "build",
+						Phase:            v1alpha1.WorkflowStepPhaseSucceeded,
+						FirstExecuteTime: metav1.NewTime(start),
+						LastExecuteTime:  metav1.NewTime(start.Add(time.Duration(seconds) * time.Second)),
+					},
+				}},
+			},
+		}
+	}
+
+	It("Test flag the regressed steps", func() {
+		baseline := &model.PipelineStepBaseline{Project: projectName, PipelineName: pipelineName}
+		By("no regression before the baseline has enough samples")
+		Expect(countStepDurations(baseline, newRun("run-0", 600))).Should(BeEmpty())
+		for i := 1; i < minStepDurationSamples; i++ {
+			Expect(countStepDurations(baseline, newRun(fmt.Sprintf("run-%d", i), 60))).Should(BeEmpty())
+		}
+		stats := getStepDurationStats(baseline)
+		Expect(len(stats)).Should(Equal(1))
+		Expect(stats[0].Baseline).Should(Equal(int64(60)))
+
+		By("the step slower than the threshold is flagged")
+		Expect(countStepDurations(baseline, newRun("run-slow", 85))).Should(BeEmpty())
+		regressions := countStepDurations(baseline, newRun("run-regressed", 120))
+		Expect(len(regressions)).Should(Equal(1))
+		Expect(regressions[0].RunName).Should(Equal("run-regressed"))
+		Expect(regressions[0].Baseline).Should(Equal(int64(60)))
+		Expect(len(baseline.Regressions)).Should(Equal(1))
+		Expect(len(baseline.Durations["build"])).Should(Equal(minStepDurationSamples + 2))
+	})
+})
@@ -19,12 +19,16 @@ package service
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	terraformapi "github.com/oam-dev/terraform-controller/api/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -55,8 +59,26 @@ type ProjectService interface {
 	UpdateProjectUser(ctx context.Context, projectName string, userName string, req apisv1.UpdateProjectUserRequest) (*apisv1.ProjectUserBase, error)
 	Init(ctx context.Context) error
 	ListTerraformProviders(ctx context.Context, projectName string) ([]*apisv1.TerraformProvider, error)
+	GetProjectQuotaUsage(ctx context.Context, projectName string) (*apisv1.ProjectQuotaUsageResponse, error)
+	ListProjectMemberEvents(ctx context.Context, projectName string, page, pageSize int) (*apisv1.ListProjectMemberEventsResponse, error)
+	LockProject(ctx context.Context, projectName string, req apisv1.LockProjectRequest) (*apisv1.ProjectBase, error)
+	UnlockProject(ctx context.Context, projectName string) (*apisv1.ProjectBase, error)
+	ListProjectLockEvents(ctx context.Context, projectName string, page, pageSize int) (*apisv1.ListProjectLockEventsResponse, error)
+	ExpireProjectLocks(ctx context.Context) error
+	GetApplicationDefaults(ctx context.Context, projectName string) (*apisv1.ApplicationDefaults, error)
+	UpdateApplicationDefaults(ctx context.Context, projectName string, req apisv1.ApplicationDefaults) (*apisv1.ApplicationDefaults, error)
+	GetRecordRetention(ctx context.Context, projectName string) (*apisv1.WorkflowRecordRetention, error)
+	UpdateRecordRetention(ctx context.Context, projectName string, req apisv1.WorkflowRecordRetention) (*apisv1.WorkflowRecordRetention, error)
 }
 
+// projectQuotaWarningThreshold the percentage of the project quota to warn the users, it is set by the server config
+var projectQuotaWarningThreshold = 80
+
+// projectMemberWebhook the URL to post the project member events to, it is set by the server config
+var projectMemberWebhook string
+
+var projectMemberWebhookClient = &http.Client{Timeout: 10 * time.Second}
+
 type projectServiceImpl struct {
 	Store         datastore.DataStore `inject:"datastore"`
 	K8sClient     client.Client       `inject:"kubeClient"`
@@ -64,6 +86,7 @@ type projectServiceImpl struct {
 	TargetService TargetService       `inject:""`
 	UserService   UserService         `inject:""`
 	EnvService    EnvService          `inject:""`
+	SysService    SystemInfoService   `inject:""`
 }
 
 // NewProjectService new project service
@@ -88,12 +111,17 @@ func (p *projectServiceImpl) InitDefaultProjectEnvTarget(ctx context.Context, de
 		for _, project := range entities {
 			pro := project.(*model.Project)
 			pro.Owner = model.DefaultAdminUserName
-			if err := p.Store.Put(ctx, pro); err != nil {
+			if err := datastore.InTransaction(ctx, p.Store, func(ctx context.Context) error {
+				if err := p.Store.Put(ctx, pro); err != nil {
+					return err
+				}
+				if err := p.RbacService.SyncDefaultRoleAndUsersForProject(ctx, pro); err != nil {
+					return fmt.Errorf("fail to sync the default role and users for the project %s %w", pro.Name, err)
+				}
+				return nil
+			}); err != nil {
 				return err
 			}
-			if err := p.RbacService.SyncDefaultRoleAndUsersForProject(ctx, pro); err != nil {
-				return fmt.Errorf("fail to sync the default role and users for the project %s %w", pro.Name, err)
-			}
 		}
 		return nil
 	}
@@ -240,7 +268,7 @@ func (p *projectServiceImpl) ListProjects(ctx context.Context, page, pageSize in
 
 // DeleteProject delete a project
 func (p *projectServiceImpl) DeleteProject(ctx context.Context, name string) error {
-	_, err := p.GetProject(ctx, name)
+	project, err := p.GetProject(ctx, name)
 	if err != nil {
 		return err
 	}
@@ -277,28 +305,37 @@ func (p *projectServiceImpl) DeleteProject(ctx context.Context, name string) err
 		}
 	}
 
-	roles, _ := p.RbacService.ListRole(ctx, name, 0, 0)
-	for _, role := range roles.Roles {
-		err := p.RbacService.DeleteRole(ctx, name, role.Name)
-		if err != nil {
-			return err
+	// the roles and the permissions are kept if the project is not deleted
+	if err := datastore.InTransaction(ctx, p.Store, func(ctx context.Context) error {
+		roles, _ := p.RbacService.ListRole(ctx, name, 0, 0, apisv1.ListRoleOptions{})
+		for _, role := range roles.Roles {
+			err := p.RbacService.DeleteRole(ctx, name, role.Name)
+			if err != nil {
+				return err
+			}
 		}
-	}
 
-	permissions, _ := p.RbacService.ListPermissions(ctx, name)
-	for _, perm := range permissions {
-		err := p.RbacService.DeletePermission(ctx, name, perm.Name)
+		permissions, err := p.RbacService.ListPermissions(ctx, name, 0, 0, apisv1.ListPermissionOptions{})
 		if err != nil {
 			return err
 		}
-	}
-	if err := p.Store.Delete(ctx, &model.Project{Name: name}); err != nil {
+		for _, perm := range permissions.Permissions {
+			err := p.RbacService.DeletePermission(ctx, name, perm.Name)
+			if err != nil {
+				return err
+			}
+		}
+		return p.Store.Delete(ctx, &model.Project{Name: name})
+	}); err != nil {
 		return err
 	}
 
 	if err := managePrivilegesForProject(ctx, p.K8sClient, &model.Project{Name: name}, true); err != nil {
 		return err
 	}
+	if err := revokePipelineRunner(ctx, p.K8sClient, project); err != nil {
+		klog.Warningf("failed to revoke the pipeline runner of the project %s: %s", name, err.Error())
+	}
 	return nil
 }
 
@@ -340,6 +377,8 @@ func (p *projectServiceImpl) CreateProject(ctx context.Context, req apisv1.Creat
 		Alias:       req.Alias,
 		Owner:       owner,
 		Namespace:   namespace,
+		Labels:      req.Labels,
+		Quota:       convertProjectQuota(req.Quota),
 	}
 
 	if err := p.Store.Add(ctx, newProject); err != nil {
@@ -397,6 +436,12 @@ func (p *projectServiceImpl) UpdateProject(ctx context.Context, projectName stri
 		}
 		project.Owner = req.Owner
 	}
+	if req.Quota != nil {
+		project.Quota = convertProjectQuota(req.Quota)
+	}
+	if req.Labels != nil {
+		project.Labels = req.Labels
+	}
 	err = p.Store.Put(ctx, project)
 	if err != nil {
 		return nil, err
@@ -434,9 +479,10 @@ func (p *projectServiceImpl) ListProjectUser(ctx context.Context, projectName st
 		}
 	}
 	var res apisv1.ListProjectUsersResponse
+	gravatar := useGravatar(ctx, p.SysService)
 	for _, entity := range entities {
 		projectUser := entity.(*model.ProjectUser)
-		res.Users = append(res.Users, ConvertProjectUserModel2Base(projectUser, userMap[projectUser.Username]))
+		res.Users = append(res.Users, ConvertProjectUserModel2Base(projectUser, userMap[projectUser.Username], gravatar))
 	}
 	count, err := p.Store.Count(ctx, &projectUser, nil)
 	if err != nil {
@@ -456,17 +502,8 @@ func (p *projectServiceImpl) AddProjectUser(ctx context.Context, projectName str
 		return nil, err
 	}
 	// check user roles
-	for _, role := range req.UserRoles {
-		var projectUser = model.Role{
-			Name:    role,
-			Project: projectName,
-		}
-		if err := p.Store.Get(ctx, &projectUser); err != nil {
-			return nil, bcode.ErrProjectRoleCheckFailure
-		}
-		if projectUser.Project != "" && projectUser.Project != projectName {
-			return nil, bcode.ErrProjectRoleCheckFailure
-		}
+	if err := checkProjectRoles(ctx, p.Store, project, req.UserRoles); err != nil {
+		return nil, err
 	}
 	var projectUser = model.ProjectUser{
 		Username:    req.UserName,
@@ -479,7 +516,14 @@ func (p *projectServiceImpl) AddProjectUser(ctx context.Context, projectName str
 		}
 		return nil, err
 	}
-	return ConvertProjectUserModel2Base(&projectUser, user), nil
+	p.syncProjectUserPrivileges(ctx, projectName, req.UserName)
+	recordProjectMemberEvent(ctx, p.Store, &model.ProjectMemberEvent{
+		Project:  projectName,
+		Type:     model.ProjectMemberAdded,
+		Username: req.UserName,
+		Roles:    req.UserRoles,
+	})
+	return ConvertProjectUserModel2Base(&projectUser, user, useGravatar(ctx, p.SysService)), nil
 }
 
 func (p *projectServiceImpl) DeleteProjectUser(ctx context.Context, projectName string, userName string) error {
@@ -497,9 +541,22 @@ func (p *projectServiceImpl) DeleteProjectUser(ctx context.Context, projectName
 		}
 		return err
 	}
+	p.syncProjectUserPrivileges(ctx, projectName, userName)
+	recordProjectMemberEvent(ctx, p.Store, &model.ProjectMemberEvent{
+		Project:  projectName,
+		Type:     model.ProjectMemberRemoved,
+		Username: userName,
+	})
 	return nil
 }
 
+// syncProjectUserPrivileges the failure of syncing the Kubernetes RBAC does not block changing the project members
+func (p *projectServiceImpl) syncProjectUserPrivileges(ctx context.Context, projectName, userName string) {
+	if err := p.RbacService.SyncProjectUserPrivileges(ctx, projectName, userName); err != nil {
+		klog.Warningf("failed to sync the kubernetes privileges of the user %s in the project %s: %s", userName, projectName, err.Error())
+	}
+}
+
 func (p *projectServiceImpl) UpdateProjectUser(ctx context.Context, projectName string, userName string, req apisv1.UpdateProjectUserRequest) (*apisv1.ProjectUserBase, error) {
 	project, err := p.GetProject(ctx, projectName)
 	if err != nil {
@@ -510,17 +567,8 @@ func (p *projectServiceImpl) UpdateProjectUser(ctx context.Context, projectName
 		return nil, err
 	}
 	// check user roles
-	for _, role := range req.UserRoles {
-		var projectUser = model.Role{
-			Name:    role,
-			Project: projectName,
-		}
-		if err := p.Store.Get(ctx, &projectUser); err != nil {
-			return nil, bcode.ErrProjectRoleCheckFailure
-		}
-		if projectUser.Project != "" && projectUser.Project != projectName {
-			return nil, bcode.ErrProjectRoleCheckFailure
-		}
+	if err := checkProjectRoles(ctx, p.Store, project, req.UserRoles); err != nil {
+		return nil, err
 	}
 	var projectUser = model.ProjectUser{
 		Username:    userName,
@@ -536,7 +584,71 @@ func (p *projectServiceImpl) UpdateProjectUser(ctx context.Context, projectName
 	if err := p.Store.Put(ctx, &projectUser); err != nil {
 		return nil, err
 	}
-	return ConvertProjectUserModel2Base(&projectUser, user), nil
+	p.syncProjectUserPrivileges(ctx, projectName, userName)
+	recordProjectMemberEvent(ctx, p.Store, &model.ProjectMemberEvent{
+		Project:  projectName,
+		Type:     model.ProjectMemberUpdated,
+		Username: userName,
+		Roles:    req.UserRoles,
+	})
+	return ConvertProjectUserModel2Base(&projectUser, user, useGravatar(ctx, p.SysService)), nil
+}
+
+func (p *projectServiceImpl) ListProjectMemberEvents(ctx context.Context, projectName string, page, pageSize int) (*apisv1.ListProjectMemberEventsResponse, error) {
+	project, err := p.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	var event = model.ProjectMemberEvent{Project: project.Name}
+	entities, err := p.Store.List(ctx, &event, &datastore.ListOptions{Page: page, PageSize: pageSize, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil {
+		return nil, err
+	}
+	var res = apisv1.ListProjectMemberEventsResponse{Events: []*apisv1.ProjectMemberEventBase{}}
+	for _, entity := range entities {
+		res.Events = append(res.Events, ConvertProjectMemberEvent2Base(entity.(*model.ProjectMemberEvent)))
+	}
+	count, err := p.Store.Count(ctx, &event, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Total = count
+	return &res, nil
+}
+
+// recordProjectMemberEvent saves the event and posts it to the project member webhook if it is configured.
+// The failure is only logged because the change of the members has taken effect.
+func recordProjectMemberEvent(ctx context.Context, store datastore.DataStore, event *model.ProjectMemberEvent) {
+	event.Name = apiutils.GenerateVersion(event.Project) + "-" + rand.String(4)
+	event.Operator, _ = ctx.Value(&apisv1.CtxKeyUser).(string)
+	if err := store.Add(ctx, event); err != nil {
+		klog.Warningf("failed to save the member event of the project %s: %s", event.Project, err.Error())
+		return
+	}
+	klog.Infof("project member event: project=%s type=%s user=%s roles=%v operator=%s", event.Project, event.Type, event.Username, event.Roles, event.Operator)
+	if projectMemberWebhook == "" {
+		return
+	}
+	go postProjectMemberEvent(ConvertProjectMemberEvent2Base(event))
+}
+
+func postProjectMemberEvent(event *apisv1.ProjectMemberEventBase) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		klog.Errorf("failed to marshal the project member event %s: %s", event.Name, err.Error())
+		return
+	}
+	resp, err := projectMemberWebhookClient.Post(projectMemberWebhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		klog.Errorf("failed to post the project member event %s to the webhook: %s", event.Name, err.Error())
+		return
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode >= http.StatusBadRequest {
+		klog.Errorf("failed to post the project member event %s to the webhook, the status code is %d", event.Name, resp.StatusCode)
+	}
 }
 
 func (p *projectServiceImpl) ListTerraformProviders(ctx context.Context, projectName string) ([]*apisv1.TerraformProvider, error) {
@@ -560,6 +672,81 @@ func (p *projectServiceImpl) ListTerraformProviders(ctx context.Context, project
 	return res, nil
 }
 
+func (p *projectServiceImpl) GetProjectQuotaUsage(ctx context.Context, projectName string) (*apisv1.ProjectQuotaUsageResponse, error) {
+	project, err := p.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	return getProjectQuotaUsage(ctx, p.Store, project)
+}
+
+// getProjectQuotaUsage counts the resources of the project and compares with the quota
+func getProjectQuotaUsage(ctx context.Context, store datastore.DataStore, project *model.Project) (*apisv1.ProjectQuotaUsageResponse, error) {
+	var quota model.ProjectQuota
+	if project.Quota != nil {
+		quota = *project.Quota
+	}
+	resources := []struct {
+		name   string
+		entity datastore.Entity
+		limit  int64
+	}{
+		{name: "application", entity: &model.Application{Project: project.Name}, limit: quota.Applications},
+		{name: "pipeline", entity: &model.Pipeline{Project: project.Name}, limit: quota.Pipelines},
+		{name: "target", entity: &model.Target{Project: project.Name}, limit: quota.Targets},
+		{name: "serviceInstance", entity: &model.ServiceInstance{Project: project.Name}, limit: quota.ServiceInstances},
+	}
+	res := &apisv1.ProjectQuotaUsageResponse{WarningThreshold: projectQuotaWarningThreshold}
+	for _, resource := range resources {
+		used, err := store.Count(ctx, resource.entity, nil)
+		if err != nil {
+			return nil, err
+		}
+		res.Usages = append(res.Usages, apisv1.ProjectResourceUsage{
+			Resource: resource.name,
+			Used:     used,
+			Limit:    resource.limit,
+			Warning:  resource.limit > 0 && used*100 >= resource.limit*int64(projectQuotaWarningThreshold),
+		})
+	}
+	return res, nil
+}
+
+// warnProjectQuotaUsage emits the warnings if the resource usage of the project reaches the threshold of the quota.
+// The quota is soft, so the failure of the checking never blocks the request.
+func warnProjectQuotaUsage(ctx context.Context, store datastore.DataStore, projectName string) {
+	project := &model.Project{Name: projectName}
+	if err := store.Get(ctx, project); err != nil {
+		klog.Warningf("failed to get the project %s to check the quota usage: %s", projectName, err.Error())
+		return
+	}
+	if project.Quota == nil {
+		return
+	}
+	usage, err := getProjectQuotaUsage(ctx, store, project)
+	if err != nil {
+		klog.Warningf("failed to check the quota usage of the project %s: %s", projectName, err.Error())
+		return
+	}
+	for _, u := range usage.Usages {
+		if u.Warning {
+			klog.Warningf("the %s usage of the project %s reaches the quota warning threshold: %d/%d", u.Resource, projectName, u.Used, u.Limit)
+		}
+	}
+}
+
+func convertProjectQuota(quota *apisv1.ProjectQuota) *model.ProjectQuota {
+	if quota == nil {
+		return nil
+	}
+	return &model.ProjectQuota{
+		Applications:     quota.Applications,
+		Pipelines:        quota.Pipelines,
+		Targets:          quota.Targets,
+		ServiceInstances: quota.ServiceInstances,
+	}
+}
+
 // ConvertProjectModel2Base convert project model to base struct
 func ConvertProjectModel2Base(project *model.Project, owner *model.User) *apisv1.ProjectBase {
 	base := &apisv1.ProjectBase{
@@ -570,6 +757,18 @@ func ConvertProjectModel2Base(project *model.Project, owner *model.User) *apisv1
 		UpdateTime:  project.UpdateTime,
 		Owner:       apisv1.NameAlias{Name: project.Owner},
 		Namespace:   project.GetNamespace(),
+		Labels:      project.Labels,
+	}
+	if project.Quota != nil {
+		base.Quota = &apisv1.ProjectQuota{
+			Applications:     project.Quota.Applications,
+			Pipelines:        project.Quota.Pipelines,
+			Targets:          project.Quota.Targets,
+			ServiceInstances: project.Quota.ServiceInstances,
+		}
+	}
+	if project.IsLocked(time.Now()) {
+		base.Lock = project.Lock
 	}
 	if owner != nil && owner.Name == project.Owner {
 		base.Owner = apisv1.NameAlias{Name: owner.Name, Alias: owner.Alias}
@@ -577,8 +776,22 @@ func ConvertProjectModel2Base(project *model.Project, owner *model.User) *apisv1
 	return base
 }
 
+// ConvertProjectMemberEvent2Base convert the project member event model to base struct
+func ConvertProjectMemberEvent2Base(event *model.ProjectMemberEvent) *apisv1.ProjectMemberEventBase {
+	return &apisv1.ProjectMemberEventBase{
+		Name:        event.Name,
+		Project:     event.Project,
+		Type:        event.Type,
+		Username:    event.Username,
+		Roles:       event.Roles,
+		Permissions: event.Permissions,
+		Operator:    event.Operator,
+		CreateTime:  event.CreateTime,
+	}
+}
+
 // ConvertProjectUserModel2Base convert project user model to base struct
-func ConvertProjectUserModel2Base(user *model.ProjectUser, userModel *model.User) *apisv1.ProjectUserBase {
+func ConvertProjectUserModel2Base(user *model.ProjectUser, userModel *model.User, gravatar bool) *apisv1.ProjectUserBase {
 	base := &apisv1.ProjectUserBase{
 		UserName:   user.Username,
 		UserRoles:  user.UserRoles,
@@ -587,6 +800,7 @@ func ConvertProjectUserModel2Base(user *model.ProjectUser, userModel *model.User
 	}
 	if userModel != nil {
 		base.UserAlias = userModel.Alias
+		base.AvatarURL = userAvatarURL(userModel, gravatar)
 	}
 	return base
 }
@@ -609,3 +823,28 @@ func NewTestProjectService(ds datastore.DataStore, c client.Client) ProjectServi
 	envImpl.ProjectService = projectService
 	return projectService
 }
+
+// checkProjectRoles checks that the roles are the project roles or the cross-project roles could be bound in the project
+func checkProjectRoles(ctx context.Context, store datastore.DataStore, project *model.Project, roles []string) error {
+	crossProjectRoles, err := listCrossProjectRoles(ctx, store, project, roles)
+	if err != nil {
+		return err
+	}
+	var crossProjectRoleNames = make(map[string]bool, len(crossProjectRoles))
+	for _, role := range crossProjectRoles {
+		crossProjectRoleNames[role.Name] = true
+	}
+	for _, role := range roles {
+		if crossProjectRoleNames[role] {
+			continue
+		}
+		var projectRole = model.Role{
+			Name:    role,
+			Project: project.Name,
+		}
+		if err := store.Get(ctx, &projectRole); err != nil {
+			return bcode.ErrProjectRoleCheckFailure
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// defaultEnvTraitType the trait to set the environment variables of the main component
+	defaultEnvTraitType = "env"
+	// defaultAnnotationsTraitType the trait to set the annotations of the main component
+	defaultAnnotationsTraitType = "annotations"
+	// defaultsTraitDescription the description of the traits added by the defaults
+	defaultsTraitDescription = "Added by the application defaults of the project."
+)
+
+// GetApplicationDefaults gets the defaults applied to the new applications of the project
+func (p *projectServiceImpl) GetApplicationDefaults(ctx context.Context, projectName string) (*apisv1.ApplicationDefaults, error) {
+	project, err := p.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	return convertApplicationDefaults2DTO(project.ApplicationDefaults), nil
+}
+
+// UpdateApplicationDefaults replaces the defaults applied to the new applications of the project, the existing applications are not changed
+func (p *projectServiceImpl) UpdateApplicationDefaults(ctx context.Context, projectName string, req apisv1.ApplicationDefaults) (*apisv1.ApplicationDefaults, error) {
+	project, err := p.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	defaults := &model.ApplicationDefaults{
+		Labels:      req.Labels,
+		Annotations: req.Annotations,
+		Env:         req.Env,
+	}
+	var traitTypes = make(map[string]bool)
+	for _, trait := range req.Traits {
+		if traitTypes[trait.Type] {
+			return nil, bcode.ErrTraitAlreadyExist
+		}
+		// the env and annotations traits are generated from the env variables and the annotations
+		if (trait.Type == defaultEnvTraitType && len(req.Env) > 0) || (trait.Type == defaultAnnotationsTraitType && len(req.Annotations) > 0) {
+			return nil, bcode.ErrTraitAlreadyExist
+		}
+		traitTypes[trait.Type] = true
+		defaults.Traits = append(defaults.Traits, model.ApplicationDefaultTrait{Type: trait.Type, Properties: trait.Properties})
+	}
+	if len(defaults.Labels) == 0 && len(defaults.Annotations) == 0 && len(defaults.Env) == 0 && len(defaults.Traits) == 0 {
+		defaults = nil
+	}
+	project.ApplicationDefaults = defaults
+	if err := p.Store.Put(ctx, project); err != nil {
+		return nil, err
+	}
+	return convertApplicationDefaults2DTO(defaults), nil
+}
+
+func convertApplicationDefaults2DTO(defaults *model.ApplicationDefaults) *apisv1.ApplicationDefaults {
+	if defaults == nil {
+		return &apisv1.ApplicationDefaults{}
+	}
+	res := &apisv1.ApplicationDefaults{
+		Labels:      defaults.Labels,
+		Annotations: defaults.Annotations,
+		Env:         defaults.Env,
+	}
+	for _, trait := range defaults.Traits {
+		res.Traits = append(res.Traits, apisv1.ApplicationDefaultTrait{Type: trait.Type, Properties: trait.Properties})
+	}
+	return res
+}
+
+// mergeDefaultLabels merges the default labels of the project into the labels of the new application
+func mergeDefaultLabels(defaults *model.ApplicationDefaults, labels map[string]string) map[string]string {
+	if defaults == nil || len(defaults.Labels) == 0 {
+		return labels
+	}
+	merged := make(map[string]string, len(defaults.Labels)+len(labels))
+	for k, v := range defaults.Labels {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return merged
+}
+
+// applyComponentDefaults adds the default traits, annotations and env variables of the project to the main component,
+// the traits and the keys set by the user are kept.
+func applyComponentDefaults(defaults *model.ApplicationDefaults, component *model.ApplicationComponent) {
+	if defaults == nil {
+		return
+	}
+	now := time.Now()
+	findTrait := func(traitType string) *model.ApplicationTrait {
+		for i := range component.Traits {
+			if component.Traits[i].Type == traitType {
+				return &component.Traits[i]
+			}
+		}
+		return nil
+	}
+	// ensureTrait returns the trait of the type, it is added if not exist
+	ensureTrait := func(traitType string) *model.ApplicationTrait {
+		if trait := findTrait(traitType); trait != nil {
+			if trait.Properties == nil {
+				trait.Properties = &model.JSONStruct{}
+			}
+			return trait
+		}
+		component.Traits = append(component.Traits, model.ApplicationTrait{
+			Type:        traitType,
+			Description: defaultsTraitDescription,
+			Properties:  &model.JSONStruct{},
+			CreateTime:  now,
+			UpdateTime:  now,
+		})
+		return &component.Traits[len(component.Traits)-1]
+	}
+	for _, trait := range defaults.Traits {
+		if findTrait(trait.Type) != nil {
+			continue
+		}
+		var properties *model.JSONStruct
+		if trait.Properties != nil {
+			copied := make(model.JSONStruct, len(*trait.Properties))
+			for k, v := range *trait.Properties {
+				copied[k] = v
+			}
+			properties = &copied
+		}
+		component.Traits = append(component.Traits, model.ApplicationTrait{
+			Type:        trait.Type,
+			Description: defaultsTraitDescription,
+			Properties:  properties,
+			CreateTime:  now,
+			UpdateTime:  now,
+		})
+	}
+	if len(defaults.Annotations) > 0 {
+		trait := ensureTrait(defaultAnnotationsTraitType)
+		for k, v := range defaults.Annotations {
+			if _, exist := (*trait.Properties)[k]; !exist {
+				(*trait.Properties)[k] = v
+			}
+		}
+	}
+	if len(defaults.Env) > 0 {
+		trait := ensureTrait(defaultEnvTraitType)
+		env, _ := (*trait.Properties)["env"].(map[string]interface{})
+		if env == nil {
+			env = make(map[string]interface{}, len(defaults.Env))
+		}
+		for k, v := range defaults.Env {
+			if _, exist := env[k]; !exist {
+				env[k] = v
+			}
+		}
+		(*trait.Properties)["env"] = env
+	}
+}
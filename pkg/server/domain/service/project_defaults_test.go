@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the application defaults of the project", func() {
+	var (
+		ds             datastore.DataStore
+		projectService *projectServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "project-defaults-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		projectService = &projectServiceImpl{Store: ds}
+	})
+
+	It("Test update and get the application defaults", func() {
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Project{Name: "defaults-project"})).Should(BeNil())
+
+		defaults, err := projectService.GetApplicationDefaults(ctx, "defaults-project")
+		Expect(err).Should(BeNil())
+		Expect(defaults.Traits).Should(BeEmpty())
+
+		_, err = projectService.UpdateApplicationDefaults(ctx, "defaults-project", apisv1.ApplicationDefaults{
+			Traits: []apisv1.ApplicationDefaultTrait{{Type: "scaler"}, {Type: "scaler"}},
+		})
+		Expect(err).Should(Equal(bcode.ErrTraitAlreadyExist))
+		_, err = projectService.UpdateApplicationDefaults(ctx, "defaults-project", apisv1.ApplicationDefaults{
+			Env:    map[string]string{"LOG_LEVEL": "info"},
+			Traits: []apisv1.ApplicationDefaultTrait{{Type: "env"}},
+		})
+		Expect(err).Should(Equal(bcode.ErrTraitAlreadyExist))
+
+		_, err = projectService.UpdateApplicationDefaults(ctx, "defaults-project", apisv1.ApplicationDefaults{
+			Labels: map[string]string{"team": "payments"},
+			Env:    map[string]string{"LOG_LEVEL": "info"},
+			Traits: []apisv1.ApplicationDefaultTrait{{Type: "gateway", Properties: &model.JSONStruct{"domain": "pay.example.com"}}},
+		})
+		Expect(err).Should(BeNil())
+		defaults, err = projectService.GetApplicationDefaults(ctx, "defaults-project")
+		Expect(err).Should(BeNil())
+		Expect(defaults.Labels).Should(Equal(map[string]string{"team": "payments"}))
+		Expect(defaults.Env).Should(Equal(map[string]string{"LOG_LEVEL": "info"}))
+		Expect(len(defaults.Traits)).Should(Equal(1))
+
+		By("the empty defaults are removed")
+		_, err = projectService.UpdateApplicationDefaults(ctx, "defaults-project", apisv1.ApplicationDefaults{})
+		Expect(err).Should(BeNil())
+		project, err := projectService.GetProject(ctx, "defaults-project")
+		Expect(err).Should(BeNil())
+		Expect(project.ApplicationDefaults).Should(BeNil())
+	})
+
+	It("Test apply the defaults to the new application", func() {
+		defaults := &model.ApplicationDefaults{
+			Labels:      map[string]string{"team": "payments", "tier": "backend"},
+			Annotations: map[string]string{"owner": "payments"},
+			Env:         map[string]string{"LOG_LEVEL": "info", "REGION": "eu"},
+			Traits:      []model.ApplicationDefaultTrait{{Type: "scaler", Properties: &model.JSONStruct{"replicas": 3}}, {Type: "gateway", Properties: &model.JSONStruct{"domain": "pay.example.com"}}},
+		}
+		Expect(mergeDefaultLabels(defaults, map[string]string{"tier": "frontend"})).Should(Equal(map[string]string{"team": "payments", "tier": "frontend"}))
+		Expect(mergeDefaultLabels(nil, nil)).Should(BeNil())
+
+		component := &model.ApplicationComponent{Traits: []model.ApplicationTrait{
+			{Type: "scaler", Properties: &model.JSONStruct{"replicas": 1}},
+			{Type: "env", Properties: &model.JSONStruct{"env": map[string]interface{}{"LOG_LEVEL": "debug"}}},
+		}}
+		applyComponentDefaults(defaults, component)
+		traits := map[string]model.ApplicationTrait{}
+		for _, trait := range component.Traits {
+			Expect(traits).ShouldNot(HaveKey(trait.Type))
+			traits[trait.Type] = trait
+		}
+		Expect(traits).Should(HaveLen(4))
+		Expect((*traits["scaler"].Properties)["replicas"]).Should(Equal(1))
+		Expect((*traits["env"].Properties)["env"]).Should(Equal(map[string]interface{}{"LOG_LEVEL": "debug", "REGION": "eu"}))
+		Expect((*traits["annotations"].Properties)["owner"]).Should(Equal("payments"))
+
+		By("the properties of the default traits are copied")
+		(*traits["gateway"].Properties)["domain"] = "changed.example.com"
+		Expect((*defaults.Traits[1].Properties)["domain"]).Should(Equal("pay.example.com"))
+	})
+})
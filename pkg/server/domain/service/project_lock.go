@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	apiutils "github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// projectLockWriteActions the actions rejected in the locked projects, they deploy or change the spec of the resources
+var projectLockWriteActions = []string{"create", "update", "delete", "deploy", "rollback", "resume", "run", "reset", "recycle", "scale"}
+
+// LockProject locks the project, the existing lock is replaced
+func (p *projectServiceImpl) LockProject(ctx context.Context, projectName string, req apisv1.LockProjectRequest) (*apisv1.ProjectBase, error) {
+	project, err := p.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	operator, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	now := time.Now()
+	project.Lock = &model.ProjectLock{
+		Reason:     req.Reason,
+		Operator:   operator,
+		LockTime:   now,
+		ExpireTime: now.Add(time.Duration(req.DurationMinutes) * time.Minute),
+	}
+	if err := p.Store.Put(ctx, project); err != nil {
+		return nil, err
+	}
+	recordProjectLockEvent(ctx, p.Store, &model.ProjectLockEvent{
+		Project:    project.Name,
+		Type:       model.ProjectLocked,
+		Reason:     req.Reason,
+		ExpireTime: project.Lock.ExpireTime,
+		Operator:   operator,
+	})
+	return ConvertProjectModel2Base(project, nil), nil
+}
+
+// UnlockProject removes the lock of the project before it expires
+func (p *projectServiceImpl) UnlockProject(ctx context.Context, projectName string) (*apisv1.ProjectBase, error) {
+	project, err := p.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	if !project.IsLocked(time.Now()) {
+		return nil, bcode.ErrProjectNotLocked
+	}
+	reason := project.Lock.Reason
+	project.Lock = nil
+	if err := p.Store.Put(ctx, project); err != nil {
+		return nil, err
+	}
+	operator, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	recordProjectLockEvent(ctx, p.Store, &model.ProjectLockEvent{
+		Project:  project.Name,
+		Type:     model.ProjectUnlocked,
+		Reason:   reason,
+		Operator: operator,
+	})
+	return ConvertProjectModel2Base(project, nil), nil
+}
+
+// ExpireProjectLocks clears the expired locks and records the expiry, the expired locks
+// do not block the requests even before they are cleared
+func (p *projectServiceImpl) ExpireProjectLocks(ctx context.Context) error {
+	projects, err := p.Store.List(ctx, &model.Project{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entity := range projects {
+		project := entity.(*model.Project)
+		if project.Lock == nil || project.IsLocked(now) {
+			continue
+		}
+		lock := project.Lock
+		project.Lock = nil
+		if err := p.Store.Put(ctx, project); err != nil {
+			klog.Errorf("failed to clear the expired lock of the project %s: %s", project.Name, err.Error())
+			continue
+		}
+		recordProjectLockEvent(ctx, p.Store, &model.ProjectLockEvent{
+			Project:    project.Name,
+			Type:       model.ProjectLockExpired,
+			Reason:     lock.Reason,
+			ExpireTime: lock.ExpireTime,
+		})
+	}
+	return nil
+}
+
+// ListProjectLockEvents lists the lock events of the project, the latest first
+func (p *projectServiceImpl) ListProjectLockEvents(ctx context.Context, projectName string, page, pageSize int) (*apisv1.ListProjectLockEventsResponse, error) {
+	project, err := p.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	var event = model.ProjectLockEvent{Project: project.Name}
+	entities, err := p.Store.List(ctx, &event, &datastore.ListOptions{Page: page, PageSize: pageSize, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil {
+		return nil, err
+	}
+	var res = apisv1.ListProjectLockEventsResponse{Events: []*apisv1.ProjectLockEventBase{}}
+	for _, entity := range entities {
+		e := entity.(*model.ProjectLockEvent)
+		res.Events = append(res.Events, &apisv1.ProjectLockEventBase{
+			Name:       e.Name,
+			Project:    e.Project,
+			Type:       e.Type,
+			Reason:     e.Reason,
+			ExpireTime: e.ExpireTime,
+			Operator:   e.Operator,
+			CreateTime: e.CreateTime,
+		})
+	}
+	count, err := p.Store.Count(ctx, &event, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Total = count
+	return &res, nil
+}
+
+// recordProjectLockEvent saves the event, the failure is only logged because the lock has taken effect
+func recordProjectLockEvent(ctx context.Context, store datastore.DataStore, event *model.ProjectLockEvent) {
+	event.Name = apiutils.GenerateVersion(event.Project) + "-" + rand.String(4)
+	if err := store.Add(ctx, event); err != nil {
+		klog.Warningf("failed to save the lock event of the project %s: %s", event.Project, err.Error())
+		return
+	}
+	klog.Infof("project lock event: project=%s type=%s reason=%q operator=%s", event.Project, event.Type, event.Reason, event.Operator)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the project locks", func() {
+	var (
+		ds             datastore.DataStore
+		projectService *projectServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "project-lock-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		projectService = &projectServiceImpl{Store: ds}
+	})
+
+	It("Test lock, unlock and expire the project lock", func() {
+		ctx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "incident-commander")
+		Expect(ds.Add(ctx, &model.Project{Name: "frozen-project"})).Should(BeNil())
+
+		_, err := projectService.UnlockProject(ctx, "frozen-project")
+		Expect(err).Should(Equal(bcode.ErrProjectNotLocked))
+
+		base, err := projectService.LockProject(ctx, "frozen-project", apisv1.LockProjectRequest{Reason: "INC-42", DurationMinutes: 30})
+		Expect(err).Should(BeNil())
+		Expect(base.Lock.Operator).Should(Equal("incident-commander"))
+		Expect(base.Lock.ExpireTime.Sub(base.Lock.LockTime)).Should(Equal(30 * time.Minute))
+
+		base, err = projectService.UnlockProject(ctx, "frozen-project")
+		Expect(err).Should(BeNil())
+		Expect(base.Lock).Should(BeNil())
+
+		By("the expired lock is cleared by the worker")
+		project := &model.Project{Name: "frozen-project"}
+		Expect(ds.Get(ctx, project)).Should(BeNil())
+		project.Lock = &model.ProjectLock{Reason: "INC-43", LockTime: time.Now().Add(-2 * time.Hour), ExpireTime: time.Now().Add(-time.Hour)}
+		Expect(ds.Put(ctx, project)).Should(BeNil())
+		Expect(project.IsLocked(time.Now())).Should(BeFalse())
+		Expect(projectService.ExpireProjectLocks(ctx)).Should(BeNil())
+		Expect(ds.Get(ctx, project)).Should(BeNil())
+		Expect(project.Lock).Should(BeNil())
+
+		events, err := projectService.ListProjectLockEvents(ctx, "frozen-project", 0, 0)
+		Expect(err).Should(BeNil())
+		Expect(events.Total).Should(Equal(int64(3)))
+		var types []string
+		for _, e := range events.Events {
+			types = append(types, e.Type)
+		}
+		Expect(types).Should(ConsistOf(model.ProjectLocked, model.ProjectUnlocked, model.ProjectLockExpired))
+	})
+})
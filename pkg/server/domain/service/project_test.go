@@ -18,6 +18,9 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -201,6 +204,57 @@ var _ = Describe("Test project service functions", func() {
 		Expect(err).Should(BeNil())
 	})
 
+	It("Test project quota usage function", func() {
+		req := apisv1.CreateProjectRequest{
+			Name:  "test-project",
+			Quota: &apisv1.ProjectQuota{Pipelines: 2, Targets: 10},
+		}
+		base, err := projectService.CreateProject(context.TODO(), req)
+		Expect(err).Should(BeNil())
+		Expect(base.Quota.Pipelines).Should(Equal(int64(2)))
+
+		err = projectService.Store.Add(context.TODO(), &model.Pipeline{Name: "test-quota-pipeline", Project: "test-project"})
+		Expect(err).Should(BeNil())
+		usage, err := projectService.GetProjectQuotaUsage(context.TODO(), "test-project")
+		Expect(err).Should(BeNil())
+		Expect(usage.WarningThreshold).Should(Equal(80))
+		Expect(len(usage.Usages)).Should(Equal(4))
+		for _, u := range usage.Usages {
+			switch u.Resource {
+			case "application":
+				Expect(u.Limit).Should(Equal(int64(0)))
+				Expect(u.Warning).Should(BeFalse())
+			case "pipeline":
+				Expect(u.Used).Should(Equal(int64(1)))
+				Expect(u.Warning).Should(BeFalse())
+			case "target":
+				Expect(u.Limit).Should(Equal(int64(10)))
+				Expect(u.Warning).Should(BeFalse())
+			}
+		}
+
+		err = projectService.Store.Add(context.TODO(), &model.Pipeline{Name: "test-quota-pipeline-2", Project: "test-project"})
+		Expect(err).Should(BeNil())
+		usage, err = projectService.GetProjectQuotaUsage(context.TODO(), "test-project")
+		Expect(err).Should(BeNil())
+		Expect(usage.Usages[1].Resource).Should(Equal("pipeline"))
+		Expect(usage.Usages[1].Used).Should(Equal(int64(2)))
+		Expect(usage.Usages[1].Warning).Should(BeTrue())
+
+		_, err = projectService.UpdateProject(context.TODO(), "test-project", apisv1.UpdateProjectRequest{
+			Quota: &apisv1.ProjectQuota{},
+		})
+		Expect(err).Should(BeNil())
+		usage, err = projectService.GetProjectQuotaUsage(context.TODO(), "test-project")
+		Expect(err).Should(BeNil())
+		Expect(usage.Usages[1].Warning).Should(BeFalse())
+
+		Expect(projectService.Store.Delete(context.TODO(), &model.Pipeline{Name: "test-quota-pipeline", Project: "test-project"})).Should(BeNil())
+		Expect(projectService.Store.Delete(context.TODO(), &model.Pipeline{Name: "test-quota-pipeline-2", Project: "test-project"})).Should(BeNil())
+		err = projectService.DeleteProject(context.TODO(), "test-project")
+		Expect(err).Should(BeNil())
+	})
+
 	It("Test Create project user function", func() {
 		req := apisv1.CreateProjectRequest{
 			Name:        "test-project",
@@ -244,6 +298,39 @@ var _ = Describe("Test project service functions", func() {
 			UserRoles: []string{"project-admin", "app-developer", "xxx"},
 		})
 		Expect(err).Should(BeEquivalentTo(bcode.ErrProjectRoleCheckFailure))
+
+		events, err := projectService.ListProjectMemberEvents(context.TODO(), "test-project", 0, 0)
+		Expect(err).Should(BeNil())
+		Expect(events.Total).Should(BeEquivalentTo(2))
+		Expect(events.Events[0].Type).Should(Equal(model.ProjectMemberUpdated))
+		Expect(events.Events[0].Roles).Should(Equal([]string{"project-admin", "app-developer"}))
+		Expect(events.Events[1].Type).Should(Equal(model.ProjectMemberAdded))
+	})
+
+	It("Test post the project member events to the webhook", func() {
+		received := make(chan apisv1.ProjectMemberEventBase, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var event apisv1.ProjectMemberEventBase
+			Expect(json.NewDecoder(r.Body).Decode(&event)).Should(BeNil())
+			received <- event
+		}))
+		defer server.Close()
+		projectMemberWebhook = server.URL
+		defer func() {
+			projectMemberWebhook = ""
+		}()
+
+		_, err := projectService.CreateProject(context.TODO(), apisv1.CreateProjectRequest{Name: "test-project"})
+		Expect(err).Should(BeNil())
+		err = projectService.Store.Add(context.TODO(), &model.ProjectUser{ProjectName: "test-project", Username: "admin"})
+		Expect(err).Should(BeNil())
+		err = projectService.DeleteProjectUser(context.TODO(), "test-project", "admin")
+		Expect(err).Should(BeNil())
+		var event apisv1.ProjectMemberEventBase
+		Eventually(received, time.Second*5).Should(Receive(&event))
+		Expect(event.Project).Should(Equal("test-project"))
+		Expect(event.Type).Should(Equal(model.ProjectMemberRemoved))
+		Expect(event.Username).Should(Equal("admin"))
 	})
 
 	It("Test delete project user and delete project function", func() {
@@ -264,10 +351,10 @@ var _ = Describe("Test project service functions", func() {
 		Expect(err).Should(BeNil())
 		err = projectService.DeleteProject(context.TODO(), "test-project")
 		Expect(err).Should(BeNil())
-		perms, err := projectService.RbacService.ListPermissions(context.TODO(), "test-project")
+		perms, err := projectService.RbacService.ListPermissions(context.TODO(), "test-project", 0, 0, apisv1.ListPermissionOptions{})
 		Expect(err).Should(BeNil())
-		Expect(len(perms)).Should(BeEquivalentTo(0))
-		roles, err := projectService.RbacService.ListRole(context.TODO(), "test-project", 0, 0)
+		Expect(len(perms.Permissions)).Should(BeEquivalentTo(0))
+		roles, err := projectService.RbacService.ListRole(context.TODO(), "test-project", 0, 0, apisv1.ListRoleOptions{})
 		Expect(err).Should(BeNil())
 		Expect(roles.Total).Should(BeEquivalentTo(0))
 	})
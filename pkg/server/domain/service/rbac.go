@@ -19,13 +19,20 @@ package service
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/emicklei/go-restful/v3"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -35,6 +42,7 @@ import (
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/changefeed"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 	assembler "github.com/kubevela/velaux/pkg/server/interfaces/api/assembler/v1"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
@@ -47,6 +55,10 @@ var resourceActions map[string][]string
 var lock sync.Mutex
 var reg = regexp.MustCompile(`(?U)\{.*\}`)
 
+// labelSelectorPrefix the prefix of the resource value that selects the resources by labels,
+// such as project:x/application:[label:team=payments]
+const labelSelectorPrefix = "[label:"
+
 var defaultProjectPermissionTemplate = []*model.PermissionTemplate{
 	{
 		Name:  "project-view",
@@ -191,6 +203,70 @@ var defaultPlatformPermission = []*model.PermissionTemplate{
 		Effect:    "Allow",
 		Scope:     "platform",
 	},
+	rbacApproverPermission,
+	maintenanceOverridePermission,
+}
+
+// rbacApproverPermission allows the user to approve the sensitive changes of the platform roles and permissions
+var rbacApproverPermission = &model.PermissionTemplate{
+	Name:      "rbac-approver",
+	Alias:     "RBAC Approver",
+	Resources: []string{"rbacApproval:*"},
+	Actions:   []string{"list", "approve", "reject"},
+	Effect:    "Allow",
+	Scope:     "platform",
+}
+
+// maintenanceOverridePermission allows the user to change the resources while the platform is in the maintenance mode
+var maintenanceOverridePermission = &model.PermissionTemplate{
+	Name:      "maintenance-override",
+	Alias:     "Maintenance Override",
+	Resources: []string{"maintenance"},
+	Actions:   []string{"override"},
+	Effect:    "Allow",
+	Scope:     "platform",
+}
+
+// maintenanceReadActions the actions allowed in the maintenance mode, all the other actions change the state and are rejected
+var maintenanceReadActions = []string{"list", "detail", "get", "compare"}
+
+// adminEquivalentResourceTypes the holders of the writable permissions of these resources could grant themselves anything
+var adminEquivalentResourceTypes = []string{"*", "role", "permission", "user"}
+
+type approvedChangeKey struct{}
+
+// uiMenuCapabilities the menus of the UI and the permissions required to show them, keep it same with the MenuService of the UI
+var uiMenuCapabilities = []apisv1.UICapability{
+	{Name: "applications", Resource: "project:{projectName}/application:*", Action: "list"},
+	{Name: "env-list", Resource: "project:{projectName}/environment:*", Action: "list"},
+	{Name: "pipeline-list", Resource: "project:{projectName}/pipeline:*", Action: "list"},
+	{Name: "cluster-list", Resource: "cluster:*", Action: "list"},
+	{Name: "target-list", Resource: "target:*", Action: "list"},
+	{Name: "addon-list", Resource: "addon:*", Action: "list"},
+	{Name: "definition-list", Resource: "definition:*", Action: "list"},
+	{Name: "project-list", Resource: "project:*", Action: "list"},
+	{Name: "user-list", Resource: "user:*", Action: "list"},
+	{Name: "role-list", Resource: "role:*", Action: "list"},
+	{Name: "configs", Resource: "config:*", Action: "list"},
+}
+
+// defaultProjectRoles the roles created in every new project, the platform admin could add more by the project role templates
+var defaultProjectRoles = []*model.Role{
+	{
+		Name:        "app-developer",
+		Alias:       "App Developer",
+		Permissions: []string{"project-view", "app-management", "env-management", "config-management", "pipeline-management"},
+	},
+	{
+		Name:        "project-admin",
+		Alias:       "Project Admin",
+		Permissions: []string{"project-view", "app-management", "env-management", "pipeline-management", "config-management", "role-management"},
+	},
+	{
+		Name:        "project-viewer",
+		Alias:       "Project Viewer",
+		Permissions: []string{"project-view"},
+	},
 }
 
 // ResourceMaps all resources definition for RBAC
@@ -242,6 +318,9 @@ var ResourceMaps = map[string]resourceMetadata{
 				pathName: "userName",
 			},
 			"applicationTemplate": {},
+			"serviceInstance": {
+				pathName: "instanceName",
+			},
 			"config": {
 				pathName: "configName",
 			},
@@ -286,6 +365,9 @@ var ResourceMaps = map[string]resourceMetadata{
 	"definition": {
 		pathName: "definitionName",
 	},
+	"definitionCatalog": {
+		pathName: "catalogName",
+	},
 	"configType": {
 		pathName: "configType",
 		subResources: map[string]resourceMetadata{
@@ -297,6 +379,10 @@ var ResourceMaps = map[string]resourceMetadata{
 	"cloudshell":     {},
 	"config":         {},
 	"configTemplate": {},
+	"rbacApproval": {
+		pathName: "approvalName",
+	},
+	"maintenance": {},
 }
 
 var existResourcePaths = convertSources(ResourceMaps)
@@ -389,9 +475,42 @@ func registerResourceAction(resource string, actions ...string) {
 	}
 }
 
+// listResourceActions return all registered resources and the actions, sorted by the resource path
+func listResourceActions() []apisv1.ResourceActionBase {
+	lock.Lock()
+	defer lock.Unlock()
+	var list []apisv1.ResourceActionBase
+	for resource, actions := range resourceActions {
+		sortedActions := append([]string{}, actions...)
+		sort.Strings(sortedActions)
+		list = append(list, apisv1.ResourceActionBase{Resource: resource, Actions: sortedActions})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Resource < list[j].Resource
+	})
+	return list
+}
+
 type rbacServiceImpl struct {
 	Store      datastore.DataStore `inject:"datastore"`
 	KubeClient client.Client       `inject:"kubeClient"`
+	ChangeFeed *changefeed.Feed    `inject:"changeFeed"`
+	// PropagateToKubeRBAC grants the Kubernetes privileges of the project namespaces to the project members
+	PropagateToKubeRBAC bool
+	// permissionCache caches the permissions of the users, it is purged after the roles or permissions change
+	permissionCache *apiserverutils.LRUCache
+	// systemInfoCache caches whether the platform is in the maintenance mode
+	systemInfoCache *apiserverutils.LRUCache
+	// projectLockCache caches the expire time of the project locks, it is zero if the project is not locked
+	projectLockCache *apiserverutils.LRUCache
+	// unknownUserCache caches the users not in the datastore, so the requests with the stale tokens
+	// of the deleted users do not query the datastore every time
+	unknownUserCache *apiserverutils.LRUCache
+	// loadGroup merges the concurrent loads of the same user or the same permissions
+	loadGroup singleflight.Group
+	// permissionGeneration is increased when the permission cache is purged, the permissions
+	// loaded before purging are not cached
+	permissionGeneration uint64
 }
 
 // RBACService implement RBAC-related business logic.
@@ -401,18 +520,36 @@ type RBACService interface {
 	CreateRole(ctx context.Context, projectName string, req apisv1.CreateRoleRequest) (*apisv1.RoleBase, error)
 	DeleteRole(ctx context.Context, projectName, roleName string) error
 	UpdateRole(ctx context.Context, projectName, roleName string, req apisv1.UpdateRoleRequest) (*apisv1.RoleBase, error)
-	ListRole(ctx context.Context, projectName string, page, pageSize int) (*apisv1.ListRolesResponse, error)
+	GrantTargets(ctx context.Context, roleName string, req apisv1.GrantTargetsRequest) (*apisv1.RoleBase, error)
+	ListRole(ctx context.Context, projectName string, page, pageSize int, options apisv1.ListRoleOptions) (*apisv1.ListRolesResponse, error)
 	ListPermissionTemplate(ctx context.Context, projectName string) ([]apisv1.PermissionTemplateBase, error)
-	ListPermissions(ctx context.Context, projectName string) ([]apisv1.PermissionBase, error)
+	ListPermissions(ctx context.Context, projectName string, page, pageSize int, options apisv1.ListPermissionOptions) (*apisv1.ListPermissionsResponse, error)
 	CreatePermission(ctx context.Context, projectName string, req apisv1.CreatePermissionRequest) (*apisv1.PermissionBase, error)
 	DeletePermission(ctx context.Context, projectName, permName string) error
+	ListPermissionReferences(ctx context.Context, projectName, permName string) (*apisv1.PermissionReferencesResponse, error)
 	SyncDefaultRoleAndUsersForProject(ctx context.Context, project *model.Project) error
+	SyncProjectUserPrivileges(ctx context.Context, projectName, userName string) error
+	ListResourceActions(ctx context.Context) (*apisv1.ListResourceActionsResponse, error)
+	ListProjectRoleTemplates(ctx context.Context) (*apisv1.ListProjectRoleTemplatesResponse, error)
+	CreateProjectRoleTemplate(ctx context.Context, req apisv1.CreateProjectRoleTemplateRequest) (*apisv1.ProjectRoleTemplateBase, error)
+	UpdateProjectRoleTemplate(ctx context.Context, templateName string, req apisv1.UpdateProjectRoleTemplateRequest) (*apisv1.ProjectRoleTemplateBase, error)
+	DeleteProjectRoleTemplate(ctx context.Context, templateName string) error
+	ListRBACApprovals(ctx context.Context, status string) (*apisv1.ListRBACApprovalsResponse, error)
+	SimulateCapabilities(ctx context.Context, req apisv1.SimulateCapabilitiesRequest) (*apisv1.SimulateCapabilitiesResponse, error)
+	ApproveRBACChange(ctx context.Context, approvalName string, req apisv1.ReviewRBACApprovalRequest) (*apisv1.RBACApprovalBase, error)
+	RejectRBACChange(ctx context.Context, approvalName string, req apisv1.ReviewRBACApprovalRequest) (*apisv1.RBACApprovalBase, error)
 	Init(ctx context.Context) error
 }
 
 // NewRBACService is the service service of RBAC
-func NewRBACService() RBACService {
-	rbacService := &rbacServiceImpl{}
+func NewRBACService(propagateToKubeRBAC bool) RBACService {
+	rbacService := &rbacServiceImpl{
+		PropagateToKubeRBAC: propagateToKubeRBAC,
+		permissionCache:     apiserverutils.NewLRUCache(1024, 10*time.Second),
+		systemInfoCache:     apiserverutils.NewLRUCache(64, 5*time.Second),
+		projectLockCache:    apiserverutils.NewLRUCache(1024, 5*time.Second),
+		unknownUserCache:    apiserverutils.NewLRUCache(1024, 5*time.Second),
+	}
 	return rbacService
 }
 
@@ -440,7 +577,9 @@ func (p *rbacServiceImpl) Init(ctx context.Context) error {
 			Alias:       "Admin",
 			Permissions: []string{"admin"},
 		})
-		if err := p.Store.BatchAdd(ctx, batchData); err != nil {
+		if err := p.Store.Transaction(ctx, func(tx datastore.DataStore) error {
+			return tx.BatchAdd(ctx, batchData)
+		}); err != nil {
 			return fmt.Errorf("init the platform perm policies failure %w", err)
 		}
 	}
@@ -453,6 +592,77 @@ func (p *rbacServiceImpl) Init(ctx context.Context) error {
 
 // GetUserPermissions get user permission policies, if projectName is empty, will only get the platform permission policies
 func (p *rbacServiceImpl) GetUserPermissions(ctx context.Context, user *model.User, projectName string, withPlatform bool) ([]*model.Permission, error) {
+	// the platform roles are part of the key because they are changed by the user service
+	cacheKey := datastore.TenantKey(ctx, fmt.Sprintf("%s/%s/%s/%t", user.Name, strings.Join(user.UserRoles, ","), projectName, withPlatform))
+	if cached, ok := p.permissionCache.Get(cacheKey); ok {
+		return append([]*model.Permission{}, cached.([]*model.Permission)...), nil
+	}
+	loaded, err, _ := p.loadGroup.Do("permissions/"+cacheKey, func() (interface{}, error) {
+		generation := atomic.LoadUint64(&p.permissionGeneration)
+		perms, err := p.listUserPermissions(ctx, user, projectName, withPlatform)
+		if err != nil {
+			return nil, err
+		}
+		if generation == atomic.LoadUint64(&p.permissionGeneration) {
+			p.permissionCache.Put(cacheKey, perms)
+		}
+		return perms, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]*model.Permission{}, loaded.([]*model.Permission)...), nil
+}
+
+// purgePermissionCache purges the cached permissions after the roles, permissions or members change
+func (p *rbacServiceImpl) purgePermissionCache() {
+	atomic.AddUint64(&p.permissionGeneration, 1)
+	p.permissionCache.Purge()
+}
+
+// publishPermissionChange purges the permission cache, primes the permissions of the login user
+// and pushes the change to the subscribers in the same request, so the UI does not need to refetch
+func (p *rbacServiceImpl) publishPermissionChange(ctx context.Context, resource, projectName, name, action string) {
+	p.purgePermissionCache()
+	if projectName == model.RoleScopeCrossProject {
+		projectName = ""
+	}
+	operator, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if operator != "" {
+		if user, err := p.getLoginUser(ctx, operator); err == nil {
+			if _, err := p.GetUserPermissions(ctx, user, projectName, true); err != nil {
+				klog.Warningf("failed to prime the permissions of the user %s: %s", operator, err.Error())
+			}
+		}
+	}
+	p.ChangeFeed.Publish(changefeed.Event{Resource: resource, Name: name, Project: projectName, Action: action, Operator: operator, Org: datastore.TenantFromContext(ctx)})
+}
+
+// getLoginUser gets the user of the request, the unknown users are cached for a while
+func (p *rbacServiceImpl) getLoginUser(ctx context.Context, userName string) (*model.User, error) {
+	cacheKey := datastore.TenantKey(ctx, userName)
+	if _, ok := p.unknownUserCache.Get(cacheKey); ok {
+		return nil, datastore.ErrRecordNotExist
+	}
+	loaded, err, _ := p.loadGroup.Do("user/"+cacheKey, func() (interface{}, error) {
+		user := &model.User{Name: userName}
+		if err := p.Store.Get(ctx, user); err != nil {
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				p.unknownUserCache.Put(cacheKey, true)
+			}
+			return nil, err
+		}
+		return user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the callers sharing the load must not modify the same user
+	user := *loaded.(*model.User)
+	return &user, nil
+}
+
+func (p *rbacServiceImpl) listUserPermissions(ctx context.Context, user *model.User, projectName string, withPlatform bool) ([]*model.Permission, error) {
 	var permissionNames []string
 	var perms []*model.Permission
 	if withPlatform && len(user.UserRoles) > 0 {
@@ -502,6 +712,16 @@ func (p *rbacServiceImpl) GetUserPermissions(ctx context.Context, user *model.Us
 			for _, entity := range entities {
 				permissionNames = append(permissionNames, entity.(*model.Role).Permissions...)
 			}
+			var project = model.Project{Name: projectName}
+			if err := p.Store.Get(ctx, &project); err == nil {
+				crossProjectRoles, err := listCrossProjectRoles(ctx, p.Store, &project, roles)
+				if err != nil {
+					return nil, err
+				}
+				for _, role := range crossProjectRoles {
+					permissionNames = append(permissionNames, role.Permissions...)
+				}
+			}
 			projectPerms, err := p.listPermPolices(ctx, projectName, permissionNames)
 			if err != nil {
 				return nil, err
@@ -531,22 +751,27 @@ func (p *rbacServiceImpl) UpdatePermission(ctx context.Context, projectName stri
 		}
 	}
 	//TODO: check req validate
+	if err := checkResourceSelectors(req.Resources); err != nil {
+		return nil, err
+	}
+	if projectName == "" && isAdminEquivalent(&model.Permission{Resources: req.Resources, Actions: req.Actions, Effect: req.Effect}) && !isApprovedChange(ctx) {
+		return nil, p.requestRBACApproval(ctx, "permission", "update", permissionName, req)
+	}
 	perm.Actions = req.Actions
 	perm.Alias = req.Alias
 	perm.Resources = req.Resources
 	perm.Effect = req.Effect
+	if req.Priority != nil {
+		perm.Priority = *req.Priority
+	}
+	if req.ResourceVersion != 0 {
+		perm.ResourceVersion = req.ResourceVersion
+	}
 	if err := p.Store.Put(ctx, perm); err != nil {
 		return nil, err
 	}
-	return &apisv1.PermissionBase{
-		Name:       perm.Name,
-		Alias:      perm.Alias,
-		Resources:  perm.Resources,
-		Actions:    perm.Actions,
-		Effect:     perm.Effect,
-		CreateTime: perm.CreateTime,
-		UpdateTime: perm.UpdateTime,
-	}, nil
+	p.publishPermissionChange(ctx, "permission", projectName, permissionName, changefeed.ActionUpdate)
+	return assembler.ConvertPermission2DTO(perm), nil
 }
 
 func (p *rbacServiceImpl) listPermPolices(ctx context.Context, projectName string, permissionNames []string) ([]*model.Permission, error) {
@@ -584,11 +809,15 @@ func (p *rbacServiceImpl) CheckPerm(resource string, actions ...string) func(req
 			bcode.ReturnError(req, res, bcode.ErrUnauthorized)
 			return
 		}
-		user := &model.User{Name: userName}
-		if err := p.Store.Get(req.Request.Context(), user); err != nil {
+		user, err := p.getLoginUser(req.Request.Context(), userName)
+		if err != nil {
 			bcode.ReturnError(req, res, bcode.ErrUnauthorized)
 			return
 		}
+		if user.MustChangePassword && !isSelfUpdateRequest(req, resource, userName) {
+			bcode.ReturnError(req, res, bcode.ErrPasswordChangeRequired)
+			return
+		}
 		path, err := checkResourcePath(resource)
 		if err != nil {
 			klog.Errorf("check resource path failure %s", err.Error())
@@ -630,6 +859,13 @@ func (p *rbacServiceImpl) CheckPerm(resource string, actions ...string) func(req
 			return req.PathParameter(name)
 		})
 		ra.SetActions(actions)
+		// the labels of the application are used to match the label selectors of the policies
+		if appName := req.PathParameter(ResourceMaps["project"].subResources["application"].pathName); appName != "" {
+			app := &model.Application{Name: appName}
+			if err := p.Store.Get(req.Request.Context(), app); err == nil {
+				ra.SetResourceLabels("application", app.Labels)
+			}
+		}
 
 		// get user's perm list.
 		projectName := getProjectName()
@@ -643,27 +879,104 @@ func (p *rbacServiceImpl) CheckPerm(resource string, actions ...string) func(req
 			bcode.ReturnError(req, res, bcode.ErrForbidden)
 			return
 		}
+		if p.isBlockedByMaintenance(req.Request.Context(), actions, permissions) {
+			bcode.ReturnError(req, res, bcode.ErrMaintenanceMode)
+			return
+		}
+		if p.isBlockedByProjectLock(req.Request.Context(), projectName, actions, permissions) {
+			bcode.ReturnError(req, res, bcode.ErrProjectLocked)
+			return
+		}
 		apiserverutils.SetUsernameAndProjectInRequestContext(req, userName, projectName)
 		chain.ProcessFilter(req, res)
 	}
 	return f
 }
 
-func (p *rbacServiceImpl) CreateRole(ctx context.Context, projectName string, req apisv1.CreateRoleRequest) (*apisv1.RoleBase, error) {
-	if projectName != "" {
-		var project = model.Project{
-			Name: projectName,
+// isSelfUpdateRequest checks whether the user updates itself, such as changing the password that must be changed
+func isSelfUpdateRequest(req *restful.Request, resource, userName string) bool {
+	return resource == "user" && req.Request.Method == http.MethodPut && req.PathParameter("username") == userName
+}
+
+// isBlockedByMaintenance checks whether the write actions should be rejected because the platform is in the maintenance mode
+func (p *rbacServiceImpl) isBlockedByMaintenance(ctx context.Context, actions []string, permissions []*model.Permission) bool {
+	var write bool
+	for _, action := range actions {
+		if !utils.StringsContain(maintenanceReadActions, action) {
+			write = true
+			break
 		}
-		if err := p.Store.Get(ctx, &project); err != nil {
-			return nil, bcode.ErrProjectIsNotExist
+	}
+	if !write {
+		return false
+	}
+	maintenance, ok := p.systemInfoCache.Get(datastore.TenantKey(ctx, "maintenance"))
+	if !ok {
+		entities, err := p.Store.List(ctx, &model.SystemInfo{}, &datastore.ListOptions{})
+		if err != nil {
+			klog.Errorf("failed to get the system info %s", err.Error())
+			return false
 		}
+		maintenance = len(entities) > 0 && entities[0].(*model.SystemInfo).MaintenanceMode
+		p.systemInfoCache.Put(datastore.TenantKey(ctx, "maintenance"), maintenance)
 	}
-	if len(req.Permissions) == 0 {
-		return nil, bcode.ErrRolePermissionCheckFailure
+	if !maintenance.(bool) {
+		return false
 	}
-	policies, err := p.listPermPolices(ctx, projectName, req.Permissions)
-	if err != nil || len(policies) != len(req.Permissions) {
-		return nil, bcode.ErrRolePermissionCheckFailure
+	ra := &RequestResourceAction{}
+	ra.SetResourceWithName("maintenance", func(name string) string { return "" })
+	ra.SetActions([]string{"override"})
+	return !ra.Match(permissions)
+}
+
+// isBlockedByProjectLock checks whether the write actions should be rejected because the project is locked,
+// the users who could override the maintenance mode could also change the locked projects
+func (p *rbacServiceImpl) isBlockedByProjectLock(ctx context.Context, projectName string, actions []string, permissions []*model.Permission) bool {
+	if projectName == "" {
+		return false
+	}
+	var write bool
+	for _, action := range actions {
+		if utils.StringsContain(projectLockWriteActions, action) {
+			write = true
+			break
+		}
+	}
+	if !write {
+		return false
+	}
+	expireTime, ok := p.projectLockCache.Get(datastore.TenantKey(ctx, projectName))
+	if !ok {
+		project := &model.Project{Name: projectName}
+		if err := p.Store.Get(ctx, project); err != nil {
+			if !errors.Is(err, datastore.ErrRecordNotExist) {
+				klog.Errorf("failed to get the project %s: %s", projectName, err.Error())
+			}
+			return false
+		}
+		var lockExpireTime time.Time
+		if project.Lock != nil {
+			lockExpireTime = project.Lock.ExpireTime
+		}
+		expireTime = lockExpireTime
+		p.projectLockCache.Put(datastore.TenantKey(ctx, projectName), expireTime)
+	}
+	if !time.Now().Before(expireTime.(time.Time)) {
+		return false
+	}
+	ra := &RequestResourceAction{}
+	ra.SetResourceWithName("maintenance", func(name string) string { return "" })
+	ra.SetActions([]string{"override"})
+	return !ra.Match(permissions)
+}
+
+func (p *rbacServiceImpl) CreateRole(ctx context.Context, projectName string, req apisv1.CreateRoleRequest) (*apisv1.RoleBase, error) {
+	policies, err := p.checkRolePermissions(ctx, projectName, req.Permissions, req.Projects, req.ProjectSelector)
+	if err != nil {
+		return nil, err
+	}
+	if projectName == "" && isAdminEquivalent(policies...) && !isApprovedChange(ctx) {
+		return nil, p.requestRBACApproval(ctx, "role", "create", req.Name, req)
 	}
 	var role = model.Role{
 		Name:        req.Name,
@@ -671,12 +984,17 @@ func (p *rbacServiceImpl) CreateRole(ctx context.Context, projectName string, re
 		Project:     projectName,
 		Permissions: req.Permissions,
 	}
+	if projectName == model.RoleScopeCrossProject {
+		role.Projects = req.Projects
+		role.ProjectSelector = req.ProjectSelector
+	}
 	if err := p.Store.Add(ctx, &role); err != nil {
 		if errors.Is(err, datastore.ErrRecordExist) {
 			return nil, bcode.ErrRoleIsExist
 		}
 		return nil, err
 	}
+	p.publishPermissionChange(ctx, "role", projectName, role.Name, changefeed.ActionCreate)
 	return assembler.ConvertRole2DTO(&role, policies), nil
 }
 
@@ -685,17 +1003,29 @@ func (p *rbacServiceImpl) DeleteRole(ctx context.Context, projectName, roleName
 		Name:    roleName,
 		Project: projectName,
 	}
-	if err := p.Store.Delete(ctx, &role); err != nil {
+	if err := p.Store.Get(ctx, &role); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrRoleIsNotExist
+		}
+		return err
+	}
+	if err := datastore.InTransaction(ctx, p.Store, func(ctx context.Context) error {
+		if _, err := moveToTrash(ctx, p.Store, TrashKindRole, projectName, role.Alias, &role); err != nil {
+			return err
+		}
+		return p.Store.Delete(ctx, &role)
+	}); err != nil {
 		if errors.Is(err, datastore.ErrRecordNotExist) {
 			return bcode.ErrRoleIsNotExist
 		}
 		return err
 	}
+	p.publishPermissionChange(ctx, "role", projectName, roleName, changefeed.ActionDelete)
 	return nil
 }
 
 func (p *rbacServiceImpl) DeletePermission(ctx context.Context, projectName, permName string) error {
-	roles, _, err := repository.ListRoles(ctx, p.Store, projectName, 0, 0)
+	roles, _, err := repository.ListRoles(ctx, p.Store, projectName, 0, 0, nil, nil)
 	if err != nil {
 		klog.Errorf("fail to list the roles: %s", err.Error())
 		return bcode.ErrPermissionIsUsed
@@ -718,24 +1048,106 @@ func (p *rbacServiceImpl) DeletePermission(ctx context.Context, projectName, per
 		}
 		return err
 	}
+	p.publishPermissionChange(ctx, "permission", projectName, permName, changefeed.ActionDelete)
 	return nil
 }
 
-func (p *rbacServiceImpl) UpdateRole(ctx context.Context, projectName, roleName string, req apisv1.UpdateRoleRequest) (*apisv1.RoleBase, error) {
-	if projectName != "" {
-		var project = model.Project{
-			Name: projectName,
+// ListPermissionReferences list the roles and users that depend on the permission.
+// The project permissions are also granted to the project members through the platform roles with the same permission name.
+func (p *rbacServiceImpl) ListPermissionReferences(ctx context.Context, projectName, permName string) (*apisv1.PermissionReferencesResponse, error) {
+	var perm = model.Permission{
+		Name:    permName,
+		Project: projectName,
+	}
+	if err := p.Store.Get(ctx, &perm); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrPermissionNotExist
 		}
-		if err := p.Store.Get(ctx, &project); err != nil {
-			return nil, bcode.ErrProjectIsNotExist
+		return nil, err
+	}
+	resp := &apisv1.PermissionReferencesResponse{Roles: []apisv1.PermissionReferenceRole{}, Users: []apisv1.PermissionReferenceUser{}}
+	referencingRoles := func(project string) ([]string, error) {
+		roles, _, err := repository.ListRoles(ctx, p.Store, project, 0, 0, nil, nil)
+		if err != nil {
+			return nil, err
 		}
+		var names []string
+		for _, role := range roles {
+			if utils.StringsContain(role.Permissions, permName) {
+				names = append(names, role.Name)
+				resp.Roles = append(resp.Roles, apisv1.PermissionReferenceRole{Name: role.Name, Alias: role.Alias, Project: role.Project})
+			}
+		}
+		return names, nil
 	}
-	if len(req.Permissions) == 0 {
-		return nil, bcode.ErrRolePermissionCheckFailure
+	platformRoles, err := referencingRoles("")
+	if err != nil {
+		return nil, err
 	}
-	policies, err := p.listPermPolices(ctx, projectName, req.Permissions)
-	if err != nil || len(policies) != len(req.Permissions) {
-		return nil, bcode.ErrRolePermissionCheckFailure
+	users, err := p.Store.List(ctx, &model.User{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if projectName == "" {
+		for _, entity := range users {
+			user := entity.(*model.User)
+			if roles := intersectStrings(platformRoles, user.UserRoles); len(roles) > 0 {
+				resp.Users = append(resp.Users, apisv1.PermissionReferenceUser{Name: user.Name, Alias: user.Alias, Roles: roles})
+			}
+		}
+		return resp, nil
+	}
+	projectRoles, err := referencingRoles(projectName)
+	if err != nil {
+		return nil, err
+	}
+	var project = model.Project{Name: projectName}
+	if err := p.Store.Get(ctx, &project); err == nil {
+		crossProjectRoles, err := listCrossProjectRoles(ctx, p.Store, &project, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range crossProjectRoles {
+			if utils.StringsContain(role.Permissions, permName) {
+				projectRoles = append(projectRoles, role.Name)
+				resp.Roles = append(resp.Roles, apisv1.PermissionReferenceRole{Name: role.Name, Alias: role.Alias, Project: role.Project})
+			}
+		}
+	}
+	userMap := make(map[string]*model.User, len(users))
+	for _, entity := range users {
+		user := entity.(*model.User)
+		userMap[user.Name] = user
+	}
+	projectUsers, err := p.Store.List(ctx, &model.ProjectUser{ProjectName: projectName}, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range projectUsers {
+		projectUser := entity.(*model.ProjectUser)
+		reference := apisv1.PermissionReferenceUser{Name: projectUser.Username}
+		if user, exist := userMap[projectUser.Username]; exist {
+			reference.Alias = user.Alias
+			if roles := intersectStrings(platformRoles, user.UserRoles); len(roles) > 0 {
+				reference.Roles = roles
+				reference.Indirect = true
+			}
+		}
+		if roles := intersectStrings(projectRoles, projectUser.UserRoles); len(roles) > 0 {
+			reference.Roles = append(roles, reference.Roles...)
+			reference.Indirect = false
+		}
+		if len(reference.Roles) > 0 {
+			resp.Users = append(resp.Users, reference)
+		}
+	}
+	return resp, nil
+}
+
+func (p *rbacServiceImpl) UpdateRole(ctx context.Context, projectName, roleName string, req apisv1.UpdateRoleRequest) (*apisv1.RoleBase, error) {
+	policies, err := p.checkRolePermissions(ctx, projectName, req.Permissions, req.Projects, req.ProjectSelector)
+	if err != nil {
+		return nil, err
 	}
 	var role = model.Role{
 		Name:    roleName,
@@ -747,79 +1159,141 @@ func (p *rbacServiceImpl) UpdateRole(ctx context.Context, projectName, roleName
 		}
 		return nil, err
 	}
+	if projectName == "" && isAdminEquivalent(policies...) && !isApprovedChange(ctx) {
+		return nil, p.requestRBACApproval(ctx, "role", "update", roleName, req)
+	}
+	// the projects that the cross-project role could be bound in before and after the change
+	projects := p.listRoleProjects(ctx, &role)
 	role.Alias = req.Alias
 	role.Permissions = req.Permissions
+	if projectName == model.RoleScopeCrossProject {
+		role.Projects = req.Projects
+		role.ProjectSelector = req.ProjectSelector
+		projects = append(projects, p.listRoleProjects(ctx, &role)...)
+	}
+	if req.ResourceVersion != 0 {
+		role.ResourceVersion = req.ResourceVersion
+	}
 	if err := p.Store.Put(ctx, &role); err != nil {
 		return nil, err
 	}
+	p.publishPermissionChange(ctx, "role", projectName, roleName, changefeed.ActionUpdate)
+	synced := make(map[string]bool)
+	for _, project := range projects {
+		if synced[project] {
+			continue
+		}
+		synced[project] = true
+		p.syncProjectPrivileges(ctx, project)
+		recordProjectMemberEvent(ctx, p.Store, &model.ProjectMemberEvent{
+			Project:     project,
+			Type:        model.ProjectRoleUpdated,
+			Roles:       []string{roleName},
+			Permissions: req.Permissions,
+		})
+	}
 	return assembler.ConvertRole2DTO(&role, policies), nil
 }
 
-func (p *rbacServiceImpl) ListRole(ctx context.Context, projectName string, page, pageSize int) (*apisv1.ListRolesResponse, error) {
-	roles, count, err := repository.ListRoles(ctx, p.Store, projectName, 0, 0)
-	if err != nil {
-		return nil, err
+// GrantTargets grants the platform role the access to the targets and clusters.
+// A permission is generated for every target and cluster, the existing ones are updated with the actions.
+func (p *rbacServiceImpl) GrantTargets(ctx context.Context, roleName string, req apisv1.GrantTargetsRequest) (*apisv1.RoleBase, error) {
+	if len(req.Targets) == 0 && len(req.Clusters) == 0 {
+		return nil, bcode.ErrRolePermissionCheckFailure
 	}
-	var policySet = make(map[string]string)
-	for _, role := range roles {
-		for _, p := range role.Permissions {
-			policySet[p] = p
+	var role = model.Role{Name: roleName}
+	if err := p.Store.Get(ctx, &role); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrRoleIsNotExist
 		}
+		return nil, err
 	}
-
-	policies, err := p.listPermPolices(ctx, projectName, utils.MapKey2Array(policySet))
-	if err != nil {
-		klog.Errorf("list perm policies failure %s", err.Error())
-	}
-	var policyMap = make(map[string]*model.Permission)
-	for i, policy := range policies {
-		policyMap[policy.Name] = policies[i]
+	actions := req.Actions
+	if len(actions) == 0 {
+		actions = []string{"*"}
 	}
-	var res apisv1.ListRolesResponse
-	for _, role := range roles {
-		var rolePolicies []*model.Permission
-		for _, perm := range role.Permissions {
-			rolePolicies = append(rolePolicies, policyMap[perm])
+	var permissions []*model.Permission
+	for _, target := range req.Targets {
+		if err := p.Store.Get(ctx, &model.Target{Name: target}); err != nil {
+			return nil, bcode.ErrTargetNotExist.SetMessage(fmt.Sprintf("target %s is not exist", target))
 		}
-		res.Roles = append(res.Roles, assembler.ConvertRole2DTO(role, rolePolicies))
+		permissions = append(permissions, &model.Permission{
+			Name:      fmt.Sprintf("%s-target-%s", roleName, target),
+			Alias:     fmt.Sprintf("Target %s", target),
+			Resources: []string{"target:" + target},
+		})
 	}
-	res.Total = count
-	return &res, nil
-}
-
-// ListPermissionTemplate TODO:
-func (p *rbacServiceImpl) ListPermissionTemplate(ctx context.Context, projectName string) ([]apisv1.PermissionTemplateBase, error) {
-	return nil, nil
-}
-
-func (p *rbacServiceImpl) ListPermissions(ctx context.Context, projectName string) ([]apisv1.PermissionBase, error) {
-	var filter datastore.FilterOptions
-	if projectName == "" {
-		filter.IsNotExist = append(filter.IsNotExist, datastore.IsNotExistQueryOption{
-			Key: "project",
+	for _, cluster := range req.Clusters {
+		if err := p.Store.Get(ctx, &model.Cluster{Name: cluster}); err != nil {
+			return nil, bcode.ErrClusterNotFoundInDataStore.SetMessage(fmt.Sprintf("cluster %s not found in data store", cluster))
+		}
+		permissions = append(permissions, &model.Permission{
+			Name:      fmt.Sprintf("%s-cluster-%s", roleName, cluster),
+			Alias:     fmt.Sprintf("Cluster %s", cluster),
+			Resources: []string{"cluster:" + cluster, "cluster:" + cluster + "/namespace:*"},
 		})
 	}
-	permEntities, err := p.Store.List(ctx, &model.Permission{Project: projectName}, &datastore.ListOptions{FilterOptions: filter})
+	generated := make(map[string]bool, len(permissions))
+	rolePermissions := role.Permissions
+	for _, perm := range permissions {
+		perm.Actions = actions
+		perm.Effect = "Allow"
+		generated[perm.Name] = true
+		if !utils.StringsContain(rolePermissions, perm.Name) {
+			rolePermissions = append(rolePermissions, perm.Name)
+		}
+	}
+	// the approval is checked with the policies of the role after the grant, before anything is written
+	var existing []string
+	for _, name := range role.Permissions {
+		if !generated[name] {
+			existing = append(existing, name)
+		}
+	}
+	policies, err := p.listPermPolices(ctx, "", existing)
 	if err != nil {
 		return nil, err
 	}
-	var perms []apisv1.PermissionBase
-	for _, entity := range permEntities {
-		perm := entity.(*model.Permission)
-		perms = append(perms, apisv1.PermissionBase{
-			Name:       perm.Name,
-			Alias:      perm.Alias,
-			Resources:  perm.Resources,
-			Actions:    perm.Actions,
-			Effect:     perm.Effect,
-			CreateTime: perm.CreateTime,
-			UpdateTime: perm.UpdateTime,
-		})
+	policies = append(policies, permissions...)
+	if isAdminEquivalent(policies...) && !isApprovedChange(ctx) {
+		return nil, p.requestRBACApproval(ctx, "role", "grant-targets", roleName, req)
+	}
+	// the role is never left with a part of the generated permissions
+	role.Permissions = rolePermissions
+	if err := p.Store.Transaction(ctx, func(tx datastore.DataStore) error {
+		for _, perm := range permissions {
+			err := tx.Put(ctx, perm)
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				err = tx.Add(ctx, perm)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Put(ctx, &role)
+	}); err != nil {
+		return nil, err
 	}
-	return perms, nil
+	p.publishPermissionChange(ctx, "role", "", roleName, changefeed.ActionUpdate)
+	return assembler.ConvertRole2DTO(&role, policies), nil
 }
 
-func (p *rbacServiceImpl) CreatePermission(ctx context.Context, projectName string, req apisv1.CreatePermissionRequest) (*apisv1.PermissionBase, error) {
+// checkRolePermissions checks the project and the permissions of the role, and returns the permission policies.
+// The permissions of the cross-project roles are resolved in every project that the role is bound in, so only the names are returned.
+func (p *rbacServiceImpl) checkRolePermissions(ctx context.Context, projectName string, permissions, projects []string, projectSelector map[string]string) ([]*model.Permission, error) {
+	if projectName == model.RoleScopeCrossProject {
+		if len(projects) == 0 && len(projectSelector) == 0 {
+			return nil, bcode.ErrRoleProjectScopeRequired
+		}
+		if len(permissions) == 0 {
+			return nil, bcode.ErrRolePermissionCheckFailure
+		}
+		var policies []*model.Permission
+		for _, name := range permissions {
+			policies = append(policies, &model.Permission{Name: name})
+		}
+		return policies, nil
+	}
 	if projectName != "" {
 		var project = model.Project{
 			Name: projectName,
@@ -828,18 +1302,228 @@ func (p *rbacServiceImpl) CreatePermission(ctx context.Context, projectName stri
 			return nil, bcode.ErrProjectIsNotExist
 		}
 	}
-	if len(req.Resources) == 0 {
+	if len(permissions) == 0 {
 		return nil, bcode.ErrRolePermissionCheckFailure
 	}
-
-	if len(req.Actions) == 0 {
-		req.Actions = []string{"*"}
+	policies, err := p.listPermPolices(ctx, projectName, permissions)
+	if err != nil || len(policies) != len(permissions) {
+		return nil, bcode.ErrRolePermissionCheckFailure
 	}
+	return policies, nil
+}
 
-	if req.Effect == "" {
-		req.Effect = "Allow"
+// listRoleProjects lists the names of the projects that the role could be bound in
+func (p *rbacServiceImpl) listRoleProjects(ctx context.Context, role *model.Role) []string {
+	if role.Project != model.RoleScopeCrossProject {
+		if role.Project == "" {
+			return nil
+		}
+		return []string{role.Project}
 	}
-
+	entities, err := p.Store.List(ctx, &model.Project{}, nil)
+	if err != nil {
+		klog.Warningf("failed to list the projects of the role %s: %s", role.Name, err.Error())
+		return nil
+	}
+	var projects []string
+	for _, entity := range entities {
+		if project := entity.(*model.Project); role.MatchProject(project) {
+			projects = append(projects, project.Name)
+		}
+	}
+	return projects
+}
+
+// listCrossProjectRoles lists the cross-project roles that could be bound in the project, filtered by the names if not empty
+func listCrossProjectRoles(ctx context.Context, store datastore.DataStore, project *model.Project, names []string) ([]*model.Role, error) {
+	var options *datastore.ListOptions
+	if len(names) > 0 {
+		options = &datastore.ListOptions{FilterOptions: datastore.FilterOptions{In: []datastore.InQueryOption{{Key: "name", Values: names}}}}
+	}
+	entities, err := store.List(ctx, &model.Role{Project: model.RoleScopeCrossProject}, options)
+	if err != nil {
+		return nil, err
+	}
+	var roles []*model.Role
+	for _, entity := range entities {
+		if role := entity.(*model.Role); role.MatchProject(project) {
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+// listSortOptions converts the sort field and the order of the list requests to the datastore sort options
+func listSortOptions(sortBy, order string) ([]datastore.SortOption, error) {
+	var sortOrder = datastore.SortOrderDescending
+	switch order {
+	case "", "desc":
+	case "asc":
+		sortOrder = datastore.SortOrderAscending
+	default:
+		return nil, bcode.ErrInvalidSortOptions
+	}
+	switch sortBy {
+	case "":
+		sortBy = "createTime"
+	case "name", "createTime":
+	default:
+		return nil, bcode.ErrInvalidSortOptions
+	}
+	return []datastore.SortOption{{Key: sortBy, Order: sortOrder}}, nil
+}
+
+// pageCrossProjectRoles returns the cross-project roles in the page, they are listed after the project roles
+func pageCrossProjectRoles(roles []*model.Role, projectRoleCount int64, page, pageSize int) []*model.Role {
+	if page <= 0 || pageSize <= 0 {
+		return roles
+	}
+	start := int64((page-1)*pageSize) - projectRoleCount
+	end := int64(page*pageSize) - projectRoleCount
+	if start < 0 {
+		start = 0
+	}
+	if end > int64(len(roles)) {
+		end = int64(len(roles))
+	}
+	if end <= start {
+		return nil
+	}
+	return roles[start:end]
+}
+
+func (p *rbacServiceImpl) ListRole(ctx context.Context, projectName string, page, pageSize int, options apisv1.ListRoleOptions) (*apisv1.ListRolesResponse, error) {
+	sortBy, err := listSortOptions(options.SortBy, options.Order)
+	if err != nil {
+		return nil, err
+	}
+	var queries []datastore.FuzzyQueryOption
+	if options.Name != "" {
+		queries = append(queries, datastore.FuzzyQueryOption{Key: "name", Query: options.Name})
+	}
+	roles, count, err := repository.ListRoles(ctx, p.Store, projectName, page, pageSize, queries, sortBy)
+	if err != nil {
+		return nil, err
+	}
+	// the cross-project roles could be bound in the project too
+	if projectName != "" && projectName != model.RoleScopeCrossProject {
+		var project = model.Project{Name: projectName}
+		if err := p.Store.Get(ctx, &project); err == nil {
+			crossProjectRoles, err := listCrossProjectRoles(ctx, p.Store, &project, nil)
+			if err != nil {
+				return nil, err
+			}
+			var matched []*model.Role
+			for _, role := range crossProjectRoles {
+				if strings.Contains(role.Name, options.Name) {
+					matched = append(matched, role)
+				}
+			}
+			roles = append(roles, pageCrossProjectRoles(matched, count, page, pageSize)...)
+			count += int64(len(matched))
+		}
+	}
+	var policySet = make(map[string]string)
+	for _, role := range roles {
+		for _, p := range role.Permissions {
+			policySet[p] = p
+		}
+	}
+
+	policies, err := p.listPermPolices(ctx, projectName, utils.MapKey2Array(policySet))
+	if err != nil {
+		klog.Errorf("list perm policies failure %s", err.Error())
+	}
+	var policyMap = make(map[string]*model.Permission)
+	for i, policy := range policies {
+		policyMap[policy.Name] = policies[i]
+	}
+	var res apisv1.ListRolesResponse
+	for _, role := range roles {
+		var rolePolicies []*model.Permission
+		for _, perm := range role.Permissions {
+			if policy, ok := policyMap[perm]; ok {
+				rolePolicies = append(rolePolicies, policy)
+			} else if role.Project == model.RoleScopeCrossProject {
+				rolePolicies = append(rolePolicies, &model.Permission{Name: perm})
+			}
+		}
+		res.Roles = append(res.Roles, assembler.ConvertRole2DTO(role, rolePolicies))
+	}
+	res.Total = count
+	return &res, nil
+}
+
+// ListPermissionTemplate TODO:
+func (p *rbacServiceImpl) ListPermissionTemplate(ctx context.Context, projectName string) ([]apisv1.PermissionTemplateBase, error) {
+	return nil, nil
+}
+
+func (p *rbacServiceImpl) ListPermissions(ctx context.Context, projectName string, page, pageSize int, options apisv1.ListPermissionOptions) (*apisv1.ListPermissionsResponse, error) {
+	sortBy, err := listSortOptions(options.SortBy, options.Order)
+	if err != nil {
+		return nil, err
+	}
+	var filter datastore.FilterOptions
+	if projectName == "" {
+		filter.IsNotExist = append(filter.IsNotExist, datastore.IsNotExistQueryOption{
+			Key: "project",
+		})
+	}
+	if options.Name != "" {
+		filter.Queries = append(filter.Queries, datastore.FuzzyQueryOption{Key: "name", Query: options.Name})
+	}
+	var permission = model.Permission{Project: projectName}
+	permEntities, err := p.Store.List(ctx, &permission, &datastore.ListOptions{FilterOptions: filter, Page: page, PageSize: pageSize, SortBy: sortBy})
+	if err != nil {
+		return nil, err
+	}
+	var res apisv1.ListPermissionsResponse
+	for _, entity := range permEntities {
+		perm := entity.(*model.Permission)
+		res.Permissions = append(res.Permissions, apisv1.PermissionBase{
+			Name:       perm.Name,
+			Alias:      perm.Alias,
+			Resources:  perm.Resources,
+			Actions:    perm.Actions,
+			Effect:     perm.Effect,
+			CreateTime: perm.CreateTime,
+			UpdateTime: perm.UpdateTime,
+		})
+	}
+	res.Total = int64(len(res.Permissions))
+	if page > 0 && pageSize > 0 {
+		if res.Total, err = p.Store.Count(ctx, &permission, &filter); err != nil {
+			return nil, err
+		}
+	}
+	return &res, nil
+}
+
+func (p *rbacServiceImpl) CreatePermission(ctx context.Context, projectName string, req apisv1.CreatePermissionRequest) (*apisv1.PermissionBase, error) {
+	if projectName != "" {
+		var project = model.Project{
+			Name: projectName,
+		}
+		if err := p.Store.Get(ctx, &project); err != nil {
+			return nil, bcode.ErrProjectIsNotExist
+		}
+	}
+	if len(req.Resources) == 0 {
+		return nil, bcode.ErrRolePermissionCheckFailure
+	}
+	if err := checkResourceSelectors(req.Resources); err != nil {
+		return nil, err
+	}
+
+	if len(req.Actions) == 0 {
+		req.Actions = []string{"*"}
+	}
+
+	if req.Effect == "" {
+		req.Effect = "Allow"
+	}
+
 	var permission = model.Permission{
 		Name:      req.Name,
 		Alias:     req.Alias,
@@ -847,6 +1531,10 @@ func (p *rbacServiceImpl) CreatePermission(ctx context.Context, projectName stri
 		Resources: req.Resources,
 		Actions:   req.Actions,
 		Effect:    req.Effect,
+		Priority:  req.Priority,
+	}
+	if projectName == "" && isAdminEquivalent(&permission) && !isApprovedChange(ctx) {
+		return nil, p.requestRBACApproval(ctx, "permission", "create", req.Name, req)
 	}
 
 	if err := p.Store.Add(ctx, &permission); err != nil {
@@ -855,21 +1543,22 @@ func (p *rbacServiceImpl) CreatePermission(ctx context.Context, projectName stri
 		}
 		return nil, err
 	}
+	p.publishPermissionChange(ctx, "permission", projectName, permission.Name, changefeed.ActionCreate)
 	return assembler.ConvertPermission2DTO(&permission), nil
 }
 
 func (p *rbacServiceImpl) SyncDefaultRoleAndUsersForProject(ctx context.Context, project *model.Project) error {
 
-	permissions, err := p.ListPermissions(ctx, project.Name)
+	permissions, err := p.ListPermissions(ctx, project.Name, 0, 0, apisv1.ListPermissionOptions{})
 	if err != nil {
 		return err
 	}
 	var permissionMap = map[string]apisv1.PermissionBase{}
-	for i, per := range permissions {
-		permissionMap[per.Name] = permissions[i]
+	for i, per := range permissions.Permissions {
+		permissionMap[per.Name] = permissions.Permissions[i]
 	}
 
-	var batchData []datastore.Entity
+	var batchData, updated []datastore.Entity
 	for _, permissionTemp := range defaultProjectPermissionTemplate {
 		var rra = RequestResourceAction{}
 		var formattedResource []string
@@ -892,32 +1581,34 @@ func (p *rbacServiceImpl) SyncDefaultRoleAndUsersForProject(ctx context.Context,
 		}
 		if perm, exist := permissionMap[permissionTemp.Name]; exist {
 			if !utils.EqualSlice(perm.Resources, permissionTemp.Resources) || utils.EqualSlice(perm.Actions, permissionTemp.Actions) {
-				if err := p.Store.Put(ctx, permission); err != nil {
-					return err
-				}
+				updated = append(updated, permission)
 			}
 			continue
 		}
 		batchData = append(batchData, permission)
 	}
 
-	if len(permissions) == 0 {
-		batchData = append(batchData, &model.Role{
-			Name:        "app-developer",
-			Alias:       "App Developer",
-			Permissions: []string{"project-view", "app-management", "env-management", "config-management", "pipeline-management"},
-			Project:     project.Name,
-		}, &model.Role{
-			Name:        "project-admin",
-			Alias:       "Project Admin",
-			Permissions: []string{"project-view", "app-management", "env-management", "pipeline-management", "config-management", "role-management"},
-			Project:     project.Name,
-		}, &model.Role{
-			Name:        "project-viewer",
-			Alias:       "Project Viewer",
-			Permissions: []string{"project-view"},
-			Project:     project.Name,
-		})
+	if len(permissions.Permissions) == 0 {
+		for _, role := range defaultProjectRoles {
+			batchData = append(batchData, &model.Role{
+				Name:        role.Name,
+				Alias:       role.Alias,
+				Permissions: role.Permissions,
+				Project:     project.Name,
+			})
+		}
+		templates, err := p.listProjectRoleTemplates(ctx)
+		if err != nil {
+			return err
+		}
+		for _, template := range templates {
+			batchData = append(batchData, &model.Role{
+				Name:        template.Name,
+				Alias:       template.Alias,
+				Permissions: template.Permissions,
+				Project:     project.Name,
+			})
+		}
 		if project.Owner != "" {
 			var projectUser = &model.ProjectUser{
 				ProjectName: project.Name,
@@ -928,24 +1619,417 @@ func (p *rbacServiceImpl) SyncDefaultRoleAndUsersForProject(ctx context.Context,
 		}
 	}
 
-	return p.Store.BatchAdd(ctx, batchData)
+	// the project is never left with a part of the default roles and permissions
+	if err := p.Store.Transaction(ctx, func(tx datastore.DataStore) error {
+		for _, permission := range updated {
+			if err := tx.Put(ctx, permission); err != nil {
+				return err
+			}
+		}
+		return tx.BatchAdd(ctx, batchData)
+	}); err != nil {
+		return err
+	}
+	p.purgePermissionCache()
+	if len(permissions.Permissions) == 0 && project.Owner != "" {
+		recordProjectMemberEvent(ctx, p.Store, &model.ProjectMemberEvent{
+			Project:  project.Name,
+			Type:     model.ProjectMemberAdded,
+			Username: project.Owner,
+			Roles:    []string{"project-admin"},
+		})
+		if err := p.SyncProjectUserPrivileges(ctx, project.Name, project.Owner); err != nil {
+			klog.Warningf("failed to sync the kubernetes privileges of the project owner %s: %s", project.Owner, err.Error())
+		}
+	}
+	return nil
+}
+
+// ListResourceActions list all resources and the actions that registered by the API routes
+func (p *rbacServiceImpl) ListResourceActions(ctx context.Context) (*apisv1.ListResourceActionsResponse, error) {
+	return &apisv1.ListResourceActionsResponse{ResourceActions: listResourceActions()}, nil
+}
+
+// SimulateCapabilities returns the menus and the actions that the users with the roles and permissions could access.
+// The roles and permissions belong to the project if the project name is not empty, otherwise they are platform level.
+func (p *rbacServiceImpl) SimulateCapabilities(ctx context.Context, req apisv1.SimulateCapabilitiesRequest) (*apisv1.SimulateCapabilitiesResponse, error) {
+	var permissionNames = make(map[string]string)
+	for _, name := range req.Permissions {
+		permissionNames[name] = name
+	}
+	for _, roleName := range req.Roles {
+		role := &model.Role{Name: roleName, Project: req.ProjectName}
+		if err := p.Store.Get(ctx, role); err != nil {
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				return nil, bcode.ErrRoleIsNotExist
+			}
+			return nil, err
+		}
+		for _, name := range role.Permissions {
+			permissionNames[name] = name
+		}
+	}
+	perms, err := p.listPermPolices(ctx, req.ProjectName, utils.MapKey2Array(permissionNames))
+	if err != nil {
+		return nil, err
+	}
+	if len(perms) != len(permissionNames) {
+		return nil, bcode.ErrRolePermissionCheckFailure
+	}
+	var res = &apisv1.SimulateCapabilitiesResponse{Permissions: []apisv1.PermissionBase{}}
+	for _, perm := range perms {
+		res.Permissions = append(res.Permissions, *assembler.ConvertPermission2DTO(perm))
+	}
+	// the same as the default permissions of the login users
+	perms = append(perms, &model.Permission{
+		Name:      "cloudshell",
+		Resources: []string{"cloudshell"},
+		Actions:   []string{"*"},
+		Effect:    "Allow",
+	})
+
+	allowed := func(resource, action string) bool {
+		ra := &RequestResourceAction{}
+		ra.SetResourceWithName(resource, func(name string) string {
+			if name == ResourceMaps["project"].pathName {
+				return req.ProjectName
+			}
+			return ""
+		})
+		ra.SetActions([]string{action})
+		return ra.Match(perms)
+	}
+	for _, menu := range uiMenuCapabilities {
+		menu.Visible = allowed(menu.Resource, menu.Action)
+		res.Menus = append(res.Menus, menu)
+	}
+	for _, resourceAction := range listResourceActions() {
+		path, err := checkResourcePath(resourceAction.Resource)
+		if err != nil {
+			continue
+		}
+		for _, action := range resourceAction.Actions {
+			res.ResourceActions = append(res.ResourceActions, apisv1.ResourceActionCapability{
+				Resource: resourceAction.Resource,
+				Action:   action,
+				Allowed:  allowed(path, action),
+			})
+		}
+	}
+	return res, nil
+}
+
+func (p *rbacServiceImpl) listProjectRoleTemplates(ctx context.Context) ([]*model.ProjectRoleTemplate, error) {
+	entities, err := p.Store.List(ctx, &model.ProjectRoleTemplate{}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var templates []*model.ProjectRoleTemplate
+	for _, entity := range entities {
+		templates = append(templates, entity.(*model.ProjectRoleTemplate))
+	}
+	return templates, nil
+}
+
+// ListProjectRoleTemplates list the project role templates defined by the platform admin
+func (p *rbacServiceImpl) ListProjectRoleTemplates(ctx context.Context) (*apisv1.ListProjectRoleTemplatesResponse, error) {
+	templates, err := p.listProjectRoleTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListProjectRoleTemplatesResponse{Templates: []*apisv1.ProjectRoleTemplateBase{}}
+	for _, template := range templates {
+		res.Templates = append(res.Templates, assembler.ConvertProjectRoleTemplate2DTO(template))
+	}
+	return res, nil
+}
+
+// CreateProjectRoleTemplate create a project role template, the role will be created in the new projects.
+// If the SyncToProjects is true, the role will be created in all existing projects too.
+func (p *rbacServiceImpl) CreateProjectRoleTemplate(ctx context.Context, req apisv1.CreateProjectRoleTemplateRequest) (*apisv1.ProjectRoleTemplateBase, error) {
+	for _, role := range defaultProjectRoles {
+		if role.Name == req.Name {
+			return nil, bcode.ErrProjectRoleTemplateIsExist
+		}
+	}
+	if err := checkProjectRoleTemplatePermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+	template := &model.ProjectRoleTemplate{
+		Name:        req.Name,
+		Alias:       req.Alias,
+		Permissions: req.Permissions,
+	}
+	if err := p.Store.Add(ctx, template); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrProjectRoleTemplateIsExist
+		}
+		return nil, err
+	}
+	if req.SyncToProjects {
+		if err := p.syncProjectRoleTemplate(ctx, template); err != nil {
+			return nil, err
+		}
+	}
+	return assembler.ConvertProjectRoleTemplate2DTO(template), nil
+}
+
+// UpdateProjectRoleTemplate update a project role template.
+// If the SyncToProjects is true, the role in all existing projects will be updated too.
+func (p *rbacServiceImpl) UpdateProjectRoleTemplate(ctx context.Context, templateName string, req apisv1.UpdateProjectRoleTemplateRequest) (*apisv1.ProjectRoleTemplateBase, error) {
+	if err := checkProjectRoleTemplatePermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+	template := &model.ProjectRoleTemplate{Name: templateName}
+	if err := p.Store.Get(ctx, template); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrProjectRoleTemplateIsNotExist
+		}
+		return nil, err
+	}
+	template.Alias = req.Alias
+	template.Permissions = req.Permissions
+	if err := p.Store.Put(ctx, template); err != nil {
+		return nil, err
+	}
+	if req.SyncToProjects {
+		if err := p.syncProjectRoleTemplate(ctx, template); err != nil {
+			return nil, err
+		}
+	}
+	return assembler.ConvertProjectRoleTemplate2DTO(template), nil
+}
+
+// DeleteProjectRoleTemplate delete a project role template, the roles created in the existing projects are kept.
+func (p *rbacServiceImpl) DeleteProjectRoleTemplate(ctx context.Context, templateName string) error {
+	if err := p.Store.Delete(ctx, &model.ProjectRoleTemplate{Name: templateName}); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrProjectRoleTemplateIsNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+// syncProjectRoleTemplate create or update the role of the template in all existing projects
+func (p *rbacServiceImpl) syncProjectRoleTemplate(ctx context.Context, template *model.ProjectRoleTemplate) error {
+	projects, err := p.Store.List(ctx, &model.Project{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range projects {
+		project := entity.(*model.Project)
+		role := &model.Role{Name: template.Name, Project: project.Name}
+		if err := p.Store.Get(ctx, role); err != nil {
+			if !errors.Is(err, datastore.ErrRecordNotExist) {
+				return err
+			}
+			role.Alias = template.Alias
+			role.Permissions = template.Permissions
+			if err := p.Store.Add(ctx, role); err != nil {
+				return fmt.Errorf("fail to create the role %s in the project %s %w", role.Name, project.Name, err)
+			}
+			continue
+		}
+		role.Alias = template.Alias
+		role.Permissions = template.Permissions
+		if err := p.Store.Put(ctx, role); err != nil {
+			return fmt.Errorf("fail to update the role %s in the project %s %w", role.Name, project.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkProjectRoleTemplatePermissions the permissions of the template must be the default project permissions,
+// because only them are created in every project.
+func checkProjectRoleTemplatePermissions(permissions []string) error {
+	if len(permissions) == 0 {
+		return bcode.ErrRolePermissionCheckFailure
+	}
+	for _, permission := range permissions {
+		var exist bool
+		for _, template := range defaultProjectPermissionTemplate {
+			if template.Name == permission {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			return bcode.ErrRolePermissionCheckFailure
+		}
+	}
+	return nil
+}
+
+// isAdminEquivalent checks whether any of the permissions allows changing everything or granting the privileges to the users
+func isAdminEquivalent(perms ...*model.Permission) bool {
+	for _, perm := range perms {
+		if perm == nil || (perm.Effect != "" && !strings.EqualFold(perm.Effect, "allow")) {
+			continue
+		}
+		readOnly := true
+		for _, action := range perm.Actions {
+			if action != "list" && action != "detail" {
+				readOnly = false
+			}
+		}
+		if readOnly {
+			continue
+		}
+		for _, resource := range perm.Resources {
+			if utils.StringsContain(adminEquivalentResourceTypes, ParseResourceName(resource).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isApprovedChange(ctx context.Context) bool {
+	approved, _ := ctx.Value(approvedChangeKey{}).(bool)
+	return approved
+}
+
+// requestRBACApproval saves the change as a pending approval, it takes effect after another user approves it
+func (p *rbacServiceImpl) requestRBACApproval(ctx context.Context, kind, operation, resourceName string, payload interface{}) error {
+	requester, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	approval := &model.RBACApproval{
+		Name:         apiserverutils.GenerateVersion(kind),
+		Kind:         kind,
+		Operation:    operation,
+		ResourceName: resourceName,
+		Payload:      string(data),
+		Requester:    requester,
+		Status:       model.RBACApprovalStatusPending,
+	}
+	if err := p.Store.Add(ctx, approval); err != nil {
+		return err
+	}
+	klog.Infof("the %s of the %s %s requested by %s is pending approval %s", operation, kind, resourceName, requester, approval.Name)
+	return bcode.ErrRBACChangePendingApproval
+}
+
+func (p *rbacServiceImpl) ListRBACApprovals(ctx context.Context, status string) (*apisv1.ListRBACApprovalsResponse, error) {
+	entities, err := p.Store.List(ctx, &model.RBACApproval{Status: status}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var res = apisv1.ListRBACApprovalsResponse{Approvals: []*apisv1.RBACApprovalBase{}}
+	for _, entity := range entities {
+		res.Approvals = append(res.Approvals, assembler.ConvertRBACApproval2DTO(entity.(*model.RBACApproval)))
+	}
+	return &res, nil
+}
+
+// getPendingRBACApproval gets the approval that could be reviewed by the login user
+func (p *rbacServiceImpl) getPendingRBACApproval(ctx context.Context, approvalName string) (*model.RBACApproval, string, error) {
+	approval := &model.RBACApproval{Name: approvalName}
+	if err := p.Store.Get(ctx, approval); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, "", bcode.ErrRBACApprovalNotExist
+		}
+		return nil, "", err
+	}
+	if approval.Status != model.RBACApprovalStatusPending {
+		return nil, "", bcode.ErrRBACApprovalIsClosed
+	}
+	reviewer, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if reviewer == "" || reviewer == approval.Requester {
+		return nil, "", bcode.ErrRBACApprovalBySelf
+	}
+	return approval, reviewer, nil
+}
+
+func (p *rbacServiceImpl) ApproveRBACChange(ctx context.Context, approvalName string, req apisv1.ReviewRBACApprovalRequest) (*apisv1.RBACApprovalBase, error) {
+	approval, reviewer, err := p.getPendingRBACApproval(ctx, approvalName)
+	if err != nil {
+		return nil, err
+	}
+	approvedCtx := context.WithValue(ctx, approvedChangeKey{}, true)
+	switch approval.Kind + "/" + approval.Operation {
+	case "role/create":
+		var createReq apisv1.CreateRoleRequest
+		if err := json.Unmarshal([]byte(approval.Payload), &createReq); err != nil {
+			return nil, err
+		}
+		_, err = p.CreateRole(approvedCtx, "", createReq)
+	case "role/update":
+		var updateReq apisv1.UpdateRoleRequest
+		if err := json.Unmarshal([]byte(approval.Payload), &updateReq); err != nil {
+			return nil, err
+		}
+		_, err = p.UpdateRole(approvedCtx, "", approval.ResourceName, updateReq)
+	case "role/grant-targets":
+		var grantReq apisv1.GrantTargetsRequest
+		if err := json.Unmarshal([]byte(approval.Payload), &grantReq); err != nil {
+			return nil, err
+		}
+		_, err = p.GrantTargets(approvedCtx, approval.ResourceName, grantReq)
+	case "permission/create":
+		var createReq apisv1.CreatePermissionRequest
+		if err := json.Unmarshal([]byte(approval.Payload), &createReq); err != nil {
+			return nil, err
+		}
+		_, err = p.CreatePermission(approvedCtx, "", createReq)
+	case "permission/update":
+		var updateReq apisv1.UpdatePermissionRequest
+		if err := json.Unmarshal([]byte(approval.Payload), &updateReq); err != nil {
+			return nil, err
+		}
+		_, err = p.UpdatePermission(approvedCtx, "", approval.ResourceName, &updateReq)
+	default:
+		err = fmt.Errorf("the approval kind %s and operation %s are not supported", approval.Kind, approval.Operation)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.closeRBACApproval(ctx, approval, model.RBACApprovalStatusApproved, reviewer, req.Comment)
+}
+
+func (p *rbacServiceImpl) RejectRBACChange(ctx context.Context, approvalName string, req apisv1.ReviewRBACApprovalRequest) (*apisv1.RBACApprovalBase, error) {
+	approval, reviewer, err := p.getPendingRBACApproval(ctx, approvalName)
+	if err != nil {
+		return nil, err
+	}
+	return p.closeRBACApproval(ctx, approval, model.RBACApprovalStatusRejected, reviewer, req.Comment)
+}
+
+func (p *rbacServiceImpl) closeRBACApproval(ctx context.Context, approval *model.RBACApproval, status, reviewer, comment string) (*apisv1.RBACApprovalBase, error) {
+	approval.Status = status
+	approval.Reviewer = reviewer
+	approval.Comment = comment
+	if err := p.Store.Put(ctx, approval); err != nil {
+		return nil, err
+	}
+	return assembler.ConvertRBACApproval2DTO(approval), nil
 }
 
 // ResourceName it is similar to ARNs
 // <type>:<value>/<type>:<value>
+// The value could be a label selector like [label:team=payments] to match the resources by labels.
 type ResourceName struct {
 	Type  string
 	Value string
-	Next  *ResourceName
+	// Labels the labels of the requested resource, it is used to match the label selector
+	Labels map[string]string
+	Next   *ResourceName
 }
 
 // ParseResourceName parse string to ResourceName
 func ParseResourceName(resource string) *ResourceName {
-	RNs := strings.Split(resource, "/")
+	RNs := splitResourceName(resource)
 	var resourceName = ResourceName{}
 	var current = &resourceName
 	for _, rn := range RNs {
-		rnData := strings.Split(rn, ":")
+		rnData := strings.SplitN(rn, ":", 2)
 		if len(rnData) == 2 {
 			current.Type = rnData[0]
 			current.Value = rnData[1]
@@ -976,7 +2060,7 @@ func (r *ResourceName) Match(target *ResourceName) bool {
 		if current.Type != currentTarget.Type {
 			return false
 		}
-		if current.Value != currentTarget.Value && current.Value != "*" {
+		if !current.matchValue(currentTarget) {
 			return false
 		}
 		current = current.Next
@@ -988,6 +2072,22 @@ func (r *ResourceName) Match(target *ResourceName) bool {
 	return true
 }
 
+// matchValue the value matches the target value if they are the same, or the label selector matches the target labels
+func (r *ResourceName) matchValue(target *ResourceName) bool {
+	if r.Value == "*" || r.Value == target.Value {
+		return true
+	}
+	if !strings.HasPrefix(r.Value, labelSelectorPrefix) || target.Value == "*" || target.Labels == nil {
+		return false
+	}
+	selector, err := parseLabelSelector(r.Value)
+	if err != nil {
+		klog.Warningf("the resource label selector %s is invalid: %s", r.Value, err.Error())
+		return false
+	}
+	return selector.Matches(labels.Set(target.Labels))
+}
+
 func (r *ResourceName) String() string {
 	strBuilder := &strings.Builder{}
 	current := r
@@ -1018,6 +2118,18 @@ func (r *RequestResourceAction) SetResourceWithName(resource string, pathParamet
 	r.resource = ParseResourceName(resource)
 }
 
+// SetResourceLabels set the labels of the requested resource with the type, to match the label selectors of the policies
+func (r *RequestResourceAction) SetResourceLabels(resourceType string, resourceLabels map[string]string) {
+	for current := r.resource; current != nil && current.Type != ""; current = current.Next {
+		if current.Type == resourceType {
+			current.Labels = resourceLabels
+			if current.Labels == nil {
+				current.Labels = map[string]string{}
+			}
+		}
+	}
+}
+
 // GetResource return the resource after be formated
 func (r *RequestResourceAction) GetResource() *ResourceName {
 	return r.resource
@@ -1044,24 +2156,197 @@ func (r *RequestResourceAction) match(policy *model.Permission) bool {
 }
 
 // Match determines whether the request resources and actions matches the user permission set.
+// The policies are evaluated from the highest priority to the lowest, the first matched priority decides the result.
+// In the same priority, the Deny policies win over the Allow policies. The project-scoped policies never outrank the
+// matched platform Deny policies, so the project admins could not carve the allows out of the platform denies.
 func (r *RequestResourceAction) Match(policies []*model.Permission) bool {
+	var platformDeny *int
 	for _, policy := range policies {
-		if strings.EqualFold(policy.Effect, "deny") {
-			if r.match(policy) {
-				return false
+		if policy.Project == "" && strings.EqualFold(policy.Effect, "deny") && r.match(policy) {
+			if platformDeny == nil || policy.Priority < *platformDeny {
+				priority := policy.Priority
+				platformDeny = &priority
 			}
 		}
 	}
-	for _, policy := range policies {
-		if strings.EqualFold(policy.Effect, "allow") || policy.Effect == "" {
-			if r.match(policy) {
-				return true
+	priority := func(policy *model.Permission) int {
+		if policy.Project != "" && platformDeny != nil && policy.Priority > *platformDeny {
+			return *platformDeny
+		}
+		return policy.Priority
+	}
+	sorted := make([]*model.Permission, len(policies))
+	copy(sorted, policies)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return priority(sorted[i]) > priority(sorted[j])
+	})
+	for i := 0; i < len(sorted); {
+		j := i
+		var allowed bool
+		for ; j < len(sorted) && priority(sorted[j]) == priority(sorted[i]); j++ {
+			policy := sorted[j]
+			if !r.match(policy) {
+				continue
+			}
+			if strings.EqualFold(policy.Effect, "deny") {
+				return false
+			}
+			if strings.EqualFold(policy.Effect, "allow") || policy.Effect == "" {
+				allowed = true
 			}
 		}
+		if allowed {
+			return true
+		}
+		i = j
 	}
 	return false
 }
 
+// splitResourceName splits the resource name by "/", the "/" in the label selector such as [label:app.kubernetes.io/name=x] is kept
+func splitResourceName(resource string) []string {
+	var RNs []string
+	var depth, start int
+	for i, c := range resource {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			if depth > 0 {
+				depth--
+			}
+		case '/':
+			if depth == 0 {
+				RNs = append(RNs, resource[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(RNs, resource[start:])
+}
+
+func parseLabelSelector(value string) (labels.Selector, error) {
+	if !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("the label selector must be wrapped by %s and ]", labelSelectorPrefix)
+	}
+	return labels.Parse(strings.TrimSuffix(strings.TrimPrefix(value, labelSelectorPrefix), "]"))
+}
+
+// checkResourceSelectors checks the label selectors in the resources are valid
+func checkResourceSelectors(resources []string) error {
+	for _, resource := range resources {
+		for current := ParseResourceName(resource); current != nil && current.Type != ""; current = current.Next {
+			if !strings.HasPrefix(current.Value, labelSelectorPrefix) {
+				continue
+			}
+			if _, err := parseLabelSelector(current.Value); err != nil {
+				return bcode.ErrInvalidResourceSelector
+			}
+		}
+	}
+	return nil
+}
+
+// SyncProjectUserPrivileges projects the project roles of the user to the Kubernetes RBAC in the namespaces of the project.
+// The user could write the resources if the roles allow deploying the applications, otherwise the user could only read them.
+// The privileges are revoked if the user is not a member of the project any more.
+func (p *rbacServiceImpl) SyncProjectUserPrivileges(ctx context.Context, projectName, userName string) error {
+	// the project roles of the user are changed, the cached permissions are stale
+	p.purgePermissionCache()
+	if !p.PropagateToKubeRBAC {
+		return nil
+	}
+	project := &model.Project{Name: projectName}
+	if err := p.Store.Get(ctx, project); err != nil {
+		return err
+	}
+	projectUser := &model.ProjectUser{ProjectName: projectName, Username: userName}
+	member := true
+	if err := p.Store.Get(ctx, projectUser); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		member = false
+	}
+	identity := &auth.Identity{User: userName}
+	var readOnly bool
+	if member {
+		permissions, err := p.GetUserPermissions(ctx, &model.User{Name: userName}, projectName, false)
+		if err != nil {
+			return err
+		}
+		readOnly = checkReadOnly(projectName, permissions)
+	}
+	for _, revokeReadOnly := range []bool{true, false} {
+		if member && revokeReadOnly == readOnly {
+			continue
+		}
+		pds, err := listProjectPrivileges(ctx, p.Store, project, revokeReadOnly)
+		if err != nil {
+			return err
+		}
+		writer := &bytes.Buffer{}
+		if err := auth.RevokePrivileges(ctx, p.KubeClient, pds, identity, writer); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		klog.Infof("RevokePrivileges: %s", writer.String())
+	}
+	if !member {
+		return nil
+	}
+	pds, err := listProjectPrivileges(ctx, p.Store, project, readOnly)
+	if err != nil {
+		return err
+	}
+	writer := &bytes.Buffer{}
+	if err := auth.GrantPrivileges(ctx, p.KubeClient, pds, identity, writer, auth.WithReplace); err != nil {
+		return err
+	}
+	klog.Infof("GrantPrivileges: %s", writer.String())
+	return nil
+}
+
+// syncProjectPrivileges syncs the Kubernetes privileges of all members after the project roles changed
+func (p *rbacServiceImpl) syncProjectPrivileges(ctx context.Context, projectName string) {
+	if !p.PropagateToKubeRBAC {
+		return
+	}
+	entities, err := p.Store.List(ctx, &model.ProjectUser{ProjectName: projectName}, nil)
+	if err != nil {
+		klog.Warningf("failed to list the users of the project %s: %s", projectName, err.Error())
+		return
+	}
+	for _, entity := range entities {
+		userName := entity.(*model.ProjectUser).Username
+		if err := p.SyncProjectUserPrivileges(ctx, projectName, userName); err != nil {
+			klog.Warningf("failed to sync the kubernetes privileges of the user %s in the project %s: %s", userName, projectName, err.Error())
+		}
+	}
+}
+
+// listProjectPrivileges lists the privileges of the namespaces that belong to the project, including the targets and the environments
+func listProjectPrivileges(ctx context.Context, store datastore.DataStore, project *model.Project, readOnly bool) ([]auth.PrivilegeDescription, error) {
+	var pds []auth.PrivilegeDescription
+	targets, err := store.List(ctx, &model.Target{Project: project.Name}, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range targets {
+		if target := entity.(*model.Target); target.Cluster != nil {
+			pds = append(pds, &auth.ScopedPrivilege{Cluster: target.Cluster.ClusterName, Namespace: target.Cluster.Namespace, ReadOnly: readOnly})
+		}
+	}
+	envs, err := store.List(ctx, &model.Env{Project: project.Name}, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range envs {
+		pds = append(pds, &auth.ApplicationPrivilege{Cluster: types.ClusterLocalName, Namespace: entity.(*model.Env).Namespace, ReadOnly: readOnly})
+	}
+	pds = append(pds, &auth.ApplicationPrivilege{Cluster: types.ClusterLocalName, Namespace: project.GetNamespace(), ReadOnly: readOnly})
+	return pds, nil
+}
+
 // managePrivilegesForAdminUser grant or revoke privileges for admin user
 func managePrivilegesForAdminUser(ctx context.Context, cli client.Client, roleName string, revoke bool) error {
 	p := &auth.ScopedPrivilege{Cluster: types.ClusterLocalName}
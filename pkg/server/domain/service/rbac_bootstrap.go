@@ -0,0 +1,318 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/types"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// rbacBootstrapConfigMapKey the key of the bootstrap file in the ConfigMap
+const rbacBootstrapConfigMapKey = "rbac.yaml"
+
+// the sources of the declarative RBAC, they are set from the server config
+var (
+	rbacBootstrapFile      string
+	rbacBootstrapConfigMap string
+)
+
+// RBACBootstrap is the declarative RBAC reconciled on every start of the server
+type RBACBootstrap struct {
+	Users       []BootstrapUser       `json:"users,omitempty"`
+	Projects    []BootstrapProject    `json:"projects,omitempty"`
+	Permissions []BootstrapPermission `json:"permissions,omitempty"`
+	Roles       []BootstrapRole       `json:"roles,omitempty"`
+}
+
+// BootstrapUser the user and its platform roles, the password only takes effect when creating the user
+type BootstrapUser struct {
+	Name     string   `json:"name"`
+	Alias    string   `json:"alias,omitempty"`
+	Email    string   `json:"email,omitempty"`
+	Password string   `json:"password,omitempty"`
+	Disabled bool     `json:"disabled,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+}
+
+// BootstrapProject the project and its members
+type BootstrapProject struct {
+	Name        string            `json:"name"`
+	Alias       string            `json:"alias,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Members     []BootstrapMember `json:"members,omitempty"`
+}
+
+// BootstrapMember binds the project roles to the user
+type BootstrapMember struct {
+	User  string   `json:"user"`
+	Roles []string `json:"roles"`
+}
+
+// BootstrapPermission the platform permission, or the project permission if the project is set
+type BootstrapPermission struct {
+	Name      string   `json:"name"`
+	Alias     string   `json:"alias,omitempty"`
+	Project   string   `json:"project,omitempty"`
+	Resources []string `json:"resources"`
+	Actions   []string `json:"actions"`
+	Effect    string   `json:"effect,omitempty"`
+}
+
+// BootstrapRole the platform role, the project role if the project is set,
+// or the cross-project role if the projects or the project selector is set
+type BootstrapRole struct {
+	Name            string            `json:"name"`
+	Alias           string            `json:"alias,omitempty"`
+	Project         string            `json:"project,omitempty"`
+	Permissions     []string          `json:"permissions"`
+	Projects        []string          `json:"projects,omitempty"`
+	ProjectSelector map[string]string `json:"projectSelector,omitempty"`
+}
+
+type rbacBootstrapImpl struct {
+	Store          datastore.DataStore `inject:"datastore"`
+	KubeClient     client.Client       `inject:"kubeClient"`
+	ProjectService ProjectService      `inject:""`
+}
+
+// NewRBACBootstrap creates the initializer of the declarative RBAC
+func NewRBACBootstrap() DataInit {
+	return &rbacBootstrapImpl{}
+}
+
+// Init reconciles the declarative RBAC, the records not in the bootstrap file are kept
+func (b *rbacBootstrapImpl) Init(ctx context.Context) error {
+	bootstrap, err := b.loadBootstrap(ctx)
+	if err != nil || bootstrap == nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, &apisv1.CtxKeyUser, model.DefaultAdminUserName)
+	if err := b.reconcile(ctx, bootstrap); err != nil {
+		return fmt.Errorf("failed to bootstrap the RBAC %w", err)
+	}
+	klog.Infof("the RBAC is bootstrapped: %d users, %d projects, %d permissions, %d roles",
+		len(bootstrap.Users), len(bootstrap.Projects), len(bootstrap.Permissions), len(bootstrap.Roles))
+	return nil
+}
+
+func (b *rbacBootstrapImpl) loadBootstrap(ctx context.Context) (*RBACBootstrap, error) {
+	var content []byte
+	switch {
+	case rbacBootstrapFile != "":
+		data, err := os.ReadFile(rbacBootstrapFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the RBAC bootstrap file %w", err)
+		}
+		content = data
+	case rbacBootstrapConfigMap != "":
+		namespace, name := types.DefaultKubeVelaNS, rbacBootstrapConfigMap
+		if strings.Contains(rbacBootstrapConfigMap, "/") {
+			namespace, name, _ = strings.Cut(rbacBootstrapConfigMap, "/")
+		}
+		var cm corev1.ConfigMap
+		if err := b.KubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &cm); err != nil {
+			return nil, fmt.Errorf("failed to get the RBAC bootstrap ConfigMap %w", err)
+		}
+		content = []byte(cm.Data[rbacBootstrapConfigMapKey])
+	default:
+		return nil, nil
+	}
+	var bootstrap RBACBootstrap
+	if err := yaml.Unmarshal(content, &bootstrap); err != nil {
+		return nil, fmt.Errorf("invalid RBAC bootstrap file %w", err)
+	}
+	return &bootstrap, nil
+}
+
+// reconcile creates the users and projects first, so that the permissions, roles and members could reference them
+func (b *rbacBootstrapImpl) reconcile(ctx context.Context, bootstrap *RBACBootstrap) error {
+	for _, user := range bootstrap.Users {
+		if err := b.reconcileUser(ctx, user); err != nil {
+			return fmt.Errorf("user %s: %w", user.Name, err)
+		}
+	}
+	for _, project := range bootstrap.Projects {
+		if err := b.reconcileProject(ctx, project); err != nil {
+			return fmt.Errorf("project %s: %w", project.Name, err)
+		}
+	}
+	for _, perm := range bootstrap.Permissions {
+		if err := b.reconcilePermission(ctx, perm); err != nil {
+			return fmt.Errorf("permission %s: %w", perm.Name, err)
+		}
+	}
+	for _, role := range bootstrap.Roles {
+		if err := b.reconcileRole(ctx, role); err != nil {
+			return fmt.Errorf("role %s: %w", role.Name, err)
+		}
+	}
+	for _, project := range bootstrap.Projects {
+		for _, member := range project.Members {
+			if err := b.reconcileMember(ctx, project.Name, member); err != nil {
+				return fmt.Errorf("member %s of the project %s: %w", member.User, project.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *rbacBootstrapImpl) reconcileUser(ctx context.Context, declared BootstrapUser) error {
+	user := &model.User{Name: declared.Name}
+	err := b.Store.Get(ctx, user)
+	if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return err
+	}
+	exist := err == nil
+	user.Alias = declared.Alias
+	user.Disabled = declared.Disabled
+	user.UserRoles = declared.Roles
+	if declared.Email != "" {
+		user.Email = declared.Email
+	}
+	if exist {
+		return b.Store.Put(ctx, user)
+	}
+	if declared.Password != "" {
+		hash, err := GeneratePasswordHash(declared.Password)
+		if err != nil {
+			return err
+		}
+		user.Password = hash
+	}
+	return b.Store.Add(ctx, user)
+}
+
+func (b *rbacBootstrapImpl) reconcileProject(ctx context.Context, declared BootstrapProject) error {
+	project := &model.Project{Name: declared.Name}
+	if err := b.Store.Get(ctx, project); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		_, err := b.ProjectService.CreateProject(ctx, apisv1.CreateProjectRequest{
+			Name:        declared.Name,
+			Alias:       declared.Alias,
+			Description: declared.Description,
+			Owner:       declared.Owner,
+			Namespace:   declared.Namespace,
+			Labels:      declared.Labels,
+		})
+		return err
+	}
+	if project.Alias == declared.Alias && project.Description == declared.Description &&
+		(declared.Owner == "" || project.Owner == declared.Owner) && reflect.DeepEqual(project.Labels, declared.Labels) {
+		return nil
+	}
+	_, err := b.ProjectService.UpdateProject(ctx, declared.Name, apisv1.UpdateProjectRequest{
+		Alias:       declared.Alias,
+		Description: declared.Description,
+		Owner:       declared.Owner,
+		Labels:      declared.Labels,
+	})
+	return err
+}
+
+func (b *rbacBootstrapImpl) reconcilePermission(ctx context.Context, declared BootstrapPermission) error {
+	if len(declared.Resources) == 0 || len(declared.Actions) == 0 {
+		return bcode.ErrRolePermissionCheckFailure
+	}
+	if declared.Project != "" {
+		if err := b.Store.Get(ctx, &model.Project{Name: declared.Project}); err != nil {
+			return bcode.ErrProjectIsNotExist
+		}
+	}
+	effect := declared.Effect
+	if effect == "" {
+		effect = "Allow"
+	}
+	perm := &model.Permission{Name: declared.Name, Project: declared.Project}
+	err := b.Store.Get(ctx, perm)
+	if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return err
+	}
+	exist := err == nil
+	perm.Alias = declared.Alias
+	perm.Resources = declared.Resources
+	perm.Actions = declared.Actions
+	perm.Effect = effect
+	if exist {
+		return b.Store.Put(ctx, perm)
+	}
+	return b.Store.Add(ctx, perm)
+}
+
+func (b *rbacBootstrapImpl) reconcileRole(ctx context.Context, declared BootstrapRole) error {
+	if len(declared.Permissions) == 0 {
+		return bcode.ErrRolePermissionCheckFailure
+	}
+	project := declared.Project
+	if len(declared.Projects) > 0 || len(declared.ProjectSelector) > 0 {
+		project = model.RoleScopeCrossProject
+	} else if project != "" {
+		if err := b.Store.Get(ctx, &model.Project{Name: project}); err != nil {
+			return bcode.ErrProjectIsNotExist
+		}
+	}
+	role := &model.Role{Name: declared.Name, Project: project}
+	err := b.Store.Get(ctx, role)
+	if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return err
+	}
+	exist := err == nil
+	role.Alias = declared.Alias
+	role.Permissions = declared.Permissions
+	role.Projects = declared.Projects
+	role.ProjectSelector = declared.ProjectSelector
+	if exist {
+		return b.Store.Put(ctx, role)
+	}
+	return b.Store.Add(ctx, role)
+}
+
+func (b *rbacBootstrapImpl) reconcileMember(ctx context.Context, projectName string, declared BootstrapMember) error {
+	member := &model.ProjectUser{ProjectName: projectName, Username: declared.User}
+	if err := b.Store.Get(ctx, member); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		_, err := b.ProjectService.AddProjectUser(ctx, projectName, apisv1.AddProjectUserRequest{UserName: declared.User, UserRoles: declared.Roles})
+		return err
+	}
+	if reflect.DeepEqual(member.UserRoles, declared.Roles) {
+		return nil
+	}
+	_, err := b.ProjectService.UpdateProjectUser(ctx, projectName, declared.User, apisv1.UpdateProjectUserRequest{UserRoles: declared.Roles})
+	return err
+}
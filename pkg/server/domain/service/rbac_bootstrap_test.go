@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+var rbacBootstrapYAML = `
+users:
+- name: bootstrap-owner
+  alias: Owner
+  password: Bootstrap123
+  roles: ["admin"]
+- name: bootstrap-dev
+  email: dev@example.com
+projects:
+- name: bootstrap-project
+  alias: Bootstrap
+  owner: bootstrap-owner
+  labels:
+    team: platform
+  members:
+  - user: bootstrap-dev
+    roles: ["release-manager"]
+permissions:
+- name: release
+  project: bootstrap-project
+  resources: ["project:bootstrap-project/application:*/envBinding:*"]
+  actions: ["deploy"]
+roles:
+- name: release-manager
+  project: bootstrap-project
+  permissions: ["release"]
+`
+
+var _ = Describe("Test the RBAC bootstrap", func() {
+	var (
+		ds        datastore.DataStore
+		bootstrap *rbacBootstrapImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "rbac-bootstrap-test-kubevela"})
+		Expect(err).Should(BeNil())
+		bootstrap = &rbacBootstrapImpl{Store: ds, KubeClient: k8sClient, ProjectService: NewTestProjectService(ds, k8sClient)}
+	})
+	AfterEach(func() {
+		rbacBootstrapFile = ""
+	})
+
+	It("Test reconcile the bootstrap file", func() {
+		rbacBootstrapFile = ""
+		Expect(bootstrap.Init(context.TODO())).Should(BeNil())
+
+		rbacBootstrapFile = filepath.Join(GinkgoT().TempDir(), "rbac.yaml")
+		Expect(os.WriteFile(rbacBootstrapFile, []byte(rbacBootstrapYAML), 0600)).Should(BeNil())
+		Expect(bootstrap.Init(context.TODO())).Should(BeNil())
+
+		owner := &model.User{Name: "bootstrap-owner"}
+		Expect(ds.Get(context.TODO(), owner)).Should(BeNil())
+		Expect(owner.UserRoles).Should(Equal([]string{"admin"}))
+		Expect(owner.Password).ShouldNot(BeEmpty())
+		project := &model.Project{Name: "bootstrap-project"}
+		Expect(ds.Get(context.TODO(), project)).Should(BeNil())
+		Expect(project.Owner).Should(Equal("bootstrap-owner"))
+		Expect(project.Labels["team"]).Should(Equal("platform"))
+		Expect(ds.Get(context.TODO(), &model.Role{Name: "release-manager", Project: "bootstrap-project"})).Should(BeNil())
+		member := &model.ProjectUser{ProjectName: "bootstrap-project", Username: "bootstrap-dev"}
+		Expect(ds.Get(context.TODO(), member)).Should(BeNil())
+		Expect(member.UserRoles).Should(Equal([]string{"release-manager"}))
+
+		// reconcile again after the member is changed in the UI
+		member.UserRoles = []string{"project-viewer"}
+		Expect(ds.Put(context.TODO(), member)).Should(BeNil())
+		password := owner.Password
+		Expect(bootstrap.Init(context.TODO())).Should(BeNil())
+		Expect(ds.Get(context.TODO(), member)).Should(BeNil())
+		Expect(member.UserRoles).Should(Equal([]string{"release-manager"}))
+		Expect(ds.Get(context.TODO(), owner)).Should(BeNil())
+		Expect(owner.Password).Should(Equal(password))
+
+		Expect(os.WriteFile(rbacBootstrapFile, []byte("roles:\n- name: empty\n"), 0600)).Should(BeNil())
+		Expect(bootstrap.Init(context.TODO())).ShouldNot(BeNil())
+	})
+})
@@ -0,0 +1,188 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+// VelaUXPolicySpec the spec of the VelaUXPolicy, the roles, the permissions and the project members managed by GitOps.
+// Each record should be declared by only one policy, the records removed from a policy are deleted.
+type VelaUXPolicySpec struct {
+	Permissions []BootstrapPermission `json:"permissions,omitempty"`
+	Roles       []BootstrapRole       `json:"roles,omitempty"`
+	Bindings    []PolicyBinding       `json:"bindings,omitempty"`
+}
+
+// PolicyBinding binds the project roles to the user
+type PolicyBinding struct {
+	Project string   `json:"project"`
+	User    string   `json:"user"`
+	Roles   []string `json:"roles"`
+}
+
+// PolicyBundleService reconciles the VelaUXPolicies into the datastore
+type PolicyBundleService interface {
+	// ApplyPolicyBundle creates or updates the declared records and deletes the records removed from the policy
+	ApplyPolicyBundle(ctx context.Context, name string, generation int64, spec VelaUXPolicySpec) error
+	// DeletePolicyBundle deletes all records of the deleted policy
+	DeletePolicyBundle(ctx context.Context, name string) error
+}
+
+type policyBundleServiceImpl struct {
+	Store          datastore.DataStore `inject:"datastore"`
+	ProjectService ProjectService      `inject:""`
+}
+
+// NewPolicyBundleService new policy bundle service
+func NewPolicyBundleService() PolicyBundleService {
+	return &policyBundleServiceImpl{}
+}
+
+// ApplyPolicyBundle the declared records are reconciled in the same order as the RBAC bootstrap, the error is recorded
+// and the next reconciliation retries the whole policy
+func (p *policyBundleServiceImpl) ApplyPolicyBundle(ctx context.Context, name string, generation int64, spec VelaUXPolicySpec) error {
+	bundle, exist, err := p.getBundle(ctx, name)
+	if err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, &apisv1.CtxKeyUser, model.DefaultAdminUserName)
+	applied := &model.PolicyBundle{Name: name, Generation: generation}
+	reconcileErr := p.reconcile(ctx, spec, applied)
+	if reconcileErr == nil {
+		p.prune(ctx, bundle, applied)
+	} else {
+		// the records of the failed reconciliation are kept, so they are pruned after the policy is fixed
+		applied.Permissions = mergeKeys(bundle.Permissions, applied.Permissions)
+		applied.Roles = mergeKeys(bundle.Roles, applied.Roles)
+		applied.Bindings = mergeKeys(bundle.Bindings, applied.Bindings)
+		applied.Message = reconcileErr.Error()
+	}
+	if exist {
+		applied.ResourceVersion = bundle.ResourceVersion
+		err = p.Store.Put(ctx, applied)
+	} else {
+		err = p.Store.Add(ctx, applied)
+	}
+	if err != nil {
+		return err
+	}
+	return reconcileErr
+}
+
+func (p *policyBundleServiceImpl) reconcile(ctx context.Context, spec VelaUXPolicySpec, applied *model.PolicyBundle) error {
+	b := &rbacBootstrapImpl{Store: p.Store, ProjectService: p.ProjectService}
+	for _, perm := range spec.Permissions {
+		if err := b.reconcilePermission(ctx, perm); err != nil {
+			return fmt.Errorf("permission %s: %w", perm.Name, err)
+		}
+		applied.Permissions = append(applied.Permissions, perm.Project+"/"+perm.Name)
+	}
+	for _, role := range spec.Roles {
+		if err := b.reconcileRole(ctx, role); err != nil {
+			return fmt.Errorf("role %s: %w", role.Name, err)
+		}
+		project := role.Project
+		if len(role.Projects) > 0 || len(role.ProjectSelector) > 0 {
+			project = model.RoleScopeCrossProject
+		}
+		applied.Roles = append(applied.Roles, project+"/"+role.Name)
+	}
+	for _, binding := range spec.Bindings {
+		if err := b.reconcileMember(ctx, binding.Project, BootstrapMember{User: binding.User, Roles: binding.Roles}); err != nil {
+			return fmt.Errorf("binding of the user %s in the project %s: %w", binding.User, binding.Project, err)
+		}
+		applied.Bindings = append(applied.Bindings, binding.Project+"/"+binding.User)
+	}
+	return nil
+}
+
+// prune deletes the records of the previous reconciliation that are no longer declared, the members are removed
+// before the roles that they may reference
+func (p *policyBundleServiceImpl) prune(ctx context.Context, previous, applied *model.PolicyBundle) {
+	for _, key := range removedKeys(previous.Bindings, applied.Bindings) {
+		project, user, _ := strings.Cut(key, "/")
+		if err := p.ProjectService.DeleteProjectUser(ctx, project, user); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Warningf("failed to delete the member %s of the policy %s: %s", key, applied.Name, err.Error())
+		}
+	}
+	for _, key := range removedKeys(previous.Roles, applied.Roles) {
+		project, name, _ := strings.Cut(key, "/")
+		if err := p.Store.Delete(ctx, &model.Role{Project: project, Name: name}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Warningf("failed to delete the role %s of the policy %s: %s", key, applied.Name, err.Error())
+		}
+	}
+	for _, key := range removedKeys(previous.Permissions, applied.Permissions) {
+		project, name, _ := strings.Cut(key, "/")
+		if err := p.Store.Delete(ctx, &model.Permission{Project: project, Name: name}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Warningf("failed to delete the permission %s of the policy %s: %s", key, applied.Name, err.Error())
+		}
+	}
+}
+
+// DeletePolicyBundle deletes the records reconciled from the policy and the record of the policy
+func (p *policyBundleServiceImpl) DeletePolicyBundle(ctx context.Context, name string) error {
+	bundle, exist, err := p.getBundle(ctx, name)
+	if err != nil || !exist {
+		return err
+	}
+	ctx = context.WithValue(ctx, &apisv1.CtxKeyUser, model.DefaultAdminUserName)
+	p.prune(ctx, bundle, &model.PolicyBundle{Name: name})
+	if err := p.Store.Delete(ctx, bundle); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (p *policyBundleServiceImpl) getBundle(ctx context.Context, name string) (*model.PolicyBundle, bool, error) {
+	bundle := &model.PolicyBundle{Name: name}
+	if err := p.Store.Get(ctx, bundle); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bundle, false, nil
+		}
+		return nil, false, err
+	}
+	return bundle, true, nil
+}
+
+// removedKeys returns the previous keys not in the current keys
+func removedKeys(previous, current []string) []string {
+	kept := make(map[string]bool, len(current))
+	for _, key := range current {
+		kept[key] = true
+	}
+	var removed []string
+	for _, key := range previous {
+		if !kept[key] {
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
+
+func mergeKeys(previous, current []string) []string {
+	return append(current, removedKeys(previous, current)...)
+}
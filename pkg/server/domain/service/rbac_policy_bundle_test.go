@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+var _ = Describe("Test the policy bundles", func() {
+	var (
+		ds            datastore.DataStore
+		bundleService *policyBundleServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "policy-bundle-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		bundleService = &policyBundleServiceImpl{Store: ds, ProjectService: NewTestProjectService(ds, k8sClient)}
+	})
+
+	It("Test apply and prune the policy", func() {
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Project{Name: "bundle-project"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.User{Name: "bundle-dev"})).Should(BeNil())
+		spec := VelaUXPolicySpec{
+			Permissions: []BootstrapPermission{
+				{Name: "bundle-deploy", Project: "bundle-project", Resources: []string{"project:bundle-project/application:*"}, Actions: []string{"deploy"}},
+				{Name: "bundle-view", Project: "bundle-project", Resources: []string{"project:bundle-project/application:*"}, Actions: []string{"detail"}},
+			},
+			Roles:    []BootstrapRole{{Name: "bundle-releaser", Project: "bundle-project", Permissions: []string{"bundle-deploy", "bundle-view"}}},
+			Bindings: []PolicyBinding{{Project: "bundle-project", User: "bundle-dev", Roles: []string{"bundle-releaser"}}},
+		}
+		Expect(bundleService.ApplyPolicyBundle(ctx, "team-a", 1, spec)).Should(BeNil())
+		perm := &model.Permission{Name: "bundle-deploy", Project: "bundle-project"}
+		Expect(ds.Get(ctx, perm)).Should(BeNil())
+		Expect(perm.Effect).Should(Equal("Allow"))
+		Expect(ds.Get(ctx, &model.Role{Name: "bundle-releaser", Project: "bundle-project"})).Should(BeNil())
+		member := &model.ProjectUser{ProjectName: "bundle-project", Username: "bundle-dev"}
+		Expect(ds.Get(ctx, member)).Should(BeNil())
+		Expect(member.UserRoles).Should(Equal([]string{"bundle-releaser"}))
+		bundle := &model.PolicyBundle{Name: "team-a"}
+		Expect(ds.Get(ctx, bundle)).Should(BeNil())
+		Expect(bundle.Generation).Should(Equal(int64(1)))
+		Expect(bundle.Permissions).Should(Equal([]string{"bundle-project/bundle-deploy", "bundle-project/bundle-view"}))
+
+		By("the records removed from the policy are deleted")
+		spec.Permissions = spec.Permissions[:1]
+		spec.Roles[0].Permissions = []string{"bundle-deploy"}
+		spec.Bindings = nil
+		Expect(bundleService.ApplyPolicyBundle(ctx, "team-a", 2, spec)).Should(BeNil())
+		Expect(errors.Is(ds.Get(ctx, &model.Permission{Name: "bundle-view", Project: "bundle-project"}), datastore.ErrRecordNotExist)).Should(BeTrue())
+		Expect(errors.Is(ds.Get(ctx, &model.ProjectUser{ProjectName: "bundle-project", Username: "bundle-dev"}), datastore.ErrRecordNotExist)).Should(BeTrue())
+		Expect(ds.Get(ctx, &model.Permission{Name: "bundle-deploy", Project: "bundle-project"})).Should(BeNil())
+
+		By("the failed reconciliation is recorded and keeps the previous records")
+		spec.Roles = append(spec.Roles, BootstrapRole{Name: "bundle-empty", Project: "bundle-project"})
+		Expect(bundleService.ApplyPolicyBundle(ctx, "team-a", 3, spec)).ShouldNot(BeNil())
+		bundle = &model.PolicyBundle{Name: "team-a"}
+		Expect(ds.Get(ctx, bundle)).Should(BeNil())
+		Expect(bundle.Message).ShouldNot(BeEmpty())
+		Expect(bundle.Roles).Should(ContainElement("bundle-project/bundle-releaser"))
+
+		By("the records of the deleted policy are deleted")
+		Expect(bundleService.DeletePolicyBundle(ctx, "team-a")).Should(BeNil())
+		Expect(errors.Is(ds.Get(ctx, &model.Role{Name: "bundle-releaser", Project: "bundle-project"}), datastore.ErrRecordNotExist)).Should(BeTrue())
+		Expect(errors.Is(ds.Get(ctx, &model.Permission{Name: "bundle-deploy", Project: "bundle-project"}), datastore.ErrRecordNotExist)).Should(BeTrue())
+		Expect(errors.Is(ds.Get(ctx, &model.PolicyBundle{Name: "team-a"}), datastore.ErrRecordNotExist)).Should(BeTrue())
+		Expect(bundleService.DeletePolicyBundle(ctx, "team-a")).Should(BeNil())
+	})
+})
@@ -18,19 +18,28 @@ package service
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/emicklei/go-restful/v3"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/changefeed"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	apiserverutils "github.com/kubevela/velaux/pkg/server/utils"
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
 
@@ -96,9 +105,31 @@ var _ = Describe("Test rbac service", func() {
 		rbacService := rbacServiceImpl{Store: ds, KubeClient: k8sClient}
 		err := rbacService.Init(context.TODO())
 		Expect(err).Should(BeNil())
-		policies, err := rbacService.ListPermissions(context.TODO(), "")
+		policies, err := rbacService.ListPermissions(context.TODO(), "", 0, 0, apisv1.ListPermissionOptions{})
 		Expect(err).Should(BeNil())
-		Expect(len(policies)).Should(BeEquivalentTo(int64(10)))
+		Expect(len(policies.Permissions)).Should(BeEquivalentTo(int64(12)))
+		Expect(policies.Total).Should(BeEquivalentTo(int64(12)))
+
+		By("list the permissions by the page and the name")
+		policies, err = rbacService.ListPermissions(context.TODO(), "", 1, 5, apisv1.ListPermissionOptions{SortBy: "name", Order: "asc"})
+		Expect(err).Should(BeNil())
+		Expect(len(policies.Permissions)).Should(Equal(5))
+		Expect(policies.Total).Should(BeEquivalentTo(int64(12)))
+		for i := 1; i < len(policies.Permissions); i++ {
+			Expect(policies.Permissions[i-1].Name < policies.Permissions[i].Name).Should(BeTrue())
+		}
+		policies, err = rbacService.ListPermissions(context.TODO(), "", 3, 5, apisv1.ListPermissionOptions{SortBy: "name", Order: "asc"})
+		Expect(err).Should(BeNil())
+		Expect(len(policies.Permissions)).Should(Equal(2))
+		policies, err = rbacService.ListPermissions(context.TODO(), "", 0, 0, apisv1.ListPermissionOptions{Name: "admin"})
+		Expect(err).Should(BeNil())
+		for _, policy := range policies.Permissions {
+			Expect(policy.Name).Should(ContainSubstring("admin"))
+		}
+		Expect(policies.Total).Should(BeEquivalentTo(int64(len(policies.Permissions))))
+
+		_, err = rbacService.ListPermissions(context.TODO(), "", 0, 0, apisv1.ListPermissionOptions{SortBy: "resources"})
+		Expect(err).Should(Equal(bcode.ErrInvalidSortOptions))
 	})
 
 	It("Test checkPerm by admin user", func() {
@@ -123,6 +154,27 @@ var _ = Describe("Test rbac service", func() {
 		Expect(pass).Should(BeTrue())
 	})
 
+	It("Test checkPerm by the user who must change the password", func() {
+		err := ds.Add(context.TODO(), &model.User{Name: "rotate-admin", UserRoles: []string{"admin"}, MustChangePassword: true})
+		Expect(err).Should(BeNil())
+
+		rbac := rbacServiceImpl{Store: ds}
+		req := &http.Request{Method: http.MethodGet}
+		req = req.WithContext(context.WithValue(req.Context(), &apisv1.CtxKeyUser, "rotate-admin"))
+		record := httptest.NewRecorder()
+		res := restful.NewResponse(record)
+		res.SetRequestAccepts("application/json")
+		pass := false
+		filter := &restful.FilterChain{
+			Target: restful.RouteFunction(func(req *restful.Request, res *restful.Response) {
+				pass = true
+			}),
+		}
+		rbac.CheckPerm("role", "list")(restful.NewRequest(req), res, filter)
+		Expect(pass).Should(BeFalse())
+		Expect(res.StatusCode()).Should(Equal(int(bcode.ErrPasswordChangeRequired.HTTPCode)))
+	})
+
 	It("Test checkPerm by dev user", func() {
 		var projectName = "test-app-project"
 
@@ -182,6 +234,92 @@ var _ = Describe("Test rbac service", func() {
 		// Expect(pass).Should(BeTrue())
 	})
 
+	It("Test checkPerm in the maintenance mode", func() {
+		var projectName = "maintenance-project"
+		Expect(ds.Add(context.TODO(), &model.User{Name: "maintenance-dev"})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.User{Name: "maintenance-operator", UserRoles: []string{"maintenance-operator"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Project{Name: projectName})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.ProjectUser{Username: "maintenance-dev", ProjectName: projectName, UserRoles: []string{"application-admin"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Role{Project: projectName, Name: "application-admin", Permissions: []string{"application-manage"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Permission{Project: projectName, Name: "application-manage", Resources: []string{"project:maintenance-project/application:*", "project:maintenance-project/application:*/revision:*"}, Actions: []string{"*"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Role{Name: "maintenance-operator", Permissions: []string{"cluster-management", "maintenance-override"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.SystemInfo{InstallID: "maintenance-test", MaintenanceMode: true})).Should(BeNil())
+		defer func() {
+			Expect(ds.Delete(context.TODO(), &model.SystemInfo{InstallID: "maintenance-test"})).Should(BeNil())
+		}()
+
+		rbac := rbacServiceImpl{Store: ds, KubeClient: k8sClient}
+		Expect(rbac.Init(context.TODO())).Should(BeNil())
+		checkPerm := func(userName, resource, action string) (bool, int) {
+			req := &http.Request{Header: http.Header{}}
+			req.Header.Set("Accept", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), &apisv1.CtxKeyUser, userName))
+			req.Form = url.Values{}
+			req.Form.Set("project", projectName)
+			res := restful.NewResponse(httptest.NewRecorder())
+			res.SetRequestAccepts("application/json")
+			pass := false
+			filter := &restful.FilterChain{
+				Target: restful.RouteFunction(func(req *restful.Request, res *restful.Response) {
+					pass = true
+				}),
+			}
+			rbac.CheckPerm(resource, action)(restful.NewRequest(req), res, filter)
+			return pass, res.StatusCode()
+		}
+
+		pass, _ := checkPerm("maintenance-dev", "application", "list")
+		Expect(pass).Should(BeTrue())
+		pass, code := checkPerm("maintenance-dev", "application", "deploy")
+		Expect(pass).Should(BeFalse())
+		Expect(code).Should(Equal(int(bcode.ErrMaintenanceMode.HTTPCode)))
+		pass, code = checkPerm("maintenance-dev", "revision", "rollback")
+		Expect(pass).Should(BeFalse())
+		Expect(code).Should(Equal(int(bcode.ErrMaintenanceMode.HTTPCode)))
+		pass, _ = checkPerm("maintenance-operator", "cluster", "create")
+		Expect(pass).Should(BeTrue())
+	})
+
+	It("Test checkPerm in the locked project", func() {
+		var projectName = "locked-project"
+		Expect(ds.Add(context.TODO(), &model.User{Name: "locked-dev"})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.User{Name: "locked-admin", UserRoles: []string{"locked-admin"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Project{Name: projectName, Lock: &model.ProjectLock{Reason: "incident", ExpireTime: time.Now().Add(time.Hour)}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.ProjectUser{Username: "locked-dev", ProjectName: projectName, UserRoles: []string{"application-admin"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Role{Project: projectName, Name: "application-admin", Permissions: []string{"application-manage"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Permission{Project: projectName, Name: "application-manage", Resources: []string{"project:locked-project/application:*"}, Actions: []string{"*"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Role{Name: "locked-admin", Permissions: []string{"admin"}})).Should(BeNil())
+
+		rbac := rbacServiceImpl{Store: ds, KubeClient: k8sClient}
+		Expect(rbac.Init(context.TODO())).Should(BeNil())
+		checkPerm := func(userName, resource, action string) (bool, int) {
+			req := &http.Request{Header: http.Header{}}
+			req.Header.Set("Accept", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), &apisv1.CtxKeyUser, userName))
+			req.Form = url.Values{}
+			req.Form.Set("project", projectName)
+			res := restful.NewResponse(httptest.NewRecorder())
+			res.SetRequestAccepts("application/json")
+			pass := false
+			filter := &restful.FilterChain{
+				Target: restful.RouteFunction(func(req *restful.Request, res *restful.Response) {
+					pass = true
+				}),
+			}
+			rbac.CheckPerm(resource, action)(restful.NewRequest(req), res, filter)
+			return pass, res.StatusCode()
+		}
+
+		pass, _ := checkPerm("locked-dev", "project/application", "detail")
+		Expect(pass).Should(BeTrue())
+		pass, code := checkPerm("locked-dev", "project/application", "deploy")
+		Expect(pass).Should(BeFalse())
+		Expect(code).Should(Equal(int(bcode.ErrProjectLocked.HTTPCode)))
+		By("the platform admins could override the lock")
+		pass, _ = checkPerm("locked-admin", "project/application", "deploy")
+		Expect(pass).Should(BeTrue())
+	})
+
 	It("Test initDefaultRoleAndUsersForProject", func() {
 		rbacService := rbacServiceImpl{Store: ds}
 		err := ds.Add(context.TODO(), &model.User{Name: "test-user"})
@@ -192,17 +330,388 @@ var _ = Describe("Test rbac service", func() {
 		err = rbacService.SyncDefaultRoleAndUsersForProject(context.TODO(), &model.Project{Name: "init-test"})
 		Expect(err).Should(BeNil())
 
-		roles, err := rbacService.ListRole(context.TODO(), "init-test", 0, 0)
+		roles, err := rbacService.ListRole(context.TODO(), "init-test", 0, 0, apisv1.ListRoleOptions{})
+		Expect(err).Should(BeNil())
+		Expect(roles.Total).Should(BeEquivalentTo(int64(3)))
+
+		roles, err = rbacService.ListRole(context.TODO(), "init-test", 1, 2, apisv1.ListRoleOptions{SortBy: "name", Order: "desc"})
 		Expect(err).Should(BeNil())
+		Expect(len(roles.Roles)).Should(Equal(2))
 		Expect(roles.Total).Should(BeEquivalentTo(int64(3)))
+		Expect(roles.Roles[0].Name > roles.Roles[1].Name).Should(BeTrue())
+		roles, err = rbacService.ListRole(context.TODO(), "init-test", 2, 2, apisv1.ListRoleOptions{SortBy: "name", Order: "desc"})
+		Expect(err).Should(BeNil())
+		Expect(len(roles.Roles)).Should(Equal(1))
+
+		policies, err := rbacService.ListPermissions(context.TODO(), "init-test", 0, 0, apisv1.ListPermissionOptions{})
+		Expect(err).Should(BeNil())
+		Expect(len(policies.Permissions)).Should(BeEquivalentTo(int64(6)))
+	})
+
+	It("Test propagating the project roles to the kubernetes RBAC", func() {
+		rbacService := rbacServiceImpl{Store: ds, KubeClient: k8sClient, PropagateToKubeRBAC: true}
+		Expect(k8sClient.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-rbac-test"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.User{Name: "kube-rbac-user"})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Project{Name: "kube-rbac-test"})).Should(BeNil())
+		Expect(rbacService.SyncDefaultRoleAndUsersForProject(context.TODO(), &model.Project{Name: "kube-rbac-test"})).Should(BeNil())
+
+		hasBinding := func() bool {
+			var bindings rbacv1.RoleBindingList
+			Expect(k8sClient.List(context.TODO(), &bindings, client.InNamespace("kube-rbac-test"))).Should(BeNil())
+			for _, binding := range bindings.Items {
+				for _, subject := range binding.Subjects {
+					if subject.Kind == rbacv1.UserKind && subject.Name == "kube-rbac-user" {
+						return true
+					}
+				}
+			}
+			return false
+		}
+
+		Expect(ds.Add(context.TODO(), &model.ProjectUser{ProjectName: "kube-rbac-test", Username: "kube-rbac-user", UserRoles: []string{"app-developer"}})).Should(BeNil())
+		Expect(rbacService.SyncProjectUserPrivileges(context.TODO(), "kube-rbac-test", "kube-rbac-user")).Should(BeNil())
+		Expect(hasBinding()).Should(BeTrue())
+
+		Expect(ds.Delete(context.TODO(), &model.ProjectUser{ProjectName: "kube-rbac-test", Username: "kube-rbac-user"})).Should(BeNil())
+		Expect(rbacService.SyncProjectUserPrivileges(context.TODO(), "kube-rbac-test", "kube-rbac-user")).Should(BeNil())
+		Expect(hasBinding()).Should(BeFalse())
+	})
+
+	It("Test project role templates", func() {
+		rbacService := rbacServiceImpl{Store: ds}
+		err := ds.Add(context.TODO(), &model.Project{Name: "template-exist"})
+		Expect(err).Should(BeNil())
+
+		_, err = rbacService.CreateProjectRoleTemplate(context.TODO(), apisv1.CreateProjectRoleTemplateRequest{Name: "project-viewer", Permissions: []string{"project-view"}})
+		Expect(err).Should(Equal(bcode.ErrProjectRoleTemplateIsExist))
+		_, err = rbacService.CreateProjectRoleTemplate(context.TODO(), apisv1.CreateProjectRoleTemplateRequest{Name: "auditor", Permissions: []string{"not-exist"}})
+		Expect(err).Should(Equal(bcode.ErrRolePermissionCheckFailure))
+
+		base, err := rbacService.CreateProjectRoleTemplate(context.TODO(), apisv1.CreateProjectRoleTemplateRequest{Name: "auditor", Alias: "Auditor", Permissions: []string{"project-view"}})
+		Expect(err).Should(BeNil())
+		Expect(base.Name).Should(Equal("auditor"))
+
+		// the template is stamped into the new project
+		err = rbacService.SyncDefaultRoleAndUsersForProject(context.TODO(), &model.Project{Name: "template-new"})
+		Expect(err).Should(BeNil())
+		role := &model.Role{Name: "auditor", Project: "template-new"}
+		Expect(ds.Get(context.TODO(), role)).Should(BeNil())
+		Expect(role.Permissions).Should(Equal([]string{"project-view"}))
+
+		// the existing project is not changed without syncing
+		exist, err := ds.IsExist(context.TODO(), &model.Role{Name: "auditor", Project: "template-exist"})
+		Expect(err).Should(BeNil())
+		Expect(exist).Should(BeFalse())
 
-		policies, err := rbacService.ListPermissions(context.TODO(), "init-test")
+		_, err = rbacService.UpdateProjectRoleTemplate(context.TODO(), "auditor", apisv1.UpdateProjectRoleTemplateRequest{Alias: "Auditor", Permissions: []string{"project-view", "app-management"}, SyncToProjects: true})
 		Expect(err).Should(BeNil())
-		Expect(len(policies)).Should(BeEquivalentTo(int64(6)))
+		role = &model.Role{Name: "auditor", Project: "template-exist"}
+		Expect(ds.Get(context.TODO(), role)).Should(BeNil())
+		Expect(role.Permissions).Should(Equal([]string{"project-view", "app-management"}))
+		role = &model.Role{Name: "auditor", Project: "template-new"}
+		Expect(ds.Get(context.TODO(), role)).Should(BeNil())
+		Expect(role.Permissions).Should(Equal([]string{"project-view", "app-management"}))
+
+		templates, err := rbacService.ListProjectRoleTemplates(context.TODO())
+		Expect(err).Should(BeNil())
+		Expect(len(templates.Templates)).Should(Equal(1))
+
+		Expect(rbacService.DeleteProjectRoleTemplate(context.TODO(), "auditor")).Should(BeNil())
+		Expect(rbacService.DeleteProjectRoleTemplate(context.TODO(), "auditor")).Should(Equal(bcode.ErrProjectRoleTemplateIsNotExist))
+	})
+
+	It("Test simulate capabilities", func() {
+		rbacService := rbacServiceImpl{Store: ds}
+		registerResourceAction("addon", "list", "enable")
+		menuVisible := func(res *apisv1.SimulateCapabilitiesResponse) map[string]bool {
+			visible := map[string]bool{}
+			for _, menu := range res.Menus {
+				visible[menu.Name] = menu.Visible
+			}
+			return visible
+		}
+
+		res, err := rbacService.SimulateCapabilities(context.TODO(), apisv1.SimulateCapabilitiesRequest{Roles: []string{"admin"}})
+		Expect(err).Should(BeNil())
+		Expect(len(res.Permissions)).Should(Equal(1))
+		for name, visible := range menuVisible(res) {
+			Expect(visible).Should(BeTrue(), name)
+		}
+
+		res, err = rbacService.SimulateCapabilities(context.TODO(), apisv1.SimulateCapabilitiesRequest{Permissions: []string{"addon-management"}})
+		Expect(err).Should(BeNil())
+		visible := menuVisible(res)
+		Expect(visible["addon-list"]).Should(BeTrue())
+		Expect(visible["user-list"]).Should(BeFalse())
+		Expect(visible["applications"]).Should(BeFalse())
+		Expect(res.ResourceActions).Should(ContainElement(apisv1.ResourceActionCapability{Resource: "addon", Action: "enable", Allowed: true}))
+
+		_, err = rbacService.SimulateCapabilities(context.TODO(), apisv1.SimulateCapabilitiesRequest{Roles: []string{"not-exist"}})
+		Expect(err).Should(Equal(bcode.ErrRoleIsNotExist))
+		_, err = rbacService.SimulateCapabilities(context.TODO(), apisv1.SimulateCapabilitiesRequest{Permissions: []string{"not-exist"}})
+		Expect(err).Should(Equal(bcode.ErrRolePermissionCheckFailure))
+	})
+
+	It("Test RBAC approvals", func() {
+		rbacService := rbacServiceImpl{Store: ds}
+		requesterCtx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "requester")
+		approverCtx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "approver")
+
+		_, err := rbacService.CreatePermission(requesterCtx, "", apisv1.CreatePermissionRequest{
+			Name: "addon-viewer", Resources: []string{"addon:*"}, Actions: []string{"list", "detail"},
+		})
+		Expect(err).Should(BeNil())
+		_, err = rbacService.CreatePermission(requesterCtx, "", apisv1.CreatePermissionRequest{
+			Name: "super-user", Resources: []string{"user:*"}, Actions: []string{"*"},
+		})
+		Expect(err).Should(Equal(bcode.ErrRBACChangePendingApproval))
+		Expect(ds.IsExist(context.TODO(), &model.Permission{Name: "super-user"})).Should(BeFalse())
+
+		approvals, err := rbacService.ListRBACApprovals(context.TODO(), model.RBACApprovalStatusPending)
+		Expect(err).Should(BeNil())
+		Expect(len(approvals.Approvals)).Should(Equal(1))
+		approval := approvals.Approvals[0]
+		Expect(approval.Kind).Should(Equal("permission"))
+		Expect(approval.Requester).Should(Equal("requester"))
+
+		_, err = rbacService.ApproveRBACChange(requesterCtx, approval.Name, apisv1.ReviewRBACApprovalRequest{})
+		Expect(err).Should(Equal(bcode.ErrRBACApprovalBySelf))
+		base, err := rbacService.ApproveRBACChange(approverCtx, approval.Name, apisv1.ReviewRBACApprovalRequest{Comment: "LGTM"})
+		Expect(err).Should(BeNil())
+		Expect(base.Status).Should(Equal(model.RBACApprovalStatusApproved))
+		Expect(base.Reviewer).Should(Equal("approver"))
+		Expect(ds.IsExist(context.TODO(), &model.Permission{Name: "super-user"})).Should(BeTrue())
+		_, err = rbacService.RejectRBACChange(approverCtx, approval.Name, apisv1.ReviewRBACApprovalRequest{})
+		Expect(err).Should(Equal(bcode.ErrRBACApprovalIsClosed))
+
+		_, err = rbacService.CreateRole(requesterCtx, "", apisv1.CreateRoleRequest{Name: "addon-viewer", Permissions: []string{"addon-viewer"}})
+		Expect(err).Should(BeNil())
+		_, err = rbacService.UpdateRole(requesterCtx, "", "addon-viewer", apisv1.UpdateRoleRequest{Permissions: []string{"addon-viewer", "super-user"}})
+		Expect(err).Should(Equal(bcode.ErrRBACChangePendingApproval))
+		approvals, err = rbacService.ListRBACApprovals(context.TODO(), model.RBACApprovalStatusPending)
+		Expect(err).Should(BeNil())
+		Expect(len(approvals.Approvals)).Should(Equal(1))
+		base, err = rbacService.RejectRBACChange(approverCtx, approvals.Approvals[0].Name, apisv1.ReviewRBACApprovalRequest{})
+		Expect(err).Should(BeNil())
+		Expect(base.Status).Should(Equal(model.RBACApprovalStatusRejected))
+		role := &model.Role{Name: "addon-viewer"}
+		Expect(ds.Get(context.TODO(), role)).Should(BeNil())
+		Expect(role.Permissions).Should(Equal([]string{"addon-viewer"}))
+
+		Expect(rbacService.DeleteRole(context.TODO(), "", "addon-viewer")).Should(BeNil())
+		Expect(rbacService.DeletePermission(context.TODO(), "", "addon-viewer")).Should(BeNil())
+		Expect(rbacService.DeletePermission(context.TODO(), "", "super-user")).Should(BeNil())
+	})
+
+	It("Test list the permission references", func() {
+		rbacService := rbacServiceImpl{Store: ds}
+		ctx := context.TODO()
+		_, err := rbacService.ListPermissionReferences(ctx, "", "ref-perm")
+		Expect(err).Should(Equal(bcode.ErrPermissionNotExist))
+
+		Expect(ds.Add(ctx, &model.Permission{Name: "ref-perm", Resources: []string{"target:*"}, Actions: []string{"list"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Role{Name: "ref-role", Permissions: []string{"ref-perm"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.User{Name: "ref-user", UserRoles: []string{"ref-role"}})).Should(BeNil())
+		references, err := rbacService.ListPermissionReferences(ctx, "", "ref-perm")
+		Expect(err).Should(BeNil())
+		Expect(references.Roles).Should(Equal([]apisv1.PermissionReferenceRole{{Name: "ref-role"}}))
+		Expect(references.Users).Should(Equal([]apisv1.PermissionReferenceUser{{Name: "ref-user", Roles: []string{"ref-role"}}}))
+
+		Expect(ds.Add(ctx, &model.Permission{Name: "ref-perm", Project: "ref-project", Resources: []string{"project:ref-project/application:*"}, Actions: []string{"list"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Role{Name: "ref-project-role", Project: "ref-project", Permissions: []string{"ref-perm"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.User{Name: "ref-member"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: "ref-project", Username: "ref-member", UserRoles: []string{"ref-project-role"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: "ref-project", Username: "ref-user"})).Should(BeNil())
+		references, err = rbacService.ListPermissionReferences(ctx, "ref-project", "ref-perm")
+		Expect(err).Should(BeNil())
+		Expect(len(references.Roles)).Should(Equal(2))
+		Expect(len(references.Users)).Should(Equal(2))
+		for _, user := range references.Users {
+			switch user.Name {
+			case "ref-member":
+				Expect(user.Indirect).Should(BeFalse())
+				Expect(user.Roles).Should(Equal([]string{"ref-project-role"}))
+			case "ref-user":
+				Expect(user.Indirect).Should(BeTrue())
+				Expect(user.Roles).Should(Equal([]string{"ref-role"}))
+			}
+		}
+	})
+
+	It("Test grant the targets to a role", func() {
+		rbacService := rbacServiceImpl{Store: ds}
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Role{Name: "grant-role", Alias: "Grant"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Target{Name: "grant-target"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Cluster{Name: "grant-cluster"})).Should(BeNil())
+
+		_, err := rbacService.GrantTargets(ctx, "grant-role", apisv1.GrantTargetsRequest{Targets: []string{"not-exist"}})
+		Expect(err).ShouldNot(BeNil())
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrTargetNotExist.BusinessCode))
+
+		role, err := rbacService.GrantTargets(ctx, "grant-role", apisv1.GrantTargetsRequest{
+			Targets:  []string{"grant-target"},
+			Clusters: []string{"grant-cluster"},
+			Actions:  []string{"detail", "list"},
+		})
+		Expect(err).Should(BeNil())
+		Expect(len(role.Permissions)).Should(Equal(2))
+		perm := &model.Permission{Name: "grant-role-cluster-grant-cluster"}
+		Expect(ds.Get(ctx, perm)).Should(BeNil())
+		Expect(perm.Resources).Should(Equal([]string{"cluster:grant-cluster", "cluster:grant-cluster/namespace:*"}))
+		Expect(perm.Actions).Should(Equal([]string{"detail", "list"}))
+
+		By("granting again updates the generated permissions")
+		role, err = rbacService.GrantTargets(ctx, "grant-role", apisv1.GrantTargetsRequest{Targets: []string{"grant-target"}})
+		Expect(err).Should(BeNil())
+		Expect(len(role.Permissions)).Should(Equal(2))
+		perm = &model.Permission{Name: "grant-role-target-grant-target"}
+		Expect(ds.Get(ctx, perm)).Should(BeNil())
+		Expect(perm.Actions).Should(Equal([]string{"*"}))
+
+		By("nothing is written before the grant to the admin-equivalent role is approved")
+		requesterCtx := context.WithValue(ctx, &apisv1.CtxKeyUser, "grant-requester")
+		approverCtx := context.WithValue(ctx, &apisv1.CtxKeyUser, "grant-approver")
+		Expect(ds.Add(ctx, &model.Permission{Name: "grant-user-admin", Resources: []string{"user:*"}, Actions: []string{"*"}, Effect: "Allow"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Role{Name: "grant-admin-role", Permissions: []string{"grant-user-admin"}})).Should(BeNil())
+		_, err = rbacService.GrantTargets(requesterCtx, "grant-admin-role", apisv1.GrantTargetsRequest{Targets: []string{"grant-target"}})
+		Expect(err).Should(Equal(bcode.ErrRBACChangePendingApproval))
+		Expect(ds.IsExist(ctx, &model.Permission{Name: "grant-admin-role-target-grant-target"})).Should(BeFalse())
+		adminRole := &model.Role{Name: "grant-admin-role"}
+		Expect(ds.Get(ctx, adminRole)).Should(BeNil())
+		Expect(adminRole.Permissions).Should(Equal([]string{"grant-user-admin"}))
+
+		approvals, err := rbacService.ListRBACApprovals(ctx, model.RBACApprovalStatusPending)
+		Expect(err).Should(BeNil())
+		Expect(len(approvals.Approvals)).Should(Equal(1))
+		_, err = rbacService.ApproveRBACChange(approverCtx, approvals.Approvals[0].Name, apisv1.ReviewRBACApprovalRequest{})
+		Expect(err).Should(BeNil())
+		Expect(ds.Get(ctx, adminRole)).Should(BeNil())
+		Expect(adminRole.Permissions).Should(Equal([]string{"grant-user-admin", "grant-admin-role-target-grant-target"}))
+	})
+
+	It("Test publish the permission changes", func() {
+		feed := changefeed.New()
+		events, cancel := feed.Subscribe(4)
+		defer cancel()
+		rbacService := rbacServiceImpl{Store: ds, ChangeFeed: feed, permissionCache: apiserverutils.NewLRUCache(16, time.Minute)}
+		err := ds.Add(context.TODO(), &model.User{Name: "feed-admin", UserRoles: []string{"admin"}})
+		Expect(err == nil || errors.Is(err, datastore.ErrRecordExist)).Should(BeTrue())
+		ctx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "feed-admin")
+		_, err = rbacService.CreatePermission(ctx, "", apisv1.CreatePermissionRequest{Name: "feed-perm", Resources: []string{"project:*"}, Actions: []string{"list"}})
+		Expect(err).Should(BeNil())
+		event := <-events
+		Expect(event.Resource).Should(Equal("permission"))
+		Expect(event.Name).Should(Equal("feed-perm"))
+		Expect(event.Action).Should(Equal(changefeed.ActionCreate))
+		Expect(event.Operator).Should(Equal("feed-admin"))
+		// the permissions of the operator are loaded again after purging
+		Expect(rbacService.permissionCache.Stats().Size).ShouldNot(BeZero())
+
+		Expect(rbacService.DeletePermission(ctx, "", "feed-perm")).Should(BeNil())
+		event = <-events
+		Expect(event.Action).Should(Equal(changefeed.ActionDelete))
+	})
+
+	It("Test the cross-project roles", func() {
+		rbacService := rbacServiceImpl{Store: ds}
+		ctx := context.TODO()
+		_, err := rbacService.CreateRole(ctx, model.RoleScopeCrossProject, apisv1.CreateRoleRequest{Name: "cross-viewer", Permissions: []string{"cross-view"}})
+		Expect(err).Should(Equal(bcode.ErrRoleProjectScopeRequired))
+		_, err = rbacService.CreateRole(ctx, model.RoleScopeCrossProject, apisv1.CreateRoleRequest{
+			Name:            "cross-viewer",
+			Permissions:     []string{"cross-view"},
+			ProjectSelector: map[string]string{"team": "cross"},
+		})
+		Expect(err).Should(BeNil())
+
+		projectA := &model.Project{Name: "cross-a", Labels: map[string]string{"team": "cross"}}
+		projectB := &model.Project{Name: "cross-b"}
+		Expect(ds.Add(ctx, projectA)).Should(BeNil())
+		Expect(ds.Add(ctx, projectB)).Should(BeNil())
+		role := &model.Role{Name: "cross-viewer", Project: model.RoleScopeCrossProject}
+		Expect(ds.Get(ctx, role)).Should(BeNil())
+		Expect(role.MatchProject(projectA)).Should(BeTrue())
+		Expect(role.MatchProject(projectB)).Should(BeFalse())
+
+		roles, err := rbacService.ListRole(ctx, "cross-a", 0, 0, apisv1.ListRoleOptions{})
+		Expect(err).Should(BeNil())
+		Expect(roles.Roles).Should(ContainElement(WithTransform(func(r *apisv1.RoleBase) string { return r.Name }, Equal("cross-viewer"))))
+		roles, err = rbacService.ListRole(ctx, "cross-a", 1, 10, apisv1.ListRoleOptions{Name: "viewer"})
+		Expect(err).Should(BeNil())
+		Expect(roles.Total).Should(BeEquivalentTo(int64(1)))
+		Expect(roles.Roles[0].Name).Should(Equal("cross-viewer"))
+		roles, err = rbacService.ListRole(ctx, "cross-a", 2, 10, apisv1.ListRoleOptions{Name: "viewer"})
+		Expect(err).Should(BeNil())
+		Expect(roles.Roles).Should(BeEmpty())
+		Expect(checkProjectRoles(ctx, ds, projectA, []string{"cross-viewer"})).Should(BeNil())
+		Expect(checkProjectRoles(ctx, ds, projectB, []string{"cross-viewer"})).Should(Equal(bcode.ErrProjectRoleCheckFailure))
+
+		for _, project := range []string{"cross-a", "cross-b"} {
+			Expect(ds.Add(ctx, &model.Permission{Name: "cross-view", Project: project, Resources: []string{"project:" + project + "/application:*"}, Actions: []string{"detail"}})).Should(BeNil())
+			Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: project, Username: "cross-user", UserRoles: []string{"cross-viewer"}})).Should(BeNil())
+		}
+		user := &model.User{Name: "cross-user"}
+		perms, err := rbacService.GetUserPermissions(ctx, user, "cross-a", false)
+		Expect(err).Should(BeNil())
+		Expect(perms).Should(ContainElement(WithTransform(func(p *model.Permission) string { return p.Name }, Equal("cross-view"))))
+		perms, err = rbacService.GetUserPermissions(ctx, user, "cross-b", false)
+		Expect(err).Should(BeNil())
+		Expect(perms).ShouldNot(ContainElement(WithTransform(func(p *model.Permission) string { return p.Name }, Equal("cross-view"))))
+
+		_, err = rbacService.UpdateRole(ctx, model.RoleScopeCrossProject, "cross-viewer", apisv1.UpdateRoleRequest{Permissions: []string{"cross-view"}, Projects: []string{"cross-a", "cross-b"}})
+		Expect(err).Should(BeNil())
+		perms, err = rbacService.GetUserPermissions(ctx, user, "cross-b", false)
+		Expect(err).Should(BeNil())
+		Expect(perms).Should(ContainElement(WithTransform(func(p *model.Permission) string { return p.Name }, Equal("cross-view"))))
+		Expect(rbacService.DeleteRole(ctx, model.RoleScopeCrossProject, "cross-viewer")).Should(BeNil())
+	})
+
+	It("Test load the users and permissions concurrently", func() {
+		rbac := NewRBACService(false).(*rbacServiceImpl)
+		rbac.Store = ds
+		Expect(ds.Add(context.TODO(), &model.User{Name: "load-test", UserRoles: []string{"admin"}})).Should(BeNil())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				user, err := rbac.getLoginUser(context.TODO(), "load-test")
+				Expect(err).Should(BeNil())
+				_, err = rbac.GetUserPermissions(context.TODO(), user, "", true)
+				Expect(err).Should(BeNil())
+			}()
+		}
+		wg.Wait()
+		Expect(rbac.permissionCache.Stats().Size).Should(Equal(1))
+
+		// the unknown users are cached for a while
+		_, err := rbac.getLoginUser(context.TODO(), "load-test-unknown")
+		Expect(errors.Is(err, datastore.ErrRecordNotExist)).Should(BeTrue())
+		Expect(ds.Add(context.TODO(), &model.User{Name: "load-test-unknown"})).Should(BeNil())
+		_, err = rbac.getLoginUser(context.TODO(), "load-test-unknown")
+		Expect(errors.Is(err, datastore.ErrRecordNotExist)).Should(BeTrue())
+		rbac.unknownUserCache.Delete("load-test-unknown")
+		_, err = rbac.getLoginUser(context.TODO(), "load-test-unknown")
+		Expect(err).Should(BeNil())
+
+		// the permissions loaded before purging are not cached
+		rbac.purgePermissionCache()
+		Expect(rbac.permissionCache.Stats().Size).Should(BeZero())
 	})
 
 	It("Test UpdatePermission", func() {
 		rbacService := rbacServiceImpl{Store: ds}
+		priority := 5
+		_, err := rbacService.UpdatePermission(context.TODO(), "test-app-project", "application-manage", &apisv1.UpdatePermissionRequest{
+			Resources: []string{"project:{projectName}/application:*/*"},
+			Actions:   []string{"*"},
+			Alias:     "App Management",
+			Priority:  &priority,
+		})
+		Expect(err).Should(BeNil())
 		base, err := rbacService.UpdatePermission(context.TODO(), "test-app-project", "application-manage", &apisv1.UpdatePermissionRequest{
 			Resources: []string{"project:{projectName}/application:*/*"},
 			Actions:   []string{"*"},
@@ -210,6 +719,26 @@ var _ = Describe("Test rbac service", func() {
 		})
 		Expect(err).Should(BeNil())
 		Expect(base.Alias).Should(BeEquivalentTo("App Management Update"))
+
+		By("the priority is kept if it is not set")
+		Expect(base.Priority).Should(Equal(5))
+
+		By("the update with a stale version is rejected")
+		_, err = rbacService.UpdatePermission(context.TODO(), "test-app-project", "application-manage", &apisv1.UpdatePermissionRequest{
+			Resources:       []string{"project:{projectName}/application:*/*"},
+			Actions:         []string{"*"},
+			Alias:           "App Management Stale",
+			ResourceVersion: base.ResourceVersion - 1,
+		})
+		Expect(errors.Is(err, datastore.ErrRecordConflict)).Should(BeTrue())
+		updated, err := rbacService.UpdatePermission(context.TODO(), "test-app-project", "application-manage", &apisv1.UpdatePermissionRequest{
+			Resources:       []string{"project:{projectName}/application:*/*"},
+			Actions:         []string{"*"},
+			Alias:           "App Management Latest",
+			ResourceVersion: base.ResourceVersion,
+		})
+		Expect(err).Should(BeNil())
+		Expect(updated.ResourceVersion).Should(Equal(base.ResourceVersion + 1))
 	})
 })
 
@@ -284,8 +813,105 @@ func TestRequestResourceActionMatch(t *testing.T) {
 
 }
 
+func TestRequestResourceActionMatchLabels(t *testing.T) {
+	rn := ParseResourceName("project:p1/application:[label:app.kubernetes.io/team=payments,env!=prod]/*")
+	assert.Equal(t, rn.Next.Type, "application")
+	assert.Equal(t, rn.Next.Value, "[label:app.kubernetes.io/team=payments,env!=prod]")
+	assert.Equal(t, rn.Next.Next.Type, "*")
+
+	ra := &RequestResourceAction{}
+	ra.SetResourceWithName("project:{projectName}/application:{app1}/component:{empty}", testPathParameter)
+	ra.SetActions([]string{"detail"})
+	selected := []*model.Permission{{Resources: []string{"project:projectName/application:[label:team=payments]/*"}, Actions: []string{"*"}}}
+	assert.Equal(t, ra.Match(selected), false)
+	ra.SetResourceLabels("application", map[string]string{"team": "payments"})
+	assert.Equal(t, ra.Match(selected), true)
+	ra.SetResourceLabels("application", map[string]string{"team": "orders"})
+	assert.Equal(t, ra.Match(selected), false)
+	ra.SetResourceLabels("application", map[string]string{"team": "payments", "env": "prod"})
+	assert.Equal(t, ra.Match([]*model.Permission{
+		{Resources: []string{"project:*/application:*/*"}, Actions: []string{"*"}},
+		{Resources: []string{"project:*/application:[label:env=prod]/*"}, Actions: []string{"detail"}, Effect: "Deny"},
+	}), false)
+
+	ra2 := &RequestResourceAction{}
+	ra2.SetResourceWithName("project:{projectName}/application:{empty}", testPathParameter)
+	ra2.SetActions([]string{"list"})
+	ra2.SetResourceLabels("application", map[string]string{"team": "payments"})
+	assert.Equal(t, ra2.Match([]*model.Permission{{Resources: []string{"project:*/application:[label:team=payments]"}, Actions: []string{"*"}}}), false)
+
+	assert.Equal(t, checkResourceSelectors([]string{"project:*/application:[label:team in (a,b)]"}), nil)
+	assert.Equal(t, checkResourceSelectors([]string{"project:*/application:[label:team=="}), bcode.ErrInvalidResourceSelector)
+}
+
+func TestRequestResourceActionMatchPriority(t *testing.T) {
+	ra := &RequestResourceAction{}
+	ra.SetResourceWithName("config:{name}", func(name string) string { return "registry" })
+	ra.SetActions([]string{"update"})
+	denyAll := &model.Permission{Resources: []string{"config:*"}, Actions: []string{"*"}, Effect: "Deny"}
+	allowRegistry := &model.Permission{Resources: []string{"config:registry"}, Actions: []string{"*"}, Effect: "Allow"}
+	assert.Equal(t, ra.Match([]*model.Permission{denyAll, allowRegistry}), false)
+
+	allowRegistry.Priority = 10
+	assert.Equal(t, ra.Match([]*model.Permission{denyAll, allowRegistry}), true)
+
+	ra.SetResourceWithName("config:{name}", func(name string) string { return "other" })
+	assert.Equal(t, ra.Match([]*model.Permission{denyAll, allowRegistry}), false)
+
+	denyAll.Priority = 10
+	ra.SetResourceWithName("config:{name}", func(name string) string { return "registry" })
+	assert.Equal(t, ra.Match([]*model.Permission{denyAll, allowRegistry}), false)
+}
+
+func TestRequestResourceActionMatchProjectPriority(t *testing.T) {
+	ra := &RequestResourceAction{}
+	ra.SetResourceWithName("project:{projectName}/application:{appName}", func(name string) string { return "demo" })
+	ra.SetActions([]string{"delete"})
+	platformDeny := &model.Permission{Resources: []string{"project:*/application:*"}, Actions: []string{"delete"}, Effect: "Deny"}
+	projectAllow := &model.Permission{Project: "demo", Resources: []string{"project:demo/application:*"}, Actions: []string{"*"}, Effect: "Allow", Priority: 1000000}
+	assert.Equal(t, ra.Match([]*model.Permission{platformDeny, projectAllow}), false)
+
+	// the platform allows still outrank the platform denies
+	platformAllow := &model.Permission{Resources: []string{"project:demo/application:*"}, Actions: []string{"*"}, Effect: "Allow", Priority: 10}
+	assert.Equal(t, ra.Match([]*model.Permission{platformDeny, projectAllow, platformAllow}), true)
+
+	projectDeny := &model.Permission{Project: "demo", Resources: []string{"project:demo/application:*"}, Actions: []string{"*"}, Effect: "Deny", Priority: -5}
+	assert.Equal(t, ra.Match([]*model.Permission{projectAllow, projectDeny}), true)
+}
+
+func TestIsAdminEquivalent(t *testing.T) {
+	assert.Equal(t, isAdminEquivalent(&model.Permission{Resources: []string{"*"}, Actions: []string{"*"}}), true)
+	assert.Equal(t, isAdminEquivalent(&model.Permission{Resources: []string{"role:*", "permission:*"}, Actions: []string{"create"}}), true)
+	assert.Equal(t, isAdminEquivalent(&model.Permission{Resources: []string{"*"}, Actions: []string{"list", "detail"}}), false)
+	assert.Equal(t, isAdminEquivalent(&model.Permission{Resources: []string{"*"}, Actions: []string{"*"}, Effect: "Deny"}), false)
+	assert.Equal(t, isAdminEquivalent(&model.Permission{Resources: []string{"cluster:*/*"}, Actions: []string{"*"}}), false)
+	assert.Equal(t, isAdminEquivalent(nil, &model.Permission{Resources: []string{"user:*"}, Actions: []string{"update"}}), true)
+}
+
 func TestRegisterResourceAction(t *testing.T) {
 	registerResourceAction("role", "list")
 	registerResourceAction("project/role", "list")
 	t.Log(resourceActions)
 }
+
+func TestListResourceActions(t *testing.T) {
+	registerResourceAction("role", "list", "create")
+	registerResourceAction("role", "delete", "list")
+	registerResourceAction("project/role", "update")
+	list := listResourceActions()
+	var roleActions, projectRoleActions []string
+	for i, ra := range list {
+		if i > 0 {
+			assert.Less(t, list[i-1].Resource, ra.Resource)
+		}
+		switch ra.Resource {
+		case "role:*":
+			roleActions = ra.Actions
+		case "project:{projectName}/role:{roleName}":
+			projectRoleActions = ra.Actions
+		}
+	}
+	assert.Subset(t, roleActions, []string{"create", "delete", "list"})
+	assert.IsNonDecreasing(t, roleActions)
+	assert.Contains(t, projectRoleActions, "update")
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+// recordArchivePrefix the prefix of the keys of the archived workflow records in the object storage
+const recordArchivePrefix = "workflow-records"
+
+// GetRecordRetention gets the retention policy of the workflow records of the project
+func (p *projectServiceImpl) GetRecordRetention(ctx context.Context, projectName string) (*apisv1.WorkflowRecordRetention, error) {
+	project, err := p.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	return convertRecordRetention2DTO(project.RecordRetention), nil
+}
+
+// UpdateRecordRetention replaces the retention policy of the workflow records of the project, the records are pruned by the next run of the pruner
+func (p *projectServiceImpl) UpdateRecordRetention(ctx context.Context, projectName string, req apisv1.WorkflowRecordRetention) (*apisv1.WorkflowRecordRetention, error) {
+	project, err := p.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	retention := &model.WorkflowRecordRetention{
+		KeepRecords: req.KeepRecords,
+		KeepDays:    req.KeepDays,
+		Archive:     req.Archive,
+	}
+	if retention.KeepRecords == 0 && retention.KeepDays == 0 {
+		retention = nil
+	}
+	project.RecordRetention = retention
+	if err := p.Store.Put(ctx, project); err != nil {
+		return nil, err
+	}
+	return convertRecordRetention2DTO(retention), nil
+}
+
+func convertRecordRetention2DTO(retention *model.WorkflowRecordRetention) *apisv1.WorkflowRecordRetention {
+	if retention == nil {
+		return &apisv1.WorkflowRecordRetention{}
+	}
+	return &apisv1.WorkflowRecordRetention{
+		KeepRecords: retention.KeepRecords,
+		KeepDays:    retention.KeepDays,
+		Archive:     retention.Archive,
+	}
+}
+
+// PruneWorkflowRecords deletes the finished records out of the retention policies of the projects. The records are
+// exported to the object storage first if the policy requires, so they are not pruned if the object storage is not configured.
+func (w *workflowServiceImpl) PruneWorkflowRecords(ctx context.Context) error {
+	projects, err := w.Store.List(ctx, &model.Project{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entity := range projects {
+		project := entity.(*model.Project)
+		retention := project.RecordRetention
+		if retention == nil || (retention.KeepRecords <= 0 && retention.KeepDays <= 0) {
+			continue
+		}
+		if retention.Archive && !w.LogStore.Enabled() {
+			klog.Warningf("the workflow records of the project %s are not pruned, the object storage to archive them is not configured", project.Name)
+			continue
+		}
+		apps, err := w.Store.List(ctx, &model.Application{Project: project.Name}, &datastore.ListOptions{})
+		if err != nil {
+			klog.Errorf("failed to list the applications of the project %s: %s", project.Name, err.Error())
+			continue
+		}
+		for _, app := range apps {
+			pruned, err := w.pruneApplicationRecords(ctx, project.Name, app.PrimaryKey(), retention, now)
+			if err != nil {
+				klog.Errorf("failed to prune the workflow records of the application %s: %s", app.PrimaryKey(), err.Error())
+			}
+			if pruned > 0 {
+				klog.Infof("pruned %d workflow records of the application %s", pruned, app.PrimaryKey())
+			}
+		}
+	}
+	return nil
+}
+
+// pruneApplicationRecords deletes the finished records of the application out of the retention, the newest ones are kept
+func (w *workflowServiceImpl) pruneApplicationRecords(ctx context.Context, projectName, appName string, retention *model.WorkflowRecordRetention, now time.Time) (int, error) {
+	records, err := w.Store.List(ctx, &model.WorkflowRecord{AppPrimaryKey: appName, Finished: "true"}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	pruned := 0
+	for i, entity := range records {
+		record := entity.(*model.WorkflowRecord)
+		expired := retention.KeepRecords > 0 && i >= retention.KeepRecords
+		if retention.KeepDays > 0 && record.CreateTime.Before(now.AddDate(0, 0, -retention.KeepDays)) {
+			expired = true
+		}
+		if !expired {
+			continue
+		}
+		if retention.Archive {
+			if err := w.archiveWorkflowRecord(ctx, projectName, record); err != nil {
+				return pruned, err
+			}
+		}
+		if err := w.Store.Delete(ctx, record); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return pruned, err
+		}
+		deleteStepLogs(ctx, w.Store, w.LogStore, &model.StepLog{Resource: stepLogResourceApplication, Entity: appName, Record: record.Name})
+		pruned++
+	}
+	return pruned, nil
+}
+
+// archiveWorkflowRecord exports the record and the logs of its steps to the object storage
+func (w *workflowServiceImpl) archiveWorkflowRecord(ctx context.Context, projectName string, record *model.WorkflowRecord) error {
+	archive := struct {
+		Project string                `json:"project"`
+		Record  *model.WorkflowRecord `json:"record"`
+		Logs    map[string]string     `json:"logs,omitempty"`
+	}{Project: projectName, Record: record, Logs: map[string]string{}}
+	for _, step := range record.Steps {
+		stepLog := &model.StepLog{Resource: stepLogResourceApplication, Entity: record.AppPrimaryKey, Record: record.Name, Step: step.Name}
+		if logs, ok := loadStepLog(ctx, w.Store, w.LogStore, stepLog); ok {
+			archive.Logs[step.Name] = logs
+		}
+	}
+	data, err := json.Marshal(archive)
+	if err != nil {
+		return err
+	}
+	key := path.Join(recordArchivePrefix, projectName, record.AppPrimaryKey, record.Name+".json")
+	if err := w.LogStore.Put(ctx, key, data); err != nil {
+		return fmt.Errorf("failed to archive the workflow record %s: %w", record.Name, err)
+	}
+	return nil
+}
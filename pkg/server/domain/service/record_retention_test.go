@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+var _ = Describe("Test the retention of the workflow records", func() {
+	var (
+		ds              datastore.DataStore
+		objects         *memoryLogStore
+		projectService  *projectServiceImpl
+		workflowService *workflowServiceImpl
+	)
+	const projectName = "retention-project"
+
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "record-retention-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		objects = &memoryLogStore{objects: map[string][]byte{}}
+		projectService = &projectServiceImpl{Store: ds}
+		workflowService = &workflowServiceImpl{Store: ds, LogStore: objects}
+	})
+
+	addRecords := func(ctx context.Context, app string, ages ...time.Duration) {
+		for i, age := range ages {
+			record := &model.WorkflowRecord{Name: app + "-v" + strconv.Itoa(i), AppPrimaryKey: app, Finished: "true", Status: "succeeded",
+				Steps: []model.WorkflowStepStatus{{StepStatus: model.StepStatus{Name: "deploy"}}}}
+			Expect(ds.Add(ctx, record)).Should(BeNil())
+			record.CreateTime = time.Now().Add(-age)
+			Expect(ds.Put(ctx, record)).Should(BeNil())
+			archiveStepLog(ctx, ds, objects, &model.StepLog{Resource: stepLogResourceApplication, Entity: app, Record: record.Name, Step: "deploy"}, "deployed")
+		}
+	}
+	recordNames := func(ctx context.Context, app string) []string {
+		entities, err := ds.List(ctx, &model.WorkflowRecord{AppPrimaryKey: app}, &datastore.ListOptions{})
+		Expect(err).Should(BeNil())
+		var names []string
+		for _, entity := range entities {
+			names = append(names, entity.(*model.WorkflowRecord).Name)
+		}
+		return names
+	}
+
+	It("Test update and get the retention policy", func() {
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Project{Name: projectName})).Should(BeNil())
+		retention, err := projectService.GetRecordRetention(ctx, projectName)
+		Expect(err).Should(BeNil())
+		Expect(*retention).Should(Equal(apisv1.WorkflowRecordRetention{}))
+
+		_, err = projectService.UpdateRecordRetention(ctx, projectName, apisv1.WorkflowRecordRetention{KeepRecords: 10, Archive: true})
+		Expect(err).Should(BeNil())
+		retention, err = projectService.GetRecordRetention(ctx, projectName)
+		Expect(err).Should(BeNil())
+		Expect(retention.KeepRecords).Should(Equal(10))
+		Expect(retention.Archive).Should(BeTrue())
+
+		By("the policy without any limit is removed")
+		_, err = projectService.UpdateRecordRetention(ctx, projectName, apisv1.WorkflowRecordRetention{Archive: true})
+		Expect(err).Should(BeNil())
+		project, err := projectService.GetProject(ctx, projectName)
+		Expect(err).Should(BeNil())
+		Expect(project.RecordRetention).Should(BeNil())
+	})
+
+	It("Test prune and archive the records out of the retention", func() {
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Project{Name: projectName, RecordRetention: &model.WorkflowRecordRetention{KeepRecords: 2, KeepDays: 7, Archive: true}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Project{Name: "unlimited-project"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Application{Name: "retention-app", Project: projectName})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Application{Name: "recent-app", Project: projectName})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Application{Name: "unlimited-app", Project: "unlimited-project"})).Should(BeNil())
+		day := 24 * time.Hour
+		addRecords(ctx, "retention-app", 4*day, 3*day, 2*day, day)
+		addRecords(ctx, "recent-app", 10*day, day)
+		addRecords(ctx, "unlimited-app", 30*day, 20*day, 10*day)
+		Expect(ds.Add(ctx, &model.WorkflowRecord{Name: "retention-app-running", AppPrimaryKey: "retention-app", Finished: "false", Status: "executing"})).Should(BeNil())
+
+		Expect(workflowService.PruneWorkflowRecords(ctx)).Should(BeNil())
+		Expect(recordNames(ctx, "retention-app")).Should(ConsistOf("retention-app-v2", "retention-app-v3", "retention-app-running"))
+		Expect(recordNames(ctx, "recent-app")).Should(ConsistOf("recent-app-v1"))
+		Expect(recordNames(ctx, "unlimited-app")).Should(HaveLen(3))
+
+		By("the pruned records and their logs are archived")
+		Expect(objects.objects).Should(HaveKey("workflow-records/retention-project/retention-app/retention-app-v0.json"))
+		Expect(objects.objects).Should(HaveKey("workflow-records/retention-project/recent-app/recent-app-v0.json"))
+		Expect(string(objects.objects["workflow-records/retention-project/retention-app/retention-app-v1.json"])).Should(ContainSubstring("deployed"))
+		_, ok := loadStepLog(ctx, ds, objects, &model.StepLog{Resource: stepLogResourceApplication, Entity: "retention-app", Record: "retention-app-v0", Step: "deploy"})
+		Expect(ok).Should(BeFalse())
+
+		By("the records are not pruned if they could not be archived")
+		workflowService.LogStore = logstore.New(logstore.Config{})
+		addRecords(ctx, "recent-app", 20*day)
+		Expect(workflowService.PruneWorkflowRecords(ctx)).Should(BeNil())
+		Expect(recordNames(ctx, "recent-app")).Should(HaveLen(2))
+	})
+})
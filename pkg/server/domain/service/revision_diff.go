@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+// revisionEnvVar the env entry of the webservice like components
+type revisionEnvVar struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	ValueFrom *struct {
+		SecretKeyRef    *revisionKeyRef `json:"secretKeyRef,omitempty"`
+		ConfigMapKeyRef *revisionKeyRef `json:"configMapKeyRef,omitempty"`
+	} `json:"valueFrom,omitempty"`
+}
+
+type revisionKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// revisionMount the config map or the secret mounted by the storage trait
+type revisionMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+}
+
+// revisionOperationalSpec the env values, the config references and the images of the components in a revision
+type revisionOperationalSpec struct {
+	env        map[string]interface{}
+	configRefs map[string]interface{}
+	images     map[string]interface{}
+}
+
+// getPreviousRevision returns the latest revision of the same env deployed before the revision, nil if there is none
+func (c *applicationServiceImpl) getPreviousRevision(ctx context.Context, revision *model.ApplicationRevision) (*model.ApplicationRevision, error) {
+	revisions, err := c.Store.List(ctx, &model.ApplicationRevision{AppPrimaryKey: revision.AppPrimaryKey, EnvName: revision.EnvName}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range revisions {
+		previous := entity.(*model.ApplicationRevision)
+		if previous.Version != revision.Version && previous.CreateTime.Before(revision.CreateTime) {
+			return previous, nil
+		}
+	}
+	return nil, nil
+}
+
+// diffRevisions compares the env values, the config references and the image of every component between the revisions
+func diffRevisions(previous, current *model.ApplicationRevision) (*apisv1.RevisionDiff, error) {
+	from, err := parseRevisionOperationalSpec(previous)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse the revision %s: %w", previous.Version, err)
+	}
+	to, err := parseRevisionOperationalSpec(current)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse the revision %s: %w", current.Version, err)
+	}
+	return &apisv1.RevisionDiff{
+		PreviousVersion: previous.Version,
+		Env:             nonNilChanges(diffSpec("", from.env, to.env)),
+		ConfigRefs:      nonNilChanges(diffSpec("", from.configRefs, to.configRefs)),
+		Images:          nonNilChanges(diffSpec("", from.images, to.images)),
+	}, nil
+}
+
+func nonNilChanges(changes []model.SpecChange) []model.SpecChange {
+	if changes == nil {
+		return []model.SpecChange{}
+	}
+	return changes
+}
+
+// parseRevisionOperationalSpec reads the env values and the config references from the env property,
+// the env trait and the storage trait, the paths are prefixed by the component name
+func parseRevisionOperationalSpec(revision *model.ApplicationRevision) (*revisionOperationalSpec, error) {
+	spec := &revisionOperationalSpec{env: map[string]interface{}{}, configRefs: map[string]interface{}{}, images: map[string]interface{}{}}
+	if revision.ApplyAppConfig == "" {
+		return spec, nil
+	}
+	var app v1beta1.Application
+	if err := yaml.Unmarshal([]byte(revision.ApplyAppConfig), &app); err != nil {
+		return nil, err
+	}
+	for _, component := range app.Spec.Components {
+		env := map[string]interface{}{}
+		refs := map[string]interface{}{}
+		var properties struct {
+			Image string           `json:"image"`
+			Env   []revisionEnvVar `json:"env"`
+		}
+		if component.Properties != nil {
+			// the properties of the other component types may not match, only the known fields are read
+			_ = json.Unmarshal(component.Properties.Raw, &properties)
+		}
+		for _, e := range properties.Env {
+			switch {
+			case e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil:
+				refs["env:"+e.Name] = fmt.Sprintf("secret:%s/%s", e.ValueFrom.SecretKeyRef.Name, e.ValueFrom.SecretKeyRef.Key)
+			case e.ValueFrom != nil && e.ValueFrom.ConfigMapKeyRef != nil:
+				refs["env:"+e.Name] = fmt.Sprintf("configMap:%s/%s", e.ValueFrom.ConfigMapKeyRef.Name, e.ValueFrom.ConfigMapKeyRef.Key)
+			default:
+				env[e.Name] = e.Value
+			}
+		}
+		for _, trait := range component.Traits {
+			if trait.Properties == nil {
+				continue
+			}
+			switch trait.Type {
+			case "env":
+				var traitProperties struct {
+					Env map[string]string `json:"env"`
+				}
+				_ = json.Unmarshal(trait.Properties.Raw, &traitProperties)
+				for k, v := range traitProperties.Env {
+					env[k] = v
+				}
+			case "storage":
+				var traitProperties struct {
+					ConfigMap []revisionMount `json:"configMap"`
+					Secret    []revisionMount `json:"secret"`
+				}
+				_ = json.Unmarshal(trait.Properties.Raw, &traitProperties)
+				for _, m := range traitProperties.ConfigMap {
+					refs["mount:"+m.MountPath] = "configMap:" + m.Name
+				}
+				for _, m := range traitProperties.Secret {
+					refs["mount:"+m.MountPath] = "secret:" + m.Name
+				}
+			}
+		}
+		if len(env) > 0 {
+			spec.env[component.Name] = env
+		}
+		if len(refs) > 0 {
+			spec.configRefs[component.Name] = refs
+		}
+		if properties.Image != "" {
+			spec.images[component.Name] = revisionImage(revision, properties.Image)
+		}
+	}
+	return spec, nil
+}
+
+// revisionImage appends the digest pushed by the image webhook to the tagged image,
+// the image pinned by the digest is returned as it is
+func revisionImage(revision *model.ApplicationRevision, image string) string {
+	if ref, err := name.ParseReference(image); err == nil {
+		if _, ok := ref.(name.Digest); ok {
+			return image
+		}
+	}
+	if revision.ImageInfo != nil && revision.ImageInfo.Resource != nil && revision.ImageInfo.Resource.Digest != "" && revision.ImageInfo.Resource.URL == image {
+		return image + "@" + revision.ImageInfo.Resource.Digest
+	}
+	return image
+}
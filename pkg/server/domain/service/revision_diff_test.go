@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+)
+
+func TestDiffRevisions(t *testing.T) {
+	previous := &model.ApplicationRevision{
+		Version: "v1",
+		ApplyAppConfig: `{"apiVersion":"core.oam.dev/v1beta1","kind":"Application","metadata":{"name":"app"},"spec":{"components":[{"name":"web","type":"webservice",` +
+			`"properties":{"image":"nginx:1.24","env":[{"name":"LOG_LEVEL","value":"info"},{"name":"DB_PASSWORD","valueFrom":{"secretKeyRef":{"name":"db","key":"password"}}}]},` +
+			`"traits":[{"type":"storage","properties":{"configMap":[{"name":"web-conf","mountPath":"/etc/web"}]}}]}]}}`,
+	}
+	current := &model.ApplicationRevision{
+		Version: "v2",
+		ApplyAppConfig: `{"apiVersion":"core.oam.dev/v1beta1","kind":"Application","metadata":{"name":"app"},"spec":{"components":[{"name":"web","type":"webservice",` +
+			`"properties":{"image":"nginx:1.25","env":[{"name":"LOG_LEVEL","value":"debug"},{"name":"DB_PASSWORD","valueFrom":{"secretKeyRef":{"name":"db-v2","key":"password"}}}]},` +
+			`"traits":[{"type":"env","properties":{"env":{"FEATURE_X":"on"}}},{"type":"storage","properties":{"configMap":[{"name":"web-conf","mountPath":"/etc/web"}]}}]}]}}`,
+		ImageInfo: &model.ImageInfo{Resource: &model.ImageResource{URL: "nginx:1.25", Digest: "sha256:abc"}},
+	}
+	diff, err := diffRevisions(previous, current)
+	assert.NilError(t, err)
+	assert.Equal(t, diff.PreviousVersion, "v1")
+	assert.DeepEqual(t, diff.Env, []model.SpecChange{
+		{Path: "web.FEATURE_X", Operation: model.SpecChangeAdd, To: `"on"`},
+		{Path: "web.LOG_LEVEL", Operation: model.SpecChangeReplace, From: `"info"`, To: `"debug"`},
+	})
+	assert.DeepEqual(t, diff.ConfigRefs, []model.SpecChange{
+		{Path: "web.env:DB_PASSWORD", Operation: model.SpecChangeReplace, From: `"secret:db/password"`, To: `"secret:db-v2/password"`},
+	})
+	assert.DeepEqual(t, diff.Images, []model.SpecChange{
+		{Path: "web", Operation: model.SpecChangeReplace, From: `"nginx:1.24"`, To: `"nginx:1.25@sha256:abc"`},
+	})
+
+	unchanged, err := diffRevisions(previous, previous)
+	assert.NilError(t, err)
+	assert.Equal(t, len(unchanged.Env)+len(unchanged.ConfigRefs)+len(unchanged.Images), 0)
+}
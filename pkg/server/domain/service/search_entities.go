@@ -0,0 +1,264 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pkgUtils "github.com/oam-dev/kubevela/pkg/utils"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// SearchEntityApplication the applications matched by the name, the alias and the description
+	SearchEntityApplication = "application"
+	// SearchEntityPipeline the pipelines matched by the name, the alias and the description
+	SearchEntityPipeline = "pipeline"
+	// SearchEntityUser the users matched by the name, the alias and the email
+	SearchEntityUser = "user"
+	// SearchEntityConfig the configs matched by the name, the alias and the description
+	SearchEntityConfig = "config"
+
+	// searchEntityCandidates how many entities of a kind are matched before they are filtered by the permissions
+	searchEntityCandidates   = 200
+	defaultSearchEntityLimit = 10
+	maxSearchEntityLimit     = 50
+)
+
+var searchEntityKinds = []string{SearchEntityApplication, SearchEntityPipeline, SearchEntityUser, SearchEntityConfig}
+
+// entitySearch is a global search of the login user, the permissions of the projects are loaded once
+type entitySearch struct {
+	*searchServiceImpl
+	user        *model.User
+	keywords    []string
+	text        string
+	limit       int
+	projects    []string
+	permissions map[string][]*model.Permission
+}
+
+// SearchEntities searches the entities of each kind by the datastore, except the configs that are stored in the cluster and matched in memory.
+// At most the limit entities of each kind are returned.
+func (s *searchServiceImpl) SearchEntities(ctx context.Context, text string, kinds []string, limit int) (*apisv1.SearchEntitiesResponse, error) {
+	keywords := datastore.SearchKeywords(text)
+	if len(keywords) == 0 {
+		return nil, bcode.ErrSearchKeywordRequired
+	}
+	if len(kinds) == 0 {
+		kinds = searchEntityKinds
+	}
+	for _, kind := range kinds {
+		if !pkgUtils.StringsContain(searchEntityKinds, kind) {
+			return nil, bcode.ErrInvalidSearchEntityKind
+		}
+	}
+	if limit <= 0 {
+		limit = defaultSearchEntityLimit
+	}
+	if limit > maxSearchEntityLimit {
+		limit = maxSearchEntityLimit
+	}
+	userName, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return nil, bcode.ErrUnauthorized
+	}
+	user := &model.User{Name: userName}
+	if err := s.Store.Get(ctx, user); err != nil {
+		return nil, err
+	}
+	projects, err := s.ProjectService.ListUserProjects(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+	search := &entitySearch{searchServiceImpl: s, user: user, keywords: keywords, text: text, limit: limit, permissions: map[string][]*model.Permission{}}
+	for _, project := range projects {
+		search.projects = append(search.projects, project.Name)
+	}
+	res := &apisv1.SearchEntitiesResponse{Hits: []*apisv1.SearchEntityHit{}}
+	for _, kind := range kinds {
+		var hits []*apisv1.SearchEntityHit
+		switch kind {
+		case SearchEntityApplication:
+			hits, err = search.applications(ctx)
+		case SearchEntityPipeline:
+			hits, err = search.pipelines(ctx)
+		case SearchEntityUser:
+			hits, err = search.users(ctx)
+		case SearchEntityConfig:
+			hits, err = search.configs(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		res.Hits = append(res.Hits, hits...)
+	}
+	return res, nil
+}
+
+// projectPermissions the permissions of the user in the project with the platform permissions, the project is empty for the platform
+func (e *entitySearch) projectPermissions(ctx context.Context, project string) ([]*model.Permission, error) {
+	if perms, ok := e.permissions[project]; ok {
+		return perms, nil
+	}
+	perms, err := e.RbacService.GetUserPermissions(ctx, e.user, project, true)
+	if err != nil {
+		return nil, err
+	}
+	e.permissions[project] = perms
+	return perms, nil
+}
+
+func (e *entitySearch) projectOptions() *datastore.ListOptions {
+	return &datastore.ListOptions{
+		FilterOptions: datastore.FilterOptions{In: []datastore.InQueryOption{{Key: "project", Values: e.projects}}},
+		Page:          1,
+		PageSize:      searchEntityCandidates,
+	}
+}
+
+func (e *entitySearch) applications(ctx context.Context) ([]*apisv1.SearchEntityHit, error) {
+	if len(e.projects) == 0 {
+		return nil, nil
+	}
+	entities, err := e.Store.Search(ctx, &model.Application{}, e.text, e.projectOptions())
+	if err != nil {
+		return nil, err
+	}
+	var hits []*apisv1.SearchEntityHit
+	for _, entity := range entities {
+		app := entity.(*model.Application)
+		perms, err := e.projectPermissions(ctx, app.Project)
+		if err != nil {
+			return nil, err
+		}
+		if !matchPermission(perms, fmt.Sprintf("project:%s/application:%s", app.Project, app.Name), app.Labels, "detail") {
+			continue
+		}
+		hits = append(hits, &apisv1.SearchEntityHit{Kind: SearchEntityApplication, Name: app.Name, Alias: app.Alias, Description: app.Description, Project: app.Project})
+		if len(hits) >= e.limit {
+			break
+		}
+	}
+	return hits, nil
+}
+
+func (e *entitySearch) pipelines(ctx context.Context) ([]*apisv1.SearchEntityHit, error) {
+	if len(e.projects) == 0 {
+		return nil, nil
+	}
+	entities, err := e.Store.Search(ctx, &model.Pipeline{}, e.text, e.projectOptions())
+	if err != nil {
+		return nil, err
+	}
+	var hits []*apisv1.SearchEntityHit
+	for _, entity := range entities {
+		pipeline := entity.(*model.Pipeline)
+		perms, err := e.projectPermissions(ctx, pipeline.Project)
+		if err != nil {
+			return nil, err
+		}
+		if !matchPermission(perms, fmt.Sprintf("project:%s/pipeline:%s", pipeline.Project, pipeline.Name), nil, "detail") {
+			continue
+		}
+		hits = append(hits, &apisv1.SearchEntityHit{Kind: SearchEntityPipeline, Name: pipeline.Name, Alias: pipeline.Alias, Description: pipeline.Description, Project: pipeline.Project})
+		if len(hits) >= e.limit {
+			break
+		}
+	}
+	return hits, nil
+}
+
+func (e *entitySearch) users(ctx context.Context) ([]*apisv1.SearchEntityHit, error) {
+	perms, err := e.projectPermissions(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	entities, err := e.Store.Search(ctx, &model.User{}, e.text, &datastore.ListOptions{Page: 1, PageSize: searchEntityCandidates})
+	if err != nil {
+		return nil, err
+	}
+	var hits []*apisv1.SearchEntityHit
+	for _, entity := range entities {
+		user := entity.(*model.User)
+		if !matchPermission(perms, fmt.Sprintf("user:%s", user.Name), nil, "detail") {
+			continue
+		}
+		hits = append(hits, &apisv1.SearchEntityHit{Kind: SearchEntityUser, Name: user.Name, Alias: user.Alias, Description: user.Email})
+		if len(hits) >= e.limit {
+			break
+		}
+	}
+	return hits, nil
+}
+
+// configs matches the configs of the platform and the projects in memory, the configs are the secrets of the cluster
+func (e *entitySearch) configs(ctx context.Context) ([]*apisv1.SearchEntityHit, error) {
+	var hits []*apisv1.SearchEntityHit
+	for _, project := range append([]string{""}, e.projects...) {
+		perms, err := e.projectPermissions(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		configs, err := e.ConfigService.ListConfigs(ctx, project, "", false)
+		if err != nil {
+			return nil, err
+		}
+		for _, config := range configs {
+			// the project scope lists the shared platform configs as well, they are matched in the platform scope
+			if (project != "" && config.Shared) || !matchSearchKeywords(e.keywords, config.Name, config.Alias, config.Description) {
+				continue
+			}
+			resource := "config:*"
+			if project != "" {
+				resource = fmt.Sprintf("project:%s/config:%s", project, config.Name)
+			}
+			if !matchPermission(perms, resource, nil, "list") {
+				continue
+			}
+			hits = append(hits, &apisv1.SearchEntityHit{Kind: SearchEntityConfig, Name: config.Name, Alias: config.Alias, Description: config.Description, Project: project})
+			if len(hits) >= e.limit {
+				return hits, nil
+			}
+		}
+	}
+	return hits, nil
+}
+
+// matchSearchKeywords checks whether each keyword is a prefix of a word of the texts, the same as the datastore search
+func matchSearchKeywords(keywords []string, texts ...string) bool {
+	words := datastore.SearchKeywords(strings.Join(texts, " "))
+	for _, keyword := range keywords {
+		matched := false
+		for _, word := range words {
+			if strings.HasPrefix(word, keyword) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/searchindex"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// SearchKindAudit the indexed spec audits of the applications and the pipelines
+	SearchKindAudit = "audit"
+	// SearchKindLog the indexed step logs of the workflows and the pipeline runs
+	SearchKindLog = "log"
+
+	searchIndexBatchSize = 100
+	// maxIndexedLogSize the logs larger than it are truncated before indexing
+	maxIndexedLogSize     = 1024 * 1024
+	defaultSearchPageSize = 20
+)
+
+// SearchService ships the audits and the logs to the search index and searches them by the permissions of the login user
+type SearchService interface {
+	IndexIncrementally(ctx context.Context) error
+	Search(ctx context.Context, projectName, kind, text string, page, pageSize int) (*apisv1.SearchResponse, error)
+	// SearchEntities searches the applications, the pipelines, the users and the configs by the keywords, only the entities
+	// that the login user could read are returned
+	SearchEntities(ctx context.Context, text string, kinds []string, limit int) (*apisv1.SearchEntitiesResponse, error)
+}
+
+type searchServiceImpl struct {
+	Store          datastore.DataStore `inject:"datastore"`
+	Indexer        searchindex.Indexer `inject:"searchIndexer"`
+	LogStore       logstore.Store      `inject:"logStore"`
+	RbacService    RBACService         `inject:""`
+	ProjectService ProjectService      `inject:""`
+	ConfigService  ConfigService       `inject:""`
+}
+
+// NewSearchService new search service
+func NewSearchService() SearchService {
+	return &searchServiceImpl{}
+}
+
+// indexedDocument is a record converted to the document, the records without the project are skipped
+type indexedDocument struct {
+	doc        *searchindex.Document
+	createTime time.Time
+}
+
+// IndexIncrementally indexes the audits and the logs created since the previous run
+func (s *searchServiceImpl) IndexIncrementally(ctx context.Context) error {
+	if !s.Indexer.Enabled() {
+		return nil
+	}
+	if err := s.indexKind(ctx, SearchKindAudit, &model.SpecAudit{}, func(entity datastore.Entity) indexedDocument {
+		return s.auditDocument(entity.(*model.SpecAudit))
+	}); err != nil {
+		return err
+	}
+	projects := map[string]string{}
+	return s.indexKind(ctx, SearchKindLog, &model.StepLog{}, func(entity datastore.Entity) indexedDocument {
+		return s.logDocument(ctx, entity.(*model.StepLog), projects)
+	})
+}
+
+// indexKind lists the records from the latest until the checkpoint, the records created at the checkpoint are indexed again
+// because the documents are replaced by the IDs.
+func (s *searchServiceImpl) indexKind(ctx context.Context, kind string, filter datastore.Entity, convert func(entity datastore.Entity) indexedDocument) error {
+	checkpoint := &model.SearchIndexCheckpoint{Kind: kind}
+	exist := true
+	if err := s.Store.Get(ctx, checkpoint); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		exist = false
+	}
+	var pending []indexedDocument
+	for page := 1; ; page++ {
+		entities, err := s.Store.List(ctx, filter, &datastore.ListOptions{Page: page, PageSize: searchIndexBatchSize, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+		if err != nil {
+			return err
+		}
+		reached := false
+		for _, entity := range entities {
+			indexed := convert(entity)
+			if indexed.createTime.Before(checkpoint.LastTime) {
+				reached = true
+				break
+			}
+			pending = append(pending, indexed)
+		}
+		if reached || len(entities) < searchIndexBatchSize {
+			break
+		}
+	}
+	lastTime := checkpoint.LastTime
+	var docs []searchindex.Document
+	for _, indexed := range pending {
+		if indexed.createTime.After(lastTime) {
+			lastTime = indexed.createTime
+		}
+		if indexed.doc != nil {
+			docs = append(docs, *indexed.doc)
+		}
+	}
+	for start := 0; start < len(docs); start += searchIndexBatchSize {
+		end := start + searchIndexBatchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+		// the checkpoint is not moved if any batch fails, the whole range is indexed again by the next run
+		if err := s.Indexer.Bulk(ctx, docs[start:end]); err != nil {
+			return err
+		}
+	}
+	if lastTime.Equal(checkpoint.LastTime) {
+		return nil
+	}
+	checkpoint.LastTime = lastTime
+	if exist {
+		return s.Store.Put(ctx, checkpoint)
+	}
+	return s.Store.Add(ctx, checkpoint)
+}
+
+func (s *searchServiceImpl) auditDocument(audit *model.SpecAudit) indexedDocument {
+	indexed := indexedDocument{createTime: audit.CreateTime}
+	if audit.Project == "" {
+		return indexed
+	}
+	indexed.doc = &searchindex.Document{
+		Index: s.Indexer.IndexName(SearchKindAudit, audit.Project),
+		ID:    audit.Name,
+		Body: map[string]interface{}{
+			"kind":        SearchKindAudit,
+			"time":        audit.CreateTime,
+			"project":     audit.Project,
+			"resource":    audit.Resource,
+			"entity":      audit.Entity,
+			"subResource": audit.SubResource,
+			"operator":    audit.Operator,
+			"changes":     audit.Changes,
+		},
+	}
+	return indexed
+}
+
+// logDocument converts the step log, the projects of the applications are cached in the run
+func (s *searchServiceImpl) logDocument(ctx context.Context, stepLog *model.StepLog, projects map[string]string) indexedDocument {
+	indexed := indexedDocument{createTime: stepLog.CreateTime}
+	project := stepLog.Project
+	if project == "" && stepLog.Resource == stepLogResourceApplication {
+		var ok bool
+		if project, ok = projects[stepLog.Entity]; !ok {
+			app := &model.Application{Name: stepLog.Entity}
+			if err := s.Store.Get(ctx, app); err == nil {
+				project = app.Project
+			}
+			projects[stepLog.Entity] = project
+		}
+	}
+	if project == "" {
+		return indexed
+	}
+	content := stepLog.Content
+	if stepLog.ObjectKey != "" {
+		data, err := s.LogStore.Get(ctx, stepLog.ObjectKey)
+		if err != nil {
+			klog.Warningf("failed to get the logs object %s to index: %s", stepLog.ObjectKey, err.Error())
+		} else {
+			content = string(data)
+		}
+	}
+	if len(content) > maxIndexedLogSize {
+		content = content[len(content)-maxIndexedLogSize:]
+	}
+	indexed.doc = &searchindex.Document{
+		Index: s.Indexer.IndexName(SearchKindLog, project),
+		ID:    stepLog.Name,
+		Body: map[string]interface{}{
+			"kind":     SearchKindLog,
+			"time":     stepLog.CreateTime,
+			"project":  project,
+			"resource": stepLog.Resource,
+			"entity":   stepLog.Entity,
+			"record":   stepLog.Record,
+			"step":     stepLog.Step,
+			"content":  content,
+		},
+	}
+	return indexed
+}
+
+// Search searches the index of the project, only the documents of the applications and the pipelines
+// that the login user could read are returned
+func (s *searchServiceImpl) Search(ctx context.Context, projectName, kind, text string, page, pageSize int) (*apisv1.SearchResponse, error) {
+	if !s.Indexer.Enabled() {
+		return nil, bcode.ErrSearchNotEnabled
+	}
+	if kind != SearchKindAudit && kind != SearchKindLog {
+		return nil, bcode.ErrInvalidSearchKind
+	}
+	entities, err := s.readableEntities(ctx, projectName, kind)
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.SearchResponse{Hits: []*apisv1.SearchHit{}}
+	if entities != nil && len(entities) == 0 {
+		return res, nil
+	}
+	if pageSize <= 0 {
+		pageSize = defaultSearchPageSize
+	}
+	if page < 1 {
+		page = 1
+	}
+	query := searchindex.Query{Text: text, SortField: "time", From: (page - 1) * pageSize, Size: pageSize}
+	if entities != nil {
+		query.Terms = map[string][]string{"entity.keyword": entities}
+	}
+	result, err := s.Indexer.Search(ctx, s.Indexer.IndexName(kind, projectName), query)
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range result.Hits {
+		res.Hits = append(res.Hits, &apisv1.SearchHit{ID: hit.ID, Source: hit.Source})
+	}
+	res.Total = result.Total
+	return res, nil
+}
+
+// readableEntities returns the applications and the pipelines of the project that the login user could read the audits or the logs of,
+// nil means all entities are readable
+func (s *searchServiceImpl) readableEntities(ctx context.Context, projectName, kind string) ([]string, error) {
+	userName, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return nil, bcode.ErrUnauthorized
+	}
+	user := &model.User{Name: userName}
+	if err := s.Store.Get(ctx, user); err != nil {
+		return nil, err
+	}
+	permissions, err := s.RbacService.GetUserPermissions(ctx, user, projectName, true)
+	if err != nil {
+		return nil, err
+	}
+	appResource, pipelineResource := "project:%s/application:%s", "project:%s/pipeline:%s"
+	if kind == SearchKindLog {
+		appResource, pipelineResource = "project:%s/application:%s/workflow:*/record:*", "project:%s/pipeline:%s/pipelineRun:*"
+	}
+	readable := func(resource, name string, labels map[string]string) bool {
+		return matchPermission(permissions, fmt.Sprintf(resource, projectName, name), labels, "detail")
+	}
+	if readable(appResource, "*", nil) && readable(pipelineResource, "*", nil) {
+		return nil, nil
+	}
+	entities := []string{}
+	apps, err := s.Store.List(ctx, &model.Application{Project: projectName}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range apps {
+		app := entity.(*model.Application)
+		if readable(appResource, app.Name, app.Labels) {
+			entities = append(entities, app.Name)
+		}
+	}
+	pipelines, err := s.Store.List(ctx, &model.Pipeline{Project: projectName}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range pipelines {
+		pipeline := entity.(*model.Pipeline)
+		if readable(pipelineResource, pipeline.Name, nil) {
+			entities = append(entities, pipeline.Name)
+		}
+	}
+	return entities, nil
+}
+
+// matchPermission checks whether the permissions allow the action on the resource, the labels are matched by the
+// label selectors of the application permissions
+func matchPermission(permissions []*model.Permission, resource string, labels map[string]string, action string) bool {
+	ra := &RequestResourceAction{}
+	ra.SetResourceWithName(resource, func(string) string { return "" })
+	if labels != nil {
+		ra.SetResourceLabels("application", labels)
+	}
+	ra.SetActions([]string{action})
+	return ra.Match(permissions)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/searchindex"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+type fakeIndexer struct {
+	docs  []searchindex.Document
+	index string
+	query searchindex.Query
+}
+
+func (f *fakeIndexer) Enabled() bool {
+	return true
+}
+
+func (f *fakeIndexer) IndexName(kind, project string) string {
+	return kind + "-" + project
+}
+
+func (f *fakeIndexer) Bulk(_ context.Context, docs []searchindex.Document) error {
+	f.docs = append(f.docs, docs...)
+	return nil
+}
+
+func (f *fakeIndexer) Search(_ context.Context, index string, query searchindex.Query) (*searchindex.Result, error) {
+	f.index = index
+	f.query = query
+	return &searchindex.Result{Total: 1, Hits: []searchindex.Hit{{ID: "hit-1", Source: []byte(`{"entity":"search-app"}`)}}}, nil
+}
+
+var _ = Describe("Test the search of the audits and the logs", func() {
+	var (
+		ds            datastore.DataStore
+		indexer       *fakeIndexer
+		searchService *searchServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "search-index-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		indexer = &fakeIndexer{}
+		searchService = &searchServiceImpl{Store: ds, Indexer: indexer, RbacService: &rbacServiceImpl{Store: ds}, ProjectService: NewTestProjectService(ds, k8sClient)}
+	})
+
+	It("Test index the new audits and logs incrementally", func() {
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Application{Name: "search-app", Project: "search-project"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.SpecAudit{Name: "audit-1", Resource: "application", Project: "search-project", Entity: "search-app", Operator: "admin"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.StepLog{Name: "log-1", Resource: stepLogResourceApplication, Entity: "search-app", Record: "search-app-v1", Step: "deploy", Content: "rollout succeeded"})).Should(BeNil())
+		By("the logs of the removed applications are skipped")
+		Expect(ds.Add(ctx, &model.StepLog{Name: "log-2", Resource: stepLogResourceApplication, Entity: "removed-app", Record: "removed-app-v1", Step: "deploy"})).Should(BeNil())
+
+		Expect(searchService.IndexIncrementally(ctx)).Should(BeNil())
+		Expect(indexer.docs).Should(HaveLen(2))
+		Expect(indexer.docs[0].Index).Should(Equal("audit-search-project"))
+		Expect(indexer.docs[1].Index).Should(Equal("log-search-project"))
+		Expect(indexer.docs[1].Body).Should(HaveKeyWithValue("content", "rollout succeeded"))
+
+		checkpoint := &model.SearchIndexCheckpoint{Kind: SearchKindAudit}
+		Expect(ds.Get(ctx, checkpoint)).Should(BeNil())
+		Expect(checkpoint.LastTime.IsZero()).Should(BeFalse())
+
+		By("only the records since the checkpoint are indexed again")
+		indexer.docs = nil
+		time.Sleep(10 * time.Millisecond)
+		Expect(ds.Add(ctx, &model.SpecAudit{Name: "audit-2", Resource: "application", Project: "search-project", Entity: "search-app"})).Should(BeNil())
+		Expect(searchService.IndexIncrementally(ctx)).Should(BeNil())
+		var ids []string
+		for _, doc := range indexer.docs {
+			ids = append(ids, doc.ID)
+		}
+		Expect(ids).Should(ContainElement("audit-2"))
+		Expect(ids).ShouldNot(ContainElement("audit-1"))
+	})
+
+	It("Test search by the permissions of the user", func() {
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Application{Name: "search-app", Project: "search-project"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Application{Name: "secret-app", Project: "search-project"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Permission{Name: "search-view", Project: "search-project", Resources: []string{"project:search-project/application:search-app"}, Actions: []string{"detail"}, Effect: "Allow"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Role{Name: "search-viewer", Project: "search-project", Permissions: []string{"search-view"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.User{Name: "search-user"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: "search-project", Username: "search-user", UserRoles: []string{"search-viewer"}})).Should(BeNil())
+
+		userCtx := context.WithValue(ctx, &apisv1.CtxKeyUser, "search-user")
+		_, err := searchService.Search(userCtx, "search-project", "unknown", "", 0, 10)
+		Expect(err).Should(Equal(bcode.ErrInvalidSearchKind))
+
+		res, err := searchService.Search(userCtx, "search-project", SearchKindAudit, "replicas", 2, 10)
+		Expect(err).Should(BeNil())
+		Expect(res.Total).Should(Equal(int64(1)))
+		Expect(indexer.index).Should(Equal("audit-search-project"))
+		Expect(indexer.query.From).Should(Equal(10))
+		Expect(indexer.query.Terms["entity.keyword"]).Should(Equal([]string{"search-app"}))
+
+		By("the users without any readable entity get nothing")
+		indexer.query = searchindex.Query{}
+		res, err = searchService.Search(userCtx, "search-project", SearchKindLog, "", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(res.Hits).Should(BeEmpty())
+		Expect(indexer.query.Size).Should(Equal(0))
+	})
+
+	It("Test search the entities by the permissions of the user", func() {
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Project{Name: "search-project"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Application{Name: "search-app", Alias: "Checkout", Project: "search-project", Description: "the checkout service"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Application{Name: "secret-app", Project: "search-project", Description: "the checkout secrets"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Permission{Name: "search-view", Project: "search-project", Resources: []string{"project:search-project/application:search-app"}, Actions: []string{"detail"}, Effect: "Allow"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Role{Name: "search-viewer", Project: "search-project", Permissions: []string{"search-view"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.User{Name: "search-user"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.User{Name: "checkout-bot"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: "search-project", Username: "search-user", UserRoles: []string{"search-viewer"}})).Should(BeNil())
+
+		userCtx := context.WithValue(ctx, &apisv1.CtxKeyUser, "search-user")
+		_, err := searchService.SearchEntities(userCtx, " - ", nil, 0)
+		Expect(err).Should(Equal(bcode.ErrSearchKeywordRequired))
+		_, err = searchService.SearchEntities(userCtx, "checkout", []string{"project"}, 0)
+		Expect(err).Should(Equal(bcode.ErrInvalidSearchEntityKind))
+
+		res, err := searchService.SearchEntities(userCtx, "check", []string{SearchEntityApplication, SearchEntityUser}, 0)
+		Expect(err).Should(BeNil())
+		Expect(res.Hits).Should(HaveLen(1))
+		Expect(res.Hits[0].Kind).Should(Equal(SearchEntityApplication))
+		Expect(res.Hits[0].Name).Should(Equal("search-app"))
+		Expect(res.Hits[0].Project).Should(Equal("search-project"))
+
+		By("the users without any project get no application")
+		res, err = searchService.SearchEntities(context.WithValue(ctx, &apisv1.CtxKeyUser, "checkout-bot"), "checkout", []string{SearchEntityApplication}, 0)
+		Expect(err).Should(BeNil())
+		Expect(res.Hits).Should(BeEmpty())
+	})
+})
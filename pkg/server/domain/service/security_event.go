@@ -0,0 +1,42 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/securityevent"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+)
+
+// securityEvents streams the authentication events to the SIEM, it is set by the server config
+var securityEvents securityevent.Emitter = securityevent.New(securityevent.Config{})
+
+// emitSecurityEvent fills the request info from the context and emits the event
+func emitSecurityEvent(ctx context.Context, event securityevent.Event) {
+	if !securityEvents.Enabled() {
+		return
+	}
+	if event.Operator == "" {
+		event.Operator, _ = ctx.Value(&apisv1.CtxKeyUser).(string)
+	}
+	event.ClientIP, _ = utils.ClientIPFrom(ctx)
+	event.UserAgent, _ = utils.UserAgentFrom(ctx)
+	event.RequestID, _ = utils.RequestIDFrom(ctx)
+	securityEvents.Emit(event)
+}
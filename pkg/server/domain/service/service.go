@@ -20,16 +20,80 @@ import (
 	"context"
 	"fmt"
 
+	"k8s.io/klog/v2"
+
 	"github.com/kubevela/velaux/pkg/server/config"
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/securityevent"
+	"github.com/kubevela/velaux/pkg/server/utils"
 )
 
 // needInitData register the service that need to init data
 var needInitData []DataInit
 
+// needInitTenantData the services that init the data of every tenant, the addons and the declarative RBAC are shared
+// by the tenants so they are only initialized in the default partition
+var needInitTenantData []DataInit
+
+// tenants the organizations hosted in the isolated partitions of the datastore
+var tenants []string
+
 // InitServiceBean init all service instance
 func InitServiceBean(c config.Config) []interface{} {
+	if c.ProjectQuotaWarningThreshold > 0 {
+		projectQuotaWarningThreshold = c.ProjectQuotaWarningThreshold
+	}
+	projectMemberWebhook = c.ProjectMemberWebhook
+	tenants = c.Datastore.Tenants
+	securityEvents = securityevent.New(c.SecurityEvents)
+	serviceCatalogApproval = c.ServiceCatalogApproval
+	rbacBootstrapFile = c.RBACBootstrapFile
+	rbacBootstrapConfigMap = c.RBACBootstrapConfigMap
+	loginMaxFailures = c.LoginMaxFailures
+	loginLockoutDuration = c.LoginLockoutDuration
+	userDeactivationNotice = c.UserDeactivationNotice
+	bootstrapTokenEnabled = c.EnableBootstrapToken || c.BootstrapTokenSecret != ""
+	bootstrapTokenSecret = c.BootstrapTokenSecret
+	randomAdminPassword = c.RandomAdminPassword
+	gravatarEnabled = c.EnableGravatar
+	if c.WebhookSignatureTolerance > 0 {
+		webhookSignatureTolerance = c.WebhookSignatureTolerance
+	}
+	if c.LoginHistoryRetention > 0 {
+		loginHistoryRetention = c.LoginHistoryRetention
+	}
+	trashRetention = c.TrashRetention
+	orphanGCPolicy = c.OrphanGCPolicy
+	if c.OrphanGCGracePeriod > 0 {
+		orphanGCGracePeriod = c.OrphanGCGracePeriod
+	}
+	defaultSyncScope = model.SyncScope{
+		IncludeNamespaces: c.ExternalApplicationNamespaces,
+		ExcludeNamespaces: c.SyncExcludeNamespaces,
+		IncludeClusters:   c.SyncIncludeClusters,
+		ExcludeClusters:   c.SyncExcludeClusters,
+		LabelSelector:     c.SyncLabelSelector,
+	}
+	specAuditRetention = c.SpecAuditRetention
+	stepLogRetention = c.StepLogRetention
+	collectStepLogs = c.CollectStepLogs
+	migrationTargetVersion = c.MigrationTargetVersion
+	if c.StepRegressionThreshold > 0 {
+		stepRegressionThreshold = c.StepRegressionThreshold
+	}
+	if err := setLintRuleSeverities(c.LintRuleSeverities); err != nil {
+		klog.Errorf("failed to set the severities of the lint rules: %s", err.Error())
+	}
+	if len(c.RedactionPatterns) > 0 || len(c.RedactionFields) > 0 {
+		r, err := utils.NewRedactor(c.RedactionPatterns, c.RedactionFields)
+		if err != nil {
+			klog.Errorf("failed to init the redactor: %s", err.Error())
+		}
+		redactor = r
+	}
 	clusterService := NewClusterService()
-	rbacService := NewRBACService()
+	rbacService := NewRBACService(c.EnableKubeRBACPropagation)
 	projectService := NewProjectService()
 	envService := NewEnvService()
 	targetService := NewTargetService()
@@ -49,12 +113,23 @@ func InitServiceBean(c config.Config) []interface{} {
 	pipelineService := NewPipelineService(c.WorkflowVersion)
 	pipelineRunService := NewPipelineRunService()
 	contextService := NewContextService()
-	needInitData = []DataInit{clusterService, userService, rbacService, projectService, targetService, systemInfoService, addonService}
+	rbacBootstrap := NewRBACBootstrap()
+	apiTokenService := NewAPITokenService()
+	sessionService := NewSessionService()
+	bootstrapService := NewBootstrapService()
+	migrationService := NewMigrationService()
+	// the migrations run at last, so they migrate the default data created by the other services as well
+	needInitData = []DataInit{clusterService, userService, bootstrapService, rbacService, projectService, targetService, systemInfoService, addonService, rbacBootstrap, apiTokenService, sessionService, migrationService}
+	needInitTenantData = []DataInit{clusterService, userService, bootstrapService, rbacService, projectService, targetService, systemInfoService, apiTokenService, sessionService, migrationService}
 	return []interface{}{
 		clusterService, rbacService, projectService, envService, targetService, workflowService, oamApplicationService,
 		velaQLService, definitionService, addonService, envBindingService, systemInfoService, helmService, userService,
 		authenticationService, configService, applicationService, webhookService, pipelineService, pipelineRunService,
-		contextService, NewImageService(), NewCloudShellService(),
+		contextService, NewImageService(), NewCloudShellService(), NewServiceCatalogService(), NewDefinitionCatalogService(),
+		rbacBootstrap, NewPasswordResetService(), apiTokenService, sessionService, NewSpecAuditService(),
+		NewUserInvitationService(), NewStatusBadgeService(), bootstrapService, NewUserPreferenceService(),
+		NewEmailChangeService(), NewPermissionSnapshotService(), NewHealthService(),
+		NewSearchService(), NewPolicyBundleService(), NewTrashService(), NewInboxService(), NewSyncStatusService(), NewOrphanGCService(), migrationService,
 	}
 }
 
@@ -70,5 +145,13 @@ func InitData(ctx context.Context) error {
 			return fmt.Errorf("database init failure %w", err)
 		}
 	}
+	for _, tenant := range tenants {
+		tenantCtx := datastore.WithTenant(ctx, tenant)
+		for _, init := range needInitTenantData {
+			if err := init.Init(tenantCtx); err != nil {
+				return fmt.Errorf("database init of the tenant %s failure %w", tenant, err)
+			}
+		}
+	}
 	return nil
 }
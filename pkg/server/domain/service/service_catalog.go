@@ -0,0 +1,307 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// LabelServiceClass marks a component definition as a service class of the catalog,
+// the definitions based on terraform or the crossplane resources are always included.
+const LabelServiceClass = "ux.oam.dev/service-class"
+
+// labelServiceInstance marks the component that provisions the service instance
+const labelServiceInstance = "ux.oam.dev/service-instance"
+
+// serviceCatalogApproval whether the service instances require the approval of another user before they are provisioned
+var serviceCatalogApproval bool
+
+// ServiceCatalogService the self-service catalog of the managed services, such as the databases and the queues
+type ServiceCatalogService interface {
+	ListServiceClasses(ctx context.Context) (*apisv1.ListServiceClassesResponse, error)
+	ListServiceInstances(ctx context.Context, projectName string) (*apisv1.ListServiceInstancesResponse, error)
+	CreateServiceInstance(ctx context.Context, projectName string, req apisv1.CreateServiceInstanceRequest) (*apisv1.ServiceInstanceBase, error)
+	ApproveServiceInstance(ctx context.Context, projectName, instanceName string, req apisv1.ReviewServiceInstanceRequest) (*apisv1.ServiceInstanceBase, error)
+	RejectServiceInstance(ctx context.Context, projectName, instanceName string, req apisv1.ReviewServiceInstanceRequest) (*apisv1.ServiceInstanceBase, error)
+	DeleteServiceInstance(ctx context.Context, projectName, instanceName string) error
+}
+
+type serviceCatalogServiceImpl struct {
+	Store              datastore.DataStore `inject:"datastore"`
+	DefinitionService  DefinitionService   `inject:""`
+	ApplicationService ApplicationService  `inject:""`
+}
+
+// NewServiceCatalogService new service catalog service
+func NewServiceCatalogService() ServiceCatalogService {
+	return &serviceCatalogServiceImpl{}
+}
+
+// ListServiceClasses list the component definitions that provision the managed services
+func (s *serviceCatalogServiceImpl) ListServiceClasses(ctx context.Context) (*apisv1.ListServiceClassesResponse, error) {
+	defs, err := s.DefinitionService.ListDefinitions(ctx, DefinitionQueryOption{Type: "component"})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListServiceClassesResponse{ServiceClasses: []*apisv1.ServiceClassBase{}, ApprovalRequired: serviceCatalogApproval}
+	for _, def := range defs {
+		if provisioner := getServiceClassProvisioner(def); provisioner != "" {
+			res.ServiceClasses = append(res.ServiceClasses, &apisv1.ServiceClassBase{
+				Name:        def.Name,
+				Alias:       def.Alias,
+				Description: def.Description,
+				Icon:        def.Icon,
+				Labels:      def.Labels,
+				Provisioner: provisioner,
+			})
+		}
+	}
+	return res, nil
+}
+
+// getServiceClassProvisioner returns the provisioner of the managed service, or empty if the definition is not a service class
+func getServiceClassProvisioner(def *apisv1.DefinitionBase) string {
+	if def.Component == nil {
+		return ""
+	}
+	if def.Component.Schematic != nil && def.Component.Schematic.Terraform != nil {
+		return "terraform"
+	}
+	if strings.Contains(def.Component.Workload.Definition.APIVersion, "crossplane.io/") || def.Labels[LabelServiceClass] == "crossplane" {
+		return "crossplane"
+	}
+	if def.Labels[LabelServiceClass] == "terraform" {
+		return "terraform"
+	}
+	return ""
+}
+
+func (s *serviceCatalogServiceImpl) getServiceClass(ctx context.Context, name string) (*apisv1.ServiceClassBase, error) {
+	classes, err := s.ListServiceClasses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, class := range classes.ServiceClasses {
+		if class.Name == name {
+			return class, nil
+		}
+	}
+	return nil, bcode.ErrServiceClassNotExist
+}
+
+func (s *serviceCatalogServiceImpl) getServiceInstance(ctx context.Context, projectName, instanceName string) (*model.ServiceInstance, error) {
+	instance := &model.ServiceInstance{Project: projectName, Name: instanceName}
+	if err := s.Store.Get(ctx, instance); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrServiceInstanceNotExist
+		}
+		return nil, err
+	}
+	return instance, nil
+}
+
+// ListServiceInstances list the service instances of the project
+func (s *serviceCatalogServiceImpl) ListServiceInstances(ctx context.Context, projectName string) (*apisv1.ListServiceInstancesResponse, error) {
+	entities, err := s.Store.List(ctx, &model.ServiceInstance{Project: projectName}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListServiceInstancesResponse{ServiceInstances: []*apisv1.ServiceInstanceBase{}}
+	for _, entity := range entities {
+		res.ServiceInstances = append(res.ServiceInstances, convertServiceInstance2Base(entity.(*model.ServiceInstance)))
+	}
+	return res, nil
+}
+
+// CreateServiceInstance requests a managed service for the application, it is provisioned at once unless the approval is required
+func (s *serviceCatalogServiceImpl) CreateServiceInstance(ctx context.Context, projectName string, req apisv1.CreateServiceInstanceRequest) (*apisv1.ServiceInstanceBase, error) {
+	project := &model.Project{Name: projectName}
+	if err := s.Store.Get(ctx, project); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrProjectIsNotExist
+		}
+		return nil, err
+	}
+	if _, err := s.getServiceClass(ctx, req.ServiceClass); err != nil {
+		return nil, err
+	}
+	app, err := s.ApplicationService.GetApplication(ctx, req.AppName)
+	if err != nil {
+		return nil, err
+	}
+	if app.Project != projectName {
+		return nil, bcode.ErrApplicationNotExist
+	}
+	if project.Quota != nil && project.Quota.ServiceInstances > 0 {
+		count, err := s.Store.Count(ctx, &model.ServiceInstance{Project: projectName}, nil)
+		if err != nil {
+			return nil, err
+		}
+		if count >= project.Quota.ServiceInstances {
+			return nil, bcode.ErrServiceInstanceQuotaExceeded
+		}
+	}
+	requester, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	instance := &model.ServiceInstance{
+		Name:          req.Name,
+		Project:       projectName,
+		ServiceClass:  req.ServiceClass,
+		AppPrimaryKey: app.PrimaryKey(),
+		Properties:    req.Properties,
+		Status:        model.ServiceInstanceStatusPending,
+		Requester:     requester,
+	}
+	if err := s.Store.Add(ctx, instance); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrServiceInstanceExist
+		}
+		return nil, err
+	}
+	if !serviceCatalogApproval {
+		if err := s.provisionServiceInstance(ctx, app, instance); err != nil {
+			if err := s.Store.Delete(ctx, instance); err != nil {
+				klog.Errorf("failed to rollback the service instance %s: %s", instance.Name, err.Error())
+			}
+			return nil, err
+		}
+	}
+	warnProjectQuotaUsage(ctx, s.Store, projectName)
+	return convertServiceInstance2Base(instance), nil
+}
+
+// provisionServiceInstance adds the component of the service class to the application,
+// the managed service is created when the application is deployed.
+func (s *serviceCatalogServiceImpl) provisionServiceInstance(ctx context.Context, app *model.Application, instance *model.ServiceInstance) error {
+	if _, err := s.ApplicationService.CreateComponent(ctx, app, apisv1.CreateComponentRequest{
+		Name:          instance.Name,
+		ComponentType: instance.ServiceClass,
+		Properties:    instance.Properties,
+		Labels:        map[string]string{labelServiceInstance: instance.Name},
+	}); err != nil {
+		return err
+	}
+	instance.Status = model.ServiceInstanceStatusProvisioned
+	return s.Store.Put(ctx, instance)
+}
+
+// getPendingServiceInstance gets the service instance that could be reviewed by the login user
+func (s *serviceCatalogServiceImpl) getPendingServiceInstance(ctx context.Context, projectName, instanceName string) (*model.ServiceInstance, string, error) {
+	instance, err := s.getServiceInstance(ctx, projectName, instanceName)
+	if err != nil {
+		return nil, "", err
+	}
+	if instance.Status != model.ServiceInstanceStatusPending {
+		return nil, "", bcode.ErrServiceInstanceIsNotPending
+	}
+	reviewer, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if reviewer == instance.Requester {
+		return nil, "", bcode.ErrServiceInstanceApprovalBySelf
+	}
+	return instance, reviewer, nil
+}
+
+// ApproveServiceInstance provisions the pending service instance
+func (s *serviceCatalogServiceImpl) ApproveServiceInstance(ctx context.Context, projectName, instanceName string, req apisv1.ReviewServiceInstanceRequest) (*apisv1.ServiceInstanceBase, error) {
+	instance, reviewer, err := s.getPendingServiceInstance(ctx, projectName, instanceName)
+	if err != nil {
+		return nil, err
+	}
+	app, err := s.ApplicationService.GetApplication(ctx, instance.AppPrimaryKey)
+	if err != nil {
+		return nil, err
+	}
+	instance.Reviewer = reviewer
+	instance.Comment = req.Comment
+	if err := s.provisionServiceInstance(ctx, app, instance); err != nil {
+		return nil, err
+	}
+	return convertServiceInstance2Base(instance), nil
+}
+
+// RejectServiceInstance closes the pending service instance without provisioning it
+func (s *serviceCatalogServiceImpl) RejectServiceInstance(ctx context.Context, projectName, instanceName string, req apisv1.ReviewServiceInstanceRequest) (*apisv1.ServiceInstanceBase, error) {
+	instance, reviewer, err := s.getPendingServiceInstance(ctx, projectName, instanceName)
+	if err != nil {
+		return nil, err
+	}
+	instance.Status = model.ServiceInstanceStatusRejected
+	instance.Reviewer = reviewer
+	instance.Comment = req.Comment
+	if err := s.Store.Put(ctx, instance); err != nil {
+		return nil, err
+	}
+	return convertServiceInstance2Base(instance), nil
+}
+
+// DeleteServiceInstance removes the component of the service instance from the application, the managed service is deleted when the application is deployed
+func (s *serviceCatalogServiceImpl) DeleteServiceInstance(ctx context.Context, projectName, instanceName string) error {
+	instance, err := s.getServiceInstance(ctx, projectName, instanceName)
+	if err != nil {
+		return err
+	}
+	if instance.Status == model.ServiceInstanceStatusProvisioned {
+		app, err := s.ApplicationService.GetApplication(ctx, instance.AppPrimaryKey)
+		if err != nil && !errors.Is(err, bcode.ErrApplicationNotExist) {
+			return err
+		}
+		if app != nil {
+			component, err := s.ApplicationService.GetApplicationComponent(ctx, app, instance.Name)
+			if err != nil && !errors.Is(err, bcode.ErrApplicationComponentNotExist) {
+				return err
+			}
+			if component != nil {
+				if err := s.ApplicationService.DeleteComponent(ctx, app, component); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := s.Store.Delete(ctx, instance); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrServiceInstanceNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+func convertServiceInstance2Base(instance *model.ServiceInstance) *apisv1.ServiceInstanceBase {
+	return &apisv1.ServiceInstanceBase{
+		Name:         instance.Name,
+		Project:      instance.Project,
+		ServiceClass: instance.ServiceClass,
+		AppName:      instance.AppPrimaryKey,
+		Properties:   instance.Properties,
+		Status:       instance.Status,
+		Requester:    instance.Requester,
+		Reviewer:     instance.Reviewer,
+		Comment:      instance.Comment,
+		CreateTime:   instance.CreateTime,
+		UpdateTime:   instance.UpdateTime,
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the service catalog", func() {
+	var (
+		ds             datastore.DataStore
+		catalogService *serviceCatalogServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "service-catalog-test-kubevela"})
+		Expect(err).Should(BeNil())
+		catalogService = &serviceCatalogServiceImpl{Store: ds}
+	})
+
+	It("Test the provisioner of the service classes", func() {
+		Expect(getServiceClassProvisioner(&apisv1.DefinitionBase{})).Should(BeEmpty())
+		Expect(getServiceClassProvisioner(&apisv1.DefinitionBase{Component: &v1beta1.ComponentDefinitionSpec{
+			Schematic: &common.Schematic{Terraform: &common.Terraform{}},
+		}})).Should(Equal("terraform"))
+		Expect(getServiceClassProvisioner(&apisv1.DefinitionBase{Component: &v1beta1.ComponentDefinitionSpec{
+			Workload: common.WorkloadTypeDescriptor{Definition: common.WorkloadGVK{APIVersion: "database.example.crossplane.io/v1alpha1", Kind: "PostgreSQLInstance"}},
+		}})).Should(Equal("crossplane"))
+		Expect(getServiceClassProvisioner(&apisv1.DefinitionBase{
+			Labels:    map[string]string{LabelServiceClass: "crossplane"},
+			Component: &v1beta1.ComponentDefinitionSpec{},
+		})).Should(Equal("crossplane"))
+		Expect(getServiceClassProvisioner(&apisv1.DefinitionBase{Component: &v1beta1.ComponentDefinitionSpec{
+			Workload: common.WorkloadTypeDescriptor{Definition: common.WorkloadGVK{APIVersion: "apps/v1", Kind: "Deployment"}},
+		}})).Should(BeEmpty())
+	})
+
+	It("Test review the service instances", func() {
+		instance := &model.ServiceInstance{
+			Name:          "orders-db",
+			Project:       "catalog-test",
+			ServiceClass:  "alibaba-rds",
+			AppPrimaryKey: "orders",
+			Status:        model.ServiceInstanceStatusPending,
+			Requester:     "dev",
+		}
+		Expect(ds.Add(context.TODO(), instance)).Should(BeNil())
+
+		list, err := catalogService.ListServiceInstances(context.TODO(), "catalog-test")
+		Expect(err).Should(BeNil())
+		Expect(len(list.ServiceInstances)).Should(Equal(1))
+		Expect(list.ServiceInstances[0].AppName).Should(Equal("orders"))
+
+		devCtx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "dev")
+		_, err = catalogService.RejectServiceInstance(devCtx, "catalog-test", "orders-db", apisv1.ReviewServiceInstanceRequest{})
+		Expect(err).Should(Equal(bcode.ErrServiceInstanceApprovalBySelf))
+
+		adminCtx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "admin")
+		rejected, err := catalogService.RejectServiceInstance(adminCtx, "catalog-test", "orders-db", apisv1.ReviewServiceInstanceRequest{Comment: "use the shared database"})
+		Expect(err).Should(BeNil())
+		Expect(rejected.Status).Should(Equal(model.ServiceInstanceStatusRejected))
+		Expect(rejected.Reviewer).Should(Equal("admin"))
+
+		_, err = catalogService.ApproveServiceInstance(adminCtx, "catalog-test", "orders-db", apisv1.ReviewServiceInstanceRequest{})
+		Expect(err).Should(Equal(bcode.ErrServiceInstanceIsNotPending))
+
+		Expect(catalogService.DeleteServiceInstance(context.TODO(), "catalog-test", "orders-db")).Should(BeNil())
+		err = catalogService.DeleteServiceInstance(context.TODO(), "catalog-test", "orders-db")
+		Expect(err).Should(Equal(bcode.ErrServiceInstanceNotExist))
+	})
+})
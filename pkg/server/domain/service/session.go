@@ -0,0 +1,345 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	apiutils "github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// defaultAccessTokenTTL how long the access token is valid if it is not set in the session settings
+	defaultAccessTokenTTL = time.Hour
+	// defaultSessionExpiration how long the session is valid if it is not set in the session settings, it is the same as the refresh token
+	defaultSessionExpiration = 24 * time.Hour
+	// minAccessTokenTTLMinutes and maxAccessTokenTTLMinutes the range of the access token TTL
+	minAccessTokenTTLMinutes = 5
+	maxAccessTokenTTLMinutes = 24 * 60
+	// maxSessionMinutes the upper bound of the refresh token TTL and the idle timeout
+	maxSessionMinutes = 30 * 24 * 60
+	// minIdleTimeoutMinutes the lower bound of the idle timeout, it must be longer than the touch interval
+	// because the last seen time of the session is only saved once per interval
+	minIdleTimeoutMinutes = 5
+	// sessionCacheTTL how long the checked sessions are cached by the auth filter
+	sessionCacheTTL = 10 * time.Second
+	// sessionTouchInterval how often the last seen time of the session is saved
+	sessionTouchInterval = time.Minute
+	// maxConcurrentSessions the upper bound of the concurrent sessions limit
+	maxConcurrentSessions = 100
+	// maxDeviceLength the user agent longer than it is truncated
+	maxDeviceLength = 256
+)
+
+// sessionChecker checks the sessions of the tokens for the auth filter, it is set when the service is initialized
+var sessionChecker *sessionServiceImpl
+
+// SessionService manages the login sessions of the users
+type SessionService interface {
+	ListSessions(ctx context.Context, username string) (*apisv1.ListSessionsResponse, error)
+	RevokeSession(ctx context.Context, username, sessionID string) error
+	RevokeSessions(ctx context.Context, username string, keepCurrent bool) error
+	Init(ctx context.Context) error
+}
+
+type sessionServiceImpl struct {
+	Store      datastore.DataStore `inject:"datastore"`
+	SysService SystemInfoService   `inject:""`
+	cache      *apiutils.LRUCache
+}
+
+// NewSessionService new session service
+func NewSessionService() SessionService {
+	return &sessionServiceImpl{cache: apiutils.NewLRUCache(1024, sessionCacheTTL)}
+}
+
+// Init enables the session checking, the expired sessions are deleted by the datastore
+func (s *sessionServiceImpl) Init(ctx context.Context) error {
+	sessionChecker = s
+	return nil
+}
+
+// ListSessions lists the active sessions of the user
+func (s *sessionServiceImpl) ListSessions(ctx context.Context, username string) (*apisv1.ListSessionsResponse, error) {
+	entities, err := s.Store.List(ctx, &model.Session{Username: username}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	current, _ := ctx.Value(&apisv1.CtxKeySession).(string)
+	settings := s.sessionSettings(ctx)
+	res := &apisv1.ListSessionsResponse{Sessions: []*apisv1.SessionBase{}}
+	if settings != nil {
+		res.MaxSessions, res.EvictOldest = settings.MaxConcurrentSessions, settings.EvictOldestSession
+	}
+	_, _, idleTimeout := sessionLifetime(settings)
+	for _, entity := range entities {
+		session := entity.(*model.Session)
+		if !sessionActive(session, idleTimeout) {
+			continue
+		}
+		res.Sessions = append(res.Sessions, &apisv1.SessionBase{
+			ID:         session.ID,
+			Device:     session.Device,
+			IP:         session.IP,
+			CreateTime: session.CreateTime,
+			LastSeen:   session.LastSeen,
+			ExpireTime: session.ExpireTime,
+			Current:    session.ID == current,
+		})
+	}
+	return res, nil
+}
+
+// RevokeSession revokes a session of the user, the tokens of the session are rejected
+func (s *sessionServiceImpl) RevokeSession(ctx context.Context, username, sessionID string) error {
+	session := &model.Session{ID: sessionID}
+	if err := s.Store.Get(ctx, session); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrSessionNotExist
+		}
+		return err
+	}
+	if session.Username != username {
+		return bcode.ErrSessionNotExist
+	}
+	if err := s.Store.Delete(ctx, session); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return err
+	}
+	s.cache.Delete(sessionID)
+	return nil
+}
+
+// RevokeSessions revokes all sessions of the user, the session of the request is kept if keepCurrent is true
+func (s *sessionServiceImpl) RevokeSessions(ctx context.Context, username string, keepCurrent bool) error {
+	var except string
+	if keepCurrent {
+		except, _ = ctx.Value(&apisv1.CtxKeySession).(string)
+	}
+	return revokeUserSessions(ctx, s.Store, username, except)
+}
+
+// check returns an error if the session is revoked or expired, the last seen time is updated at intervals
+func (s *sessionServiceImpl) check(ctx context.Context, sessionID string) error {
+	if _, ok := s.cache.Get(sessionID); ok {
+		return nil
+	}
+	session := &model.Session{ID: sessionID}
+	if err := s.Store.Get(ctx, session); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrSessionRevoked
+		}
+		return err
+	}
+	if time.Now().After(session.ExpireTime) {
+		return bcode.ErrSessionRevoked
+	}
+	if idleTimeout := s.idleTimeout(ctx); idleTimeout > 0 && time.Since(session.LastSeen) > idleTimeout {
+		if err := s.Store.Delete(ctx, session); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Warningf("failed to delete the idle session of the user %s: %s", session.Username, err.Error())
+		}
+		return bcode.ErrSessionIdleTimeout
+	}
+	if time.Since(session.LastSeen) > sessionTouchInterval {
+		session.LastSeen = time.Now()
+		if err := s.Store.Put(ctx, session); err != nil {
+			klog.Warningf("failed to update the last seen time of the session: %s", err.Error())
+		}
+	}
+	s.cache.Put(sessionID, true)
+	return nil
+}
+
+// idleTimeout returns the idle timeout of the sessions, 0 means the idle sessions are not revoked
+func (s *sessionServiceImpl) idleTimeout(ctx context.Context) time.Duration {
+	_, _, idle := sessionLifetime(s.sessionSettings(ctx))
+	return idle
+}
+
+// sessionSettings returns nil if the settings could not be read, the defaults are used
+func (s *sessionServiceImpl) sessionSettings(ctx context.Context) *model.SessionSettings {
+	if s.SysService == nil {
+		return nil
+	}
+	info, err := s.SysService.Get(ctx)
+	if err != nil {
+		klog.Errorf("failed to get the session settings: %s", err.Error())
+		return nil
+	}
+	return info.SessionSettings
+}
+
+// sessionActive returns false if the session is expired or idle for longer than the idle timeout
+func sessionActive(session *model.Session, idleTimeout time.Duration) bool {
+	if time.Now().After(session.ExpireTime) {
+		return false
+	}
+	return idleTimeout <= 0 || time.Since(session.LastSeen) <= idleTimeout
+}
+
+// sessionLifetime returns the TTL of the access and the refresh tokens and the idle timeout, the defaults are used for the zero values
+func sessionLifetime(settings *model.SessionSettings) (access, refresh, idle time.Duration) {
+	access, refresh = defaultAccessTokenTTL, defaultSessionExpiration
+	if settings == nil {
+		return access, refresh, 0
+	}
+	if settings.AccessTokenTTLMinutes > 0 {
+		access = time.Duration(settings.AccessTokenTTLMinutes) * time.Minute
+	}
+	if settings.RefreshTokenTTLMinutes > 0 {
+		refresh = time.Duration(settings.RefreshTokenTTLMinutes) * time.Minute
+	}
+	return access, refresh, time.Duration(settings.IdleTimeoutMinutes) * time.Minute
+}
+
+// effectiveSessionSettings fills the defaults of the session settings
+func effectiveSessionSettings(settings *model.SessionSettings) model.SessionSettings {
+	access, refresh, idle := sessionLifetime(settings)
+	return model.SessionSettings{
+		AccessTokenTTLMinutes:  int(access / time.Minute),
+		RefreshTokenTTLMinutes: int(refresh / time.Minute),
+		IdleTimeoutMinutes:     int(idle / time.Minute),
+		MaxConcurrentSessions:  settings.GetMaxConcurrentSessions(),
+		EvictOldestSession:     settings != nil && settings.EvictOldestSession,
+	}
+}
+
+func validateSessionSettings(settings *model.SessionSettings) error {
+	if settings.AccessTokenTTLMinutes < 0 || settings.RefreshTokenTTLMinutes < 0 || settings.IdleTimeoutMinutes < 0 ||
+		settings.MaxConcurrentSessions < 0 || settings.MaxConcurrentSessions > maxConcurrentSessions {
+		return bcode.ErrInvalidSessionSettings
+	}
+	effective := effectiveSessionSettings(settings)
+	if effective.AccessTokenTTLMinutes < minAccessTokenTTLMinutes || effective.AccessTokenTTLMinutes > maxAccessTokenTTLMinutes ||
+		effective.AccessTokenTTLMinutes > effective.RefreshTokenTTLMinutes ||
+		effective.RefreshTokenTTLMinutes > maxSessionMinutes || effective.IdleTimeoutMinutes > maxSessionMinutes ||
+		(effective.IdleTimeoutMinutes > 0 && effective.IdleTimeoutMinutes < minIdleTimeoutMinutes) {
+		return bcode.ErrInvalidSessionSettings
+	}
+	return nil
+}
+
+// CheckSession checks the tenant and the session of the token, the tokens issued without the session are not checked
+func CheckSession(ctx context.Context, claims *model.CustomClaims) error {
+	if claims.Org != datastore.TenantFromContext(ctx) {
+		return bcode.ErrTokenInvalid
+	}
+	if claims.SessionID == "" || sessionChecker == nil {
+		return nil
+	}
+	return sessionChecker.check(ctx, claims.SessionID)
+}
+
+// createSession records the login of the user with the device and the IP of the request, it expires with the refresh token
+func createSession(ctx context.Context, store datastore.DataStore, username string, expiration time.Duration) (*model.Session, error) {
+	id, err := generateSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	device, _ := apiutils.UserAgentFrom(ctx)
+	if len(device) > maxDeviceLength {
+		device = device[:maxDeviceLength]
+	}
+	ip, _ := apiutils.ClientIPFrom(ctx)
+	session := &model.Session{
+		ID:         id[:32],
+		Username:   username,
+		Device:     device,
+		IP:         ip,
+		LastSeen:   time.Now(),
+		ExpireTime: time.Now().Add(expiration),
+	}
+	// the datastore deletes the session after it expires
+	session.SetExpireAt(session.ExpireTime)
+	if err := store.Add(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// enforceSessionLimit makes room for the new session of the user if the concurrent sessions are limited,
+// the inactive sessions are deleted and the oldest active sessions are evicted if it is allowed
+func enforceSessionLimit(ctx context.Context, store datastore.DataStore, username string, settings *model.SessionSettings) error {
+	limit := settings.GetMaxConcurrentSessions()
+	if limit <= 0 {
+		return nil
+	}
+	entities, err := store.List(ctx, &model.Session{Username: username}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return err
+	}
+	_, _, idleTimeout := sessionLifetime(settings)
+	var active []*model.Session
+	for _, entity := range entities {
+		session := entity.(*model.Session)
+		if sessionActive(session, idleTimeout) {
+			active = append(active, session)
+			continue
+		}
+		if err := store.Delete(ctx, session); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Warningf("failed to delete the inactive session of the user %s: %s", username, err.Error())
+		}
+	}
+	if len(active) < limit {
+		return nil
+	}
+	if !settings.EvictOldestSession {
+		return bcode.ErrSessionLimitExceeded
+	}
+	for _, session := range active[:len(active)-limit+1] {
+		if err := store.Delete(ctx, session); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		if sessionChecker != nil {
+			sessionChecker.cache.Delete(session.ID)
+		}
+		klog.Infof("the session %s of the user %s is evicted by the concurrent sessions limit", session.ID, username)
+	}
+	return nil
+}
+
+// revokeUserSessions deletes the sessions of the user except the given one
+func revokeUserSessions(ctx context.Context, store datastore.DataStore, username, except string) error {
+	sessions, err := store.List(ctx, &model.Session{Username: username}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, v := range sessions {
+		session := v.(*model.Session)
+		if session.ID == except {
+			continue
+		}
+		if err := store.Delete(ctx, session); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+	}
+	if sessionChecker != nil {
+		sessionChecker.cache.Purge()
+	}
+	return nil
+}
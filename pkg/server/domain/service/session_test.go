@@ -0,0 +1,224 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test session service functions", func() {
+	var (
+		authService    *authenticationServiceImpl
+		userService    *userServiceImpl
+		sessionService *sessionServiceImpl
+		ds             datastore.DataStore
+	)
+
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "session-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(ds).ToNot(BeNil())
+		Expect(err).Should(BeNil())
+		sysService := &systemInfoServiceImpl{Store: ds, KubeClient: k8sClient}
+		Expect(sysService.Init(context.TODO())).Should(BeNil())
+		userService = &userServiceImpl{Store: ds, SysService: sysService}
+		authService = &authenticationServiceImpl{KubeClient: k8sClient, Store: ds, SysService: sysService, UserService: userService}
+		sessionService = NewSessionService().(*sessionServiceImpl)
+		sessionService.Store = ds
+		sessionService.SysService = sysService
+		Expect(sessionService.Init(context.TODO())).Should(BeNil())
+	})
+
+	AfterEach(func() {
+		sessionChecker = nil
+	})
+
+	It("Test list and revoke the sessions", func() {
+		_, err := userService.CreateUser(context.Background(), apisv1.CreateUserRequest{
+			Name:     "test-session",
+			Email:    "session@example.com",
+			Password: "password1",
+		})
+		Expect(err).Should(BeNil())
+
+		ctx := utils.WithUserAgent(utils.WithClientIP(context.Background(), "10.0.0.1"), "test-agent")
+		first, err := authService.Login(ctx, apisv1.LoginRequest{Username: "test-session", Password: "password1"})
+		Expect(err).Should(BeNil())
+		second, err := authService.Login(ctx, apisv1.LoginRequest{Username: "test-session", Password: "password1"})
+		Expect(err).Should(BeNil())
+
+		firstClaims, err := ParseToken(first.AccessToken)
+		Expect(err).Should(BeNil())
+		Expect(firstClaims.SessionID).ShouldNot(BeEmpty())
+		refreshClaims, err := ParseToken(first.RefreshToken)
+		Expect(err).Should(BeNil())
+		Expect(refreshClaims.SessionID).Should(Equal(firstClaims.SessionID))
+		secondClaims, err := ParseToken(second.AccessToken)
+		Expect(err).Should(BeNil())
+		Expect(CheckSession(context.TODO(), firstClaims)).Should(BeNil())
+
+		current := context.WithValue(context.TODO(), &apisv1.CtxKeySession, firstClaims.SessionID)
+		sessions, err := sessionService.ListSessions(current, "test-session")
+		Expect(err).Should(BeNil())
+		Expect(len(sessions.Sessions)).Should(Equal(2))
+		for _, s := range sessions.Sessions {
+			Expect(s.Device).Should(Equal("test-agent"))
+			Expect(s.IP).Should(Equal("10.0.0.1"))
+			Expect(s.Current).Should(Equal(s.ID == firstClaims.SessionID))
+		}
+
+		By("revoke the session of another user")
+		err = sessionService.RevokeSession(context.TODO(), "admin", firstClaims.SessionID)
+		Expect(err).Should(Equal(bcode.ErrSessionNotExist))
+
+		By("revoke the first session")
+		Expect(sessionService.RevokeSession(context.TODO(), "test-session", firstClaims.SessionID)).Should(BeNil())
+		Expect(CheckSession(context.TODO(), firstClaims)).Should(Equal(bcode.ErrSessionRevoked))
+		_, err = authService.RefreshToken(context.TODO(), first.RefreshToken)
+		Expect(err).Should(Equal(bcode.ErrSessionRevoked))
+		Expect(CheckSession(context.TODO(), secondClaims)).Should(BeNil())
+
+		By("the token is rejected by the other tenants")
+		Expect(CheckSession(datastore.WithTenant(context.TODO(), "other-org"), secondClaims)).Should(Equal(bcode.ErrTokenInvalid))
+
+		By("the tokens without the session can not be refreshed")
+		legacy, err := authService.generateJWTToken(context.TODO(), "test-session", GrantTypeRefresh, "", time.Hour)
+		Expect(err).Should(BeNil())
+		_, err = authService.RefreshToken(context.TODO(), legacy)
+		Expect(err).Should(Equal(bcode.ErrSessionRevoked))
+	})
+
+	It("Test revoke the other sessions after changing the password", func() {
+		_, err := userService.CreateUser(context.Background(), apisv1.CreateUserRequest{
+			Name:     "test-session-password",
+			Email:    "session-password@example.com",
+			Password: "password1",
+		})
+		Expect(err).Should(BeNil())
+		first, err := authService.Login(context.TODO(), apisv1.LoginRequest{Username: "test-session-password", Password: "password1"})
+		Expect(err).Should(BeNil())
+		second, err := authService.Login(context.TODO(), apisv1.LoginRequest{Username: "test-session-password", Password: "password1"})
+		Expect(err).Should(BeNil())
+		firstClaims, err := ParseToken(first.AccessToken)
+		Expect(err).Should(BeNil())
+		secondClaims, err := ParseToken(second.AccessToken)
+		Expect(err).Should(BeNil())
+
+		user := &model.User{Name: "test-session-password"}
+		Expect(ds.Get(context.TODO(), user)).Should(BeNil())
+		current := context.WithValue(context.TODO(), &apisv1.CtxKeySession, firstClaims.SessionID)
+		_, err = userService.UpdateUser(current, user, apisv1.UpdateUserRequest{Password: "password2"})
+		Expect(err).Should(BeNil())
+		Expect(CheckSession(context.TODO(), firstClaims)).Should(BeNil())
+		Expect(CheckSession(context.TODO(), secondClaims)).Should(Equal(bcode.ErrSessionRevoked))
+
+		By("disabling the user revokes all sessions")
+		Expect(userService.DisableUser(context.TODO(), user)).Should(BeNil())
+		Expect(CheckSession(context.TODO(), firstClaims)).Should(Equal(bcode.ErrSessionRevoked))
+	})
+
+	It("Test limit the concurrent sessions", func() {
+		Expect(validateSessionSettings(&model.SessionSettings{MaxConcurrentSessions: -1})).Should(Equal(bcode.ErrInvalidSessionSettings))
+		Expect(validateSessionSettings(&model.SessionSettings{MaxConcurrentSessions: maxConcurrentSessions + 1})).Should(Equal(bcode.ErrInvalidSessionSettings))
+		_, err := userService.CreateUser(context.Background(), apisv1.CreateUserRequest{
+			Name:     "test-session-limit",
+			Email:    "session-limit@example.com",
+			Password: "password1",
+		})
+		Expect(err).Should(BeNil())
+		info, err := authService.SysService.Get(context.TODO())
+		Expect(err).Should(BeNil())
+		info.SessionSettings = &model.SessionSettings{MaxConcurrentSessions: 2}
+		Expect(ds.Put(context.TODO(), info)).Should(BeNil())
+
+		login := apisv1.LoginRequest{Username: "test-session-limit", Password: "password1"}
+		first, err := authService.Login(context.TODO(), login)
+		Expect(err).Should(BeNil())
+		_, err = authService.Login(context.TODO(), login)
+		Expect(err).Should(BeNil())
+		_, err = authService.Login(context.TODO(), login)
+		Expect(err).Should(Equal(bcode.ErrSessionLimitExceeded))
+
+		By("the oldest session is evicted if it is allowed")
+		info.SessionSettings.EvictOldestSession = true
+		Expect(ds.Put(context.TODO(), info)).Should(BeNil())
+		third, err := authService.Login(context.TODO(), login)
+		Expect(err).Should(BeNil())
+		firstClaims, err := ParseToken(first.AccessToken)
+		Expect(err).Should(BeNil())
+		Expect(CheckSession(context.TODO(), firstClaims)).Should(Equal(bcode.ErrSessionRevoked))
+		thirdClaims, err := ParseToken(third.AccessToken)
+		Expect(err).Should(BeNil())
+		Expect(CheckSession(context.TODO(), thirdClaims)).Should(BeNil())
+
+		sessions, err := sessionService.ListSessions(context.TODO(), "test-session-limit")
+		Expect(err).Should(BeNil())
+		Expect(len(sessions.Sessions)).Should(Equal(2))
+		Expect(sessions.MaxSessions).Should(Equal(2))
+		Expect(sessions.EvictOldest).Should(BeTrue())
+	})
+
+	It("Test the session settings", func() {
+		Expect(validateSessionSettings(&model.SessionSettings{AccessTokenTTLMinutes: 15, RefreshTokenTTLMinutes: 480, IdleTimeoutMinutes: 15})).Should(BeNil())
+		Expect(validateSessionSettings(&model.SessionSettings{AccessTokenTTLMinutes: 1})).Should(Equal(bcode.ErrInvalidSessionSettings))
+		Expect(validateSessionSettings(&model.SessionSettings{AccessTokenTTLMinutes: 120, RefreshTokenTTLMinutes: 60})).Should(Equal(bcode.ErrInvalidSessionSettings))
+		Expect(validateSessionSettings(&model.SessionSettings{IdleTimeoutMinutes: -1})).Should(Equal(bcode.ErrInvalidSessionSettings))
+		Expect(validateSessionSettings(&model.SessionSettings{IdleTimeoutMinutes: 1})).Should(Equal(bcode.ErrInvalidSessionSettings))
+		Expect(time.Duration(minIdleTimeoutMinutes)*time.Minute > sessionTouchInterval).Should(BeTrue())
+
+		_, err := userService.CreateUser(context.Background(), apisv1.CreateUserRequest{
+			Name:     "test-session-idle",
+			Email:    "session-idle@example.com",
+			Password: "password1",
+		})
+		Expect(err).Should(BeNil())
+		info, err := authService.SysService.Get(context.TODO())
+		Expect(err).Should(BeNil())
+		info.SessionSettings = &model.SessionSettings{AccessTokenTTLMinutes: 15, RefreshTokenTTLMinutes: 480, IdleTimeoutMinutes: 15}
+		Expect(ds.Put(context.TODO(), info)).Should(BeNil())
+
+		login, err := authService.Login(context.TODO(), apisv1.LoginRequest{Username: "test-session-idle", Password: "password1"})
+		Expect(err).Should(BeNil())
+		claims, err := ParseToken(login.AccessToken)
+		Expect(err).Should(BeNil())
+		Expect(claims.ExpiresAt - claims.NotBefore).Should(Equal(int64(15 * 60)))
+		refreshClaims, err := ParseToken(login.RefreshToken)
+		Expect(err).Should(BeNil())
+		Expect(refreshClaims.ExpiresAt - refreshClaims.NotBefore).Should(Equal(int64(480 * 60)))
+		Expect(CheckSession(context.TODO(), claims)).Should(BeNil())
+
+		By("the idle session is revoked")
+		session := &model.Session{ID: claims.SessionID}
+		Expect(ds.Get(context.TODO(), session)).Should(BeNil())
+		session.LastSeen = time.Now().Add(-20 * time.Minute)
+		Expect(ds.Put(context.TODO(), session)).Should(BeNil())
+		sessionService.cache.Purge()
+		Expect(CheckSession(context.TODO(), claims)).Should(Equal(bcode.ErrSessionIdleTimeout))
+		Expect(CheckSession(context.TODO(), claims)).Should(Equal(bcode.ErrSessionRevoked))
+	})
+})
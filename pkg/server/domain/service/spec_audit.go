@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	apiutils "github.com/kubevela/velaux/pkg/server/utils"
+)
+
+const (
+	// SpecAuditResourceApplication the spec audits of the applications
+	SpecAuditResourceApplication = "application"
+	// SpecAuditResourcePipeline the spec audits of the pipelines
+	SpecAuditResourcePipeline = "pipeline"
+)
+
+// SpecAuditService lists the recorded spec updates of the applications and the pipelines
+type SpecAuditService interface {
+	ListSpecAudits(ctx context.Context, resource, project, entity string, page, pageSize int) (*apisv1.ListSpecAuditsResponse, error)
+}
+
+type specAuditServiceImpl struct {
+	Store datastore.DataStore `inject:"datastore"`
+}
+
+// NewSpecAuditService new spec audit service
+func NewSpecAuditService() SpecAuditService {
+	return &specAuditServiceImpl{}
+}
+
+// ListSpecAudits lists the spec updates of an entity, the latest one is the first
+func (s *specAuditServiceImpl) ListSpecAudits(ctx context.Context, resource, project, entity string, page, pageSize int) (*apisv1.ListSpecAuditsResponse, error) {
+	var audit = model.SpecAudit{Resource: resource, Project: project, Entity: entity}
+	entities, err := s.Store.List(ctx, &audit, &datastore.ListOptions{Page: page, PageSize: pageSize, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil {
+		return nil, err
+	}
+	var res = apisv1.ListSpecAuditsResponse{Audits: []*apisv1.SpecAuditBase{}}
+	for _, entity := range entities {
+		res.Audits = append(res.Audits, convertSpecAudit2Base(entity.(*model.SpecAudit)))
+	}
+	count, err := s.Store.Count(ctx, &audit, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Total = count
+	return &res, nil
+}
+
+func convertSpecAudit2Base(audit *model.SpecAudit) *apisv1.SpecAuditBase {
+	return &apisv1.SpecAuditBase{
+		Name:        audit.Name,
+		Resource:    audit.Resource,
+		Project:     audit.Project,
+		Entity:      audit.Entity,
+		SubResource: audit.SubResource,
+		Changes:     audit.Changes,
+		Operator:    audit.Operator,
+		CreateTime:  audit.CreateTime,
+	}
+}
+
+// specAuditRetention how long the spec audits are kept, 0 keeps them forever, it is set by the server config
+var specAuditRetention time.Duration
+
+// recordSpecAudit saves the diff between the snapshots taken before and after the update.
+// Nothing is saved if the spec is not changed, the failure is only logged because the update has taken effect.
+func recordSpecAudit(ctx context.Context, store datastore.DataStore, audit *model.SpecAudit, before, after interface{}) {
+	audit.Changes = diffSpec("", before, after)
+	if len(audit.Changes) == 0 {
+		return
+	}
+	audit.Name = apiutils.GenerateVersion(audit.Entity) + "-" + rand.String(4)
+	audit.Operator, _ = ctx.Value(&apisv1.CtxKeyUser).(string)
+	if specAuditRetention > 0 {
+		audit.SetExpireAt(time.Now().Add(specAuditRetention))
+	}
+	if err := store.Add(ctx, audit); err != nil {
+		klog.Warningf("failed to save the spec audit of the %s %s: %s", audit.Resource, audit.Entity, err.Error())
+	}
+}
+
+// specSnapshot converts the object to the generic JSON value, the timestamps of the model are ignored
+func specSnapshot(obj interface{}) interface{} {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	var snapshot interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+	if fields, ok := snapshot.(map[string]interface{}); ok {
+		delete(fields, "createTime")
+		delete(fields, "updateTime")
+	}
+	return snapshot
+}
+
+// diffSpec compares the snapshots field by field, the changes are sorted by the path
+func diffSpec(path string, from, to interface{}) []model.SpecChange {
+	switch {
+	case from == nil && to == nil:
+		return nil
+	case from == nil:
+		return []model.SpecChange{{Path: path, Operation: model.SpecChangeAdd, To: encodeSpecValue(to)}}
+	case to == nil:
+		return []model.SpecChange{{Path: path, Operation: model.SpecChangeRemove, From: encodeSpecValue(from)}}
+	}
+	switch fromValue := from.(type) {
+	case map[string]interface{}:
+		if toValue, ok := to.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(fromValue)+len(toValue))
+			for key := range fromValue {
+				keys = append(keys, key)
+			}
+			for key := range toValue {
+				if _, exist := fromValue[key]; !exist {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			var changes []model.SpecChange
+			for _, key := range keys {
+				fieldPath := key
+				if path != "" {
+					fieldPath = path + "." + key
+				}
+				changes = append(changes, diffSpec(fieldPath, fromValue[key], toValue[key])...)
+			}
+			return changes
+		}
+	case []interface{}:
+		if toValue, ok := to.([]interface{}); ok {
+			var changes []model.SpecChange
+			for i := 0; i < len(fromValue) || i < len(toValue); i++ {
+				var fromItem, toItem interface{}
+				if i < len(fromValue) {
+					fromItem = fromValue[i]
+				}
+				if i < len(toValue) {
+					toItem = toValue[i]
+				}
+				changes = append(changes, diffSpec(fmt.Sprintf("%s[%d]", path, i), fromItem, toItem)...)
+			}
+			return changes
+		}
+	}
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+	return []model.SpecChange{{Path: path, Operation: model.SpecChangeReplace, From: encodeSpecValue(from), To: encodeSpecValue(to)}}
+}
+
+func encodeSpecValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gotest.tools/assert"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+func TestDiffSpec(t *testing.T) {
+	testCases := map[string]struct {
+		from    interface{}
+		to      interface{}
+		changes []model.SpecChange
+	}{
+		"unchanged": {
+			from: &model.ApplicationComponent{Name: "web", Alias: "Web", BaseModel: model.BaseModel{CreateTime: time.Now()}},
+			to:   &model.ApplicationComponent{Name: "web", Alias: "Web", BaseModel: model.BaseModel{CreateTime: time.Now().Add(time.Hour)}},
+		},
+		"replace the nested field": {
+			from: &model.ApplicationComponent{Name: "web", Properties: &model.JSONStruct{"image": "nginx:1.20", "ports": []interface{}{80}}},
+			to:   &model.ApplicationComponent{Name: "web", Properties: &model.JSONStruct{"image": "nginx:1.21", "ports": []interface{}{80}}},
+			changes: []model.SpecChange{
+				{Path: "properties.image", Operation: model.SpecChangeReplace, From: `"nginx:1.20"`, To: `"nginx:1.21"`},
+			},
+		},
+		"remove the item of the list": {
+			from: &model.ApplicationComponent{Name: "web", Properties: &model.JSONStruct{"ports": []interface{}{80, 443}}},
+			to:   &model.ApplicationComponent{Name: "web", Alias: "Web", Properties: &model.JSONStruct{"ports": []interface{}{80}}},
+			changes: []model.SpecChange{
+				{Path: "alias", Operation: model.SpecChangeReplace, From: `""`, To: `"Web"`},
+				{Path: "properties.ports[1]", Operation: model.SpecChangeRemove, From: `443`},
+			},
+		},
+		"change the type of the field": {
+			from: map[string]interface{}{"replicas": map[string]interface{}{"min": 1}},
+			to:   map[string]interface{}{"replicas": 2, "paused": false},
+			changes: []model.SpecChange{
+				{Path: "paused", Operation: model.SpecChangeAdd, To: `false`},
+				{Path: "replicas", Operation: model.SpecChangeReplace, From: `{"min":1}`, To: `2`},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.DeepEqual(t, diffSpec("", specSnapshot(tc.from), specSnapshot(tc.to)), tc.changes)
+		})
+	}
+}
+
+var _ = Describe("Test spec audit service functions", func() {
+	var (
+		specAuditService *specAuditServiceImpl
+		ds               datastore.DataStore
+	)
+
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "spec-audit-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(ds).ToNot(BeNil())
+		Expect(err).Should(BeNil())
+		specAuditService = &specAuditServiceImpl{Store: ds}
+	})
+
+	It("Test record and list the spec audits of a pipeline", func() {
+		ctx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "admin")
+		pipeline := &model.Pipeline{Name: "test-pipeline", Project: "test-project", Alias: "Test"}
+		before := specSnapshot(pipeline)
+
+		By("the unchanged spec is not recorded")
+		recordSpecAudit(ctx, ds, &model.SpecAudit{Resource: SpecAuditResourcePipeline, Project: "test-project", Entity: "test-pipeline"}, before, specSnapshot(pipeline))
+		audits, err := specAuditService.ListSpecAudits(ctx, SpecAuditResourcePipeline, "test-project", "test-pipeline", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(audits.Total).Should(Equal(int64(0)))
+
+		pipeline.Description = "updated"
+		recordSpecAudit(ctx, ds, &model.SpecAudit{Resource: SpecAuditResourcePipeline, Project: "test-project", Entity: "test-pipeline"}, before, specSnapshot(pipeline))
+		audits, err = specAuditService.ListSpecAudits(ctx, SpecAuditResourcePipeline, "test-project", "test-pipeline", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(audits.Total).Should(Equal(int64(1)))
+		Expect(audits.Audits[0].Operator).Should(Equal("admin"))
+		Expect(audits.Audits[0].Changes).Should(Equal([]model.SpecChange{{Path: "description", Operation: model.SpecChangeReplace, From: `""`, To: `"updated"`}}))
+
+		audits, err = specAuditService.ListSpecAudits(ctx, SpecAuditResourceApplication, "test-project", "test-pipeline", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(audits.Total).Should(Equal(int64(0)))
+	})
+})
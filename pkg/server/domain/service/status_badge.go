@@ -0,0 +1,274 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/kubevela/workflow/api/v1alpha1"
+	"k8s.io/klog/v2"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	apiutils "github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// StatusBadgeResourceApplication the badges of the application health
+	StatusBadgeResourceApplication = "application"
+	// StatusBadgeResourcePipeline the badges of the pipeline status
+	StatusBadgeResourcePipeline = "pipeline"
+	// statusBadgeCacheTTL how long the status of the badge is cached, the badges are requested anonymously and frequently
+	statusBadgeCacheTTL = 30 * time.Second
+)
+
+// the colors of the badges, they are the names of the shields.io colors
+const (
+	badgeColorSuccess  = "brightgreen"
+	badgeColorFailure  = "red"
+	badgeColorWarning  = "yellow"
+	badgeColorProgress = "blue"
+	badgeColorUnknown  = "lightgrey"
+)
+
+// StatusBadgeService manages the public status badges of the applications and the pipelines
+type StatusBadgeService interface {
+	CreateBadge(ctx context.Context, resource, project, entity string, req apisv1.CreateStatusBadgeRequest) (*apisv1.CreateStatusBadgeResponse, error)
+	ListBadges(ctx context.Context, resource, project, entity string) (*apisv1.ListStatusBadgesResponse, error)
+	UpdateBadge(ctx context.Context, resource, project, entity, id string, req apisv1.UpdateStatusBadgeRequest) (*apisv1.StatusBadgeBase, error)
+	DeleteBadge(ctx context.Context, resource, project, entity, id string) error
+	GetBadgeStatus(ctx context.Context, id, token string) (*apisv1.StatusBadgeResponse, error)
+}
+
+type statusBadgeServiceImpl struct {
+	Store              datastore.DataStore `inject:"datastore"`
+	ApplicationService ApplicationService  `inject:""`
+	EnvBindingService  EnvBindingService   `inject:""`
+	PipelineService    PipelineService     `inject:""`
+	ProjectService     ProjectService      `inject:""`
+	cache              *apiutils.LRUCache
+}
+
+// NewStatusBadgeService new status badge service
+func NewStatusBadgeService() StatusBadgeService {
+	return &statusBadgeServiceImpl{cache: apiutils.NewLRUCache(1024, statusBadgeCacheTTL)}
+}
+
+// CreateBadge creates an enabled badge, the token is only returned by this call
+func (s *statusBadgeServiceImpl) CreateBadge(ctx context.Context, resource, project, entity string, req apisv1.CreateStatusBadgeRequest) (*apisv1.CreateStatusBadgeResponse, error) {
+	if resource == StatusBadgeResourceApplication {
+		if req.EnvName == "" {
+			return nil, bcode.ErrStatusBadgeEnvRequired
+		}
+		app := &model.Application{Name: entity}
+		if err := s.Store.Get(ctx, app); err != nil {
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				return nil, bcode.ErrApplicationNotExist
+			}
+			return nil, err
+		}
+		if _, err := s.EnvBindingService.GetEnvBinding(ctx, app, req.EnvName); err != nil {
+			return nil, err
+		}
+	}
+	token, err := generateSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	id, err := generateSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	creator, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	badge := &model.StatusBadge{
+		ID:        id[:16],
+		Resource:  resource,
+		Project:   project,
+		Entity:    entity,
+		EnvName:   req.EnvName,
+		Enabled:   true,
+		TokenHash: hashSecretToken(token),
+		Creator:   creator,
+	}
+	if err := s.Store.Add(ctx, badge); err != nil {
+		return nil, err
+	}
+	return &apisv1.CreateStatusBadgeResponse{StatusBadgeBase: *convertStatusBadge2Base(badge), Token: token}, nil
+}
+
+// ListBadges lists the badges of the application or the pipeline
+func (s *statusBadgeServiceImpl) ListBadges(ctx context.Context, resource, project, entity string) (*apisv1.ListStatusBadgesResponse, error) {
+	entities, err := s.Store.List(ctx, &model.StatusBadge{Resource: resource, Project: project, Entity: entity}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListStatusBadgesResponse{Badges: []*apisv1.StatusBadgeBase{}}
+	for _, entity := range entities {
+		res.Badges = append(res.Badges, convertStatusBadge2Base(entity.(*model.StatusBadge)))
+	}
+	return res, nil
+}
+
+// UpdateBadge enables or disables the badge, the token is not changed
+func (s *statusBadgeServiceImpl) UpdateBadge(ctx context.Context, resource, project, entity, id string, req apisv1.UpdateStatusBadgeRequest) (*apisv1.StatusBadgeBase, error) {
+	badge, err := s.getBadge(ctx, resource, project, entity, id)
+	if err != nil {
+		return nil, err
+	}
+	badge.Enabled = req.Enabled
+	if err := s.Store.Put(ctx, badge); err != nil {
+		return nil, err
+	}
+	s.cache.Delete(badge.ID)
+	return convertStatusBadge2Base(badge), nil
+}
+
+// DeleteBadge deletes the badge, the token of the badge is revoked
+func (s *statusBadgeServiceImpl) DeleteBadge(ctx context.Context, resource, project, entity, id string) error {
+	badge, err := s.getBadge(ctx, resource, project, entity, id)
+	if err != nil {
+		return err
+	}
+	if err := s.Store.Delete(ctx, badge); err != nil {
+		return err
+	}
+	s.cache.Delete(badge.ID)
+	return nil
+}
+
+// GetBadgeStatus checks the token and returns the current status of the entity of the badge.
+// The failure of getting the status is shown as unknown, the badge is still rendered.
+func (s *statusBadgeServiceImpl) GetBadgeStatus(ctx context.Context, id, token string) (*apisv1.StatusBadgeResponse, error) {
+	badge := &model.StatusBadge{ID: id}
+	if err := s.Store.Get(ctx, badge); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrStatusBadgeNotExist
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(badge.TokenHash), []byte(hashSecretToken(token))) != 1 {
+		return nil, bcode.ErrStatusBadgeNotExist
+	}
+	if !badge.Enabled {
+		return nil, bcode.ErrStatusBadgeDisabled
+	}
+	if cached, ok := s.cache.Get(badge.ID); ok {
+		return cached.(*apisv1.StatusBadgeResponse), nil
+	}
+	res := &apisv1.StatusBadgeResponse{SchemaVersion: 1, Label: badge.Entity}
+	switch badge.Resource {
+	case StatusBadgeResourceApplication:
+		res.Message, res.Color = s.applicationStatus(ctx, badge)
+	case StatusBadgeResourcePipeline:
+		res.Message, res.Color = s.pipelineStatus(ctx, badge)
+	default:
+		res.Message, res.Color = "unknown", badgeColorUnknown
+	}
+	s.cache.Put(badge.ID, res)
+	return res, nil
+}
+
+func (s *statusBadgeServiceImpl) applicationStatus(ctx context.Context, badge *model.StatusBadge) (string, string) {
+	app := &model.Application{Name: badge.Entity}
+	if err := s.Store.Get(ctx, app); err != nil {
+		klog.Warningf("failed to get the application %s of the status badge: %s", badge.Entity, err.Error())
+		return "unknown", badgeColorUnknown
+	}
+	status, err := s.ApplicationService.GetApplicationStatus(ctx, app, badge.EnvName)
+	if err != nil {
+		klog.Warningf("failed to get the status of the application %s in the env %s: %s", badge.Entity, badge.EnvName, err.Error())
+		return "unknown", badgeColorUnknown
+	}
+	if status == nil {
+		return "not deployed", badgeColorUnknown
+	}
+	switch status.Phase {
+	case common.ApplicationRunning:
+		return string(status.Phase), badgeColorSuccess
+	case common.ApplicationUnhealthy, common.ApplicationWorkflowFailed, common.ApplicationWorkflowTerminated:
+		return string(status.Phase), badgeColorFailure
+	case common.ApplicationWorkflowSuspending:
+		return string(status.Phase), badgeColorWarning
+	default:
+		return string(status.Phase), badgeColorProgress
+	}
+}
+
+func (s *statusBadgeServiceImpl) pipelineStatus(ctx context.Context, badge *model.StatusBadge) (string, string) {
+	project, err := s.ProjectService.GetProject(ctx, badge.Project)
+	if err != nil {
+		klog.Warningf("failed to get the project %s of the status badge: %s", badge.Project, err.Error())
+		return "unknown", badgeColorUnknown
+	}
+	pipeline, err := s.PipelineService.GetPipeline(context.WithValue(ctx, &apisv1.CtxKeyProject, project), badge.Entity, true)
+	if err != nil {
+		klog.Warningf("failed to get the pipeline %s of the status badge: %s", badge.Entity, err.Error())
+		return "unknown", badgeColorUnknown
+	}
+	if pipeline.LastRun == nil {
+		return "never run", badgeColorUnknown
+	}
+	phase := pipeline.LastRun.Status.Phase
+	switch phase {
+	case v1alpha1.WorkflowStateSucceeded:
+		return string(phase), badgeColorSuccess
+	case v1alpha1.WorkflowStateFailed, v1alpha1.WorkflowStateTerminated:
+		return string(phase), badgeColorFailure
+	case v1alpha1.WorkflowStateSuspending:
+		return string(phase), badgeColorWarning
+	case "":
+		return "unknown", badgeColorUnknown
+	default:
+		return string(phase), badgeColorProgress
+	}
+}
+
+func (s *statusBadgeServiceImpl) getBadge(ctx context.Context, resource, project, entity, id string) (*model.StatusBadge, error) {
+	badge := &model.StatusBadge{ID: id}
+	if err := s.Store.Get(ctx, badge); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrStatusBadgeNotExist
+		}
+		return nil, err
+	}
+	if badge.Resource != resource || badge.Project != project || badge.Entity != entity {
+		return nil, bcode.ErrStatusBadgeNotExist
+	}
+	return badge, nil
+}
+
+func convertStatusBadge2Base(badge *model.StatusBadge) *apisv1.StatusBadgeBase {
+	return &apisv1.StatusBadgeBase{
+		ID:         badge.ID,
+		Resource:   badge.Resource,
+		Project:    badge.Project,
+		Entity:     badge.Entity,
+		EnvName:    badge.EnvName,
+		Enabled:    badge.Enabled,
+		Creator:    badge.Creator,
+		CreateTime: badge.CreateTime,
+	}
+}
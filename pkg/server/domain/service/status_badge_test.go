@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test status badge service functions", func() {
+	var (
+		badgeService *statusBadgeServiceImpl
+		ds           datastore.DataStore
+	)
+
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "status-badge-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(ds).ToNot(BeNil())
+		Expect(err).Should(BeNil())
+		badgeService = NewStatusBadgeService().(*statusBadgeServiceImpl)
+		badgeService.Store = ds
+		badgeService.ProjectService = NewTestProjectService(ds, k8sClient)
+	})
+
+	It("Test the application badge requires the environment", func() {
+		_, err := badgeService.CreateBadge(context.TODO(), StatusBadgeResourceApplication, "badge-project", "badge-app", apisv1.CreateStatusBadgeRequest{})
+		Expect(err).Should(Equal(bcode.ErrStatusBadgeEnvRequired))
+	})
+
+	It("Test create, disable and revoke a pipeline badge", func() {
+		ctx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "admin")
+		badge, err := badgeService.CreateBadge(ctx, StatusBadgeResourcePipeline, "badge-project", "badge-pipeline", apisv1.CreateStatusBadgeRequest{})
+		Expect(err).Should(BeNil())
+		Expect(badge.Token).ShouldNot(BeEmpty())
+		Expect(badge.Enabled).Should(BeTrue())
+		Expect(badge.Creator).Should(Equal("admin"))
+
+		badges, err := badgeService.ListBadges(ctx, StatusBadgeResourcePipeline, "badge-project", "badge-pipeline")
+		Expect(err).Should(BeNil())
+		Expect(len(badges.Badges)).Should(Equal(1))
+
+		_, err = badgeService.GetBadgeStatus(context.TODO(), badge.ID, "invalid")
+		Expect(err).Should(Equal(bcode.ErrStatusBadgeNotExist))
+
+		By("the status is unknown because the project is not exist")
+		status, err := badgeService.GetBadgeStatus(context.TODO(), badge.ID, badge.Token)
+		Expect(err).Should(BeNil())
+		Expect(status.Label).Should(Equal("badge-pipeline"))
+		Expect(status.Message).Should(Equal("unknown"))
+		Expect(status.Color).Should(Equal(badgeColorUnknown))
+
+		By("the badge of another pipeline could not be updated")
+		_, err = badgeService.UpdateBadge(ctx, StatusBadgeResourcePipeline, "badge-project", "other-pipeline", badge.ID, apisv1.UpdateStatusBadgeRequest{})
+		Expect(err).Should(Equal(bcode.ErrStatusBadgeNotExist))
+
+		_, err = badgeService.UpdateBadge(ctx, StatusBadgeResourcePipeline, "badge-project", "badge-pipeline", badge.ID, apisv1.UpdateStatusBadgeRequest{Enabled: false})
+		Expect(err).Should(BeNil())
+		_, err = badgeService.GetBadgeStatus(context.TODO(), badge.ID, badge.Token)
+		Expect(err).Should(Equal(bcode.ErrStatusBadgeDisabled))
+
+		Expect(badgeService.DeleteBadge(ctx, StatusBadgeResourcePipeline, "badge-project", "badge-pipeline", badge.ID)).Should(BeNil())
+		_, err = badgeService.GetBadgeStatus(context.TODO(), badge.ID, badge.Token)
+		Expect(err).Should(Equal(bcode.ErrStatusBadgeNotExist))
+	})
+})
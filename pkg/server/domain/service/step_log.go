@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+const (
+	stepLogResourceApplication = "application"
+	stepLogResourcePipeline    = "pipeline"
+)
+
+// stepLogName returns the primary key of the archived step logs, the names of the steps may be too long for kubeapi
+func stepLogName(stepLog *model.StepLog) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{stepLog.Resource, stepLog.Project, stepLog.Entity, stepLog.Record, stepLog.Step}, "/")))
+	return stepLog.Resource + "-" + hex.EncodeToString(sum[:])[:32]
+}
+
+func stepLogObjectKey(stepLog *model.StepLog) string {
+	return path.Join(stepLog.Resource, stepLog.Project, stepLog.Entity, stepLog.Record, stepLog.Step+".log")
+}
+
+// stepFinished returns true if the logs of the step would not change anymore
+func stepFinished(phase string) bool {
+	switch workflowv1alpha1.WorkflowStepPhase(phase) {
+	case workflowv1alpha1.WorkflowStepPhaseSucceeded, workflowv1alpha1.WorkflowStepPhaseFailed, workflowv1alpha1.WorkflowStepPhaseSkipped:
+		return true
+	}
+	return false
+}
+
+// loadStepLog reads the archived logs of the step, returns false if the logs are not archived or could not be read
+func loadStepLog(ctx context.Context, store datastore.DataStore, objects logstore.Store, stepLog *model.StepLog) (string, bool) {
+	stepLog.Name = stepLogName(stepLog)
+	if err := store.Get(ctx, stepLog); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Warningf("failed to get the archived logs of the step %s: %s", stepLog.Step, err.Error())
+		}
+		return "", false
+	}
+	if stepLog.ObjectKey == "" {
+		return stepLog.Content, true
+	}
+	data, err := objects.Get(ctx, stepLog.ObjectKey)
+	if err != nil {
+		klog.Warningf("failed to get the logs object %s: %s", stepLog.ObjectKey, err.Error())
+		return "", false
+	}
+	return string(data), true
+}
+
+var (
+	// stepLogRetention how long the archived step logs are kept, 0 keeps them forever, it is set by the server config
+	stepLogRetention time.Duration
+	// collectStepLogs archives the logs of the steps once the workflow finishes, it is set by the server config
+	collectStepLogs = true
+)
+
+// archiveStepLog saves the logs of the finished step, the logs larger than the threshold are skipped if the object storage is not configured
+func archiveStepLog(ctx context.Context, store datastore.DataStore, objects logstore.Store, stepLog *model.StepLog, content string) {
+	stepLog.Name = stepLogName(stepLog)
+	stepLog.Size = len(content)
+	if len(content) > objects.Threshold() {
+		if !objects.Enabled() {
+			return
+		}
+		key := stepLogObjectKey(stepLog)
+		if err := objects.Put(ctx, key, []byte(content)); err != nil {
+			klog.Warningf("failed to put the logs object %s: %s", key, err.Error())
+			return
+		}
+		stepLog.ObjectKey = key
+	} else {
+		stepLog.Content = content
+		// the logs in the object storage would be orphaned if the record expires, they are deleted with the workflow record
+		if stepLogRetention > 0 {
+			stepLog.SetExpireAt(time.Now().Add(stepLogRetention))
+		}
+	}
+	if err := store.Add(ctx, stepLog); err != nil && !errors.Is(err, datastore.ErrRecordExist) {
+		klog.Warningf("failed to archive the logs of the step %s: %s", stepLog.Step, err.Error())
+	}
+}
+
+// deleteStepLogs deletes the archived logs matching the filter and their objects
+func deleteStepLogs(ctx context.Context, store datastore.DataStore, objects logstore.Store, filter *model.StepLog) {
+	logs, err := store.List(ctx, filter, &datastore.ListOptions{})
+	if err != nil {
+		klog.Warningf("failed to list the archived step logs of %s: %s", filter.Entity, err.Error())
+		return
+	}
+	for _, entity := range logs {
+		stepLog := entity.(*model.StepLog)
+		if stepLog.ObjectKey != "" {
+			if err := objects.Delete(ctx, stepLog.ObjectKey); err != nil {
+				klog.Warningf("failed to delete the logs object %s: %s", stepLog.ObjectKey, err.Error())
+				continue
+			}
+		}
+		if err := store.Delete(ctx, stepLog); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Warningf("failed to delete the archived logs of the step %s: %s", stepLog.Step, err.Error())
+		}
+	}
+}
+
+// collectStepLogs archives the logs of the finished steps once the workflow finishes, so they could be viewed after
+// the pods they are read from are restarted or deleted. The steps archived before are skipped.
+func (w *workflowServiceImpl) collectStepLogs(ctx context.Context, record *model.WorkflowRecord) {
+	if len(record.ContextValue) == 0 {
+		return
+	}
+	var steps []model.StepStatus
+	for _, step := range record.Steps {
+		steps = append(steps, step.StepStatus)
+		steps = append(steps, step.SubStepsStatus...)
+	}
+	collected := 0
+	for _, step := range steps {
+		if !stepFinished(string(step.Phase)) {
+			continue
+		}
+		archived := &model.StepLog{Resource: stepLogResourceApplication, Entity: record.AppPrimaryKey, Record: record.Name, Step: step.Name}
+		archived.Name = stepLogName(archived)
+		if exist, err := w.Store.IsExist(ctx, archived); err != nil || exist {
+			continue
+		}
+		res, err := w.GetWorkflowRecordLog(ctx, record, step.Name)
+		if err != nil {
+			klog.Warningf("failed to collect the logs of the step %s of the workflow record %s: %s", step.Name, record.Name, err.Error())
+			continue
+		}
+		if res.Log != "" {
+			collected++
+		}
+	}
+	if collected > 0 {
+		klog.Infof("collected the logs of %d steps of the workflow record %s", collected, record.Name)
+	}
+}
+
+// PageStepLog cuts the log lines of the page, the pages start from 1 and the whole log is kept if the page is 0
+func PageStepLog(res *apisv1.GetPipelineRunLogResponse, page, pageSize int) {
+	if page <= 0 || pageSize <= 0 {
+		return
+	}
+	var lines []string
+	if res.Log != "" {
+		lines = strings.SplitAfter(res.Log, "\n")
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+	}
+	res.Page = page
+	res.PageSize = pageSize
+	res.TotalLines = len(lines)
+	start := (page - 1) * pageSize
+	if start >= len(lines) {
+		res.Log = ""
+		return
+	}
+	end := start + pageSize
+	if end > len(lines) {
+		end = len(lines)
+	}
+	res.Log = strings.Join(lines[start:end], "")
+	res.HasMore = end < len(lines)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+type memoryLogStore struct {
+	objects map[string][]byte
+}
+
+func (m *memoryLogStore) Enabled() bool {
+	return true
+}
+
+func (m *memoryLogStore) Threshold() int {
+	return 16
+}
+
+func (m *memoryLogStore) Put(_ context.Context, key string, data []byte) error {
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memoryLogStore) Get(_ context.Context, key string) ([]byte, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, logstore.ErrObjectNotExist
+	}
+	return data, nil
+}
+
+func (m *memoryLogStore) Delete(_ context.Context, key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+var _ = Describe("Test step log functions", func() {
+	var (
+		ds      datastore.DataStore
+		objects *memoryLogStore
+	)
+
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "step-log-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(ds).ToNot(BeNil())
+		Expect(err).Should(BeNil())
+		objects = &memoryLogStore{objects: map[string][]byte{}}
+	})
+
+	It("Test archive, load and delete the step logs", func() {
+		ctx := context.TODO()
+		newStepLog := func(step string) *model.StepLog {
+			return &model.StepLog{Resource: stepLogResourcePipeline, Project: "test-project", Entity: "test-pipeline", Record: "run-1", Step: step}
+		}
+
+		By("the small logs are stored in the datastore")
+		archiveStepLog(ctx, ds, objects, newStepLog("small"), "done")
+		logs, ok := loadStepLog(ctx, ds, objects, newStepLog("small"))
+		Expect(ok).Should(BeTrue())
+		Expect(logs).Should(Equal("done"))
+		Expect(len(objects.objects)).Should(Equal(0))
+
+		By("the large logs are stored in the object storage")
+		large := strings.Repeat("line\n", 10)
+		archiveStepLog(ctx, ds, objects, newStepLog("large"), large)
+		Expect(objects.objects["pipeline/test-project/test-pipeline/run-1/large.log"]).Should(Equal([]byte(large)))
+		stored := newStepLog("large")
+		logs, ok = loadStepLog(ctx, ds, objects, stored)
+		Expect(ok).Should(BeTrue())
+		Expect(logs).Should(Equal(large))
+		Expect(stored.Content).Should(BeEmpty())
+		Expect(stored.Size).Should(Equal(len(large)))
+
+		By("the large logs are not archived without the object storage")
+		archiveStepLog(ctx, ds, logstore.New(logstore.Config{Threshold: 16}), newStepLog("skipped"), large)
+		_, ok = loadStepLog(ctx, ds, objects, newStepLog("skipped"))
+		Expect(ok).Should(BeFalse())
+
+		By("delete the logs of the pipeline run")
+		deleteStepLogs(ctx, ds, objects, &model.StepLog{Resource: stepLogResourcePipeline, Project: "test-project", Entity: "test-pipeline", Record: "run-1"})
+		Expect(len(objects.objects)).Should(Equal(0))
+		_, ok = loadStepLog(ctx, ds, objects, newStepLog("small"))
+		Expect(ok).Should(BeFalse())
+	})
+	It("Test page the step logs by the lines", func() {
+		res := &apisv1.GetPipelineRunLogResponse{Log: "line-1\nline-2\nline-3\n"}
+		PageStepLog(res, 0, 2)
+		Expect(res.Log).Should(Equal("line-1\nline-2\nline-3\n"))
+		Expect(res.TotalLines).Should(Equal(0))
+
+		first := *res
+		PageStepLog(&first, 1, 2)
+		Expect(first.Log).Should(Equal("line-1\nline-2\n"))
+		Expect(first.TotalLines).Should(Equal(3))
+		Expect(first.HasMore).Should(BeTrue())
+
+		second := *res
+		PageStepLog(&second, 2, 2)
+		Expect(second.Log).Should(Equal("line-3\n"))
+		Expect(second.HasMore).Should(BeFalse())
+
+		outOfRange := *res
+		PageStepLog(&outOfRange, 3, 2)
+		Expect(outOfRange.Log).Should(BeEmpty())
+		Expect(outOfRange.TotalLines).Should(Equal(3))
+	})
+})
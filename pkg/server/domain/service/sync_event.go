@@ -0,0 +1,85 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"time"
+
+	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/cloudevent"
+)
+
+// SyncEventData the data of the CloudEvents of the application deploys and the workflow results
+type SyncEventData struct {
+	Project     string `json:"project"`
+	Application string `json:"application"`
+	Namespace   string `json:"namespace,omitempty"`
+	// Cluster the managed cluster the application is synced from, it is empty for the hub cluster
+	Cluster   string     `json:"cluster,omitempty"`
+	Record    string     `json:"record"`
+	Revision  string     `json:"revision,omitempty"`
+	Workflow  string     `json:"workflow,omitempty"`
+	Status    string     `json:"status,omitempty"`
+	Message   string     `json:"message,omitempty"`
+	StartTime *time.Time `json:"startTime,omitempty"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+}
+
+// PublishSyncEvent publishes the event of the workflow record of the application, it does nothing if no sink is configured.
+// The ID is generated by the record, so the consumers could drop the event published again after the restart.
+func PublishSyncEvent(publisher cloudevent.Publisher, eventType string, app *model.Application, record *model.WorkflowRecord) {
+	if publisher == nil || !publisher.Enabled() {
+		return
+	}
+	data := SyncEventData{
+		Project:     app.Project,
+		Application: app.Name,
+		Namespace:   record.Namespace,
+		Cluster:     record.Cluster,
+		Record:      record.Name,
+		Revision:    record.RevisionPrimaryKey,
+		Workflow:    record.WorkflowName,
+		Status:      record.Status,
+		Message:     record.Message,
+	}
+	if !record.StartTime.IsZero() {
+		data.StartTime = &record.StartTime
+	}
+	if !record.EndTime.IsZero() {
+		data.EndTime = &record.EndTime
+	}
+	publisher.Publish(cloudevent.Event{
+		ID:      app.Name + "/" + record.Name + "/" + eventType,
+		Type:    eventType,
+		Subject: app.Name,
+		Data:    data,
+	})
+}
+
+// workflowEventType the type of the event of the finished workflow
+func workflowEventType(phase string) string {
+	switch workflowv1alpha1.WorkflowRunPhase(phase) {
+	case workflowv1alpha1.WorkflowStateFailed:
+		return cloudevent.TypeWorkflowFailed
+	case workflowv1alpha1.WorkflowStateTerminated:
+		return cloudevent.TypeWorkflowTerminated
+	default:
+		return cloudevent.TypeWorkflowCompleted
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/cloudevent"
+)
+
+type recordingPublisher struct {
+	events []cloudevent.Event
+}
+
+func (p *recordingPublisher) Enabled() bool {
+	return true
+}
+
+func (p *recordingPublisher) Publish(event cloudevent.Event) {
+	p.events = append(p.events, event)
+}
+
+var _ = Describe("Test the sync events", func() {
+	It("Test publish the events of the workflow records", func() {
+		app := &model.Application{Name: "payments", Project: "finance"}
+		record := &model.WorkflowRecord{Name: "payments-v3", AppPrimaryKey: "payments", RevisionPrimaryKey: "v3", Status: "failed", Message: "step deploy failed", Cluster: "prod"}
+
+		By("nothing is published without the publisher")
+		PublishSyncEvent(nil, cloudevent.TypeWorkflowFailed, app, record)
+		PublishSyncEvent(cloudevent.New(cloudevent.Config{}), cloudevent.TypeWorkflowFailed, app, record)
+
+		publisher := &recordingPublisher{}
+		PublishSyncEvent(publisher, workflowEventType(record.Status), app, record)
+		Expect(publisher.events).Should(HaveLen(1))
+		event := publisher.events[0]
+		Expect(event.Type).Should(Equal(cloudevent.TypeWorkflowFailed))
+		Expect(event.ID).Should(Equal("payments/payments-v3/" + cloudevent.TypeWorkflowFailed))
+		Expect(event.Subject).Should(Equal("payments"))
+		data := event.Data.(SyncEventData)
+		Expect(data.Project).Should(Equal("finance"))
+		Expect(data.Cluster).Should(Equal("prod"))
+		Expect(data.Revision).Should(Equal("v3"))
+		Expect(data.StartTime).Should(BeNil())
+
+		Expect(workflowEventType("succeeded")).Should(Equal(cloudevent.TypeWorkflowCompleted))
+		Expect(workflowEventType("terminated")).Should(Equal(cloudevent.TypeWorkflowTerminated))
+	})
+})
@@ -0,0 +1,241 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	v1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// SyncWorkerApplication the worker syncing the applications from the cluster
+	SyncWorkerApplication = "application"
+	// SyncWorkerWorkflowRecord the worker syncing the workflow records from the cluster
+	SyncWorkerWorkflowRecord = "workflow-record"
+)
+
+const (
+	// syncScopeName the name of the sync scope updated by the API, there is only one
+	syncScopeName = "default"
+	// SyncScopeSourceConfig the sync scope is set by the server config
+	SyncScopeSourceConfig = "config"
+	// SyncScopeSourceAPI the sync scope is updated by the API
+	SyncScopeSourceAPI = "api"
+)
+
+// defaultSyncScope the sync scope of the server config, it is used until the scope is updated by the API
+var defaultSyncScope model.SyncScope
+
+// SyncStatusService reports how far the data of VelaUX drifts from the cluster state
+type SyncStatusService interface {
+	// Tracker returns the tracker of the sync worker, the metrics of the worker are registered at the first call
+	Tracker(worker string) *utils.SyncTracker
+	GetSyncStatus(ctx context.Context) *v1.SyncStatusResponse
+	// GetEffectiveSyncScope returns the scope filtering the external applications to sync
+	GetEffectiveSyncScope(ctx context.Context) (*model.SyncScope, error)
+	GetSyncScope(ctx context.Context) (*v1.SyncScopeResponse, error)
+	UpdateSyncScope(ctx context.Context, req v1.SyncScope) (*v1.SyncScopeResponse, error)
+	// ResetSyncScope drops the scope updated by the API, the scope of the server config is used again
+	ResetSyncScope(ctx context.Context) (*v1.SyncScopeResponse, error)
+}
+
+type syncStatusServiceImpl struct {
+	Store datastore.DataStore `inject:"datastore"`
+
+	mu       sync.Mutex
+	trackers map[string]*utils.SyncTracker
+}
+
+// NewSyncStatusService new sync status service
+func NewSyncStatusService() SyncStatusService {
+	return &syncStatusServiceImpl{trackers: map[string]*utils.SyncTracker{}}
+}
+
+func (s *syncStatusServiceImpl) Tracker(worker string) *utils.SyncTracker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tracker, ok := s.trackers[worker]; ok {
+		return tracker
+	}
+	tracker := utils.NewSyncTracker(worker)
+	s.trackers[worker] = tracker
+	registerSyncMetrics(worker, tracker)
+	return tracker
+}
+
+func (s *syncStatusServiceImpl) GetSyncStatus(ctx context.Context) *v1.SyncStatusResponse {
+	// the workers are listed even if they are not started on this replica
+	trackers := map[string]*utils.SyncTracker{SyncWorkerApplication: nil, SyncWorkerWorkflowRecord: nil}
+	s.mu.Lock()
+	for name, tracker := range s.trackers {
+		trackers[name] = tracker
+	}
+	s.mu.Unlock()
+	names := make([]string, 0, len(trackers))
+	for name := range trackers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := &v1.SyncStatusResponse{Workers: make([]v1.SyncWorkerStatus, 0, len(names))}
+	for _, name := range names {
+		status := trackers[name].Status()
+		worker := v1.SyncWorkerStatus{
+			Name:       name,
+			Running:    !status.Started.IsZero(),
+			Backlog:    status.Backlog,
+			LagSeconds: status.Lag.Seconds(),
+			ErrorCount: status.ErrorCount,
+			Errors:     make([]v1.SyncResourceError, 0, len(status.Failures)),
+		}
+		if !status.LastSuccess.IsZero() {
+			lastSuccess := status.LastSuccess
+			worker.LastSuccessTime = &lastSuccess
+		}
+		for _, failure := range status.Failures {
+			worker.Errors = append(worker.Errors, v1.SyncResourceError{
+				Resource:      failure.Resource,
+				Count:         failure.Count,
+				Message:       failure.Message,
+				LastErrorTime: failure.LastError,
+			})
+		}
+		res.Workers = append(res.Workers, worker)
+	}
+	return res
+}
+
+func (s *syncStatusServiceImpl) GetEffectiveSyncScope(ctx context.Context) (*model.SyncScope, error) {
+	scope := &model.SyncScope{Name: syncScopeName}
+	if err := s.Store.Get(ctx, scope); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			defaultScope := defaultSyncScope
+			return &defaultScope, nil
+		}
+		return nil, err
+	}
+	return scope, nil
+}
+
+func (s *syncStatusServiceImpl) GetSyncScope(ctx context.Context) (*v1.SyncScopeResponse, error) {
+	scope, err := s.GetEffectiveSyncScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return convertSyncScope(scope), nil
+}
+
+func (s *syncStatusServiceImpl) UpdateSyncScope(ctx context.Context, req v1.SyncScope) (*v1.SyncScopeResponse, error) {
+	if _, err := labels.Parse(req.LabelSelector); err != nil {
+		klog.Warningf("the label selector of the sync scope is invalid: %s", err.Error())
+		return nil, bcode.ErrInvalidSyncScope
+	}
+	updatedBy, _ := ctx.Value(&v1.CtxKeyUser).(string)
+	scope := &model.SyncScope{
+		Name:              syncScopeName,
+		IncludeNamespaces: req.IncludeNamespaces,
+		ExcludeNamespaces: req.ExcludeNamespaces,
+		IncludeClusters:   req.IncludeClusters,
+		ExcludeClusters:   req.ExcludeClusters,
+		LabelSelector:     req.LabelSelector,
+		UpdatedBy:         updatedBy,
+	}
+	err := s.Store.Put(ctx, scope)
+	if errors.Is(err, datastore.ErrRecordNotExist) {
+		err = s.Store.Add(ctx, scope)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return convertSyncScope(scope), nil
+}
+
+func (s *syncStatusServiceImpl) ResetSyncScope(ctx context.Context) (*v1.SyncScopeResponse, error) {
+	if err := s.Store.Delete(ctx, &model.SyncScope{Name: syncScopeName}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	return s.GetSyncScope(ctx)
+}
+
+func convertSyncScope(scope *model.SyncScope) *v1.SyncScopeResponse {
+	res := &v1.SyncScopeResponse{
+		SyncScope: v1.SyncScope{
+			IncludeNamespaces: scope.IncludeNamespaces,
+			ExcludeNamespaces: scope.ExcludeNamespaces,
+			IncludeClusters:   scope.IncludeClusters,
+			ExcludeClusters:   scope.ExcludeClusters,
+			LabelSelector:     scope.LabelSelector,
+		},
+		Source: SyncScopeSourceConfig,
+	}
+	if scope.Name != "" {
+		updateTime := scope.UpdateTime
+		res.Source = SyncScopeSourceAPI
+		res.UpdatedBy = scope.UpdatedBy
+		res.UpdateTime = &updateTime
+	}
+	return res
+}
+
+// registerSyncMetrics registers the metrics of the sync worker to the default registry, they are served by the metrics path
+func registerSyncMetrics(worker string, tracker *utils.SyncTracker) {
+	labels := prometheus.Labels{"worker": worker}
+	collectors := []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "velaux_sync_backlog",
+			Help:        "The number of the resources pending to sync from the cluster.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(tracker.Status().Backlog) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "velaux_sync_lag_seconds",
+			Help:        "How long the oldest pending resource has been waiting to sync.",
+			ConstLabels: labels,
+		}, func() float64 { return tracker.Status().Lag.Seconds() }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "velaux_sync_last_success_timestamp_seconds",
+			Help:        "The unix time of the last successful sync, it is 0 if nothing is synced.",
+			ConstLabels: labels,
+		}, func() float64 {
+			lastSuccess := tracker.Status().LastSuccess
+			if lastSuccess.IsZero() {
+				return 0
+			}
+			return float64(lastSuccess.UnixNano()) / 1e9
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "velaux_sync_errors_total",
+			Help:        "The number of the failures of the sync.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(tracker.Status().ErrorCount) }),
+	}
+	for _, collector := range collectors {
+		if err := prometheus.Register(collector); err != nil {
+			klog.Warningf("failed to register the metrics of the %s sync worker: %s", worker, err.Error())
+		}
+	}
+}
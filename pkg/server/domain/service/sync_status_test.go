@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	v1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the sync status", func() {
+	It("Test report the status and the metrics of the sync workers", func() {
+		syncStatusService := NewSyncStatusService()
+		By("the workers are reported even if they are not started")
+		status := syncStatusService.GetSyncStatus(context.TODO())
+		Expect(len(status.Workers)).Should(Equal(2))
+		Expect(status.Workers[0].Name).Should(Equal(SyncWorkerApplication))
+		Expect(status.Workers[0].Running).Should(BeFalse())
+		Expect(status.Workers[0].LastSuccessTime).Should(BeNil())
+
+		tracker := syncStatusService.Tracker(SyncWorkerApplication)
+		Expect(syncStatusService.Tracker(SyncWorkerApplication)).Should(Equal(tracker))
+		tracker.Start()
+		tracker.Queued("default/app-1")
+		tracker.Queued("default/app-2")
+		tracker.Failed("default/app-1", errors.New("the cluster is unreachable"))
+		tracker.Succeeded("default/app-2")
+
+		status = syncStatusService.GetSyncStatus(context.TODO())
+		Expect(status.Workers[0].Running).Should(BeTrue())
+		Expect(status.Workers[0].LastSuccessTime).ShouldNot(BeNil())
+		Expect(status.Workers[0].Backlog).Should(Equal(1))
+		Expect(status.Workers[0].ErrorCount).Should(Equal(int64(1)))
+		Expect(len(status.Workers[0].Errors)).Should(Equal(1))
+		Expect(status.Workers[0].Errors[0].Resource).Should(Equal("default/app-1"))
+		Expect(status.Workers[1].Name).Should(Equal(SyncWorkerWorkflowRecord))
+		Expect(status.Workers[1].Running).Should(BeFalse())
+
+		By("the metrics are registered to the default registry")
+		count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "velaux_sync_backlog", "velaux_sync_errors_total")
+		Expect(err).Should(BeNil())
+		Expect(count).Should(Equal(2))
+		Expect(testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(`
+# HELP velaux_sync_backlog The number of the resources pending to sync from the cluster.
+# TYPE velaux_sync_backlog gauge
+velaux_sync_backlog{worker="application"} 1
+`), "velaux_sync_backlog")).Should(BeNil())
+	})
+
+	It("Test update and reset the sync scope", func() {
+		ctx := context.WithValue(context.TODO(), &v1.CtxKeyUser, "admin")
+		ds, err := NewDatastore(datastore.Config{Type: "kubeapi", Database: "sync-scope-test"})
+		Expect(err).Should(BeNil())
+		defaultSyncScope.IncludeNamespaces = []string{"team-a"}
+		defer func() { defaultSyncScope.IncludeNamespaces = nil }()
+		syncStatusService := &syncStatusServiceImpl{Store: ds}
+
+		By("the scope of the server config is used by default")
+		scope, err := syncStatusService.GetSyncScope(ctx)
+		Expect(err).Should(BeNil())
+		Expect(scope.Source).Should(Equal(SyncScopeSourceConfig))
+		Expect(scope.IncludeNamespaces).Should(Equal([]string{"team-a"}))
+
+		By("the invalid label selector is rejected")
+		_, err = syncStatusService.UpdateSyncScope(ctx, v1.SyncScope{LabelSelector: "team in (a"})
+		Expect(err).Should(Equal(bcode.ErrInvalidSyncScope))
+
+		By("the scope updated by the API replaces the scope of the server config")
+		scope, err = syncStatusService.UpdateSyncScope(ctx, v1.SyncScope{ExcludeClusters: []string{"edge"}, LabelSelector: "team=payments"})
+		Expect(err).Should(BeNil())
+		Expect(scope.Source).Should(Equal(SyncScopeSourceAPI))
+		Expect(scope.UpdatedBy).Should(Equal("admin"))
+		effective, err := syncStatusService.GetEffectiveSyncScope(ctx)
+		Expect(err).Should(BeNil())
+		Expect(effective.IncludeNamespaces).Should(BeEmpty())
+		Expect(effective.ExcludeClusters).Should(Equal([]string{"edge"}))
+		Expect(effective.LabelSelector).Should(Equal("team=payments"))
+		_, err = syncStatusService.UpdateSyncScope(ctx, v1.SyncScope{LabelSelector: "team=orders"})
+		Expect(err).Should(BeNil())
+
+		By("the scope of the server config is used again after the reset")
+		scope, err = syncStatusService.ResetSyncScope(ctx)
+		Expect(err).Should(BeNil())
+		Expect(scope.Source).Should(Equal(SyncScopeSourceConfig))
+		Expect(scope.IncludeNamespaces).Should(Equal([]string{"team-a"}))
+	})
+})
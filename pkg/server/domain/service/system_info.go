@@ -19,9 +19,11 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/version"
@@ -33,25 +35,41 @@ import (
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
 
+const (
+	// FeatureAddonRegistry means discovering and installing the addons from the registries
+	FeatureAddonRegistry = "addonRegistry"
+	// FeatureDefinitionCatalogSync means importing the definitions from the catalog repositories
+	FeatureDefinitionCatalogSync = "definitionCatalogSync"
+	// FeatureUsageCollection means reporting the anonymous usage data
+	FeatureUsageCollection = "usageCollection"
+)
+
 // SystemInfoService is service for systemInfoCollection
 type SystemInfoService interface {
 	Get(ctx context.Context) (*model.SystemInfo, error)
 	GetSystemInfo(ctx context.Context) (*v1.SystemInfoResponse, error)
 	UpdateSystemInfo(ctx context.Context, sysInfo v1.SystemInfoRequest) (*v1.SystemInfoResponse, error)
+	GetVersionAdvice(ctx context.Context) (*v1.VersionAdviceResponse, error)
 	Init(ctx context.Context) error
 }
 
 type systemInfoServiceImpl struct {
 	Store      datastore.DataStore `inject:"datastore"`
 	KubeClient client.Client       `inject:"kubeClient"`
+	// cache the system info is read by every login and token refresh, it is purged after updating
+	cache *utils.LRUCache
 }
 
 // NewSystemInfoService return a systemInfoCollectionService
 func NewSystemInfoService() SystemInfoService {
-	return &systemInfoServiceImpl{}
+	return &systemInfoServiceImpl{cache: utils.NewLRUCache(64, 30*time.Second)}
 }
 
 func (u systemInfoServiceImpl) Get(ctx context.Context) (*model.SystemInfo, error) {
+	if cached, ok := u.cache.Get(datastore.TenantKey(ctx, "systemInfo")); ok {
+		info := *cached.(*model.SystemInfo)
+		return &info, nil
+	}
 	// first get request will init systemInfoCollection{installId: {random}, enableCollection: true}
 	info := &model.SystemInfo{}
 	entities, err := u.Store.List(ctx, info, &datastore.ListOptions{})
@@ -63,6 +81,8 @@ func (u systemInfoServiceImpl) Get(ctx context.Context) (*model.SystemInfo, erro
 		if info.LoginType == "" {
 			info.LoginType = model.LoginTypeLocal
 		}
+		cached := *info
+		u.cache.Put(datastore.TenantKey(ctx, "systemInfo"), &cached)
 		return info, nil
 	}
 	info.SignedKey = rand.String(32)
@@ -119,6 +139,48 @@ func (u systemInfoServiceImpl) UpdateSystemInfo(ctx context.Context, sysInfo v1.
 		StatisticInfo:               info.StatisticInfo,
 		DexUserDefaultProjects:      sysInfo.DexUserDefaultProjects,
 		DexUserDefaultPlatformRoles: info.DexUserDefaultPlatformRoles,
+		MaintenanceMode:             info.MaintenanceMode,
+		OfflineMode:                 info.OfflineMode,
+		OAuthConnectors:             info.OAuthConnectors,
+		OAuthGroupMappings:          info.OAuthGroupMappings,
+		SessionSettings:             info.SessionSettings,
+		VelaAddress:                 info.VelaAddress,
+		LoginBanner:                 info.LoginBanner,
+	}
+	if sysInfo.VelaAddress != "" {
+		modifiedInfo.VelaAddress = strings.TrimSuffix(sysInfo.VelaAddress, "/")
+	}
+	if sysInfo.MaintenanceMode != nil {
+		modifiedInfo.MaintenanceMode = *sysInfo.MaintenanceMode
+	}
+	if sysInfo.OfflineMode != nil {
+		modifiedInfo.OfflineMode = *sysInfo.OfflineMode
+	}
+	if sysInfo.SessionSettings != nil {
+		if err := validateSessionSettings(sysInfo.SessionSettings); err != nil {
+			return nil, err
+		}
+		modifiedInfo.SessionSettings = sysInfo.SessionSettings
+	}
+	if sysInfo.LoginBanner != nil {
+		banner, err := mergeLoginBanner(info.LoginBanner, sysInfo.LoginBanner)
+		if err != nil {
+			return nil, err
+		}
+		modifiedInfo.LoginBanner = banner
+	}
+	if sysInfo.OAuthConnectors != nil {
+		connectors, err := mergeOAuthConnectors(info.OAuthConnectors, sysInfo.OAuthConnectors)
+		if err != nil {
+			return nil, err
+		}
+		modifiedInfo.OAuthConnectors = connectors
+	}
+	if sysInfo.OAuthGroupMappings != nil {
+		if err := validateOAuthGroupMappings(sysInfo.OAuthGroupMappings); err != nil {
+			return nil, err
+		}
+		modifiedInfo.OAuthGroupMappings = sysInfo.OAuthGroupMappings
 	}
 
 	if sysInfo.LoginType == model.LoginTypeDex {
@@ -133,6 +195,17 @@ func (u systemInfoServiceImpl) UpdateSystemInfo(ctx context.Context, sysInfo v1.
 		if err != nil {
 			return nil, err
 		}
+		if len(modifiedInfo.OAuthConnectors) > 0 {
+			issuer := sysInfo.VelaAddress + "/dex"
+			if sysInfo.VelaAddress == "" {
+				dexConfig, err := getDexConfig(ctx, u.KubeClient)
+				if err != nil {
+					return nil, err
+				}
+				issuer = dexConfig.Issuer
+			}
+			connectors = append(connectors, buildDexOAuthConnectors(modifiedInfo.OAuthConnectors, issuer)...)
+		}
 		if len(connectors) < 1 {
 			return nil, bcode.ErrNoDexConnector
 		}
@@ -147,11 +220,20 @@ func (u systemInfoServiceImpl) UpdateSystemInfo(ctx context.Context, sysInfo v1.
 	if err != nil {
 		return nil, err
 	}
+	u.cache.Purge()
 	return &v1.SystemInfoResponse{
 		SystemInfo: v1.SystemInfo{
-			PlatformID:       modifiedInfo.InstallID,
-			EnableCollection: modifiedInfo.EnableCollection,
-			LoginType:        modifiedInfo.LoginType,
+			PlatformID:         modifiedInfo.InstallID,
+			EnableCollection:   modifiedInfo.EnableCollection && !modifiedInfo.OfflineMode,
+			LoginType:          modifiedInfo.LoginType,
+			MaintenanceMode:    modifiedInfo.MaintenanceMode,
+			OfflineMode:        modifiedInfo.OfflineMode,
+			DegradedFeatures:   degradedFeatures(&modifiedInfo),
+			OAuthConnectors:    convertOAuthConnectors2DTO(modifiedInfo.OAuthConnectors),
+			OAuthGroupMappings: modifiedInfo.OAuthGroupMappings,
+			SessionSettings:    effectiveSessionSettings(modifiedInfo.SessionSettings),
+			VelaAddress:        modifiedInfo.VelaAddress,
+			LoginBanner:        modifiedInfo.LoginBanner,
 			// always use the initial createTime as system's installTime
 			InstallTime: info.CreateTime,
 		},
@@ -164,6 +246,10 @@ func (u systemInfoServiceImpl) Init(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	// the tokens of all tenants are signed by the key of the default partition, and the dex config is shared
+	if datastore.TenantFromContext(ctx) != "" {
+		return nil
+	}
 	signedKey = info.SignedKey
 	_, err = initDexConfig(ctx, u.KubeClient, "http://velaux.com")
 	return err
@@ -172,10 +258,60 @@ func (u systemInfoServiceImpl) Init(ctx context.Context) error {
 func convertInfoToBase(info *model.SystemInfo) v1.SystemInfo {
 	return v1.SystemInfo{
 		PlatformID:                  info.InstallID,
-		EnableCollection:            info.EnableCollection,
+		EnableCollection:            info.EnableCollection && !info.OfflineMode,
 		LoginType:                   info.LoginType,
 		InstallTime:                 info.CreateTime,
 		DexUserDefaultProjects:      info.DexUserDefaultProjects,
 		DexUserDefaultPlatformRoles: info.DexUserDefaultPlatformRoles,
+		MaintenanceMode:             info.MaintenanceMode,
+		OfflineMode:                 info.OfflineMode,
+		DegradedFeatures:            degradedFeatures(info),
+		OAuthConnectors:             convertOAuthConnectors2DTO(info.OAuthConnectors),
+		OAuthGroupMappings:          info.OAuthGroupMappings,
+		SessionSettings:             effectiveSessionSettings(info.SessionSettings),
+		VelaAddress:                 info.VelaAddress,
+		LoginBanner:                 info.LoginBanner,
+	}
+}
+
+// mergeLoginBanner replaces the login notice, the version is increased if the notice changes.
+// The removed notice is kept with the empty content, so the version is never reused.
+func mergeLoginBanner(current, update *model.LoginBanner) (*model.LoginBanner, error) {
+	if update.Content == "" && update.RequireConsent {
+		return nil, bcode.ErrInvalidLoginBanner
+	}
+	banner := &model.LoginBanner{Title: update.Title, Content: update.Content, RequireConsent: update.RequireConsent}
+	if update.Content == "" {
+		banner.Title = ""
+	}
+	switch {
+	case current == nil:
+		banner.Version = 1
+	case current.Title != banner.Title || current.Content != banner.Content:
+		banner.Version = current.Version + 1
+	default:
+		banner.Version = current.Version
+	}
+	return banner, nil
+}
+
+// degradedFeatures returns the features which depend on the internet access
+func degradedFeatures(info *model.SystemInfo) []string {
+	if !info.OfflineMode {
+		return nil
+	}
+	return []string{FeatureAddonRegistry, FeatureDefinitionCatalogSync, FeatureUsageCollection}
+}
+
+// isOfflineMode checks whether the outbound internet calls are disabled
+func isOfflineMode(ctx context.Context, sysService SystemInfoService) bool {
+	if sysService == nil {
+		return false
+	}
+	info, err := sysService.Get(ctx)
+	if err != nil {
+		klog.Errorf("failed to get the system info: %s", err.Error())
+		return false
 	}
+	return info.OfflineMode
 }
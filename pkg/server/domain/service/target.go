@@ -45,6 +45,7 @@ type TargetService interface {
 	UpdateTarget(ctx context.Context, Target *model.Target, req apisv1.UpdateTargetRequest) (*apisv1.DetailTargetResponse, error)
 	ListTargets(ctx context.Context, page, pageSize int, projectName string) (*apisv1.ListTargetResponse, error)
 	ListTargetCount(ctx context.Context, projectName string) (int64, error)
+	ReconcileNamespacePolicies(ctx context.Context) error
 	Init(ctx context.Context) error
 }
 
@@ -139,6 +140,9 @@ func (dt *targetServiceImpl) DeleteTarget(ctx context.Context, targetName string
 // CreateTarget will create a delivery target binding with a cluster and namespace, by default, it will use local cluster and namespace align with targetName
 // TODO(@wonderflow): we should support empty target in the future which only delivery cloud resources
 func (dt *targetServiceImpl) CreateTarget(ctx context.Context, req apisv1.CreateTargetRequest) (*apisv1.DetailTargetResponse, error) {
+	if err := validateNamespacePolicy((*model.TargetNamespacePolicy)(req.NamespacePolicy)); err != nil {
+		return nil, err
+	}
 	var project = model.Project{
 		Name: req.Project,
 	}
@@ -156,14 +160,24 @@ func (dt *targetServiceImpl) CreateTarget(ctx context.Context, req apisv1.Create
 	if err := managePrivilegesForTarget(createTargetCtx, dt.K8sClient, &target, false); err != nil {
 		return nil, err
 	}
+	if err := applyNamespacePolicy(createTargetCtx, dt.K8sClient, &target, nil); err != nil {
+		return nil, err
+	}
 	err := repository.CreateTarget(ctx, dt.Store, &target)
 	if err != nil {
 		return nil, err
 	}
+	if target.Project != "" {
+		warnProjectQuotaUsage(ctx, dt.Store, target.Project)
+	}
 	return dt.DetailTarget(ctx, &target)
 }
 
 func (dt *targetServiceImpl) UpdateTarget(ctx context.Context, target *model.Target, req apisv1.UpdateTargetRequest) (*apisv1.DetailTargetResponse, error) {
+	if err := validateNamespacePolicy((*model.TargetNamespacePolicy)(req.NamespacePolicy)); err != nil {
+		return nil, err
+	}
+	previous := target.NamespacePolicy
 	targetModel := convertUpdateReqToTargetModel(target, req)
 	if err := dt.Store.Put(ctx, targetModel); err != nil {
 		return nil, err
@@ -173,14 +187,23 @@ func (dt *targetServiceImpl) UpdateTarget(ctx context.Context, target *model.Tar
 	if err := managePrivilegesForTarget(updateCtx, dt.K8sClient, targetModel, false); err != nil {
 		return nil, err
 	}
+	if err := applyNamespacePolicy(updateCtx, dt.K8sClient, targetModel, previous); err != nil {
+		return nil, err
+	}
 	return dt.DetailTarget(ctx, targetModel)
 }
 
 // DetailTarget detail Target
 func (dt *targetServiceImpl) DetailTarget(ctx context.Context, target *model.Target) (*apisv1.DetailTargetResponse, error) {
-	return &apisv1.DetailTargetResponse{
+	detail := &apisv1.DetailTargetResponse{
 		TargetBase: *dt.convertFromTargetModel(ctx, target),
-	}, nil
+	}
+	drift, err := namespacePolicyDrift(utils.WithProject(ctx, ""), dt.K8sClient, target)
+	if err != nil {
+		klog.Warningf("failed to check the namespace policy of the target %s: %s", target.Name, err.Error())
+	}
+	detail.NamespaceDrift = drift
+	return detail, nil
 }
 
 // GetTarget get Target model
@@ -198,17 +221,19 @@ func convertUpdateReqToTargetModel(target *model.Target, req apisv1.UpdateTarget
 	target.Alias = req.Alias
 	target.Description = req.Description
 	target.Variable = req.Variable
+	target.NamespacePolicy = (*model.TargetNamespacePolicy)(req.NamespacePolicy)
 	return target
 }
 
 func convertCreateReqToTargetModel(req apisv1.CreateTargetRequest) model.Target {
 	target := model.Target{
-		Name:        req.Name,
-		Alias:       req.Alias,
-		Description: req.Description,
-		Cluster:     (*model.ClusterTarget)(req.Cluster),
-		Variable:    req.Variable,
-		Project:     req.Project,
+		Name:            req.Name,
+		Alias:           req.Alias,
+		Description:     req.Description,
+		Cluster:         (*model.ClusterTarget)(req.Cluster),
+		Variable:        req.Variable,
+		Project:         req.Project,
+		NamespacePolicy: (*model.TargetNamespacePolicy)(req.NamespacePolicy),
 	}
 	return target
 }
@@ -217,14 +242,15 @@ func (dt *targetServiceImpl) convertFromTargetModel(ctx context.Context, target
 	var appNum int64
 	// TODO: query app num in target
 	targetBase := &apisv1.TargetBase{
-		Name:        target.Name,
-		Alias:       target.Alias,
-		Description: target.Description,
-		Cluster:     (*apisv1.ClusterTarget)(target.Cluster),
-		Variable:    target.Variable,
-		CreateTime:  target.CreateTime,
-		UpdateTime:  target.UpdateTime,
-		AppNum:      appNum,
+		Name:            target.Name,
+		Alias:           target.Alias,
+		Description:     target.Description,
+		Cluster:         (*apisv1.ClusterTarget)(target.Cluster),
+		Variable:        target.Variable,
+		CreateTime:      target.CreateTime,
+		UpdateTime:      target.UpdateTime,
+		AppNum:          appNum,
+		NamespacePolicy: (*apisv1.TargetNamespacePolicy)(target.NamespacePolicy),
 	}
 	if target.Project != "" {
 		var project = model.Project{
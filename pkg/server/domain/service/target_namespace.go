@@ -0,0 +1,273 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	pkgUtils "github.com/oam-dev/kubevela/pkg/utils"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// NetworkPolicyDenyAllIngress denies the ingress traffic of all pods in the namespace
+	NetworkPolicyDenyAllIngress = "deny-all-ingress"
+	// NetworkPolicyDenyAll denies the ingress and the egress traffic of all pods in the namespace
+	NetworkPolicyDenyAll = "deny-all"
+	// NetworkPolicyAllowSameNamespace only allows the ingress traffic from the pods in the same namespace
+	NetworkPolicyAllowSameNamespace = "allow-same-namespace"
+
+	podSecurityLabelPrefix  = "pod-security.kubernetes.io/"
+	podSecurityEnforceLabel = podSecurityLabelPrefix + "enforce"
+	// targetNetworkPolicyName the network policy created in the namespace of the target by the preset
+	targetNetworkPolicyName = "velaux-target-policy"
+)
+
+var podSecurityLevels = []string{"privileged", "baseline", "restricted"}
+
+var networkPolicyPresets = []string{NetworkPolicyDenyAllIngress, NetworkPolicyDenyAll, NetworkPolicyAllowSameNamespace}
+
+// reservedNamespaceLabels the labels of the namespace managed by KubeVela, the namespace policies could not set them
+var reservedNamespaceLabels = []string{oam.LabelRuntimeNamespaceUsage, oam.LabelNamespaceOfTargetName, oam.LabelControlPlaneNamespaceUsage, oam.LabelNamespaceOfEnvName}
+
+// validateNamespacePolicy checks the keys and the values of the labels and the annotations, and the presets
+func validateNamespacePolicy(policy *model.TargetNamespacePolicy) error {
+	if policy == nil {
+		return nil
+	}
+	for key, value := range policy.Labels {
+		if len(validation.IsQualifiedName(key)) > 0 || len(validation.IsValidLabelValue(value)) > 0 {
+			return bcode.ErrInvalidNamespacePolicy
+		}
+		// the pod security labels are set by the pod security preset
+		if strings.HasPrefix(key, podSecurityLabelPrefix) || pkgUtils.StringsContain(reservedNamespaceLabels, key) {
+			return bcode.ErrInvalidNamespacePolicy
+		}
+	}
+	for key := range policy.Annotations {
+		if len(validation.IsQualifiedName(key)) > 0 {
+			return bcode.ErrInvalidNamespacePolicy
+		}
+	}
+	if policy.PodSecurity != "" && !pkgUtils.StringsContain(podSecurityLevels, policy.PodSecurity) {
+		return bcode.ErrInvalidNamespacePolicy
+	}
+	if policy.NetworkPolicy != "" && !pkgUtils.StringsContain(networkPolicyPresets, policy.NetworkPolicy) {
+		return bcode.ErrInvalidNamespacePolicy
+	}
+	return nil
+}
+
+// namespacePolicyLabels the labels of the policy with the pod security label
+func namespacePolicyLabels(policy *model.TargetNamespacePolicy) map[string]string {
+	labels := map[string]string{}
+	if policy == nil {
+		return labels
+	}
+	for k, v := range policy.Labels {
+		labels[k] = v
+	}
+	if policy.PodSecurity != "" {
+		labels[podSecurityEnforceLabel] = policy.PodSecurity
+	}
+	return labels
+}
+
+func namespacePolicyAnnotations(policy *model.TargetNamespacePolicy) map[string]string {
+	if policy == nil || policy.Annotations == nil {
+		return map[string]string{}
+	}
+	return policy.Annotations
+}
+
+func namespacePolicyNetworkPreset(policy *model.TargetNamespacePolicy) string {
+	if policy == nil {
+		return ""
+	}
+	return policy.NetworkPolicy
+}
+
+// mergeManagedKeys sets the desired keys and removes the keys only in the previous policy, it returns whether the map is changed
+func mergeManagedKeys(current *map[string]string, desired, previous map[string]string) bool {
+	changed := false
+	for k := range previous {
+		if _, ok := desired[k]; ok {
+			continue
+		}
+		if _, ok := (*current)[k]; ok {
+			delete(*current, k)
+			changed = true
+		}
+	}
+	for k, v := range desired {
+		if *current == nil {
+			*current = map[string]string{}
+		}
+		if value, ok := (*current)[k]; !ok || value != v {
+			(*current)[k] = v
+			changed = true
+		}
+	}
+	return changed
+}
+
+// applyNamespacePolicy applies the namespace policy of the target to its namespace, the labels, the annotations and
+// the network policy of the previous policy are removed if they are not in the current one
+func applyNamespacePolicy(ctx context.Context, cli client.Client, target *model.Target, previous *model.TargetNamespacePolicy) error {
+	if target.Cluster == nil || (target.NamespacePolicy == nil && previous == nil) {
+		return nil
+	}
+	ctx = multicluster.ContextWithClusterName(ctx, target.Cluster.ClusterName)
+	var namespace corev1.Namespace
+	if err := cli.Get(ctx, types.NamespacedName{Name: target.Cluster.Namespace}, &namespace); err != nil {
+		return err
+	}
+	changed := mergeManagedKeys(&namespace.Labels, namespacePolicyLabels(target.NamespacePolicy), namespacePolicyLabels(previous))
+	if mergeManagedKeys(&namespace.Annotations, namespacePolicyAnnotations(target.NamespacePolicy), namespacePolicyAnnotations(previous)) {
+		changed = true
+	}
+	if changed {
+		if err := cli.Update(ctx, &namespace); err != nil {
+			return err
+		}
+	}
+	preset := namespacePolicyNetworkPreset(target.NamespacePolicy)
+	if preset == "" && namespacePolicyNetworkPreset(previous) == "" {
+		return nil
+	}
+	return reconcileTargetNetworkPolicy(ctx, cli, namespace.Name, preset)
+}
+
+// networkPolicyPresetSpec the spec of the network policy preset, all pods of the namespace are selected
+func networkPolicyPresetSpec(preset string) networkingv1.NetworkPolicySpec {
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+	}
+	switch preset {
+	case NetworkPolicyDenyAll:
+		spec.PolicyTypes = append(spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+	case NetworkPolicyAllowSameNamespace:
+		spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}}}
+	}
+	return spec
+}
+
+// reconcileTargetNetworkPolicy creates or updates the network policy of the preset, it is deleted if the preset is empty
+func reconcileTargetNetworkPolicy(ctx context.Context, cli client.Client, namespace, preset string) error {
+	existing := &networkingv1.NetworkPolicy{}
+	err := cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: targetNetworkPolicyName}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exist := err == nil
+	if preset == "" {
+		if exist {
+			return client.IgnoreNotFound(cli.Delete(ctx, existing))
+		}
+		return nil
+	}
+	spec := networkPolicyPresetSpec(preset)
+	if !exist {
+		return cli.Create(ctx, &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: targetNetworkPolicyName, Namespace: namespace},
+			Spec:       spec,
+		})
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, spec) {
+		return nil
+	}
+	existing.Spec = spec
+	return cli.Update(ctx, existing)
+}
+
+// namespacePolicyDrift compares the namespace of the target with the namespace policy
+func namespacePolicyDrift(ctx context.Context, cli client.Client, target *model.Target) ([]apisv1.NamespacePolicyDrift, error) {
+	if target.Cluster == nil || target.NamespacePolicy == nil {
+		return nil, nil
+	}
+	ctx = multicluster.ContextWithClusterName(ctx, target.Cluster.ClusterName)
+	var namespace corev1.Namespace
+	if err := cli.Get(ctx, types.NamespacedName{Name: target.Cluster.Namespace}, &namespace); err != nil {
+		return nil, err
+	}
+	var drifts []apisv1.NamespacePolicyDrift
+	compare := func(kind string, desired, actual map[string]string) {
+		var keys []string
+		for k := range desired {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if actual[k] != desired[k] {
+				drifts = append(drifts, apisv1.NamespacePolicyDrift{Kind: kind, Key: k, Expected: desired[k], Actual: actual[k]})
+			}
+		}
+	}
+	compare("label", namespacePolicyLabels(target.NamespacePolicy), namespace.Labels)
+	compare("annotation", namespacePolicyAnnotations(target.NamespacePolicy), namespace.Annotations)
+	if preset := target.NamespacePolicy.NetworkPolicy; preset != "" {
+		drift := apisv1.NamespacePolicyDrift{Kind: "networkPolicy", Key: targetNetworkPolicyName, Expected: preset}
+		existing := &networkingv1.NetworkPolicy{}
+		err := cli.Get(ctx, types.NamespacedName{Namespace: namespace.Name, Name: targetNetworkPolicyName}, existing)
+		switch {
+		case apierrors.IsNotFound(err):
+			drifts = append(drifts, drift)
+		case err != nil:
+			return nil, err
+		case !equality.Semantic.DeepEqual(existing.Spec, networkPolicyPresetSpec(preset)):
+			drift.Actual = "modified"
+			drifts = append(drifts, drift)
+		}
+	}
+	return drifts, nil
+}
+
+// ReconcileNamespacePolicies applies the namespace policies of all targets again, so the drift is fixed
+func (dt *targetServiceImpl) ReconcileNamespacePolicies(ctx context.Context) error {
+	targets, err := repository.ListTarget(ctx, dt.Store, "", nil)
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		if target.NamespacePolicy == nil {
+			continue
+		}
+		if err := applyNamespacePolicy(ctx, dt.K8sClient, target, nil); err != nil {
+			klog.Errorf("failed to reconcile the namespace policy of the target %s: %s", target.Name, err.Error())
+		}
+	}
+	return nil
+}
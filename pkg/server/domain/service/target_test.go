@@ -22,10 +22,18 @@ import (
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
 
 var _ = Describe("Test target service functions", func() {
@@ -86,4 +94,63 @@ var _ = Describe("Test target service functions", func() {
 		err = targetService.DeleteTarget(context.TODO(), "test--target")
 		Expect(err).Should(BeNil())
 	})
+
+	It("Test apply and reconcile the namespace policy", func() {
+		ctx := context.TODO()
+		_, err := projectService.CreateProject(ctx, apisv1.CreateProjectRequest{Name: "target-policy-project"})
+		Expect(err).Should(BeNil())
+		req := apisv1.CreateTargetRequest{
+			Name:    "policy-target",
+			Project: "target-policy-project",
+			Cluster: &apisv1.ClusterTarget{ClusterName: multicluster.ClusterLocalName, Namespace: "policy-target"},
+			NamespacePolicy: &apisv1.TargetNamespacePolicy{
+				Labels: map[string]string{oam.LabelNamespaceOfTargetName: "other"},
+			},
+		}
+		_, err = targetService.CreateTarget(ctx, req)
+		Expect(err).Should(Equal(bcode.ErrInvalidNamespacePolicy))
+
+		req.NamespacePolicy = &apisv1.TargetNamespacePolicy{
+			Labels:        map[string]string{"team": "payments"},
+			Annotations:   map[string]string{"owner": "payments@example.com"},
+			PodSecurity:   "baseline",
+			NetworkPolicy: NetworkPolicyDenyAllIngress,
+		}
+		_, err = targetService.CreateTarget(ctx, req)
+		Expect(err).Should(BeNil())
+		var namespace corev1.Namespace
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "policy-target"}, &namespace)).Should(BeNil())
+		Expect(namespace.Labels).Should(HaveKeyWithValue("team", "payments"))
+		Expect(namespace.Labels).Should(HaveKeyWithValue(podSecurityEnforceLabel, "baseline"))
+		Expect(namespace.Annotations).Should(HaveKeyWithValue("owner", "payments@example.com"))
+		var networkPolicy networkingv1.NetworkPolicy
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "policy-target", Name: targetNetworkPolicyName}, &networkPolicy)).Should(BeNil())
+		Expect(networkPolicy.Spec.PolicyTypes).Should(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeIngress}))
+
+		By("the drift is shown in the detail and fixed by the reconciliation")
+		namespace.Labels["team"] = "checkout"
+		Expect(k8sClient.Update(ctx, &namespace)).Should(BeNil())
+		target, err := targetService.GetTarget(ctx, "policy-target")
+		Expect(err).Should(BeNil())
+		detail, err := targetService.DetailTarget(ctx, target)
+		Expect(err).Should(BeNil())
+		Expect(detail.NamespaceDrift).Should(Equal([]apisv1.NamespacePolicyDrift{{Kind: "label", Key: "team", Expected: "payments", Actual: "checkout"}}))
+		Expect(targetService.ReconcileNamespacePolicies(ctx)).Should(BeNil())
+		detail, err = targetService.DetailTarget(ctx, target)
+		Expect(err).Should(BeNil())
+		Expect(detail.NamespaceDrift).Should(BeEmpty())
+
+		By("the labels and the presets removed from the policy are removed from the namespace")
+		_, err = targetService.UpdateTarget(ctx, target, apisv1.UpdateTargetRequest{
+			NamespacePolicy: &apisv1.TargetNamespacePolicy{Labels: map[string]string{"tier": "backend"}},
+		})
+		Expect(err).Should(BeNil())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "policy-target"}, &namespace)).Should(BeNil())
+		Expect(namespace.Labels).Should(HaveKeyWithValue("tier", "backend"))
+		Expect(namespace.Labels).ShouldNot(HaveKey("team"))
+		Expect(namespace.Labels).ShouldNot(HaveKey(podSecurityEnforceLabel))
+		Expect(namespace.Annotations).ShouldNot(HaveKey("owner"))
+		err = k8sClient.Get(ctx, types.NamespacedName{Namespace: "policy-target", Name: targetNetworkPolicyName}, &networkPolicy)
+		Expect(apierrors.IsNotFound(err)).Should(BeTrue())
+	})
 })
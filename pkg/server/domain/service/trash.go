@@ -0,0 +1,328 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pkgUtils "github.com/oam-dev/kubevela/pkg/utils"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	apiutils "github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// TrashKindApplication the deleted application with its components, policies, triggers, workflows and env bindings
+	TrashKindApplication = "application"
+	// TrashKindEnvironment the deleted environment
+	TrashKindEnvironment = "environment"
+	// TrashKindPipeline the deleted pipeline with its contexts, the pipeline runs are not kept
+	TrashKindPipeline = "pipeline"
+	// TrashKindRole the deleted project role or platform role
+	TrashKindRole = "role"
+)
+
+// trashRetention how long the deleted entities are kept in the trash, it is set by the server config and 0 disables the trash
+var trashRetention = time.Hour * 24 * 7
+
+// TrashService lists, restores and purges the deleted entities in the trash
+type TrashService interface {
+	// ListTrash lists the items that the login user could read, the project and the kind filter the items if they are not empty
+	ListTrash(ctx context.Context, projectName, kind string, page, pageSize int) (*apisv1.ListTrashResponse, error)
+	// RestoreTrash adds the entity and its records back, the login user must be allowed to create the entity
+	RestoreTrash(ctx context.Context, itemName string) (*apisv1.TrashItemBase, error)
+	// PurgeTrash deletes the items out of the retention permanently
+	PurgeTrash(ctx context.Context) error
+}
+
+type trashServiceImpl struct {
+	Store       datastore.DataStore `inject:"datastore"`
+	KubeClient  client.Client       `inject:"kubeClient"`
+	RbacService RBACService         `inject:""`
+}
+
+// NewTrashService new trash service
+func NewTrashService() TrashService {
+	return &trashServiceImpl{}
+}
+
+// skipTrashKey the context key of the deletions that skip the trash
+type skipTrashKey struct{}
+
+// withoutTrash the entities deleted with the returned context are not kept in the trash, such as the purged orphans
+func withoutTrash(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipTrashKey{}, true)
+}
+
+// moveToTrash keeps the entity and the records deleted with it in the trash, it is called before they are deleted,
+// so the deletion is aborted if they could not be kept. Nothing is kept if the trash is disabled.
+func moveToTrash(ctx context.Context, store datastore.DataStore, kind, projectName, alias string, entity datastore.Entity, related ...datastore.Entity) (*model.TrashItem, error) {
+	if skip, _ := ctx.Value(skipTrashKey{}).(bool); trashRetention <= 0 || skip {
+		return nil, nil
+	}
+	operator, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	item := &model.TrashItem{
+		Name:      apiutils.GenerateVersion(kind) + "-" + rand.String(4),
+		Kind:      kind,
+		Project:   projectName,
+		Entity:    entity.PrimaryKey(),
+		Alias:     alias,
+		DeletedAt: time.Now(),
+		DeletedBy: operator,
+	}
+	for _, record := range append([]datastore.Entity{entity}, related...) {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		item.Records = append(item.Records, model.TrashRecord{Table: record.TableName(), Data: data})
+	}
+	if err := store.Add(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// listTrashRecords lists the records of the queries, they are kept in the trash with the deleted entity
+func listTrashRecords(ctx context.Context, store datastore.DataStore, queries ...datastore.Entity) ([]datastore.Entity, error) {
+	var records []datastore.Entity
+	for _, query := range queries {
+		entities, err := store.List(ctx, query, &datastore.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, entities...)
+	}
+	return records, nil
+}
+
+// trashResource the RBAC resource of the deleted entity, the cross-project roles are managed as the platform roles
+func trashResource(item *model.TrashItem) string {
+	if item.Kind == TrashKindRole && (item.Project == "" || item.Project == model.RoleScopeCrossProject) {
+		return "role:" + item.Entity
+	}
+	return fmt.Sprintf("project:%s/%s:%s", item.Project, item.Kind, item.Entity)
+}
+
+func convertTrashItem(item *model.TrashItem) *apisv1.TrashItemBase {
+	return &apisv1.TrashItemBase{
+		Name:      item.Name,
+		Kind:      item.Kind,
+		Project:   item.Project,
+		Entity:    item.Entity,
+		Alias:     item.Alias,
+		DeletedAt: item.DeletedAt,
+		DeletedBy: item.DeletedBy,
+		PurgeAt:   item.DeletedAt.Add(trashRetention),
+	}
+}
+
+// loginUserPermissions returns the function to get the permissions of the login user in the projects,
+// the permissions of each project are loaded once
+func loginUserPermissions(ctx context.Context, store datastore.DataStore, rbacService RBACService) (func(projectName string) ([]*model.Permission, error), error) {
+	userName, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return nil, bcode.ErrUnauthorized
+	}
+	user := &model.User{Name: userName}
+	if err := store.Get(ctx, user); err != nil {
+		return nil, err
+	}
+	cache := map[string][]*model.Permission{}
+	return func(projectName string) ([]*model.Permission, error) {
+		if permissions, ok := cache[projectName]; ok {
+			return permissions, nil
+		}
+		permissions, err := rbacService.GetUserPermissions(ctx, user, projectName, true)
+		if err != nil {
+			return nil, err
+		}
+		cache[projectName] = permissions
+		return permissions, nil
+	}, nil
+}
+
+// ListTrash lists the items in the trash, only the items whose entities the login user could read are returned
+func (t *trashServiceImpl) ListTrash(ctx context.Context, projectName, kind string, page, pageSize int) (*apisv1.ListTrashResponse, error) {
+	permissions, err := loginUserPermissions(ctx, t.Store, t.RbacService)
+	if err != nil {
+		return nil, err
+	}
+	entities, err := t.Store.List(ctx, &model.TrashItem{Project: projectName, Kind: kind}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListTrashResponse{Items: []*apisv1.TrashItemBase{}}
+	var readable []*model.TrashItem
+	for _, entity := range entities {
+		item := entity.(*model.TrashItem)
+		perms, err := permissions(item.Project)
+		if err != nil {
+			return nil, err
+		}
+		if matchPermission(perms, trashResource(item), nil, "detail") {
+			readable = append(readable, item)
+		}
+	}
+	res.Total = int64(len(readable))
+	if pageSize > 0 {
+		start := (page - 1) * pageSize
+		if start < 0 {
+			start = 0
+		}
+		if start > len(readable) {
+			start = len(readable)
+		}
+		end := start + pageSize
+		if end > len(readable) {
+			end = len(readable)
+		}
+		readable = readable[start:end]
+	}
+	for _, item := range readable {
+		res.Items = append(res.Items, convertTrashItem(item))
+	}
+	return res, nil
+}
+
+// RestoreTrash restores the entity of the item, it fails if an entity of the same name has been created since the deletion
+func (t *trashServiceImpl) RestoreTrash(ctx context.Context, itemName string) (*apisv1.TrashItemBase, error) {
+	item := &model.TrashItem{Name: itemName}
+	if err := t.Store.Get(ctx, item); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrTrashItemNotExist
+		}
+		return nil, err
+	}
+	permissions, err := loginUserPermissions(ctx, t.Store, t.RbacService)
+	if err != nil {
+		return nil, err
+	}
+	perms, err := permissions(item.Project)
+	if err != nil {
+		return nil, err
+	}
+	if !matchPermission(perms, trashResource(item), nil, "detail") {
+		return nil, bcode.ErrTrashItemNotExist
+	}
+	if !matchPermission(perms, trashResource(item), nil, "create") {
+		return nil, bcode.ErrForbidden
+	}
+	if item.Project != "" && item.Project != model.RoleScopeCrossProject {
+		if err := t.Store.Get(ctx, &model.Project{Name: item.Project}); err != nil {
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				return nil, bcode.ErrProjectIsNotExist
+			}
+			return nil, err
+		}
+	}
+	var records []datastore.Entity
+	for _, record := range item.Records {
+		entity, err := decodeTrashRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, entity)
+	}
+	if len(records) == 0 {
+		return nil, bcode.ErrTrashItemNotExist
+	}
+	exist, err := t.Store.IsExist(ctx, records[0])
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return nil, bcode.ErrTrashRestoreConflict
+	}
+	if env, ok := records[0].(*model.Env); ok {
+		// the namespace of the environment is labeled and the project is granted again like creating it
+		createNamespaceCtx := apiutils.WithProject(ctx, "")
+		if err := repository.CreateEnv(createNamespaceCtx, t.KubeClient, t.Store, env); err != nil {
+			return nil, err
+		}
+		if err := managePrivilegesForEnvironment(createNamespaceCtx, t.KubeClient, env, false); err != nil {
+			return nil, err
+		}
+	} else if err := t.Store.Transaction(ctx, func(tx datastore.DataStore) error {
+		for _, record := range records {
+			if err := tx.Add(ctx, record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrTrashRestoreConflict
+		}
+		return nil, err
+	}
+	if err := t.Store.Delete(ctx, item); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		klog.Errorf("failed to delete the restored trash item %s: %s", item.Name, err.Error())
+	}
+	klog.Infof("the %s %s is restored from the trash", item.Kind, pkgUtils.Sanitize(item.Entity))
+	return convertTrashItem(item), nil
+}
+
+// decodeTrashRecord decodes the record to the entity of the registered model, the entity is added as a new one
+func decodeTrashRecord(record model.TrashRecord) (datastore.Entity, error) {
+	registered, ok := model.GetRegisterModels()[record.Table].(datastore.Entity)
+	if !ok {
+		return nil, fmt.Errorf("the table %s of the trash record is not registered", record.Table)
+	}
+	entity, err := datastore.NewEntity(registered)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(record.Data, entity); err != nil {
+		return nil, err
+	}
+	entity.SetResourceVersion(0)
+	return entity, nil
+}
+
+// PurgeTrash deletes the items deleted before the retention, all items are deleted if the trash is disabled
+func (t *trashServiceImpl) PurgeTrash(ctx context.Context) error {
+	entities, err := t.Store.List(ctx, &model.TrashItem{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entity := range entities {
+		item := entity.(*model.TrashItem)
+		if now.Sub(item.DeletedAt) <= trashRetention {
+			continue
+		}
+		if err := t.Store.Delete(ctx, item); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to purge the trash item %s: %s", item.Name, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the trash of the deleted entities", func() {
+	var (
+		ds           datastore.DataStore
+		rbacService  *rbacServiceImpl
+		trashService *trashServiceImpl
+		adminCtx     context.Context
+		viewerCtx    context.Context
+	)
+	const projectName = "trash-project"
+
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "trash-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		rbacService = &rbacServiceImpl{Store: ds}
+		trashService = &trashServiceImpl{Store: ds, KubeClient: k8sClient, RbacService: rbacService}
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.Project{Name: projectName})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Permission{Name: "trash-manage", Project: projectName, Resources: []string{"project:trash-project/role:*", "project:trash-project/application:*"}, Actions: []string{"*"}, Effect: "Allow"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Permission{Name: "trash-view", Project: projectName, Resources: []string{"project:trash-project/application:*"}, Actions: []string{"detail", "list"}, Effect: "Allow"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Role{Name: "trash-manager", Project: projectName, Permissions: []string{"trash-manage"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Role{Name: "trash-viewer", Project: projectName, Permissions: []string{"trash-view"}})).Should(BeNil())
+		for _, user := range []string{"trash-admin", "trash-dev"} {
+			Expect(ds.Add(ctx, &model.User{Name: user})).Should(BeNil())
+		}
+		Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: projectName, Username: "trash-admin", UserRoles: []string{"trash-manager"}})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: projectName, Username: "trash-dev", UserRoles: []string{"trash-viewer"}})).Should(BeNil())
+		adminCtx = context.WithValue(ctx, &apisv1.CtxKeyUser, "trash-admin")
+		viewerCtx = context.WithValue(ctx, &apisv1.CtxKeyUser, "trash-dev")
+	})
+
+	It("Test restore the deleted role", func() {
+		Expect(ds.Add(context.TODO(), &model.Role{Name: "deploy-viewer", Alias: "Deploy Viewer", Project: projectName, Permissions: []string{"trash-view"}})).Should(BeNil())
+		Expect(rbacService.DeleteRole(adminCtx, projectName, "deploy-viewer")).Should(BeNil())
+		Expect(ds.IsExist(context.TODO(), &model.Role{Name: "deploy-viewer", Project: projectName})).Should(BeFalse())
+
+		items, err := trashService.ListTrash(adminCtx, projectName, TrashKindRole, 0, 0)
+		Expect(err).Should(BeNil())
+		Expect(items.Total).Should(Equal(int64(1)))
+		item := items.Items[0]
+		Expect(item.Entity).Should(Equal("deploy-viewer"))
+		Expect(item.Alias).Should(Equal("Deploy Viewer"))
+		Expect(item.DeletedBy).Should(Equal("trash-admin"))
+		Expect(item.PurgeAt).Should(Equal(item.DeletedAt.Add(trashRetention)))
+
+		By("the users could not read the deleted role do not see it")
+		items, err = trashService.ListTrash(viewerCtx, projectName, "", 0, 0)
+		Expect(err).Should(BeNil())
+		Expect(items.Items).Should(BeEmpty())
+		_, err = trashService.RestoreTrash(viewerCtx, item.Name)
+		Expect(err).Should(Equal(bcode.ErrTrashItemNotExist))
+
+		By("the role of the same name blocks the restore")
+		Expect(ds.Add(context.TODO(), &model.Role{Name: "deploy-viewer", Project: projectName})).Should(BeNil())
+		_, err = trashService.RestoreTrash(adminCtx, item.Name)
+		Expect(err).Should(Equal(bcode.ErrTrashRestoreConflict))
+		Expect(ds.Delete(context.TODO(), &model.Role{Name: "deploy-viewer", Project: projectName})).Should(BeNil())
+
+		_, err = trashService.RestoreTrash(adminCtx, item.Name)
+		Expect(err).Should(BeNil())
+		role := &model.Role{Name: "deploy-viewer", Project: projectName}
+		Expect(ds.Get(context.TODO(), role)).Should(BeNil())
+		Expect(role.Permissions).Should(Equal([]string{"trash-view"}))
+		_, err = trashService.RestoreTrash(adminCtx, item.Name)
+		Expect(err).Should(Equal(bcode.ErrTrashItemNotExist))
+	})
+
+	It("Test restore the deleted application with its records", func() {
+		ctx := context.TODO()
+		app := &model.Application{Name: "trash-app", Project: projectName}
+		component := &model.ApplicationComponent{AppPrimaryKey: "trash-app", Name: "web", Type: "webservice"}
+		Expect(ds.Add(ctx, app)).Should(BeNil())
+		Expect(ds.Add(ctx, component)).Should(BeNil())
+		item, err := moveToTrash(adminCtx, ds, TrashKindApplication, projectName, "", app, component)
+		Expect(err).Should(BeNil())
+		Expect(ds.Delete(ctx, component)).Should(BeNil())
+		Expect(ds.Delete(ctx, app)).Should(BeNil())
+
+		By("the users could read but not create the application could not restore it")
+		items, err := trashService.ListTrash(viewerCtx, projectName, "", 0, 0)
+		Expect(err).Should(BeNil())
+		Expect(items.Total).Should(Equal(int64(1)))
+		_, err = trashService.RestoreTrash(viewerCtx, item.Name)
+		Expect(err).Should(Equal(bcode.ErrForbidden))
+
+		_, err = trashService.RestoreTrash(adminCtx, item.Name)
+		Expect(err).Should(BeNil())
+		Expect(ds.Get(ctx, &model.Application{Name: "trash-app"})).Should(BeNil())
+		restored := &model.ApplicationComponent{AppPrimaryKey: "trash-app", Name: "web"}
+		Expect(ds.Get(ctx, restored)).Should(BeNil())
+		Expect(restored.Type).Should(Equal("webservice"))
+	})
+
+	It("Test purge the items out of the retention", func() {
+		ctx := context.TODO()
+		kept, err := moveToTrash(adminCtx, ds, TrashKindApplication, projectName, "", &model.Application{Name: "kept-app", Project: projectName})
+		Expect(err).Should(BeNil())
+		expired, err := moveToTrash(adminCtx, ds, TrashKindApplication, projectName, "", &model.Application{Name: "expired-app", Project: projectName})
+		Expect(err).Should(BeNil())
+		expired.DeletedAt = time.Now().Add(-trashRetention - time.Hour)
+		Expect(ds.Put(ctx, expired)).Should(BeNil())
+
+		Expect(trashService.PurgeTrash(ctx)).Should(BeNil())
+		Expect(ds.IsExist(ctx, &model.TrashItem{Name: kept.Name})).Should(BeTrue())
+		Expect(ds.IsExist(ctx, &model.TrashItem{Name: expired.Name})).Should(BeFalse())
+	})
+})
@@ -18,7 +18,10 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 
 	"golang.org/x/crypto/bcrypt"
 	"helm.sh/helm/v3/pkg/time"
@@ -29,6 +32,9 @@ import (
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/email"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/securityevent"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/slack"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
@@ -38,18 +44,29 @@ const (
 	InitAdminPassword = "VelaUX12345"
 )
 
+// randomAdminPassword initializes the admin user with a random password instead of the default one, it is set from the server config
+var randomAdminPassword bool
+
 // UserService User manage api
 type UserService interface {
 	GetUser(ctx context.Context, username string) (*model.User, error)
 	DetailUser(ctx context.Context, user *model.User) (*apisv1.DetailUserResponse, error)
-	DeleteUser(ctx context.Context, username string) error
+	DeleteUser(ctx context.Context, username, transferTo string) error
+	ListOwnedResources(ctx context.Context, username string) (*apisv1.UserOwnedResourcesResponse, error)
 	CreateUser(ctx context.Context, req apisv1.CreateUserRequest) (*apisv1.UserBase, error)
 	UpdateUser(ctx context.Context, user *model.User, req apisv1.UpdateUserRequest) (*apisv1.UserBase, error)
 	ListUsers(ctx context.Context, page, pageSize int, listOptions apisv1.ListUserOptions) (*apisv1.ListUserResponse, error)
 	DisableUser(ctx context.Context, user *model.User) error
 	EnableUser(ctx context.Context, user *model.User) error
+	UnlockUser(ctx context.Context, user *model.User) error
+	ChangePassword(ctx context.Context, req apisv1.ChangePasswordRequest) (*apisv1.UserBase, error)
+	UploadAvatar(ctx context.Context, req apisv1.UploadAvatarRequest) (*apisv1.UserBase, error)
+	DeleteAvatar(ctx context.Context) (*apisv1.UserBase, error)
+	GetAvatar(ctx context.Context, avatarID string) (*model.UserAvatar, error)
 	DetailLoginUserInfo(ctx context.Context) (*apisv1.LoginUserInfoResponse, error)
 	UpdateUserLoginTime(ctx context.Context, user *model.User) error
+	ListLoginHistory(ctx context.Context, user *model.User, page, pageSize int) (*apisv1.ListLoginHistoryResponse, error)
+	ProcessScheduledDeactivations(ctx context.Context) error
 	Init(ctx context.Context) error
 }
 
@@ -59,6 +76,8 @@ type userServiceImpl struct {
 	ProjectService ProjectService      `inject:""`
 	RbacService    RBACService         `inject:""`
 	SysService     SystemInfoService   `inject:""`
+	EmailSender    email.Sender        `inject:"emailSender"`
+	SlackSender    slack.Sender        `inject:"slackSender"`
 }
 
 // NewUserService new User service
@@ -67,33 +86,86 @@ func NewUserService() UserService {
 }
 
 func (u *userServiceImpl) Init(ctx context.Context) error {
-	admin := model.DefaultAdminUserName
-	if err := u.Store.Get(ctx, &model.User{
-		Name: admin,
-	}); err != nil {
+	admin := &model.User{
+		Name: model.DefaultAdminUserName,
+	}
+	if err := u.Store.Get(ctx, admin); err != nil {
 		if errors.Is(err, datastore.ErrRecordNotExist) {
-			encrypted, err := GeneratePasswordHash(InitAdminPassword)
+			if bootstrapTokenEnabled {
+				return u.initBootstrapAdmin(ctx)
+			}
+			password := InitAdminPassword
+			if randomAdminPassword {
+				if password, err = generateInitialPassword(); err != nil {
+					return err
+				}
+			}
+			encrypted, err := GeneratePasswordHash(password)
 			if err != nil {
 				return err
 			}
 			if err := u.Store.Add(ctx, &model.User{
-				Name:      admin,
-				Alias:     model.DefaultAdminUserAlias,
-				Password:  encrypted,
-				UserRoles: []string{"admin"},
+				Name:               model.DefaultAdminUserName,
+				Alias:              model.DefaultAdminUserAlias,
+				Password:           encrypted,
+				UserRoles:          []string{"admin"},
+				MustChangePassword: true,
 			}); err != nil {
 				return err
 			}
-			// print default password of admin user in log
-			klog.Infof("initialized admin username and password: admin / %s", InitAdminPassword)
-		} else {
+			// print the initial password of admin user in log, it must be changed at the first login
+			klog.Infof("initialized admin username and password: admin / %s, the password must be changed at the first login", password)
+			return nil
+		}
+		return err
+	}
+	// the admin user that still uses the default password must change it
+	if !admin.MustChangePassword && admin.Password != "" && compareHashWithPassword(admin.Password, InitAdminPassword) == nil {
+		admin.MustChangePassword = true
+		if err := u.Store.Put(ctx, admin); err != nil {
 			return err
 		}
+		klog.Warning("the admin user uses the default password, it must be changed at the next login")
 	}
 	klog.Info("admin user is exist")
 	return nil
 }
 
+// generateInitialPassword generates the random initial password of the admin user
+func generateInitialPassword() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// initBootstrapAdmin creates the disabled admin user with a random password, it is enabled by the bootstrap token
+func (u *userServiceImpl) initBootstrapAdmin(ctx context.Context) error {
+	password, err := generateSecretToken()
+	if err != nil {
+		return err
+	}
+	encrypted, err := GeneratePasswordHash(password)
+	if err != nil {
+		return err
+	}
+	if err := u.Store.Add(ctx, &model.User{
+		Name:      model.DefaultAdminUserName,
+		Alias:     model.DefaultAdminUserAlias,
+		Password:  encrypted,
+		Disabled:  true,
+		UserRoles: []string{"admin"},
+	}); err != nil {
+		return err
+	}
+	if err := u.Store.Add(ctx, &model.BootstrapToken{Name: bootstrapTokenName}); err != nil && !errors.Is(err, datastore.ErrRecordExist) {
+		return err
+	}
+	klog.Info("initialized the disabled admin user, it waits for the bootstrap token")
+	return nil
+}
+
 // GetUser get user
 func (u *userServiceImpl) GetUser(ctx context.Context, username string) (*model.User, error) {
 	user := &model.User{
@@ -107,11 +179,11 @@ func (u *userServiceImpl) GetUser(ctx context.Context, username string) (*model.
 
 // DetailUser return user detail
 func (u *userServiceImpl) DetailUser(ctx context.Context, user *model.User) (*apisv1.DetailUserResponse, error) {
-	roles, err := u.RbacService.ListRole(ctx, "", 0, 0)
+	roles, err := u.RbacService.ListRole(ctx, "", 0, 0, apisv1.ListRoleOptions{})
 	if err != nil {
 		klog.Warningf("list platform roles failure %s", err.Error())
 	}
-	detailUser := convertUserModel(user, roles)
+	detailUser := convertUserModel(user, roles, useGravatar(ctx, u.SysService))
 	pUser := &model.ProjectUser{
 		Username: user.Name,
 	}
@@ -135,8 +207,23 @@ func (u *userServiceImpl) DetailUser(ctx context.Context, user *model.User) (*ap
 	return detailUser, nil
 }
 
-// DeleteUser delete user
-func (u *userServiceImpl) DeleteUser(ctx context.Context, username string) error {
+// DeleteUser delete user, the resources owned by the user must be transferred to another user first
+func (u *userServiceImpl) DeleteUser(ctx context.Context, username, transferTo string) error {
+	owned, err := u.ListOwnedResources(ctx, username)
+	if err != nil {
+		return err
+	}
+	if owned.Total > 0 {
+		if transferTo == "" {
+			return bcode.ErrUserOwnsResources.SetMessage(fmt.Sprintf("the user owns %d resources, transfer them to another user before deleting", owned.Total))
+		}
+		// the owned resources are transferred together, so a failed transfer leaves all of them to the user
+		if err := datastore.InTransaction(ctx, u.Store, func(ctx context.Context) error {
+			return u.transferOwnedResources(ctx, username, transferTo)
+		}); err != nil {
+			return err
+		}
+	}
 	pUser := &model.ProjectUser{
 		Username: username,
 	}
@@ -145,16 +232,146 @@ func (u *userServiceImpl) DeleteUser(ctx context.Context, username string) error
 	if err != nil {
 		return err
 	}
+	// remove the memberships by the project service, so the Kubernetes privileges are revoked and the events are recorded
 	for _, v := range projectUsers {
 		pu := v.(*model.ProjectUser)
-		if err := u.Store.Delete(ctx, pu); err != nil {
+		err := u.ProjectService.DeleteProjectUser(ctx, pu.ProjectName, username)
+		if errors.Is(err, bcode.ErrProjectIsNotExist) {
+			err = u.Store.Delete(ctx, pu)
+		}
+		if err != nil {
 			klog.Errorf("failed to delete project user %s: %s", pu.PrimaryKey(), err.Error())
 		}
 	}
-	if err := u.Store.Delete(ctx, &model.User{Name: username}); err != nil {
-		klog.Errorf("failed to delete user %s %v", pkgUtils.Sanitize(username), err.Error())
+	// the sessions and the tokens are kept if the user is not deleted
+	return datastore.InTransaction(ctx, u.Store, func(ctx context.Context) error {
+		if err := revokeUserSessions(ctx, u.Store, username, ""); err != nil {
+			return err
+		}
+		tokens, err := u.Store.List(ctx, &model.APIToken{Username: username}, &datastore.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, v := range tokens {
+			token := v.(*model.APIToken)
+			if err := u.Store.Delete(ctx, token); err != nil {
+				klog.Errorf("failed to delete the API token %s: %s", token.Name, err.Error())
+			}
+		}
+		if err := u.Store.Delete(ctx, &model.UserPreference{Username: username}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to delete the preferences of the user %s: %s", pkgUtils.Sanitize(username), err.Error())
+		}
+		deleteUserAvatars(ctx, u.Store, username)
+		deleteLoginHistory(ctx, u.Store, username)
+		if err := u.Store.Delete(ctx, &model.User{Name: username}); err != nil {
+			klog.Errorf("failed to delete user %s %v", pkgUtils.Sanitize(username), err.Error())
+			return err
+		}
+		return nil
+	})
+}
+
+// ListOwnedResources list the projects owned by the user and the components, policies and status badges created by the user
+func (u *userServiceImpl) ListOwnedResources(ctx context.Context, username string) (*apisv1.UserOwnedResourcesResponse, error) {
+	resp := &apisv1.UserOwnedResourcesResponse{Resources: []apisv1.UserOwnedResource{}}
+	projects, err := u.Store.List(ctx, &model.Project{Owner: username}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range projects {
+		project := v.(*model.Project)
+		resp.Resources = append(resp.Resources, apisv1.UserOwnedResource{Type: "project", Name: project.Name, Project: project.Name})
+	}
+	components, err := u.Store.List(ctx, &model.ApplicationComponent{Creator: username}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range components {
+		component := v.(*model.ApplicationComponent)
+		resp.Resources = append(resp.Resources, apisv1.UserOwnedResource{Type: "component", Name: component.Name, Application: component.AppPrimaryKey})
+	}
+	policies, err := u.Store.List(ctx, &model.ApplicationPolicy{Creator: username}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range policies {
+		policy := v.(*model.ApplicationPolicy)
+		resp.Resources = append(resp.Resources, apisv1.UserOwnedResource{Type: "policy", Name: policy.Name, Application: policy.AppPrimaryKey})
+	}
+	badges, err := u.Store.List(ctx, &model.StatusBadge{Creator: username}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range badges {
+		badge := v.(*model.StatusBadge)
+		resp.Resources = append(resp.Resources, apisv1.UserOwnedResource{Type: "statusBadge", Name: badge.ID, Project: badge.Project})
+	}
+	resp.Total = len(resp.Resources)
+	return resp, nil
+}
+
+// transferOwnedResources makes the target user the owner of the projects and the creator of the components, policies and status badges of the user
+func (u *userServiceImpl) transferOwnedResources(ctx context.Context, username, transferTo string) error {
+	if transferTo == username {
+		return bcode.ErrInvalidTransferUser
+	}
+	target, err := u.GetUser(ctx, transferTo)
+	if err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrInvalidTransferUser
+		}
+		return err
+	}
+	if target.Disabled {
+		return bcode.ErrInvalidTransferUser
+	}
+	projects, err := u.Store.List(ctx, &model.Project{Owner: username}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, v := range projects {
+		project := v.(*model.Project)
+		if _, err := u.ProjectService.UpdateProject(ctx, project.Name, apisv1.UpdateProjectRequest{
+			Alias:       project.Alias,
+			Description: project.Description,
+			Owner:       transferTo,
+		}); err != nil {
+			return err
+		}
+	}
+	components, err := u.Store.List(ctx, &model.ApplicationComponent{Creator: username}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, v := range components {
+		component := v.(*model.ApplicationComponent)
+		component.Creator = transferTo
+		if err := u.Store.Put(ctx, component); err != nil {
+			return err
+		}
+	}
+	policies, err := u.Store.List(ctx, &model.ApplicationPolicy{Creator: username}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, v := range policies {
+		policy := v.(*model.ApplicationPolicy)
+		policy.Creator = transferTo
+		if err := u.Store.Put(ctx, policy); err != nil {
+			return err
+		}
+	}
+	badges, err := u.Store.List(ctx, &model.StatusBadge{Creator: username}, &datastore.ListOptions{})
+	if err != nil {
 		return err
 	}
+	for _, v := range badges {
+		badge := v.(*model.StatusBadge)
+		badge.Creator = transferTo
+		if err := u.Store.Put(ctx, badge); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -167,6 +384,9 @@ func (u *userServiceImpl) CreateUser(ctx context.Context, req apisv1.CreateUserR
 	if sysInfo.LoginType == model.LoginTypeDex {
 		return nil, bcode.ErrUserCannotModified
 	}
+	if req.DeactivateAt != nil && !req.DeactivateAt.After(time.Now().Time) {
+		return nil, bcode.ErrInvalidDeactivateTime
+	}
 	hash, err := GeneratePasswordHash(req.Password)
 	if err != nil {
 		return nil, err
@@ -174,17 +394,20 @@ func (u *userServiceImpl) CreateUser(ctx context.Context, req apisv1.CreateUserR
 
 	// TODO: validate the roles, they must be platform roles
 	user := &model.User{
-		Name:      req.Name,
-		Alias:     req.Alias,
-		Email:     req.Email,
-		UserRoles: req.Roles,
-		Password:  hash,
-		Disabled:  false,
+		Name:         req.Name,
+		Alias:        req.Alias,
+		Email:        req.Email,
+		UserRoles:    req.Roles,
+		Password:     hash,
+		Disabled:     false,
+		DeactivateAt: req.DeactivateAt,
+		// the password is set by the administrator, the user must change it at the first login
+		MustChangePassword: req.MustChangePassword == nil || *req.MustChangePassword,
 	}
 	if err := u.Store.Add(ctx, user); err != nil {
 		return nil, err
 	}
-	return convertUserBase(user), nil
+	return convertUserBase(user, useGravatar(ctx, u.SysService)), nil
 }
 
 // UpdateUser update user
@@ -196,17 +419,37 @@ func (u *userServiceImpl) UpdateUser(ctx context.Context, user *model.User, req
 	if req.Alias != "" {
 		user.Alias = req.Alias
 	}
+	switch {
+	case req.CancelDeactivation:
+		user.DeactivateAt = nil
+		user.DeactivationNotified = false
+	case req.DeactivateAt != nil:
+		if !req.DeactivateAt.After(time.Now().Time) {
+			return nil, bcode.ErrInvalidDeactivateTime
+		}
+		if user.DeactivateAt == nil || !user.DeactivateAt.Equal(*req.DeactivateAt) {
+			// the notice is sent again for the new time
+			user.DeactivationNotified = false
+		}
+		user.DeactivateAt = req.DeactivateAt
+	}
+	var passwordChanged bool
 	if sysInfo.LoginType != model.LoginTypeDex {
 		if req.Password != "" {
+			if user.MustChangePassword && compareHashWithPassword(user.Password, req.Password) == nil {
+				return nil, bcode.ErrPasswordNotChanged
+			}
 			hash, err := GeneratePasswordHash(req.Password)
 			if err != nil {
 				return nil, err
 			}
 			user.Password = hash
+			user.MustChangePassword = req.MustChangePassword
+			passwordChanged = true
 		}
 	}
 	if req.Email != "" {
-		if user.Email != "" {
+		if user.Email != "" && user.Email != req.Email {
 			return nil, bcode.ErrUnsupportedEmailModification
 		}
 		user.Email = req.Email
@@ -219,6 +462,14 @@ func (u *userServiceImpl) UpdateUser(ctx context.Context, user *model.User, req
 	if err := u.Store.Put(ctx, user); err != nil {
 		return nil, err
 	}
+	if passwordChanged {
+		emitSecurityEvent(ctx, securityevent.Event{Type: securityevent.TypePasswordChange, Outcome: securityevent.OutcomeSuccess, Username: user.Name})
+		// the other sessions are signed out, the user changing the password keeps the current session
+		current, _ := ctx.Value(&apisv1.CtxKeySession).(string)
+		if err := revokeUserSessions(ctx, u.Store, user.Name, current); err != nil {
+			klog.Errorf("failed to revoke the sessions of the user %s: %s", user.Name, err.Error())
+		}
+	}
 	if user.Name == model.DefaultAdminUserName {
 		if err := generateDexConfig(ctx, u.K8sClient, &model.UpdateDexConfig{
 			StaticPasswords: []model.StaticPassword{
@@ -232,7 +483,7 @@ func (u *userServiceImpl) UpdateUser(ctx context.Context, user *model.User, req
 			return nil, err
 		}
 	}
-	return convertUserBase(user), nil
+	return convertUserBase(user, useGravatar(ctx, u.SysService)), nil
 }
 
 // ListUsers list users
@@ -260,14 +511,15 @@ func (u *userServiceImpl) ListUsers(ctx context.Context, page, pageSize int, lis
 	if err != nil {
 		return nil, err
 	}
-	roles, err := u.RbacService.ListRole(ctx, "", 0, 0)
+	roles, err := u.RbacService.ListRole(ctx, "", 0, 0, apisv1.ListRoleOptions{})
 	if err != nil {
 		klog.Warningf("list platform roles failure %s", err.Error())
 	}
+	gravatar := useGravatar(ctx, u.SysService)
 	for _, v := range users {
 		user, ok := v.(*model.User)
 		if ok {
-			userList = append(userList, convertUserModel(user, roles))
+			userList = append(userList, convertUserModel(user, roles, gravatar))
 		}
 	}
 	count, err := u.Store.Count(ctx, user, &fo)
@@ -287,7 +539,10 @@ func (u *userServiceImpl) DisableUser(ctx context.Context, user *model.User) err
 		return bcode.ErrUserAlreadyDisabled
 	}
 	user.Disabled = true
-	return u.Store.Put(ctx, user)
+	if err := u.Store.Put(ctx, user); err != nil {
+		return err
+	}
+	return revokeUserSessions(ctx, u.Store, user.Name, "")
 }
 
 // EnableUser disable user
@@ -296,9 +551,51 @@ func (u *userServiceImpl) EnableUser(ctx context.Context, user *model.User) erro
 		return bcode.ErrUserAlreadyEnabled
 	}
 	user.Disabled = false
+	if user.DeactivateAt != nil && !user.DeactivateAt.After(time.Now().Time) {
+		// the passed deactivation would disable the user again
+		user.DeactivateAt = nil
+		user.DeactivationNotified = false
+	}
 	return u.Store.Put(ctx, user)
 }
 
+// UnlockUser clears the failed login attempts of the user, the locked client IPs are not unlocked
+func (u *userServiceImpl) UnlockUser(ctx context.Context, user *model.User) error {
+	if err := resetLoginFailures(ctx, u.Store, userLoginAttemptKey(user.Name)); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrUserNotLocked
+		}
+		return err
+	}
+	return nil
+}
+
+// ChangePassword changes the password of the login user after checking the current password
+func (u *userServiceImpl) ChangePassword(ctx context.Context, req apisv1.ChangePasswordRequest) (*apisv1.UserBase, error) {
+	userName, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return nil, bcode.ErrUnauthorized
+	}
+	sysInfo, err := u.SysService.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if sysInfo.LoginType == model.LoginTypeDex {
+		return nil, bcode.ErrUserCannotModified
+	}
+	user, err := u.GetUser(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+	if err := compareHashWithPassword(user.Password, req.OldPassword); err != nil {
+		return nil, bcode.ErrUserInconsistentPassword
+	}
+	if req.NewPassword == req.OldPassword {
+		return nil, bcode.ErrPasswordNotChanged
+	}
+	return u.UpdateUser(ctx, user, apisv1.UpdateUserRequest{Password: req.NewPassword})
+}
+
 // UpdateUserLoginTime update user login time
 func (u *userServiceImpl) UpdateUserLoginTime(ctx context.Context, user *model.User) error {
 	user.LastLoginTime = time.Now().Time
@@ -359,14 +656,14 @@ func (u *userServiceImpl) DetailLoginUserInfo(ctx context.Context) (*apisv1.Logi
 		})
 	}
 	return &apisv1.LoginUserInfoResponse{
-		UserBase:            *convertUserBase(user),
+		UserBase:            *convertUserBase(user, useGravatar(ctx, u.SysService)),
 		Projects:            projects,
 		ProjectPermissions:  projectPermissions,
 		PlatformPermissions: platformPermissions,
 	}, nil
 }
 
-func convertUserModel(user *model.User, roles *apisv1.ListRolesResponse) *apisv1.DetailUserResponse {
+func convertUserModel(user *model.User, roles *apisv1.ListRolesResponse, gravatar bool) *apisv1.DetailUserResponse {
 
 	var nameAlias = make(map[string]string)
 	if roles != nil {
@@ -375,7 +672,7 @@ func convertUserModel(user *model.User, roles *apisv1.ListRolesResponse) *apisv1
 		}
 	}
 	return &apisv1.DetailUserResponse{
-		UserBase: *convertUserBase(user),
+		UserBase: *convertUserBase(user, gravatar),
 		Roles: func() (list []apisv1.NameAlias) {
 			for _, r := range user.UserRoles {
 				list = append(list, apisv1.NameAlias{Name: r, Alias: nameAlias[r]})
@@ -386,14 +683,19 @@ func convertUserModel(user *model.User, roles *apisv1.ListRolesResponse) *apisv1
 	}
 }
 
-func convertUserBase(user *model.User) *apisv1.UserBase {
+func convertUserBase(user *model.User, gravatar bool) *apisv1.UserBase {
 	return &apisv1.UserBase{
-		Name:          user.Name,
-		Alias:         user.Alias,
-		Email:         user.Email,
-		CreateTime:    user.CreateTime,
-		LastLoginTime: user.LastLoginTime,
-		Disabled:      user.Disabled,
+		Name:               user.Name,
+		Alias:              user.Alias,
+		Email:              user.Email,
+		CreateTime:         user.CreateTime,
+		LastLoginTime:      user.LastLoginTime,
+		Disabled:           user.Disabled,
+		DeactivateAt:       user.DeactivateAt,
+		MustChangePassword: user.MustChangePassword,
+		ConsentVersion:     user.ConsentVersion,
+		ConsentTime:        user.ConsentTime,
+		AvatarURL:          userAvatarURL(user, gravatar),
 	}
 }
 
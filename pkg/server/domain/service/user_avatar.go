@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	pkgUtils "github.com/oam-dev/kubevela/pkg/utils"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// avatarPathPrefix the path of the avatar images, the IDs are random so the images could be loaded without the login
+	avatarPathPrefix = "/api/v1/users/avatars/"
+	maxAvatarSize    = 256 * 1024
+	gravatarURL      = "https://www.gravatar.com/avatar/"
+)
+
+// gravatarEnabled the Gravatar of the email is the fallback avatar, it is set by the server config
+var gravatarEnabled = true
+
+// avatarContentTypes the image types accepted as the avatars, they are detected from the content instead of trusting the client
+var avatarContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// useGravatar returns true if the Gravatar is the fallback avatar, it is not used in the offline mode
+// so no URL of the external service is returned
+func useGravatar(ctx context.Context, sysService SystemInfoService) bool {
+	return gravatarEnabled && !isOfflineMode(ctx, sysService)
+}
+
+// userAvatarURL returns the uploaded avatar, or the Gravatar of the email if the user has not uploaded one and gravatar is true
+func userAvatarURL(user *model.User, gravatar bool) string {
+	if user.AvatarID != "" {
+		return avatarPathPrefix + user.AvatarID
+	}
+	email := strings.ToLower(strings.TrimSpace(user.Email))
+	if !gravatar || email == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(email))
+	// the identicon is returned by Gravatar if the email is not registered
+	return gravatarURL + hex.EncodeToString(sum[:]) + "?d=identicon"
+}
+
+// UploadAvatar replaces the avatar of the login user
+func (u *userServiceImpl) UploadAvatar(ctx context.Context, req apisv1.UploadAvatarRequest) (*apisv1.UserBase, error) {
+	userName, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return nil, bcode.ErrUnauthorized
+	}
+	if len(req.Image) > maxAvatarSize {
+		return nil, bcode.ErrAvatarTooLarge
+	}
+	contentType := http.DetectContentType(req.Image)
+	if !avatarContentTypes[contentType] {
+		return nil, bcode.ErrInvalidAvatar
+	}
+	user, err := u.GetUser(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+	id, err := generateSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	avatar := &model.UserAvatar{ID: id[:24], Username: user.Name, ContentType: contentType, Data: req.Image}
+	if err := u.Store.Add(ctx, avatar); err != nil {
+		return nil, err
+	}
+	previous := user.AvatarID
+	user.AvatarID = avatar.ID
+	if err := u.Store.Put(ctx, user); err != nil {
+		return nil, err
+	}
+	if previous != "" {
+		if err := u.Store.Delete(ctx, &model.UserAvatar{ID: previous}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Warningf("failed to delete the previous avatar of the user %s: %s", pkgUtils.Sanitize(user.Name), err.Error())
+		}
+	}
+	return convertUserBase(user, useGravatar(ctx, u.SysService)), nil
+}
+
+// DeleteAvatar deletes the uploaded avatar of the login user, the Gravatar is used again
+func (u *userServiceImpl) DeleteAvatar(ctx context.Context) (*apisv1.UserBase, error) {
+	userName, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return nil, bcode.ErrUnauthorized
+	}
+	user, err := u.GetUser(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+	if user.AvatarID == "" {
+		return convertUserBase(user, useGravatar(ctx, u.SysService)), nil
+	}
+	if err := u.Store.Delete(ctx, &model.UserAvatar{ID: user.AvatarID}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	user.AvatarID = ""
+	if err := u.Store.Put(ctx, user); err != nil {
+		return nil, err
+	}
+	return convertUserBase(user, useGravatar(ctx, u.SysService)), nil
+}
+
+// GetAvatar gets the avatar image by the ID
+func (u *userServiceImpl) GetAvatar(ctx context.Context, avatarID string) (*model.UserAvatar, error) {
+	if avatarID == "" {
+		return nil, bcode.ErrAvatarNotExist
+	}
+	avatar := &model.UserAvatar{ID: avatarID}
+	if err := u.Store.Get(ctx, avatar); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrAvatarNotExist
+		}
+		return nil, err
+	}
+	return avatar, nil
+}
+
+// deleteUserAvatars deletes the avatars of the deleted user
+func deleteUserAvatars(ctx context.Context, store datastore.DataStore, username string) {
+	avatars, err := store.List(ctx, &model.UserAvatar{Username: username}, &datastore.ListOptions{})
+	if err != nil {
+		klog.Errorf("failed to list the avatars of the user %s: %s", pkgUtils.Sanitize(username), err.Error())
+		return
+	}
+	for _, entity := range avatars {
+		if err := store.Delete(ctx, entity); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to delete the avatar of the user %s: %s", pkgUtils.Sanitize(username), err.Error())
+		}
+	}
+}
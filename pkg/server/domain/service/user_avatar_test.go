@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the user avatars", func() {
+	var (
+		ds          datastore.DataStore
+		userService *userServiceImpl
+	)
+	// the minimal PNG signature detected as image/png
+	pngImage := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 32)...)
+
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "user-avatar-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		userService = &userServiceImpl{Store: ds}
+		gravatarEnabled = true
+	})
+
+	It("Test upload, get and delete the avatar", func() {
+		ctx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "avatar-user")
+		Expect(ds.Add(ctx, &model.User{Name: "avatar-user", Email: " Avatar@Example.com"})).Should(BeNil())
+
+		_, err := userService.UploadAvatar(ctx, apisv1.UploadAvatarRequest{Image: []byte("<svg></svg>")})
+		Expect(err).Should(Equal(bcode.ErrInvalidAvatar))
+		_, err = userService.UploadAvatar(ctx, apisv1.UploadAvatarRequest{Image: append(pngImage, make([]byte, maxAvatarSize)...)})
+		Expect(err).Should(Equal(bcode.ErrAvatarTooLarge))
+
+		base, err := userService.UploadAvatar(ctx, apisv1.UploadAvatarRequest{Image: pngImage})
+		Expect(err).Should(BeNil())
+		Expect(base.AvatarURL).Should(HavePrefix(avatarPathPrefix))
+		first := base.AvatarURL[len(avatarPathPrefix):]
+		avatar, err := userService.GetAvatar(ctx, first)
+		Expect(err).Should(BeNil())
+		Expect(avatar.ContentType).Should(Equal("image/png"))
+		Expect(avatar.Data).Should(Equal(pngImage))
+
+		By("the previous avatar is deleted after uploading a new one")
+		base, err = userService.UploadAvatar(ctx, apisv1.UploadAvatarRequest{Image: pngImage})
+		Expect(err).Should(BeNil())
+		Expect(base.AvatarURL).ShouldNot(Equal(avatarPathPrefix + first))
+		_, err = userService.GetAvatar(ctx, first)
+		Expect(err).Should(Equal(bcode.ErrAvatarNotExist))
+
+		By("the Gravatar is used after deleting the avatar")
+		base, err = userService.DeleteAvatar(ctx)
+		Expect(err).Should(BeNil())
+		Expect(base.AvatarURL).Should(Equal(userAvatarURL(&model.User{Email: "avatar@example.com"}, true)))
+		Expect(base.AvatarURL).Should(HavePrefix(gravatarURL))
+		count, err := ds.Count(ctx, &model.UserAvatar{Username: "avatar-user"}, nil)
+		Expect(err).Should(BeNil())
+		Expect(count).Should(Equal(int64(0)))
+	})
+
+	It("Test the Gravatar fallback", func() {
+		Expect(userAvatarURL(&model.User{Name: "no-email"}, true)).Should(BeEmpty())
+		gravatarEnabled = false
+		defer func() { gravatarEnabled = true }()
+		Expect(useGravatar(context.TODO(), nil)).Should(BeFalse())
+		Expect(userAvatarURL(&model.User{Name: "dev", Email: "dev@example.com"}, false)).Should(BeEmpty())
+		Expect(userAvatarURL(&model.User{Name: "dev", Email: "dev@example.com", AvatarID: "abc"}, false)).Should(Equal(avatarPathPrefix + "abc"))
+	})
+
+	It("Test no Gravatar in the offline mode", func() {
+		ctx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "offline-user")
+		Expect(ds.Add(ctx, &model.User{Name: "offline-user", Email: "offline@example.com"})).Should(BeNil())
+		sysService := systemInfoServiceImpl{Store: ds}
+		userService.SysService = sysService
+		Expect(useGravatar(ctx, sysService)).Should(BeTrue())
+
+		info, err := sysService.Get(ctx)
+		Expect(err).Should(BeNil())
+		_, err = sysService.UpdateSystemInfo(ctx, apisv1.SystemInfoRequest{LoginType: info.LoginType, OfflineMode: pointer.BoolPtr(true)})
+		Expect(err).Should(BeNil())
+		Expect(useGravatar(ctx, sysService)).Should(BeFalse())
+		base, err := userService.DeleteAvatar(ctx)
+		Expect(err).Should(BeNil())
+		Expect(base.AvatarURL).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// userDeactivationNotice how long before the scheduled deactivation the user and the project admins are notified
+var userDeactivationNotice = time.Hour * 72
+
+// projectAdminRole the project role whose users are notified of the deactivation of the project members
+const projectAdminRole = "project-admin"
+
+// ProcessScheduledDeactivations disables the users whose deactivation time passed,
+// and notifies the users to be deactivated soon and the admins of their projects.
+func (u *userServiceImpl) ProcessScheduledDeactivations(ctx context.Context) error {
+	users, err := u.Store.List(ctx, &model.User{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entity := range users {
+		user := entity.(*model.User)
+		if user.Disabled || user.DeactivateAt == nil {
+			continue
+		}
+		if !now.Before(*user.DeactivateAt) {
+			if err := u.DisableUser(ctx, user); err != nil {
+				klog.Errorf("failed to deactivate the user %s: %s", user.Name, err.Error())
+				continue
+			}
+			klog.Infof("the user %s is deactivated as scheduled at %s", user.Name, user.DeactivateAt.Format(time.RFC3339))
+			continue
+		}
+		if user.DeactivationNotified || userDeactivationNotice <= 0 || user.DeactivateAt.Sub(now) > userDeactivationNotice {
+			continue
+		}
+		u.notifyDeactivation(ctx, user)
+		user.DeactivationNotified = true
+		if err := u.Store.Put(ctx, user); err != nil {
+			klog.Errorf("failed to mark the deactivation notice of the user %s: %s", user.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// notifyDeactivation notifies the user and the admins of the user's projects of the upcoming deactivation
+func (u *userServiceImpl) notifyDeactivation(ctx context.Context, user *model.User) {
+	at := user.DeactivateAt.Format(time.RFC3339)
+	notifyUser(ctx, u.Store, u.SysService, u.EmailSender, u.SlackSender, user.Name, userNotification{
+		Event:    model.NotificationEventUserDeactivation,
+		Severity: model.NotificationSeverityWarning,
+		Subject:  "Your VelaUX account will be deactivated",
+		Body:     fmt.Sprintf("Your VelaUX account %s will be deactivated at %s. Please contact the administrator if you still need the access.\n", user.Name, at),
+	})
+	admins, err := u.listProjectAdmins(ctx, user.Name)
+	if err != nil {
+		klog.Errorf("failed to list the project admins of the user %s: %s", user.Name, err.Error())
+		return
+	}
+	for _, admin := range admins {
+		notifyUser(ctx, u.Store, u.SysService, u.EmailSender, u.SlackSender, admin, userNotification{
+			Event:    model.NotificationEventUserDeactivation,
+			Severity: model.NotificationSeverityWarning,
+			Subject:  fmt.Sprintf("The user %s will be deactivated", user.Name),
+			Body:     fmt.Sprintf("The user %s, a member of your projects, will be deactivated at %s.\n", user.Name, at),
+		})
+	}
+}
+
+// listProjectAdmins lists the owners and the project admins of the projects the user belongs to, excluding the user
+func (u *userServiceImpl) listProjectAdmins(ctx context.Context, username string) ([]string, error) {
+	memberships, err := u.Store.List(ctx, &model.ProjectUser{Username: username}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	admins := map[string]struct{}{}
+	for _, entity := range memberships {
+		projectName := entity.(*model.ProjectUser).ProjectName
+		project := &model.Project{Name: projectName}
+		if err := u.Store.Get(ctx, project); err == nil && project.Owner != "" {
+			admins[project.Owner] = struct{}{}
+		}
+		projectUsers, err := u.Store.List(ctx, &model.ProjectUser{ProjectName: projectName}, &datastore.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, pu := range projectUsers {
+			projectUser := pu.(*model.ProjectUser)
+			for _, role := range projectUser.UserRoles {
+				if role == projectAdminRole {
+					admins[projectUser.Username] = struct{}{}
+				}
+			}
+		}
+	}
+	delete(admins, username)
+	var names []string
+	for name := range admins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+var _ = Describe("Test the scheduled user deactivation", func() {
+	var (
+		ds          datastore.DataStore
+		sender      *fakeEmailSender
+		userService *userServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "user-deactivation-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		sender = &fakeEmailSender{}
+		userService = &userServiceImpl{Store: ds, EmailSender: sender}
+	})
+
+	It("Test notify and deactivate the user", func() {
+		ctx := context.TODO()
+		deactivateAt := time.Now().Add(time.Hour)
+		Expect(ds.Add(ctx, &model.User{Name: "contractor", Email: "contractor@example.com", DeactivateAt: &deactivateAt})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.User{Name: "contract-owner", Email: "owner@example.com"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Project{Name: "contract-project", Owner: "contract-owner"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: "contract-project", Username: "contractor", UserRoles: []string{"app-developer"}})).Should(BeNil())
+
+		Expect(userService.ProcessScheduledDeactivations(ctx)).Should(BeNil())
+		Expect(sender.to).Should(Equal("owner@example.com"))
+		Expect(sender.body).Should(ContainSubstring("contractor"))
+		user := &model.User{Name: "contractor"}
+		Expect(ds.Get(ctx, user)).Should(BeNil())
+		Expect(user.DeactivationNotified).Should(BeTrue())
+		Expect(user.Disabled).Should(BeFalse())
+
+		By("the notice is sent only once")
+		sender.to = ""
+		Expect(userService.ProcessScheduledDeactivations(ctx)).Should(BeNil())
+		Expect(sender.to).Should(BeEmpty())
+
+		passed := time.Now().Add(-time.Minute)
+		user.DeactivateAt = &passed
+		Expect(ds.Put(ctx, user)).Should(BeNil())
+		Expect(userService.ProcessScheduledDeactivations(ctx)).Should(BeNil())
+		Expect(ds.Get(ctx, user)).Should(BeNil())
+		Expect(user.Disabled).Should(BeTrue())
+
+		By("enabling the user clears the passed deactivation")
+		Expect(userService.EnableUser(ctx, user)).Should(BeNil())
+		Expect(user.DeactivateAt).Should(BeNil())
+	})
+})
@@ -0,0 +1,286 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/email"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// defaultInvitationExpireDays how many days the invitation is valid if it is not specified
+const defaultInvitationExpireDays = 7
+
+// UserInvitationService invites the new users by the link sent to their email
+type UserInvitationService interface {
+	CreateInvitation(ctx context.Context, req apisv1.CreateUserInvitationRequest) (*apisv1.UserInvitationBase, error)
+	ListInvitations(ctx context.Context) (*apisv1.ListUserInvitationsResponse, error)
+	DeleteInvitation(ctx context.Context, id string) error
+	GetInvitation(ctx context.Context, id, token string) (*apisv1.UserInvitationBase, error)
+	AcceptInvitation(ctx context.Context, id string, req apisv1.AcceptUserInvitationRequest) (*apisv1.UserBase, error)
+}
+
+type userInvitationServiceImpl struct {
+	Store          datastore.DataStore `inject:"datastore"`
+	SysService     SystemInfoService   `inject:""`
+	UserService    UserService         `inject:""`
+	ProjectService ProjectService      `inject:""`
+	EmailSender    email.Sender        `inject:"emailSender"`
+}
+
+// NewUserInvitationService new user invitation service
+func NewUserInvitationService() UserInvitationService {
+	return &userInvitationServiceImpl{}
+}
+
+// CreateInvitation checks the roles and sends the invite link to the email, the previous invitation of the email is replaced.
+// The invite link points to the VelaUX address configured in the system info, it is never built from the request headers.
+func (u *userInvitationServiceImpl) CreateInvitation(ctx context.Context, req apisv1.CreateUserInvitationRequest) (*apisv1.UserInvitationBase, error) {
+	if err := u.checkLocalLogin(ctx); err != nil {
+		return nil, err
+	}
+	if !u.EmailSender.Enabled() {
+		return nil, bcode.ErrInvitationDisabled
+	}
+	info, err := u.SysService.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if info.VelaAddress == "" {
+		return nil, bcode.ErrInvitationAddressRequired
+	}
+	users, err := u.Store.List(ctx, &model.User{Email: req.Email}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) > 0 {
+		return nil, bcode.ErrInvitationUserExist
+	}
+	for _, role := range req.PlatformRoles {
+		if err := u.Store.Get(ctx, &model.Role{Name: role}); err != nil {
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				return nil, bcode.ErrRoleIsNotExist
+			}
+			return nil, err
+		}
+	}
+	for _, projectRoles := range req.ProjectRoles {
+		project, err := u.ProjectService.GetProject(ctx, projectRoles.Project)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkProjectRoles(ctx, u.Store, project, projectRoles.Roles); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.deleteInvitationsOfEmail(ctx, req.Email); err != nil {
+		return nil, err
+	}
+
+	token, err := generateSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	id, err := generateSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	expireDays := req.ExpireDays
+	if expireDays == 0 {
+		expireDays = defaultInvitationExpireDays
+	}
+	inviter, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	invitation := &model.UserInvitation{
+		ID:            id[:16],
+		Email:         req.Email,
+		Alias:         req.Alias,
+		PlatformRoles: req.PlatformRoles,
+		ProjectRoles:  req.ProjectRoles,
+		TokenHash:     hashSecretToken(token),
+		ExpireTime:    time.Now().AddDate(0, 0, expireDays),
+		Inviter:       inviter,
+	}
+	// the datastore deletes the invitation after it expires
+	invitation.SetExpireAt(invitation.ExpireTime)
+	if err := u.Store.Add(ctx, invitation); err != nil {
+		return nil, err
+	}
+	link := fmt.Sprintf("%s/invitation?id=%s&token=%s", info.VelaAddress, url.QueryEscape(invitation.ID), url.QueryEscape(token))
+	body := fmt.Sprintf("Hi,\n\n%s invited you to VelaUX. Open the link below to set your username and password, it expires in %d days.\n\n%s\n\nIf you do not expect the invitation, please ignore this email.\n",
+		inviter, expireDays, link)
+	if err := u.EmailSender.Send(ctx, invitation.Email, "You are invited to VelaUX", body); err != nil {
+		klog.Errorf("failed to send the invitation email to %s: %s", invitation.Email, err.Error())
+		if err := u.Store.Delete(ctx, invitation); err != nil {
+			klog.Warningf("failed to delete the invitation that is not sent: %s", err.Error())
+		}
+		return nil, bcode.ErrInvitationEmailFailure
+	}
+	return convertInvitation2Base(invitation), nil
+}
+
+// ListInvitations lists the pending invitations, the expired ones are skipped until the datastore deletes them
+func (u *userInvitationServiceImpl) ListInvitations(ctx context.Context) (*apisv1.ListUserInvitationsResponse, error) {
+	entities, err := u.Store.List(ctx, &model.UserInvitation{}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListUserInvitationsResponse{Invitations: []*apisv1.UserInvitationBase{}}
+	for _, entity := range entities {
+		invitation := entity.(*model.UserInvitation)
+		if time.Now().After(invitation.ExpireTime) {
+			continue
+		}
+		res.Invitations = append(res.Invitations, convertInvitation2Base(invitation))
+	}
+	return res, nil
+}
+
+// DeleteInvitation revokes the invitation, the invite link could not be used anymore
+func (u *userInvitationServiceImpl) DeleteInvitation(ctx context.Context, id string) error {
+	if err := u.Store.Delete(ctx, &model.UserInvitation{ID: id}); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrInvitationNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+// GetInvitation returns the invitation if the token is valid, it shows the invitee the roles to be granted
+func (u *userInvitationServiceImpl) GetInvitation(ctx context.Context, id, token string) (*apisv1.UserInvitationBase, error) {
+	invitation, err := u.checkInvitation(ctx, id, token)
+	if err != nil {
+		return nil, err
+	}
+	return convertInvitation2Base(invitation), nil
+}
+
+// AcceptInvitation creates the user with the invited email and grants the roles, the invitation could only be used once
+func (u *userInvitationServiceImpl) AcceptInvitation(ctx context.Context, id string, req apisv1.AcceptUserInvitationRequest) (*apisv1.UserBase, error) {
+	if err := u.checkLocalLogin(ctx); err != nil {
+		return nil, err
+	}
+	invitation, err := u.checkInvitation(ctx, id, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := u.UserService.GetUser(ctx, req.Name); err == nil {
+		return nil, bcode.ErrInvitationUserExist
+	} else if !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	alias := req.Alias
+	if alias == "" {
+		alias = invitation.Alias
+	}
+	// the invitation is used up only if the user is created
+	var user *apisv1.UserBase
+	if err := datastore.InTransaction(ctx, u.Store, func(ctx context.Context) error {
+		created, err := u.UserService.CreateUser(ctx, apisv1.CreateUserRequest{
+			Name:     req.Name,
+			Alias:    alias,
+			Email:    invitation.Email,
+			Password: req.Password,
+			Roles:    invitation.PlatformRoles,
+		})
+		if err != nil {
+			return err
+		}
+		user = created
+		return u.Store.Delete(ctx, invitation)
+	}); err != nil {
+		return nil, err
+	}
+	// the inviter is recorded as the operator of the project member events
+	inviterCtx := context.WithValue(ctx, &apisv1.CtxKeyUser, invitation.Inviter)
+	for _, projectRoles := range invitation.ProjectRoles {
+		if _, err := u.ProjectService.AddProjectUser(inviterCtx, projectRoles.Project, apisv1.AddProjectUserRequest{
+			UserName:  user.Name,
+			UserRoles: projectRoles.Roles,
+		}); err != nil {
+			klog.Warningf("failed to add the invited user %s to the project %s: %s", user.Name, projectRoles.Project, err.Error())
+		}
+	}
+	return user, nil
+}
+
+func (u *userInvitationServiceImpl) checkInvitation(ctx context.Context, id, token string) (*model.UserInvitation, error) {
+	invitation := &model.UserInvitation{ID: id}
+	if err := u.Store.Get(ctx, invitation); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrInvitationInvalid
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(invitation.TokenHash), []byte(hashSecretToken(token))) != 1 {
+		return nil, bcode.ErrInvitationInvalid
+	}
+	if time.Now().After(invitation.ExpireTime) {
+		return nil, bcode.ErrInvitationExpired
+	}
+	return invitation, nil
+}
+
+func (u *userInvitationServiceImpl) deleteInvitationsOfEmail(ctx context.Context, email string) error {
+	invitations, err := u.Store.List(ctx, &model.UserInvitation{Email: email}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, invitation := range invitations {
+		if err := u.Store.Delete(ctx, invitation); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *userInvitationServiceImpl) checkLocalLogin(ctx context.Context) error {
+	sysInfo, err := u.SysService.Get(ctx)
+	if err != nil {
+		return err
+	}
+	if sysInfo.LoginType == model.LoginTypeDex {
+		return bcode.ErrUnsupportedLoginType
+	}
+	return nil
+}
+
+func convertInvitation2Base(invitation *model.UserInvitation) *apisv1.UserInvitationBase {
+	return &apisv1.UserInvitationBase{
+		ID:            invitation.ID,
+		Email:         invitation.Email,
+		Alias:         invitation.Alias,
+		PlatformRoles: invitation.PlatformRoles,
+		ProjectRoles:  invitation.ProjectRoles,
+		Inviter:       invitation.Inviter,
+		CreateTime:    invitation.CreateTime,
+		ExpireTime:    invitation.ExpireTime,
+	}
+}
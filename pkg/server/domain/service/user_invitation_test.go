@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the user invitation", func() {
+	var (
+		ds                datastore.DataStore
+		sender            *fakeEmailSender
+		invitationService *userInvitationServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "invitation-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		sender = &fakeEmailSender{}
+		sysService := &systemInfoServiceImpl{Store: ds}
+		projectService := NewTestProjectService(ds, k8sClient)
+		invitationService = &userInvitationServiceImpl{
+			Store:          ds,
+			SysService:     sysService,
+			UserService:    &userServiceImpl{Store: ds, SysService: sysService},
+			ProjectService: projectService,
+			EmailSender:    sender,
+		}
+		Expect(ds.Add(context.TODO(), &model.Role{Name: "invite-platform-role"})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Project{Name: "invite-project"})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.Role{Name: "invite-project-role", Project: "invite-project"})).Should(BeNil())
+		info, err := sysService.Get(context.TODO())
+		Expect(err).Should(BeNil())
+		info.VelaAddress = "https://velaux.example.com"
+		Expect(ds.Put(context.TODO(), info)).Should(BeNil())
+	})
+
+	It("Test invite a user and accept the invitation", func() {
+		ctx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, "admin")
+		By("the invite link is not built from the request")
+		info, err := invitationService.SysService.Get(ctx)
+		Expect(err).Should(BeNil())
+		info.VelaAddress = ""
+		Expect(ds.Put(ctx, info)).Should(BeNil())
+		_, err = invitationService.CreateInvitation(ctx, apisv1.CreateUserInvitationRequest{Email: "invitee@example.com"})
+		Expect(err).Should(Equal(bcode.ErrInvitationAddressRequired))
+		info.VelaAddress = "https://velaux.example.com"
+		Expect(ds.Put(ctx, info)).Should(BeNil())
+
+		_, err = invitationService.CreateInvitation(ctx, apisv1.CreateUserInvitationRequest{
+			Email:         "invitee@example.com",
+			PlatformRoles: []string{"not-exist"},
+		})
+		Expect(err).Should(Equal(bcode.ErrRoleIsNotExist))
+
+		invitation, err := invitationService.CreateInvitation(ctx, apisv1.CreateUserInvitationRequest{
+			Email:         "invitee@example.com",
+			Alias:         "Invitee",
+			PlatformRoles: []string{"invite-platform-role"},
+			ProjectRoles:  []model.InvitationProjectRoles{{Project: "invite-project", Roles: []string{"invite-project-role"}}},
+		})
+		Expect(err).Should(BeNil())
+		Expect(invitation.Inviter).Should(Equal("admin"))
+		Expect(sender.to).Should(Equal("invitee@example.com"))
+		Expect(sender.body).Should(ContainSubstring("https://velaux.example.com/invitation?id=" + invitation.ID + "&token="))
+		token := regexp.MustCompile(`token=([0-9a-f]{64})`).FindStringSubmatch(sender.body)[1]
+
+		_, err = invitationService.GetInvitation(context.TODO(), invitation.ID, "invalid")
+		Expect(err).Should(Equal(bcode.ErrInvitationInvalid))
+		detail, err := invitationService.GetInvitation(context.TODO(), invitation.ID, token)
+		Expect(err).Should(BeNil())
+		Expect(detail.PlatformRoles).Should(Equal([]string{"invite-platform-role"}))
+
+		invitations, err := invitationService.ListInvitations(context.TODO())
+		Expect(err).Should(BeNil())
+		Expect(len(invitations.Invitations)).Should(Equal(1))
+
+		user, err := invitationService.AcceptInvitation(context.TODO(), invitation.ID, apisv1.AcceptUserInvitationRequest{
+			Token:    token,
+			Name:     "invitee",
+			Password: "Invitee12345",
+		})
+		Expect(err).Should(BeNil())
+		Expect(user.Email).Should(Equal("invitee@example.com"))
+		Expect(user.Alias).Should(Equal("Invitee"))
+		created := &model.User{Name: "invitee"}
+		Expect(ds.Get(context.TODO(), created)).Should(BeNil())
+		Expect(created.UserRoles).Should(Equal([]string{"invite-platform-role"}))
+		projectUser := &model.ProjectUser{Username: "invitee", ProjectName: "invite-project"}
+		Expect(ds.Get(context.TODO(), projectUser)).Should(BeNil())
+		Expect(projectUser.UserRoles).Should(Equal([]string{"invite-project-role"}))
+
+		By("the invitation could only be used once")
+		_, err = invitationService.AcceptInvitation(context.TODO(), invitation.ID, apisv1.AcceptUserInvitationRequest{
+			Token:    token,
+			Name:     "invitee2",
+			Password: "Invitee12345",
+		})
+		Expect(err).Should(Equal(bcode.ErrInvitationInvalid))
+
+		By("the email of the existing user could not be invited")
+		_, err = invitationService.CreateInvitation(ctx, apisv1.CreateUserInvitationRequest{Email: "invitee@example.com"})
+		Expect(err).Should(Equal(bcode.ErrInvitationUserExist))
+	})
+
+	It("Test the expired invitation", func() {
+		invitation, err := invitationService.CreateInvitation(context.TODO(), apisv1.CreateUserInvitationRequest{Email: "expired@example.com"})
+		Expect(err).Should(BeNil())
+		token := regexp.MustCompile(`token=([0-9a-f]{64})`).FindStringSubmatch(sender.body)[1]
+		stored := &model.UserInvitation{ID: invitation.ID}
+		Expect(ds.Get(context.TODO(), stored)).Should(BeNil())
+		stored.ExpireTime = time.Now().Add(-time.Minute)
+		Expect(ds.Put(context.TODO(), stored)).Should(BeNil())
+
+		_, err = invitationService.GetInvitation(context.TODO(), invitation.ID, token)
+		Expect(err).Should(Equal(bcode.ErrInvitationExpired))
+		invitations, err := invitationService.ListInvitations(context.TODO())
+		Expect(err).Should(BeNil())
+		Expect(len(invitations.Invitations)).Should(Equal(0))
+	})
+})
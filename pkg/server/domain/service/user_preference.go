@@ -0,0 +1,259 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/email"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/slack"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// maxNotificationDestinations the maximum count of the destinations of a user
+const maxNotificationDestinations = 10
+
+// notificationEvents the notification events that could be disabled and routed by the users
+var notificationEvents = map[string]struct{}{
+	model.NotificationEventStepRegression:   {},
+	model.NotificationEventUserDeactivation: {},
+}
+
+// notificationSeverityLevels the more severe notification has the higher level
+var notificationSeverityLevels = map[string]int{
+	model.NotificationSeverityInfo:     0,
+	model.NotificationSeverityWarning:  1,
+	model.NotificationSeverityCritical: 2,
+}
+
+// UserPreferenceService manages the preferences of the users by themselves
+type UserPreferenceService interface {
+	GetPreference(ctx context.Context, username string) (*apisv1.UserPreferenceBase, error)
+	UpdatePreference(ctx context.Context, username string, req apisv1.UpdateUserPreferenceRequest) (*apisv1.UserPreferenceBase, error)
+}
+
+type userPreferenceServiceImpl struct {
+	Store datastore.DataStore `inject:"datastore"`
+}
+
+// NewUserPreferenceService new user preference service
+func NewUserPreferenceService() UserPreferenceService {
+	return &userPreferenceServiceImpl{}
+}
+
+// GetPreference returns the preferences of the user, the empty preferences are returned if the user never saves them
+func (u *userPreferenceServiceImpl) GetPreference(ctx context.Context, username string) (*apisv1.UserPreferenceBase, error) {
+	preference, err := getUserPreference(ctx, u.Store, username)
+	if err != nil {
+		return nil, err
+	}
+	return convertUserPreferenceBase(preference), nil
+}
+
+// UpdatePreference replaces the preferences of the user
+func (u *userPreferenceServiceImpl) UpdatePreference(ctx context.Context, username string, req apisv1.UpdateUserPreferenceRequest) (*apisv1.UserPreferenceBase, error) {
+	if req.DefaultProject != "" {
+		if err := u.Store.Get(ctx, &model.Project{Name: req.DefaultProject}); err != nil {
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				return nil, bcode.ErrProjectIsNotExist
+			}
+			return nil, err
+		}
+	}
+	if err := validateNotificationPreference(req.Notifications); err != nil {
+		return nil, err
+	}
+	preference := &model.UserPreference{
+		Username:       username,
+		DefaultProject: req.DefaultProject,
+		Language:       req.Language,
+		Theme:          req.Theme,
+		ItemsPerPage:   req.ItemsPerPage,
+		Notifications:  req.Notifications,
+	}
+	err := u.Store.Get(ctx, &model.UserPreference{Username: username})
+	switch {
+	case err == nil:
+		err = u.Store.Put(ctx, preference)
+	case errors.Is(err, datastore.ErrRecordNotExist):
+		err = u.Store.Add(ctx, preference)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return convertUserPreferenceBase(preference), nil
+}
+
+func validateNotificationPreference(preference model.NotificationPreference) error {
+	for _, event := range preference.DisabledEvents {
+		if _, ok := notificationEvents[event]; !ok {
+			return bcode.ErrUnknownNotificationEvent
+		}
+	}
+	if len(preference.Destinations) > maxNotificationDestinations {
+		return bcode.ErrInvalidNotificationPreference
+	}
+	names := map[string]struct{}{model.NotificationDestinationDefault: {}}
+	for _, d := range preference.Destinations {
+		if _, exist := names[d.Name]; exist || d.Name == "" || d.Address == "" {
+			return bcode.ErrInvalidNotificationPreference
+		}
+		names[d.Name] = struct{}{}
+		switch d.Type {
+		case model.NotificationDestinationEmail:
+			if _, err := mail.ParseAddress(d.Address); err != nil {
+				return bcode.ErrInvalidNotificationPreference
+			}
+		case model.NotificationDestinationSlack:
+		default:
+			return bcode.ErrInvalidNotificationPreference
+		}
+	}
+	for _, rule := range preference.Rules {
+		if _, ok := notificationEvents[rule.Category]; !ok && rule.Category != "*" {
+			return bcode.ErrUnknownNotificationEvent
+		}
+		if _, ok := notificationSeverityLevels[rule.Severity]; !ok && rule.Severity != "" {
+			return bcode.ErrInvalidNotificationPreference
+		}
+		if len(rule.Destinations) == 0 {
+			return bcode.ErrInvalidNotificationPreference
+		}
+		for _, name := range rule.Destinations {
+			if _, exist := names[name]; !exist {
+				return bcode.ErrInvalidNotificationPreference
+			}
+		}
+	}
+	return nil
+}
+
+// userNotification the notification to a user, it is delivered by the notification preferences of the user
+type userNotification struct {
+	Event    string
+	Severity string
+	Subject  string
+	Body     string
+}
+
+// sendSlackMessage sends the message by the Slack API, it is an external service so nothing is sent in the offline mode
+func sendSlackMessage(ctx context.Context, sysService SystemInfoService, slackSender slack.Sender, channel, text string) error {
+	if isOfflineMode(ctx, sysService) {
+		return slack.ErrOffline
+	}
+	return slackSender.Send(ctx, channel, text)
+}
+
+// notifyUser sends the notification to the destinations that the user routes the event to, the failures are only logged
+func notifyUser(ctx context.Context, store datastore.DataStore, sysService SystemInfoService, emailSender email.Sender, slackSender slack.Sender, username string, notification userNotification) {
+	user := &model.User{Name: username}
+	if err := store.Get(ctx, user); err != nil {
+		return
+	}
+	preference, err := getUserPreference(ctx, store, username)
+	if err != nil {
+		klog.Errorf("failed to get the preferences of the user %s: %s", username, err.Error())
+		return
+	}
+	for _, d := range notificationDestinations(user, preference.Notifications, notification) {
+		var err error
+		switch d.Type {
+		case model.NotificationDestinationEmail:
+			if emailSender == nil || !emailSender.Enabled() {
+				continue
+			}
+			err = emailSender.Send(ctx, d.Address, notification.Subject, notification.Body)
+		case model.NotificationDestinationSlack:
+			if slackSender == nil || !slackSender.Enabled() {
+				continue
+			}
+			err = sendSlackMessage(ctx, sysService, slackSender, d.Address, notification.Subject+"\n\n"+notification.Body)
+		}
+		if err != nil {
+			klog.Errorf("failed to send the %s notification to the %s destination %s of the user %s: %s", notification.Event, d.Type, d.Name, username, err.Error())
+		}
+	}
+}
+
+// notificationDestinations returns the destinations matched by the rules without the duplicated addresses,
+// the notification goes to the email of the user if there is no rule
+func notificationDestinations(user *model.User, preference model.NotificationPreference, notification userNotification) []model.NotificationDestination {
+	for _, event := range preference.DisabledEvents {
+		if event == notification.Event {
+			return nil
+		}
+	}
+	destinations := map[string]model.NotificationDestination{
+		model.NotificationDestinationDefault: {Name: model.NotificationDestinationDefault, Type: model.NotificationDestinationEmail, Address: user.Email},
+	}
+	for _, d := range preference.Destinations {
+		destinations[d.Name] = d
+	}
+	names := []string{model.NotificationDestinationDefault}
+	if len(preference.Rules) > 0 {
+		names = nil
+		for _, rule := range preference.Rules {
+			if rule.Category != "*" && rule.Category != notification.Event {
+				continue
+			}
+			if notificationSeverityLevels[notification.Severity] < notificationSeverityLevels[rule.Severity] {
+				continue
+			}
+			names = append(names, rule.Destinations...)
+		}
+	}
+	var matched []model.NotificationDestination
+	sent := map[string]struct{}{}
+	for _, name := range names {
+		d, ok := destinations[name]
+		if !ok || d.Address == "" || (d.Type == model.NotificationDestinationEmail && preference.DisableEmail) {
+			continue
+		}
+		if _, exist := sent[d.Type+"/"+d.Address]; exist {
+			continue
+		}
+		sent[d.Type+"/"+d.Address] = struct{}{}
+		matched = append(matched, d)
+	}
+	return matched
+}
+
+// getUserPreference returns the empty preferences if the user never saves them
+func getUserPreference(ctx context.Context, store datastore.DataStore, username string) (*model.UserPreference, error) {
+	preference := &model.UserPreference{Username: username}
+	if err := store.Get(ctx, preference); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	return preference, nil
+}
+
+func convertUserPreferenceBase(preference *model.UserPreference) *apisv1.UserPreferenceBase {
+	return &apisv1.UserPreferenceBase{
+		DefaultProject: preference.DefaultProject,
+		Language:       preference.Language,
+		Theme:          preference.Theme,
+		ItemsPerPage:   preference.ItemsPerPage,
+		Notifications:  preference.Notifications,
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gotest.tools/assert"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+func TestNotificationDestinations(t *testing.T) {
+	user := &model.User{Name: "dev", Email: "dev@example.com"}
+	oncall := model.NotificationDestination{Name: "oncall", Type: model.NotificationDestinationSlack, Address: "U123"}
+	personal := model.NotificationDestination{Name: "personal", Type: model.NotificationDestinationEmail, Address: "dev@example.com"}
+	defaultEmail := model.NotificationDestination{Name: model.NotificationDestinationDefault, Type: model.NotificationDestinationEmail, Address: "dev@example.com"}
+	warning := userNotification{Event: model.NotificationEventStepRegression, Severity: model.NotificationSeverityWarning}
+	testCases := map[string]struct {
+		preference   model.NotificationPreference
+		notification userNotification
+		destinations []model.NotificationDestination
+	}{
+		"the email of the user without the rules": {
+			notification: warning,
+			destinations: []model.NotificationDestination{defaultEmail},
+		},
+		"the disabled event": {
+			preference:   model.NotificationPreference{DisabledEvents: []string{model.NotificationEventStepRegression}},
+			notification: warning,
+		},
+		"the rules filtered by the severity": {
+			preference: model.NotificationPreference{
+				Destinations: []model.NotificationDestination{oncall},
+				Rules: []model.NotificationRule{
+					{Category: "*", Destinations: []string{model.NotificationDestinationDefault}},
+					{Category: model.NotificationEventStepRegression, Severity: model.NotificationSeverityCritical, Destinations: []string{"oncall"}},
+				},
+			},
+			notification: warning,
+			destinations: []model.NotificationDestination{defaultEmail},
+		},
+		"the duplicated address and the disabled email": {
+			preference: model.NotificationPreference{
+				DisableEmail: true,
+				Destinations: []model.NotificationDestination{oncall, personal},
+				Rules:        []model.NotificationRule{{Category: "*", Severity: model.NotificationSeverityInfo, Destinations: []string{"oncall", "personal", "oncall"}}},
+			},
+			notification: warning,
+			destinations: []model.NotificationDestination{oncall},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.DeepEqual(t, notificationDestinations(user, tc.preference, tc.notification), tc.destinations)
+		})
+	}
+}
+
+var _ = Describe("Test the user preferences", func() {
+	var (
+		ds                datastore.DataStore
+		preferenceService *userPreferenceServiceImpl
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "preference-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		preferenceService = &userPreferenceServiceImpl{Store: ds}
+		Expect(ds.Add(context.TODO(), &model.Project{Name: "preference-project"})).Should(BeNil())
+	})
+
+	It("Test get and update the preferences", func() {
+		ctx := context.TODO()
+		preference, err := preferenceService.GetPreference(ctx, "dev")
+		Expect(err).Should(BeNil())
+		Expect(preference.Language).Should(BeEmpty())
+		Expect(preference.Notifications.Rules).Should(BeEmpty())
+
+		By("the default project must exist")
+		_, err = preferenceService.UpdatePreference(ctx, "dev", apisv1.UpdateUserPreferenceRequest{DefaultProject: "not-exist"})
+		Expect(err).Should(Equal(bcode.ErrProjectIsNotExist))
+
+		By("the unknown notification event is rejected")
+		_, err = preferenceService.UpdatePreference(ctx, "dev", apisv1.UpdateUserPreferenceRequest{
+			Notifications: model.NotificationPreference{DisabledEvents: []string{"unknown"}},
+		})
+		Expect(err).Should(Equal(bcode.ErrUnknownNotificationEvent))
+
+		req := apisv1.UpdateUserPreferenceRequest{
+			DefaultProject: "preference-project",
+			Language:       "zh",
+			Theme:          "dark",
+			ItemsPerPage:   50,
+			Notifications:  model.NotificationPreference{DisabledEvents: []string{model.NotificationEventStepRegression}},
+		}
+		_, err = preferenceService.UpdatePreference(ctx, "dev", req)
+		Expect(err).Should(BeNil())
+		req.Theme = "light"
+		_, err = preferenceService.UpdatePreference(ctx, "dev", req)
+		Expect(err).Should(BeNil())
+
+		preference, err = preferenceService.GetPreference(ctx, "dev")
+		Expect(err).Should(BeNil())
+		Expect(preference.DefaultProject).Should(Equal("preference-project"))
+		Expect(preference.Language).Should(Equal("zh"))
+		Expect(preference.Theme).Should(Equal("light"))
+		Expect(preference.ItemsPerPage).Should(Equal(50))
+		Expect(preference.Notifications.DisabledEvents).Should(Equal([]string{model.NotificationEventStepRegression}))
+
+		By("the rules must refer to the registered destinations")
+		_, err = preferenceService.UpdatePreference(ctx, "dev", apisv1.UpdateUserPreferenceRequest{
+			Notifications: model.NotificationPreference{
+				Destinations: []model.NotificationDestination{{Name: "oncall", Type: model.NotificationDestinationSlack, Address: "U123"}},
+				Rules:        []model.NotificationRule{{Category: "*", Destinations: []string{"oncall", "team"}}},
+			},
+		})
+		Expect(err).Should(Equal(bcode.ErrInvalidNotificationPreference))
+		_, err = preferenceService.UpdatePreference(ctx, "dev", apisv1.UpdateUserPreferenceRequest{
+			Notifications: model.NotificationPreference{
+				Destinations: []model.NotificationDestination{{Name: "team", Type: model.NotificationDestinationEmail, Address: "not-an-email"}},
+			},
+		})
+		Expect(err).Should(Equal(bcode.ErrInvalidNotificationPreference))
+		_, err = preferenceService.UpdatePreference(ctx, "dev", apisv1.UpdateUserPreferenceRequest{
+			Notifications: model.NotificationPreference{
+				Destinations: []model.NotificationDestination{{Name: "oncall", Type: model.NotificationDestinationSlack, Address: "U123"}},
+				Rules:        []model.NotificationRule{{Category: model.NotificationEventStepRegression, Severity: model.NotificationSeverityWarning, Destinations: []string{"oncall", "default"}}},
+			},
+		})
+		Expect(err).Should(BeNil())
+	})
+})
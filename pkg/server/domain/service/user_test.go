@@ -18,6 +18,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -54,6 +55,7 @@ var _ = Describe("Test authentication service functions", func() {
 		projectService := &projectServiceImpl{K8sClient: k8sClient, Store: ds, RbacService: rbacService}
 		sysService := &systemInfoServiceImpl{Store: ds}
 		userService = &userServiceImpl{Store: ds, K8sClient: k8sClient, ProjectService: projectService, SysService: sysService, RbacService: rbacService}
+		projectService.UserService = userService
 	})
 	AfterEach(func() {
 		err := k8sClient.Delete(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: db}})
@@ -80,9 +82,52 @@ var _ = Describe("Test authentication service functions", func() {
 		Expect(u.Alias).Should(Equal("alias"))
 		Expect(u.Email).Should(Equal("email@example.com"))
 		Expect(u.Disabled).Should(Equal(false))
+		Expect(u.MustChangePassword).Should(BeTrue())
 		Expect(compareHashWithPassword(u.Password, "password")).Should(BeNil())
 	})
 
+	It("Test change the password that must be changed", func() {
+		_, err := userService.CreateUser(context.Background(), apisv1.CreateUserRequest{Name: "rotate", Password: "password1"})
+		Expect(err).Should(BeNil())
+		ctx := context.WithValue(context.Background(), &apisv1.CtxKeyUser, "rotate")
+
+		_, err = userService.ChangePassword(ctx, apisv1.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "password2"})
+		Expect(err).Should(Equal(bcode.ErrUserInconsistentPassword))
+		_, err = userService.ChangePassword(ctx, apisv1.ChangePasswordRequest{OldPassword: "password1", NewPassword: "password1"})
+		Expect(err).Should(Equal(bcode.ErrPasswordNotChanged))
+		user, err := userService.ChangePassword(ctx, apisv1.ChangePasswordRequest{OldPassword: "password1", NewPassword: "password2"})
+		Expect(err).Should(BeNil())
+		Expect(user.MustChangePassword).Should(BeFalse())
+
+		By("the administrator rotates the password and requires the user to change it again")
+		u, err := userService.GetUser(context.Background(), "rotate")
+		Expect(err).Should(BeNil())
+		user, err = userService.UpdateUser(context.Background(), u, apisv1.UpdateUserRequest{Password: "password3", MustChangePassword: true})
+		Expect(err).Should(BeNil())
+		Expect(user.MustChangePassword).Should(BeTrue())
+	})
+
+	It("Test init the admin user with the random password", func() {
+		randomAdminPassword = true
+		defer func() { randomAdminPassword = false }()
+		Expect(userService.Init(context.Background())).Should(BeNil())
+		admin, err := userService.GetUser(context.Background(), model.DefaultAdminUserName)
+		Expect(err).Should(BeNil())
+		Expect(admin.MustChangePassword).Should(BeTrue())
+		Expect(compareHashWithPassword(admin.Password, InitAdminPassword)).ShouldNot(BeNil())
+
+		By("the existing admin user with the default password must change it")
+		hash, err := GeneratePasswordHash(InitAdminPassword)
+		Expect(err).Should(BeNil())
+		admin.Password = hash
+		admin.MustChangePassword = false
+		Expect(ds.Put(context.Background(), admin)).Should(BeNil())
+		Expect(userService.Init(context.Background())).Should(BeNil())
+		admin, err = userService.GetUser(context.Background(), model.DefaultAdminUserName)
+		Expect(err).Should(BeNil())
+		Expect(admin.MustChangePassword).Should(BeTrue())
+	})
+
 	It("Test detail user", func() {
 		ctx := context.Background()
 		err := ds.Add(ctx, &model.User{
@@ -149,13 +194,53 @@ var _ = Describe("Test authentication service functions", func() {
 		Expect(err).Should(BeNil())
 		Expect(users.Total).Should(Equal(int64(1)))
 
-		err = userService.DeleteUser(ctx, "name")
+		err = userService.DeleteUser(ctx, "name", "")
 		Expect(err).Should(BeNil())
 		users, err = userService.ListUsers(ctx, 0, 10, apisv1.ListUserOptions{})
 		Expect(err).Should(BeNil())
 		Expect(users.Total).Should(Equal(int64(0)))
 	})
 
+	It("Test delete user with the owned resources", func() {
+		ctx := context.Background()
+		Expect(ds.Add(ctx, &model.User{Name: "owner", Email: "owner@example.com"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.User{Name: "successor", Email: "successor@example.com"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.User{Name: "disabled", Email: "disabled@example.com", Disabled: true})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Project{Name: "owned-project", Alias: "Owned", Owner: "owner"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ApplicationComponent{AppPrimaryKey: "owned-app", Name: "web", Creator: "owner"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ApplicationPolicy{AppPrimaryKey: "owned-app", Name: "topology", Creator: "owner"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.StatusBadge{ID: "owned-badge", Project: "owned-project", Creator: "owner"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ProjectUser{ProjectName: "owned-project", Username: "owner", UserRoles: []string{"app-developer"}})).Should(BeNil())
+
+		owned, err := userService.ListOwnedResources(ctx, "owner")
+		Expect(err).Should(BeNil())
+		Expect(owned.Total).Should(Equal(4))
+
+		err = userService.DeleteUser(ctx, "owner", "")
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrUserOwnsResources.BusinessCode))
+		Expect(userService.DeleteUser(ctx, "owner", "owner")).Should(Equal(bcode.ErrInvalidTransferUser))
+		Expect(userService.DeleteUser(ctx, "owner", "disabled")).Should(Equal(bcode.ErrInvalidTransferUser))
+		Expect(userService.DeleteUser(ctx, "owner", "nobody")).Should(Equal(bcode.ErrInvalidTransferUser))
+
+		Expect(userService.DeleteUser(ctx, "owner", "successor")).Should(BeNil())
+		_, err = userService.GetUser(ctx, "owner")
+		Expect(errors.Is(err, datastore.ErrRecordNotExist)).Should(BeTrue())
+
+		project := &model.Project{Name: "owned-project"}
+		Expect(ds.Get(ctx, project)).Should(BeNil())
+		Expect(project.Owner).Should(Equal("successor"))
+		Expect(project.Alias).Should(Equal("Owned"))
+		Expect(ds.Get(ctx, &model.ProjectUser{ProjectName: "owned-project", Username: "successor"})).Should(BeNil())
+		Expect(errors.Is(ds.Get(ctx, &model.ProjectUser{ProjectName: "owned-project", Username: "owner"}), datastore.ErrRecordNotExist)).Should(BeTrue())
+		events, err := ds.List(ctx, &model.ProjectMemberEvent{Project: "owned-project", Username: "owner"}, nil)
+		Expect(err).Should(BeNil())
+		Expect(events).Should(HaveLen(1))
+		Expect(events[0].(*model.ProjectMemberEvent).Type).Should(Equal(model.ProjectMemberRemoved))
+		owned, err = userService.ListOwnedResources(ctx, "successor")
+		Expect(err).Should(BeNil())
+		Expect(owned.Total).Should(Equal(4))
+	})
+
 	It("Test update user", func() {
 		ctx := context.Background()
 		userModel := &model.User{
@@ -91,3 +91,17 @@ func InterfaceSlice(slice interface{}) ([]interface{}, error) {
 	}
 	return ret, nil
 }
+
+// intersectStrings returns the items of b that also exist in a, keeping the order of b
+func intersectStrings(a, b []string) []string {
+	var res []string
+	for _, item := range b {
+		for _, target := range a {
+			if item == target {
+				res = append(res, item)
+				break
+			}
+		}
+	}
+	return res
+}
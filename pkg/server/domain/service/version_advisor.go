@@ -0,0 +1,291 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubevelatypes "github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/version"
+
+	v1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+const (
+	// AdviceLevelError means the version skew is not supported
+	AdviceLevelError = "error"
+	// AdviceLevelWarning means the version skew is supported but should be fixed soon
+	AdviceLevelWarning = "warning"
+
+	componentVelaCore = "vela-core"
+	componentVelaUX   = "velaux"
+
+	velaCoreReleaseURL = "https://github.com/kubevela/kubevela/releases"
+	velaUXReleaseURL   = "https://github.com/kubevela/velaux/releases"
+)
+
+// requiredCRDs the CRDs and the versions VelaUX depends on
+var requiredCRDs = map[string]string{
+	"applications.core.oam.dev":            "v1beta1",
+	"applicationrevisions.core.oam.dev":    "v1beta1",
+	"componentdefinitions.core.oam.dev":    "v1beta1",
+	"traitdefinitions.core.oam.dev":        "v1beta1",
+	"policydefinitions.core.oam.dev":       "v1beta1",
+	"workflowstepdefinitions.core.oam.dev": "v1beta1",
+	"definitionrevisions.core.oam.dev":     "v1beta1",
+	"resourcetrackers.core.oam.dev":        "v1beta1",
+	"workflowruns.core.oam.dev":            "v1alpha1",
+}
+
+// GetVersionAdvice compares the versions of VelaUX, vela-core and the CRDs in the hub and the managed clusters
+func (u systemInfoServiceImpl) GetVersionAdvice(ctx context.Context) (*v1.VersionAdviceResponse, error) {
+	res := &v1.VersionAdviceResponse{
+		VelaUXVersion: version.VelaVersion,
+		Components:    []v1.ComponentVersion{},
+		Advices:       []v1.UpgradeAdvice{},
+	}
+	hubCore, err := getVelaCoreVersion(ctx, u.KubeClient)
+	if err != nil {
+		return nil, err
+	}
+	if hubCore == "" {
+		res.Advices = append(res.Advices, v1.UpgradeAdvice{
+			Level:     AdviceLevelError,
+			Cluster:   multicluster.ClusterLocalName,
+			Component: componentVelaCore,
+			Message:   fmt.Sprintf("vela-core is not found in the namespace %s", kubevelatypes.DefaultKubeVelaNS),
+			Link:      velaCoreReleaseURL,
+		})
+	} else {
+		res.Components = append(res.Components, v1.ComponentVersion{Cluster: multicluster.ClusterLocalName, Component: componentVelaCore, Version: hubCore})
+		if advice := compareVelaCoreVersion(res.VelaUXVersion, hubCore); advice != nil {
+			res.Advices = append(res.Advices, *advice)
+		}
+	}
+
+	var crdNames []string
+	for name := range requiredCRDs {
+		crdNames = append(crdNames, name)
+	}
+	sort.Strings(crdNames)
+	hubCRDs := map[string]string{}
+	for _, name := range crdNames {
+		required := requiredCRDs[name]
+		storage, served, err := getCRDVersions(ctx, u.KubeClient, name)
+		if err != nil {
+			return nil, err
+		}
+		hubCRDs[name] = storage
+		if storage == "" {
+			res.Advices = append(res.Advices, v1.UpgradeAdvice{
+				Level:     AdviceLevelError,
+				Cluster:   multicluster.ClusterLocalName,
+				Component: name,
+				Message:   fmt.Sprintf("the CRD %s is not installed, please install the CRDs of vela-core", name),
+				Link:      velaCoreReleaseURL,
+			})
+			continue
+		}
+		res.Components = append(res.Components, v1.ComponentVersion{Cluster: multicluster.ClusterLocalName, Component: name, Version: storage})
+		if !served[required] {
+			res.Advices = append(res.Advices, v1.UpgradeAdvice{
+				Level:     AdviceLevelError,
+				Cluster:   multicluster.ClusterLocalName,
+				Component: name,
+				Message:   fmt.Sprintf("the CRD %s does not serve the version %s, please upgrade the CRDs of vela-core", name, required),
+				Link:      velaCoreReleaseURL,
+			})
+		}
+	}
+
+	clusters, err := multicluster.ListVirtualClusters(ctx, u.KubeClient)
+	if err != nil {
+		return nil, err
+	}
+	for _, cluster := range clusters {
+		if cluster.Name == multicluster.ClusterLocalName {
+			continue
+		}
+		res.Components, res.Advices = checkManagedClusterVersions(ctx, u.KubeClient, cluster.Name, hubCore, crdNames, hubCRDs, res.Components, res.Advices)
+	}
+	sortComponentVersions(res.Components)
+	return res, nil
+}
+
+// checkManagedClusterVersions compares vela-core and the CRDs installed in the managed cluster with the hub
+func checkManagedClusterVersions(ctx context.Context, cli client.Client, cluster string, hubCore string, crdNames []string, hubCRDs map[string]string,
+	components []v1.ComponentVersion, advices []v1.UpgradeAdvice) ([]v1.ComponentVersion, []v1.UpgradeAdvice) {
+	clusterCtx := multicluster.ContextWithClusterName(ctx, cluster)
+	core, err := getVelaCoreVersion(clusterCtx, cli)
+	if err != nil {
+		klog.Warningf("failed to get the vela-core version of the cluster %s: %s", cluster, err.Error())
+		return components, append(advices, v1.UpgradeAdvice{
+			Level:     AdviceLevelWarning,
+			Cluster:   cluster,
+			Component: componentVelaCore,
+			Message:   fmt.Sprintf("failed to check the versions of the cluster: %s", err.Error()),
+		})
+	}
+	if core != "" {
+		components = append(components, v1.ComponentVersion{Cluster: cluster, Component: componentVelaCore, Version: core})
+		if hubCore != "" && !sameMinorVersion(core, hubCore) {
+			advices = append(advices, v1.UpgradeAdvice{
+				Level:     AdviceLevelWarning,
+				Cluster:   cluster,
+				Component: componentVelaCore,
+				Message:   fmt.Sprintf("vela-core %s in the cluster does not match the version %s in the hub cluster", core, hubCore),
+				Link:      velaCoreReleaseURL,
+			})
+		}
+	}
+	for _, name := range crdNames {
+		hubStorage := hubCRDs[name]
+		storage, _, err := getCRDVersions(clusterCtx, cli, name)
+		if err != nil || storage == "" {
+			continue
+		}
+		components = append(components, v1.ComponentVersion{Cluster: cluster, Component: name, Version: storage})
+		if hubStorage != "" && storage != hubStorage {
+			advices = append(advices, v1.UpgradeAdvice{
+				Level:     AdviceLevelWarning,
+				Cluster:   cluster,
+				Component: name,
+				Message:   fmt.Sprintf("the storage version %s of the CRD does not match the version %s in the hub cluster", storage, hubStorage),
+				Link:      velaCoreReleaseURL,
+			})
+		}
+	}
+	return components, advices
+}
+
+// compareVelaCoreVersion checks the skew between VelaUX and vela-core, they should be in the same minor version
+func compareVelaCoreVersion(velaux, core string) *v1.UpgradeAdvice {
+	uxMajor, uxMinor, ok1 := parseMinorVersion(velaux)
+	coreMajor, coreMinor, ok2 := parseMinorVersion(core)
+	if !ok1 || !ok2 {
+		return &v1.UpgradeAdvice{
+			Level:     AdviceLevelWarning,
+			Cluster:   multicluster.ClusterLocalName,
+			Component: componentVelaCore,
+			Message:   fmt.Sprintf("could not compare the VelaUX version %s with the vela-core version %s", velaux, core),
+		}
+	}
+	switch {
+	case uxMajor == coreMajor && uxMinor == coreMinor:
+		return nil
+	case uxMajor == coreMajor && coreMinor == uxMinor+1:
+		return &v1.UpgradeAdvice{
+			Level:     AdviceLevelWarning,
+			Cluster:   multicluster.ClusterLocalName,
+			Component: componentVelaUX,
+			Message:   fmt.Sprintf("VelaUX %s is older than vela-core %s, please upgrade VelaUX to v%d.%d", velaux, core, coreMajor, coreMinor),
+			Link:      velaUXReleaseURL,
+		}
+	case uxMajor > coreMajor || (uxMajor == coreMajor && uxMinor > coreMinor):
+		return &v1.UpgradeAdvice{
+			Level:     AdviceLevelError,
+			Cluster:   multicluster.ClusterLocalName,
+			Component: componentVelaCore,
+			Message:   fmt.Sprintf("vela-core %s is older than VelaUX %s and is not supported, please upgrade vela-core to v%d.%d", core, velaux, uxMajor, uxMinor),
+			Link:      velaCoreReleaseURL,
+		}
+	default:
+		return &v1.UpgradeAdvice{
+			Level:     AdviceLevelError,
+			Cluster:   multicluster.ClusterLocalName,
+			Component: componentVelaUX,
+			Message:   fmt.Sprintf("VelaUX %s is too old for vela-core %s, please upgrade VelaUX to v%d.%d", velaux, core, coreMajor, coreMinor),
+			Link:      velaUXReleaseURL,
+		}
+	}
+}
+
+// getVelaCoreVersion returns the image tag of the vela-core deployment, it is empty if vela-core is not installed
+func getVelaCoreVersion(ctx context.Context, cli client.Client) (string, error) {
+	var deployments appsv1.DeploymentList
+	if err := cli.List(ctx, &deployments, client.InNamespace(kubevelatypes.DefaultKubeVelaNS),
+		client.MatchingLabels{"app.kubernetes.io/name": componentVelaCore}); err != nil {
+		return "", err
+	}
+	for _, deploy := range deployments.Items {
+		for _, c := range deploy.Spec.Template.Spec.Containers {
+			if index := strings.LastIndex(c.Image, ":"); index > 0 && !strings.Contains(c.Image[index:], "/") {
+				return c.Image[index+1:], nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// getCRDVersions returns the storage version and the served versions of the CRD
+func getCRDVersions(ctx context.Context, cli client.Client, name string) (string, map[string]bool, error) {
+	var crd crdv1.CustomResourceDefinition
+	if err := cli.Get(ctx, client.ObjectKey{Name: name}, &crd); err != nil {
+		return "", nil, client.IgnoreNotFound(err)
+	}
+	var storage string
+	served := map[string]bool{}
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			storage = v.Name
+		}
+		served[v.Name] = v.Served
+	}
+	return storage, served, nil
+}
+
+// parseMinorVersion parses the major and the minor version from a version like v1.8.0-alpha.1
+func parseMinorVersion(v string) (int, int, bool) {
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+func sameMinorVersion(a, b string) bool {
+	aMajor, aMinor, ok1 := parseMinorVersion(a)
+	bMajor, bMinor, ok2 := parseMinorVersion(b)
+	return ok1 && ok2 && aMajor == bMajor && aMinor == bMinor
+}
+
+func sortComponentVersions(components []v1.ComponentVersion) {
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].Cluster != components[j].Cluster {
+			return components[i].Cluster < components[j].Cluster
+		}
+		return components[i].Component < components[j].Component
+	})
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubevelatypes "github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/version"
+
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+func TestCompareVelaCoreVersion(t *testing.T) {
+	testCases := map[string]struct {
+		velaux    string
+		core      string
+		level     string
+		component string
+	}{
+		"same minor version": {velaux: "v1.8.0", core: "v1.8.2"},
+		"newer vela-core":    {velaux: "v1.8.0", core: "v1.9.0", level: AdviceLevelWarning, component: componentVelaUX},
+		"older vela-core":    {velaux: "v1.8.0", core: "v1.7.5", level: AdviceLevelError, component: componentVelaCore},
+		"too new vela-core":  {velaux: "v1.8.0", core: "v1.10.0", level: AdviceLevelError, component: componentVelaUX},
+		"unknown version":    {velaux: "UNKNOWN", core: "v1.8.0", level: AdviceLevelWarning, component: componentVelaCore},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			advice := compareVelaCoreVersion(tc.velaux, tc.core)
+			if tc.level == "" {
+				assert.Nil(t, advice)
+				return
+			}
+			assert.Equal(t, tc.level, advice.Level)
+			assert.Equal(t, tc.component, advice.Component)
+		})
+	}
+}
+
+var _ = Describe("Test the version advice", func() {
+	It("Test compare vela-core and the CRDs of the hub cluster", func() {
+		velaVersion := version.VelaVersion
+		version.VelaVersion = "v1.8.0"
+		defer func() { version.VelaVersion = velaVersion }()
+
+		labels := map[string]string{"app.kubernetes.io/name": componentVelaCore}
+		deploy := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubevela-vela-core", Namespace: kubevelatypes.DefaultKubeVelaNS, Labels: labels},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "kubevela", Image: "oamdev/vela-core:v1.7.2"}}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), deploy)).Should(BeNil())
+		defer func() { Expect(k8sClient.Delete(context.TODO(), deploy)).Should(BeNil()) }()
+
+		sysService := systemInfoServiceImpl{KubeClient: k8sClient}
+		res, err := sysService.GetVersionAdvice(context.TODO())
+		Expect(err).Should(BeNil())
+		Expect(res.VelaUXVersion).Should(Equal("v1.8.0"))
+		Expect(res.Components).Should(ContainElement(Equal(apisv1.ComponentVersion{Cluster: "local", Component: componentVelaCore, Version: "v1.7.2"})))
+		Expect(res.Components).Should(ContainElement(Equal(apisv1.ComponentVersion{Cluster: "local", Component: "applications.core.oam.dev", Version: "v1beta1"})))
+
+		var coreAdvice, crdAdvice bool
+		for _, advice := range res.Advices {
+			if advice.Component == componentVelaCore && advice.Level == AdviceLevelError {
+				coreAdvice = true
+			}
+			if advice.Component == "workflowruns.core.oam.dev" {
+				crdAdvice = true
+			}
+			Expect(advice.Component).ShouldNot(Equal("applications.core.oam.dev"))
+		}
+		Expect(coreAdvice).Should(BeTrue())
+		Expect(crdAdvice).Should(BeTrue())
+	})
+})
@@ -38,6 +38,8 @@ import (
 // WebhookService webhook service
 type WebhookService interface {
 	HandleApplicationWebhook(ctx context.Context, token string, req *restful.Request) (interface{}, error)
+	ListTriggerRejectedDeliveries(ctx context.Context, app *model.Application, token string, page, pageSize int) (*apisv1.ListTriggerRejectedDeliveriesResponse, error)
+	PurgeTriggerDeliveries(ctx context.Context) error
 }
 
 type webhookServiceImpl struct {
@@ -125,6 +127,9 @@ func (c *webhookServiceImpl) HandleApplicationWebhook(ctx context.Context, token
 		}
 		return nil, err
 	}
+	if err := c.verifyWebhookDelivery(ctx, webhookTrigger, req); err != nil {
+		return nil, err
+	}
 	app := &model.Application{
 		Name: webhookTrigger.AppPrimaryKey,
 	}
@@ -134,6 +139,11 @@ func (c *webhookServiceImpl) HandleApplicationWebhook(ctx context.Context, token
 		}
 		return nil, err
 	}
+	// the triggers patch the components and deploy the application, they are blocked in the locked projects
+	project := &model.Project{Name: app.Project}
+	if err := c.Store.Get(ctx, project); err == nil && project.IsLocked(time.Now()) {
+		return nil, bcode.ErrProjectLocked
+	}
 
 	var handler webhookHandler
 	var err error
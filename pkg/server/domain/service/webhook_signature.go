@@ -0,0 +1,238 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// HeaderWebhookTimestamp the header of the unix seconds when the delivery is signed
+	HeaderWebhookTimestamp = "X-VelaUX-Timestamp"
+	// HeaderWebhookNonce the header of the unique value of the delivery
+	HeaderWebhookNonce = "X-VelaUX-Nonce"
+	// HeaderWebhookSignature the header of the signature of the delivery, in the format of sha256=<hex>
+	HeaderWebhookSignature = "X-VelaUX-Signature"
+
+	minWebhookSecretLength = 16
+	maxWebhookNonceLength  = 128
+	// triggerRejectedDeliveryRetention how long the rejected deliveries are kept
+	triggerRejectedDeliveryRetention = time.Hour * 24 * 7
+)
+
+// webhookSignatureTolerance the max difference between the timestamp of the delivery and the server time, it is set by the server config
+var webhookSignatureTolerance = time.Minute * 5
+
+var (
+	// rejectedDeliveryClientLimiter limits the rejected deliveries recorded for each trigger and client IP, anyone
+	// knowing the URL of the trigger could send the unsigned requests
+	rejectedDeliveryClientLimiter = utils.NewRateLimiter(1.0/60, 10, 10000)
+	// rejectedDeliveryTriggerLimiter limits the rejected deliveries recorded for each trigger from all clients
+	rejectedDeliveryTriggerLimiter = utils.NewRateLimiter(1.0/10, 30, 10000)
+	// rejectedDeliveries counts all rejected deliveries, including the ones not recorded by the limits
+	rejectedDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "velaux_trigger_rejected_deliveries_total",
+		Help: "The number of the trigger deliveries rejected by the signature verification or the replay protection.",
+	}, []string{"reason"})
+)
+
+func init() {
+	prometheus.MustRegister(rejectedDeliveries)
+}
+
+func checkWebhookSecret(secret string) error {
+	if secret != "" && len(secret) < minWebhookSecretLength {
+		return bcode.ErrInvalidWebhookSecret
+	}
+	return nil
+}
+
+// signWebhookDelivery returns the HMAC-SHA256 of the timestamp, the nonce and the body joined by the dots
+func signWebhookDelivery(secret, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + nonce + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// triggerNonceKey the nonces are hashed with the token, so the key is short and safe for any datastore
+func triggerNonceKey(token, nonce string) string {
+	sum := sha256.Sum256([]byte(token + ":" + nonce))
+	return hex.EncodeToString(sum[:])[:48]
+}
+
+// verifyWebhookDelivery checks the timestamp, the signature and the nonce of the delivery if the trigger has a secret,
+// the rejected deliveries are recorded. The body is restored to be read by the payload handlers.
+func (c *webhookServiceImpl) verifyWebhookDelivery(ctx context.Context, trigger *model.ApplicationTrigger, req *restful.Request) error {
+	if trigger.Secret == "" {
+		return nil
+	}
+	reason, err := c.checkWebhookDelivery(ctx, trigger, req)
+	if err != nil {
+		c.recordRejectedDelivery(ctx, trigger, reason, utils.TrustedClientIP(req.Request))
+	}
+	return err
+}
+
+func (c *webhookServiceImpl) checkWebhookDelivery(ctx context.Context, trigger *model.ApplicationTrigger, req *restful.Request) (string, error) {
+	timestamp := req.HeaderParameter(HeaderWebhookTimestamp)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "missing or invalid timestamp", bcode.ErrWebhookDeliveryExpired
+	}
+	if diff := time.Since(time.Unix(seconds, 0)); diff > webhookSignatureTolerance || diff < -webhookSignatureTolerance {
+		return "timestamp out of the tolerance", bcode.ErrWebhookDeliveryExpired
+	}
+	nonce := req.HeaderParameter(HeaderWebhookNonce)
+	if nonce == "" || len(nonce) > maxWebhookNonceLength {
+		return "missing or invalid nonce", bcode.ErrInvalidWebhookSignature
+	}
+	signature := strings.TrimPrefix(req.HeaderParameter(HeaderWebhookSignature), "sha256=")
+	if signature == "" {
+		return "missing signature", bcode.ErrInvalidWebhookSignature
+	}
+	body, err := io.ReadAll(req.Request.Body)
+	if err != nil {
+		return "unreadable body", bcode.ErrInvalidWebhookPayloadBody
+	}
+	req.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if !hmac.Equal([]byte(signature), []byte(signWebhookDelivery(trigger.Secret, timestamp, nonce, body))) {
+		return "signature mismatch", bcode.ErrInvalidWebhookSignature
+	}
+	// the nonce is only stored after the signature is verified, so it could not be used up by the unsigned requests
+	if err := c.Store.Add(ctx, &model.TriggerNonce{Key: triggerNonceKey(trigger.Token, nonce), Token: trigger.Token}); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return "replayed nonce", bcode.ErrWebhookDeliveryReplayed
+		}
+		return "", err
+	}
+	return "", nil
+}
+
+// recordRejectedDelivery saves the rejected delivery, the failure is only logged because the delivery is rejected anyway.
+// The deliveries over the rate limits of the trigger and the client are only counted by the metric, so the unsigned
+// requests could not flood the datastore.
+func (c *webhookServiceImpl) recordRejectedDelivery(ctx context.Context, trigger *model.ApplicationTrigger, reason, clientIP string) {
+	if reason == "" {
+		return
+	}
+	rejectedDeliveries.WithLabelValues(reason).Inc()
+	if !rejectedDeliveryClientLimiter.Allow(trigger.Token+"/"+clientIP) || !rejectedDeliveryTriggerLimiter.Allow(trigger.Token) {
+		klog.V(4).Infof("webhook delivery rejected and not recorded by the rate limit: app=%s trigger=%s reason=%q client=%s", trigger.AppPrimaryKey, trigger.Name, reason, clientIP)
+		return
+	}
+	delivery := &model.TriggerRejectedDelivery{
+		Name:          utils.GenerateVersion(trigger.Name) + "-" + rand.String(4),
+		AppPrimaryKey: trigger.AppPrimaryKey,
+		Token:         trigger.Token,
+		Reason:        reason,
+		ClientIP:      clientIP,
+	}
+	if err := c.Store.Add(ctx, delivery); err != nil {
+		klog.Warningf("failed to save the rejected delivery of the trigger %s: %s", trigger.Name, err.Error())
+		return
+	}
+	klog.Warningf("webhook delivery rejected: app=%s trigger=%s reason=%q client=%s", trigger.AppPrimaryKey, trigger.Name, reason, clientIP)
+}
+
+// ListTriggerRejectedDeliveries lists the rejected deliveries of the trigger, the latest first
+func (c *webhookServiceImpl) ListTriggerRejectedDeliveries(ctx context.Context, app *model.Application, token string, page, pageSize int) (*apisv1.ListTriggerRejectedDeliveriesResponse, error) {
+	trigger := &model.ApplicationTrigger{Token: token}
+	if err := c.Store.Get(ctx, trigger); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrApplicationTriggerNotExist
+		}
+		return nil, err
+	}
+	if trigger.AppPrimaryKey != app.PrimaryKey() {
+		return nil, bcode.ErrApplicationTriggerNotExist
+	}
+	var delivery = model.TriggerRejectedDelivery{AppPrimaryKey: app.PrimaryKey(), Token: token}
+	entities, err := c.Store.List(ctx, &delivery, &datastore.ListOptions{Page: page, PageSize: pageSize, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil {
+		return nil, err
+	}
+	var res = apisv1.ListTriggerRejectedDeliveriesResponse{Deliveries: []*apisv1.TriggerRejectedDeliveryBase{}}
+	for _, entity := range entities {
+		d := entity.(*model.TriggerRejectedDelivery)
+		res.Deliveries = append(res.Deliveries, &apisv1.TriggerRejectedDeliveryBase{
+			Name:       d.Name,
+			Reason:     d.Reason,
+			ClientIP:   d.ClientIP,
+			CreateTime: d.CreateTime,
+		})
+	}
+	count, err := c.Store.Count(ctx, &delivery, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Total = count
+	return &res, nil
+}
+
+// PurgeTriggerDeliveries deletes the nonces that could not be replayed anymore because their timestamps are out of
+// the tolerance, and the rejected deliveries out of the retention
+func (c *webhookServiceImpl) PurgeTriggerDeliveries(ctx context.Context) error {
+	nonces, err := c.Store.List(ctx, &model.TriggerNonce{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entity := range nonces {
+		nonce := entity.(*model.TriggerNonce)
+		if now.Sub(nonce.CreateTime) <= 2*webhookSignatureTolerance {
+			continue
+		}
+		if err := c.Store.Delete(ctx, nonce); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to delete the expired nonce of the trigger: %s", err.Error())
+		}
+	}
+	deliveries, err := c.Store.List(ctx, &model.TriggerRejectedDelivery{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range deliveries {
+		delivery := entity.(*model.TriggerRejectedDelivery)
+		if now.Sub(delivery.CreateTime) <= triggerRejectedDeliveryRetention {
+			continue
+		}
+		if err := c.Store.Delete(ctx, delivery); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to delete the rejected delivery %s: %s", delivery.Name, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the signature verification of the trigger deliveries", func() {
+	var (
+		ds             datastore.DataStore
+		webhookService *webhookServiceImpl
+		trigger        *model.ApplicationTrigger
+	)
+	BeforeEach(func() {
+		var err error
+		ds, err = NewDatastore(datastore.Config{Type: "kubeapi", Database: "webhook-signature-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		Expect(err).Should(BeNil())
+		webhookService = &webhookServiceImpl{Store: ds}
+		trigger = &model.ApplicationTrigger{Name: "signed", AppPrimaryKey: "signed-app", Token: "signedtoken", Secret: "0123456789abcdef"}
+		Expect(ds.Add(context.TODO(), trigger)).Should(BeNil())
+	})
+
+	newDelivery := func(secret string, timestamp time.Time, nonce string, body string) *restful.Request {
+		httpreq, err := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+		Expect(err).Should(BeNil())
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		httpreq.Header.Set(HeaderWebhookTimestamp, ts)
+		httpreq.Header.Set(HeaderWebhookNonce, nonce)
+		httpreq.Header.Set(HeaderWebhookSignature, "sha256="+signWebhookDelivery(secret, ts, nonce, []byte(body)))
+		httpreq.RemoteAddr = "10.0.0.8:4321"
+		return restful.NewRequest(httpreq)
+	}
+
+	It("Test verify the signed deliveries", func() {
+		ctx := context.TODO()
+		req := newDelivery(trigger.Secret, time.Now(), "nonce-1", `{"upgrade":{}}`)
+		Expect(webhookService.verifyWebhookDelivery(ctx, trigger, req)).Should(BeNil())
+		body, err := io.ReadAll(req.Request.Body)
+		Expect(err).Should(BeNil())
+		Expect(string(body)).Should(Equal(`{"upgrade":{}}`))
+
+		By("the replayed delivery is rejected")
+		req = newDelivery(trigger.Secret, time.Now(), "nonce-1", `{"upgrade":{}}`)
+		Expect(webhookService.verifyWebhookDelivery(ctx, trigger, req)).Should(Equal(bcode.ErrWebhookDeliveryReplayed))
+
+		By("the stale and the wrongly signed deliveries are rejected")
+		req = newDelivery(trigger.Secret, time.Now().Add(-time.Hour), "nonce-2", `{}`)
+		Expect(webhookService.verifyWebhookDelivery(ctx, trigger, req)).Should(Equal(bcode.ErrWebhookDeliveryExpired))
+		req = newDelivery("another-secret-value", time.Now(), "nonce-3", `{}`)
+		Expect(webhookService.verifyWebhookDelivery(ctx, trigger, req)).Should(Equal(bcode.ErrInvalidWebhookSignature))
+
+		By("the deliveries of the triggers without the secret are not verified")
+		Expect(webhookService.verifyWebhookDelivery(ctx, &model.ApplicationTrigger{Token: "unsigned"}, newDelivery("", time.Now(), "", `{}`))).Should(BeNil())
+
+		deliveries, err := webhookService.ListTriggerRejectedDeliveries(ctx, &model.Application{Name: "signed-app"}, trigger.Token, 0, 0)
+		Expect(err).Should(BeNil())
+		Expect(deliveries.Total).Should(Equal(int64(3)))
+		var reasons []string
+		for _, d := range deliveries.Deliveries {
+			Expect(d.ClientIP).Should(Equal("10.0.0.8"))
+			reasons = append(reasons, d.Reason)
+		}
+		Expect(reasons).Should(ConsistOf("replayed nonce", "timestamp out of the tolerance", "signature mismatch"))
+
+		_, err = webhookService.ListTriggerRejectedDeliveries(ctx, &model.Application{Name: "another-app"}, trigger.Token, 0, 0)
+		Expect(err).Should(Equal(bcode.ErrApplicationTriggerNotExist))
+	})
+
+	It("Test the rejected deliveries over the rate limit are not recorded", func() {
+		ctx := context.TODO()
+		rejectedDeliveryClientLimiter = utils.NewRateLimiter(0.001, 2, 10)
+		defer func() { rejectedDeliveryClientLimiter = utils.NewRateLimiter(1.0/60, 10, 10000) }()
+		before := testutil.ToFloat64(rejectedDeliveries.WithLabelValues("signature mismatch"))
+		for i := 0; i < 5; i++ {
+			req := newDelivery("another-secret-value", time.Now(), "flood-"+strconv.Itoa(i), `{}`)
+			Expect(webhookService.verifyWebhookDelivery(ctx, trigger, req)).Should(Equal(bcode.ErrInvalidWebhookSignature))
+		}
+		count, err := ds.Count(ctx, &model.TriggerRejectedDelivery{Token: trigger.Token}, nil)
+		Expect(err).Should(BeNil())
+		Expect(count).Should(Equal(int64(2)))
+		Expect(testutil.ToFloat64(rejectedDeliveries.WithLabelValues("signature mismatch")) - before).Should(Equal(float64(5)))
+	})
+
+	It("Test purge the expired nonces and rejected deliveries", func() {
+		ctx := context.TODO()
+		Expect(ds.Add(ctx, &model.TriggerNonce{Key: triggerNonceKey(trigger.Token, "fresh"), Token: trigger.Token})).Should(BeNil())
+		expired := &model.TriggerNonce{Key: triggerNonceKey(trigger.Token, "expired"), Token: trigger.Token}
+		Expect(ds.Add(ctx, expired)).Should(BeNil())
+		expired.CreateTime = time.Now().Add(-time.Hour)
+		Expect(ds.Put(ctx, expired)).Should(BeNil())
+
+		Expect(webhookService.PurgeTriggerDeliveries(ctx)).Should(BeNil())
+		count, err := ds.Count(ctx, &model.TriggerNonce{Token: trigger.Token}, nil)
+		Expect(err).Should(BeNil())
+		Expect(count).Should(Equal(int64(1)))
+	})
+})
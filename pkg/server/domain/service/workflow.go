@@ -41,6 +41,7 @@ import (
 	wfUtils "github.com/kubevela/workflow/pkg/utils"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	pkgUtils "github.com/oam-dev/kubevela/pkg/utils"
 	"github.com/oam-dev/kubevela/pkg/utils/apply"
@@ -49,7 +50,10 @@ import (
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/domain/repository"
 	"github.com/kubevela/velaux/pkg/server/event/sync/convert"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/cloudevent"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/slack"
 	assembler "github.com/kubevela/velaux/pkg/server/interfaces/api/assembler/v1"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	"github.com/kubevela/velaux/pkg/server/utils"
@@ -62,6 +66,9 @@ const LogSourceResource = "Resource"
 // LogSourceURL Read the step logs from the URL.
 const LogSourceURL = "URL"
 
+// redactor masks the secrets in the workflow logs and step parameters, it is nil if no redaction rule is configured
+var redactor *utils.Redactor
+
 // WorkflowService workflow manage api
 type WorkflowService interface {
 	ListApplicationWorkflow(ctx context.Context, app *model.Application) ([]*apisv1.WorkflowBase, error)
@@ -75,9 +82,17 @@ type WorkflowService interface {
 
 	GetWorkflowRecord(ctx context.Context, workflow *model.Workflow, recordName string) (*model.WorkflowRecord, error)
 	CreateWorkflowRecord(ctx context.Context, appModel *model.Application, app *v1beta1.Application, workflow *model.Workflow) (*model.WorkflowRecord, error)
-	ListWorkflowRecords(ctx context.Context, workflow *model.Workflow, page, pageSize int) (*apisv1.ListWorkflowRecordsResponse, error)
+	ListWorkflowRecords(ctx context.Context, workflow *model.Workflow, page, pageSize int, options apisv1.ListWorkflowRecordsOptions) (*apisv1.ListWorkflowRecordsResponse, error)
 	DetailWorkflowRecord(ctx context.Context, workflow *model.Workflow, recordName string) (*apisv1.DetailWorkflowRecordResponse, error)
-	SyncWorkflowRecord(ctx context.Context) error
+	AnnotateWorkflowRecord(ctx context.Context, record *model.WorkflowRecord, req apisv1.AnnotateWorkflowRecordRequest) (*apisv1.WorkflowRecord, error)
+	// SyncWorkflowRecord syncs the status of the unfinished records, the warm-up throttles the first sync after the restart
+	SyncWorkflowRecord(ctx context.Context, warmUp *utils.WarmUp) error
+	// ListUnfinishedRecordApplications lists the applications deployed by the unfinished records, they are synced by SyncApplicationWorkflowRecords
+	ListUnfinishedRecordApplications(ctx context.Context) ([]types.NamespacedName, error)
+	// SyncApplicationWorkflowRecords syncs the status of the unfinished records deployed as the application, it is called when the application is changed
+	SyncApplicationWorkflowRecords(ctx context.Context, namespace, name string) error
+	// PruneWorkflowRecords deletes the finished records out of the retention policies of the projects
+	PruneWorkflowRecords(ctx context.Context) error
 	ResumeRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName, stepName string) error
 	TerminateRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName string) error
 	RollbackRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName, revisionName string) (*apisv1.WorkflowRecordBase, error)
@@ -94,12 +109,16 @@ func NewWorkflowService() WorkflowService {
 }
 
 type workflowServiceImpl struct {
-	Store             datastore.DataStore `inject:"datastore"`
-	KubeClient        client.Client       `inject:"kubeClient"`
-	KubeConfig        *rest.Config        `inject:"kubeConfig"`
-	Apply             apply.Applicator    `inject:"apply"`
-	EnvService        EnvService          `inject:""`
-	EnvBindingService EnvBindingService   `inject:""`
+	Store             datastore.DataStore  `inject:"datastore"`
+	KubeClient        client.Client        `inject:"kubeClient"`
+	KubeConfig        *rest.Config         `inject:"kubeConfig"`
+	Apply             apply.Applicator     `inject:"apply"`
+	EnvService        EnvService           `inject:""`
+	EnvBindingService EnvBindingService    `inject:""`
+	LogStore          logstore.Store       `inject:"logStore"`
+	SlackSender       slack.Sender         `inject:"slackSender"`
+	CloudEvents       cloudevent.Publisher `inject:"cloudEventPublisher"`
+	SysService        SystemInfoService    `inject:""`
 }
 
 // DeleteWorkflow delete application workflow
@@ -120,6 +139,7 @@ func (w *workflowServiceImpl) DeleteWorkflow(ctx context.Context, app *model.App
 		if err := w.Store.Delete(ctx, record); err != nil {
 			klog.Errorf("delete workflow record %s failure %s", record.PrimaryKey(), err.Error())
 		}
+		deleteStepLogs(ctx, w.Store, w.LogStore, &model.StepLog{Resource: stepLogResourceApplication, Entity: workflow.AppPrimaryKey, Record: record.PrimaryKey()})
 	}
 	if err := w.Store.Delete(ctx, workflow); err != nil {
 		if errors.Is(err, datastore.ErrRecordNotExist) {
@@ -135,31 +155,16 @@ func (w *workflowServiceImpl) DeleteWorkflowByApp(ctx context.Context, app *mode
 		AppPrimaryKey: app.PrimaryKey(),
 	}
 
-	workflows, err := w.Store.List(ctx, workflow, &datastore.ListOptions{})
-	if err != nil {
-		if errors.Is(err, datastore.ErrRecordNotExist) {
-			return nil
-		}
+	if _, err := w.Store.DeleteByFilter(ctx, workflow, nil); err != nil {
 		return err
 	}
-	for i := range workflows {
-		workflow := workflows[i].(*model.Workflow)
-		if err := w.Store.Delete(ctx, workflow); err != nil {
-			klog.Errorf("delete workflow %s failure %s", workflow.PrimaryKey(), err.Error())
-		}
-	}
 	var record = model.WorkflowRecord{
 		AppPrimaryKey: workflow.AppPrimaryKey,
 	}
-	records, err := w.Store.List(ctx, &record, &datastore.ListOptions{})
-	if err != nil {
-		klog.Errorf("list workflow %s record failure %s", workflow.PrimaryKey(), err.Error())
-	}
-	for _, record := range records {
-		if err := w.Store.Delete(ctx, record); err != nil {
-			klog.Errorf("delete workflow record %s failure %s", record.PrimaryKey(), err.Error())
-		}
+	if _, err := w.Store.DeleteByFilter(ctx, &record, nil); err != nil {
+		klog.Errorf("delete the workflow records of app %s failure %s", workflow.AppPrimaryKey, err.Error())
 	}
+	deleteStepLogs(ctx, w.Store, w.LogStore, &model.StepLog{Resource: stepLogResourceApplication, Entity: app.PrimaryKey()})
 	return nil
 }
 
@@ -287,14 +292,25 @@ func (w *workflowServiceImpl) GetApplicationDefaultWorkflow(ctx context.Context,
 }
 
 // ListWorkflowRecords list workflow record
-func (w *workflowServiceImpl) ListWorkflowRecords(ctx context.Context, workflow *model.Workflow, page, pageSize int) (*apisv1.ListWorkflowRecordsResponse, error) {
+func (w *workflowServiceImpl) ListWorkflowRecords(ctx context.Context, workflow *model.Workflow, page, pageSize int, options apisv1.ListWorkflowRecordsOptions) (*apisv1.ListWorkflowRecordsResponse, error) {
 	var record = model.WorkflowRecord{
 		AppPrimaryKey: workflow.AppPrimaryKey,
 		WorkflowName:  workflow.Name,
 	}
+	var queries []datastore.FuzzyQueryOption
+	if options.Commit != "" {
+		queries = append(queries, datastore.FuzzyQueryOption{Key: "buildMetadata.commit", Query: options.Commit})
+	}
+	if options.Branch != "" {
+		queries = append(queries, datastore.FuzzyQueryOption{Key: "buildMetadata.branch", Query: options.Branch})
+	}
+	if options.BuildURL != "" {
+		queries = append(queries, datastore.FuzzyQueryOption{Key: "buildMetadata.buildURL", Query: options.BuildURL})
+	}
+	fo := datastore.FilterOptions{Queries: queries}
 	records, err := w.Store.List(ctx, &record, &datastore.ListOptions{Page: page, PageSize: pageSize, SortBy: []datastore.SortOption{
 		{Key: "createTime", Order: datastore.SortOrderAscending},
-	}})
+	}, FilterOptions: fo})
 	if err != nil {
 		return nil, err
 	}
@@ -308,7 +324,7 @@ func (w *workflowServiceImpl) ListWorkflowRecords(ctx context.Context, workflow
 			resp.Records = append(resp.Records, *assembler.ConvertFromRecordModel(record))
 		}
 	}
-	count, err := w.Store.Count(ctx, &record, nil)
+	count, err := w.Store.Count(ctx, &record, &fo)
 	if err != nil {
 		return nil, err
 	}
@@ -353,7 +369,62 @@ func (w *workflowServiceImpl) DetailWorkflowRecord(ctx context.Context, workflow
 	}, nil
 }
 
-func (w *workflowServiceImpl) SyncWorkflowRecord(ctx context.Context) error {
+// AnnotateWorkflowRecord merge the external build metadata into the workflow record
+func (w *workflowServiceImpl) AnnotateWorkflowRecord(ctx context.Context, record *model.WorkflowRecord, req apisv1.AnnotateWorkflowRecordRequest) (*apisv1.WorkflowRecord, error) {
+	if err := validateBuildMetadata(req); err != nil {
+		return nil, err
+	}
+	metadata := record.BuildMetadata
+	if metadata == nil {
+		metadata = &model.BuildMetadata{}
+	}
+	if req.Provider != "" {
+		metadata.Provider = req.Provider
+	}
+	if req.Commit != "" {
+		metadata.Commit = req.Commit
+	}
+	if req.Branch != "" {
+		metadata.Branch = req.Branch
+	}
+	if req.BuildURL != "" {
+		metadata.BuildURL = req.BuildURL
+	}
+	if req.TestResults != nil {
+		metadata.TestResults = req.TestResults
+	}
+	for k, v := range req.Labels {
+		if v == "" {
+			delete(metadata.Labels, k)
+			continue
+		}
+		if metadata.Labels == nil {
+			metadata.Labels = map[string]string{}
+		}
+		metadata.Labels[k] = v
+	}
+	metadata.UpdateUser, _ = ctx.Value(&apisv1.CtxKeyUser).(string)
+	metadata.UpdateTime = time.Now().Time
+	record.BuildMetadata = metadata
+	if err := w.Store.Put(ctx, record); err != nil {
+		return nil, err
+	}
+	return assembler.ConvertFromRecordModel(record), nil
+}
+
+func validateBuildMetadata(req apisv1.AnnotateWorkflowRecordRequest) error {
+	if req.Provider == "" && req.Commit == "" && req.Branch == "" && req.BuildURL == "" && req.TestResults == nil && len(req.Labels) == 0 {
+		return bcode.ErrInvalidBuildMetadata
+	}
+	if tr := req.TestResults; tr != nil {
+		if tr.Total < 0 || tr.Passed < 0 || tr.Failed < 0 || tr.Skipped < 0 || tr.Passed+tr.Failed+tr.Skipped > tr.Total {
+			return bcode.ErrInvalidBuildMetadata
+		}
+	}
+	return nil
+}
+
+func (w *workflowServiceImpl) SyncWorkflowRecord(ctx context.Context, warmUp *utils.WarmUp) error {
 	var record = model.WorkflowRecord{
 		Finished: "false",
 	}
@@ -362,123 +433,191 @@ func (w *workflowServiceImpl) SyncWorkflowRecord(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	warmUp.SetTotal(len(records))
 
 	for _, item := range records {
-		app := &v1beta1.Application{}
+		if err := warmUp.Wait(ctx); err != nil {
+			return err
+		}
+		warmUp.Done()
 		record := item.(*model.WorkflowRecord)
-		workflow := &model.Workflow{
-			Name:          record.WorkflowName,
-			AppPrimaryKey: record.AppPrimaryKey,
+		if appName, ok := w.recordAppName(ctx, record); ok {
+			w.syncRecord(ctx, record, appName)
 		}
-		if err := w.Store.Get(ctx, workflow); err != nil {
-			klog.ErrorS(err, "failed to get workflow", "app name", record.AppPrimaryKey, "workflow name", record.WorkflowName, "record name", record.Name)
+	}
+
+	return nil
+}
+
+func (w *workflowServiceImpl) ListUnfinishedRecordApplications(ctx context.Context) ([]types.NamespacedName, error) {
+	var record = model.WorkflowRecord{
+		Finished: "false",
+	}
+	records, err := w.Store.List(ctx, &record, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var apps []types.NamespacedName
+	listed := map[types.NamespacedName]bool{}
+	for _, item := range records {
+		record := item.(*model.WorkflowRecord)
+		appName, ok := w.recordAppName(ctx, record)
+		if !ok {
 			continue
 		}
-		envbinding, err := w.EnvBindingService.GetEnvBinding(ctx, &model.Application{Name: record.AppPrimaryKey}, workflow.EnvName)
-		if err != nil {
-			klog.ErrorS(err, "failed to get envbinding", "app name", record.AppPrimaryKey, "workflow name", record.WorkflowName, "record name", record.Name)
-		}
-		var appName string
-		if envbinding != nil {
-			appName = envbinding.AppDeployName
-		}
-		if appName == "" {
-			appName = record.AppPrimaryKey
-		}
-		if err := w.KubeClient.Get(ctx, types.NamespacedName{
-			Name:      appName,
-			Namespace: record.Namespace,
-		}, app); err != nil {
-			if apierrors.IsNotFound(err) {
-				klog.Warningf("can't find the application %s/%s, set the record status to terminated", appName, record.Namespace)
-				if err := w.setRecordToTerminated(ctx, record.AppPrimaryKey, record.Name); err != nil {
-					klog.Errorf("failed to set the record status to terminated %s", err.Error())
-				}
-				continue
-			}
-			klog.ErrorS(err, "failed to get app", "oam app name", appName, "workflow name", record.WorkflowName, "record name", record.Name)
-			continue
+		app := types.NamespacedName{Namespace: record.Namespace, Name: appName}
+		if !listed[app] {
+			listed[app] = true
+			apps = append(apps, app)
 		}
+	}
+	return apps, nil
+}
 
-		if app.Status.Workflow == nil {
-			continue
+func (w *workflowServiceImpl) SyncApplicationWorkflowRecords(ctx context.Context, namespace, name string) error {
+	var record = model.WorkflowRecord{
+		Namespace: namespace,
+		Finished:  "false",
+	}
+	records, err := w.Store.List(ctx, &record, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, item := range records {
+		record := item.(*model.WorkflowRecord)
+		if appName, ok := w.recordAppName(ctx, record); ok && appName == name {
+			w.syncRecord(ctx, record, appName)
 		}
+	}
+	return nil
+}
 
-		// This means the application workflow has not run.
-		if app.Generation > app.Status.ObservedGeneration {
-			continue
-		}
+// recordAppName the name of the application deployed by the workflow of the record, it is the deploy name of the env binding
+func (w *workflowServiceImpl) recordAppName(ctx context.Context, record *model.WorkflowRecord) (string, bool) {
+	workflow := &model.Workflow{
+		Name:          record.WorkflowName,
+		AppPrimaryKey: record.AppPrimaryKey,
+	}
+	if err := w.Store.Get(ctx, workflow); err != nil {
+		klog.ErrorS(err, "failed to get workflow", "app name", record.AppPrimaryKey, "workflow name", record.WorkflowName, "record name", record.Name)
+		return "", false
+	}
+	envbinding, err := w.EnvBindingService.GetEnvBinding(ctx, &model.Application{Name: record.AppPrimaryKey}, workflow.EnvName)
+	if err != nil {
+		klog.ErrorS(err, "failed to get envbinding", "app name", record.AppPrimaryKey, "workflow name", record.WorkflowName, "record name", record.Name)
+	}
+	var appName string
+	if envbinding != nil {
+		appName = envbinding.AppDeployName
+	}
+	if appName == "" {
+		appName = record.AppPrimaryKey
+	}
+	return appName, true
+}
 
-		// there is a ":" in the default app revision
-		recordName := strings.Replace(app.Status.Workflow.AppRevision, ":", "-", 1)
+// recordKubeContext the context to read the resources of the record from the cluster running its workflow. It must not
+// be used by the datastore, the kubeapi datastore shares the multi-cluster client.
+func recordKubeContext(ctx context.Context, record *model.WorkflowRecord) context.Context {
+	if record.Cluster == "" {
+		return ctx
+	}
+	return multicluster.ContextWithClusterName(ctx, record.Cluster)
+}
 
-		// try to sync the status from the running application
-		if app.Annotations != nil && app.Status.Workflow != nil && recordName == record.Name {
-			if err := w.syncWorkflowStatus(ctx, record.AppPrimaryKey, app, record.Name, app.Name, nil); err != nil {
-				klog.ErrorS(err, "failed to sync workflow status", "oam app name", appName, "workflow name", record.WorkflowName, "record name", record.Name)
+// syncRecord syncs the status of the record from the application or its revision, the failures are only logged
+func (w *workflowServiceImpl) syncRecord(ctx context.Context, record *model.WorkflowRecord, appName string) {
+	app := &v1beta1.Application{}
+	if err := w.KubeClient.Get(recordKubeContext(ctx, record), types.NamespacedName{
+		Name:      appName,
+		Namespace: record.Namespace,
+	}, app); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.Warningf("can't find the application %s/%s, set the record status to terminated", appName, record.Namespace)
+			if err := w.setRecordToTerminated(ctx, record.AppPrimaryKey, record.Name); err != nil {
+				klog.Errorf("failed to set the record status to terminated %s", err.Error())
 			}
+			return
 		}
+		klog.ErrorS(err, "failed to get app", "oam app name", appName, "workflow name", record.WorkflowName, "record name", record.Name)
+		return
+	}
 
-		if record.Name == oam.GetPublishVersion(app) {
-			continue
-		}
+	if app.Status.Workflow == nil {
+		return
+	}
 
-		// try to sync the status from the application revision
-		var revision = &model.ApplicationRevision{AppPrimaryKey: record.AppPrimaryKey, Version: record.RevisionPrimaryKey}
-		if err := w.Store.Get(ctx, revision); err != nil {
-			if errors.Is(err, datastore.ErrRecordNotExist) {
-				// If the application revision is not exist, the record do not need be synced
-				var record = &model.WorkflowRecord{
-					AppPrimaryKey: record.AppPrimaryKey,
-					Name:          recordName,
-				}
-				if err := w.Store.Get(ctx, record); err == nil {
-					record.Finished = "true"
-					record.Status = model.RevisionStatusFail
-					err := w.Store.Put(ctx, record)
-					if err != nil {
-						klog.Errorf("failed to set the workflow status is failure %s", err.Error())
-					}
-					continue
-				}
-			}
-			klog.Errorf("failed to get the application revision from database %s", err.Error())
-			continue
+	// This means the application workflow has not run.
+	if app.Generation > app.Status.ObservedGeneration {
+		return
+	}
+
+	// there is a ":" in the default app revision
+	recordName := strings.Replace(app.Status.Workflow.AppRevision, ":", "-", 1)
+
+	// try to sync the status from the running application
+	if app.Annotations != nil && app.Status.Workflow != nil && recordName == record.Name {
+		if err := w.syncWorkflowStatus(ctx, record.AppPrimaryKey, app, record.Name, app.Name, nil); err != nil {
+			klog.ErrorS(err, "failed to sync workflow status", "oam app name", appName, "workflow name", record.WorkflowName, "record name", record.Name)
 		}
+	}
 
-		var appRevision v1beta1.ApplicationRevision
-		if err := w.KubeClient.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: revision.RevisionCRName}, &appRevision); err != nil {
-			if apierrors.IsNotFound(err) {
-				klog.Warningf("can't find the application revision %s/%s, set the record status to terminated", revision.RevisionCRName, app.Namespace)
-				if err := w.setRecordToTerminated(ctx, record.AppPrimaryKey, record.Name); err != nil {
-					klog.Errorf("failed to set the record status to terminated %s", err.Error())
+	if record.Name == oam.GetPublishVersion(app) {
+		return
+	}
+
+	// try to sync the status from the application revision
+	var revision = &model.ApplicationRevision{AppPrimaryKey: record.AppPrimaryKey, Version: record.RevisionPrimaryKey}
+	if err := w.Store.Get(ctx, revision); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			// If the application revision is not exist, the record do not need be synced
+			var record = &model.WorkflowRecord{
+				AppPrimaryKey: record.AppPrimaryKey,
+				Name:          recordName,
+			}
+			if err := w.Store.Get(ctx, record); err == nil {
+				record.Finished = "true"
+				record.Status = model.RevisionStatusFail
+				err := w.Store.Put(ctx, record)
+				if err != nil {
+					klog.Errorf("failed to set the workflow status is failure %s", err.Error())
 				}
-				continue
+				return
 			}
-			klog.Warningf("failed to get the application revision %s", err.Error())
-			continue
 		}
+		klog.Errorf("failed to get the application revision from database %s", err.Error())
+		return
+	}
 
-		if appRevision.Status.Workflow != nil {
-			appRevision.Spec.Application.Status.Workflow = appRevision.Status.Workflow
-			if !appRevision.Spec.Application.Status.Workflow.Finished {
-				appRevision.Spec.Application.Status.Workflow.Finished = true
-				appRevision.Spec.Application.Status.Workflow.Terminated = true
+	var appRevision v1beta1.ApplicationRevision
+	if err := w.KubeClient.Get(recordKubeContext(ctx, record), types.NamespacedName{Namespace: app.Namespace, Name: revision.RevisionCRName}, &appRevision); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.Warningf("can't find the application revision %s/%s, set the record status to terminated", revision.RevisionCRName, app.Namespace)
+			if err := w.setRecordToTerminated(ctx, record.AppPrimaryKey, record.Name); err != nil {
+				klog.Errorf("failed to set the record status to terminated %s", err.Error())
 			}
+			return
 		}
-		if err := w.syncWorkflowStatus(ctx,
-			record.AppPrimaryKey,
-			&appRevision.Spec.Application,
-			record.Name,
-			appRevision.Name,
-			appRevision.Status.WorkflowContext,
-		); err != nil {
-			klog.ErrorS(err, "failed to sync workflow status", "oam app name", appName, "workflow name", record.WorkflowName, "record name", record.Name)
-			continue
-		}
+		klog.Warningf("failed to get the application revision %s", err.Error())
+		return
 	}
 
-	return nil
+	if appRevision.Status.Workflow != nil {
+		appRevision.Spec.Application.Status.Workflow = appRevision.Status.Workflow
+		if !appRevision.Spec.Application.Status.Workflow.Finished {
+			appRevision.Spec.Application.Status.Workflow.Finished = true
+			appRevision.Spec.Application.Status.Workflow.Terminated = true
+		}
+	}
+	if err := w.syncWorkflowStatus(ctx,
+		record.AppPrimaryKey,
+		&appRevision.Spec.Application,
+		record.Name,
+		appRevision.Name,
+		appRevision.Status.WorkflowContext,
+	); err != nil {
+		klog.ErrorS(err, "failed to sync workflow status", "oam app name", appName, "workflow name", record.WorkflowName, "record name", record.Name)
+	}
 }
 
 func (w *workflowServiceImpl) setRecordToTerminated(ctx context.Context, appPrimaryKey, recordName string) error {
@@ -548,13 +687,14 @@ func (w *workflowServiceImpl) syncWorkflowStatus(ctx context.Context,
 			return nil
 		}
 		status := app.Status.Workflow
+		previousFinished := record.Finished
 		record.Status = string(status.Phase)
 		record.Message = status.Message
 		record.Mode = status.Mode
 
 		if cb := app.Status.Workflow.ContextBackend; cb != nil && workflowContext == nil && cb.Namespace != "" && cb.Name != "" {
 			var cm corev1.ConfigMap
-			if err := w.KubeClient.Get(ctx, types.NamespacedName{Namespace: cb.Namespace, Name: cb.Name}, &cm); err != nil {
+			if err := w.KubeClient.Get(recordKubeContext(ctx, record), types.NamespacedName{Namespace: cb.Namespace, Name: cb.Name}, &cm); err != nil {
 				klog.Errorf("failed to load the context values of the application %s:%s", app.Name, err.Error())
 			}
 			record.ContextValue = cm.Data
@@ -592,10 +732,12 @@ func (w *workflowServiceImpl) syncWorkflowStatus(ctx context.Context,
 
 		record.Finished = strconv.FormatBool(status.Finished)
 		record.EndTime = status.EndTime.Time
+		redactWorkflowRecord(record)
 		if err := w.Store.Put(ctx, record); err != nil {
 			return err
 		}
 
+		previousStatus := revision.Status
 		revision.Status = generateRevisionStatus(status.Phase)
 		if app.Status.LatestRevision != nil {
 			revision.RevisionCRName = app.Status.LatestRevision.Name
@@ -603,6 +745,22 @@ func (w *workflowServiceImpl) syncWorkflowStatus(ctx context.Context,
 		if err := w.Store.Put(ctx, revision); err != nil {
 			return err
 		}
+		failed := revision.Status == model.RevisionStatusFail && previousStatus != model.RevisionStatusFail
+		finished := record.Finished == "true" && previousFinished != "true"
+		if failed || finished {
+			var appModel = &model.Application{Name: appPrimaryKey}
+			if err := w.Store.Get(ctx, appModel); err == nil {
+				if failed {
+					alertDeployFailure(ctx, w.SysService, w.SlackSender, appModel, record)
+				}
+				if finished {
+					PublishSyncEvent(w.CloudEvents, workflowEventType(record.Status), appModel, record)
+				}
+			}
+		}
+		if finished && collectStepLogs {
+			w.collectStepLogs(ctx, record)
+		}
 	}
 
 	if record.Finished == "true" {
@@ -612,6 +770,21 @@ func (w *workflowServiceImpl) syncWorkflowStatus(ctx context.Context,
 	return nil
 }
 
+// redactWorkflowRecord masks the secrets echoed by the steps before the record is saved
+func redactWorkflowRecord(record *model.WorkflowRecord) {
+	if redactor == nil {
+		return
+	}
+	record.Message = redactor.Redact(record.Message)
+	for i := range record.Steps {
+		record.Steps[i].Message = redactor.Redact(record.Steps[i].Message)
+		for j := range record.Steps[i].SubStepsStatus {
+			record.Steps[i].SubStepsStatus[j].Message = redactor.Redact(record.Steps[i].SubStepsStatus[j].Message)
+		}
+	}
+	redactor.RedactMap(record.ContextValue)
+}
+
 func generateRevisionStatus(phase workflowv1alpha1.WorkflowRunPhase) string {
 	summaryStatus := model.RevisionStatusRunning
 	switch {
@@ -665,6 +838,7 @@ func (w *workflowServiceImpl) CreateWorkflowRecord(ctx context.Context, appModel
 		StartTime:          time.Now().Time,
 		Steps:              steps,
 		Status:             string(workflowv1alpha1.WorkflowStateInitializing),
+		RequestID:          app.Annotations[model.AnnotationRequestID],
 	}
 
 	if err := w.Store.Add(ctx, workflowRecord); err != nil {
@@ -864,6 +1038,10 @@ func (w *workflowServiceImpl) RollbackRecord(ctx context.Context, appModel *mode
 	newRecordName := utils.GenerateVersion(record.WorkflowName)
 	oamApp.Annotations[oam.AnnotationDeployVersion] = revisionVersion
 	oamApp.Annotations[oam.AnnotationPublishVersion] = newRecordName
+	delete(oamApp.Annotations, model.AnnotationRequestID)
+	if requestID, ok := utils.RequestIDFrom(ctx); ok {
+		oamApp.Annotations[model.AnnotationRequestID] = requestID
+	}
 	// create a new workflow record
 	newRecord, err := w.CreateWorkflowRecord(ctx, appModel, oamApp, workflow)
 	if err != nil {
@@ -910,11 +1088,20 @@ func (w *workflowServiceImpl) GetWorkflowRecordLog(ctx context.Context, record *
 	if len(record.ContextValue) == 0 {
 		return apisv1.GetPipelineRunLogResponse{}, nil
 	}
+	stepBase := getWorkflowStepBase(*record, step)
+	archived := &model.StepLog{Resource: stepLogResourceApplication, Entity: record.AppPrimaryKey, Record: record.Name, Step: step}
+	if logs, ok := loadStepLog(ctx, w.Store, w.LogStore, archived); ok {
+		return apisv1.GetPipelineRunLogResponse{
+			LogSource: archived.Source,
+			StepBase:  stepBase,
+			Log:       logs,
+		}, nil
+	}
 	logConfig, err := getLogConfigFromStep(record.ContextValue, step)
 	if err != nil {
 		if strings.Contains(err.Error(), "no log config found") {
 			return apisv1.GetPipelineRunLogResponse{
-				StepBase: getWorkflowStepBase(*record, step),
+				StepBase: stepBase,
 				Log:      "",
 			}, nil
 		}
@@ -947,9 +1134,14 @@ func (w *workflowServiceImpl) GetWorkflowRecordLog(ctx context.Context, record *
 			logs = logsBuilder.String()
 		}
 	}
+	logs = redactor.Redact(logs)
+	if logs != "" && stepFinished(stepBase.Phase) {
+		archived.Source = source
+		archiveStepLog(ctx, w.Store, w.LogStore, archived, logs)
+	}
 	return apisv1.GetPipelineRunLogResponse{
 		LogSource: source,
-		StepBase:  getWorkflowStepBase(*record, step),
+		StepBase:  stepBase,
 		Log:       logs,
 	}, nil
 }
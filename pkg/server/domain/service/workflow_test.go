@@ -39,6 +39,7 @@ import (
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/mongodb"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
 
 var appName = "app-workflow"
@@ -195,7 +196,7 @@ var _ = Describe("Test workflow service functions", func() {
 			Expect(err).Should(BeNil())
 		}
 
-		resp, err := workflowService.ListWorkflowRecords(context.TODO(), workflow, 0, 10)
+		resp, err := workflowService.ListWorkflowRecords(context.TODO(), workflow, 0, 10, apisv1.ListWorkflowRecordsOptions{})
 		Expect(err).Should(BeNil())
 		Expect(resp.Total).Should(Equal(int64(3)))
 
@@ -235,6 +236,34 @@ var _ = Describe("Test workflow service functions", func() {
 		Expect(detail.WorkflowRecord.Name).Should(Equal("test-workflow-2-123"))
 		Expect(detail.DeployUser).Should(Equal("test-user"))
 
+		By("attach the build metadata to the workflow record and search by it")
+		wfRecord, err := workflowService.GetWorkflowRecord(context.TODO(), workflow, "test-workflow-2-123")
+		Expect(err).Should(BeNil())
+		_, err = workflowService.AnnotateWorkflowRecord(context.TODO(), wfRecord, apisv1.AnnotateWorkflowRecordRequest{})
+		Expect(err).Should(Equal(bcode.ErrInvalidBuildMetadata))
+		_, err = workflowService.AnnotateWorkflowRecord(context.TODO(), wfRecord, apisv1.AnnotateWorkflowRecordRequest{
+			TestResults: &model.BuildTestResults{Total: 1, Passed: 2},
+		})
+		Expect(err).Should(Equal(bcode.ErrInvalidBuildMetadata))
+		_, err = workflowService.AnnotateWorkflowRecord(context.TODO(), wfRecord, apisv1.AnnotateWorkflowRecordRequest{
+			Commit:   "8f2c9e1",
+			BuildURL: "https://ci.example.com/builds/42",
+			Labels:   map[string]string{"pipeline": "release"},
+		})
+		Expect(err).Should(BeNil())
+		annotated, err := workflowService.AnnotateWorkflowRecord(context.TODO(), wfRecord, apisv1.AnnotateWorkflowRecordRequest{
+			TestResults: &model.BuildTestResults{Total: 10, Passed: 9, Failed: 1},
+		})
+		Expect(err).Should(BeNil())
+		Expect(annotated.BuildMetadata.Commit).Should(Equal("8f2c9e1"))
+		Expect(annotated.BuildMetadata.Labels["pipeline"]).Should(Equal("release"))
+		Expect(annotated.BuildMetadata.TestResults.Failed).Should(Equal(1))
+
+		resp, err = workflowService.ListWorkflowRecords(context.TODO(), workflow, 0, 10, apisv1.ListWorkflowRecordsOptions{Commit: "8f2c"})
+		Expect(err).Should(BeNil())
+		Expect(resp.Total).Should(Equal(int64(1)))
+		Expect(resp.Records[0].Name).Should(Equal("test-workflow-2-123"))
+
 		By("create one workflow record to test sync status from application")
 		raw, err = yaml.YAMLToJSON([]byte(yamlStr))
 		Expect(err).Should(BeNil())
@@ -274,7 +303,7 @@ var _ = Describe("Test workflow service functions", func() {
 		app.Status.ObservedGeneration = 1
 		err = workflowService.KubeClient.Status().Patch(ctx, app, client.Merge)
 		Expect(err).Should(BeNil())
-		err = workflowService.SyncWorkflowRecord(ctx)
+		err = workflowService.SyncWorkflowRecord(ctx, nil)
 		Expect(err).Should(BeNil())
 
 		workflow, err = workflowService.GetWorkflow(context.TODO(), &model.Application{
@@ -344,7 +373,7 @@ var _ = Describe("Test workflow service functions", func() {
 		appRevision.Status.Workflow.AppRevision = app.Annotations[oam.AnnotationPublishVersion]
 		err = workflowService.KubeClient.Status().Update(ctx, appRevision)
 		Expect(err).Should(BeNil())
-		err = workflowService.SyncWorkflowRecord(ctx)
+		err = workflowService.SyncWorkflowRecord(ctx, nil)
 		Expect(err).Should(BeNil())
 
 		By("check the record")
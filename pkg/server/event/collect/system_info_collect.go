@@ -110,8 +110,8 @@ func (i InfoCalculateCronJob) run() error {
 	if !ok {
 		return nil
 	}
-	// if disable collection skip calculate job
-	if !info.EnableCollection {
+	// if disable collection or in the offline mode skip calculate job
+	if !info.EnableCollection || info.OfflineMode {
 		return nil
 	}
 
@@ -18,12 +18,12 @@ package event
 
 import (
 	"context"
-
-	"k8s.io/client-go/util/workqueue"
+	"time"
 
 	"github.com/kubevela/velaux/pkg/server/config"
 	"github.com/kubevela/velaux/pkg/server/event/collect"
 	"github.com/kubevela/velaux/pkg/server/event/sync"
+	"github.com/kubevela/velaux/pkg/server/utils"
 )
 
 var workers []Worker
@@ -36,14 +36,62 @@ type Worker interface {
 // InitEvent init all event worker
 func InitEvent(cfg config.Config) []interface{} {
 	workflow := &sync.WorkflowRecordSync{
-		Duration: cfg.LeaderConfig.Duration,
+		Duration: cfg.WorkflowRecordResyncInterval,
+		WarmUp:   utils.NewWarmUp("workflow records", cfg.WarmUpResyncQPS),
+		Workers:  cfg.SyncWorkers,
 	}
 	application := &sync.ApplicationSync{
-		Queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		Workers:              cfg.SyncWorkers,
+		DisableExternal:      !cfg.SyncExternalApplications,
+		ExternalAppsReadOnly: cfg.ExternalApplicationsReadOnly,
+		SyncManagedClusters:  cfg.SyncManagedClusters,
+		WarmUp:               utils.NewWarmUp("applications", cfg.WarmUpResyncQPS),
+	}
+	capiCluster := &sync.CAPIClusterSync{
+		Duration: time.Second * 30,
+	}
+	definitionCatalog := &sync.DefinitionCatalogSync{
+		Duration: time.Minute * 5,
+	}
+	pipelineStepDuration := &sync.PipelineStepDurationSync{
+		Duration: time.Minute,
+	}
+	addonRollout := &sync.AddonRolloutSync{
+		Duration: time.Second * 10,
+	}
+	permissionSnapshot := &sync.PermissionSnapshotSync{
+		Duration: cfg.PermissionSnapshotInterval,
+	}
+	userDeactivation := &sync.UserDeactivationSync{
+		Duration: time.Minute,
+	}
+	projectLock := &sync.ProjectLockSync{
+		Duration: time.Minute,
+	}
+	triggerDelivery := &sync.TriggerDeliverySync{
+		Duration: time.Minute * 5,
+	}
+	searchIndex := &sync.SearchIndexSync{
+		Duration: cfg.SearchIndexInterval,
+	}
+	policyBundle := &sync.PolicyBundleSync{
+		Enabled: cfg.EnablePolicyBundles,
+	}
+	trash := &sync.TrashSync{
+		Duration: time.Hour,
+	}
+	targetNamespace := &sync.TargetNamespaceSync{
+		Duration: time.Minute * 5,
+	}
+	recordPrune := &sync.WorkflowRecordPruneSync{
+		Duration: time.Hour,
+	}
+	orphanGC := &sync.OrphanGCSync{
+		Duration: time.Minute * 10,
 	}
 	collect := &collect.InfoCalculateCronJob{}
-	workers = append(workers, workflow, application, collect)
-	return []interface{}{workflow, application, collect}
+	workers = append(workers, workflow, application, capiCluster, definitionCatalog, pipelineStepDuration, addonRollout, permissionSnapshot, userDeactivation, projectLock, triggerDelivery, searchIndex, policyBundle, trash, targetNamespace, recordPrune, orphanGC, collect)
+	return []interface{}{workflow, application, capiCluster, definitionCatalog, pipelineStepDuration, addonRollout, permissionSnapshot, userDeactivation, projectLock, triggerDelivery, searchIndex, policyBundle, trash, targetNamespace, recordPrune, orphanGC, collect}
 }
 
 // StartEventWorker start all event worker
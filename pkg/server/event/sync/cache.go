@@ -34,6 +34,7 @@ import (
 type cached struct {
 	revision string
 	targets  int64
+	status   string
 }
 
 // initCache will initialize the cache
@@ -51,17 +52,17 @@ func (c *CR2UX) initCache(ctx context.Context) error {
 			continue
 		}
 		revision, ok := app.Labels[model.LabelSyncRevision]
-		if !ok {
+		if !ok || app.GetSyncedCluster() != c.cluster {
 			continue
 		}
 		namespace := app.Labels[model.LabelSyncNamespace]
 		var key = formatAppComposedName(app.Name, namespace)
-		if strings.HasSuffix(app.Name, namespace) {
+		if c.cluster != "" || strings.HasSuffix(app.Name, namespace) {
 			key = app.Name
 		}
 
 		// we should check targets if we synced from app status
-		c.syncCache(key, revision, 0)
+		c.syncCache(key, revision, 0, syncedStatusKey(app.SyncedStatus))
 	}
 	return nil
 }
@@ -78,6 +79,10 @@ func (c *CR2UX) shouldSync(ctx context.Context, targetApp *v1beta1.Application,
 		}
 	}
 
+	if !isAddonApplication(targetApp) && !c.shouldSyncExternal(targetApp) {
+		return false
+	}
+
 	// if no LabelSourceOfTruth label, it means the app is existing ones, check the existing labels and annotations
 	if targetApp.Annotations != nil {
 		if _, exist := targetApp.Annotations[oam.AnnotationAppName]; exist {
@@ -85,7 +90,7 @@ func (c *CR2UX) shouldSync(ctx context.Context, targetApp *v1beta1.Application,
 		}
 	}
 
-	key := formatAppComposedName(targetApp.Name, targetApp.Namespace)
+	key := c.cacheKey(targetApp.Name, targetApp.Namespace)
 	cachedData, ok := c.cache.Load(key)
 	if ok {
 		cd := cachedData.(*cached)
@@ -101,7 +106,15 @@ func (c *CR2UX) shouldSync(ctx context.Context, targetApp *v1beta1.Application,
 	return true
 }
 
-func (c *CR2UX) syncCache(key string, revision string, targets int64) {
+func (c *CR2UX) syncCache(key string, revision string, targets int64, status string) {
 	// update cache
-	c.cache.Store(key, &cached{revision: revision, targets: targets})
+	c.cache.Store(key, &cached{revision: revision, targets: targets, status: status})
+}
+
+// shouldSyncExternal checks whether the application created outside VelaUX is in the scope of the sync
+func (c *CR2UX) shouldSyncExternal(targetApp *v1beta1.Application) bool {
+	if c.disableExternal {
+		return false
+	}
+	return c.scope.get().match(c.cluster, targetApp.Namespace, targetApp.Labels)
 }
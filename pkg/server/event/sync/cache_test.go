@@ -28,6 +28,7 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	velatypes "github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
@@ -81,7 +82,7 @@ var _ = Describe("Test Cache", func() {
 		Expect(cr2ux.shouldSync(ctx, app2, false)).Should(BeEquivalentTo(true))
 
 		// Only need to sync once.
-		cr2ux.syncCache(formatAppComposedName(app2.Name, app2.Namespace), "v1", 1)
+		cr2ux.syncCache(formatAppComposedName(app2.Name, app2.Namespace), "v1", 1, "")
 		Expect(cr2ux.shouldSync(ctx, app2, false)).Should(BeEquivalentTo(false))
 
 		app3 := &v1beta1.Application{}
@@ -98,7 +99,7 @@ var _ = Describe("Test Cache", func() {
 			model.LabelSyncRevision:  "v1",
 			model.LabelSyncNamespace: "app1-ns",
 		}})).Should(BeNil())
-		cr2ux.syncCache(formatAppComposedName(app1.Name, app1.Namespace), "v1", 0)
+		cr2ux.syncCache(formatAppComposedName(app1.Name, app1.Namespace), "v1", 0, "")
 		app1.Status.LatestRevision = &common.Revision{Name: "v1"}
 		Expect(cr2ux.shouldSync(ctx, app1, false)).Should(BeEquivalentTo(false))
 		Expect(cr2ux.shouldSync(ctx, app1, true)).Should(BeEquivalentTo(true))
@@ -130,4 +131,28 @@ var _ = Describe("Test Cache", func() {
 		Expect(k8sClient.Create(ctx, app1)).Should(BeNil())
 		Expect(cr2ux.shouldSync(ctx, app1, false)).Should(BeEquivalentTo(false))
 	})
+	It("Test sync the external applications by the namespaces", func() {
+		ctx := context.Background()
+		app := &v1beta1.Application{}
+		app.Name = "external-app"
+		app.Namespace = "gitops"
+
+		scope, err := newSyncScope(&model.SyncScope{IncludeNamespaces: []string{"gitops"}})
+		Expect(err).Should(BeNil())
+		holder := &scopeHolder{}
+		holder.set(scope)
+		cr2ux := CR2UX{cache: sync.Map{}, scope: holder}
+		Expect(cr2ux.shouldSync(ctx, app, false)).Should(BeTrue())
+		app.Namespace = "default"
+		Expect(cr2ux.shouldSync(ctx, app, false)).Should(BeFalse())
+
+		cr2ux = CR2UX{cache: sync.Map{}, disableExternal: true}
+		Expect(cr2ux.shouldSync(ctx, app, false)).Should(BeFalse())
+		By("the addon applications are always synced")
+		addonApp := &v1beta1.Application{}
+		addonApp.Name = "addon-fluxcd"
+		addonApp.Namespace = velatypes.DefaultKubeVelaNS
+		addonApp.Labels = map[string]string{oam.LabelAddonName: "fluxcd", velatypes.LabelSourceOfTruth: velatypes.FromInner}
+		Expect(cr2ux.shouldSync(ctx, addonApp, false)).Should(BeTrue())
+	})
 })
@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// checkpointFlushInterval how often the checkpoints of the processed objects are persisted, they are batched so the
+// frequent status changes of the objects do not double the writes of the datastore
+const checkpointFlushInterval = time.Second * 10
+
+// checkpoints the resource versions of the objects processed by a sync worker, keyed by the keys of the pool. They
+// are loaded at the start, so the objects unchanged while the server was down are not queued again. The nil
+// checkpoints know nothing, so every object is processed.
+type checkpoints struct {
+	ds     datastore.DataStore
+	worker string
+	// scope the options filtering the objects, the objects skipped by another scope may need the processing now
+	scope string
+
+	mu       sync.Mutex
+	versions map[string]string
+	// dirty the keys processed or deleted since the last flush
+	dirty map[string]bool
+}
+
+// loadCheckpoints loads the checkpoints of the worker persisted in the scope, nothing is skipped if they could not be loaded
+func loadCheckpoints(ctx context.Context, ds datastore.DataStore, worker, scope string) *checkpoints {
+	c := &checkpoints{ds: ds, worker: worker, scope: scope, versions: map[string]string{}, dirty: map[string]bool{}}
+	entities, err := ds.List(ctx, &model.SyncCheckpoint{Worker: worker}, &datastore.ListOptions{})
+	if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		klog.Errorf("failed to load the checkpoints of the %s sync, all objects are synced again: %s", worker, err.Error())
+		return c
+	}
+	for _, entity := range entities {
+		checkpoint := entity.(*model.SyncCheckpoint)
+		if checkpoint.Scope != scope {
+			continue
+		}
+		c.versions[clusterKey(checkpoint.Cluster, checkpoint.Namespace+"/"+checkpoint.Name)] = checkpoint.ResourceVersion
+	}
+	klog.Infof("loaded %d checkpoints of the %s sync", len(c.versions), worker)
+	return c
+}
+
+// known returns true if the object of the key is processed
+func (c *checkpoints) known(key string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.versions[key]
+	return ok
+}
+
+// unchanged returns true if the object of the key is processed at the resource version
+func (c *checkpoints) unchanged(key, resourceVersion string) bool {
+	if c == nil || resourceVersion == "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.versions[key] == resourceVersion
+}
+
+// outdated returns true if the object of the hub cluster is processed before and changed since then
+func (c *checkpoints) outdated(obj interface{}) bool {
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	key := object.GetNamespace() + "/" + object.GetName()
+	return c.known(key) && !c.unchanged(key, object.GetResourceVersion())
+}
+
+// changed counts the objects not processed at their current resource versions
+func (c *checkpoints) changed(cluster string, objs []interface{}) int {
+	count := 0
+	for _, obj := range objs {
+		object, err := meta.Accessor(obj)
+		if err != nil || !c.unchanged(clusterKey(cluster, object.GetNamespace()+"/"+object.GetName()), object.GetResourceVersion()) {
+			count++
+		}
+	}
+	return count
+}
+
+// processed records the object of the key is processed at the resource version
+func (c *checkpoints) processed(key, resourceVersion string) {
+	if c == nil || resourceVersion == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.versions[key] != resourceVersion {
+		c.versions[key] = resourceVersion
+		c.dirty[key] = true
+	}
+}
+
+// forget drops the checkpoint of the deleted object
+func (c *checkpoints) forget(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.versions[key]; ok {
+		delete(c.versions, key)
+		c.dirty[key] = true
+	}
+}
+
+// reset drops the checkpoints once the scope is changed, the checkpoints are saved with the new scope since then
+func (c *checkpoints) reset(scope string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scope = scope
+	c.versions = map[string]string{}
+	c.dirty = map[string]bool{}
+}
+
+// run flushes the checkpoints periodically until the context is done, they are flushed once more at the end
+func (c *checkpoints) run(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	ticker := time.NewTicker(checkpointFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.flush(ctx)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), checkpointFlushInterval)
+			defer cancel()
+			c.flush(flushCtx)
+			return
+		}
+	}
+}
+
+// flush persists the dirty checkpoints, the failed ones are flushed by the next time
+func (c *checkpoints) flush(ctx context.Context) {
+	c.mu.Lock()
+	scope := c.scope
+	dirty := make(map[string]string, len(c.dirty))
+	for key := range c.dirty {
+		dirty[key] = c.versions[key]
+	}
+	c.dirty = map[string]bool{}
+	c.mu.Unlock()
+
+	var failed []string
+	for key, resourceVersion := range dirty {
+		cluster, namespacedName := splitClusterKey(key)
+		namespace, name, _ := cache.SplitMetaNamespaceKey(namespacedName)
+		checkpoint := &model.SyncCheckpoint{Worker: c.worker, Cluster: cluster, Namespace: namespace, Name: name, Scope: scope, ResourceVersion: resourceVersion}
+		var err error
+		if resourceVersion == "" {
+			if err = c.ds.Delete(ctx, checkpoint); errors.Is(err, datastore.ErrRecordNotExist) {
+				err = nil
+			}
+		} else if err = c.ds.Put(ctx, checkpoint); errors.Is(err, datastore.ErrRecordNotExist) {
+			err = c.ds.Add(ctx, checkpoint)
+		}
+		if err != nil {
+			klog.Warningf("failed to save the checkpoint of the %s %s: %s", c.worker, key, err.Error())
+			failed = append(failed, key)
+		}
+	}
+	if len(failed) > 0 {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, key := range failed {
+			c.dirty[key] = true
+		}
+	}
+}
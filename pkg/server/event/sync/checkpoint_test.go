@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+var _ = Describe("Test the checkpoints of the sync workers", func() {
+	It("Test resume from the persisted checkpoints", func() {
+		ctx := context.Background()
+		ds, err := NewDatastore(datastore.Config{Type: "kubeapi", Database: "sync-checkpoint-db-test"})
+		Expect(err).Should(BeNil())
+
+		checkpoints := loadCheckpoints(ctx, ds, "application", "external=true")
+		Expect(checkpoints.unchanged("default/app1", "100")).Should(BeFalse())
+		checkpoints.processed("default/app1", "100")
+		checkpoints.processed("default/app2", "200")
+		checkpoints.processed("prod:default/app1", "300")
+		checkpoints.flush(ctx)
+		Expect(checkpoints.dirty).Should(BeEmpty())
+		Expect(ds.IsExist(ctx, &model.SyncCheckpoint{Worker: "application", Cluster: "prod", Namespace: "default", Name: "app1"})).Should(BeTrue())
+
+		By("the checkpoints are resumed after the restart")
+		resumed := loadCheckpoints(ctx, ds, "application", "external=true")
+		Expect(resumed.unchanged("default/app1", "100")).Should(BeTrue())
+		Expect(resumed.unchanged("prod:default/app1", "300")).Should(BeTrue())
+		Expect(resumed.unchanged("default/app2", "201")).Should(BeFalse())
+		apps := []interface{}{
+			&v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app1", ResourceVersion: "100"}},
+			&v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app2", ResourceVersion: "201"}},
+			&v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app3", ResourceVersion: "1"}},
+		}
+		Expect(resumed.changed("", apps)).Should(Equal(2))
+		Expect(resumed.outdated(apps[0])).Should(BeFalse())
+		Expect(resumed.outdated(apps[1])).Should(BeTrue())
+		Expect(resumed.outdated(apps[2])).Should(BeFalse())
+
+		By("the checkpoints of the deleted objects are deleted")
+		resumed.forget("default/app2")
+		resumed.flush(ctx)
+		Expect(loadCheckpoints(ctx, ds, "application", "external=true").known("default/app2")).Should(BeFalse())
+
+		By("the checkpoints of another scope are ignored")
+		Expect(loadCheckpoints(ctx, ds, "application", "external=false").known("default/app1")).Should(BeFalse())
+
+		By("nothing is skipped without the checkpoints")
+		var none *checkpoints
+		Expect(none.unchanged("default/app1", "100")).Should(BeFalse())
+		Expect(none.changed("", apps)).Should(Equal(3))
+	})
+})
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	pkgmulticluster "github.com/kubevela/pkg/multicluster"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+	dynamicInformer "k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+)
+
+// clusterDiscoveryInterval how often the managed clusters are listed to watch the joined ones and stop watching the detached ones
+const clusterDiscoveryInterval = time.Minute
+
+// clusterClient reads the resources of the managed cluster. The datastore must not share the context routed to the
+// managed cluster, the kubeapi datastore shares the multi-cluster client, so the cluster is set by every request.
+type clusterClient struct {
+	client.Client
+	cluster string
+}
+
+func (c clusterClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return c.Client.Get(multicluster.ContextWithClusterName(ctx, c.cluster), key, obj)
+}
+
+func (c clusterClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.Client.List(multicluster.ContextWithClusterName(ctx, c.cluster), list, opts...)
+}
+
+// clusterConfig the config of the requests proxied to the managed cluster by the cluster gateway
+func clusterConfig(config *rest.Config, cluster string) *rest.Config {
+	conf := rest.CopyConfig(config)
+	conf.Wrap(pkgmulticluster.NewTransportWrapper(pkgmulticluster.ForCluster(cluster)))
+	return conf
+}
+
+// clusterKey the key of the application of the cluster in the pool, the keys of the hub cluster have no cluster prefix
+func clusterKey(cluster, key string) string {
+	if cluster == "" {
+		return key
+	}
+	return cluster + ":" + key
+}
+
+// splitClusterKey returns the cluster and the namespace/name key of the application, the cluster names never contain ":"
+func splitClusterKey(key string) (string, string) {
+	if i := strings.Index(key, ":"); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+// appSources the watched clusters, the hub cluster is keyed by the empty name
+type appSources struct {
+	mu      sync.RWMutex
+	sources map[string]*appSource
+}
+
+func (s *appSources) get(cluster string) *appSource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sources[cluster]
+}
+
+// managedClusters the names of the watched managed clusters
+func (s *appSources) managedClusters() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var clusters []string
+	for cluster := range s.sources {
+		if cluster != "" {
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters
+}
+
+// all the watched clusters
+func (s *appSources) all() []*appSource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := make([]*appSource, 0, len(s.sources))
+	for _, source := range s.sources {
+		all = append(all, source)
+	}
+	return all
+}
+
+func (s *appSources) set(cluster string, source *appSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[cluster] = source
+}
+
+func (s *appSources) remove(cluster string) *appSource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	source := s.sources[cluster]
+	delete(s.sources, cluster)
+	return source
+}
+
+// watchManagedClusters watches the applications of the joined managed clusters until the context is done. The
+// applications of the detached clusters are kept in the datastore, so their workflow records are still browsable.
+func (a *ApplicationSync) watchManagedClusters(ctx context.Context, sources *appSources, pool *workerPool) {
+	ticker := time.NewTicker(clusterDiscoveryInterval)
+	defer ticker.Stop()
+	for {
+		clusters, err := multicluster.ListVirtualClusters(ctx, a.KubeClient)
+		if err != nil {
+			klog.Errorf("failed to list the managed clusters to sync the applications: %s", err.Error())
+		} else {
+			joined := map[string]bool{}
+			for _, cluster := range clusters {
+				// the clusters out of the scope are not watched at all
+				if cluster.Name == multicluster.ClusterLocalName || !a.scope.get().matchCluster(cluster.Name) {
+					continue
+				}
+				joined[cluster.Name] = true
+				if sources.get(cluster.Name) == nil {
+					if err := a.watchManagedCluster(ctx, sources, pool, cluster.Name); err != nil {
+						klog.Errorf("failed to watch the applications of the cluster %s: %s", cluster.Name, err.Error())
+					}
+				}
+			}
+			for _, cluster := range sources.managedClusters() {
+				if !joined[cluster] {
+					if source := sources.remove(cluster); source != nil {
+						source.cancel()
+						klog.Infof("stop syncing the applications of the cluster %s, it is detached or out of the sync scope", cluster)
+					}
+				}
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchManagedCluster starts the informer of the applications of the managed cluster
+func (a *ApplicationSync) watchManagedCluster(ctx context.Context, sources *appSources, pool *workerPool, cluster string) error {
+	dynamicClient, err := dynamic.NewForConfig(clusterConfig(a.KubeConfig, cluster))
+	if err != nil {
+		return err
+	}
+	cu := a.cr2ux(cluster)
+	if err := cu.initCache(ctx); err != nil {
+		return err
+	}
+	sourceCtx, cancel := context.WithCancel(ctx)
+	factory := dynamicInformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, v1.NamespaceAll, nil)
+	source := &appSource{
+		cluster:     cluster,
+		informer:    factory.ForResource(v1beta1.SchemeGroupVersion.WithResource("applications")).Informer(),
+		cu:          cu,
+		cancel:      cancel,
+		checkpoints: a.checkpoints,
+	}
+	source.watch(sourceCtx, pool)
+	sources.set(cluster, source)
+	go source.informer.Run(sourceCtx.Done())
+	klog.Infof("start syncing the applications of the cluster %s", cluster)
+	return nil
+}
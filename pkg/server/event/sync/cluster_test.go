@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+var _ = Describe("Test sync the applications of the managed clusters", func() {
+	It("Test the keys of the applications of the clusters", func() {
+		Expect(clusterKey("", "default/app")).Should(Equal("default/app"))
+		cluster, key := splitClusterKey(clusterKey("cluster-a", "default/app"))
+		Expect(cluster).Should(Equal("cluster-a"))
+		Expect(key).Should(Equal("default/app"))
+		cluster, key = splitClusterKey("default/app")
+		Expect(cluster).Should(Equal(""))
+		Expect(key).Should(Equal("default/app"))
+	})
+
+	It("Test sync the application of the managed cluster", func() {
+		ctx := context.Background()
+		ds, err := NewDatastore(datastore.Config{Type: "kubeapi", Database: "managed-cluster-app-db-test"})
+		Expect(err).Should(BeNil())
+		for _, name := range []string{"managed-cluster-app-db-test", "managed-cluster-app-ns"} {
+			ns := corev1.Namespace{}
+			ns.Name = name
+			Expect(k8sClient.Create(ctx, &ns)).Should(SatisfyAny(BeNil(), &util.AlreadyExistMatcher{}))
+		}
+
+		cr2ux := newCR2UX(ds)
+		cr2ux.cluster = "cluster-a"
+		cr2ux.cli = clusterClient{Client: k8sClient, cluster: "cluster-a"}
+		app := &v1beta1.Application{}
+		Expect(common2.ReadYamlToObject("testdata/test-app1.yaml", app)).Should(BeNil())
+		app.Namespace = "managed-cluster-app-ns"
+		Expect(cr2ux.AddOrUpdate(ctx, app)).Should(BeNil())
+
+		By("the application is named with the cluster and could not be modified")
+		synced := &model.Application{Name: formatClusterAppName(app.Name, app.Namespace, "cluster-a")}
+		Expect(ds.Get(ctx, synced)).Should(BeNil())
+		Expect(synced.GetSyncedCluster()).Should(Equal("cluster-a"))
+		Expect(synced.Labels[model.LabelSyncManagedExternally]).Should(Equal("true"))
+
+		By("the hub cluster does not load the cache of the managed cluster")
+		hub := newCR2UX(ds)
+		Expect(hub.initCache(ctx)).Should(BeNil())
+		_, ok := hub.cache.Load(synced.Name)
+		Expect(ok).Should(BeFalse())
+		managed := newCR2UX(ds)
+		managed.cluster = "cluster-a"
+		Expect(managed.initCache(ctx)).Should(BeNil())
+		_, ok = managed.cache.Load(synced.Name)
+		Expect(ok).Should(BeTrue())
+	})
+})
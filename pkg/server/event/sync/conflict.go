@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// detectConflict flags the application deployed by VelaUX if its CR diverged from the deployed revision, such as
+// edited by kubectl. The conflict is cleared once the CR matches the revision again.
+func (c *CR2UX) detectConflict(ctx context.Context, targetApp *v1beta1.Application) error {
+	appName := targetApp.Annotations[oam.AnnotationAppName]
+	version := targetApp.Annotations[oam.AnnotationDeployVersion]
+	if appName == "" || version == "" {
+		return nil
+	}
+	revision := &model.ApplicationRevision{AppPrimaryKey: appName, Version: version}
+	if err := c.ds.Get(ctx, revision); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil
+		}
+		return err
+	}
+	if revision.ApplyAppConfig == "" {
+		return nil
+	}
+	var deployed v1beta1.Application
+	if err := yaml.Unmarshal([]byte(revision.ApplyAppConfig), &deployed); err != nil {
+		klog.Warningf("failed to decode the revision %s of the application %s: %s", version, appName, err.Error())
+		return nil
+	}
+	diverged, err := specDiverged(deployed.Spec, targetApp.Spec)
+	if err != nil {
+		return err
+	}
+
+	app := &model.Application{Name: appName}
+	if err := c.ds.Get(ctx, app); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil
+		}
+		return err
+	}
+	conflict := app.GetConflict(revision.EnvName)
+	switch {
+	case diverged && conflict == nil:
+		app.Conflicts = append(app.Conflicts, model.ApplicationConflict{EnvName: revision.EnvName, Revision: version, DetectTime: time.Now()})
+		klog.Warningf("the application %s in the env %s is modified outside VelaUX", appName, revision.EnvName)
+	case diverged && conflict.Revision != version:
+		conflict.Revision = version
+		conflict.DetectTime = time.Now()
+	case !diverged && conflict != nil:
+		app.RemoveConflict(revision.EnvName)
+		klog.Infof("the application %s in the env %s matches the deployed revision again", appName, revision.EnvName)
+	default:
+		return nil
+	}
+	return c.ds.Put(ctx, app)
+}
+
+// specDiverged compares the specs by their JSON values, so the order of the keys in the properties does not matter
+func specDiverged(deployed, current v1beta1.ApplicationSpec) (bool, error) {
+	var values [2]interface{}
+	for i, spec := range []v1beta1.ApplicationSpec{deployed, current} {
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return false, err
+		}
+		if err := json.Unmarshal(data, &values[i]); err != nil {
+			return false, err
+		}
+	}
+	return !reflect.DeepEqual(values[0], values[1]), nil
+}
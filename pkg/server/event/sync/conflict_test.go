@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+var _ = Describe("Test detect the conflicts of the applications deployed by VelaUX", func() {
+	It("Test flag and clear the conflict", func() {
+		ctx := context.Background()
+		ds, err := NewDatastore(datastore.Config{Type: "kubeapi", Database: "app-conflict-db-test"})
+		Expect(err).Should(BeNil())
+		newApp := func(properties string) *v1beta1.Application {
+			return &v1beta1.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "conflict-app",
+					Namespace:   "default",
+					Labels:      map[string]string{types.LabelSourceOfTruth: types.FromUX},
+					Annotations: map[string]string{oam.AnnotationAppName: "conflict-app", oam.AnnotationDeployVersion: "v1"},
+				},
+				Spec: v1beta1.ApplicationSpec{Components: []common.ApplicationComponent{{
+					Name: "web", Type: "webservice", Properties: &runtime.RawExtension{Raw: []byte(properties)},
+				}}},
+			}
+		}
+		config, err := yaml.Marshal(newApp(`{"image":"nginx","port":80}`))
+		Expect(err).Should(BeNil())
+		Expect(ds.Add(ctx, &model.Application{Name: "conflict-app", Project: "default"})).Should(BeNil())
+		Expect(ds.Add(ctx, &model.ApplicationRevision{AppPrimaryKey: "conflict-app", Version: "v1", EnvName: "dev", ApplyAppConfig: string(config)})).Should(BeNil())
+		conflicts := func() []model.ApplicationConflict {
+			app := &model.Application{Name: "conflict-app"}
+			Expect(ds.Get(ctx, app)).Should(BeNil())
+			return app.Conflicts
+		}
+		cr2ux := &CR2UX{ds: ds}
+
+		By("the order of the properties does not matter")
+		Expect(cr2ux.AddOrUpdate(ctx, newApp(`{"port":80,"image":"nginx"}`))).Should(BeNil())
+		Expect(conflicts()).Should(BeEmpty())
+
+		By("the application edited by kubectl is flagged")
+		Expect(cr2ux.AddOrUpdate(ctx, newApp(`{"image":"nginx:edited","port":80}`))).Should(BeNil())
+		Expect(conflicts()).Should(HaveLen(1))
+		Expect(conflicts()[0].EnvName).Should(Equal("dev"))
+		Expect(conflicts()[0].Revision).Should(Equal("v1"))
+
+		By("the conflict is cleared once the application matches the revision again")
+		Expect(cr2ux.AddOrUpdate(ctx, newApp(`{"image":"nginx","port":80}`))).Should(BeNil())
+		Expect(conflicts()).Should(BeEmpty())
+	})
+})
@@ -46,7 +46,7 @@ func (c *CR2UX) ConvertApp2DatastoreApp(ctx context.Context, targetApp *v1beta1.
 		Name: model.DefaultInitName,
 	}
 	sourceOfTruth := apitypes.FromCR
-	if _, ok := targetApp.Labels[oam.LabelAddonName]; ok && strings.HasPrefix(targetApp.Name, "addon-") && targetApp.Namespace == apitypes.DefaultKubeVelaNS {
+	if isAddonApplication(targetApp) {
 		project = c.generateSystemProject(ctx, targetApp.Namespace)
 		sourceOfTruth = apitypes.FromInner
 	}
@@ -62,6 +62,15 @@ func (c *CR2UX) ConvertApp2DatastoreApp(ctx context.Context, targetApp *v1beta1.
 			model.LabelSyncRevision:     getRevision(*targetApp),
 			apitypes.LabelSourceOfTruth: sourceOfTruth,
 		},
+		SyncedStatus: convertSyncedStatus(targetApp),
+	}
+	if c.externalReadOnly && sourceOfTruth == apitypes.FromCR {
+		appMeta.Labels[model.LabelSyncManagedExternally] = "true"
+	}
+	if c.cluster != "" {
+		appMeta.Labels[model.LabelSyncCluster] = c.cluster
+		// the applications of the managed clusters could only be modified in their clusters
+		appMeta.Labels[model.LabelSyncManagedExternally] = "true"
 	}
 	appMeta.CreateTime = targetApp.CreationTimestamp.Time
 	appMeta.UpdateTime = time.Now()
@@ -78,7 +87,7 @@ func (c *CR2UX) ConvertApp2DatastoreApp(ctx context.Context, targetApp *v1beta1.
 		return nil, fmt.Errorf("fail to list the targets, %w", err)
 	}
 	var envTargetNames map[string]string
-	dsApp.Targets, envTargetNames = convert.FromCRTargets(ctx, c.cli, targetApp, existTargets, project.Name)
+	dsApp.Targets, envTargetNames = convert.FromCRTargets(ctx, c.cli, targetApp, existTargets, project.Name, c.cluster)
 
 	// 3. generate the environment
 	env, newProject, err := c.generateEnv(ctx, project.Name, targetApp.Namespace, envTargetNames)
@@ -152,6 +161,7 @@ func (c *CR2UX) ConvertApp2DatastoreApp(ctx context.Context, targetApp *v1beta1.
 	}
 	// 8. convert the workflow record
 	if record := convert.FromCRWorkflowRecord(targetApp, *dsApp.Workflow, dsApp.Revision); record != nil {
+		record.Cluster = c.cluster
 		dsApp.Record = record
 	}
 	return dsApp, nil
@@ -234,6 +244,35 @@ func (c *CR2UX) generateEnv(ctx context.Context, defaultProject string, envNames
 	return env, "", nil
 }
 
+// isAddonApplication checks whether the application is installed by an addon
+func isAddonApplication(app *v1beta1.Application) bool {
+	_, ok := app.Labels[oam.LabelAddonName]
+	return ok && strings.HasPrefix(app.Name, "addon-") && app.Namespace == apitypes.DefaultKubeVelaNS
+}
+
+// convertSyncedStatus summarizes the phase and the health of the services of the application
+func convertSyncedStatus(app *v1beta1.Application) *model.SyncedApplicationStatus {
+	status := &model.SyncedApplicationStatus{
+		Phase:      string(app.Status.Phase),
+		Healthy:    true,
+		UpdateTime: time.Now(),
+	}
+	for _, s := range app.Status.Services {
+		if !s.Healthy {
+			status.Healthy = false
+		}
+	}
+	return status
+}
+
+// syncedStatusKey the status without the update time, the unchanged status is not saved again
+func syncedStatusKey(status *model.SyncedApplicationStatus) string {
+	if status == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%t", status.Phase, status.Healthy)
+}
+
 func getRevision(app v1beta1.Application) string {
 	if app.Status.LatestRevision == nil {
 		return ""
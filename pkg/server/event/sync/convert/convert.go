@@ -159,8 +159,9 @@ func FromCRWorkflowStepBase(step workflowv1alpha1.WorkflowStepBase) (*model.Work
 	return base, nil
 }
 
-// FromCRTargets converts deployed Cluster/Namespace from Application CR Status into velaux data store
-func FromCRTargets(ctx context.Context, cli client.Client, targetApp *v1beta1.Application, existTargets []datastore.Entity, project string) ([]*model.Target, map[string]string) {
+// FromCRTargets converts deployed Cluster/Namespace from Application CR Status into velaux data store,
+// the cluster is the managed cluster the application is synced from, it is empty for the hub cluster
+func FromCRTargets(ctx context.Context, cli client.Client, targetApp *v1beta1.Application, existTargets []datastore.Entity, project string, cluster string) ([]*model.Target, map[string]string) {
 	existTarget := make(map[string]*model.Target)
 	for i := range existTargets {
 		t := existTargets[i].(*model.Target)
@@ -179,6 +180,10 @@ func FromCRTargets(ctx context.Context, cli client.Client, targetApp *v1beta1.Ap
 		if placement.Cluster == "" {
 			placement.Cluster = multicluster.ClusterLocalName
 		}
+		// the local cluster of the application synced from a managed cluster is the managed cluster
+		if cluster != "" && placement.Cluster == multicluster.ClusterLocalName {
+			placement.Cluster = cluster
+		}
 		if placement.Namespace == "" {
 			placement.Namespace = targetApp.Namespace
 		}
@@ -24,14 +24,24 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/domain/service"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/cloudevent"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 )
 
 // getApp will return the app and appname if exists
 func (c *CR2UX) getApp(ctx context.Context, name, namespace string) (*model.Application, string, error) {
+	// the applications of the managed clusters are always named with the cluster suffix, so they never occupy the names of the hub ones
+	if c.cluster != "" {
+		app := &model.Application{Name: formatClusterAppName(name, namespace, c.cluster)}
+		if err := c.ds.Get(ctx, app); err != nil {
+			return nil, app.Name, err
+		}
+		return app, app.Name, nil
+	}
 	alreadyCreated := &model.Application{Name: formatAppComposedName(name, namespace)}
 	err1 := c.ds.Get(ctx, alreadyCreated)
 	if err1 == nil {
@@ -61,12 +71,32 @@ type CR2UX struct {
 	applicationService service.ApplicationService
 	targetService      service.TargetService
 	envService         service.EnvService
+	disableExternal    bool
+	externalReadOnly   bool
+	// scope filters the external applications to sync
+	scope *scopeHolder
+	// cloudEvents publishes the deploys of the applications
+	cloudEvents cloudevent.Publisher
+	// cluster the managed cluster the applications are synced from, it is empty for the hub cluster
+	cluster string
 }
 
 func formatAppComposedName(name, namespace string) string {
 	return name + "-" + namespace
 }
 
+func formatClusterAppName(name, namespace, cluster string) string {
+	return formatAppComposedName(name, namespace) + "-" + cluster
+}
+
+// cacheKey the key of the synced application in the cache
+func (c *CR2UX) cacheKey(name, namespace string) string {
+	if c.cluster != "" {
+		return formatClusterAppName(name, namespace, c.cluster)
+	}
+	return formatAppComposedName(name, namespace)
+}
+
 // we need to prevent the case that one app is deleted ant it's name is pure appName, then other app with namespace suffix will be mixed
 func (c *CR2UX) getAppMetaName(ctx context.Context, name, namespace string) string {
 	_, appName, _ := c.getApp(ctx, name, namespace)
@@ -76,8 +106,12 @@ func (c *CR2UX) getAppMetaName(ctx context.Context, name, namespace string) stri
 // AddOrUpdate will sync application CR to storage of VelaUX automatically
 func (c *CR2UX) AddOrUpdate(ctx context.Context, targetApp *v1beta1.Application) error {
 	ds := c.ds
+	// the applications deployed by VelaUX are not synced, but they are checked in case they are modified outside VelaUX
+	if c.cluster == "" && targetApp.Labels[types.LabelSourceOfTruth] == types.FromUX {
+		return c.detectConflict(ctx, targetApp)
+	}
 	if !c.shouldSync(ctx, targetApp, false) {
-		return nil
+		return c.syncStatus(ctx, targetApp)
 	}
 
 	dsApp, err := c.ConvertApp2DatastoreApp(ctx, targetApp)
@@ -123,7 +157,8 @@ func (c *CR2UX) AddOrUpdate(ctx context.Context, targetApp *v1beta1.Application)
 		return err
 	}
 
-	if err = StoreWorkflowRecord(ctx, dsApp, ds); err != nil {
+	deployed, err := StoreWorkflowRecord(ctx, dsApp, ds)
+	if err != nil {
 		klog.Errorf("Store Workflow Record to data store err %v", err)
 		return err
 	}
@@ -132,15 +167,43 @@ func (c *CR2UX) AddOrUpdate(ctx context.Context, targetApp *v1beta1.Application)
 		klog.Errorf("Store App Metadata to data store err %v", err)
 		return err
 	}
+	// a new workflow record means a new revision of the application is deployed
+	if deployed {
+		service.PublishSyncEvent(c.cloudEvents, cloudevent.TypeApplicationDeployed, dsApp.AppMeta, dsApp.Record)
+	}
 
 	// update cache
-	key := formatAppComposedName(targetApp.Name, targetApp.Namespace)
+	key := c.cacheKey(targetApp.Name, targetApp.Namespace)
 	syncedVersion := getSyncedRevision(dsApp.Revision)
-	c.syncCache(key, syncedVersion, int64(len(dsApp.Targets)))
+	c.syncCache(key, syncedVersion, int64(len(dsApp.Targets)), syncedStatusKey(dsApp.AppMeta.SyncedStatus))
 	klog.Infof("application %s/%s revision %s synced successful", targetApp.Name, targetApp.Namespace, syncedVersion)
 	return nil
 }
 
+// syncStatus updates the status of the synced application whose revision is not changed
+func (c *CR2UX) syncStatus(ctx context.Context, targetApp *v1beta1.Application) error {
+	key := c.cacheKey(targetApp.Name, targetApp.Namespace)
+	cachedData, ok := c.cache.Load(key)
+	if !ok {
+		return nil
+	}
+	cd := cachedData.(*cached)
+	status := convertSyncedStatus(targetApp)
+	if cd.status == syncedStatusKey(status) {
+		return nil
+	}
+	app, _, err := c.getApp(ctx, targetApp.Name, targetApp.Namespace)
+	if err != nil {
+		return err
+	}
+	app.SyncedStatus = status
+	if err := c.ds.Put(ctx, app); err != nil {
+		return err
+	}
+	c.syncCache(key, cd.revision, cd.targets, syncedStatusKey(status))
+	return nil
+}
+
 // DeleteApp will delete the application as the CR was deleted
 func (c *CR2UX) DeleteApp(ctx context.Context, targetApp *v1beta1.Application) error {
 	if !c.shouldSync(ctx, targetApp, true) {
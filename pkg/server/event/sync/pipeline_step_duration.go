@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// PipelineStepDurationSync counts the finished pipeline runs into the step duration baselines periodically
+type PipelineStepDurationSync struct {
+	Duration        time.Duration
+	PipelineService service.PipelineService `inject:""`
+}
+
+// Start sync the step durations of the pipelines
+func (p *PipelineStepDurationSync) Start(ctx context.Context, errorChan chan error) {
+	klog.Infof("pipeline step duration syncing worker started")
+	defer klog.Infof("pipeline step duration syncing worker closed")
+	t := time.NewTicker(p.Duration)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := p.PipelineService.SyncStepDurations(ctx); err != nil {
+				klog.Errorf("syncPipelineStepDurationError: %s", err.Error())
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
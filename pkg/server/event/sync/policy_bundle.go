@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicInformer "k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// VelaUXPolicyResource the cluster scoped resource of the VelaUXPolicies
+var VelaUXPolicyResource = schema.GroupVersionResource{Group: "velaux.oam.dev", Version: "v1alpha1", Resource: "velauxpolicies"}
+
+// policyBundleResync the policies are reconciled again periodically, so the records modified in VelaUX are reverted
+const policyBundleResync = time.Minute * 10
+
+// PolicyBundleSync reconciles the VelaUXPolicies into the roles, the permissions and the project members
+type PolicyBundleSync struct {
+	Enabled             bool
+	KubeConfig          *rest.Config                `inject:"kubeConfig"`
+	PolicyBundleService service.PolicyBundleService `inject:""`
+}
+
+// Start watches the VelaUXPolicies if enabled
+func (p *PolicyBundleSync) Start(ctx context.Context, errorChan chan error) {
+	if !p.Enabled {
+		return
+	}
+	dynamicClient, err := dynamic.NewForConfig(p.KubeConfig)
+	if err != nil {
+		errorChan <- err
+		return
+	}
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	factory := dynamicInformer.NewDynamicSharedInformerFactory(dynamicClient, policyBundleResync)
+	informer := factory.ForResource(VelaUXPolicyResource).Informer()
+	enqueue := func(obj interface{}) {
+		if key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err == nil {
+			queue.Add(key)
+		}
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    enqueue,
+		UpdateFunc: func(_, obj interface{}) { enqueue(obj) },
+		DeleteFunc: enqueue,
+	})
+	klog.Infof("velaux policy syncing worker started")
+	defer klog.Infof("velaux policy syncing worker closed")
+	go informer.Run(ctx.Done())
+	go func() {
+		<-ctx.Done()
+		queue.ShutDown()
+	}()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return
+	}
+	for {
+		key, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+		if err := p.reconcile(ctx, dynamicClient, informer, key.(string)); err != nil {
+			klog.Errorf("failed to reconcile the velaux policy %s: %s", key, err.Error())
+			queue.AddRateLimited(key)
+		} else {
+			queue.Forget(key)
+		}
+		queue.Done(key)
+	}
+}
+
+func (p *PolicyBundleSync) reconcile(ctx context.Context, dynamicClient dynamic.Interface, informer cache.SharedIndexInformer, name string) error {
+	obj, exist, err := informer.GetStore().GetByKey(name)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return p.PolicyBundleService.DeletePolicyBundle(ctx, name)
+	}
+	policy, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	var spec service.VelaUXPolicySpec
+	if specObj, ok := policy.Object["spec"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(specObj, &spec); err != nil {
+			return p.updateStatus(ctx, dynamicClient, policy, err)
+		}
+	}
+	applyErr := p.PolicyBundleService.ApplyPolicyBundle(ctx, name, policy.GetGeneration(), spec)
+	if err := p.updateStatus(ctx, dynamicClient, policy, applyErr); err != nil {
+		klog.Warningf("failed to update the status of the velaux policy %s: %s", name, err.Error())
+	}
+	return applyErr
+}
+
+// updateStatus reports the reconciled generation and the error message, it is skipped if nothing changes
+func (p *PolicyBundleSync) updateStatus(ctx context.Context, dynamicClient dynamic.Interface, policy *unstructured.Unstructured, applyErr error) error {
+	message := ""
+	if applyErr != nil {
+		message = applyErr.Error()
+	}
+	observed, _, _ := unstructured.NestedInt64(policy.Object, "status", "observedGeneration")
+	current, _, _ := unstructured.NestedString(policy.Object, "status", "message")
+	if observed == policy.GetGeneration() && current == message {
+		return applyErr
+	}
+	updated := policy.DeepCopy()
+	if err := unstructured.SetNestedField(updated.Object, map[string]interface{}{
+		"observedGeneration": policy.GetGeneration(),
+		"message":            message,
+	}, "status"); err != nil {
+		return err
+	}
+	if _, err := dynamicClient.Resource(VelaUXPolicyResource).UpdateStatus(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	return applyErr
+}
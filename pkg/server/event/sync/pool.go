@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/utils"
+)
+
+const (
+	// syncBackoffBase the delay of the first retry of a failed key, it is doubled by every failure
+	syncBackoffBase = time.Second
+	// syncBackoffMax the max delay of the retries of a failed key
+	syncBackoffMax = time.Minute * 5
+)
+
+// workerPool processes the keys by a bounded number of workers. The keys are queued by their partitions such as the
+// clusters, and a partition takes at most all workers but one, so the keys of a slow or unreachable partition leave a
+// worker to the others. A key is never processed by two workers at the same time, and the failed keys are retried with
+// the exponential backoff.
+type workerPool struct {
+	name      string
+	partition func(key string) string
+	process   func(ctx context.Context, key string) error
+	// warmUp throttles the keys processed after the restart
+	warmUp *utils.WarmUp
+	// tracker reports the backlog and the failures of the keys
+	tracker *utils.SyncTracker
+	slots   chan struct{}
+	// partitionWorkers the max keys of a partition processed at the same time, it is below the workers
+	partitionWorkers int
+
+	ctx    context.Context
+	mu     sync.Mutex
+	queues map[string]workqueue.RateLimitingInterface
+}
+
+// newWorkerPool creates the pool of the workers, the queues are shut down when the context is done. There are 2
+// workers at least, so a partition could not take all of them.
+func newWorkerPool(ctx context.Context, name string, workers int, partition func(key string) string, process func(ctx context.Context, key string) error) *workerPool {
+	if workers < 2 {
+		workers = 2
+	}
+	p := &workerPool{
+		name:             name,
+		partition:        partition,
+		process:          process,
+		slots:            make(chan struct{}, workers),
+		partitionWorkers: workers - 1,
+		ctx:              ctx,
+		queues:           map[string]workqueue.RateLimitingInterface{},
+	}
+	go func() {
+		<-ctx.Done()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for _, queue := range p.queues {
+			queue.ShutDown()
+		}
+	}()
+	return p
+}
+
+// Add queues the key to its partition, the key queued more than once is processed once
+func (p *workerPool) Add(key string) {
+	if queue := p.queue(p.partition(key)); queue != nil {
+		p.tracker.Queued(key)
+		queue.Add(key)
+	}
+}
+
+// queue returns the queue of the partition, the queue and its dispatcher are created at the first key
+func (p *workerPool) queue(partition string) workqueue.RateLimitingInterface {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ctx.Err() != nil {
+		return nil
+	}
+	queue, ok := p.queues[partition]
+	if !ok {
+		queue = workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(syncBackoffBase, syncBackoffMax), p.name+"-"+partition)
+		p.queues[partition] = queue
+		go p.dispatch(queue)
+	}
+	return queue
+}
+
+// dispatch hands the keys of the queue to the free workers, the keys of the partition in process are limited by its own
+// slots before they take the shared ones
+func (p *workerPool) dispatch(queue workqueue.RateLimitingInterface) {
+	partitionSlots := make(chan struct{}, p.partitionWorkers)
+	for {
+		key, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+		select {
+		case partitionSlots <- struct{}{}:
+		case <-p.ctx.Done():
+			queue.Done(key)
+			return
+		}
+		select {
+		case p.slots <- struct{}{}:
+		case <-p.ctx.Done():
+			<-partitionSlots
+			queue.Done(key)
+			return
+		}
+		go func() {
+			defer func() { <-partitionSlots }()
+			defer func() { <-p.slots }()
+			defer queue.Done(key)
+			if err := p.warmUp.Wait(p.ctx); err != nil {
+				return
+			}
+			err := p.process(p.ctx, key.(string))
+			p.warmUp.Done()
+			if err != nil {
+				klog.Errorf("failed to sync the %s %s, retry after the backoff: %s", p.name, key, err.Error())
+				p.tracker.Failed(key.(string), err)
+				queue.AddRateLimited(key)
+				return
+			}
+			p.tracker.Succeeded(key.(string))
+			queue.Forget(key)
+		}()
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test the worker pool", func() {
+	It("Test the workers are bounded and the partitions are apart", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var mu sync.Mutex
+		running, maxRunning := 0, 0
+		var processed []string
+		release := make(chan struct{})
+		pool := newWorkerPool(ctx, "test", 2, func(key string) string {
+			return strings.Split(key, "/")[0]
+		}, func(ctx context.Context, key string) error {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			if strings.HasPrefix(key, "slow/") {
+				<-release
+			}
+			mu.Lock()
+			running--
+			processed = append(processed, key)
+			mu.Unlock()
+			return nil
+		})
+		pool.Add("slow/app-1")
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return running
+		}, 5*time.Second, 10*time.Millisecond).Should(Equal(1))
+
+		By("the keys of the other partitions are not held up by the slow one")
+		for _, key := range []string{"fast/app-1", "fast/app-2", "fast/app-3"} {
+			pool.Add(key)
+		}
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(processed)
+		}, 5*time.Second, 10*time.Millisecond).Should(Equal(3))
+
+		By("a partition takes all workers but one")
+		pool.Add("slow/app-2")
+		pool.Add("slow/app-3")
+		Consistently(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return running
+		}, 500*time.Millisecond, 10*time.Millisecond).Should(Equal(1))
+		close(release)
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(processed)
+		}, 5*time.Second, 10*time.Millisecond).Should(Equal(6))
+		Expect(maxRunning).Should(Equal(2))
+	})
+
+	It("Test the slow partition with more keys than the workers does not stall the others", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var mu sync.Mutex
+		running := map[string]int{}
+		processed := map[string]int{}
+		release := make(chan struct{})
+		defer close(release)
+		pool := newWorkerPool(ctx, "test", 4, func(key string) string {
+			return strings.Split(key, "/")[0]
+		}, func(ctx context.Context, key string) error {
+			partition := strings.Split(key, "/")[0]
+			mu.Lock()
+			running[partition]++
+			mu.Unlock()
+			if partition == "unreachable" {
+				<-release
+			}
+			mu.Lock()
+			running[partition]--
+			processed[partition]++
+			mu.Unlock()
+			return nil
+		})
+		for i := 0; i < 10; i++ {
+			pool.Add("unreachable/app-" + strconv.Itoa(i))
+		}
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return running["unreachable"]
+		}, 5*time.Second, 10*time.Millisecond).Should(Equal(3))
+
+		By("the keys of the other partitions are processed while the slow keys hold their workers")
+		for i := 0; i < 10; i++ {
+			pool.Add("hub/app-" + strconv.Itoa(i))
+			pool.Add("edge/app-" + strconv.Itoa(i))
+		}
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return processed["hub"] + processed["edge"]
+		}, 5*time.Second, 10*time.Millisecond).Should(Equal(20))
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(running["unreachable"]).Should(Equal(3))
+		Expect(processed["unreachable"]).Should(Equal(0))
+	})
+
+	It("Test the failed keys are retried", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var mu sync.Mutex
+		attempts := 0
+		pool := newWorkerPool(ctx, "test", 1, func(key string) string { return localCluster }, func(ctx context.Context, key string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts++
+			if attempts == 1 {
+				return errors.New("the cluster is unreachable")
+			}
+			return nil
+		})
+		pool.Add("default/app")
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return attempts
+		}, 5*time.Second, 100*time.Millisecond).Should(Equal(2))
+		Consistently(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return attempts
+		}, 2*syncBackoffBase, 100*time.Millisecond).Should(Equal(2))
+	})
+})
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+)
+
+// syncScopeReloadInterval how often the scope is reloaded, the scope updated by the API on any replica is applied by then
+const syncScopeReloadInterval = time.Minute
+
+// syncScope filters the external applications to sync, the nil scope matches all
+type syncScope struct {
+	includeNamespaces []string
+	excludeNamespaces []string
+	includeClusters   []string
+	excludeClusters   []string
+	selector          labels.Selector
+	// key identifies the scope, the checkpoints of the applications are saved with it
+	key string
+}
+
+// newSyncScope compiles the scope
+func newSyncScope(scope *model.SyncScope) (*syncScope, error) {
+	selector, err := labels.Parse(scope.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	return &syncScope{
+		includeNamespaces: scope.IncludeNamespaces,
+		excludeNamespaces: scope.ExcludeNamespaces,
+		includeClusters:   scope.IncludeClusters,
+		excludeClusters:   scope.ExcludeClusters,
+		selector:          selector,
+		key: fmt.Sprintf("namespaces=%s!%s,clusters=%s!%s,selector=%s", strings.Join(scope.IncludeNamespaces, ";"), strings.Join(scope.ExcludeNamespaces, ";"),
+			strings.Join(scope.IncludeClusters, ";"), strings.Join(scope.ExcludeClusters, ";"), selector.String()),
+	}, nil
+}
+
+// matchCluster returns true if the applications of the cluster are synced, the hub cluster is the empty name
+func (s *syncScope) matchCluster(cluster string) bool {
+	if s == nil {
+		return true
+	}
+	if cluster == "" {
+		cluster = multicluster.ClusterLocalName
+	}
+	return matchList(s.includeClusters, s.excludeClusters, cluster)
+}
+
+// match returns true if the application of the cluster is synced
+func (s *syncScope) match(cluster, namespace string, appLabels map[string]string) bool {
+	if s == nil {
+		return true
+	}
+	return s.matchCluster(cluster) && matchList(s.includeNamespaces, s.excludeNamespaces, namespace) && s.selector.Matches(labels.Set(appLabels))
+}
+
+// matchList the excluded names never match, and only the included names match if the inclusions are not empty
+func matchList(included, excluded []string, name string) bool {
+	for _, n := range excluded {
+		if n == name {
+			return false
+		}
+	}
+	if len(included) == 0 {
+		return true
+	}
+	for _, n := range included {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeHolder the current scope shared by the syncs of all clusters, it is replaced once the scope is updated by the API
+type scopeHolder struct {
+	value atomic.Value
+}
+
+func (h *scopeHolder) get() *syncScope {
+	if h == nil {
+		return nil
+	}
+	scope, _ := h.value.Load().(*syncScope)
+	return scope
+}
+
+func (h *scopeHolder) set(scope *syncScope) {
+	h.value.Store(scope)
+}
+
+// key the key of the current scope, it is empty if all applications are synced
+func (h *scopeHolder) key() string {
+	if scope := h.get(); scope != nil {
+		return scope.key
+	}
+	return ""
+}
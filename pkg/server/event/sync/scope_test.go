@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+)
+
+var _ = Describe("Test the scope of the sync", func() {
+	It("Test match the clusters, the namespaces and the labels", func() {
+		scope, err := newSyncScope(&model.SyncScope{
+			ExcludeNamespaces: []string{"kube-system"},
+			IncludeClusters:   []string{"local", "prod"},
+			ExcludeClusters:   []string{"prod"},
+			LabelSelector:     "team=payments",
+		})
+		Expect(err).Should(BeNil())
+		payments := map[string]string{"team": "payments"}
+		Expect(scope.matchCluster("")).Should(BeTrue())
+		Expect(scope.matchCluster("prod")).Should(BeFalse())
+		Expect(scope.matchCluster("dev")).Should(BeFalse())
+		Expect(scope.match("", "default", payments)).Should(BeTrue())
+		Expect(scope.match("", "kube-system", payments)).Should(BeFalse())
+		Expect(scope.match("", "default", map[string]string{"team": "orders"})).Should(BeFalse())
+
+		By("the nil scope matches all")
+		var holder *scopeHolder
+		Expect(holder.get().match("prod", "kube-system", nil)).Should(BeTrue())
+		Expect(holder.key()).Should(BeEmpty())
+
+		By("the key changes with the scope")
+		other, err := newSyncScope(&model.SyncScope{LabelSelector: "team=orders"})
+		Expect(err).Should(BeNil())
+		Expect(other.key).ShouldNot(Equal(scope.key))
+
+		_, err = newSyncScope(&model.SyncScope{LabelSelector: "team in (a"})
+		Expect(err).ShouldNot(BeNil())
+	})
+})
@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// SearchIndexSync ships the new audits and step logs to the search index
+type SearchIndexSync struct {
+	Duration      time.Duration
+	SearchService service.SearchService `inject:""`
+}
+
+// Start index the new audits and step logs every duration
+func (s *SearchIndexSync) Start(ctx context.Context, errorChan chan error) {
+	klog.Infof("search index worker started")
+	defer klog.Infof("search index worker closed")
+	ticker := time.NewTicker(s.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.SearchService.IndexIncrementally(ctx); err != nil {
+				klog.Errorf("indexSearchError: %s", err.Error())
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -261,20 +261,23 @@ func StoreWorkflow(ctx context.Context, dsApp *DataStoreApp, ds datastore.DataSt
 	return ds.Add(ctx, dsApp.Workflow)
 }
 
-// StoreWorkflowRecord will sync workflow status to datastore.
-func StoreWorkflowRecord(ctx context.Context, dsApp *DataStoreApp, ds datastore.DataStore) error {
+// StoreWorkflowRecord will sync workflow status to datastore, it returns true if the record is new.
+func StoreWorkflowRecord(ctx context.Context, dsApp *DataStoreApp, ds datastore.DataStore) (bool, error) {
 	if dsApp.Record == nil {
-		return nil
+		return false, nil
 	}
 	records, err := ds.List(ctx, &model.WorkflowRecord{AppPrimaryKey: dsApp.AppMeta.Name, Name: dsApp.Record.Name}, nil)
 	if err == nil && len(records) > 0 {
-		return nil
+		return false, nil
 	}
 	if err != nil {
 		// other database error, return it
-		return err
+		return false, err
+	}
+	if err := ds.Add(ctx, dsApp.Record); err != nil {
+		return false, err
 	}
-	return ds.Add(ctx, dsApp.Record)
+	return true, nil
 }
 
 // StoreApplicationRevision will sync the application revision to datastore.
@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// TriggerDeliverySync purges the expired nonces and rejected deliveries of the application triggers
+type TriggerDeliverySync struct {
+	Duration       time.Duration
+	WebhookService service.WebhookService `inject:""`
+}
+
+// Start purge the trigger deliveries every duration
+func (t *TriggerDeliverySync) Start(ctx context.Context, errorChan chan error) {
+	klog.Infof("trigger delivery worker started")
+	defer klog.Infof("trigger delivery worker closed")
+	ticker := time.NewTicker(t.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.WebhookService.PurgeTriggerDeliveries(ctx); err != nil {
+				klog.Errorf("purgeTriggerDeliveriesError: %s", err.Error())
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -18,7 +18,9 @@ package sync
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/fatih/color"
 	v1 "k8s.io/api/core/v1"
@@ -28,14 +30,15 @@ import (
 	dynamicInformer "k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 
 	"github.com/kubevela/velaux/pkg/server/domain/service"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/cloudevent"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/utils"
 )
 
 // ApplicationSync sync application from cluster to database
@@ -47,7 +50,21 @@ type ApplicationSync struct {
 	ApplicationService service.ApplicationService `inject:""`
 	TargetService      service.TargetService      `inject:""`
 	EnvService         service.EnvService         `inject:""`
-	Queue              workqueue.RateLimitingInterface
+	SyncStatusService  service.SyncStatusService  `inject:""`
+	CloudEvents        cloudevent.Publisher       `inject:"cloudEventPublisher"`
+	// Workers how many applications are synced at the same time
+	Workers int
+	// DisableExternal only the addon applications are synced if it is true
+	DisableExternal bool
+	// ExternalAppsReadOnly the synced external applications could not be modified in VelaUX
+	ExternalAppsReadOnly bool
+	// SyncManagedClusters the applications deployed to the managed clusters directly are synced as the read-only applications
+	SyncManagedClusters bool
+	// WarmUp throttles the sync of the applications listed at the start
+	WarmUp *utils.WarmUp
+
+	checkpoints *checkpoints
+	scope       *scopeHolder
 }
 
 // Start prepares watchers and run their controllers, then waits for process termination signals
@@ -59,19 +76,120 @@ func (a *ApplicationSync) Start(ctx context.Context, errorChan chan error) {
 
 	factory := dynamicInformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, v1.NamespaceAll, nil)
 	informer := factory.ForResource(v1beta1.SchemeGroupVersion.WithResource("applications")).Informer()
-	getApp := func(obj interface{}) *v1beta1.Application {
-		if app, ok := obj.(*v1beta1.Application); ok {
-			return app
+	a.scope = &scopeHolder{}
+	a.reloadScope(ctx)
+	cu := a.cr2ux("")
+	if err = cu.initCache(ctx); err != nil {
+		errorChan <- err
+	}
+	a.checkpoints = loadCheckpoints(ctx, a.Store, service.SyncWorkerApplication, a.checkpointScope())
+	go a.checkpoints.run(ctx)
+	hub := &appSource{informer: informer, cu: cu, checkpoints: a.checkpoints}
+	sources := &appSources{sources: map[string]*appSource{"": hub}}
+
+	pool := newWorkerPool(ctx, "application", a.Workers, func(key string) string {
+		// the applications of a managed cluster are queued by the cluster
+		if cluster, _ := splitClusterKey(key); cluster != "" {
+			return cluster
+		}
+		obj, exist, err := informer.GetStore().GetByKey(key)
+		if err != nil || !exist {
+			return localCluster
+		}
+		return appCluster(obj)
+	}, func(ctx context.Context, poolKey string) error {
+		cluster, key := splitClusterKey(poolKey)
+		source := sources.get(cluster)
+		// the managed cluster is detached
+		if source == nil {
+			return nil
 		}
-		var app v1beta1.Application
-		if object, ok := obj.(*unstructured.Unstructured); ok {
-			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &app); err != nil {
-				klog.Errorf("decode the application failure %s", err.Error())
-				return &app
+		obj, exist, err := source.informer.GetStore().GetByKey(key)
+		if err != nil || !exist {
+			return err
+		}
+		app := getApp(obj)
+		if app.DeletionTimestamp != nil {
+			return nil
+		}
+		if err := source.cu.AddOrUpdate(ctx, app); err != nil {
+			return err
+		}
+		a.checkpoints.processed(poolKey, app.ResourceVersion)
+		return nil
+	})
+	pool.warmUp = a.WarmUp
+	if a.SyncStatusService != nil {
+		pool.tracker = a.SyncStatusService.Tracker(service.SyncWorkerApplication)
+		pool.tracker.Start()
+	}
+
+	hub.watch(ctx, pool)
+	klog.Info("app syncing started")
+	go informer.Run(ctx.Done())
+	if a.SyncManagedClusters {
+		go a.watchManagedClusters(ctx, sources, pool)
+	}
+	go a.watchScope(ctx, sources, pool)
+	if cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		a.WarmUp.SetTotal(a.checkpoints.changed("", informer.GetStore().List()))
+	}
+	<-ctx.Done()
+}
+
+// checkpointScope the options filtering the synced applications, the checkpoints saved with the other options are ignored
+func (a *ApplicationSync) checkpointScope() string {
+	return fmt.Sprintf("external=%t,readonly=%t,%s", !a.DisableExternal, a.ExternalAppsReadOnly, a.scope.key())
+}
+
+// reloadScope loads the scope set by the API or the server config, it returns true if the scope is changed
+func (a *ApplicationSync) reloadScope(ctx context.Context) bool {
+	if a.SyncStatusService == nil {
+		return false
+	}
+	scope, err := a.SyncStatusService.GetEffectiveSyncScope(ctx)
+	if err != nil {
+		klog.Errorf("failed to load the sync scope: %s", err.Error())
+		return false
+	}
+	compiled, err := newSyncScope(scope)
+	if err != nil {
+		klog.Errorf("the sync scope is invalid, keep the current one: %s", err.Error())
+		return false
+	}
+	if current := a.scope.get(); current != nil && current.key == compiled.key {
+		return false
+	}
+	a.scope.set(compiled)
+	return true
+}
+
+// watchScope reloads the scope periodically, all applications are synced again once the scope is changed, so the
+// applications included by the new scope are imported
+func (a *ApplicationSync) watchScope(ctx context.Context, sources *appSources, pool *workerPool) {
+	ticker := time.NewTicker(syncScopeReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !a.reloadScope(ctx) {
+				continue
 			}
+			klog.Infof("the sync scope is changed to %s, sync all applications again", a.scope.key())
+			a.checkpoints.reset(a.checkpointScope())
+			for _, source := range sources.all() {
+				for _, key := range source.informer.GetStore().ListKeys() {
+					pool.Add(clusterKey(source.cluster, key))
+				}
+			}
+		case <-ctx.Done():
+			return
 		}
-		return &app
 	}
+}
+
+// cr2ux creates the CR2UX syncing the applications of the cluster, the cluster is empty for the hub cluster
+func (a *ApplicationSync) cr2ux(cluster string) *CR2UX {
 	cu := &CR2UX{
 		ds:                 a.Store,
 		cli:                a.KubeClient,
@@ -80,29 +198,58 @@ func (a *ApplicationSync) Start(ctx context.Context, errorChan chan error) {
 		applicationService: a.ApplicationService,
 		targetService:      a.TargetService,
 		envService:         a.EnvService,
+		disableExternal:    a.DisableExternal,
+		scope:              a.scope,
+		externalReadOnly:   a.ExternalAppsReadOnly,
+		cloudEvents:        a.CloudEvents,
+		cluster:            cluster,
 	}
-	if err = cu.initCache(ctx); err != nil {
-		errorChan <- err
+	if cluster != "" {
+		cu.cli = clusterClient{Client: a.KubeClient, cluster: cluster}
 	}
+	return cu
+}
 
-	go func() {
-		for {
-			app, down := a.Queue.Get()
-			if down {
-				break
-			}
-			if err := cu.AddOrUpdate(ctx, app.(*v1beta1.Application)); err != nil {
-				klog.Errorf("fail to add or update application %s", err.Error())
-			}
-			a.Queue.Done(app)
+func getApp(obj interface{}) *v1beta1.Application {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if app, ok := obj.(*v1beta1.Application); ok {
+		return app
+	}
+	var app v1beta1.Application
+	if object, ok := obj.(*unstructured.Unstructured); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &app); err != nil {
+			klog.Errorf("decode the application failure %s", err.Error())
+			return &app
 		}
-	}()
+	}
+	return &app
+}
+
+// appSource the informer of the applications of a cluster and the CR2UX syncing them
+type appSource struct {
+	cluster  string
+	informer cache.SharedIndexInformer
+	cu       *CR2UX
+	// checkpoints the applications processed at their current versions are not queued
+	checkpoints *checkpoints
+	// cancel stops the informer of the managed cluster
+	cancel context.CancelFunc
+}
 
+// watch queues the added and updated applications to the pool, the deleted ones are deleted from the datastore at once
+func (s *appSource) watch(ctx context.Context, pool *workerPool) {
 	addOrUpdateHandler := func(obj interface{}) {
 		app := getApp(obj)
 		if app.DeletionTimestamp == nil {
-			a.Queue.Add(app)
-			klog.V(4).Infof("watched update/add app event, namespace: %s, name: %s", app.Namespace, app.Name)
+			key := clusterKey(s.cluster, app.Namespace+"/"+app.Name)
+			if s.checkpoints.unchanged(key, app.ResourceVersion) {
+				klog.V(5).Infof("app %s is synced at the resource version %s, ignore the event", key, app.ResourceVersion)
+				return
+			}
+			pool.Add(key)
+			klog.V(4).Infof("watched update/add app event, cluster: %s, namespace: %s, name: %s", s.cluster, app.Namespace, app.Name)
 		}
 	}
 
@@ -115,17 +262,34 @@ func (a *ApplicationSync) Start(ctx context.Context, errorChan chan error) {
 		},
 		DeleteFunc: func(obj interface{}) {
 			app := getApp(obj)
-			klog.V(4).Infof("watched delete app event, namespace: %s, name: %s", app.Namespace, app.Name)
-			a.Queue.Forget(app)
-			a.Queue.Done(app)
-			err = cu.DeleteApp(ctx, app)
-			if err != nil {
-				klog.Errorf("Application %-30s Deleted Sync to db err %v", color.WhiteString(app.Namespace+"/"+app.Name), err)
+			klog.V(4).Infof("watched delete app event, cluster: %s, namespace: %s, name: %s", s.cluster, app.Namespace, app.Name)
+			if err := s.cu.DeleteApp(ctx, app); err != nil {
+				klog.Errorf("Application %-30s Deleted Sync to db err %v", color.WhiteString(clusterKey(s.cluster, app.Namespace+"/"+app.Name)), err)
+				return
 			}
+			s.checkpoints.forget(clusterKey(s.cluster, app.Namespace+"/"+app.Name))
 			klog.Infof("delete the application (%s/%s) metadata successfully", app.Namespace, app.Name)
 		},
 	}
-	informer.AddEventHandler(handlers)
-	klog.Info("app syncing started")
-	informer.Run(ctx.Done())
+	s.informer.AddEventHandler(handlers)
+}
+
+// localCluster the partition of the applications deployed to the control plane cluster only
+const localCluster = "local"
+
+// appCluster the cluster of the first applied resource of the application, the applications are queued by their clusters
+func appCluster(obj interface{}) string {
+	object, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return localCluster
+	}
+	resources, _, _ := unstructured.NestedSlice(object.Object, "status", "appliedResources")
+	for _, resource := range resources {
+		if r, ok := resource.(map[string]interface{}); ok {
+			if cluster, ok := r["cluster"].(string); ok && cluster != "" {
+				return cluster
+			}
+		}
+	}
+	return localCluster
 }
@@ -18,33 +18,211 @@ package sync
 
 import (
 	"context"
+	"reflect"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicInformer "k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
 
 	"github.com/kubevela/velaux/pkg/server/domain/service"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/utils"
 )
 
-// WorkflowRecordSync sync workflow record from cluster to database
+// WorkflowRecordSync sync workflow record from cluster to database. The records of an application are synced when
+// the application or its WorkflowRuns are changed, and all unfinished records are synced periodically in case a change is missed.
 type WorkflowRecordSync struct {
-	Duration        time.Duration
-	WorkflowService service.WorkflowService `inject:""`
+	// Duration the interval of the periodic sync of all unfinished records
+	Duration time.Duration
+	// WarmUp throttles the first sync after the restart
+	WarmUp *utils.WarmUp
+	// Workers how many applications are synced at the same time
+	Workers           int
+	KubeClient        client.Client             `inject:"kubeClient"`
+	KubeConfig        *rest.Config              `inject:"kubeConfig"`
+	Store             datastore.DataStore       `inject:"datastore"`
+	WorkflowService   service.WorkflowService   `inject:""`
+	SyncStatusService service.SyncStatusService `inject:""`
 }
 
 // Start sync workflow record data
 func (w *WorkflowRecordSync) Start(ctx context.Context, errorChan chan error) {
+	dynamicClient, err := dynamic.NewForConfig(w.KubeConfig)
+	if err != nil {
+		errorChan <- err
+		return
+	}
 	klog.Infof("workflow record syncing worker started")
 	defer klog.Infof("workflow record syncing worker closed")
+
+	factory := dynamicInformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, v1.NamespaceAll, nil)
+	appInformer := factory.ForResource(v1beta1.SchemeGroupVersion.WithResource("applications")).Informer()
+	// the records of an application are synced by one worker at a time, no matter the application is changed or resynced
+	checkpoints := loadCheckpoints(ctx, w.Store, service.SyncWorkerWorkflowRecord, "")
+	go checkpoints.run(ctx)
+	pool := newWorkerPool(ctx, "workflow records", w.Workers, func(key string) string {
+		obj, exist, err := appInformer.GetStore().GetByKey(key)
+		if err != nil || !exist {
+			return localCluster
+		}
+		return appCluster(obj)
+	}, func(ctx context.Context, key string) error {
+		namespace, name, _ := cache.SplitMetaNamespaceKey(key)
+		if err := w.WorkflowService.SyncApplicationWorkflowRecords(ctx, namespace, name); err != nil {
+			return err
+		}
+		if obj, exist, err := appInformer.GetStore().GetByKey(key); err == nil && exist {
+			if app, err := meta.Accessor(obj); err == nil {
+				checkpoints.processed(key, app.GetResourceVersion())
+			}
+		} else {
+			checkpoints.forget(key)
+		}
+		return nil
+	})
+	pool.warmUp = w.WarmUp
+	if w.SyncStatusService != nil {
+		pool.tracker = w.SyncStatusService.Tracker(service.SyncWorkerWorkflowRecord)
+		pool.tracker.Start()
+	}
+	appInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			// the applications existing at the start are synced by the first sync of all records, unless they are
+			// changed since they were synced before the restart
+			if appInformer.HasSynced() || checkpoints.outdated(obj) {
+				enqueueObject(pool, obj)
+			}
+		},
+		UpdateFunc: func(oldObj, obj interface{}) {
+			if workflowChanged(oldObj, obj) {
+				enqueueObject(pool, obj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			enqueueObject(pool, obj)
+		},
+	})
+	synced := []cache.InformerSynced{appInformer.HasSynced}
+	// the WorkflowRuns are watched only if the CRD is installed
+	if _, err := w.KubeClient.RESTMapper().RESTMapping(workflowv1alpha1.WorkflowRunGroupVersionKind.GroupKind(), workflowv1alpha1.Version); err == nil {
+		runInformer := factory.ForResource(workflowv1alpha1.SchemeGroupVersion.WithResource("workflowruns")).Informer()
+		enqueueRun := func(obj interface{}) {
+			if key := runAppKey(obj); key != "" {
+				pool.Add(key)
+			}
+		}
+		runInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if runInformer.HasSynced() {
+					enqueueRun(obj)
+				}
+			},
+			UpdateFunc: func(_, obj interface{}) { enqueueRun(obj) },
+			DeleteFunc: enqueueRun,
+		})
+		synced = append(synced, runInformer.HasSynced)
+	} else {
+		klog.Infof("the WorkflowRuns are not watched: %s", err.Error())
+	}
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return
+	}
+
 	t := time.NewTicker(w.Duration)
 	defer t.Stop()
+	first := true
 	for {
+		apps, err := w.WorkflowService.ListUnfinishedRecordApplications(ctx)
+		if err != nil {
+			klog.Errorf("syncWorkflowRecordError: %s", err.Error())
+		} else {
+			if first {
+				w.WarmUp.SetTotal(warmUpTotal(apps, appInformer.GetStore().List(), checkpoints))
+				first = false
+			}
+			for _, app := range apps {
+				pool.Add(app.String())
+			}
+		}
 		select {
 		case <-t.C:
-			if err := w.WorkflowService.SyncWorkflowRecord(ctx); err != nil {
-				klog.Errorf("syncWorkflowRecordError: %s", err.Error())
-			}
 		case <-ctx.Done():
 			return
 		}
 	}
 }
+
+// warmUpTotal counts the applications queued at the start, the ones of the unfinished records and the ones changed
+// since the last sync
+func warmUpTotal(apps []types.NamespacedName, objs []interface{}, checkpoints *checkpoints) int {
+	keys := map[string]bool{}
+	for _, app := range apps {
+		keys[app.String()] = true
+	}
+	for _, obj := range objs {
+		if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil && checkpoints.outdated(obj) {
+			keys[key] = true
+		}
+	}
+	return len(keys)
+}
+
+func enqueueObject(pool *workerPool, obj interface{}) {
+	if key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err == nil {
+		pool.Add(key)
+	}
+}
+
+// workflowChanged returns true if the workflow status or the published version of the application is changed,
+// the other changes such as the health of the services do not change the records
+func workflowChanged(oldObj, obj interface{}) bool {
+	old, ok := oldObj.(*unstructured.Unstructured)
+	if !ok {
+		return true
+	}
+	current, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return true
+	}
+	for _, fields := range [][]string{{"status", "workflow"}, {"status", "observedGeneration"}} {
+		oldValue, _, _ := unstructured.NestedFieldNoCopy(old.Object, fields...)
+		value, _, _ := unstructured.NestedFieldNoCopy(current.Object, fields...)
+		if !reflect.DeepEqual(oldValue, value) {
+			return true
+		}
+	}
+	return old.GetAnnotations()[oam.AnnotationPublishVersion] != current.GetAnnotations()[oam.AnnotationPublishVersion]
+}
+
+// runAppKey the key of the application of the WorkflowRun, the application is the owner or the one of the app name label
+func runAppKey(obj interface{}) string {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	run, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return ""
+	}
+	for _, owner := range run.GetOwnerReferences() {
+		if owner.Kind == v1beta1.ApplicationKind {
+			return run.GetNamespace() + "/" + owner.Name
+		}
+	}
+	if name := run.GetLabels()[oam.LabelAppName]; name != "" {
+		return run.GetNamespace() + "/" + name
+	}
+	return ""
+}
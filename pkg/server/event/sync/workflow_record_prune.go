@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// WorkflowRecordPruneSync prunes the finished workflow records out of the retention policies of the projects
+type WorkflowRecordPruneSync struct {
+	Duration        time.Duration
+	WorkflowService service.WorkflowService `inject:""`
+}
+
+// Start prune the workflow records every duration
+func (w *WorkflowRecordPruneSync) Start(ctx context.Context, errorChan chan error) {
+	klog.Infof("workflow record pruning worker started")
+	defer klog.Infof("workflow record pruning worker closed")
+	ticker := time.NewTicker(w.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.WorkflowService.PruneWorkflowRecords(ctx); err != nil {
+				klog.Errorf("pruneWorkflowRecordError: %s", err.Error())
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var _ = Describe("Test the watch of the workflow records", func() {
+	It("Test the changes of the application workflows", func() {
+		app := &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"observedGeneration": int64(1),
+				"workflow":           map[string]interface{}{"appRevision": "app-v1", "finished": false},
+			},
+		}}
+		app.SetAnnotations(map[string]string{oam.AnnotationPublishVersion: "workflow-v1"})
+
+		By("the health of the services does not change the records")
+		healthy := app.DeepCopy()
+		Expect(unstructured.SetNestedField(healthy.Object, "running", "status", "status")).Should(Succeed())
+		Expect(workflowChanged(app, healthy)).Should(BeFalse())
+
+		By("the workflow status changes the records")
+		finished := app.DeepCopy()
+		Expect(unstructured.SetNestedField(finished.Object, true, "status", "workflow", "finished")).Should(Succeed())
+		Expect(workflowChanged(app, finished)).Should(BeTrue())
+
+		By("the published version changes the records")
+		published := app.DeepCopy()
+		published.SetAnnotations(map[string]string{oam.AnnotationPublishVersion: "workflow-v2"})
+		Expect(workflowChanged(app, published)).Should(BeTrue())
+	})
+
+	It("Test the applications of the WorkflowRuns", func() {
+		run := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		run.SetNamespace("default")
+		run.SetName("run")
+		Expect(runAppKey(run)).Should(BeEmpty())
+
+		run.SetLabels(map[string]string{oam.LabelAppName: "labeled"})
+		Expect(runAppKey(run)).Should(Equal("default/labeled"))
+
+		run.SetOwnerReferences([]metav1.OwnerReference{{Kind: v1beta1.ApplicationKind, Name: "owner"}})
+		Expect(runAppKey(run)).Should(Equal("default/owner"))
+		Expect(runAppKey(cache.DeletedFinalStateUnknown{Key: "default/run", Obj: run})).Should(Equal("default/owner"))
+	})
+})
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changefeed
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// ActionCreate means the resource is created
+	ActionCreate = "create"
+	// ActionUpdate means the resource is updated
+	ActionUpdate = "update"
+	// ActionDelete means the resource is deleted
+	ActionDelete = "delete"
+)
+
+// Event is a change of a resource made by an API request
+type Event struct {
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+	// Project is empty for the platform level resources
+	Project  string    `json:"project,omitempty"`
+	Action   string    `json:"action"`
+	Operator string    `json:"operator,omitempty"`
+	Time     time.Time `json:"time"`
+	// Org the tenant of the change, the subscribers of the other tenants never receive it
+	Org string `json:"-"`
+}
+
+// Feed fans the change events out to the subscribers, the events are dropped for the slow subscribers
+type Feed struct {
+	mutex       sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+// New creates an empty change feed
+func New() *Feed {
+	return &Feed{subscribers: map[chan Event]struct{}{}}
+}
+
+// Publish sends the event to all subscribers without blocking, it does nothing on a nil feed
+func (f *Feed) Publish(event Event) {
+	if f == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	for ch := range f.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns the channel of the events and the function to cancel the subscription
+func (f *Feed) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	f.mutex.Lock()
+	f.subscribers[ch] = struct{}{}
+	f.mutex.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			f.mutex.Lock()
+			delete(f.subscribers, ch)
+			f.mutex.Unlock()
+			close(ch)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changefeed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeed(t *testing.T) {
+	var nilFeed *Feed
+	nilFeed.Publish(Event{Resource: "application", Name: "app"})
+
+	feed := New()
+	events, cancel := feed.Subscribe(1)
+	feed.Publish(Event{Resource: "application", Name: "app", Action: ActionCreate})
+	// the event is dropped because the buffer is full
+	feed.Publish(Event{Resource: "application", Name: "app", Action: ActionUpdate})
+
+	event := <-events
+	assert.Equal(t, ActionCreate, event.Action)
+	assert.False(t, event.Time.IsZero())
+
+	cancel()
+	cancel()
+	_, ok := <-events
+	assert.False(t, ok)
+	feed.Publish(Event{Resource: "application", Name: "app", Action: ActionDelete})
+}
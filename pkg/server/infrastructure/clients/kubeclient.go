@@ -32,6 +32,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 
 	apiConfig "github.com/kubevela/velaux/pkg/server/config"
+	"github.com/kubevela/velaux/pkg/server/utils"
 )
 
 var kubeClient client.Client
@@ -51,6 +52,7 @@ func setKubeConfig(conf *rest.Config) (err error) {
 	}
 	kubeConfig = conf
 	kubeConfig.Wrap(auth.NewImpersonatingRoundTripper)
+	kubeConfig.Wrap(utils.NewRequestIDRoundTripper)
 	return nil
 }
 
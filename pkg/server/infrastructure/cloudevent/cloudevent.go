@@ -0,0 +1,317 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudevent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+)
+
+// SpecVersion the version of the CloudEvents specification the events follow
+const SpecVersion = "1.0"
+
+// DefaultSource the source attribute of the events if it is not configured
+const DefaultSource = "/velaux"
+
+const (
+	// TypeApplicationDeployed a new revision of the application is deployed
+	TypeApplicationDeployed = "dev.kubevela.velaux.application.deployed"
+	// TypeWorkflowCompleted the workflow of the application succeeded
+	TypeWorkflowCompleted = "dev.kubevela.velaux.workflow.completed"
+	// TypeWorkflowFailed the workflow of the application failed
+	TypeWorkflowFailed = "dev.kubevela.velaux.workflow.failed"
+	// TypeWorkflowTerminated the workflow of the application is terminated
+	TypeWorkflowTerminated = "dev.kubevela.velaux.workflow.terminated"
+)
+
+// queueSize the count of the events waiting to be published, the new events are dropped if the queue is full
+const queueSize = 1024
+
+// Event is the CloudEvent in the structured JSON format
+type Event struct {
+	SpecVersion string `json:"specversion"`
+	// ID identifies the event with the source, the consumers could drop the duplicated events by it
+	ID      string    `json:"id"`
+	Source  string    `json:"source"`
+	Type    string    `json:"type"`
+	Subject string    `json:"subject,omitempty"`
+	Time    time.Time `json:"time"`
+	// DataContentType is always application/json
+	DataContentType string      `json:"datacontenttype,omitempty"`
+	Data            interface{} `json:"data,omitempty"`
+}
+
+// Config the sinks to publish the events to
+type Config struct {
+	// Sinks the URLs of the sinks, the http(s)://host/path ones receive the events by the HTTP POST requests,
+	// the nats://host:port/subject ones receive the events by the NATS subject, and the kafka+http(s)://host/topic
+	// ones receive the events by the Kafka topic through the Kafka REST proxy
+	Sinks []string
+	// Source the source attribute of the events, it identifies the VelaUX instance
+	Source string
+}
+
+// Validate checks the sinks
+func (c Config) Validate() error {
+	for _, sink := range c.Sinks {
+		if _, err := newSink(sink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Publisher publishes the events to the configured sinks
+type Publisher interface {
+	// Enabled returns false if no sink is configured
+	Enabled() bool
+	// Publish queues the event to publish, it never blocks the caller
+	Publish(event Event)
+}
+
+// New creates the publisher, the events are dropped if no sink is configured
+func New(cfg Config) Publisher {
+	var sinks []sink
+	for _, address := range cfg.Sinks {
+		s, err := newSink(address)
+		if err != nil {
+			klog.Errorf("failed to init the sink of the events: %s", err.Error())
+			continue
+		}
+		sinks = append(sinks, s)
+	}
+	if len(sinks) == 0 {
+		return disabledPublisher{}
+	}
+	if cfg.Source == "" {
+		cfg.Source = DefaultSource
+	}
+	p := &asyncPublisher{source: cfg.Source, sinks: sinks, queue: make(chan Event, queueSize)}
+	go p.run()
+	return p
+}
+
+type disabledPublisher struct{}
+
+func (disabledPublisher) Enabled() bool {
+	return false
+}
+
+func (disabledPublisher) Publish(_ Event) {}
+
+type sink interface {
+	name() string
+	send(ctx context.Context, event Event, body []byte) error
+}
+
+func newSink(address string) (sink, error) {
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("the event sink must be a http(s), nats or kafka+http(s) URL, got %s", address)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch u.Scheme {
+	case "http", "https":
+		return &httpSink{url: address, client: client}, nil
+	case "nats":
+		subject := strings.Trim(u.Path, "/")
+		if subject == "" || strings.ContainsAny(subject, " \t/") {
+			return nil, fmt.Errorf("the NATS event sink must be in the format of nats://host:port/subject, got %s", address)
+		}
+		s := &natsSink{address: u.Host, subject: subject}
+		if u.User != nil {
+			s.user = u.User.Username()
+			s.password, _ = u.User.Password()
+		}
+		return s, nil
+	case "kafka+http", "kafka+https":
+		topic := strings.Trim(u.Path, "/")
+		if topic == "" || strings.Contains(topic, "/") {
+			return nil, fmt.Errorf("the Kafka event sink must be in the format of kafka+http(s)://rest-proxy/topic, got %s", address)
+		}
+		proxy := strings.TrimPrefix(u.Scheme, "kafka+") + "://" + u.Host
+		return &kafkaSink{url: proxy + "/topics/" + url.PathEscape(topic), client: client}, nil
+	default:
+		return nil, fmt.Errorf("the event sink must be a http(s), nats or kafka+http(s) URL, got %s", address)
+	}
+}
+
+type asyncPublisher struct {
+	source string
+	sinks  []sink
+	queue  chan Event
+}
+
+func (p *asyncPublisher) Enabled() bool {
+	return true
+}
+
+func (p *asyncPublisher) Publish(event Event) {
+	event.SpecVersion = SpecVersion
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Source == "" {
+		event.Source = p.source
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Data != nil {
+		event.DataContentType = "application/json"
+	}
+	select {
+	case p.queue <- event:
+	default:
+		klog.Warningf("the event queue is full, the %s event of %s is dropped", event.Type, event.Subject)
+	}
+}
+
+func (p *asyncPublisher) run() {
+	for event := range p.queue {
+		body, err := json.Marshal(event)
+		if err != nil {
+			klog.Errorf("failed to marshal the %s event: %s", event.Type, err.Error())
+			continue
+		}
+		for _, s := range p.sinks {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := s.send(ctx, event, body); err != nil {
+				klog.Errorf("failed to publish the %s event of %s to %s: %s", event.Type, event.Subject, s.name(), err.Error())
+			}
+			cancel()
+		}
+	}
+}
+
+// httpSink posts the events in the structured content mode of the HTTP protocol binding
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (h *httpSink) name() string {
+	return h.url
+}
+
+func (h *httpSink) send(ctx context.Context, _ Event, body []byte) error {
+	return post(ctx, h.client, h.url, "application/cloudevents+json; charset=UTF-8", body)
+}
+
+// kafkaSink produces the events to the topic by the v2 API of the Kafka REST proxy, the subject is the key of the records
+type kafkaSink struct {
+	url    string
+	client *http.Client
+}
+
+func (k *kafkaSink) name() string {
+	return k.url
+}
+
+func (k *kafkaSink) send(ctx context.Context, event Event, body []byte) error {
+	type record struct {
+		Key   string          `json:"key,omitempty"`
+		Value json.RawMessage `json:"value"`
+	}
+	records, err := json.Marshal(map[string][]record{"records": {{Key: event.Subject, Value: body}}})
+	if err != nil {
+		return err
+	}
+	return post(ctx, k.client, k.url, "application/vnd.kafka.json.v2+json", records)
+}
+
+func post(ctx context.Context, client *http.Client, target, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("the sink responds %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// natsSink publishes the events to the subject by the NATS client protocol. A connection is dialed for every event,
+// the events are rare and the idle connections are closed by the server if the pings are not answered.
+type natsSink struct {
+	address  string
+	subject  string
+	user     string
+	password string
+}
+
+func (n *natsSink) name() string {
+	return "nats://" + n.address + "/" + n.subject
+}
+
+func (n *natsSink) send(ctx context.Context, _ Event, body []byte) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", n.address)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	reader := bufio.NewReader(conn)
+	// the server greets with the INFO message
+	if _, err := reader.ReadString('\n'); err != nil {
+		return err
+	}
+	options, err := json.Marshal(map[string]interface{}{"verbose": false, "pedantic": false, "name": "velaux", "user": n.user, "pass": n.password})
+	if err != nil {
+		return err
+	}
+	// the PING makes the server answer a PONG after the message is processed, or an error if it is rejected
+	msg := fmt.Sprintf("CONNECT %s\r\nPUB %s %d\r\n%s\r\nPING\r\n", options, n.subject, len(body), body)
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("the NATS server responds %s", line)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudevent
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishToHTTPAndKafka(t *testing.T) {
+	type request struct {
+		path        string
+		contentType string
+		body        []byte
+	}
+	received := make(chan request, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{path: r.URL.Path, contentType: r.Header.Get("Content-Type"), body: body}
+	}))
+	defer server.Close()
+
+	publisher := New(Config{Sinks: []string{server.URL + "/events", "kafka+" + server.URL + "/deploys"}})
+	assert.True(t, publisher.Enabled())
+	publisher.Publish(Event{Type: TypeWorkflowFailed, Subject: "payments", Data: map[string]string{"status": "failed"}})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case req := <-received:
+			var event Event
+			switch req.path {
+			case "/events":
+				assert.Equal(t, "application/cloudevents+json; charset=UTF-8", req.contentType)
+				assert.NoError(t, json.Unmarshal(req.body, &event))
+			case "/topics/deploys":
+				assert.Equal(t, "application/vnd.kafka.json.v2+json", req.contentType)
+				var records struct {
+					Records []struct {
+						Key   string `json:"key"`
+						Value Event  `json:"value"`
+					} `json:"records"`
+				}
+				assert.NoError(t, json.Unmarshal(req.body, &records))
+				assert.Equal(t, "payments", records.Records[0].Key)
+				event = records.Records[0].Value
+			default:
+				t.Fatalf("unexpected request to %s", req.path)
+			}
+			assert.Equal(t, SpecVersion, event.SpecVersion)
+			assert.Equal(t, DefaultSource, event.Source)
+			assert.Equal(t, TypeWorkflowFailed, event.Type)
+			assert.Equal(t, "application/json", event.DataContentType)
+			assert.NotEmpty(t, event.ID)
+			assert.False(t, event.Time.IsZero())
+		case <-time.After(5 * time.Second):
+			t.Fatal("the event is not published")
+		}
+	}
+}
+
+func TestPublishToNATS(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+	published := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		//nolint:errcheck
+		defer conn.Close()
+		_, _ = conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		reader := bufio.NewReader(conn)
+		var connect string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "CONNECT "):
+				connect = line
+			case strings.HasPrefix(line, "PUB "):
+				fields := strings.Fields(line)
+				size, _ := strconv.Atoi(fields[2])
+				payload := make([]byte, size+2)
+				_, _ = io.ReadFull(reader, payload)
+				published <- fields[1] + " " + connect + string(payload[:size])
+			case strings.HasPrefix(line, "PING"):
+				_, _ = conn.Write([]byte("PONG\r\n"))
+				return
+			}
+		}
+	}()
+
+	publisher := New(Config{Sinks: []string{"nats://velaux:secret@" + listener.Addr().String() + "/velaux.deploys"}, Source: "/velaux/prod"})
+	publisher.Publish(Event{Type: TypeApplicationDeployed, Subject: "payments"})
+	select {
+	case msg := <-published:
+		assert.True(t, strings.HasPrefix(msg, "velaux.deploys "), msg)
+		assert.Contains(t, msg, `"user":"velaux"`)
+		assert.Contains(t, msg, `"source":"/velaux/prod"`)
+		assert.Contains(t, msg, `"type":"`+TypeApplicationDeployed+`"`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event is not published")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Sinks: []string{"https://tracker.example.com/events", "nats://nats:4222/velaux.events", "kafka+http://rest-proxy:8082/velaux-events"}}.Validate())
+	assert.Error(t, Config{Sinks: []string{"tracker.example.com"}}.Validate())
+	assert.Error(t, Config{Sinks: []string{"nats://nats:4222"}}.Validate())
+	assert.Error(t, Config{Sinks: []string{"kafka://kafka:9092/velaux-events"}}.Validate())
+
+	disabled := New(Config{})
+	assert.False(t, disabled.Enabled())
+	disabled.Publish(Event{Type: TypeApplicationDeployed})
+}
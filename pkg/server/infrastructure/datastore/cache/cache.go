@@ -0,0 +1,358 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+const (
+	// TypeMemory caches the entities in the in-process LRU, the writes of the other replicas are only seen after the TTL
+	TypeMemory = "memory"
+	// TypeRedis caches the entities in Redis, the cache is shared and invalidated by all replicas
+	TypeRedis = "redis"
+
+	keyPrefix = "velaux:cache:"
+)
+
+// DefaultTables the tables of the users, the projects and the permissions, they are read by every request
+var DefaultTables = []string{"vela_user", "vela_project", "vela_project_user", "vela_role", "vela_perm"}
+
+// Config the read-through cache of the datastore
+type Config struct {
+	// Type option values: memory, redis. The datastore is not cached if it is empty.
+	Type string
+	// Tables the tables to cache, each one is <table> or <table>=<ttl> to override the default TTL, such as vela_user=30s
+	Tables []string
+	// TTL the default time to live of the cached entities and lists
+	TTL time.Duration
+	// Size the max number of the cached entries of the memory cache
+	Size int
+	// RedisAddr the address of Redis, such as redis:6379
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+}
+
+// Backend stores the cached values
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Incr increases the counter and returns the new value, the counters never expire
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// ParseTables parses the tables of the config to the TTLs of the tables
+func ParseTables(tables []string, ttl time.Duration) (map[string]time.Duration, error) {
+	res := make(map[string]time.Duration, len(tables))
+	for _, table := range tables {
+		name, value, found := strings.Cut(strings.TrimSpace(table), "=")
+		if name == "" {
+			continue
+		}
+		res[name] = ttl
+		if found {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("the TTL of the cached table %s must be a positive duration, got %s", name, value)
+			}
+			res[name] = d
+		}
+	}
+	return res, nil
+}
+
+// New creates the backend of the config, nil is returned if the cache is disabled
+func New(cfg Config) (Backend, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case TypeMemory:
+		return newMemoryBackend(cfg.Size), nil
+	case TypeRedis:
+		return newRedisBackend(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB), nil
+	default:
+		return nil, fmt.Errorf("not support cache type %s", cfg.Type)
+	}
+}
+
+// Wrap returns the datastore that caches the Get and List results of the tables, the store is returned as it is if the backend is nil.
+// A write removes the cached entity and moves the generation of its table, the lists are cached by the generation so
+// all cached lists of the table are dropped by any write.
+func Wrap(store datastore.DataStore, backend Backend, tables map[string]time.Duration) datastore.DataStore {
+	if backend == nil || len(tables) == 0 {
+		return store
+	}
+	return &cachedStore{DataStore: store, backend: backend, tables: tables}
+}
+
+type cachedStore struct {
+	datastore.DataStore
+	backend Backend
+	tables  map[string]time.Duration
+}
+
+func (c *cachedStore) ttl(entity datastore.Entity) (time.Duration, bool) {
+	ttl, ok := c.tables[entity.TableName()]
+	return ttl, ok
+}
+
+// entityKey the key of the cached entity, the keys of the tenants are apart so they never share the cached entities
+func entityKey(ctx context.Context, entity datastore.Entity) string {
+	return keyPrefix + datastore.TenantKey(ctx, "entity:"+entity.TableName()+":"+entity.PrimaryKey())
+}
+
+func generationKey(ctx context.Context, table string) string {
+	return keyPrefix + datastore.TenantKey(ctx, "generation:"+table)
+}
+
+func (c *cachedStore) generation(ctx context.Context, table string) (string, error) {
+	value, ok, err := c.backend.Get(ctx, generationKey(ctx, table))
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "0", nil
+	}
+	return string(value), nil
+}
+
+func (c *cachedStore) Get(ctx context.Context, entity datastore.Entity) error {
+	ttl, ok := c.ttl(entity)
+	if !ok || entity.PrimaryKey() == "" {
+		return c.DataStore.Get(ctx, entity)
+	}
+	key := entityKey(ctx, entity)
+	if data, hit, err := c.backend.Get(ctx, key); err != nil {
+		klog.Warningf("get the cached entity %s failure %s", key, err.Error())
+	} else if hit {
+		if err := json.Unmarshal(data, entity); err == nil {
+			return nil
+		}
+	}
+	if err := c.DataStore.Get(ctx, entity); err != nil {
+		return err
+	}
+	c.set(ctx, key, entity, ttl)
+	return nil
+}
+
+func (c *cachedStore) List(ctx context.Context, query datastore.Entity, options *datastore.ListOptions) ([]datastore.Entity, error) {
+	ttl, ok := c.ttl(query)
+	if !ok {
+		return c.DataStore.List(ctx, query, options)
+	}
+	generation, err := c.generation(ctx, query.TableName())
+	if err != nil {
+		klog.Warningf("get the generation of the cached table %s failure %s", query.TableName(), err.Error())
+		return c.DataStore.List(ctx, query, options)
+	}
+	condition, err := json.Marshal([]interface{}{query.Index(), options})
+	if err != nil {
+		return c.DataStore.List(ctx, query, options)
+	}
+	sum := sha256.Sum256(condition)
+	key := keyPrefix + datastore.TenantKey(ctx, "list:"+query.TableName()+":"+generation+":"+hex.EncodeToString(sum[:]))
+	if data, hit, err := c.backend.Get(ctx, key); err != nil {
+		klog.Warningf("get the cached list %s failure %s", key, err.Error())
+	} else if hit {
+		if entities, err := decodeList(query, data); err == nil {
+			return entities, nil
+		}
+	}
+	entities, err := c.DataStore.List(ctx, query, options)
+	if err != nil {
+		return nil, err
+	}
+	c.set(ctx, key, entities, ttl)
+	return entities, nil
+}
+
+func decodeList(query datastore.Entity, data []byte) ([]datastore.Entity, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	entities := make([]datastore.Entity, 0, len(items))
+	for _, item := range items {
+		entity, err := datastore.NewEntity(query)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(item, entity); err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+// set caches the value, the failures are only logged because the datastore is the source of truth
+func (c *cachedStore) set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := c.backend.Set(ctx, key, data, ttl); err != nil {
+		klog.Warningf("cache the %s failure %s", key, err.Error())
+	}
+}
+
+// invalidate drops the cached entities and the cached lists of their tables
+func (c *cachedStore) invalidate(ctx context.Context, entities ...datastore.Entity) {
+	tables := map[string]bool{}
+	var keys []string
+	for _, entity := range entities {
+		if _, ok := c.ttl(entity); !ok {
+			continue
+		}
+		tables[entity.TableName()] = true
+		if entity.PrimaryKey() != "" {
+			keys = append(keys, entityKey(ctx, entity))
+		}
+	}
+	if len(keys) > 0 {
+		if err := c.backend.Delete(ctx, keys...); err != nil {
+			klog.Errorf("invalidate the cached entities %v failure %s", keys, err.Error())
+		}
+	}
+	for table := range tables {
+		if _, err := c.backend.Incr(ctx, generationKey(ctx, table)); err != nil {
+			klog.Errorf("invalidate the cached lists of %s failure %s", table, err.Error())
+		}
+	}
+}
+
+func (c *cachedStore) Add(ctx context.Context, entity datastore.Entity) error {
+	defer c.invalidate(ctx, entity)
+	return c.DataStore.Add(ctx, entity)
+}
+
+func (c *cachedStore) BatchAdd(ctx context.Context, entities []datastore.Entity) error {
+	defer c.invalidate(ctx, entities...)
+	return c.DataStore.BatchAdd(ctx, entities)
+}
+
+func (c *cachedStore) Put(ctx context.Context, entity datastore.Entity) error {
+	defer c.invalidate(ctx, entity)
+	return c.DataStore.Put(ctx, entity)
+}
+
+func (c *cachedStore) BatchPut(ctx context.Context, entities []datastore.Entity) error {
+	defer c.invalidate(ctx, entities...)
+	return c.DataStore.BatchPut(ctx, entities)
+}
+
+func (c *cachedStore) Delete(ctx context.Context, entity datastore.Entity) error {
+	defer c.invalidate(ctx, entity)
+	return c.DataStore.Delete(ctx, entity)
+}
+
+// DeleteByFilter lists the matched entities before they are deleted, so their cached entities are dropped as well
+func (c *cachedStore) DeleteByFilter(ctx context.Context, query datastore.Entity, options *datastore.FilterOptions) (int64, error) {
+	if _, ok := c.ttl(query); !ok {
+		return c.DataStore.DeleteByFilter(ctx, query, options)
+	}
+	matched, err := listMatched(ctx, c.DataStore, query, options)
+	if err != nil {
+		return 0, err
+	}
+	defer c.invalidate(ctx, append(matched, query)...)
+	return c.DataStore.DeleteByFilter(ctx, query, options)
+}
+
+// listMatched lists the entities matched by the query and the filter options from the datastore
+func listMatched(ctx context.Context, store datastore.DataStore, query datastore.Entity, options *datastore.FilterOptions) ([]datastore.Entity, error) {
+	listOptions := &datastore.ListOptions{}
+	if options != nil {
+		listOptions.FilterOptions = *options
+	}
+	return store.List(ctx, query, listOptions)
+}
+
+// Transaction reads the datastore directly in the transaction because the uncommitted writes are not cached,
+// the written entities are invalidated after the transaction is committed or rolled back.
+func (c *cachedStore) Transaction(ctx context.Context, fn func(tx datastore.DataStore) error) error {
+	tx := &txStore{}
+	defer func() {
+		c.invalidate(ctx, tx.written...)
+	}()
+	return c.DataStore.Transaction(ctx, func(inner datastore.DataStore) error {
+		tx.DataStore = inner
+		return fn(tx)
+	})
+}
+
+// txStore records the entities written in the transaction
+type txStore struct {
+	datastore.DataStore
+	written []datastore.Entity
+}
+
+func (t *txStore) Add(ctx context.Context, entity datastore.Entity) error {
+	t.written = append(t.written, entity)
+	return t.DataStore.Add(ctx, entity)
+}
+
+func (t *txStore) BatchAdd(ctx context.Context, entities []datastore.Entity) error {
+	t.written = append(t.written, entities...)
+	return t.DataStore.BatchAdd(ctx, entities)
+}
+
+func (t *txStore) Put(ctx context.Context, entity datastore.Entity) error {
+	t.written = append(t.written, entity)
+	return t.DataStore.Put(ctx, entity)
+}
+
+func (t *txStore) BatchPut(ctx context.Context, entities []datastore.Entity) error {
+	t.written = append(t.written, entities...)
+	return t.DataStore.BatchPut(ctx, entities)
+}
+
+func (t *txStore) Delete(ctx context.Context, entity datastore.Entity) error {
+	t.written = append(t.written, entity)
+	return t.DataStore.Delete(ctx, entity)
+}
+
+func (t *txStore) DeleteByFilter(ctx context.Context, query datastore.Entity, options *datastore.FilterOptions) (int64, error) {
+	matched, err := listMatched(ctx, t.DataStore, query, options)
+	if err != nil {
+		return 0, err
+	}
+	t.written = append(t.written, append(matched, query)...)
+	return t.DataStore.DeleteByFilter(ctx, query, options)
+}
+
+func (t *txStore) Transaction(ctx context.Context, fn func(tx datastore.DataStore) error) error {
+	return t.DataStore.Transaction(ctx, func(inner datastore.DataStore) error {
+		nested := &txStore{DataStore: inner}
+		err := fn(nested)
+		t.written = append(t.written, nested.written...)
+		return err
+	})
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// countingStore stores the users in memory and counts the reads
+type countingStore struct {
+	datastore.DataStore
+	users map[string]*model.User
+	reads int
+}
+
+func (c *countingStore) Put(_ context.Context, entity datastore.Entity) error {
+	user := *entity.(*model.User)
+	c.users[user.Name] = &user
+	return nil
+}
+
+func (c *countingStore) Get(_ context.Context, entity datastore.Entity) error {
+	c.reads++
+	user, ok := c.users[entity.PrimaryKey()]
+	if !ok {
+		return datastore.ErrRecordNotExist
+	}
+	*entity.(*model.User) = *user
+	return nil
+}
+
+func (c *countingStore) List(_ context.Context, _ datastore.Entity, _ *datastore.ListOptions) ([]datastore.Entity, error) {
+	c.reads++
+	var entities []datastore.Entity
+	for _, user := range c.users {
+		copied := *user
+		entities = append(entities, &copied)
+	}
+	return entities, nil
+}
+
+func testCachedStore(t *testing.T, backend Backend) {
+	ctx := context.Background()
+	inner := &countingStore{users: map[string]*model.User{"admin": {Name: "admin", Alias: "Admin"}}}
+	tables, err := ParseTables([]string{"vela_user=1m", "vela_project"}, time.Minute)
+	assert.NoError(t, err)
+	store := Wrap(inner, backend, tables)
+
+	for i := 0; i < 2; i++ {
+		user := &model.User{Name: "admin"}
+		assert.NoError(t, store.Get(ctx, user))
+		assert.Equal(t, "Admin", user.Alias)
+		users, err := store.List(ctx, &model.User{}, &datastore.ListOptions{Page: 1, PageSize: 10})
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+	}
+	assert.Equal(t, 2, inner.reads)
+
+	assert.NoError(t, store.Put(ctx, &model.User{Name: "admin", Alias: "Administrator"}))
+	user := &model.User{Name: "admin"}
+	assert.NoError(t, store.Get(ctx, user))
+	assert.Equal(t, "Administrator", user.Alias)
+	users, err := store.List(ctx, &model.User{}, &datastore.ListOptions{Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "Administrator", users[0].(*model.User).Alias)
+	assert.Equal(t, 4, inner.reads)
+
+	assert.Equal(t, datastore.ErrRecordNotExist, store.Get(ctx, &model.User{Name: "unknown"}))
+}
+
+func TestMemoryCache(t *testing.T) {
+	testCachedStore(t, newMemoryBackend(0))
+
+	_, err := ParseTables([]string{"vela_user=never"}, time.Minute)
+	assert.Error(t, err)
+	backend, err := New(Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, backend)
+}
+
+// fakeRedis serves the GET, SET, DEL and INCR commands
+func fakeRedis(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	values := make(chan map[string]string, 1)
+	values <- map[string]string{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer func() { _ = conn.Close() }()
+				reader := bufio.NewReader(conn)
+				for {
+					args, err := readCommand(reader)
+					if err != nil {
+						return
+					}
+					data := <-values
+					var reply string
+					switch strings.ToUpper(args[0]) {
+					case "GET":
+						if value, ok := data[args[1]]; ok {
+							reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+						} else {
+							reply = "$-1\r\n"
+						}
+					case "SET":
+						data[args[1]] = args[2]
+						reply = "+OK\r\n"
+					case "DEL":
+						for _, key := range args[1:] {
+							delete(data, key)
+						}
+						reply = fmt.Sprintf(":%d\r\n", len(args)-1)
+					case "INCR":
+						count, _ := strconv.Atoi(data[args[1]])
+						data[args[1]] = strconv.Itoa(count + 1)
+						reply = fmt.Sprintf(":%d\r\n", count+1)
+					default:
+						reply = "-ERR unknown command\r\n"
+					}
+					values <- data
+					if _, err := conn.Write([]byte(reply)); err != nil {
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args = append(args, string(arg[:size]))
+	}
+	return args, nil
+}
+
+func TestRedisCache(t *testing.T) {
+	backend := newRedisBackend(fakeRedis(t), "", 0)
+	testCachedStore(t, backend)
+
+	ctx := context.Background()
+	_, err := backend.do(ctx, "PING")
+	assert.Error(t, err)
+	data, _ := json.Marshal("value")
+	assert.NoError(t, backend.Set(ctx, "key", data, time.Minute))
+	value, ok, err := backend.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, data, value)
+}
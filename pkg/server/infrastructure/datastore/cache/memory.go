@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+)
+
+// defaultMemorySize the max number of the cached entries if the size is not set
+const defaultMemorySize = 10000
+
+type memoryBackend struct {
+	lru         *utilcache.LRUExpireCache
+	mu          sync.Mutex
+	generations map[string]int64
+}
+
+func newMemoryBackend(size int) *memoryBackend {
+	if size <= 0 {
+		size = defaultMemorySize
+	}
+	return &memoryBackend{lru: utilcache.NewLRUExpireCache(size), generations: map[string]int64{}}
+}
+
+func (m *memoryBackend) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	generation, ok := m.generations[key]
+	m.mu.Unlock()
+	if ok {
+		return []byte(strconv.FormatInt(generation, 10)), true, nil
+	}
+	value, ok := m.lru.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	return value.([]byte), true, nil
+}
+
+func (m *memoryBackend) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.lru.Add(key, value, ttl)
+	return nil
+}
+
+func (m *memoryBackend) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		m.lru.Remove(key)
+	}
+	return nil
+}
+
+// Incr keeps the counters out of the LRU, so they are not evicted and the stale lists are never read again
+func (m *memoryBackend) Incr(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.generations[key]++
+	return m.generations[key], nil
+}
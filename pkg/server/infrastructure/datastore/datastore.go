@@ -39,6 +39,9 @@ var (
 	// ErrRecordNotExist Error that entity primary key is not exist
 	ErrRecordNotExist = NewDBError(fmt.Errorf("data record is not exist"))
 
+	// ErrRecordConflict Error that entity is modified by others since it was read
+	ErrRecordConflict = NewDBError(fmt.Errorf("data record has been modified"))
+
 	// ErrIndexInvalid Error that entity index is invalid
 	ErrIndexInvalid = NewDBError(fmt.Errorf("entity index is invalid"))
 
@@ -65,18 +68,33 @@ type Config struct {
 	Type     string
 	URL      string
 	Database string
+	// Shards the number of the namespaces the kubeapi driver spreads the entities across, 0 or 1 stores them in one namespace
+	Shards int
+	// Tenants the organizations hosted in the isolated partitions besides the default one, the multi-tenancy is disabled if it is empty
+	Tenants []string
 }
 
 // Entity database data model
 type Entity interface {
 	SetCreateTime(time time.Time)
 	SetUpdateTime(time time.Time)
+	GetResourceVersion() int64
+	SetResourceVersion(version int64)
 	PrimaryKey() string
 	TableName() string
 	ShortTableName() string
 	Index() map[string]interface{}
 }
 
+// CheckResourceVersion compares the version of the entity to put with the stored version, ErrRecordConflict is returned if
+// the entity is stale. The entity without the version overwrites the stored one.
+func CheckResourceVersion(entity Entity, stored int64) error {
+	if version := entity.GetResourceVersion(); version != 0 && version != stored {
+		return ErrRecordConflict
+	}
+	return nil
+}
+
 // NewEntity Create a new object based on the input type
 func NewEntity(in Entity) (Entity, error) {
 	if in == nil {
@@ -123,11 +141,26 @@ type IsNotExistQueryOption struct {
 	Key string
 }
 
-// FilterOptions filter query returned items
+// IsExistQueryOption means the value is not empty
+type IsExistQueryOption struct {
+	Key string
+}
+
+// FilterOptions filter query returned items, all options must be matched.
+// The field without the value matches IsNotExist and NotIn.
 type FilterOptions struct {
 	Queries    []FuzzyQueryOption
 	In         []InQueryOption
+	NotIn      []InQueryOption
+	IsExist    []IsExistQueryOption
 	IsNotExist []IsNotExistQueryOption
+	// Or at least one of the groups must be matched, the groups could be nested
+	Or []FilterOptions
+}
+
+// IsEmpty returns true if no option is set
+func (f *FilterOptions) IsEmpty() bool {
+	return f == nil || len(f.Queries) == 0 && len(f.In) == 0 && len(f.NotIn) == 0 && len(f.IsExist) == 0 && len(f.IsNotExist) == 0 && len(f.Or) == 0
 }
 
 // ListOptions list api options
@@ -147,11 +180,20 @@ type DataStore interface {
 	BatchAdd(ctx context.Context, entities []Entity) error
 
 	// Put will update entity to database, Name() and TableName() can't return zero value.
+	// It fails with ErrRecordConflict if the resource version of the entity is not the stored one, the version is increased after the update.
 	Put(ctx context.Context, entity Entity) error
 
+	// BatchPut will update batched entities to database together, Name() and TableName() can't return zero value.
+	// It fails like Put if any entity fails, and none of the entities is updated.
+	BatchPut(ctx context.Context, entities []Entity) error
+
 	// Delete entity from database, Name() and TableName() can't return zero value.
 	Delete(ctx context.Context, entity Entity) error
 
+	// DeleteByFilter deletes the entities matched by the index of the query and the filter options, TableName() can't return zero value.
+	// It returns the number of the deleted entities, if no matches, it returns zero without error.
+	DeleteByFilter(ctx context.Context, query Entity, options *FilterOptions) (int64, error)
+
 	// Get entity from database, Name() and TableName() can't return zero value.
 	Get(ctx context.Context, entity Entity) error
 
@@ -161,6 +203,23 @@ type DataStore interface {
 	// Count entities from database, TableName() can't return zero value.
 	Count(ctx context.Context, entity Entity, options *FilterOptions) (int64, error)
 
+	// Search lists the entities whose search fields contain all keywords of the text, the better matches are listed first.
+	// The query must be a SearchableEntity, the entities are filtered by its index and the filter options like List, and the sort options are ignored.
+	// MongoDB matches the whole words by its text index, the other drivers match the prefixes of the words as well.
+	Search(ctx context.Context, query Entity, text string, options *ListOptions) ([]Entity, error)
+
 	// IsExist Name() and TableName() can't return zero value.
 	IsExist(ctx context.Context, entity Entity) (bool, error)
+
+	// Transaction calls fn with a datastore whose writes are applied together, they are rolled back if fn returns an error.
+	// The nested transactions join the outer one. MongoDB uses the multi-document transactions of the replica sets and
+	// the sharded clusters, the standalone MongoDB and the kubeapi driver undo the writes by the compensating writes.
+	// InTransaction carries the transaction by the context, so the writes of the services called in it join it as well.
+	Transaction(ctx context.Context, fn func(tx DataStore) error) error
+
+	// Watch sends the changes of the entities matched by the index of the query until the context is done or the watch fails,
+	// then the channel is closed and the callers could watch again. The entities existing before Watch is called are not sent.
+	// The kubeapi driver watches the ConfigMaps and MongoDB uses the change streams of the replica sets, the other drivers
+	// poll the datastore so the changes are delayed and merged by the poll interval.
+	Watch(ctx context.Context, query Entity) (<-chan WatchEvent, error)
 }
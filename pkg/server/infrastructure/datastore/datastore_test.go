@@ -17,6 +17,7 @@ limitations under the License.
 package datastore
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -58,3 +59,44 @@ var _ = Describe("Test new entity function", func() {
 	})
 
 })
+
+var _ = Describe("Test the index functions", func() {
+	It("Test parse the indexes", func() {
+		index, err := ParseIndex("project,-createTime")
+		Expect(err).Should(BeNil())
+		Expect(index.Name).Should(Equal("velaux_project_createTime_desc"))
+		Expect(index.Unique).Should(BeFalse())
+		Expect(index.Fields).Should(Equal([]SortOption{{Key: "project", Order: SortOrderAscending}, {Key: "createTime", Order: SortOrderDescending}}))
+
+		index, err = ParseIndex("unique:principal.type")
+		Expect(err).Should(BeNil())
+		Expect(index.Name).Should(Equal("velaux_unique_principal_type"))
+		Expect(index.Unique).Should(BeTrue())
+		Expect(index.Keys()).Should(Equal([]string{"principal.type"}))
+
+		By("the long names are shortened by the hash")
+		index, err = ParseIndex("resourceDefinitionName,applicationPrimaryKey,environmentName,-createTime")
+		Expect(err).Should(BeNil())
+		Expect(len(index.Name)).Should(Equal(64))
+
+		for _, definition := range []string{"", "project,", "project,project", "unique:", "$project"} {
+			_, err := ParseIndex(definition)
+			Expect(err).ShouldNot(BeNil(), definition)
+		}
+	})
+
+	It("Test reconcile the indexes", func() {
+		var created, dropped []string
+		ReconcileIndexes(context.TODO(), &model.Env{}, []string{"_id_", "velaux_project", "velaux_name"}, func(ctx context.Context, index Index) error {
+			created = append(created, index.Name)
+			return nil
+		}, func(ctx context.Context, name string) error {
+			dropped = append(dropped, name)
+			return nil
+		})
+		Expect(created).Should(Equal([]string{"velaux_unique_namespace"}))
+		Expect(dropped).Should(Equal([]string{"velaux_name"}))
+		Expect(IndexedKeys(&model.Env{})).Should(Equal(map[string]bool{"project": true, "namespace": true}))
+		Expect(EntityIndexes(&model.Cluster{})).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastoretest
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// DescribeBatchConformance adds the tests of the batch updates and the deletion by the filter options
+func DescribeBatchConformance(getStore func() datastore.DataStore) {
+	It("Test the conformance of the batch put and the delete by filter", func() {
+		store := getStore()
+		ctx := context.TODO()
+		names := []string{"batch-1", "batch-2", "batch-3"}
+		var entities []datastore.Entity
+		for _, name := range names {
+			env := &model.Env{Name: name, Namespace: name, Project: "batch"}
+			Expect(store.Add(ctx, env)).Should(Succeed())
+			entities = append(entities, env)
+		}
+		defer func() {
+			for _, name := range names {
+				if err := store.Delete(ctx, &model.Env{Name: name}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+					Fail(err.Error())
+				}
+			}
+		}()
+
+		By("all entities are updated together")
+		for _, entity := range entities {
+			entity.(*model.Env).Alias = "updated"
+		}
+		Expect(store.BatchPut(ctx, entities)).Should(Succeed())
+		count, err := store.Count(ctx, &model.Env{Project: "batch"}, &datastore.FilterOptions{In: []datastore.InQueryOption{{Key: "alias", Values: []string{"updated"}}}})
+		Expect(err).Should(BeNil())
+		Expect(count).Should(Equal(int64(3)))
+
+		By("none of the entities is updated if one of them conflicts")
+		fresh := &model.Env{Name: "batch-1"}
+		Expect(store.Get(ctx, fresh)).Should(Succeed())
+		fresh.Alias = "conflict"
+		stale := &model.Env{Name: "batch-2"}
+		Expect(store.Get(ctx, stale)).Should(Succeed())
+		stale.Alias = "conflict"
+		stale.SetResourceVersion(stale.GetResourceVersion() + 100)
+		err = store.BatchPut(ctx, []datastore.Entity{fresh, stale})
+		Expect(errors.Is(err, datastore.ErrRecordConflict)).Should(BeTrue())
+		env := &model.Env{Name: "batch-1"}
+		Expect(store.Get(ctx, env)).Should(Succeed())
+		Expect(env.Alias).Should(Equal("updated"))
+
+		By("the entities matched by the index and the filter options are deleted")
+		deleted, err := store.DeleteByFilter(ctx, &model.Env{Project: "batch"}, &datastore.FilterOptions{In: []datastore.InQueryOption{{Key: "name", Values: []string{"batch-1", "batch-2"}}}})
+		Expect(err).Should(BeNil())
+		Expect(deleted).Should(Equal(int64(2)))
+		Expect(store.IsExist(ctx, &model.Env{Name: "batch-1"})).Should(BeFalse())
+		Expect(store.IsExist(ctx, &model.Env{Name: "batch-3"})).Should(BeTrue())
+
+		By("no entity is deleted if nothing is matched")
+		deleted, err = store.DeleteByFilter(ctx, &model.Env{Project: "batch"}, &datastore.FilterOptions{In: []datastore.InQueryOption{{Key: "name", Values: []string{"batch-1"}}}})
+		Expect(err).Should(BeNil())
+		Expect(deleted).Should(Equal(int64(0)))
+		deleted, err = store.DeleteByFilter(ctx, &model.Env{Project: "batch"}, nil)
+		Expect(err).Should(BeNil())
+		Expect(deleted).Should(Equal(int64(1)))
+	})
+}
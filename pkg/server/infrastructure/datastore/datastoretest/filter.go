@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package datastoretest contains the conformance tests shared by the datastore drivers
+package datastoretest
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+const conformanceAlias = "filter-conformance"
+
+// filterCase the roles expected to be listed and counted by the filter options
+type filterCase struct {
+	description string
+	query       *model.Role
+	filter      datastore.FilterOptions
+	expected    []string
+}
+
+// DescribeFilterConformance adds the tests of the filter options, every driver must list and count the same entities by them
+func DescribeFilterConformance(getStore func() datastore.DataStore) {
+	It("Test the conformance of the filter options", func() {
+		ctx := context.TODO()
+		store := getStore()
+		roles := []*model.Role{
+			{Name: "conformance-admin", Alias: conformanceAlias},
+			{Name: "conformance-dev", Alias: conformanceAlias, Project: "conformance-a"},
+			{Name: "conformance-ops", Alias: conformanceAlias, Project: "conformance-b"},
+			{Name: "conformance-viewer", Alias: conformanceAlias, Project: "conformance-a"},
+		}
+		for _, role := range roles {
+			Expect(store.Add(ctx, role)).Should(Succeed())
+		}
+		defer func() {
+			for _, role := range roles {
+				if err := store.Delete(ctx, role); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+					Fail(err.Error())
+				}
+			}
+		}()
+
+		cases := []filterCase{{
+			description: "the values not in the list and the missing values are matched by NotIn",
+			filter:      datastore.FilterOptions{NotIn: []datastore.InQueryOption{{Key: "project", Values: []string{"conformance-a"}}}},
+			expected:    []string{"conformance-admin", "conformance-ops"},
+		}, {
+			description: "the empty NotIn matches all",
+			filter:      datastore.FilterOptions{NotIn: []datastore.InQueryOption{{Key: "project"}}},
+			expected:    []string{"conformance-admin", "conformance-dev", "conformance-ops", "conformance-viewer"},
+		}, {
+			description: "the empty In matches nothing",
+			filter:      datastore.FilterOptions{In: []datastore.InQueryOption{{Key: "project"}}},
+		}, {
+			description: "the present values are matched by IsExist",
+			filter:      datastore.FilterOptions{IsExist: []datastore.IsExistQueryOption{{Key: "project"}}},
+			expected:    []string{"conformance-dev", "conformance-ops", "conformance-viewer"},
+		}, {
+			description: "the missing values are matched by IsNotExist",
+			filter:      datastore.FilterOptions{IsNotExist: []datastore.IsNotExistQueryOption{{Key: "project"}}},
+			expected:    []string{"conformance-admin"},
+		}, {
+			description: "one of the OR groups is matched",
+			filter: datastore.FilterOptions{Or: []datastore.FilterOptions{
+				{In: []datastore.InQueryOption{{Key: "project", Values: []string{"conformance-b"}}}},
+				{IsNotExist: []datastore.IsNotExistQueryOption{{Key: "project"}}},
+			}},
+			expected: []string{"conformance-admin", "conformance-ops"},
+		}, {
+			description: "the OR groups are nested",
+			filter: datastore.FilterOptions{Or: []datastore.FilterOptions{
+				{
+					In: []datastore.InQueryOption{{Key: "project", Values: []string{"conformance-a"}}},
+					Or: []datastore.FilterOptions{
+						{In: []datastore.InQueryOption{{Key: "name", Values: []string{"conformance-dev"}}}},
+						{In: []datastore.InQueryOption{{Key: "name", Values: []string{"conformance-ops"}}}},
+					},
+				},
+				{In: []datastore.InQueryOption{{Key: "name", Values: []string{"conformance-admin"}}}},
+			}},
+			expected: []string{"conformance-admin", "conformance-dev"},
+		}, {
+			description: "the filter options and the index are matched together",
+			query:       &model.Role{Project: "conformance-a"},
+			filter:      datastore.FilterOptions{NotIn: []datastore.InQueryOption{{Key: "name", Values: []string{"conformance-dev"}}}},
+			expected:    []string{"conformance-viewer"},
+		}}
+		for _, c := range cases {
+			By(c.description)
+			query := c.query
+			if query == nil {
+				query = &model.Role{}
+			}
+			// only the roles of the test are matched
+			filter := c.filter
+			filter.Queries = append(filter.Queries, datastore.FuzzyQueryOption{Key: "alias", Query: conformanceAlias})
+			entities, err := store.List(ctx, query, &datastore.ListOptions{FilterOptions: filter})
+			Expect(err).ShouldNot(HaveOccurred())
+			var names []string
+			for _, entity := range entities {
+				names = append(names, entity.(*model.Role).Name)
+			}
+			Expect(names).Should(ConsistOf(c.expected))
+			count, err := store.Count(ctx, query, &filter)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(count).Should(Equal(int64(len(c.expected))))
+		}
+
+		By("the entities matched by the OR groups are sorted and paged")
+		entities, err := store.List(ctx, &model.Role{}, &datastore.ListOptions{
+			FilterOptions: datastore.FilterOptions{
+				Queries: []datastore.FuzzyQueryOption{{Key: "alias", Query: conformanceAlias}},
+				Or: []datastore.FilterOptions{
+					{In: []datastore.InQueryOption{{Key: "project", Values: []string{"conformance-b"}}}},
+					{IsNotExist: []datastore.IsNotExistQueryOption{{Key: "project"}}},
+				},
+			},
+			SortBy:   []datastore.SortOption{{Key: "name", Order: datastore.SortOrderDescending}},
+			Page:     1,
+			PageSize: 1,
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(entities)).Should(Equal(1))
+		Expect(entities[0].(*model.Role).Name).Should(Equal("conformance-ops"))
+	})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastoretest
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// DescribeIndexConformance adds the tests of the unique indexes, the namespaces of the environments are unique
+func DescribeIndexConformance(getStore func() datastore.DataStore) {
+	It("Test the conformance of the unique indexes", func() {
+		store := getStore()
+		ctx := context.TODO()
+		names := []string{"index-dev", "index-test", "index-empty-1", "index-empty-2"}
+		defer func() {
+			for _, name := range names {
+				if err := store.Delete(ctx, &model.Env{Name: name}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+					Fail(err.Error())
+				}
+			}
+		}()
+
+		Expect(store.Add(ctx, &model.Env{Name: "index-dev", Namespace: "index-dev", Project: "index"})).Should(Succeed())
+		err := store.Add(ctx, &model.Env{Name: "index-test", Namespace: "index-dev", Project: "index"})
+		Expect(errors.Is(err, datastore.ErrRecordExist)).Should(BeTrue())
+		Expect(store.IsExist(ctx, &model.Env{Name: "index-test"})).Should(BeFalse())
+
+		By("the entity could be updated with its own values")
+		env := &model.Env{Name: "index-dev"}
+		Expect(store.Get(ctx, env)).Should(Succeed())
+		env.Alias = "dev"
+		Expect(store.Put(ctx, env)).Should(Succeed())
+
+		By("the update to the values of another entity is rejected")
+		Expect(store.Add(ctx, &model.Env{Name: "index-test", Namespace: "index-test", Project: "index"})).Should(Succeed())
+		env = &model.Env{Name: "index-test"}
+		Expect(store.Get(ctx, env)).Should(Succeed())
+		env.Namespace = "index-dev"
+		err = store.Put(ctx, env)
+		Expect(errors.Is(err, datastore.ErrRecordExist)).Should(BeTrue())
+		env = &model.Env{Name: "index-test"}
+		Expect(store.Get(ctx, env)).Should(Succeed())
+		Expect(env.Namespace).Should(Equal("index-test"))
+
+		By("the entities with the empty values are not constrained")
+		Expect(store.Add(ctx, &model.Env{Name: "index-empty-1", Project: "index"})).Should(Succeed())
+		Expect(store.Add(ctx, &model.Env{Name: "index-empty-2", Project: "index"})).Should(Succeed())
+		count, err := store.Count(ctx, &model.Env{Project: "index"}, nil)
+		Expect(err).Should(BeNil())
+		Expect(count).Should(Equal(int64(4)))
+	})
+}
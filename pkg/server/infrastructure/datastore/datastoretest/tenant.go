@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastoretest
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// ConformanceTenant the tenant used by the tenant conformance tests, the kubeapi driver must be created with it
+const ConformanceTenant = "conformance"
+
+// DescribeTenantConformance adds the tests of the tenant isolation, the entities of a tenant are never read by the others
+func DescribeTenantConformance(getStore func() datastore.DataStore) {
+	It("Test the conformance of the tenant isolation", func() {
+		store := getStore()
+		ctx := context.TODO()
+		tenantCtx := datastore.WithTenant(ctx, ConformanceTenant)
+
+		Expect(store.Add(tenantCtx, &model.Project{Name: "tenant-project", Alias: "tenant"})).Should(Succeed())
+		defer func() {
+			if err := store.Delete(tenantCtx, &model.Project{Name: "tenant-project"}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+				Fail(err.Error())
+			}
+		}()
+
+		By("the entity of the tenant is not visible in the default partition")
+		err := store.Get(ctx, &model.Project{Name: "tenant-project"})
+		Expect(errors.Is(err, datastore.ErrRecordNotExist)).Should(BeTrue())
+		exist, err := store.IsExist(ctx, &model.Project{Name: "tenant-project"})
+		Expect(err).Should(BeNil())
+		Expect(exist).Should(BeFalse())
+		entities, err := store.List(ctx, &model.Project{Name: "tenant-project"}, &datastore.ListOptions{})
+		Expect(err).Should(BeNil())
+		Expect(entities).Should(BeEmpty())
+
+		By("the same primary key is stored apart in the default partition")
+		Expect(store.Add(ctx, &model.Project{Name: "tenant-project", Alias: "default"})).Should(Succeed())
+		defer func() {
+			if err := store.Delete(ctx, &model.Project{Name: "tenant-project"}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+				Fail(err.Error())
+			}
+		}()
+		project := &model.Project{Name: "tenant-project"}
+		Expect(store.Get(tenantCtx, project)).Should(Succeed())
+		Expect(project.Alias).Should(Equal("tenant"))
+		count, err := store.Count(tenantCtx, &model.Project{Name: "tenant-project"}, nil)
+		Expect(err).Should(BeNil())
+		Expect(count).Should(Equal(int64(1)))
+
+		By("the delete in the default partition keeps the entity of the tenant")
+		Expect(store.Delete(ctx, &model.Project{Name: "tenant-project"})).Should(Succeed())
+		Expect(store.Get(tenantCtx, &model.Project{Name: "tenant-project"})).Should(Succeed())
+	})
+}
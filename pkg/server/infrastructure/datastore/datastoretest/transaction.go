@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastoretest
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// DescribeTransactionConformance adds the tests of the transactions carried by the context
+func DescribeTransactionConformance(getStore func() datastore.DataStore) {
+	It("Test the conformance of the transaction carried by the context", func() {
+		store := datastore.JoinContextTransaction(getStore())
+		ctx := context.TODO()
+		Expect(store.Add(ctx, &model.Env{Name: "context-tx-1", Alias: "before", Project: "context-tx"})).Should(Succeed())
+		defer func() {
+			for _, name := range []string{"context-tx-1", "context-tx-2"} {
+				if err := store.Delete(ctx, &model.Env{Name: name}); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+					Fail(err.Error())
+				}
+			}
+		}()
+
+		By("the writes with the context join the transaction, and they are rolled back together")
+		err := datastore.InTransaction(ctx, store, func(ctx context.Context) error {
+			if err := store.Add(ctx, &model.Env{Name: "context-tx-2", Project: "context-tx"}); err != nil {
+				return err
+			}
+			// the nested transaction joins the outer one
+			if err := datastore.InTransaction(ctx, store, func(ctx context.Context) error {
+				env := &model.Env{Name: "context-tx-1"}
+				if err := store.Get(ctx, env); err != nil {
+					return err
+				}
+				env.Alias = "after"
+				return store.Put(ctx, env)
+			}); err != nil {
+				return err
+			}
+			exist, err := store.IsExist(ctx, &model.Env{Name: "context-tx-2"})
+			if err != nil {
+				return err
+			}
+			Expect(exist).Should(BeTrue())
+			return errors.New("abort")
+		})
+		Expect(err).Should(MatchError("abort"))
+		Expect(store.IsExist(ctx, &model.Env{Name: "context-tx-2"})).Should(BeFalse())
+		env := &model.Env{Name: "context-tx-1"}
+		Expect(store.Get(ctx, env)).Should(Succeed())
+		Expect(env.Alias).Should(Equal("before"))
+
+		By("the writes are committed together")
+		Expect(datastore.InTransaction(ctx, store, func(ctx context.Context) error {
+			if err := store.Add(ctx, &model.Env{Name: "context-tx-2", Project: "context-tx"}); err != nil {
+				return err
+			}
+			env := &model.Env{Name: "context-tx-1"}
+			if err := store.Get(ctx, env); err != nil {
+				return err
+			}
+			env.Alias = "after"
+			return store.Put(ctx, env)
+		})).Should(Succeed())
+		count, err := store.Count(ctx, &model.Env{Project: "context-tx"}, nil)
+		Expect(err).Should(BeNil())
+		Expect(count).Should(Equal(int64(2)))
+		Expect(store.Get(ctx, env)).Should(Succeed())
+		Expect(env.Alias).Should(Equal("after"))
+	})
+}
@@ -0,0 +1,429 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+const (
+	// ProviderSecret the key encryption keys are stored in a Kubernetes Secret
+	ProviderSecret = "secret"
+	// ProviderKMS the data keys are encrypted by a KMS plugin implementing the KMS v1 API of Kubernetes
+	ProviderKMS = "kms"
+
+	// encryptedTag the string fields of the models with the tag `encrypted:"true"` are encrypted at rest
+	encryptedTag = "encrypted"
+	// sealedPrefix the encrypted values are formatted as enc:v1:<key id>:<wrapped data key>:<nonce and cipher text>
+	sealedPrefix = "enc:v1:"
+)
+
+// Config the key encryption keys of the sensitive fields
+type Config struct {
+	// Provider option values: secret, kms. The fields are stored in plaintext if it is empty.
+	Provider        string
+	SecretNamespace string
+	SecretName      string
+	// SecretKey the key of the secret that encrypts the new values, the other keys of the secret only decrypt the existing values
+	SecretKey string
+	// KMSEndpoint the gRPC endpoint of the KMS plugin, such as unix:///var/run/kms-plugin/socket.sock
+	KMSEndpoint string
+}
+
+// KeyProvider encrypts the data keys by the key encryption key
+type KeyProvider interface {
+	// Wrap encrypts the data key, the ID of the key encryption key is returned to decrypt it later
+	Wrap(ctx context.Context, dek []byte) (keyID string, wrapped []byte, err error)
+	Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// NewKeyProvider creates the key provider of the config, nil is returned if the encryption is disabled
+func NewKeyProvider(cfg Config, kubeClient client.Client) (KeyProvider, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case ProviderSecret:
+		return &secretKeyProvider{cfg: cfg, kubeClient: kubeClient}, nil
+	case ProviderKMS:
+		return newKMSKeyProvider(cfg.KMSEndpoint)
+	default:
+		return nil, fmt.Errorf("not support encryption provider %s", cfg.Provider)
+	}
+}
+
+// Wrap returns the datastore that encrypts the sensitive fields of the entities before they are written and decrypts them after they are read,
+// the store is returned as it is if the key provider is nil. The values written before the encryption is enabled are read in plaintext,
+// they are encrypted when the entities are updated.
+func Wrap(store datastore.DataStore, keys KeyProvider) datastore.DataStore {
+	if keys == nil {
+		return store
+	}
+	return &encryptedStore{DataStore: store, envelope: &envelope{keys: keys, deks: map[string][]byte{}}}
+}
+
+type encryptedStore struct {
+	datastore.DataStore
+	envelope *envelope
+}
+
+func (e *encryptedStore) Add(ctx context.Context, entity datastore.Entity) error {
+	restore, err := e.envelope.seal(ctx, entity)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	return e.DataStore.Add(ctx, entity)
+}
+
+func (e *encryptedStore) BatchAdd(ctx context.Context, entities []datastore.Entity) error {
+	for _, entity := range entities {
+		restore, err := e.envelope.seal(ctx, entity)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+	return e.DataStore.BatchAdd(ctx, entities)
+}
+
+func (e *encryptedStore) Put(ctx context.Context, entity datastore.Entity) error {
+	restore, err := e.envelope.seal(ctx, entity)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	return e.DataStore.Put(ctx, entity)
+}
+
+func (e *encryptedStore) BatchPut(ctx context.Context, entities []datastore.Entity) error {
+	for _, entity := range entities {
+		restore, err := e.envelope.seal(ctx, entity)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+	return e.DataStore.BatchPut(ctx, entities)
+}
+
+func (e *encryptedStore) Get(ctx context.Context, entity datastore.Entity) error {
+	if err := e.DataStore.Get(ctx, entity); err != nil {
+		return err
+	}
+	return e.envelope.open(ctx, entity)
+}
+
+func (e *encryptedStore) List(ctx context.Context, query datastore.Entity, options *datastore.ListOptions) ([]datastore.Entity, error) {
+	entities, err := e.DataStore.List(ctx, query, options)
+	if err != nil {
+		return nil, err
+	}
+	return entities, e.openAll(ctx, entities)
+}
+
+func (e *encryptedStore) Search(ctx context.Context, query datastore.Entity, text string, options *datastore.ListOptions) ([]datastore.Entity, error) {
+	entities, err := e.DataStore.Search(ctx, query, text, options)
+	if err != nil {
+		return nil, err
+	}
+	return entities, e.openAll(ctx, entities)
+}
+
+func (e *encryptedStore) Transaction(ctx context.Context, fn func(tx datastore.DataStore) error) error {
+	return e.DataStore.Transaction(ctx, func(tx datastore.DataStore) error {
+		return fn(&encryptedStore{DataStore: tx, envelope: e.envelope})
+	})
+}
+
+// Watch decrypts the entities of the events, the events whose entities could not be decrypted are dropped
+func (e *encryptedStore) Watch(ctx context.Context, query datastore.Entity) (<-chan datastore.WatchEvent, error) {
+	events, err := e.DataStore.Watch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan datastore.WatchEvent, datastore.WatchBufferSize)
+	go func() {
+		defer close(ch)
+		for event := range events {
+			if err := e.envelope.open(ctx, event.Entity); err != nil {
+				klog.Errorf("decrypt the watched entity %s failure %s", event.Entity.PrimaryKey(), err.Error())
+				continue
+			}
+			if !datastore.SendWatchEvent(ctx, ch, event) {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (e *encryptedStore) openAll(ctx context.Context, entities []datastore.Entity) error {
+	for _, entity := range entities {
+		if err := e.envelope.open(ctx, entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envelope encrypts the values by a data key, the data key is encrypted by the key provider and stored with the values.
+// One data key is used by the process, the decrypted data keys are cached so the key provider is rarely called.
+type envelope struct {
+	keys    KeyProvider
+	mu      sync.Mutex
+	dek     []byte
+	keyID   string
+	wrapped string
+	deks    map[string][]byte
+}
+
+func (e *envelope) currentKey(ctx context.Context) ([]byte, string, string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.dek == nil {
+		dek := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+			return nil, "", "", err
+		}
+		keyID, wrapped, err := e.keys.Wrap(ctx, dek)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("encrypt the data key failure %w", err)
+		}
+		if strings.Contains(keyID, ":") {
+			return nil, "", "", fmt.Errorf("the key id %s must not contain the colon", keyID)
+		}
+		e.dek, e.keyID, e.wrapped = dek, keyID, base64.RawStdEncoding.EncodeToString(wrapped)
+		e.deks[e.keyID+":"+e.wrapped] = dek
+	}
+	return e.dek, e.keyID, e.wrapped, nil
+}
+
+func (e *envelope) dataKey(ctx context.Context, keyID, wrapped string) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if dek, ok := e.deks[keyID+":"+wrapped]; ok {
+		return dek, nil
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := e.keys.Unwrap(ctx, keyID, raw)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt the data key failure %w", err)
+	}
+	e.deks[keyID+":"+wrapped] = dek
+	return dek, nil
+}
+
+func (e *envelope) encrypt(ctx context.Context, plain string) (string, error) {
+	if plain == "" || strings.HasPrefix(plain, sealedPrefix) {
+		return plain, nil
+	}
+	dek, keyID, wrapped, err := e.currentKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := aesSeal(dek, []byte(plain))
+	if err != nil {
+		return "", err
+	}
+	return sealedPrefix + keyID + ":" + wrapped + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (e *envelope) decrypt(ctx context.Context, value string) (string, error) {
+	if !strings.HasPrefix(value, sealedPrefix) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, sealedPrefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("the encrypted value is malformed")
+	}
+	dek, err := e.dataKey(ctx, parts[0], parts[1])
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+	plain, err := aesOpen(dek, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// seal encrypts the sensitive fields of the entity in place, the returned function restores the plaintext values
+func (e *envelope) seal(ctx context.Context, entity datastore.Entity) (func(), error) {
+	var fields []reflect.Value
+	var plains []string
+	restore := func() {
+		for i, field := range fields {
+			field.SetString(plains[i])
+		}
+	}
+	err := walkEncryptedFields(reflect.ValueOf(entity), func(field reflect.Value) error {
+		sealed, err := e.encrypt(ctx, field.String())
+		if err != nil {
+			return err
+		}
+		fields = append(fields, field)
+		plains = append(plains, field.String())
+		field.SetString(sealed)
+		return nil
+	})
+	if err != nil {
+		restore()
+		return nil, fmt.Errorf("encrypt the fields of %s failure %w", entity.TableName(), err)
+	}
+	return restore, nil
+}
+
+// open decrypts the sensitive fields of the entity in place
+func (e *envelope) open(ctx context.Context, entity datastore.Entity) error {
+	err := walkEncryptedFields(reflect.ValueOf(entity), func(field reflect.Value) error {
+		plain, err := e.decrypt(ctx, field.String())
+		if err != nil {
+			return err
+		}
+		field.SetString(plain)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("decrypt the fields of %s failure %w", entity.TableName(), err)
+	}
+	return nil
+}
+
+// encryptedTypes caches whether the struct types have the encrypted fields, most models have none
+var encryptedTypes sync.Map
+
+func hasEncryptedFields(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if cached, ok := encryptedTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	// the recursive types are treated as having no encrypted fields until they are resolved
+	encryptedTypes.Store(t, false)
+	has := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if (field.Type.Kind() == reflect.String && field.Tag.Get(encryptedTag) == "true") || hasEncryptedFields(field.Type) {
+			has = true
+			break
+		}
+	}
+	encryptedTypes.Store(t, has)
+	return has
+}
+
+// walkEncryptedFields calls fn with the tagged string fields of the value, the nested structs, pointers and slices are walked as well
+func walkEncryptedFields(v reflect.Value, fn func(field reflect.Value) error) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return walkEncryptedFields(v.Elem(), fn)
+	case reflect.Slice, reflect.Array:
+		if !hasEncryptedFields(v.Type()) {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := walkEncryptedFields(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if !hasEncryptedFields(v.Type()) {
+			return nil
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if field.Type.Kind() == reflect.String {
+				if field.Tag.Get(encryptedTag) == "true" && v.Field(i).CanSet() {
+					if err := fn(v.Field(i)); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := walkEncryptedFields(v.Field(i), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func aesSeal(key, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func aesOpen(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("the cipher text is too short")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// memoryStore stores the JSON of the entities by their primary keys
+type memoryStore struct {
+	datastore.DataStore
+	records map[string][]byte
+}
+
+func (m *memoryStore) Add(_ context.Context, entity datastore.Entity) error {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	m.records[entity.PrimaryKey()] = data
+	return nil
+}
+
+func (m *memoryStore) Put(ctx context.Context, entity datastore.Entity) error {
+	return m.Add(ctx, entity)
+}
+
+func (m *memoryStore) Get(_ context.Context, entity datastore.Entity) error {
+	data, ok := m.records[entity.PrimaryKey()]
+	if !ok {
+		return datastore.ErrRecordNotExist
+	}
+	return json.Unmarshal(data, entity)
+}
+
+func newSecretStore(t *testing.T, secretKey string) (datastore.DataStore, *memoryStore) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "velaux-encryption-keys", Namespace: "vela-system"},
+		Data: map[string][]byte{
+			"key1": bytes.Repeat([]byte("a"), 32),
+			"key2": bytes.Repeat([]byte("b"), 32),
+		},
+	}
+	keys, err := NewKeyProvider(Config{Provider: ProviderSecret, SecretNamespace: "vela-system", SecretName: "velaux-encryption-keys", SecretKey: secretKey},
+		fake.NewClientBuilder().WithObjects(secret).Build())
+	assert.NoError(t, err)
+	memory := &memoryStore{records: map[string][]byte{}}
+	return Wrap(memory, keys), memory
+}
+
+func TestEncryptTheTaggedFields(t *testing.T) {
+	ctx := context.Background()
+	store, memory := newSecretStore(t, "key1")
+	info := &model.SystemInfo{
+		InstallID: "install",
+		SignedKey: "signed-key",
+		OAuthConnectors: []model.OAuthConnector{
+			{ID: "github", ClientID: "client", ClientSecret: "client-secret"},
+		},
+	}
+	assert.NoError(t, store.Add(ctx, info))
+	assert.Equal(t, "signed-key", info.SignedKey)
+	assert.Equal(t, "client-secret", info.OAuthConnectors[0].ClientSecret)
+
+	stored := string(memory.records["install"])
+	assert.NotContains(t, stored, "signed-key")
+	assert.NotContains(t, stored, "client-secret")
+	assert.Contains(t, stored, `"clientID":"client"`)
+	assert.Contains(t, stored, sealedPrefix+"key1:")
+
+	read := &model.SystemInfo{InstallID: "install"}
+	assert.NoError(t, store.Get(ctx, read))
+	assert.Equal(t, "signed-key", read.SignedKey)
+	assert.Equal(t, "client-secret", read.OAuthConnectors[0].ClientSecret)
+}
+
+func TestReadThePlaintextAndRotatedValues(t *testing.T) {
+	ctx := context.Background()
+	store, memory := newSecretStore(t, "key1")
+	memory.records["catalog"] = []byte(`{"name":"catalog","token":"plain-token"}`)
+	catalog := &model.DefinitionCatalog{Name: "catalog"}
+	assert.NoError(t, store.Get(ctx, catalog))
+	assert.Equal(t, "plain-token", catalog.Token)
+	assert.NoError(t, store.Put(ctx, catalog))
+	assert.False(t, strings.Contains(string(memory.records["catalog"]), "plain-token"))
+
+	rotated, _ := newSecretStore(t, "key2")
+	rotated.(*encryptedStore).DataStore = memory
+	read := &model.DefinitionCatalog{Name: "catalog"}
+	assert.NoError(t, rotated.Get(ctx, read))
+	assert.Equal(t, "plain-token", read.Token)
+	assert.NoError(t, rotated.Put(ctx, read))
+	assert.Contains(t, string(memory.records["catalog"]), sealedPrefix+"key2:")
+
+	_, err := NewKeyProvider(Config{Provider: "vault"}, nil)
+	assert.Error(t, err)
+}
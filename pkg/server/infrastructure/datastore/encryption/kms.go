@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	kmsapi "k8s.io/apiserver/pkg/storage/value/encrypt/envelope/v1beta1"
+)
+
+const (
+	kmsAPIVersion = "v1beta1"
+	kmsKeyID      = "kms"
+	kmsTimeout    = 3 * time.Second
+)
+
+// kmsKeyProvider encrypts the data keys by the KMS plugins of Kubernetes, so the plugins of the cloud KMS services and Vault
+// could be used. The plugin tracks its own key versions in the cipher texts.
+type kmsKeyProvider struct {
+	client kmsapi.KeyManagementServiceClient
+}
+
+func newKMSKeyProvider(endpoint string) (KeyProvider, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("the endpoint of the KMS plugin is required")
+	}
+	conn, err := grpc.Dial(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connect to the KMS plugin %s failure %w", endpoint, err)
+	}
+	return &kmsKeyProvider{client: kmsapi.NewKeyManagementServiceClient(conn)}, nil
+}
+
+func (k *kmsKeyProvider) Wrap(ctx context.Context, dek []byte) (string, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, kmsTimeout)
+	defer cancel()
+	res, err := k.client.Encrypt(ctx, &kmsapi.EncryptRequest{Version: kmsAPIVersion, Plain: dek})
+	if err != nil {
+		return "", nil, err
+	}
+	return kmsKeyID, res.Cipher, nil
+}
+
+func (k *kmsKeyProvider) Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	if keyID != kmsKeyID {
+		return nil, fmt.Errorf("the data key is encrypted by the key %s, not the KMS plugin", keyID)
+	}
+	ctx, cancel := context.WithTimeout(ctx, kmsTimeout)
+	defer cancel()
+	res, err := k.client.Decrypt(ctx, &kmsapi.DecryptRequest{Version: kmsAPIVersion, Cipher: wrapped})
+	if err != nil {
+		return nil, err
+	}
+	return res.Plain, nil
+}
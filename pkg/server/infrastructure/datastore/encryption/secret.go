@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// secretKeyProvider encrypts the data keys by the 32 bytes keys of the secret, the keys are rotated by adding a new key
+// to the secret and switching the config to it
+type secretKeyProvider struct {
+	cfg        Config
+	kubeClient client.Client
+}
+
+func (s *secretKeyProvider) key(ctx context.Context, keyID string) ([]byte, error) {
+	var secret corev1.Secret
+	if err := s.kubeClient.Get(ctx, types.NamespacedName{Namespace: s.cfg.SecretNamespace, Name: s.cfg.SecretName}, &secret); err != nil {
+		return nil, fmt.Errorf("get the encryption key secret %s/%s failure %w", s.cfg.SecretNamespace, s.cfg.SecretName, err)
+	}
+	key, ok := secret.Data[keyID]
+	if !ok {
+		return nil, fmt.Errorf("the key %s is not found in the secret %s/%s", keyID, s.cfg.SecretNamespace, s.cfg.SecretName)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("the key %s of the secret %s/%s must be 32 bytes", keyID, s.cfg.SecretNamespace, s.cfg.SecretName)
+	}
+	return key, nil
+}
+
+func (s *secretKeyProvider) Wrap(ctx context.Context, dek []byte) (string, []byte, error) {
+	key, err := s.key(ctx, s.cfg.SecretKey)
+	if err != nil {
+		return "", nil, err
+	}
+	wrapped, err := aesSeal(key, dek)
+	if err != nil {
+		return "", nil, err
+	}
+	return s.cfg.SecretKey, wrapped, nil
+}
+
+func (s *secretKeyProvider) Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, err := s.key(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return aesOpen(key, wrapped)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// JanitorInterval the interval of deleting the expired entities by the drivers without the TTL indexes
+var JanitorInterval = time.Minute
+
+// ExpiringEntity the entity deleted by the datastore after it expires. MongoDB deletes the expired entities by the TTL index
+// and the other drivers delete them every JanitorInterval, so the expired entities could still be read for a while and
+// the readers must check the expiry themselves.
+type ExpiringEntity interface {
+	Entity
+	// GetExpireAt returns when the entity expires, nil means it never expires
+	GetExpireAt() *time.Time
+}
+
+// ExpireAt returns when the entity expires, nil is returned if it never expires
+func ExpireAt(entity Entity) *time.Time {
+	if expiring, ok := entity.(ExpiringEntity); ok {
+		return expiring.GetExpireAt()
+	}
+	return nil
+}
+
+// RunJanitor deletes the expired entities by clean every JanitorInterval until the context is done
+func RunJanitor(ctx context.Context, driver string, clean func(ctx context.Context) (int, error)) {
+	ticker := time.NewTicker(JanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		deleted, err := clean(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				klog.Errorf("delete the expired entities of the %s datastore failure %s", driver, err.Error())
+			}
+			continue
+		}
+		if deleted > 0 {
+			klog.Infof("%d expired entities of the %s datastore are deleted", deleted, driver)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// indexPrefix the prefix of the names of the indexes managed by the drivers, the other indexes of the tables such as
+	// the ones created by the administrators are never dropped
+	indexPrefix = "velaux_"
+	// uniqueIndexPrefix marks the unique indexes in the definitions
+	uniqueIndexPrefix = "unique:"
+	// maxIndexNameLength the length limit of the index names of MySQL, the longer names are shortened by the hash
+	maxIndexNameLength = 64
+)
+
+// indexKeyPattern the JSON keys of the indexed fields, the nested fields are joined by dots such as principal.type
+var indexKeyPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)*$`)
+
+// IndexedEntity the entity whose fields are indexed by the drivers. The indexes are created when the datastore starts
+// and the ones removed from the entity are dropped, so the queries and the sorts of the fields do not scan the tables.
+// The kubeapi driver has no indexes besides the labels, it only checks the unique indexes.
+type IndexedEntity interface {
+	Entity
+	// Indexes returns the definitions of the indexes, a definition is the JSON keys of the fields joined by commas such
+	// as project,-createTime. The key prefixed by - is descending. The index prefixed by unique: rejects the entity whose
+	// fields have the same values as another one by ErrRecordExist, the entities with an empty field are not constrained.
+	// The kubeapi driver checks the unique fields by the labels, so they must be in the index of the entity.
+	Indexes() []string
+}
+
+// Index an index of the fields of the entities
+type Index struct {
+	// Name is derived from the definition, so a changed index is dropped and created again with another name
+	Name   string
+	Fields []SortOption
+	Unique bool
+}
+
+// Keys returns the JSON keys of the fields
+func (i Index) Keys() []string {
+	keys := make([]string, 0, len(i.Fields))
+	for _, field := range i.Fields {
+		keys = append(keys, field.Key)
+	}
+	return keys
+}
+
+// ParseIndex parses the definition of the index, such as project,-createTime or unique:token
+func ParseIndex(definition string) (Index, error) {
+	index := Index{}
+	fields := definition
+	if strings.HasPrefix(fields, uniqueIndexPrefix) {
+		index.Unique = true
+		fields = strings.TrimPrefix(fields, uniqueIndexPrefix)
+	}
+	names := []string{strings.TrimSuffix(indexPrefix, "_")}
+	if index.Unique {
+		names = append(names, "unique")
+	}
+	seen := map[string]bool{}
+	for _, key := range strings.Split(fields, ",") {
+		field := SortOption{Key: strings.TrimSpace(key), Order: SortOrderAscending}
+		if strings.HasPrefix(field.Key, "-") {
+			field.Key = strings.TrimPrefix(field.Key, "-")
+			field.Order = SortOrderDescending
+		}
+		if !indexKeyPattern.MatchString(field.Key) || seen[field.Key] {
+			return Index{}, NewDBError(fmt.Errorf("the index %q is invalid", definition))
+		}
+		seen[field.Key] = true
+		index.Fields = append(index.Fields, field)
+		name := strings.ReplaceAll(field.Key, ".", "_")
+		if field.Order == SortOrderDescending {
+			name += "_desc"
+		}
+		names = append(names, name)
+	}
+	index.Name = strings.Join(names, "_")
+	if len(index.Name) > maxIndexNameLength {
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(index.Name))
+		index.Name = fmt.Sprintf("%s_%08x", index.Name[:maxIndexNameLength-9], hash.Sum32())
+	}
+	return index, nil
+}
+
+// EntityIndexes returns the indexes of the entity, it is empty if the entity is not an IndexedEntity.
+// The invalid definitions are skipped.
+func EntityIndexes(entity Entity) []Index {
+	indexed, ok := entity.(IndexedEntity)
+	if !ok {
+		return nil
+	}
+	var indexes []Index
+	for _, definition := range indexed.Indexes() {
+		index, err := ParseIndex(definition)
+		if err != nil {
+			klog.Errorf("skip the index of the table %s: %s", entity.TableName(), err.Error())
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes
+}
+
+// IndexedKeys returns the keys of the fields indexed by the entity
+func IndexedKeys(entity Entity) map[string]bool {
+	keys := map[string]bool{}
+	for _, index := range EntityIndexes(entity) {
+		for _, key := range index.Keys() {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// ReconcileIndexes creates the indexes of the entity missing in the existing ones and drops the existing managed indexes
+// the entity does not have any more. The failures of the indexes are logged and the others are still reconciled, because
+// a unique index could not be created until the duplicated entities are fixed by the administrators.
+func ReconcileIndexes(ctx context.Context, entity Entity, existing []string, create func(ctx context.Context, index Index) error, drop func(ctx context.Context, name string) error) {
+	desired := map[string]bool{}
+	for _, index := range EntityIndexes(entity) {
+		desired[index.Name] = true
+	}
+	current := map[string]bool{}
+	for _, name := range existing {
+		current[name] = true
+		if !strings.HasPrefix(name, indexPrefix) || desired[name] {
+			continue
+		}
+		if err := drop(ctx, name); err != nil {
+			klog.Errorf("drop the index %s of the table %s failure %s", name, entity.TableName(), err.Error())
+			continue
+		}
+		klog.Infof("the index %s of the table %s is dropped", name, entity.TableName())
+	}
+	for _, index := range EntityIndexes(entity) {
+		if current[index.Name] {
+			continue
+		}
+		if err := create(ctx, index); err != nil {
+			klog.Errorf("create the index %s of the table %s failure %s", index.Name, entity.TableName(), err.Error())
+			continue
+		}
+		klog.Infof("the index %s of the table %s is created", index.Name, entity.TableName())
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeapi
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// ExpireAtLabel the label of the unix time the entity expires, the ConfigMaps of the expiring entities are listed by it
+const ExpireAtLabel = "expireAt"
+
+// deleteExpired deletes the ConfigMaps of the expired entities of the default partition and all tenants
+func (m *kubeapi) deleteExpired(ctx context.Context) (int, error) {
+	deleted, err := m.deleteExpiredOf(ctx)
+	if err != nil {
+		return deleted, err
+	}
+	for _, tenant := range m.tenants {
+		count, err := m.deleteExpiredOf(datastore.WithTenant(ctx, tenant))
+		deleted += count
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// deleteExpiredOf deletes the ConfigMaps of the expired entities of the tenant of the context, a ConfigMap updated
+// after it is listed is kept because its expiry could be extended
+func (m *kubeapi) deleteExpiredOf(ctx context.Context) (int, error) {
+	rq, err := labels.NewRequirement(ExpireAtLabel, selection.Exists, nil)
+	if err != nil {
+		return 0, err
+	}
+	configMaps, err := m.listConfigMaps(ctx, labels.NewSelector().Add(*rq))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().Unix()
+	var deleted int
+	for i := range configMaps {
+		configMap := &configMaps[i]
+		expireAt, err := strconv.ParseInt(configMap.Labels[ExpireAtLabel], 10, 64)
+		if err != nil || expireAt > now {
+			continue
+		}
+		resourceVersion := configMap.ResourceVersion
+		if err := m.kubeClient.Delete(ctx, configMap, client.Preconditions{ResourceVersion: &resourceVersion}); err != nil {
+			if !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+				klog.Warningf("delete the expired configmap %s failure %s", configMap.Name, err.Error())
+			}
+			continue
+		}
+		if m.searchIndexes != nil {
+			m.searchIndexes.Delete(datastore.TenantKey(ctx, configMap.Labels["table"]))
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeapi
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// filterSelector adds the label requirements of the filter options to the selector, the fuzzy queries and the OR groups
+// could not be selected by the labels so they are matched by _filterConfigMapByFilterOptions.
+// It returns false if nothing could be matched, the label selector could not express the IN requirement without values.
+func filterSelector(selector labels.Selector, op datastore.FilterOptions) (labels.Selector, bool, error) {
+	for _, inFilter := range op.In {
+		if len(inFilter.Values) == 0 {
+			return selector, false, nil
+		}
+		rq, err := labels.NewRequirement(inFilter.Key, selection.In, verifyValues(inFilter.Values))
+		if err != nil {
+			klog.Errorf("new list requirement failure %s", err.Error())
+			return nil, false, datastore.ErrIndexInvalid
+		}
+		selector = selector.Add(*rq)
+	}
+	for _, notInFilter := range op.NotIn {
+		if len(notInFilter.Values) == 0 {
+			continue
+		}
+		rq, err := labels.NewRequirement(notInFilter.Key, selection.NotIn, verifyValues(notInFilter.Values))
+		if err != nil {
+			klog.Errorf("new list requirement failure %s", err.Error())
+			return nil, false, datastore.ErrIndexInvalid
+		}
+		selector = selector.Add(*rq)
+	}
+	for _, existFilter := range op.IsExist {
+		rq, err := labels.NewRequirement(existFilter.Key, selection.Exists, []string{})
+		if err != nil {
+			klog.Errorf("new list requirement failure %s", err.Error())
+			return nil, false, datastore.ErrIndexInvalid
+		}
+		selector = selector.Add(*rq)
+	}
+	for _, notFilter := range op.IsNotExist {
+		rq, err := labels.NewRequirement(notFilter.Key, selection.DoesNotExist, []string{})
+		if err != nil {
+			klog.Errorf("new list requirement failure %s", err.Error())
+			return nil, false, datastore.ErrIndexInvalid
+		}
+		selector = selector.Add(*rq)
+	}
+	return selector, true, nil
+}
+
+func verifyValues(values []string) []string {
+	verified := make([]string, 0, len(values))
+	for _, value := range values {
+		verified = append(verified, verifyValue(value))
+	}
+	return verified
+}
+
+// _filterConfigMapByFilterOptions keeps the ConfigMaps matched by the fuzzy queries and the OR groups,
+// the label requirements are selected by the API server
+func _filterConfigMapByFilterOptions(items []corev1.ConfigMap, op datastore.FilterOptions) ([]corev1.ConfigMap, error) {
+	if len(op.Queries) == 0 && len(op.Or) == 0 {
+		return items, nil
+	}
+	var _items []corev1.ConfigMap
+	for _, item := range items {
+		matched, err := matchOrGroups(item, op.Or)
+		if err != nil {
+			return nil, err
+		}
+		if matched && matchFuzzyQueries(item, op.Queries) {
+			_items = append(_items, item)
+		}
+	}
+	return _items, nil
+}
+
+// matchFilterOptions returns true if the ConfigMap matches all the filter options
+func matchFilterOptions(item corev1.ConfigMap, op datastore.FilterOptions) (bool, error) {
+	selector, matchable, err := filterSelector(labels.NewSelector(), op)
+	if err != nil || !matchable || !selector.Matches(labels.Set(item.Labels)) || !matchFuzzyQueries(item, op.Queries) {
+		return false, err
+	}
+	return matchOrGroups(item, op.Or)
+}
+
+// matchOrGroups returns true if the ConfigMap matches one of the groups or there is no group
+func matchOrGroups(item corev1.ConfigMap, groups []datastore.FilterOptions) (bool, error) {
+	if len(groups) == 0 {
+		return true, nil
+	}
+	for _, group := range groups {
+		matched, err := matchFilterOptions(item, group)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func matchFuzzyQueries(item corev1.ConfigMap, queries []datastore.FuzzyQueryOption) bool {
+	data := string(item.BinaryData["data"])
+	for _, query := range queries {
+		res := gjson.Get(data, query.Key)
+		if res.Type != gjson.String || !strings.Contains(res.Str, query.Query) {
+			return false
+		}
+	}
+	return true
+}
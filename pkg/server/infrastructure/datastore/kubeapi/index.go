@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	pkgUtils "github.com/oam-dev/kubevela/pkg/utils"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// checkUnique rejects the entity if another one has the same values of the fields of a unique index. The ConfigMaps
+// are selected by the labels of the index of the entity, so the unique fields must be in the index. The API server
+// has no unique constraints, so the concurrent writes of the same values are not prevented.
+func (m *kubeapi) checkUnique(ctx context.Context, entity datastore.Entity) error {
+	index := entity.Index()
+	for _, unique := range datastore.EntityIndexes(entity) {
+		if !unique.Unique {
+			continue
+		}
+		query, err := datastore.NewEntity(entity)
+		if err != nil {
+			return err
+		}
+		selector, err := indexSelector(query)
+		if err != nil {
+			return err
+		}
+		values := map[string]string{}
+		for _, key := range unique.Keys() {
+			value := pkgUtils.ToString(index[key])
+			if index[key] == nil || value == "" {
+				break
+			}
+			rq, err := labels.NewRequirement(key, selection.Equals, []string{verifyValue(value)})
+			if err != nil {
+				return datastore.ErrIndexInvalid
+			}
+			selector = selector.Add(*rq)
+			values[key] = value
+		}
+		if len(values) < len(unique.Fields) {
+			continue
+		}
+		items, err := m.listConfigMaps(ctx, selector)
+		if err != nil {
+			return datastore.NewDBError(err)
+		}
+		for _, item := range items {
+			other, err := datastore.NewEntity(entity)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(item.BinaryData["data"], other); err != nil || other.PrimaryKey() == entity.PrimaryKey() {
+				continue
+			}
+			// the labels are lowercase, so the values are compared again
+			duplicated := true
+			for key, value := range values {
+				if pkgUtils.ToString(other.Index()[key]) != value {
+					duplicated = false
+				}
+			}
+			if duplicated {
+				return datastore.ErrRecordExist
+			}
+		}
+	}
+	return nil
+}
@@ -22,7 +22,9 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/tidwall/gjson"
@@ -42,30 +44,57 @@ import (
 
 type kubeapi struct {
 	kubeClient client.Client
-	namespace  string
+	// namespaces the namespaces of the shards, the ConfigMap of an entity is stored in one of them by the hash of its name
+	namespaces []string
+	// database the namespace of the first shard, the namespaces of the tenants are prefixed by it
+	database string
+	// tenants the isolated tenants, each one stores its ConfigMaps in its own shards
+	tenants []string
+	// searchIndexes the in-memory search indexes by the tenants and the table names
+	searchIndexes *sync.Map
+	// watchClient the client to watch the ConfigMaps, it is created on the first watch if the kube client could not watch
+	watchClient     client.WithWatch
+	watchClientErr  error
+	watchClientOnce sync.Once
 }
 
 // New new kubeapi datastore instance
-// Data is stored using ConfigMap.
+// Data is stored using ConfigMap, the ConfigMaps are spread across the namespaces of the shards.
 func New(ctx context.Context, cfg datastore.Config, client client.Client) (datastore.DataStore, error) {
 	if cfg.Database == "" {
 		cfg.Database = "kubevela_store"
 	}
-	var namespace corev1.Namespace
-	if err := client.Get(ctx, types.NamespacedName{Name: cfg.Database}, &namespace); apierrors.IsNotFound(err) {
-		if err := client.Create(ctx, &corev1.Namespace{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:        cfg.Database,
-				Annotations: map[string]string{"description": "For KubeVela API Server metadata storage."},
-			}}); err != nil {
-			return nil, fmt.Errorf("create namespace failure %w", err)
+	namespaces := shardNamespaces(cfg.Database, cfg.Shards)
+	for _, namespace := range namespaces {
+		if err := ensureNamespace(ctx, client, namespace); err != nil {
+			return nil, err
 		}
 	}
 	migrate(cfg.Database, client)
-	return &kubeapi{
-		kubeClient: client,
-		namespace:  cfg.Database,
-	}, nil
+	if err := rebalance(ctx, client, cfg.Database, namespaces); err != nil {
+		return nil, fmt.Errorf("move the configmaps to the shards failure %w", err)
+	}
+	for _, tenant := range cfg.Tenants {
+		database := tenantDatabase(cfg.Database, tenant)
+		tenantNamespaces := shardNamespaces(database, cfg.Shards)
+		for _, namespace := range tenantNamespaces {
+			if err := ensureNamespace(ctx, client, namespace); err != nil {
+				return nil, err
+			}
+		}
+		if err := rebalance(ctx, client, database, tenantNamespaces); err != nil {
+			return nil, fmt.Errorf("move the configmaps of the tenant %s to the shards failure %w", tenant, err)
+		}
+	}
+	m := &kubeapi{
+		kubeClient:    client,
+		namespaces:    namespaces,
+		database:      cfg.Database,
+		tenants:       cfg.Tenants,
+		searchIndexes: &sync.Map{},
+	}
+	go datastore.RunJanitor(ctx, "kubeapi", m.deleteExpired)
+	return m, nil
 }
 
 func generateName(entity datastore.Entity) string {
@@ -76,8 +105,8 @@ func generateName(entity datastore.Entity) string {
 	return strings.ReplaceAll(name, "_", "-")
 }
 
-func (m *kubeapi) generateConfigMap(entity datastore.Entity) *corev1.ConfigMap {
-	data, _ := json.Marshal(entity)
+// entityLabels the labels of the ConfigMap of the entity, the index is converted to the labels to list the entities
+func entityLabels(entity datastore.Entity) map[string]string {
 	labels := convertIndex2Labels(entity.Index())
 	if labels == nil {
 		labels = make(map[string]string)
@@ -87,10 +116,19 @@ func (m *kubeapi) generateConfigMap(entity datastore.Entity) *corev1.ConfigMap {
 	for k, v := range labels {
 		labels[k] = verifyValue(v)
 	}
+	if expireAt := datastore.ExpireAt(entity); expireAt != nil {
+		labels[ExpireAtLabel] = strconv.FormatInt(expireAt.Unix(), 10)
+	}
+	return labels
+}
+
+func (m *kubeapi) generateConfigMap(ctx context.Context, entity datastore.Entity) *corev1.ConfigMap {
+	data, _ := json.Marshal(entity)
+	labels := entityLabels(entity)
 	var configMap = corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      generateName(entity),
-			Namespace: m.namespace,
+			Namespace: m.namespaceOf(ctx, entity),
 			Labels:    labels,
 		},
 		BinaryData: map[string][]byte{
@@ -110,13 +148,18 @@ func (m *kubeapi) Add(ctx context.Context, entity datastore.Entity) error {
 	}
 	entity.SetCreateTime(time.Now())
 	entity.SetUpdateTime(time.Now())
-	configMap := m.generateConfigMap(entity)
+	entity.SetResourceVersion(1)
+	if err := m.checkUnique(ctx, entity); err != nil {
+		return err
+	}
+	configMap := m.generateConfigMap(ctx, entity)
 	if err := m.kubeClient.Create(ctx, configMap); err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			return datastore.ErrRecordExist
 		}
 		return datastore.NewDBError(err)
 	}
+	m.invalidateSearchIndex(ctx, entity)
 	return nil
 }
 
@@ -143,6 +186,11 @@ func (m *kubeapi) BatchAdd(ctx context.Context, entities []datastore.Entity) err
 	return nil
 }
 
+// Transaction the writes are undone by the compensating writes, there are no multi-document transactions in the Kubernetes API
+func (m *kubeapi) Transaction(ctx context.Context, fn func(tx datastore.DataStore) error) error {
+	return datastore.CompensatingTransaction(ctx, m, fn)
+}
+
 // Get get data model
 func (m *kubeapi) Get(ctx context.Context, entity datastore.Entity) error {
 	if entity.PrimaryKey() == "" {
@@ -152,7 +200,7 @@ func (m *kubeapi) Get(ctx context.Context, entity datastore.Entity) error {
 		return datastore.ErrTableNameEmpty
 	}
 	var configMap corev1.ConfigMap
-	if err := m.kubeClient.Get(ctx, types.NamespacedName{Namespace: m.namespace, Name: generateName(entity)}, &configMap); err != nil {
+	if err := m.kubeClient.Get(ctx, types.NamespacedName{Namespace: m.namespaceOf(ctx, entity), Name: generateName(entity)}, &configMap); err != nil {
 		if apierrors.IsNotFound(err) {
 			return datastore.ErrRecordNotExist
 		}
@@ -172,36 +220,60 @@ func (m *kubeapi) Put(ctx context.Context, entity datastore.Entity) error {
 	if entity.TableName() == "" {
 		return datastore.ErrTableNameEmpty
 	}
-	// update labels
-	labels := convertIndex2Labels(entity.Index())
-	if labels == nil {
-		labels = make(map[string]string)
-	}
-	labels["table"] = entity.TableName()
-	labels["primaryKey"] = entity.PrimaryKey()
-	for k, v := range labels {
-		labels[k] = verifyValue(v)
-	}
 	entity.SetUpdateTime(time.Now())
 	var configMap corev1.ConfigMap
-	if err := m.kubeClient.Get(ctx, types.NamespacedName{Namespace: m.namespace, Name: generateName(entity)}, &configMap); err != nil {
+	if err := m.kubeClient.Get(ctx, types.NamespacedName{Namespace: m.namespaceOf(ctx, entity), Name: generateName(entity)}, &configMap); err != nil {
 		if apierrors.IsNotFound(err) {
 			return datastore.ErrRecordNotExist
 		}
 		return datastore.NewDBError(err)
 	}
+	stored, err := datastore.NewEntity(entity)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(configMap.BinaryData["data"], stored); err != nil {
+		return datastore.NewDBError(err)
+	}
+	if err := datastore.CheckResourceVersion(entity, stored.GetResourceVersion()); err != nil {
+		return err
+	}
+	if err := m.checkUnique(ctx, entity); err != nil {
+		return err
+	}
+	version := entity.GetResourceVersion()
+	entity.SetResourceVersion(stored.GetResourceVersion() + 1)
 	data, err := json.Marshal(entity)
 	if err != nil {
+		entity.SetResourceVersion(version)
 		return datastore.NewDBError(err)
 	}
 	configMap.BinaryData["data"] = data
-	configMap.Labels = labels
+	configMap.Labels = entityLabels(entity)
+	// the update fails if the config map is updated by others since it was got
 	if err := m.kubeClient.Update(ctx, &configMap); err != nil {
+		entity.SetResourceVersion(version)
+		if apierrors.IsConflict(err) {
+			return datastore.ErrRecordConflict
+		}
 		return datastore.NewDBError(err)
 	}
+	m.invalidateSearchIndex(ctx, entity)
 	return nil
 }
 
+// BatchPut update entities, the updated entities are restored by the compensating writes if any fails
+func (m *kubeapi) BatchPut(ctx context.Context, entities []datastore.Entity) error {
+	return m.Transaction(ctx, func(tx datastore.DataStore) error {
+		for _, entity := range entities {
+			if err := tx.Put(ctx, entity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // IsExist determine whether data exists.
 func (m *kubeapi) IsExist(ctx context.Context, entity datastore.Entity) (bool, error) {
 	if entity.PrimaryKey() == "" {
@@ -211,7 +283,7 @@ func (m *kubeapi) IsExist(ctx context.Context, entity datastore.Entity) (bool, e
 		return false, datastore.ErrTableNameEmpty
 	}
 	var configMap corev1.ConfigMap
-	if err := m.kubeClient.Get(ctx, types.NamespacedName{Namespace: m.namespace, Name: generateName(entity)}, &configMap); err != nil {
+	if err := m.kubeClient.Get(ctx, types.NamespacedName{Namespace: m.namespaceOf(ctx, entity), Name: generateName(entity)}, &configMap); err != nil {
 		if apierrors.IsNotFound(err) {
 			return false, nil
 		}
@@ -228,12 +300,13 @@ func (m *kubeapi) Delete(ctx context.Context, entity datastore.Entity) error {
 	if entity.TableName() == "" {
 		return datastore.ErrTableNameEmpty
 	}
-	if err := m.kubeClient.Delete(ctx, m.generateConfigMap(entity)); err != nil {
+	if err := m.kubeClient.Delete(ctx, m.generateConfigMap(ctx, entity)); err != nil {
 		if apierrors.IsNotFound(err) {
 			return datastore.ErrRecordNotExist
 		}
 		return datastore.NewDBError(err)
 	}
+	m.invalidateSearchIndex(ctx, entity)
 	return nil
 }
 
@@ -260,6 +333,8 @@ func newBySortOptionConfigMap(items []corev1.ConfigMap, sortBy []datastore.SortO
 			default:
 				if !res.Time().IsZero() {
 					m[op.Key] = res.Time()
+				} else if res.Type == gjson.String {
+					m[op.Key] = res.Str
 				} else {
 					m[op.Key] = res.Raw
 				}
@@ -283,6 +358,18 @@ func (b bySortOptionConfigMap) Less(i, j int) bool {
 	for _, op := range b.sortBy {
 		x := b.objects[i][op.Key]
 		y := b.objects[j][op.Key]
+		// the strings, such as the names, are sorted in the lexical order
+		if _x, xok := x.(string); xok {
+			if _y, yok := y.(string); yok {
+				if _x == _y {
+					continue
+				}
+				if op.Order == datastore.SortOrderAscending {
+					return _x < _y
+				}
+				return _x > _y
+			}
+		}
 		_x, xok := x.(time.Time)
 		_y, yok := y.(time.Time)
 		var xScore, yScore float64
@@ -315,72 +402,26 @@ func _sortConfigMapBySortOptions(items []corev1.ConfigMap, sortOptions []datasto
 	return so.items
 }
 
-func _filterConfigMapByFuzzyQueryOptions(items []corev1.ConfigMap, queries []datastore.FuzzyQueryOption) []corev1.ConfigMap {
-	var _items []corev1.ConfigMap
-	for _, item := range items {
-		data := string(item.BinaryData["data"])
-		valid := true
-		for _, query := range queries {
-			res := gjson.Get(data, query.Key)
-			if res.Type != gjson.String || !strings.Contains(res.Str, query.Query) {
-				valid = false
-				break
-			}
-		}
-		if valid {
-			_items = append(_items, item)
-		}
-	}
-	return _items
-}
-
 // List will list all database records by select labels according to table name
 func (m *kubeapi) List(ctx context.Context, entity datastore.Entity, op *datastore.ListOptions) ([]datastore.Entity, error) {
 	if entity.TableName() == "" {
 		return nil, datastore.ErrTableNameEmpty
 	}
 
-	selector, err := labels.Parse(fmt.Sprintf("table=%s", entity.TableName()))
+	selector, err := indexSelector(entity)
 	if err != nil {
-		return nil, datastore.NewDBError(err)
-	}
-
-	rq, _ := labels.NewRequirement(MigrateKey, selection.DoesNotExist, []string{"ok"})
-	selector = selector.Add(*rq)
-	metedataLabels := convertIndex2Labels(entity.Index())
-	for k, v := range metedataLabels {
-		rq, err := labels.NewRequirement(k, selection.Equals, []string{verifyValue(v)})
-		if err != nil {
-			return nil, datastore.ErrIndexInvalid
-		}
-		selector = selector.Add(*rq)
+		return nil, err
 	}
 	if op != nil {
-		for _, inFilter := range op.In {
-			var values []string
-			for _, value := range inFilter.Values {
-				values = append(values, verifyValue(value))
-			}
-			rq, err := labels.NewRequirement(inFilter.Key, selection.In, values)
-			if err != nil {
-				klog.Errorf("new list requirement failure %s", err.Error())
-				return nil, datastore.ErrIndexInvalid
-			}
-			selector = selector.Add(*rq)
+		var matchable bool
+		selector, matchable, err = filterSelector(selector, op.FilterOptions)
+		if err != nil {
+			return nil, err
 		}
-		for _, notFilter := range op.IsNotExist {
-			rq, err := labels.NewRequirement(notFilter.Key, selection.DoesNotExist, []string{})
-			if err != nil {
-				klog.Errorf("new list requirement failure %s", err.Error())
-				return nil, datastore.ErrIndexInvalid
-			}
-			selector = selector.Add(*rq)
+		if !matchable {
+			return nil, nil
 		}
 	}
-	options := &client.ListOptions{
-		LabelSelector: selector,
-		Namespace:     m.namespace,
-	}
 	var skip, limit int
 	if op != nil && op.PageSize > 0 && op.Page > 0 {
 		skip = op.PageSize * (op.Page - 1)
@@ -389,16 +430,14 @@ func (m *kubeapi) List(ctx context.Context, entity datastore.Entity, op *datasto
 			skip = 0
 		}
 	}
-	var configMaps corev1.ConfigMapList
-	if err := m.kubeClient.List(ctx, &configMaps, options); err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil, nil
-		}
+	items, err := m.listConfigMaps(ctx, selector)
+	if err != nil {
 		return nil, datastore.NewDBError(err)
 	}
-	items := configMaps.Items
-	if op != nil && len(op.Queries) > 0 {
-		items = _filterConfigMapByFuzzyQueryOptions(items, op.Queries)
+	if op != nil {
+		if items, err = _filterConfigMapByFilterOptions(items, op.FilterOptions); err != nil {
+			return nil, err
+		}
 	}
 	if op != nil && len(op.SortBy) > 0 {
 		items = _sortConfigMapBySortOptions(items, op.SortBy)
@@ -428,63 +467,88 @@ func (m *kubeapi) List(ctx context.Context, entity datastore.Entity, op *datasto
 	return list, nil
 }
 
-// Count counts entities
-func (m *kubeapi) Count(ctx context.Context, entity datastore.Entity, filterOptions *datastore.FilterOptions) (int64, error) {
-	if entity.TableName() == "" {
-		return 0, datastore.ErrTableNameEmpty
-	}
-
+// indexSelector selects the ConfigMaps of the table matched by the index of the entity, the migrated ConfigMaps are excluded
+func indexSelector(entity datastore.Entity) (labels.Selector, error) {
 	selector, err := labels.Parse(fmt.Sprintf("table=%s", entity.TableName()))
 	if err != nil {
-		return 0, datastore.NewDBError(err)
+		return nil, datastore.NewDBError(err)
 	}
+
+	rq, _ := labels.NewRequirement(MigrateKey, selection.DoesNotExist, []string{"ok"})
+	selector = selector.Add(*rq)
 	metedataLabels := convertIndex2Labels(entity.Index())
 	for k, v := range metedataLabels {
 		rq, err := labels.NewRequirement(k, selection.Equals, []string{verifyValue(v)})
 		if err != nil {
-			return 0, datastore.ErrIndexInvalid
+			return nil, datastore.ErrIndexInvalid
 		}
 		selector = selector.Add(*rq)
 	}
-	if filterOptions != nil {
-		for _, inFilter := range filterOptions.In {
-			var values []string
-			for _, value := range inFilter.Values {
-				values = append(values, verifyValue(value))
-			}
-			rq, err := labels.NewRequirement(inFilter.Key, selection.In, values)
-			if err != nil {
-				return 0, datastore.ErrIndexInvalid
-			}
-			selector = selector.Add(*rq)
-		}
-		for _, notFilter := range filterOptions.IsNotExist {
-			rq, err := labels.NewRequirement(notFilter.Key, selection.DoesNotExist, []string{})
-			if err != nil {
-				klog.Errorf("new list requirement failure %s", err.Error())
-				return 0, datastore.ErrIndexInvalid
+	return selector, nil
+}
+
+// Count counts entities
+func (m *kubeapi) Count(ctx context.Context, entity datastore.Entity, filterOptions *datastore.FilterOptions) (int64, error) {
+	items, err := m.filterConfigMaps(ctx, entity, filterOptions)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(items)), nil
+}
+
+// DeleteByFilter deletes the ConfigMaps matched by the query and the filter options one by one, the API server has no
+// deletion by the field values, so the deletion is not atomic.
+func (m *kubeapi) DeleteByFilter(ctx context.Context, entity datastore.Entity, filterOptions *datastore.FilterOptions) (int64, error) {
+	items, err := m.filterConfigMaps(ctx, entity, filterOptions)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for i := range items {
+		if err := m.kubeClient.Delete(ctx, &items[i]); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
 			}
-			selector = selector.Add(*rq)
+			m.invalidateSearchIndex(ctx, entity)
+			return deleted, datastore.NewDBError(err)
 		}
+		deleted++
 	}
+	m.invalidateSearchIndex(ctx, entity)
+	return deleted, nil
+}
 
-	options := &client.ListOptions{
-		LabelSelector: selector,
-		Namespace:     m.namespace,
+// filterConfigMaps lists the ConfigMaps matched by the index of the entity and the filter options
+func (m *kubeapi) filterConfigMaps(ctx context.Context, entity datastore.Entity, filterOptions *datastore.FilterOptions) ([]corev1.ConfigMap, error) {
+	if entity.TableName() == "" {
+		return nil, datastore.ErrTableNameEmpty
 	}
 
-	var configMaps corev1.ConfigMapList
-	if err := m.kubeClient.List(ctx, &configMaps, options); err != nil {
-		if apierrors.IsNotFound(err) {
-			return 0, nil
+	selector, err := indexSelector(entity)
+	if err != nil {
+		return nil, err
+	}
+	if filterOptions != nil {
+		var matchable bool
+		selector, matchable, err = filterSelector(selector, *filterOptions)
+		if err != nil {
+			return nil, err
+		}
+		if !matchable {
+			return nil, nil
 		}
-		return 0, datastore.NewDBError(err)
 	}
-	items := configMaps.Items
-	if filterOptions != nil && len(filterOptions.Queries) > 0 {
-		items = _filterConfigMapByFuzzyQueryOptions(configMaps.Items, filterOptions.Queries)
+
+	items, err := m.listConfigMaps(ctx, selector)
+	if err != nil {
+		return nil, datastore.NewDBError(err)
 	}
-	return int64(len(items)), nil
+	if filterOptions != nil {
+		if items, err = _filterConfigMapByFilterOptions(items, *filterOptions); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
 }
 
 func verifyValue(v string) string {
@@ -33,6 +33,7 @@ import (
 
 	"github.com/kubevela/velaux/pkg/server/infrastructure/clients"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/datastoretest"
 )
 
 func TestKubeapi(t *testing.T) {
@@ -73,7 +74,7 @@ var _ = BeforeSuite(func(done Done) {
 	By("new kube client success")
 
 	clients.SetKubeClient(k8sClient)
-	kubeStore, err = New(context.TODO(), datastore.Config{Database: "test"}, k8sClient)
+	kubeStore, err = New(context.TODO(), datastore.Config{Database: "test", Tenants: []string{datastoretest.ConformanceTenant}}, k8sClient)
 	Expect(err).Should(BeNil())
 	Expect(kubeStore).ToNot(BeNil())
 	close(done)
@@ -26,14 +26,22 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/datastoretest"
 )
 
 var _ = Describe("Test kubeapi datastore driver", func() {
+	datastoretest.DescribeFilterConformance(func() datastore.DataStore { return kubeStore })
+	datastoretest.DescribeTenantConformance(func() datastore.DataStore { return kubeStore })
+	datastoretest.DescribeIndexConformance(func() datastore.DataStore { return kubeStore })
+	datastoretest.DescribeBatchConformance(func() datastore.DataStore { return kubeStore })
+	datastoretest.DescribeTransactionConformance(func() datastore.DataStore { return kubeStore })
 
 	It("Test add function", func() {
 		app := &model.Application{Name: "kubevela-app", Description: "default"}
@@ -71,6 +79,17 @@ var _ = Describe("Test kubeapi datastore driver", func() {
 	It("Test put function", func() {
 		err := kubeStore.Put(context.TODO(), &model.Application{Name: "kubevela-app", Description: "this is demo"})
 		Expect(err).ToNot(HaveOccurred())
+
+		By("the stale entity could not be put")
+		stale := &model.Application{Name: "kubevela-app"}
+		Expect(kubeStore.Get(context.TODO(), stale)).Should(Succeed())
+		fresh := &model.Application{Name: "kubevela-app"}
+		Expect(kubeStore.Get(context.TODO(), fresh)).Should(Succeed())
+		fresh.Description = "updated"
+		Expect(kubeStore.Put(context.TODO(), fresh)).Should(Succeed())
+		Expect(fresh.ResourceVersion).Should(Equal(stale.ResourceVersion + 1))
+		stale.Description = "stale"
+		Expect(kubeStore.Put(context.TODO(), stale)).Should(Equal(datastore.ErrRecordConflict))
 	})
 	It("Test application index", func() {
 		var app = model.Application{
@@ -196,6 +215,15 @@ var _ = Describe("Test kubeapi datastore driver", func() {
 		for i, name := range []string{"third", "first"} {
 			Expect(entities[i].(*model.Cluster).Name).Should(Equal(name))
 		}
+
+		entities, err = kubeStore.List(context.TODO(), &model.Cluster{}, &datastore.ListOptions{
+			SortBy: []datastore.SortOption{{Key: "name", Order: datastore.SortOrderAscending}},
+		})
+		Expect(err).Should(Succeed())
+		Expect(len(entities)).Should(Equal(3))
+		for i, name := range []string{"first", "second", "third"} {
+			Expect(entities[i].(*model.Cluster).Name).Should(Equal(name))
+		}
 	})
 
 	It("Test count function", func() {
@@ -243,6 +271,36 @@ var _ = Describe("Test kubeapi datastore driver", func() {
 		Expect(diff).Should(BeEmpty())
 	})
 
+	It("Test transaction function", func() {
+		ctx := context.TODO()
+		Expect(kubeStore.Add(ctx, &model.Target{Name: "tx-target", Alias: "before"})).Should(Succeed())
+		err := kubeStore.Transaction(ctx, func(tx datastore.DataStore) error {
+			if err := tx.Add(ctx, &model.Target{Name: "tx-target-2"}); err != nil {
+				return err
+			}
+			if err := tx.Put(ctx, &model.Target{Name: "tx-target", Alias: "after"}); err != nil {
+				return err
+			}
+			return tx.Add(ctx, &model.Target{Name: "tx-target"})
+		})
+		equal := cmp.Equal(err, datastore.ErrRecordExist, cmpopts.EquateErrors())
+		Expect(equal).Should(BeTrue())
+		By("the writes are rolled back")
+		exist, err := kubeStore.IsExist(ctx, &model.Target{Name: "tx-target-2"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(exist).Should(BeFalse())
+		target := &model.Target{Name: "tx-target"}
+		Expect(kubeStore.Get(ctx, target)).Should(Succeed())
+		Expect(target.Alias).Should(Equal("before"))
+
+		Expect(kubeStore.Transaction(ctx, func(tx datastore.DataStore) error {
+			return tx.Delete(ctx, &model.Target{Name: "tx-target"})
+		})).Should(Succeed())
+		exist, err = kubeStore.IsExist(ctx, &model.Target{Name: "tx-target"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(exist).Should(BeFalse())
+	})
+
 	It("Test delete function", func() {
 		var app model.Application
 		app.Name = "kubevela-app"
@@ -304,4 +362,146 @@ var _ = Describe("Test kubeapi datastore driver", func() {
 		err = kubeStore.Delete(context.TODO(), &usr)
 		Expect(err).ShouldNot(HaveOccurred())
 	})
+
+	It("Test search function", func() {
+		ctx := context.TODO()
+		Expect(kubeStore.Add(ctx, &model.Application{Name: "search-payment-api", Alias: "Payment API", Project: "search-project", Description: "handles the checkout payments"})).Should(Succeed())
+		Expect(kubeStore.Add(ctx, &model.Application{Name: "search-payroll", Project: "search-project", Description: "the monthly payroll"})).Should(Succeed())
+
+		list, err := kubeStore.Search(ctx, &model.Application{}, "Payment", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(1))
+		Expect(list[0].(*model.Application).Name).Should(Equal("search-payment-api"))
+
+		By("the keywords match the word prefixes")
+		list, err = kubeStore.Search(ctx, &model.Application{}, "pay", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(2))
+
+		By("all keywords are required")
+		list, err = kubeStore.Search(ctx, &model.Application{}, "payroll checkout", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(list).Should(BeEmpty())
+
+		By("the entities are filtered by the index of the query")
+		list, err = kubeStore.Search(ctx, &model.Application{Project: "other-project"}, "payroll", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(list).Should(BeEmpty())
+
+		By("the updated fields are searchable")
+		Expect(kubeStore.Put(ctx, &model.Application{Name: "search-payroll", Project: "search-project", Description: "the monthly salaries"})).Should(Succeed())
+		list, err = kubeStore.Search(ctx, &model.Application{}, "salaries", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(1))
+
+		_, err = kubeStore.Search(ctx, &model.Workflow{}, "payroll", nil)
+		Expect(err).Should(Equal(datastore.ErrEntityInvalid))
+		Expect(kubeStore.Delete(ctx, &model.Application{Name: "search-payment-api"})).Should(Succeed())
+		Expect(kubeStore.Delete(ctx, &model.Application{Name: "search-payroll"})).Should(Succeed())
+	})
+
+	It("Test watch function", func() {
+		watchClient, err := client.NewWithWatch(cfg, client.Options{Scheme: testScheme})
+		Expect(err).ShouldNot(HaveOccurred())
+		watchStore, err := New(context.TODO(), datastore.Config{Database: "test"}, watchClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		Expect(kubeStore.Add(ctx, &model.Application{Name: "watch-existing", Project: "watch-project"})).Should(Succeed())
+		events, err := watchStore.Watch(ctx, &model.Application{Project: "watch-project"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("the entities out of the index of the query are not sent")
+		Expect(kubeStore.Add(ctx, &model.Application{Name: "watch-other", Project: "other-project"})).Should(Succeed())
+		Expect(kubeStore.Add(ctx, &model.Application{Name: "watch-app", Project: "watch-project"})).Should(Succeed())
+		var event datastore.WatchEvent
+		Eventually(events, time.Minute).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(datastore.WatchEventAdded))
+		Expect(event.Entity.PrimaryKey()).Should(Equal("watch-app"))
+
+		Expect(kubeStore.Put(ctx, &model.Application{Name: "watch-app", Project: "watch-project", Alias: "Watch App"})).Should(Succeed())
+		Eventually(events, time.Minute).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(datastore.WatchEventModified))
+		Expect(event.Entity.(*model.Application).Alias).Should(Equal("Watch App"))
+
+		Expect(kubeStore.Delete(ctx, &model.Application{Name: "watch-app"})).Should(Succeed())
+		Eventually(events, time.Minute).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(datastore.WatchEventDeleted))
+		Expect(event.Entity.PrimaryKey()).Should(Equal("watch-app"))
+
+		By("the channel is closed after the context is done")
+		cancel()
+		Eventually(events, time.Minute).Should(BeClosed())
+		Expect(kubeStore.Delete(context.TODO(), &model.Application{Name: "watch-existing"})).Should(Succeed())
+		Expect(kubeStore.Delete(context.TODO(), &model.Application{Name: "watch-other"})).Should(Succeed())
+	})
+
+	It("Test expire function", func() {
+		ctx := context.TODO()
+		expired := &model.Session{ID: "expired-session", Username: "expire-user"}
+		expired.SetExpireAt(time.Now().Add(-time.Minute))
+		Expect(kubeStore.Add(ctx, expired)).Should(Succeed())
+		living := &model.Session{ID: "living-session", Username: "expire-user"}
+		living.SetExpireAt(time.Now().Add(time.Hour))
+		Expect(kubeStore.Add(ctx, living)).Should(Succeed())
+		Expect(kubeStore.Add(ctx, &model.Session{ID: "forever-session", Username: "expire-user"})).Should(Succeed())
+
+		deleted, err := kubeStore.(*kubeapi).deleteExpired(ctx)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).Should(Equal(1))
+		list, err := kubeStore.List(ctx, &model.Session{Username: "expire-user"}, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(2))
+
+		By("the extended expiry is honored")
+		living.SetExpireAt(time.Now().Add(-time.Minute))
+		Expect(kubeStore.Put(ctx, living)).Should(Succeed())
+		deleted, err = kubeStore.(*kubeapi).deleteExpired(ctx)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).Should(Equal(1))
+		Expect(kubeStore.Delete(ctx, &model.Session{ID: "forever-session"})).Should(Succeed())
+	})
+
+	It("Test the entities are sharded across the namespaces", func() {
+		ctx := context.TODO()
+		shardStore, err := New(ctx, datastore.Config{Database: "shard-test", Shards: 3}, k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		var names []string
+		for i := 0; i < 12; i++ {
+			name := fmt.Sprintf("shard-app-%d", i)
+			names = append(names, name)
+			Expect(shardStore.Add(ctx, &model.Application{Name: name, Project: "shard-project"})).Should(Succeed())
+		}
+
+		By("the entities are routed to all shards")
+		namespaces := map[string]int{}
+		for _, name := range names {
+			app := &model.Application{Name: name}
+			Expect(shardStore.Get(ctx, app)).Should(Succeed())
+			namespaces[shardStore.(*kubeapi).namespaceOf(context.TODO(), app)]++
+		}
+		Expect(len(namespaces)).Should(Equal(3))
+		list, err := shardStore.List(ctx, &model.Application{Project: "shard-project"}, &datastore.ListOptions{
+			SortBy:   []datastore.SortOption{{Key: "name", Order: datastore.SortOrderAscending}},
+			Page:     1,
+			PageSize: 5,
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(5))
+		Expect(list[0].PrimaryKey()).Should(Equal("shard-app-0"))
+		count, err := shardStore.Count(ctx, &model.Application{Project: "shard-project"}, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).Should(Equal(int64(12)))
+
+		By("the entities are moved back to one namespace")
+		shardStore, err = New(ctx, datastore.Config{Database: "shard-test", Shards: 1}, k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		var configMaps corev1.ConfigMapList
+		Expect(k8sClient.List(ctx, &configMaps, client.InNamespace("shard-test"), client.MatchingLabels{"table": (&model.Application{}).TableName()})).Should(Succeed())
+		Expect(len(configMaps.Items)).Should(Equal(12))
+		for _, name := range names {
+			Expect(shardStore.Get(ctx, &model.Application{Name: name})).Should(Succeed())
+			Expect(shardStore.Delete(ctx, &model.Application{Name: name})).Should(Succeed())
+		}
+	})
 })
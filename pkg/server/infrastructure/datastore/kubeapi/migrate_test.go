@@ -36,12 +36,12 @@ var _ = Describe("Test Migrate", func() {
 		clients.SetKubeClient(k8sClient)
 
 		nsName := "test-migrate"
-		ds := &kubeapi{kubeClient: k8sClient, namespace: nsName}
+		ds := &kubeapi{kubeClient: k8sClient, namespaces: []string{nsName}}
 		ns := &v1.Namespace{}
 		ns.Name = nsName
 		Expect(k8sClient.Create(context.Background(), ns)).Should(BeNil())
 		entity := &model.Application{Name: "my-app"}
-		cm := ds.generateConfigMap(entity)
+		cm := ds.generateConfigMap(context.Background(), entity)
 		name := fmt.Sprintf("veladatabase-%s-%s", entity.TableName(), entity.PrimaryKey())
 		cm.Name = strings.ReplaceAll(name, "_", "-")
 		cm.Namespace = nsName
@@ -0,0 +1,192 @@
+/*
+ Copyright 2023 The KubeVela Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ 	http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubeapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// searchIndexTTL the index of a table is rebuilt after it, so the writes of the other replicas become searchable
+const searchIndexTTL = 30 * time.Second
+
+// searchIndex the in-memory inverted index of a table, it is dropped by the writes of the table
+type searchIndex struct {
+	built time.Time
+	// words the primary keys of the entities by the words of their search fields
+	words map[string]map[string]struct{}
+	// data the stored entities by the primary keys
+	data map[string][]byte
+}
+
+// match scores the entities that contain all keywords, the whole words score higher than the prefixes
+func (s *searchIndex) match(keywords []string) map[string]int {
+	var scores map[string]int
+	for _, keyword := range keywords {
+		matched := map[string]int{}
+		for word, keys := range s.words {
+			if !strings.HasPrefix(word, keyword) {
+				continue
+			}
+			score := 1
+			if word == keyword {
+				score = 2
+			}
+			for key := range keys {
+				if matched[key] < score {
+					matched[key] = score
+				}
+			}
+		}
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for key := range scores {
+			if score, ok := matched[key]; ok {
+				scores[key] += score
+			} else {
+				delete(scores, key)
+			}
+		}
+	}
+	return scores
+}
+
+func (m *kubeapi) invalidateSearchIndex(ctx context.Context, entity datastore.Entity) {
+	if m.searchIndexes == nil {
+		return
+	}
+	if _, ok := entity.(datastore.SearchableEntity); ok {
+		m.searchIndexes.Delete(datastore.TenantKey(ctx, entity.TableName()))
+	}
+}
+
+// getSearchIndex returns the cached index of the table, it is rebuilt from all entities of the table if it is dropped or stale
+func (m *kubeapi) getSearchIndex(ctx context.Context, entity datastore.SearchableEntity) (*searchIndex, error) {
+	if cached, ok := m.searchIndexes.Load(datastore.TenantKey(ctx, entity.TableName())); ok && time.Since(cached.(*searchIndex).built) < searchIndexTTL {
+		return cached.(*searchIndex), nil
+	}
+	selector, err := labels.Parse(fmt.Sprintf("table=%s", entity.TableName()))
+	if err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	rq, _ := labels.NewRequirement(MigrateKey, selection.DoesNotExist, []string{"ok"})
+	selector = selector.Add(*rq)
+	items, err := m.listConfigMaps(ctx, selector)
+	if err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	index := &searchIndex{built: time.Now(), words: map[string]map[string]struct{}{}, data: map[string][]byte{}}
+	for _, item := range items {
+		ent, err := datastore.NewEntity(entity)
+		if err != nil {
+			return nil, datastore.NewDBError(err)
+		}
+		if err := json.Unmarshal(item.BinaryData["data"], ent); err != nil {
+			return nil, datastore.NewDBError(err)
+		}
+		key := ent.PrimaryKey()
+		index.data[key] = item.BinaryData["data"]
+		for _, word := range datastore.SearchKeywords(datastore.SearchText(ent.(datastore.SearchableEntity))) {
+			if index.words[word] == nil {
+				index.words[word] = map[string]struct{}{}
+			}
+			index.words[word][key] = struct{}{}
+		}
+	}
+	m.searchIndexes.Store(datastore.TenantKey(ctx, entity.TableName()), index)
+	return index, nil
+}
+
+// Search matches the keywords by the in-memory index of the table, the index and the filter options of the query are
+// applied by listing the table
+func (m *kubeapi) Search(ctx context.Context, entity datastore.Entity, text string, op *datastore.ListOptions) ([]datastore.Entity, error) {
+	if entity.TableName() == "" {
+		return nil, datastore.ErrTableNameEmpty
+	}
+	searchable, ok := entity.(datastore.SearchableEntity)
+	if !ok {
+		return nil, datastore.ErrEntityInvalid
+	}
+	keywords := datastore.SearchKeywords(text)
+	if len(keywords) == 0 {
+		return nil, nil
+	}
+	index, err := m.getSearchIndex(ctx, searchable)
+	if err != nil {
+		return nil, err
+	}
+	scores := index.match(keywords)
+	if len(scores) == 0 {
+		return nil, nil
+	}
+	var matched []datastore.Entity
+	if len(entity.Index()) > 0 || (op != nil && !op.FilterOptions.IsEmpty()) {
+		listOptions := &datastore.ListOptions{}
+		if op != nil {
+			listOptions.FilterOptions = op.FilterOptions
+		}
+		listed, err := m.List(ctx, entity, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range listed {
+			if _, ok := scores[item.PrimaryKey()]; ok {
+				matched = append(matched, item)
+			}
+		}
+	} else {
+		for key := range scores {
+			item, err := datastore.NewEntity(entity)
+			if err != nil {
+				return nil, datastore.NewDBError(err)
+			}
+			if err := json.Unmarshal(index.data[key], item); err != nil {
+				return nil, datastore.NewDBError(err)
+			}
+			matched = append(matched, item)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		si, sj := scores[matched[i].PrimaryKey()], scores[matched[j].PrimaryKey()]
+		if si != sj {
+			return si > sj
+		}
+		return matched[i].PrimaryKey() < matched[j].PrimaryKey()
+	})
+	if op != nil && op.PageSize > 0 && op.Page > 0 {
+		skip := op.PageSize * (op.Page - 1)
+		if skip >= len(matched) {
+			return nil, nil
+		}
+		matched = matched[skip:]
+		if len(matched) > op.PageSize {
+			matched = matched[:op.PageSize]
+		}
+	}
+	return matched, nil
+}
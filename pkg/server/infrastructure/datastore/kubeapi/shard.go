@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeapi
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+const (
+	// shardStateName the ConfigMap in the first shard records the number of the shards the entities are spread across
+	shardStateName = "velaux-datastore-shards"
+	shardStateKey  = "shards"
+)
+
+// shardNamespaces the namespaces of the shards, the first one is the database namespace so the entities stored before
+// the sharding is enabled are kept in it
+func shardNamespaces(database string, shards int) []string {
+	namespaces := []string{database}
+	for i := 1; i < shards; i++ {
+		namespaces = append(namespaces, fmt.Sprintf("%s-shard-%d", database, i))
+	}
+	return namespaces
+}
+
+// shardOf returns the namespace of the ConfigMap by the hash of its name
+func shardOf(name string, namespaces []string) string {
+	if len(namespaces) == 1 {
+		return namespaces[0]
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return namespaces[h.Sum32()%uint32(len(namespaces))]
+}
+
+// tenantDatabase the namespace of the first shard of the tenant
+func tenantDatabase(database, tenant string) string {
+	return database + "-" + tenant
+}
+
+// shardsOf returns the namespaces of the shards of the tenant of the context
+func (m *kubeapi) shardsOf(ctx context.Context) []string {
+	tenant := datastore.TenantFromContext(ctx)
+	if tenant == "" {
+		return m.namespaces
+	}
+	return shardNamespaces(tenantDatabase(m.database, tenant), len(m.namespaces))
+}
+
+// namespaceOf returns the namespace of the ConfigMap of the entity
+func (m *kubeapi) namespaceOf(ctx context.Context, entity datastore.Entity) string {
+	return shardOf(generateName(entity), m.shardsOf(ctx))
+}
+
+// listConfigMaps lists the ConfigMaps selected in all shards of the tenant of the context
+func (m *kubeapi) listConfigMaps(ctx context.Context, selector labels.Selector) ([]corev1.ConfigMap, error) {
+	return listShards(ctx, m.kubeClient, m.shardsOf(ctx), selector)
+}
+
+func listShards(ctx context.Context, c client.Client, namespaces []string, selector labels.Selector) ([]corev1.ConfigMap, error) {
+	var items []corev1.ConfigMap
+	for _, namespace := range namespaces {
+		var configMaps corev1.ConfigMapList
+		if err := c.List(ctx, &configMaps, &client.ListOptions{Namespace: namespace, LabelSelector: selector}); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		items = append(items, configMaps.Items...)
+	}
+	return items, nil
+}
+
+// ensureNamespace creates the namespace if it does not exist
+func ensureNamespace(ctx context.Context, c client.Client, name string) error {
+	var namespace corev1.Namespace
+	err := c.Get(ctx, types.NamespacedName{Name: name}, &namespace)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := c.Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{"description": "For KubeVela API Server metadata storage."},
+		}}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("create namespace failure %w", err)
+	}
+	return nil
+}
+
+// rebalance moves the ConfigMaps to their shards if the number of the shards is changed since the last start.
+// The number is recorded after all ConfigMaps are moved, so the interrupted rebalance is continued at the next start.
+func rebalance(ctx context.Context, c client.Client, database string, namespaces []string) error {
+	previous := 1
+	var state corev1.ConfigMap
+	err := c.Get(ctx, types.NamespacedName{Namespace: database, Name: shardStateName}, &state)
+	stateExist := err == nil
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if shards, err := strconv.Atoi(state.Data[shardStateKey]); err == nil && shards > 0 {
+		previous = shards
+	}
+	if previous == len(namespaces) {
+		return nil
+	}
+	klog.Infof("moving the datastore from %d shards to %d shards", previous, len(namespaces))
+	// the shards are the prefixes of each other, the longer one contains the namespaces of both
+	scanned := namespaces
+	if previous > len(namespaces) {
+		scanned = shardNamespaces(database, previous)
+	}
+	rq, _ := labels.NewRequirement("table", selection.Exists, nil)
+	items, err := listShards(ctx, c, scanned, labels.NewSelector().Add(*rq))
+	if err != nil {
+		return err
+	}
+	var moved int
+	for i := range items {
+		item := &items[i]
+		target := shardOf(item.Name, namespaces)
+		if target == item.Namespace {
+			continue
+		}
+		// the copy exists if the last rebalance is interrupted before the ConfigMap is deleted
+		if err := c.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        item.Name,
+				Namespace:   target,
+				Labels:      item.Labels,
+				Annotations: item.Annotations,
+			},
+			Data:       item.Data,
+			BinaryData: item.BinaryData,
+		}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("move the configmap %s to %s failure %w", item.Name, target, err)
+		}
+		if err := c.Delete(ctx, item); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete the moved configmap %s failure %w", item.Name, err)
+		}
+		moved++
+	}
+	klog.Infof("%d configmaps are moved to the new shards", moved)
+	if previous > len(namespaces) {
+		klog.Infof("the namespaces of the removed shards %v are empty, they could be deleted", scanned[len(namespaces):])
+	}
+	state.Name = shardStateName
+	state.Namespace = database
+	state.Data = map[string]string{shardStateKey: strconv.Itoa(len(namespaces))}
+	if stateExist {
+		return c.Update(ctx, &state)
+	}
+	return c.Create(ctx, &state)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/clients"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// watchSyncTimeout the timeout of listing the ConfigMaps when the watch starts
+const watchSyncTimeout = time.Minute
+
+// getWatchClient returns the kube client if it could watch, otherwise a watch client is created by the kube config
+func (m *kubeapi) getWatchClient() (client.WithWatch, error) {
+	if watchClient, ok := m.kubeClient.(client.WithWatch); ok {
+		return watchClient, nil
+	}
+	m.watchClientOnce.Do(func() {
+		conf, err := clients.GetKubeConfig()
+		if err != nil {
+			m.watchClientErr = err
+			return
+		}
+		m.watchClient, m.watchClientErr = client.NewWithWatch(conf, client.Options{Scheme: m.kubeClient.Scheme()})
+	})
+	return m.watchClient, m.watchClientErr
+}
+
+// Watch runs an informer of the ConfigMaps of the table in every shard of the tenant. The ConfigMaps listed when the
+// informers start are not sent, and the informers list them again if the watch is expired, so the changes are not lost.
+func (m *kubeapi) Watch(ctx context.Context, query datastore.Entity) (<-chan datastore.WatchEvent, error) {
+	if query.TableName() == "" {
+		return nil, datastore.ErrTableNameEmpty
+	}
+	selector, err := indexSelector(query)
+	if err != nil {
+		return nil, err
+	}
+	watchClient, err := m.getWatchClient()
+	if err != nil {
+		return nil, datastore.NewDBError(fmt.Errorf("create the watch client failure %w", err))
+	}
+	watchCtx, stop := context.WithCancel(ctx)
+	ch := make(chan datastore.WatchEvent, datastore.WatchBufferSize)
+	var wg sync.WaitGroup
+	var synced []cache.InformerSynced
+	for _, namespace := range m.shardsOf(ctx) {
+		controller := newWatchController(watchCtx, watchClient, namespace, selector, query, ch)
+		synced = append(synced, controller.HasSynced)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			controller.Run(watchCtx.Done())
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(ch)
+		close(done)
+	}()
+	syncCtx, cancel := context.WithTimeout(watchCtx, watchSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), synced...) {
+		stop()
+		<-done
+		return nil, datastore.NewDBError(fmt.Errorf("list the configmaps of %s to watch failure", query.TableName()))
+	}
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ch, nil
+}
+
+// newWatchController creates the informer of the ConfigMaps selected in the namespace, the changes are sent to the channel
+func newWatchController(watchCtx context.Context, watchClient client.WithWatch, namespace string, selector labels.Selector, query datastore.Entity, ch chan<- datastore.WatchEvent) cache.Controller {
+	// initial the versions of the ConfigMaps of the first list, they exist before the watch
+	var mu sync.Mutex
+	var initial map[string]string
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			var configMaps corev1.ConfigMapList
+			if err := watchClient.List(watchCtx, &configMaps, &client.ListOptions{Namespace: namespace, LabelSelector: selector, Raw: &options}); err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if initial == nil {
+				initial = make(map[string]string, len(configMaps.Items))
+				for _, item := range configMaps.Items {
+					initial[item.Name] = item.ResourceVersion
+				}
+			}
+			return &configMaps, nil
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return watchClient.Watch(watchCtx, &corev1.ConfigMapList{}, &client.ListOptions{Namespace: namespace, LabelSelector: selector, Raw: &options})
+		},
+	}
+	send := func(eventType datastore.WatchEventType, obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		configMap, ok := obj.(*corev1.ConfigMap)
+		if !ok {
+			return
+		}
+		entity, err := datastore.NewEntity(query)
+		if err != nil {
+			klog.Errorf("create the entity of %s failure %s", query.TableName(), err.Error())
+			return
+		}
+		if err := json.Unmarshal(configMap.BinaryData["data"], entity); err != nil {
+			klog.Errorf("decode the entity of the configmap %s failure %s", configMap.Name, err.Error())
+			return
+		}
+		datastore.SendWatchEvent(watchCtx, ch, datastore.WatchEvent{Type: eventType, Entity: entity})
+	}
+	_, controller := cache.NewInformer(lw, &corev1.ConfigMap{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if configMap, ok := obj.(*corev1.ConfigMap); ok {
+				mu.Lock()
+				version, existed := initial[configMap.Name]
+				delete(initial, configMap.Name)
+				mu.Unlock()
+				if existed && version == configMap.ResourceVersion {
+					return
+				}
+			}
+			send(datastore.WatchEventAdded, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// the ConfigMaps listed again after the watch is expired are updated by themselves
+			oldConfigMap, ok1 := oldObj.(*corev1.ConfigMap)
+			newConfigMap, ok2 := newObj.(*corev1.ConfigMap)
+			if ok1 && ok2 && oldConfigMap.ResourceVersion == newConfigMap.ResourceVersion {
+				return
+			}
+			send(datastore.WatchEventModified, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			send(datastore.WatchEventDeleted, obj)
+		},
+	})
+	return controller
+}
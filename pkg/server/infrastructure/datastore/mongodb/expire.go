@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+const (
+	// expireAtKey the key of the expiry, the fields of the base model are stored in the embedded document
+	expireAtKey = "basemodel.expireat"
+	// expireIndexName the name of the TTL index, the documents without the expiry are ignored by it
+	expireIndexName = "expire_at"
+)
+
+// expireIndexes the collections whose TTL indexes are ensured
+var expireIndexes sync.Map
+
+// ensureExpireIndex creates the TTL index of the collection before the first expiring entity is written,
+// MongoDB deletes the expired documents by it about every minute
+func (m *mongodb) ensureExpireIndex(ctx context.Context, entity datastore.Entity) error {
+	if datastore.ExpireAt(entity) == nil {
+		return nil
+	}
+	key := m.databaseName(ctx) + "/" + entity.TableName()
+	if _, ok := expireIndexes.Load(key); ok {
+		return nil
+	}
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
+	if _, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: expireAtKey, Value: 1}},
+		Options: options.Index().SetName(expireIndexName).SetExpireAfterSeconds(0),
+	}); err != nil {
+		return datastore.NewDBError(fmt.Errorf("create the TTL index of %s failure %w", entity.TableName(), err))
+	}
+	klog.Infof("the TTL index of the collection %s is ensured", entity.TableName())
+	expireIndexes.Store(key, struct{}{})
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// errNamespaceNotFound the error code of listing the indexes of the collection not created yet
+const errNamespaceNotFound = 26
+
+// indexKey the key of the document field, the keys are lowercase and the times of the base model are in the embedded document
+func indexKey(key string) string {
+	key = strings.ToLower(key)
+	if key == "createtime" || key == "updatetime" {
+		return "basemodel." + key
+	}
+	return key
+}
+
+// reconcileIndexes creates and drops the indexes of the collection by the indexes of the entity
+func (m *mongodb) reconcileIndexes(ctx context.Context, entity datastore.Entity) error {
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Code == errNamespaceNotFound) {
+		return datastore.NewDBError(err)
+	}
+	var existing []string
+	for _, spec := range specs {
+		existing = append(existing, spec.Name)
+	}
+	datastore.ReconcileIndexes(ctx, entity, existing, func(ctx context.Context, index datastore.Index) error {
+		keys := bson.D{}
+		partial := bson.D{}
+		for _, field := range index.Fields {
+			keys = append(keys, bson.E{Key: indexKey(field.Key), Value: int(field.Order)})
+			if !strings.HasPrefix(indexKey(field.Key), "basemodel.") {
+				partial = append(partial, bson.E{Key: indexKey(field.Key), Value: bson.D{{Key: "$gt", Value: ""}}})
+			}
+		}
+		opts := options.Index().SetName(index.Name)
+		if index.Unique {
+			// the documents with an empty or missing field are not constrained, the same as the other drivers
+			opts.SetUnique(true)
+			if len(partial) > 0 {
+				opts.SetPartialFilterExpression(partial)
+			}
+		}
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys, Options: opts})
+		return err
+	}, func(ctx context.Context, name string) error {
+		_, err := collection.Indexes().DropOne(ctx, name)
+		return err
+	})
+	return nil
+}
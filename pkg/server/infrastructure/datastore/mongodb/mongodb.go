@@ -29,17 +29,25 @@ import (
 	"go.mongodb.org/mongo-driver/x/bsonx"
 	"k8s.io/klog/v2"
 
+	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 )
 
 type mongodb struct {
 	client   *mongo.Client
 	database string
+	// transactions is true if the server supports the multi-document transactions
+	transactions bool
+	// session is the session of the transaction in a transaction, otherwise it is nil
+	session mongo.Session
 }
 
 // PrimaryKey primary key
 const PrimaryKey = "_name"
 
+// resourceVersionKey the key of the resource version, the fields of the base model are stored in the embedded document
+const resourceVersionKey = "basemodel.resourceversion"
+
 // New new mongodb datastore instance
 func New(ctx context.Context, cfg datastore.Config) (datastore.DataStore, error) {
 	if !strings.HasPrefix(cfg.URL, "mongodb://") {
@@ -51,13 +59,60 @@ func New(ctx context.Context, cfg datastore.Config) (datastore.DataStore, error)
 		return nil, err
 	}
 
+	transactions, err := supportsTransactions(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if !transactions {
+		klog.Warning("the standalone MongoDB has no multi-document transactions, the writes of a transaction are undone by the compensating writes")
+	}
 	m := &mongodb{
-		client:   client,
-		database: cfg.Database,
+		client:       client,
+		database:     cfg.Database,
+		transactions: transactions,
+	}
+	for _, registered := range model.GetRegisterModels() {
+		entity, ok := registered.(datastore.IndexedEntity)
+		if !ok {
+			continue
+		}
+		for _, partition := range datastore.Partitions(ctx, cfg.Tenants) {
+			if err := m.reconcileIndexes(partition, entity); err != nil {
+				return nil, fmt.Errorf("reconcile the indexes of %s failure %w", entity.TableName(), err)
+			}
+		}
 	}
 	return m, nil
 }
 
+// supportsTransactions the replica sets and the sharded clusters support the multi-document transactions, the standalone server does not
+func supportsTransactions(ctx context.Context, client *mongo.Client) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello); err != nil {
+		return false, fmt.Errorf("check the topology of mongodb failure %w", err)
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
+
+// withSession joins the operations to the transaction of the session, the index creation is never joined to it
+func (m *mongodb) withSession(ctx context.Context) context.Context {
+	if m.session == nil {
+		return ctx
+	}
+	return mongo.NewSessionContext(ctx, m.session)
+}
+
+// databaseName the database of the tenant of the context, the default partition is the configured database
+func (m *mongodb) databaseName(ctx context.Context) string {
+	if tenant := datastore.TenantFromContext(ctx); tenant != "" {
+		return m.database + "_" + tenant
+	}
+	return m.database
+}
+
 // Add add data model
 func (m *mongodb) Add(ctx context.Context, entity datastore.Entity) error {
 	if entity.PrimaryKey() == "" {
@@ -67,41 +122,66 @@ func (m *mongodb) Add(ctx context.Context, entity datastore.Entity) error {
 		return datastore.ErrTableNameEmpty
 	}
 	entity.SetCreateTime(time.Now())
+	entity.SetResourceVersion(1)
 	if err := m.Get(ctx, entity); err == nil {
 		return datastore.ErrRecordExist
 	}
-	model, err := convertToMap(entity)
+	if err := m.ensureExpireIndex(ctx, entity); err != nil {
+		return err
+	}
+	document, err := convertToMap(entity)
 	if err != nil {
 		return datastore.ErrEntityInvalid
 	}
-	model[PrimaryKey] = entity.PrimaryKey()
-	collection := m.client.Database(m.database).Collection(entity.TableName())
-	_, err = collection.InsertOne(ctx, model)
+	document[PrimaryKey] = entity.PrimaryKey()
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
+	_, err = collection.InsertOne(m.withSession(ctx), document)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return datastore.ErrRecordExist
+		}
 		return datastore.NewDBError(err)
 	}
 	return nil
 }
 
-// BatchAdd batch add entity, this operation has some atomicity.
+// BatchAdd batch add entity, the entities are added in a transaction
 func (m *mongodb) BatchAdd(ctx context.Context, entities []datastore.Entity) error {
-	notRollback := make(map[string]int)
-	for i, saveEntity := range entities {
-		if err := m.Add(ctx, saveEntity); err != nil {
-			if errors.Is(err, datastore.ErrRecordExist) {
-				notRollback[saveEntity.PrimaryKey()] = 1
-			}
-			for _, deleteEntity := range entities[:i] {
-				if _, exit := notRollback[deleteEntity.PrimaryKey()]; !exit {
-					if err := m.Delete(ctx, deleteEntity); err != nil {
-						if !errors.Is(err, datastore.ErrRecordNotExist) {
-							klog.Errorf("rollback delete entity failure %w", err)
-						}
-					}
-				}
+	return m.Transaction(ctx, func(tx datastore.DataStore) error {
+		for _, entity := range entities {
+			if err := tx.Add(ctx, entity); err != nil {
+				return datastore.NewDBError(fmt.Errorf("save entities occur error, %w", err))
 			}
-			return datastore.NewDBError(fmt.Errorf("save entities occur error, %w", err))
 		}
+		return nil
+	})
+}
+
+// Transaction runs fn in a multi-document transaction of a session, fn must not use the transaction concurrently.
+// The standalone MongoDB has no multi-document transactions, the writes are undone by the compensating writes there.
+func (m *mongodb) Transaction(ctx context.Context, fn func(tx datastore.DataStore) error) error {
+	if m.session != nil {
+		return fn(m)
+	}
+	if !m.transactions {
+		return datastore.CompensatingTransaction(ctx, m, fn)
+	}
+	session, err := m.client.StartSession()
+	if err != nil {
+		return datastore.NewDBError(err)
+	}
+	defer session.EndSession(ctx)
+	if err := session.StartTransaction(); err != nil {
+		return datastore.NewDBError(err)
+	}
+	if err := fn(&mongodb{client: m.client, database: m.database, transactions: m.transactions, session: session}); err != nil {
+		if err := session.AbortTransaction(ctx); err != nil {
+			klog.Errorf("rollback the transaction failure %s", err.Error())
+		}
+		return err
+	}
+	if err := session.CommitTransaction(ctx); err != nil {
+		return datastore.NewDBError(err)
 	}
 	return nil
 }
@@ -114,8 +194,8 @@ func (m *mongodb) Get(ctx context.Context, entity datastore.Entity) error {
 	if entity.TableName() == "" {
 		return datastore.ErrTableNameEmpty
 	}
-	collection := m.client.Database(m.database).Collection(entity.TableName())
-	if err := collection.FindOne(ctx, makeNameFilter(entity.PrimaryKey())).Decode(entity); err != nil {
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
+	if err := collection.FindOne(m.withSession(ctx), makeNameFilter(entity.PrimaryKey())).Decode(entity); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return datastore.ErrRecordNotExist
 		}
@@ -132,18 +212,62 @@ func (m *mongodb) Put(ctx context.Context, entity datastore.Entity) error {
 	if entity.TableName() == "" {
 		return datastore.ErrTableNameEmpty
 	}
+	if err := m.ensureExpireIndex(ctx, entity); err != nil {
+		return err
+	}
 	entity.SetUpdateTime(time.Now())
-	collection := m.client.Database(m.database).Collection(entity.TableName())
-	_, err := collection.UpdateOne(ctx, makeNameFilter(entity.PrimaryKey()), makeEntityUpdate(entity))
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
+	version := entity.GetResourceVersion()
+	if version == 0 {
+		// the entity without the version overwrites the stored one, it is compared with the version read just now
+		stored, err := datastore.NewEntity(entity)
+		if err != nil {
+			return err
+		}
+		if err := collection.FindOne(m.withSession(ctx), makeNameFilter(entity.PrimaryKey())).Decode(stored); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return datastore.ErrRecordNotExist
+			}
+			return datastore.NewDBError(err)
+		}
+		entity.SetResourceVersion(stored.GetResourceVersion())
+	}
+	filter := append(makeNameFilter(entity.PrimaryKey()), makeResourceVersionFilter(entity.GetResourceVersion()))
+	entity.SetResourceVersion(entity.GetResourceVersion() + 1)
+	res, err := collection.UpdateOne(m.withSession(ctx), filter, makeEntityUpdate(entity))
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return datastore.ErrRecordNotExist
+		entity.SetResourceVersion(version)
+		if mongo.IsDuplicateKeyError(err) {
+			return datastore.ErrRecordExist
 		}
 		return datastore.NewDBError(err)
 	}
+	if res.MatchedCount == 0 {
+		entity.SetResourceVersion(version)
+		exist, err := m.IsExist(ctx, entity)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return datastore.ErrRecordNotExist
+		}
+		return datastore.ErrRecordConflict
+	}
 	return nil
 }
 
+// BatchPut update entities, the entities are updated in a transaction
+func (m *mongodb) BatchPut(ctx context.Context, entities []datastore.Entity) error {
+	return m.Transaction(ctx, func(tx datastore.DataStore) error {
+		for _, entity := range entities {
+			if err := tx.Put(ctx, entity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // IsExist determine whether data exists.
 func (m *mongodb) IsExist(ctx context.Context, entity datastore.Entity) (bool, error) {
 	if entity.PrimaryKey() == "" {
@@ -152,8 +276,8 @@ func (m *mongodb) IsExist(ctx context.Context, entity datastore.Entity) (bool, e
 	if entity.TableName() == "" {
 		return false, datastore.ErrTableNameEmpty
 	}
-	collection := m.client.Database(m.database).Collection(entity.TableName())
-	err := collection.FindOne(ctx, makeNameFilter(entity.PrimaryKey())).Err()
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
+	err := collection.FindOne(m.withSession(ctx), makeNameFilter(entity.PrimaryKey())).Err()
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return false, nil
 	} else if err != nil {
@@ -175,7 +299,7 @@ func (m *mongodb) Delete(ctx context.Context, entity datastore.Entity) error {
 	if err := m.Get(ctx, entity); err != nil {
 		return err
 	}
-	collection := m.client.Database(m.database).Collection(entity.TableName())
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
 	// delete at most one document in which the "name" field is "Bob" or "bob"
 	// specify the SetCollation option to provide a collation that will ignore case for string comparisons
 	opts := options.Delete().SetCollation(&options.Collation{
@@ -183,7 +307,7 @@ func (m *mongodb) Delete(ctx context.Context, entity datastore.Entity) error {
 		Strength:  1,
 		CaseLevel: false,
 	})
-	_, err := collection.DeleteOne(ctx, makeNameFilter(entity.PrimaryKey()), opts)
+	_, err := collection.DeleteOne(m.withSession(ctx), makeNameFilter(entity.PrimaryKey()), opts)
 	if err != nil {
 		klog.Errorf("delete document failure %w", err)
 		return datastore.NewDBError(err)
@@ -191,6 +315,29 @@ func (m *mongodb) Delete(ctx context.Context, entity datastore.Entity) error {
 	return nil
 }
 
+// DeleteByFilter delete the documents matched by the query and the filter options
+func (m *mongodb) DeleteByFilter(ctx context.Context, entity datastore.Entity, filterOptions *datastore.FilterOptions) (int64, error) {
+	if entity.TableName() == "" {
+		return 0, datastore.ErrTableNameEmpty
+	}
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
+	filter := bson.D{}
+	for k, v := range entity.Index() {
+		filter = append(filter, bson.E{
+			Key:   strings.ToLower(k),
+			Value: v,
+		})
+	}
+	if filterOptions != nil {
+		filter = _applyFilterOptions(filter, *filterOptions)
+	}
+	res, err := collection.DeleteMany(m.withSession(ctx), filter)
+	if err != nil {
+		return 0, datastore.NewDBError(err)
+	}
+	return res.DeletedCount, nil
+}
+
 func _applyFilterOptions(filter bson.D, filterOptions datastore.FilterOptions) bson.D {
 	for _, queryOp := range filterOptions.Queries {
 		filter = append(filter, bson.E{Key: strings.ToLower(queryOp.Key), Value: bsonx.Regex(".*"+queryOp.Query+".*", "s")})
@@ -198,8 +345,22 @@ func _applyFilterOptions(filter bson.D, filterOptions datastore.FilterOptions) b
 	for _, queryOp := range filterOptions.In {
 		filter = append(filter, bson.E{Key: strings.ToLower(queryOp.Key), Value: bson.D{bson.E{Key: "$in", Value: queryOp.Values}}})
 	}
+	for _, queryOp := range filterOptions.NotIn {
+		filter = append(filter, bson.E{Key: strings.ToLower(queryOp.Key), Value: bson.D{bson.E{Key: "$nin", Value: queryOp.Values}}})
+	}
+	// the null value matches the documents without the field as well
+	for _, queryOp := range filterOptions.IsExist {
+		filter = append(filter, bson.E{Key: strings.ToLower(queryOp.Key), Value: bson.D{bson.E{Key: "$nin", Value: bson.A{"", nil}}}})
+	}
 	for _, queryOp := range filterOptions.IsNotExist {
-		filter = append(filter, bson.E{Key: strings.ToLower(queryOp.Key), Value: bson.D{bson.E{Key: "$eq", Value: ""}}})
+		filter = append(filter, bson.E{Key: strings.ToLower(queryOp.Key), Value: bson.D{bson.E{Key: "$in", Value: bson.A{"", nil}}}})
+	}
+	if len(filterOptions.Or) > 0 {
+		var groups bson.A
+		for _, group := range filterOptions.Or {
+			groups = append(groups, _applyFilterOptions(bson.D{}, group))
+		}
+		filter = append(filter, bson.E{Key: "$or", Value: groups})
 	}
 	return filter
 }
@@ -209,7 +370,7 @@ func (m *mongodb) List(ctx context.Context, entity datastore.Entity, op *datasto
 	if entity.TableName() == "" {
 		return nil, datastore.ErrTableNameEmpty
 	}
-	collection := m.client.Database(m.database).Collection(entity.TableName())
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
 	// bson.D{{}} specifies 'all documents'
 	filter := bson.D{}
 	for k, v := range entity.Index() {
@@ -237,6 +398,7 @@ func (m *mongodb) List(ctx context.Context, entity datastore.Entity, op *datasto
 		}
 		findOptions.SetSort(_d)
 	}
+	ctx = m.withSession(ctx)
 	cur, err := collection.Find(ctx, filter, &findOptions)
 	if err != nil {
 		return nil, datastore.NewDBError(err)
@@ -268,7 +430,7 @@ func (m *mongodb) Count(ctx context.Context, entity datastore.Entity, filterOpti
 	if entity.TableName() == "" {
 		return 0, datastore.ErrTableNameEmpty
 	}
-	collection := m.client.Database(m.database).Collection(entity.TableName())
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
 	filter := bson.D{}
 	for k, v := range entity.Index() {
 		filter = append(filter, bson.E{
@@ -279,7 +441,7 @@ func (m *mongodb) Count(ctx context.Context, entity datastore.Entity, filterOpti
 	if filterOptions != nil {
 		filter = _applyFilterOptions(filter, *filterOptions)
 	}
-	count, err := collection.CountDocuments(ctx, filter)
+	count, err := collection.CountDocuments(m.withSession(ctx), filter)
 	if err != nil {
 		return 0, datastore.NewDBError(err)
 	}
@@ -290,6 +452,14 @@ func makeNameFilter(name string) bson.D {
 	return bson.D{{Key: PrimaryKey, Value: name}}
 }
 
+// makeResourceVersionFilter matches the documents of the version, the documents stored before the versions are added have no version
+func makeResourceVersionFilter(version int64) bson.E {
+	if version == 0 {
+		return bson.E{Key: resourceVersionKey, Value: bson.D{{Key: "$in", Value: bson.A{0, nil}}}}
+	}
+	return bson.E{Key: resourceVersionKey, Value: version}
+}
+
 func makeEntityUpdate(entity interface{}) bson.M {
 	return bson.M{"$set": entity}
 }
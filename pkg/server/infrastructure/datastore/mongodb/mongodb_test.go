@@ -18,6 +18,7 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 	"strings"
 	"time"
@@ -26,11 +27,13 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/datastoretest"
 )
 
 var mongodbDriver datastore.DataStore
@@ -61,6 +64,11 @@ var _ = BeforeSuite(func(done Done) {
 }, 120)
 
 var _ = Describe("Test mongodb datastore driver", func() {
+	datastoretest.DescribeFilterConformance(func() datastore.DataStore { return mongodbDriver })
+	datastoretest.DescribeTenantConformance(func() datastore.DataStore { return mongodbDriver })
+	datastoretest.DescribeIndexConformance(func() datastore.DataStore { return mongodbDriver })
+	datastoretest.DescribeBatchConformance(func() datastore.DataStore { return mongodbDriver })
+	datastoretest.DescribeTransactionConformance(func() datastore.DataStore { return mongodbDriver })
 
 	It("Test add function", func() {
 		err := mongodbDriver.Add(context.TODO(), &model.Application{Name: "kubevela-app", Description: "default"})
@@ -104,6 +112,17 @@ var _ = Describe("Test mongodb datastore driver", func() {
 	It("Test put function", func() {
 		err := mongodbDriver.Put(context.TODO(), &model.Application{Name: "kubevela-app", Description: "this is demo"})
 		Expect(err).ToNot(HaveOccurred())
+
+		By("the stale entity could not be put")
+		stale := &model.Application{Name: "kubevela-app"}
+		Expect(mongodbDriver.Get(context.TODO(), stale)).Should(Succeed())
+		fresh := &model.Application{Name: "kubevela-app"}
+		Expect(mongodbDriver.Get(context.TODO(), fresh)).Should(Succeed())
+		fresh.Description = "updated"
+		Expect(mongodbDriver.Put(context.TODO(), fresh)).Should(Succeed())
+		Expect(fresh.ResourceVersion).Should(Equal(stale.ResourceVersion + 1))
+		stale.Description = "stale"
+		Expect(mongodbDriver.Put(context.TODO(), stale)).Should(Equal(datastore.ErrRecordConflict))
 	})
 	It("Test list function", func() {
 		var app model.Application
@@ -286,4 +305,131 @@ var _ = Describe("Test mongodb datastore driver", func() {
 		err = mongodbDriver.Delete(context.TODO(), &trigger)
 		Expect(err).ShouldNot(HaveOccurred())
 	})
+
+	It("Test search function", func() {
+		ctx := context.TODO()
+		Expect(mongodbDriver.Add(ctx, &model.Application{Name: "search-payment-api", Alias: "Payment API", Project: "search-project", Description: "handles the checkout payments"})).Should(Succeed())
+		Expect(mongodbDriver.Add(ctx, &model.Application{Name: "search-payroll", Project: "search-project", Description: "the monthly payroll"})).Should(Succeed())
+
+		list, err := mongodbDriver.Search(ctx, &model.Application{}, "Payment", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(1))
+		Expect(list[0].(*model.Application).Name).Should(Equal("search-payment-api"))
+
+		By("all keywords are required")
+		list, err = mongodbDriver.Search(ctx, &model.Application{}, "payroll checkout", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(list).Should(BeEmpty())
+
+		By("the entities are filtered by the index of the query")
+		list, err = mongodbDriver.Search(ctx, &model.Application{Project: "other-project"}, "payroll", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(list).Should(BeEmpty())
+
+		By("the updated fields are searchable")
+		Expect(mongodbDriver.Put(ctx, &model.Application{Name: "search-payroll", Project: "search-project", Description: "the monthly salaries"})).Should(Succeed())
+		list, err = mongodbDriver.Search(ctx, &model.Application{}, "salaries", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(1))
+
+		_, err = mongodbDriver.Search(ctx, &model.Workflow{}, "payroll", nil)
+		Expect(err).Should(Equal(datastore.ErrEntityInvalid))
+		Expect(mongodbDriver.Delete(ctx, &model.Application{Name: "search-payment-api"})).Should(Succeed())
+		Expect(mongodbDriver.Delete(ctx, &model.Application{Name: "search-payroll"})).Should(Succeed())
+	})
+
+	It("Test watch function", func() {
+		interval := datastore.DefaultPollInterval
+		datastore.DefaultPollInterval = 100 * time.Millisecond
+		defer func() { datastore.DefaultPollInterval = interval }()
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		Expect(mongodbDriver.Add(ctx, &model.Application{Name: "watch-existing", Project: "watch-project"})).Should(Succeed())
+		events, err := mongodbDriver.Watch(ctx, &model.Application{Project: "watch-project"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("the entities out of the index of the query are not sent")
+		Expect(mongodbDriver.Add(ctx, &model.Application{Name: "watch-other", Project: "other-project"})).Should(Succeed())
+		Expect(mongodbDriver.Add(ctx, &model.Application{Name: "watch-app", Project: "watch-project"})).Should(Succeed())
+		var event datastore.WatchEvent
+		Eventually(events, time.Minute).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(datastore.WatchEventAdded))
+		Expect(event.Entity.PrimaryKey()).Should(Equal("watch-app"))
+
+		Expect(mongodbDriver.Put(ctx, &model.Application{Name: "watch-app", Project: "watch-project", Alias: "Watch App"})).Should(Succeed())
+		Eventually(events, time.Minute).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(datastore.WatchEventModified))
+		Expect(event.Entity.(*model.Application).Alias).Should(Equal("Watch App"))
+
+		Expect(mongodbDriver.Delete(ctx, &model.Application{Name: "watch-app"})).Should(Succeed())
+		Eventually(events, time.Minute).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(datastore.WatchEventDeleted))
+		Expect(event.Entity.PrimaryKey()).Should(Equal("watch-app"))
+
+		By("the channel is closed after the context is done")
+		cancel()
+		Eventually(events, time.Minute).Should(BeClosed())
+		Expect(mongodbDriver.Delete(context.TODO(), &model.Application{Name: "watch-existing"})).Should(Succeed())
+		Expect(mongodbDriver.Delete(context.TODO(), &model.Application{Name: "watch-other"})).Should(Succeed())
+	})
+
+	It("Test transaction function", func() {
+		ctx := context.TODO()
+		Expect(mongodbDriver.Add(ctx, &model.Application{Name: "tx-existing", Alias: "Before"})).Should(Succeed())
+
+		By("the writes are rolled back if the transaction fails")
+		err := mongodbDriver.Transaction(ctx, func(tx datastore.DataStore) error {
+			if err := tx.Add(ctx, &model.Application{Name: "tx-added"}); err != nil {
+				return err
+			}
+			if err := tx.Put(ctx, &model.Application{Name: "tx-existing", Alias: "After"}); err != nil {
+				return err
+			}
+			return errors.New("abort")
+		})
+		Expect(err).Should(MatchError("abort"))
+		exist, err := mongodbDriver.IsExist(ctx, &model.Application{Name: "tx-added"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(exist).Should(BeFalse())
+		existing := &model.Application{Name: "tx-existing"}
+		Expect(mongodbDriver.Get(ctx, existing)).Should(Succeed())
+		Expect(existing.Alias).Should(Equal("Before"))
+
+		By("the writes are committed together")
+		Expect(mongodbDriver.Transaction(ctx, func(tx datastore.DataStore) error {
+			if err := tx.Add(ctx, &model.Application{Name: "tx-added"}); err != nil {
+				return err
+			}
+			return tx.Put(ctx, &model.Application{Name: "tx-existing", Alias: "After"})
+		})).Should(Succeed())
+		Expect(mongodbDriver.Get(ctx, existing)).Should(Succeed())
+		Expect(existing.Alias).Should(Equal("After"))
+		Expect(mongodbDriver.Delete(ctx, &model.Application{Name: "tx-added"})).Should(Succeed())
+		Expect(mongodbDriver.Delete(ctx, existing)).Should(Succeed())
+	})
+
+	It("Test expire function", func() {
+		ctx := context.TODO()
+		Expect(mongodbDriver.Add(ctx, &model.Session{ID: "forever-session", Username: "expire-user"})).Should(Succeed())
+		session := &model.Session{ID: "expired-session", Username: "expire-user"}
+		session.SetExpireAt(time.Now().Add(-time.Minute))
+		Expect(mongodbDriver.Add(ctx, session)).Should(Succeed())
+
+		By("the TTL index is created with the first expiring entity")
+		m := mongodbDriver.(*mongodb)
+		cursor, err := m.client.Database(m.database).Collection(session.TableName()).Indexes().List(ctx)
+		Expect(err).ShouldNot(HaveOccurred())
+		var indexes []bson.M
+		Expect(cursor.All(ctx, &indexes)).Should(Succeed())
+		var ttl bson.M
+		for _, index := range indexes {
+			if index["name"] == expireIndexName {
+				ttl = index
+			}
+		}
+		Expect(ttl).ShouldNot(BeNil())
+		Expect(ttl["expireAfterSeconds"]).Should(BeNumerically("==", 0))
+		Expect(mongodbDriver.Delete(ctx, session)).Should(Succeed())
+		Expect(mongodbDriver.Delete(ctx, &model.Session{ID: "forever-session"})).Should(Succeed())
+	})
 })
@@ -0,0 +1,112 @@
+/*
+ Copyright 2023 The KubeVela Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ 	http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// searchIndexName the name of the text index, a collection has at most one text index
+const searchIndexName = "search_text"
+
+// searchIndexes the collections whose text indexes are ensured
+var searchIndexes sync.Map
+
+// ensureSearchIndex creates the text index of the search fields on the first search of the collection
+func (m *mongodb) ensureSearchIndex(ctx context.Context, entity datastore.SearchableEntity) error {
+	key := m.databaseName(ctx) + "/" + entity.TableName()
+	if _, ok := searchIndexes.Load(key); ok {
+		return nil
+	}
+	keys := bson.D{}
+	for _, field := range entity.SearchFields() {
+		keys = append(keys, bson.E{Key: strings.ToLower(field), Value: "text"})
+	}
+	collection := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName())
+	if _, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys, Options: options.Index().SetName(searchIndexName)}); err != nil {
+		return datastore.NewDBError(fmt.Errorf("create the text index of %s failure %w", entity.TableName(), err))
+	}
+	klog.Infof("the text index of the collection %s is ensured", entity.TableName())
+	searchIndexes.Store(key, struct{}{})
+	return nil
+}
+
+// Search matches the keywords by the text index, the keywords are quoted so the documents must contain all of them
+func (m *mongodb) Search(ctx context.Context, entity datastore.Entity, text string, op *datastore.ListOptions) ([]datastore.Entity, error) {
+	if entity.TableName() == "" {
+		return nil, datastore.ErrTableNameEmpty
+	}
+	searchable, ok := entity.(datastore.SearchableEntity)
+	if !ok {
+		return nil, datastore.ErrEntityInvalid
+	}
+	keywords := datastore.SearchKeywords(text)
+	if len(keywords) == 0 {
+		return nil, nil
+	}
+	if err := m.ensureSearchIndex(ctx, searchable); err != nil {
+		return nil, err
+	}
+	filter := bson.D{{Key: "$text", Value: bson.D{{Key: "$search", Value: `"` + strings.Join(keywords, `" "`) + `"`}}}}
+	for k, v := range entity.Index() {
+		filter = append(filter, bson.E{Key: strings.ToLower(k), Value: v})
+	}
+	if op != nil {
+		filter = _applyFilterOptions(filter, op.FilterOptions)
+	}
+	score := bson.D{{Key: "$meta", Value: "textScore"}}
+	findOptions := options.Find().SetProjection(bson.D{{Key: "score", Value: score}}).SetSort(bson.D{{Key: "score", Value: score}})
+	if op != nil && op.PageSize > 0 && op.Page > 0 {
+		findOptions.SetSkip(int64(op.PageSize * (op.Page - 1)))
+		findOptions.SetLimit(int64(op.PageSize))
+	}
+	ctx = m.withSession(ctx)
+	cur, err := m.client.Database(m.databaseName(ctx)).Collection(entity.TableName()).Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	defer func() {
+		if err := cur.Close(ctx); err != nil {
+			klog.Warningf("close mongodb cursor failure %s", err.Error())
+		}
+	}()
+	var list []datastore.Entity
+	for cur.Next(ctx) {
+		item, err := datastore.NewEntity(entity)
+		if err != nil {
+			return nil, datastore.NewDBError(err)
+		}
+		if err := cur.Decode(item); err != nil {
+			return nil, datastore.NewDBError(fmt.Errorf("decode entity failure %w", err))
+		}
+		list = append(list, item)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	return list, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// changeEvent the fields of the change stream events used by the watch
+type changeEvent struct {
+	OperationType string   `bson:"operationType"`
+	DocumentKey   bson.Raw `bson:"documentKey"`
+	FullDocument  bson.Raw `bson:"fullDocument"`
+}
+
+// Watch watches the change stream of the collection, the standalone MongoDB has no change streams so it is polled instead.
+// The deleted documents only have their IDs in the change events, so the matched entities are remembered by the IDs,
+// and an entity updated out of the index of the query is sent as deleted.
+func (m *mongodb) Watch(ctx context.Context, query datastore.Entity) (<-chan datastore.WatchEvent, error) {
+	if query.TableName() == "" {
+		return nil, datastore.ErrTableNameEmpty
+	}
+	collection := m.client.Database(m.databaseName(ctx)).Collection(query.TableName())
+	stream, err := collection.Watch(ctx, mongo.Pipeline{}, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		klog.Infof("the change stream of %s is not supported, poll the changes instead: %s", query.TableName(), err.Error())
+		return datastore.PollWatch(ctx, m, query, datastore.DefaultPollInterval)
+	}
+	known, err := m.watchSnapshot(ctx, collection, query)
+	if err != nil {
+		if err := stream.Close(context.Background()); err != nil {
+			klog.Warningf("close the change stream failure %s", err.Error())
+		}
+		return nil, err
+	}
+	ch := make(chan datastore.WatchEvent, datastore.WatchBufferSize)
+	go func() {
+		defer close(ch)
+		defer func() {
+			if err := stream.Close(context.Background()); err != nil {
+				klog.Warningf("close the change stream failure %s", err.Error())
+			}
+		}()
+		for stream.Next(ctx) {
+			var change changeEvent
+			if err := stream.Decode(&change); err != nil {
+				klog.Errorf("decode the change event of %s failure %s", query.TableName(), err.Error())
+				continue
+			}
+			event, ok, err := m.convertChange(query, known, change)
+			if err != nil {
+				klog.Errorf("convert the change event of %s failure %s", query.TableName(), err.Error())
+				continue
+			}
+			if ok && !datastore.SendWatchEvent(ctx, ch, event) {
+				return
+			}
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			klog.Errorf("watch the change stream of %s failure %s", query.TableName(), err.Error())
+		}
+	}()
+	return ch, nil
+}
+
+// watchSnapshot lists the entities matched by the query by their document IDs
+func (m *mongodb) watchSnapshot(ctx context.Context, collection *mongo.Collection, query datastore.Entity) (map[string]datastore.Entity, error) {
+	filter := bson.D{}
+	for k, v := range query.Index() {
+		filter = append(filter, bson.E{Key: strings.ToLower(k), Value: v})
+	}
+	cur, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	defer func() {
+		if err := cur.Close(ctx); err != nil {
+			klog.Warningf("close mongodb cursor failure %s", err.Error())
+		}
+	}()
+	known := map[string]datastore.Entity{}
+	for cur.Next(ctx) {
+		item, err := datastore.NewEntity(query)
+		if err != nil {
+			return nil, datastore.NewDBError(err)
+		}
+		if err := cur.Decode(item); err != nil {
+			return nil, datastore.NewDBError(fmt.Errorf("decode entity failure %w", err))
+		}
+		known[cur.Current.Lookup("_id").String()] = item
+	}
+	if err := cur.Err(); err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	return known, nil
+}
+
+// convertChange converts the change event to the watch event, false is returned if the change is not matched by the query
+func (m *mongodb) convertChange(query datastore.Entity, known map[string]datastore.Entity, change changeEvent) (datastore.WatchEvent, bool, error) {
+	if change.DocumentKey == nil {
+		return datastore.WatchEvent{}, false, nil
+	}
+	id := change.DocumentKey.Lookup("_id").String()
+	last, exist := known[id]
+	deleted := func() (datastore.WatchEvent, bool, error) {
+		if !exist {
+			return datastore.WatchEvent{}, false, nil
+		}
+		delete(known, id)
+		return datastore.WatchEvent{Type: datastore.WatchEventDeleted, Entity: last}, true, nil
+	}
+	switch change.OperationType {
+	case "delete":
+		return deleted()
+	case "insert", "update", "replace":
+		// the full document is empty if the document is deleted before the update is looked up, the delete event follows
+		if change.FullDocument == nil {
+			return datastore.WatchEvent{}, false, nil
+		}
+		entity, err := datastore.NewEntity(query)
+		if err != nil {
+			return datastore.WatchEvent{}, false, err
+		}
+		if err := bson.Unmarshal(change.FullDocument, entity); err != nil {
+			return datastore.WatchEvent{}, false, err
+		}
+		if !matchIndex(query, entity) {
+			return deleted()
+		}
+		known[id] = entity
+		if exist {
+			return datastore.WatchEvent{Type: datastore.WatchEventModified, Entity: entity}, true, nil
+		}
+		return datastore.WatchEvent{Type: datastore.WatchEventAdded, Entity: entity}, true, nil
+	default:
+		return datastore.WatchEvent{}, false, nil
+	}
+}
+
+func matchIndex(query, entity datastore.Entity) bool {
+	index := entity.Index()
+	for k, v := range query.Index() {
+		value, ok := index[k]
+		if !ok || fmt.Sprint(value) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+const (
+	// expireAtColumn the time the entity expires, it is NULL for the entities never expire
+	expireAtColumn = "`expire_at` DATETIME(6) NULL"
+	expireAtIndex  = "KEY `idx_expire_at` (`expire_at`)"
+)
+
+// expireAt the value of the expire_at column of the entity
+func expireAt(entity datastore.Entity) interface{} {
+	if t := datastore.ExpireAt(entity); t != nil {
+		return t.UTC()
+	}
+	return nil
+}
+
+// ensureExpireAt adds the expire_at column to the tables created before it, the stored entities have no expiry
+func (m *mysql) ensureExpireAt(ctx context.Context, entity datastore.Entity) error {
+	var exist int
+	err := m.db.QueryRowContext(ctx, "SELECT 1 FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = 'expire_at'", tableName(ctx, entity)).Scan(&exist)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return datastore.NewDBError(err)
+	}
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s, ADD %s", quoteIdentifier(tableName(ctx, entity)), expireAtColumn, expireAtIndex)); err != nil {
+		return datastore.NewDBError(err)
+	}
+	return nil
+}
+
+// deleteExpired deletes the expired rows of all tables with the expire_at column, including the tables not used by this process
+func (m *mysql) deleteExpired(ctx context.Context) (int, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT TABLE_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND COLUMN_NAME = 'expire_at'")
+	if err != nil {
+		return 0, err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	var deleted int
+	for _, table := range tables {
+		res, err := m.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE `expire_at` <= ?", quoteIdentifier(table)), now)
+		if err != nil {
+			return deleted, err
+		}
+		if affected, err := res.RowsAffected(); err == nil {
+			deleted += int(affected)
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// indexColumnLength the length of the generated columns of the indexed fields, the entities whose indexed fields
+// are longer could not be stored, so only the short fields such as the names are indexed
+const indexColumnLength = 255
+
+// indexColumn the generated column of the string value of the indexed field, the times of the base model are the columns
+func indexColumn(key string) string {
+	switch key {
+	case createTimeKey:
+		return "create_time"
+	case updateTimeKey:
+		return "update_time"
+	default:
+		return "field_" + strings.ReplaceAll(key, ".", "_")
+	}
+}
+
+// uniqueColumn the generated column of the unique indexes, the empty strings are NULL so they are not constrained
+func uniqueColumn(key string) string {
+	switch key {
+	case createTimeKey, updateTimeKey:
+		return indexColumn(key)
+	default:
+		return "unique_" + strings.ReplaceAll(key, ".", "_")
+	}
+}
+
+// fieldExpr the string value of the field, the indexed fields are read from the generated columns so the indexes are used
+func fieldExpr(key string, indexed map[string]bool) (string, []interface{}) {
+	if indexed[key] && key != createTimeKey && key != updateTimeKey {
+		return quoteIdentifier(indexColumn(key)), nil
+	}
+	return "JSON_UNQUOTE(JSON_EXTRACT(`data`, ?))", []interface{}{jsonPath(key)}
+}
+
+// stringLiteral quotes the string as a SQL literal, the generated columns could not use the placeholders
+func stringLiteral(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(s) + "'"
+}
+
+// queryStrings returns the strings of the first column of the rows
+func (m *mysql) queryStrings(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Close()
+}
+
+// ensureIndexColumns adds the generated columns of the indexed fields, the columns are virtual so they cost nothing
+// but the indexes. The columns of the removed indexes are kept.
+func (m *mysql) ensureIndexColumns(ctx context.Context, entity datastore.Entity) error {
+	table := tableName(ctx, entity)
+	columns, err := m.queryStrings(ctx, "SELECT COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", table)
+	if err != nil {
+		return datastore.NewDBError(err)
+	}
+	existing := map[string]bool{}
+	for _, column := range columns {
+		existing[strings.ToLower(column)] = true
+	}
+	var adds []string
+	add := func(column, expr string) {
+		if existing[strings.ToLower(column)] {
+			return
+		}
+		existing[strings.ToLower(column)] = true
+		adds = append(adds, fmt.Sprintf("ADD COLUMN %s VARCHAR(%d) AS (%s) VIRTUAL", quoteIdentifier(column), indexColumnLength, expr))
+	}
+	for _, index := range datastore.EntityIndexes(entity) {
+		for _, key := range index.Keys() {
+			if key == createTimeKey || key == updateTimeKey {
+				continue
+			}
+			add(indexColumn(key), "JSON_UNQUOTE(JSON_EXTRACT(`data`, "+stringLiteral(jsonPath(key))+"))")
+			if index.Unique {
+				add(uniqueColumn(key), "NULLIF("+quoteIdentifier(indexColumn(key))+", '')")
+			}
+		}
+	}
+	if len(adds) == 0 {
+		return nil
+	}
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s %s", quoteIdentifier(table), strings.Join(adds, ", "))); err != nil {
+		return datastore.NewDBError(err)
+	}
+	return nil
+}
+
+// reconcileIndexes creates and drops the indexes of the table by the indexes of the entity
+func (m *mysql) reconcileIndexes(ctx context.Context, entity datastore.Entity) error {
+	if err := m.ensureIndexColumns(ctx, entity); err != nil {
+		return err
+	}
+	table := quoteIdentifier(tableName(ctx, entity))
+	existing, err := m.queryStrings(ctx, "SELECT DISTINCT INDEX_NAME FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", tableName(ctx, entity))
+	if err != nil {
+		return datastore.NewDBError(err)
+	}
+	datastore.ReconcileIndexes(ctx, entity, existing, func(ctx context.Context, index datastore.Index) error {
+		var columns []string
+		for _, field := range index.Fields {
+			column := indexColumn(field.Key)
+			if index.Unique {
+				column = uniqueColumn(field.Key)
+			}
+			order := "ASC"
+			if field.Order == datastore.SortOrderDescending {
+				order = "DESC"
+			}
+			columns = append(columns, quoteIdentifier(column)+" "+order)
+		}
+		kind := "INDEX"
+		if index.Unique {
+			kind = "UNIQUE INDEX"
+		}
+		_, err := m.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD %s %s (%s)", table, kind, quoteIdentifier(index.Name), strings.Join(columns, ", ")))
+		return err
+	}, func(ctx context.Context, name string) error {
+		_, err := m.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", table, quoteIdentifier(name)))
+		return err
+	})
+	return nil
+}
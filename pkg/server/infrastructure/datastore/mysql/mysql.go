@@ -0,0 +1,568 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	driver "github.com/go-sql-driver/mysql"
+	"k8s.io/klog/v2"
+
+	pkgUtils "github.com/oam-dev/kubevela/pkg/utils"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+const (
+	// errDuplicateEntry the error number of the duplicate primary key or unique index
+	errDuplicateEntry = 1062
+
+	createTimeKey = "createTime"
+	updateTimeKey = "updateTime"
+
+	// resourceVersionColumn the version of the entity, the entities stored before the versions are added have no version
+	resourceVersionColumn = "CAST(IFNULL(JSON_EXTRACT(`data`, '$.resourceVersion'), 0) AS SIGNED)"
+)
+
+// mysql stores each table as a SQL table, the entities are stored as the JSON documents
+// so the indices and the queries are the same as the other drivers
+type mysql struct {
+	db *sql.DB
+	// conn is the transaction in a transaction, otherwise it is the db
+	conn   conn
+	tables *sync.Map
+}
+
+// conn the queries shared by the database and the transactions
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// New new mysql datastore instance, the URL is the DSN without the database, such as user:password@tcp(127.0.0.1:3306)/
+func New(ctx context.Context, cfg datastore.Config) (datastore.DataStore, error) {
+	dsn, err := driver.ParseDSN(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mysql URL: %w", err)
+	}
+	dsn.ParseTime = true
+	dsn.Loc = time.UTC
+	// the updates of the unchanged rows are counted, so the missing rows are distinguished by the affected rows
+	dsn.ClientFoundRows = true
+	dsn.DBName = ""
+	admin, err := sql.Open("mysql", dsn.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer admin.Close()
+	if _, err := admin.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s CHARACTER SET utf8mb4", quoteIdentifier(cfg.Database))); err != nil {
+		return nil, fmt.Errorf("create the database %s failure %w", cfg.Database, err)
+	}
+
+	dsn.DBName = cfg.Database
+	db, err := sql.Open("mysql", dsn.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+	m := &mysql{db: db, conn: db, tables: &sync.Map{}}
+	for _, registered := range model.GetRegisterModels() {
+		entity, ok := registered.(datastore.IndexedEntity)
+		if !ok {
+			continue
+		}
+		for _, partition := range datastore.Partitions(ctx, cfg.Tenants) {
+			if err := m.ensureTable(partition, entity); err != nil {
+				return nil, fmt.Errorf("ensure the table %s failure %w", entity.TableName(), err)
+			}
+		}
+	}
+	go datastore.RunJanitor(ctx, "mysql", m.deleteExpired)
+	return m, nil
+}
+
+// ensureTable creates the table and reconciles its indexes on the first use, the tables are never dropped. The tables are created out of the
+// transactions because the DDL statements commit the transactions implicitly.
+func (m *mysql) ensureTable(ctx context.Context, entity datastore.Entity) error {
+	table := tableName(ctx, entity)
+	if _, ok := m.tables.Load(table); ok {
+		return nil
+	}
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s ("+
+		"`name` VARCHAR(512) NOT NULL,"+
+		"`data` JSON NOT NULL,"+
+		"`create_time` DATETIME(6) NOT NULL,"+
+		"`update_time` DATETIME(6) NOT NULL,"+
+		searchTextColumn+","+
+		expireAtColumn+","+
+		"PRIMARY KEY (`name`),"+
+		"KEY `idx_create_time` (`create_time`),"+
+		expireAtIndex+","+
+		searchTextIndex+
+		") DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin", quoteIdentifier(table))
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return datastore.NewDBError(err)
+	}
+	if err := m.ensureSearchText(ctx, entity); err != nil {
+		return err
+	}
+	if err := m.ensureExpireAt(ctx, entity); err != nil {
+		return err
+	}
+	if err := m.reconcileIndexes(ctx, entity); err != nil {
+		return err
+	}
+	m.tables.Store(table, struct{}{})
+	return nil
+}
+
+func checkEntity(entity datastore.Entity) error {
+	if entity.PrimaryKey() == "" {
+		return datastore.ErrPrimaryEmpty
+	}
+	if entity.TableName() == "" {
+		return datastore.ErrTableNameEmpty
+	}
+	return nil
+}
+
+// Add add data model
+func (m *mysql) Add(ctx context.Context, entity datastore.Entity) error {
+	if err := checkEntity(entity); err != nil {
+		return err
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return err
+	}
+	return insert(ctx, m.conn, entity)
+}
+
+func insert(ctx context.Context, db conn, entity datastore.Entity) error {
+	now := time.Now()
+	entity.SetCreateTime(now)
+	entity.SetUpdateTime(now)
+	entity.SetResourceVersion(1)
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return datastore.ErrEntityInvalid
+	}
+	query := fmt.Sprintf("INSERT INTO %s (`name`, `data`, `create_time`, `update_time`, `search_text`, `expire_at`) VALUES (?, ?, ?, ?, ?, ?)", quoteIdentifier(tableName(ctx, entity)))
+	if _, err := db.ExecContext(ctx, query, entity.PrimaryKey(), string(data), now.UTC(), now.UTC(), searchText(entity), expireAt(entity)); err != nil {
+		var mysqlErr *driver.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+			return datastore.ErrRecordExist
+		}
+		return datastore.NewDBError(err)
+	}
+	return nil
+}
+
+// BatchAdd batch add entity, the entities are added in a transaction
+func (m *mysql) BatchAdd(ctx context.Context, entities []datastore.Entity) error {
+	return m.Transaction(ctx, func(tx datastore.DataStore) error {
+		for _, entity := range entities {
+			if err := tx.Add(ctx, entity); err != nil {
+				return datastore.NewDBError(fmt.Errorf("save entities occur error, %w", err))
+			}
+		}
+		return nil
+	})
+}
+
+// Transaction runs fn in a SQL transaction
+func (m *mysql) Transaction(ctx context.Context, fn func(tx datastore.DataStore) error) error {
+	if _, ok := m.conn.(*sql.Tx); ok {
+		return fn(m)
+	}
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return datastore.NewDBError(err)
+	}
+	if err := fn(&mysql{db: m.db, conn: tx, tables: m.tables}); err != nil {
+		if err := tx.Rollback(); err != nil {
+			klog.Errorf("rollback the transaction failure %s", err.Error())
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return datastore.NewDBError(err)
+	}
+	return nil
+}
+
+// Watch polls the table because MySQL has no change notifications without the binlog, the poll is not joined to the transaction
+func (m *mysql) Watch(ctx context.Context, query datastore.Entity) (<-chan datastore.WatchEvent, error) {
+	return datastore.PollWatch(ctx, &mysql{db: m.db, conn: m.db, tables: m.tables}, query, datastore.DefaultPollInterval)
+}
+
+// Get get data model
+func (m *mysql) Get(ctx context.Context, entity datastore.Entity) error {
+	if err := checkEntity(entity); err != nil {
+		return err
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return err
+	}
+	var data string
+	query := fmt.Sprintf("SELECT `data` FROM %s WHERE `name` = ?", quoteIdentifier(tableName(ctx, entity)))
+	if err := m.conn.QueryRowContext(ctx, query, entity.PrimaryKey()).Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return datastore.ErrRecordNotExist
+		}
+		return datastore.NewDBError(err)
+	}
+	if err := json.Unmarshal([]byte(data), entity); err != nil {
+		return datastore.NewDBError(err)
+	}
+	return nil
+}
+
+// Put update data model
+func (m *mysql) Put(ctx context.Context, entity datastore.Entity) error {
+	if err := checkEntity(entity); err != nil {
+		return err
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return err
+	}
+	now := time.Now()
+	entity.SetUpdateTime(now)
+	version := entity.GetResourceVersion()
+	if version == 0 {
+		// the entity without the version overwrites the stored one, it is compared with the version read just now
+		stored, err := m.storedResourceVersion(ctx, entity)
+		if err != nil {
+			return err
+		}
+		entity.SetResourceVersion(stored)
+	}
+	expected := entity.GetResourceVersion()
+	entity.SetResourceVersion(expected + 1)
+	data, err := json.Marshal(entity)
+	if err != nil {
+		entity.SetResourceVersion(version)
+		return datastore.ErrEntityInvalid
+	}
+	query := fmt.Sprintf("UPDATE %s SET `data` = ?, `update_time` = ?, `search_text` = ?, `expire_at` = ? WHERE `name` = ? AND %s = ?", quoteIdentifier(tableName(ctx, entity)), resourceVersionColumn)
+	res, err := m.conn.ExecContext(ctx, query, string(data), now.UTC(), searchText(entity), expireAt(entity), entity.PrimaryKey(), expected)
+	if err != nil {
+		entity.SetResourceVersion(version)
+		var mysqlErr *driver.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+			return datastore.ErrRecordExist
+		}
+		return datastore.NewDBError(err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		entity.SetResourceVersion(version)
+		exist, err := m.IsExist(ctx, entity)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return datastore.ErrRecordNotExist
+		}
+		return datastore.ErrRecordConflict
+	}
+	return nil
+}
+
+// BatchPut update entities together, none of them is updated if any fails
+func (m *mysql) BatchPut(ctx context.Context, entities []datastore.Entity) error {
+	return m.Transaction(ctx, func(tx datastore.DataStore) error {
+		for _, entity := range entities {
+			if err := tx.Put(ctx, entity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *mysql) storedResourceVersion(ctx context.Context, entity datastore.Entity) (int64, error) {
+	var version int64
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE `name` = ?", resourceVersionColumn, quoteIdentifier(tableName(ctx, entity)))
+	if err := m.conn.QueryRowContext(ctx, query, entity.PrimaryKey()).Scan(&version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, datastore.ErrRecordNotExist
+		}
+		return 0, datastore.NewDBError(err)
+	}
+	return version, nil
+}
+
+// IsExist determine whether data exists.
+func (m *mysql) IsExist(ctx context.Context, entity datastore.Entity) (bool, error) {
+	if err := checkEntity(entity); err != nil {
+		return false, err
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return false, err
+	}
+	var exist int
+	query := fmt.Sprintf("SELECT 1 FROM %s WHERE `name` = ?", quoteIdentifier(tableName(ctx, entity)))
+	if err := m.conn.QueryRowContext(ctx, query, entity.PrimaryKey()).Scan(&exist); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, datastore.NewDBError(err)
+	}
+	return true, nil
+}
+
+// Delete delete data
+func (m *mysql) Delete(ctx context.Context, entity datastore.Entity) error {
+	if err := checkEntity(entity); err != nil {
+		return err
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE `name` = ?", quoteIdentifier(tableName(ctx, entity)))
+	res, err := m.conn.ExecContext(ctx, query, entity.PrimaryKey())
+	if err != nil {
+		return datastore.NewDBError(err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return datastore.ErrRecordNotExist
+	}
+	return nil
+}
+
+// DeleteByFilter delete the entities matched by the query and the filter options
+func (m *mysql) DeleteByFilter(ctx context.Context, entity datastore.Entity, filterOptions *datastore.FilterOptions) (int64, error) {
+	if entity.TableName() == "" {
+		return 0, datastore.ErrTableNameEmpty
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return 0, err
+	}
+	where, args := makeWhere(entity, filterOptions)
+	query := fmt.Sprintf("DELETE FROM %s%s", quoteIdentifier(tableName(ctx, entity)), where)
+	res, err := m.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, datastore.NewDBError(err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, datastore.NewDBError(err)
+	}
+	return affected, nil
+}
+
+// List list entity function
+func (m *mysql) List(ctx context.Context, entity datastore.Entity, op *datastore.ListOptions) ([]datastore.Entity, error) {
+	if entity.TableName() == "" {
+		return nil, datastore.ErrTableNameEmpty
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return nil, err
+	}
+	var filterOptions *datastore.FilterOptions
+	if op != nil {
+		filterOptions = &op.FilterOptions
+	}
+	where, args := makeWhere(entity, filterOptions)
+	query := fmt.Sprintf("SELECT `data` FROM %s%s", quoteIdentifier(tableName(ctx, entity)), where)
+	if op != nil && len(op.SortBy) > 0 {
+		var orders []string
+		for _, sortOp := range op.SortBy {
+			order := "ASC"
+			if sortOp.Order == datastore.SortOrderDescending {
+				order = "DESC"
+			}
+			switch sortOp.Key {
+			case createTimeKey:
+				orders = append(orders, "`create_time` "+order)
+			case updateTimeKey:
+				orders = append(orders, "`update_time` "+order)
+			default:
+				orders = append(orders, "JSON_EXTRACT(`data`, ?) "+order)
+				args = append(args, jsonPath(sortOp.Key))
+			}
+		}
+		query += " ORDER BY " + strings.Join(orders, ", ")
+	}
+	if op != nil && op.PageSize > 0 && op.Page > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, op.PageSize, op.PageSize*(op.Page-1))
+	}
+	rows, err := m.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			klog.Warningf("close mysql rows failure %s", err.Error())
+		}
+	}()
+	var list []datastore.Entity
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, datastore.NewDBError(err)
+		}
+		item, err := datastore.NewEntity(entity)
+		if err != nil {
+			return nil, datastore.NewDBError(err)
+		}
+		if err := json.Unmarshal([]byte(data), item); err != nil {
+			return nil, datastore.NewDBError(fmt.Errorf("decode entity failure %w", err))
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	return list, nil
+}
+
+// Count counts entities
+func (m *mysql) Count(ctx context.Context, entity datastore.Entity, filterOptions *datastore.FilterOptions) (int64, error) {
+	if entity.TableName() == "" {
+		return 0, datastore.ErrTableNameEmpty
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return 0, err
+	}
+	where, args := makeWhere(entity, filterOptions)
+	var count int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", quoteIdentifier(tableName(ctx, entity)), where)
+	if err := m.conn.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, datastore.NewDBError(err)
+	}
+	return count, nil
+}
+
+// makeWhere matches the indices of the entity and the filter options, the keys are the JSON paths of the fields.
+// The values are compared as strings, the same as the labels of the kubeapi driver.
+func makeWhere(entity datastore.Entity, filterOptions *datastore.FilterOptions) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	indexed := datastore.IndexedKeys(entity)
+	for k, v := range entity.Index() {
+		expr, exprArgs := fieldExpr(k, indexed)
+		conditions = append(conditions, expr+" = ?")
+		args = append(append(args, exprArgs...), pkgUtils.ToString(v))
+	}
+	if filterOptions != nil {
+		filterConditions, filterArgs := makeFilterConditions(*filterOptions, indexed)
+		conditions = append(conditions, filterConditions...)
+		args = append(args, filterArgs...)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// makeFilterConditions converts the filter options to the conditions joined by AND, the missing fields are NULL
+func makeFilterConditions(filterOptions datastore.FilterOptions, indexed map[string]bool) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, queryOp := range filterOptions.Queries {
+		expr, exprArgs := fieldExpr(queryOp.Key, indexed)
+		conditions = append(conditions, expr+" LIKE ?")
+		args = append(append(args, exprArgs...), "%"+escapeLike(queryOp.Query)+"%")
+	}
+	for _, queryOp := range filterOptions.In {
+		if len(queryOp.Values) == 0 {
+			conditions = append(conditions, "FALSE")
+			continue
+		}
+		expr, exprArgs := fieldExpr(queryOp.Key, indexed)
+		conditions = append(conditions, expr+" IN (?"+strings.Repeat(", ?", len(queryOp.Values)-1)+")")
+		args = append(args, exprArgs...)
+		for _, value := range queryOp.Values {
+			args = append(args, value)
+		}
+	}
+	for _, queryOp := range filterOptions.NotIn {
+		if len(queryOp.Values) == 0 {
+			continue
+		}
+		expr, exprArgs := fieldExpr(queryOp.Key, indexed)
+		conditions = append(conditions, "("+expr+" IS NULL OR "+expr+" NOT IN (?"+strings.Repeat(", ?", len(queryOp.Values)-1)+"))")
+		args = append(append(args, exprArgs...), exprArgs...)
+		for _, value := range queryOp.Values {
+			args = append(args, value)
+		}
+	}
+	for _, queryOp := range filterOptions.IsExist {
+		expr, exprArgs := fieldExpr(queryOp.Key, indexed)
+		conditions = append(conditions, "("+expr+" IS NOT NULL AND "+expr+" != '')")
+		args = append(append(args, exprArgs...), exprArgs...)
+	}
+	for _, queryOp := range filterOptions.IsNotExist {
+		expr, exprArgs := fieldExpr(queryOp.Key, indexed)
+		conditions = append(conditions, "("+expr+" IS NULL OR "+expr+" = '')")
+		args = append(append(args, exprArgs...), exprArgs...)
+	}
+	if len(filterOptions.Or) > 0 {
+		var groups []string
+		for _, group := range filterOptions.Or {
+			groupConditions, groupArgs := makeFilterConditions(group, indexed)
+			if len(groupConditions) == 0 {
+				groupConditions = []string{"TRUE"}
+			}
+			groups = append(groups, "("+strings.Join(groupConditions, " AND ")+")")
+			args = append(args, groupArgs...)
+		}
+		conditions = append(conditions, "("+strings.Join(groups, " OR ")+")")
+	}
+	return conditions, args
+}
+
+// jsonPath converts the key such as principal.type to the JSON path $."principal"."type"
+func jsonPath(key string) string {
+	var path strings.Builder
+	path.WriteString("$")
+	for _, field := range strings.Split(key, ".") {
+		path.WriteString(`."`)
+		path.WriteString(strings.ReplaceAll(strings.ReplaceAll(field, `\`, `\\`), `"`, `\"`))
+		path.WriteString(`"`)
+	}
+	return path.String()
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// tableName the table of the entity in the partition of the tenant of the context
+func tableName(ctx context.Context, entity datastore.Entity) string {
+	if tenant := datastore.TenantFromContext(ctx); tenant != "" {
+		return tenant + "__" + entity.TableName()
+	}
+	return entity.TableName()
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,158 @@
+/*
+ Copyright 2023 The KubeVela Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ 	http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+const (
+	// searchTextColumn the joined search fields of the searchable entities, it is empty for the other entities.
+	// It is case-insensitive, unlike the binary collation of the table.
+	searchTextColumn = "`search_text` TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci"
+	searchTextIndex  = "FULLTEXT KEY `idx_search_text` (`search_text`)"
+	searchTextMatch  = "MATCH(`search_text`) AGAINST(? IN BOOLEAN MODE)"
+)
+
+func searchText(entity datastore.Entity) string {
+	if searchable, ok := entity.(datastore.SearchableEntity); ok {
+		return datastore.SearchText(searchable)
+	}
+	return ""
+}
+
+// ensureSearchText adds the search text column to the tables created before it, and fills it for the stored searchable entities
+func (m *mysql) ensureSearchText(ctx context.Context, entity datastore.Entity) error {
+	var exist int
+	err := m.db.QueryRowContext(ctx, "SELECT 1 FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = 'search_text'", tableName(ctx, entity)).Scan(&exist)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return datastore.NewDBError(err)
+	}
+	table := quoteIdentifier(tableName(ctx, entity))
+	if _, err := m.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s, ADD %s", table, searchTextColumn, searchTextIndex)); err != nil {
+		return datastore.NewDBError(err)
+	}
+	if _, ok := entity.(datastore.SearchableEntity); !ok {
+		return nil
+	}
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf("SELECT `name`, `data` FROM %s", table))
+	if err != nil {
+		return datastore.NewDBError(err)
+	}
+	texts := map[string]string{}
+	for rows.Next() {
+		var name, data string
+		if err := rows.Scan(&name, &data); err != nil {
+			_ = rows.Close()
+			return datastore.NewDBError(err)
+		}
+		item, err := datastore.NewEntity(entity)
+		if err != nil {
+			_ = rows.Close()
+			return datastore.NewDBError(err)
+		}
+		if err := json.Unmarshal([]byte(data), item); err != nil {
+			klog.Warningf("skip filling the search text of %s/%s: %s", entity.TableName(), name, err.Error())
+			continue
+		}
+		texts[name] = searchText(item)
+	}
+	if err := rows.Close(); err != nil {
+		return datastore.NewDBError(err)
+	}
+	for name, text := range texts {
+		if _, err := m.db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET `search_text` = ? WHERE `name` = ?", table), text, name); err != nil {
+			return datastore.NewDBError(err)
+		}
+	}
+	klog.Infof("the search text of %d entities in the table %s is filled", len(texts), entity.TableName())
+	return nil
+}
+
+// Search matches the keywords by the full-text index in the boolean mode, each keyword is required and matches the word prefixes
+func (m *mysql) Search(ctx context.Context, entity datastore.Entity, text string, op *datastore.ListOptions) ([]datastore.Entity, error) {
+	if entity.TableName() == "" {
+		return nil, datastore.ErrTableNameEmpty
+	}
+	if _, ok := entity.(datastore.SearchableEntity); !ok {
+		return nil, datastore.ErrEntityInvalid
+	}
+	keywords := datastore.SearchKeywords(text)
+	if len(keywords) == 0 {
+		return nil, nil
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return nil, err
+	}
+	against := "+" + strings.Join(keywords, "* +") + "*"
+	var filterOptions *datastore.FilterOptions
+	if op != nil {
+		filterOptions = &op.FilterOptions
+	}
+	where, args := makeWhere(entity, filterOptions)
+	if where == "" {
+		where = " WHERE " + searchTextMatch
+	} else {
+		where += " AND " + searchTextMatch
+	}
+	args = append(args, against, against)
+	query := fmt.Sprintf("SELECT `data` FROM %s%s ORDER BY %s DESC, `name` ASC", quoteIdentifier(tableName(ctx, entity)), where, searchTextMatch)
+	if op != nil && op.PageSize > 0 && op.Page > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, op.PageSize, op.PageSize*(op.Page-1))
+	}
+	rows, err := m.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			klog.Warningf("close mysql rows failure %s", err.Error())
+		}
+	}()
+	var list []datastore.Entity
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, datastore.NewDBError(err)
+		}
+		item, err := datastore.NewEntity(entity)
+		if err != nil {
+			return nil, datastore.NewDBError(err)
+		}
+		if err := json.Unmarshal([]byte(data), item); err != nil {
+			return nil, datastore.NewDBError(fmt.Errorf("decode entity failure %w", err))
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	return list, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// SearchableEntity the entity whose text fields could be searched by the keywords
+type SearchableEntity interface {
+	Entity
+	// SearchFields returns the JSON keys of the text fields, such as name, alias and description
+	SearchFields() []string
+}
+
+// SearchText joins the values of the search fields, the fields that are not strings or string lists are skipped
+func SearchText(entity SearchableEntity) string {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return ""
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+	var values []string
+	for _, key := range entity.SearchFields() {
+		switch value := fields[key].(type) {
+		case string:
+			values = append(values, value)
+		case []interface{}:
+			for _, item := range value {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// SearchKeywords splits the text into the lowercase words, the punctuations separate the words,
+// so the name my-app has the keywords my and app
+func SearchKeywords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
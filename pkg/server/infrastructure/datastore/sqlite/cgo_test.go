@@ -0,0 +1,39 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// TestCgoRequired runs with CGO_ENABLED=0 in the CI too, the server built without cgo must refuse the sqlite datastore at the start
+func TestCgoRequired(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := New(ctx, datastore.Config{URL: filepath.Join(t.TempDir(), "cgo.db"), Database: "cgo"})
+	if cgoEnabled {
+		assert.NoError(t, err)
+		return
+	}
+	assert.True(t, errors.Is(err, ErrCgoRequired))
+}
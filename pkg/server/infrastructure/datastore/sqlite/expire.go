@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// expireAt the value of the expire_at column of the entity, the unix time in nanoseconds or NULL for the entities never expire
+func expireAt(entity datastore.Entity) interface{} {
+	if t := datastore.ExpireAt(entity); t != nil {
+		return t.UnixNano()
+	}
+	return nil
+}
+
+// deleteExpired deletes the expired rows of all tables, including the tables of the tenants and the tables not used by this process
+func (m *sqlite) deleteExpired(ctx context.Context) (int, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT `name` FROM `sqlite_master` WHERE `type` = 'table' AND `name` NOT LIKE 'sqlite\\_%' ESCAPE '\\'")
+	if err != nil {
+		return 0, err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+	now := time.Now().UnixNano()
+	var deleted int
+	for _, table := range tables {
+		res, err := m.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE `expire_at` <= ?", quoteIdentifier(table)), now)
+		if err != nil {
+			return deleted, err
+		}
+		if affected, err := res.RowsAffected(); err == nil {
+			deleted += int(affected)
+		}
+	}
+	return deleted, nil
+}
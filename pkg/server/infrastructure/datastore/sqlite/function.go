@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+const (
+	// driverName the SQLite driver with the functions of the datastore, the JSON1 extension is not compiled by default
+	driverName = "velaux_sqlite3"
+	// fieldFunction returns the string value of the field of the JSON document by the key such as principal.type
+	fieldFunction = "velaux_field"
+	// searchScoreFunction scores the search text by the keywords, it is 0 if any keyword is not matched
+	searchScoreFunction = "velaux_search_score"
+)
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if err := conn.RegisterFunc(fieldFunction, field, true); err != nil {
+				return err
+			}
+			return conn.RegisterFunc(searchScoreFunction, searchScore, true)
+		},
+	})
+}
+
+// field returns the value of the field as the string, the same as the labels of the kubeapi driver.
+// It is empty if the field is missing or null, the objects and the arrays are the JSON documents.
+func field(data, key string) string {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return ""
+	}
+	for _, name := range strings.Split(key, ".") {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		value = fields[name]
+	}
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	default:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return ""
+		}
+		return strings.TrimSuffix(buf.String(), "\n")
+	}
+}
+
+// searchScore scores the text by the keywords joined by the spaces, a keyword matches the word prefixes and the whole
+// words score higher, the same as the search index of the kubeapi driver
+func searchScore(text, keywords string) int64 {
+	words := datastore.SearchKeywords(text)
+	var total int64
+	for _, keyword := range strings.Fields(keywords) {
+		var score int64
+		for _, word := range words {
+			if word == keyword {
+				score = 2
+				break
+			}
+			if strings.HasPrefix(word, keyword) {
+				score = 1
+			}
+		}
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+	return total
+}
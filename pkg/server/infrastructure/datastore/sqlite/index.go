@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// fieldExpr the string value of the field, the key is a literal so the expression matches the one of the index
+func fieldExpr(key string) string {
+	return fieldFunction + "(`data`, '" + strings.ReplaceAll(key, "'", "''") + "')"
+}
+
+// indexColumn the indexed expression of the field, the times of the base model are the columns
+func indexColumn(key string) string {
+	switch key {
+	case createTimeKey:
+		return "`create_time`"
+	case updateTimeKey:
+		return "`update_time`"
+	default:
+		return fieldExpr(key)
+	}
+}
+
+// indexName the index names of SQLite are unique in the database, so they are prefixed by the table
+func indexName(table, name string) string {
+	return table + "_" + name
+}
+
+// reconcileIndexes creates and drops the indexes of the table by the indexes of the entity
+func (m *sqlite) reconcileIndexes(ctx context.Context, entity datastore.Entity) error {
+	table := tableName(ctx, entity)
+	rows, err := m.conn.QueryContext(ctx, "SELECT `name` FROM `sqlite_master` WHERE `type` = 'index' AND `tbl_name` = ?", table)
+	if err != nil {
+		return datastore.NewDBError(err)
+	}
+	var existing []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return datastore.NewDBError(err)
+		}
+		if strings.HasPrefix(name, table+"_") {
+			existing = append(existing, strings.TrimPrefix(name, table+"_"))
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return datastore.NewDBError(err)
+	}
+	datastore.ReconcileIndexes(ctx, entity, existing, func(ctx context.Context, index datastore.Index) error {
+		var columns, conditions []string
+		for _, field := range index.Fields {
+			order := "ASC"
+			if field.Order == datastore.SortOrderDescending {
+				order = "DESC"
+			}
+			columns = append(columns, indexColumn(field.Key)+" "+order)
+			if field.Key != createTimeKey && field.Key != updateTimeKey {
+				conditions = append(conditions, fieldExpr(field.Key)+" != ''")
+			}
+		}
+		query := fmt.Sprintf("CREATE INDEX %s ON %s (%s)", quoteIdentifier(indexName(table, index.Name)), quoteIdentifier(table), strings.Join(columns, ", "))
+		if index.Unique {
+			// the entities with an empty field are not constrained, the same as the other drivers
+			query = "CREATE UNIQUE" + strings.TrimPrefix(query, "CREATE")
+			if len(conditions) > 0 {
+				query += " WHERE " + strings.Join(conditions, " AND ")
+			}
+		}
+		_, err := m.conn.ExecContext(ctx, query)
+		return err
+	}, func(ctx context.Context, name string) error {
+		_, err := m.conn.ExecContext(ctx, fmt.Sprintf("DROP INDEX %s", quoteIdentifier(indexName(table, name))))
+		return err
+	})
+	return nil
+}
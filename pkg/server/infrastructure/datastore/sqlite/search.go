@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+func searchText(entity datastore.Entity) string {
+	if searchable, ok := entity.(datastore.SearchableEntity); ok {
+		return datastore.SearchText(searchable)
+	}
+	return ""
+}
+
+// Search scores the search text of the rows by the keywords, the full-text extension is not compiled by default.
+// Each keyword is required and matches the word prefixes.
+func (m *sqlite) Search(ctx context.Context, entity datastore.Entity, text string, op *datastore.ListOptions) ([]datastore.Entity, error) {
+	if entity.TableName() == "" {
+		return nil, datastore.ErrTableNameEmpty
+	}
+	if _, ok := entity.(datastore.SearchableEntity); !ok {
+		return nil, datastore.ErrEntityInvalid
+	}
+	keywords := datastore.SearchKeywords(text)
+	if len(keywords) == 0 {
+		return nil, nil
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return nil, err
+	}
+	score := searchScoreFunction + "(`search_text`, ?)"
+	var filterOptions *datastore.FilterOptions
+	if op != nil {
+		filterOptions = &op.FilterOptions
+	}
+	where, args := makeWhere(entity, filterOptions)
+	if where == "" {
+		where = " WHERE " + score + " > 0"
+	} else {
+		where += " AND " + score + " > 0"
+	}
+	joined := strings.Join(keywords, " ")
+	args = append(args, joined, joined)
+	query := fmt.Sprintf("SELECT `data` FROM %s%s ORDER BY %s DESC, `name` ASC", quoteIdentifier(tableName(ctx, entity)), where, score)
+	query, args = paginate(query, args, op)
+	return m.query(ctx, entity, query, args)
+}
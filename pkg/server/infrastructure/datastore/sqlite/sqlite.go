@@ -0,0 +1,546 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"k8s.io/klog/v2"
+
+	pkgUtils "github.com/oam-dev/kubevela/pkg/utils"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+const (
+	createTimeKey = "createTime"
+	updateTimeKey = "updateTime"
+
+	// busyTimeout how long a write waits for the lock of the database file held by the other writers
+	busyTimeout = 5 * time.Second
+)
+
+// ErrCgoRequired the server is built with CGO_ENABLED=0, the SQLite driver could not work without cgo
+var ErrCgoRequired = errors.New("the sqlite datastore requires the server built with CGO_ENABLED=1")
+
+// sqlite stores each table as a SQL table in a local database file, the entities are stored as the JSON documents
+// so the indices and the queries are the same as the other drivers. The file could be opened by one process only.
+type sqlite struct {
+	db *sql.DB
+	// conn is the transaction in a transaction, otherwise it is the db
+	conn   conn
+	tables *sync.Map
+}
+
+// conn the queries shared by the database and the transactions
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// New new sqlite datastore instance, the URL is the path of the database file, it is <database>.db in the working
+// directory if the URL is empty. The driver requires the binary built with cgo.
+func New(ctx context.Context, cfg datastore.Config) (datastore.DataStore, error) {
+	if !cgoEnabled {
+		return nil, ErrCgoRequired
+	}
+	path := cfg.URL
+	if path == "" {
+		path = cfg.Database + ".db"
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return nil, fmt.Errorf("create the directory of the sqlite database failure %w", err)
+		}
+	}
+	// the writes of the transactions take the lock at the beginning, so the concurrent transactions wait for each other
+	// instead of failing on the lock upgrade
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=%d&_journal_mode=WAL&_txlock=immediate", path, busyTimeout.Milliseconds())
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+	m := &sqlite{db: db, conn: db, tables: &sync.Map{}}
+	for _, registered := range model.GetRegisterModels() {
+		entity, ok := registered.(datastore.IndexedEntity)
+		if !ok {
+			continue
+		}
+		for _, partition := range datastore.Partitions(ctx, cfg.Tenants) {
+			if err := m.ensureTable(partition, entity); err != nil {
+				return nil, fmt.Errorf("ensure the table %s failure %w", entity.TableName(), err)
+			}
+		}
+	}
+	go datastore.RunJanitor(ctx, "sqlite", m.deleteExpired)
+	return m, nil
+}
+
+// ensureTable creates the table and reconciles its indexes on the first use, the tables are never dropped. SQLite runs
+// the DDL statements in the transactions, the table created in a transaction is not cached because the transaction
+// could be rolled back.
+func (m *sqlite) ensureTable(ctx context.Context, entity datastore.Entity) error {
+	table := tableName(ctx, entity)
+	if _, ok := m.tables.Load(table); ok {
+		return nil
+	}
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %[1]s ("+
+		"`name` TEXT NOT NULL PRIMARY KEY,"+
+		"`data` TEXT NOT NULL,"+
+		"`resource_version` INTEGER NOT NULL,"+
+		"`create_time` INTEGER NOT NULL,"+
+		"`update_time` INTEGER NOT NULL,"+
+		"`search_text` TEXT NOT NULL DEFAULT '',"+
+		"`expire_at` INTEGER NULL);"+
+		"CREATE INDEX IF NOT EXISTS %[2]s ON %[1]s (`create_time`);"+
+		"CREATE INDEX IF NOT EXISTS %[3]s ON %[1]s (`expire_at`)",
+		quoteIdentifier(table), quoteIdentifier(table+"_create_time"), quoteIdentifier(table+"_expire_at"))
+	if _, err := m.conn.ExecContext(ctx, query); err != nil {
+		return datastore.NewDBError(err)
+	}
+	if err := m.reconcileIndexes(ctx, entity); err != nil {
+		return err
+	}
+	if _, ok := m.conn.(*sql.Tx); !ok {
+		m.tables.Store(table, struct{}{})
+	}
+	return nil
+}
+
+// isDuplicate returns true if the error is the violation of the primary key or a unique index
+func isDuplicate(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey || sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique)
+}
+
+func checkEntity(entity datastore.Entity) error {
+	if entity.PrimaryKey() == "" {
+		return datastore.ErrPrimaryEmpty
+	}
+	if entity.TableName() == "" {
+		return datastore.ErrTableNameEmpty
+	}
+	return nil
+}
+
+// Add add data model
+func (m *sqlite) Add(ctx context.Context, entity datastore.Entity) error {
+	if err := checkEntity(entity); err != nil {
+		return err
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return err
+	}
+	now := time.Now()
+	entity.SetCreateTime(now)
+	entity.SetUpdateTime(now)
+	entity.SetResourceVersion(1)
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return datastore.ErrEntityInvalid
+	}
+	query := fmt.Sprintf("INSERT INTO %s (`name`, `data`, `resource_version`, `create_time`, `update_time`, `search_text`, `expire_at`) VALUES (?, ?, ?, ?, ?, ?, ?)", quoteIdentifier(tableName(ctx, entity)))
+	if _, err := m.conn.ExecContext(ctx, query, entity.PrimaryKey(), string(data), 1, now.UnixNano(), now.UnixNano(), searchText(entity), expireAt(entity)); err != nil {
+		if isDuplicate(err) {
+			return datastore.ErrRecordExist
+		}
+		return datastore.NewDBError(err)
+	}
+	return nil
+}
+
+// BatchAdd batch add entity, the entities are added in a transaction
+func (m *sqlite) BatchAdd(ctx context.Context, entities []datastore.Entity) error {
+	return m.Transaction(ctx, func(tx datastore.DataStore) error {
+		for _, entity := range entities {
+			if err := tx.Add(ctx, entity); err != nil {
+				return datastore.NewDBError(fmt.Errorf("save entities occur error, %w", err))
+			}
+		}
+		return nil
+	})
+}
+
+// Transaction runs fn in a SQL transaction
+func (m *sqlite) Transaction(ctx context.Context, fn func(tx datastore.DataStore) error) error {
+	if _, ok := m.conn.(*sql.Tx); ok {
+		return fn(m)
+	}
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return datastore.NewDBError(err)
+	}
+	if err := fn(&sqlite{db: m.db, conn: tx, tables: m.tables}); err != nil {
+		if err := tx.Rollback(); err != nil {
+			klog.Errorf("rollback the transaction failure %s", err.Error())
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return datastore.NewDBError(err)
+	}
+	return nil
+}
+
+// Watch polls the table because SQLite has no change notifications across the connections, the poll is not joined to the transaction
+func (m *sqlite) Watch(ctx context.Context, query datastore.Entity) (<-chan datastore.WatchEvent, error) {
+	return datastore.PollWatch(ctx, &sqlite{db: m.db, conn: m.db, tables: m.tables}, query, datastore.DefaultPollInterval)
+}
+
+// Get get data model
+func (m *sqlite) Get(ctx context.Context, entity datastore.Entity) error {
+	if err := checkEntity(entity); err != nil {
+		return err
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return err
+	}
+	var data string
+	query := fmt.Sprintf("SELECT `data` FROM %s WHERE `name` = ?", quoteIdentifier(tableName(ctx, entity)))
+	if err := m.conn.QueryRowContext(ctx, query, entity.PrimaryKey()).Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return datastore.ErrRecordNotExist
+		}
+		return datastore.NewDBError(err)
+	}
+	if err := json.Unmarshal([]byte(data), entity); err != nil {
+		return datastore.NewDBError(err)
+	}
+	return nil
+}
+
+// Put update data model
+func (m *sqlite) Put(ctx context.Context, entity datastore.Entity) error {
+	if err := checkEntity(entity); err != nil {
+		return err
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return err
+	}
+	now := time.Now()
+	entity.SetUpdateTime(now)
+	version := entity.GetResourceVersion()
+	if version == 0 {
+		// the entity without the version overwrites the stored one, it is compared with the version read just now
+		stored, err := m.storedResourceVersion(ctx, entity)
+		if err != nil {
+			return err
+		}
+		entity.SetResourceVersion(stored)
+	}
+	expected := entity.GetResourceVersion()
+	entity.SetResourceVersion(expected + 1)
+	data, err := json.Marshal(entity)
+	if err != nil {
+		entity.SetResourceVersion(version)
+		return datastore.ErrEntityInvalid
+	}
+	query := fmt.Sprintf("UPDATE %s SET `data` = ?, `resource_version` = ?, `update_time` = ?, `search_text` = ?, `expire_at` = ? WHERE `name` = ? AND `resource_version` = ?", quoteIdentifier(tableName(ctx, entity)))
+	res, err := m.conn.ExecContext(ctx, query, string(data), expected+1, now.UnixNano(), searchText(entity), expireAt(entity), entity.PrimaryKey(), expected)
+	if err != nil {
+		entity.SetResourceVersion(version)
+		if isDuplicate(err) {
+			return datastore.ErrRecordExist
+		}
+		return datastore.NewDBError(err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		entity.SetResourceVersion(version)
+		exist, err := m.IsExist(ctx, entity)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return datastore.ErrRecordNotExist
+		}
+		return datastore.ErrRecordConflict
+	}
+	return nil
+}
+
+// BatchPut update entities together, none of them is updated if any fails
+func (m *sqlite) BatchPut(ctx context.Context, entities []datastore.Entity) error {
+	return m.Transaction(ctx, func(tx datastore.DataStore) error {
+		for _, entity := range entities {
+			if err := tx.Put(ctx, entity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *sqlite) storedResourceVersion(ctx context.Context, entity datastore.Entity) (int64, error) {
+	var version int64
+	query := fmt.Sprintf("SELECT `resource_version` FROM %s WHERE `name` = ?", quoteIdentifier(tableName(ctx, entity)))
+	if err := m.conn.QueryRowContext(ctx, query, entity.PrimaryKey()).Scan(&version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, datastore.ErrRecordNotExist
+		}
+		return 0, datastore.NewDBError(err)
+	}
+	return version, nil
+}
+
+// IsExist determine whether data exists.
+func (m *sqlite) IsExist(ctx context.Context, entity datastore.Entity) (bool, error) {
+	if err := checkEntity(entity); err != nil {
+		return false, err
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return false, err
+	}
+	var exist int
+	query := fmt.Sprintf("SELECT 1 FROM %s WHERE `name` = ?", quoteIdentifier(tableName(ctx, entity)))
+	if err := m.conn.QueryRowContext(ctx, query, entity.PrimaryKey()).Scan(&exist); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, datastore.NewDBError(err)
+	}
+	return true, nil
+}
+
+// Delete delete data
+func (m *sqlite) Delete(ctx context.Context, entity datastore.Entity) error {
+	if err := checkEntity(entity); err != nil {
+		return err
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE `name` = ?", quoteIdentifier(tableName(ctx, entity)))
+	res, err := m.conn.ExecContext(ctx, query, entity.PrimaryKey())
+	if err != nil {
+		return datastore.NewDBError(err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return datastore.ErrRecordNotExist
+	}
+	return nil
+}
+
+// DeleteByFilter delete the entities matched by the query and the filter options
+func (m *sqlite) DeleteByFilter(ctx context.Context, entity datastore.Entity, filterOptions *datastore.FilterOptions) (int64, error) {
+	if entity.TableName() == "" {
+		return 0, datastore.ErrTableNameEmpty
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return 0, err
+	}
+	where, args := makeWhere(entity, filterOptions)
+	query := fmt.Sprintf("DELETE FROM %s%s", quoteIdentifier(tableName(ctx, entity)), where)
+	res, err := m.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, datastore.NewDBError(err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, datastore.NewDBError(err)
+	}
+	return affected, nil
+}
+
+// List list entity function
+func (m *sqlite) List(ctx context.Context, entity datastore.Entity, op *datastore.ListOptions) ([]datastore.Entity, error) {
+	if entity.TableName() == "" {
+		return nil, datastore.ErrTableNameEmpty
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return nil, err
+	}
+	var filterOptions *datastore.FilterOptions
+	if op != nil {
+		filterOptions = &op.FilterOptions
+	}
+	where, args := makeWhere(entity, filterOptions)
+	query := fmt.Sprintf("SELECT `data` FROM %s%s", quoteIdentifier(tableName(ctx, entity)), where)
+	if op != nil && len(op.SortBy) > 0 {
+		var orders []string
+		for _, sortOp := range op.SortBy {
+			order := "ASC"
+			if sortOp.Order == datastore.SortOrderDescending {
+				order = "DESC"
+			}
+			switch sortOp.Key {
+			case createTimeKey:
+				orders = append(orders, "`create_time` "+order)
+			case updateTimeKey:
+				orders = append(orders, "`update_time` "+order)
+			default:
+				orders = append(orders, fieldExpr(sortOp.Key)+" "+order)
+			}
+		}
+		query += " ORDER BY " + strings.Join(orders, ", ")
+	}
+	query, args = paginate(query, args, op)
+	return m.query(ctx, entity, query, args)
+}
+
+// paginate limits the rows of the page
+func paginate(query string, args []interface{}, op *datastore.ListOptions) (string, []interface{}) {
+	if op != nil && op.PageSize > 0 && op.Page > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, op.PageSize, op.PageSize*(op.Page-1))
+	}
+	return query, args
+}
+
+// query decodes the entities of the selected data
+func (m *sqlite) query(ctx context.Context, entity datastore.Entity, query string, args []interface{}) ([]datastore.Entity, error) {
+	rows, err := m.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			klog.Warningf("close sqlite rows failure %s", err.Error())
+		}
+	}()
+	var list []datastore.Entity
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, datastore.NewDBError(err)
+		}
+		item, err := datastore.NewEntity(entity)
+		if err != nil {
+			return nil, datastore.NewDBError(err)
+		}
+		if err := json.Unmarshal([]byte(data), item); err != nil {
+			return nil, datastore.NewDBError(fmt.Errorf("decode entity failure %w", err))
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, datastore.NewDBError(err)
+	}
+	return list, nil
+}
+
+// Count counts entities
+func (m *sqlite) Count(ctx context.Context, entity datastore.Entity, filterOptions *datastore.FilterOptions) (int64, error) {
+	if entity.TableName() == "" {
+		return 0, datastore.ErrTableNameEmpty
+	}
+	if err := m.ensureTable(ctx, entity); err != nil {
+		return 0, err
+	}
+	where, args := makeWhere(entity, filterOptions)
+	var count int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", quoteIdentifier(tableName(ctx, entity)), where)
+	if err := m.conn.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, datastore.NewDBError(err)
+	}
+	return count, nil
+}
+
+// makeWhere matches the indices of the entity and the filter options, the keys are the paths of the fields.
+// The values are compared as strings, the same as the labels of the kubeapi driver. The keys are the literals of the
+// expressions, so the expressions of the indexed fields use the indexes.
+func makeWhere(entity datastore.Entity, filterOptions *datastore.FilterOptions) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for k, v := range entity.Index() {
+		conditions = append(conditions, fieldExpr(k)+" = ?")
+		args = append(args, pkgUtils.ToString(v))
+	}
+	if filterOptions != nil {
+		filterConditions, filterArgs := makeFilterConditions(*filterOptions)
+		conditions = append(conditions, filterConditions...)
+		args = append(args, filterArgs...)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// makeFilterConditions converts the filter options to the conditions joined by AND, the missing fields are the empty strings
+func makeFilterConditions(filterOptions datastore.FilterOptions) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, queryOp := range filterOptions.Queries {
+		conditions = append(conditions, "INSTR("+fieldExpr(queryOp.Key)+", ?) > 0")
+		args = append(args, queryOp.Query)
+	}
+	for _, queryOp := range filterOptions.In {
+		if len(queryOp.Values) == 0 {
+			conditions = append(conditions, "0")
+			continue
+		}
+		conditions = append(conditions, fieldExpr(queryOp.Key)+" IN (?"+strings.Repeat(", ?", len(queryOp.Values)-1)+")")
+		for _, value := range queryOp.Values {
+			args = append(args, value)
+		}
+	}
+	for _, queryOp := range filterOptions.NotIn {
+		if len(queryOp.Values) == 0 {
+			continue
+		}
+		conditions = append(conditions, fieldExpr(queryOp.Key)+" NOT IN (?"+strings.Repeat(", ?", len(queryOp.Values)-1)+")")
+		for _, value := range queryOp.Values {
+			args = append(args, value)
+		}
+	}
+	for _, queryOp := range filterOptions.IsExist {
+		conditions = append(conditions, fieldExpr(queryOp.Key)+" != ''")
+	}
+	for _, queryOp := range filterOptions.IsNotExist {
+		conditions = append(conditions, fieldExpr(queryOp.Key)+" = ''")
+	}
+	if len(filterOptions.Or) > 0 {
+		var groups []string
+		for _, group := range filterOptions.Or {
+			groupConditions, groupArgs := makeFilterConditions(group)
+			if len(groupConditions) == 0 {
+				groupConditions = []string{"1"}
+			}
+			groups = append(groups, "("+strings.Join(groupConditions, " AND ")+")")
+			args = append(args, groupArgs...)
+		}
+		conditions = append(conditions, "("+strings.Join(groups, " OR ")+")")
+	}
+	return conditions, args
+}
+
+// tableName the table of the entity in the partition of the tenant of the context
+func tableName(ctx context.Context, entity datastore.Entity) string {
+	if tenant := datastore.TenantFromContext(ctx); tenant != "" {
+		return tenant + "__" + entity.TableName()
+	}
+	return entity.TableName()
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
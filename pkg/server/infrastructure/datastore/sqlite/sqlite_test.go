@@ -0,0 +1,384 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/datastoretest"
+)
+
+var sqliteDriver datastore.DataStore
+var testDir string
+var _ = BeforeSuite(func(done Done) {
+	By("bootstrapping sqlite test environment")
+	var err error
+	testDir, err = os.MkdirTemp("", "velaux-sqlite")
+	Expect(err).ToNot(HaveOccurred())
+
+	sqliteDriver, err = New(context.TODO(), datastore.Config{
+		URL:      filepath.Join(testDir, "data", "kubevela.db"),
+		Database: "kubevela",
+	})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(sqliteDriver).ToNot(BeNil())
+	By("create sqlite driver success")
+	close(done)
+}, 120)
+
+var _ = AfterSuite(func() {
+	Expect(os.RemoveAll(testDir)).Should(Succeed())
+})
+
+var _ = Describe("Test sqlite datastore driver", func() {
+	datastoretest.DescribeFilterConformance(func() datastore.DataStore { return sqliteDriver })
+	datastoretest.DescribeTenantConformance(func() datastore.DataStore { return sqliteDriver })
+	datastoretest.DescribeIndexConformance(func() datastore.DataStore { return sqliteDriver })
+	datastoretest.DescribeBatchConformance(func() datastore.DataStore { return sqliteDriver })
+	datastoretest.DescribeTransactionConformance(func() datastore.DataStore { return sqliteDriver })
+
+	It("Test add function", func() {
+		err := sqliteDriver.Add(context.TODO(), &model.Application{Name: "kubevela-app", Description: "default"})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = sqliteDriver.Add(context.TODO(), &model.Application{Name: "kubevela-app", Description: "default"})
+		equal := cmp.Equal(err, datastore.ErrRecordExist, cmpopts.EquateErrors())
+		Expect(equal).Should(BeTrue())
+	})
+
+	It("Test batch add function", func() {
+		var datas = []datastore.Entity{
+			&model.Application{Name: "kubevela-app-2", Description: "this is demo 2"},
+			&model.Application{Name: "kubevela-app-3", Description: "this is demo 3"},
+			&model.Application{Name: "kubevela-app-4", Project: "test-project", Description: "this is demo 4"},
+			&model.Workflow{Name: "kubevela-app-workflow", AppPrimaryKey: "kubevela-app-2", Description: "this is workflow"},
+			&model.ApplicationTrigger{Name: "kubevela-app-trigger", AppPrimaryKey: "kubevela-app-2", Token: "token-test", Description: "this is demo 4"},
+		}
+		err := sqliteDriver.BatchAdd(context.TODO(), datas)
+		Expect(err).ToNot(HaveOccurred())
+
+		var datas2 = []datastore.Entity{
+			&model.Application{Name: "can-delete", Description: "this is demo can-delete"},
+			&model.Application{Name: "kubevela-app-2", Description: "this is demo 2"},
+		}
+		err = sqliteDriver.BatchAdd(context.TODO(), datas2)
+		Expect(strings.Contains(err.Error(), "save entities occur error")).Should(BeTrue())
+		By("the added entities are rolled back")
+		exist, err := sqliteDriver.IsExist(context.TODO(), &model.Application{Name: "can-delete"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(exist).Should(BeFalse())
+	})
+
+	It("Test get function", func() {
+		app := &model.Application{Name: "kubevela-app"}
+		err := sqliteDriver.Get(context.TODO(), app)
+		Expect(err).Should(BeNil())
+		Expect(app.Description).Should(Equal("default"))
+		Expect(app.CreateTime.IsZero()).Should(BeFalse())
+
+		workflow := &model.Workflow{Name: "kubevela-app-workflow", AppPrimaryKey: "kubevela-app-2"}
+		err = sqliteDriver.Get(context.TODO(), workflow)
+		Expect(err).Should(BeNil())
+		Expect(workflow.Description).Should(Equal("this is workflow"))
+
+		err = sqliteDriver.Get(context.TODO(), &model.Application{Name: "not-exist"})
+		equal := cmp.Equal(err, datastore.ErrRecordNotExist, cmpopts.EquateErrors())
+		Expect(equal).Should(BeTrue())
+	})
+
+	It("Test put function", func() {
+		err := sqliteDriver.Put(context.TODO(), &model.Application{Name: "kubevela-app", Description: "this is demo"})
+		Expect(err).ToNot(HaveOccurred())
+		app := &model.Application{Name: "kubevela-app"}
+		Expect(sqliteDriver.Get(context.TODO(), app)).Should(Succeed())
+		Expect(app.Description).Should(Equal("this is demo"))
+
+		err = sqliteDriver.Put(context.TODO(), &model.Application{Name: "not-exist"})
+		equal := cmp.Equal(err, datastore.ErrRecordNotExist, cmpopts.EquateErrors())
+		Expect(equal).Should(BeTrue())
+
+		By("the stale entity could not be put")
+		stale := &model.Application{Name: "kubevela-app"}
+		Expect(sqliteDriver.Get(context.TODO(), stale)).Should(Succeed())
+		fresh := &model.Application{Name: "kubevela-app"}
+		Expect(sqliteDriver.Get(context.TODO(), fresh)).Should(Succeed())
+		fresh.Description = "updated"
+		Expect(sqliteDriver.Put(context.TODO(), fresh)).Should(Succeed())
+		Expect(fresh.ResourceVersion).Should(Equal(stale.ResourceVersion + 1))
+		stale.Description = "stale"
+		Expect(sqliteDriver.Put(context.TODO(), stale)).Should(Equal(datastore.ErrRecordConflict))
+	})
+
+	It("Test list function", func() {
+		var app model.Application
+		list, err := sqliteDriver.List(context.TODO(), &app, &datastore.ListOptions{Page: -1})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(4))
+
+		list, err = sqliteDriver.List(context.TODO(), &app, &datastore.ListOptions{Page: 2, PageSize: 3})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(1))
+
+		list, err = sqliteDriver.List(context.TODO(), &app, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(4))
+
+		var workflow = model.Workflow{
+			AppPrimaryKey: "kubevela-app-2",
+		}
+		list, err = sqliteDriver.List(context.TODO(), &workflow, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(1))
+
+		list, err = sqliteDriver.List(context.TODO(), &app, &datastore.ListOptions{FilterOptions: datastore.FilterOptions{In: []datastore.InQueryOption{
+			{
+				Key:    "name",
+				Values: []string{"kubevela-app-3", "kubevela-app-2"},
+			},
+		}}})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(2))
+
+		list, err = sqliteDriver.List(context.TODO(), &app, &datastore.ListOptions{FilterOptions: datastore.FilterOptions{IsNotExist: []datastore.IsNotExistQueryOption{
+			{
+				Key: "project",
+			},
+		}}})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(3))
+	})
+
+	It("Test list clusters with sort and fuzzy query", func() {
+		for _, name := range []string{"first", "second", "third"} {
+			Expect(sqliteDriver.Add(context.TODO(), &model.Cluster{Name: name})).Should(Succeed())
+			time.Sleep(time.Millisecond * 100)
+		}
+		entities, err := sqliteDriver.List(context.TODO(), &model.Cluster{}, &datastore.ListOptions{
+			SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}}})
+		Expect(err).Should(Succeed())
+		Expect(len(entities)).Should(Equal(3))
+		for i, name := range []string{"first", "second", "third"} {
+			Expect(entities[i].(*model.Cluster).Name).Should(Equal(name))
+		}
+		entities, err = sqliteDriver.List(context.TODO(), &model.Cluster{}, &datastore.ListOptions{
+			SortBy:   []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+			Page:     2,
+			PageSize: 2,
+		})
+		Expect(err).Should(Succeed())
+		Expect(len(entities)).Should(Equal(1))
+		Expect(entities[0].(*model.Cluster).Name).Should(Equal("first"))
+
+		entities, err = sqliteDriver.List(context.TODO(), &model.Cluster{}, &datastore.ListOptions{
+			SortBy: []datastore.SortOption{{Key: "name", Order: datastore.SortOrderDescending}},
+			FilterOptions: datastore.FilterOptions{
+				Queries: []datastore.FuzzyQueryOption{{Key: "name", Query: "ir"}},
+			},
+		})
+		Expect(err).Should(Succeed())
+		Expect(len(entities)).Should(Equal(2))
+		for i, name := range []string{"third", "first"} {
+			Expect(entities[i].(*model.Cluster).Name).Should(Equal(name))
+		}
+
+		By("the wildcards of the fuzzy query are matched literally")
+		count, err := sqliteDriver.Count(context.TODO(), &model.Cluster{}, &datastore.FilterOptions{
+			Queries: []datastore.FuzzyQueryOption{{Key: "name", Query: "%"}},
+		})
+		Expect(err).Should(Succeed())
+		Expect(count).Should(Equal(int64(0)))
+	})
+
+	It("Test list by the nested index", func() {
+		Expect(sqliteDriver.Add(context.TODO(), &model.Permission{Name: "user-perm", Principal: &model.Principal{Type: "User"}})).Should(Succeed())
+		Expect(sqliteDriver.Add(context.TODO(), &model.Permission{Name: "role-perm"})).Should(Succeed())
+		count, err := sqliteDriver.Count(context.TODO(), &model.Permission{Principal: &model.Principal{Type: "User"}}, nil)
+		Expect(err).Should(Succeed())
+		Expect(count).Should(Equal(int64(1)))
+	})
+
+	It("Test count function", func() {
+		var app model.Application
+		count, err := sqliteDriver.Count(context.TODO(), &app, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).Should(Equal(int64(4)))
+
+		count, err = sqliteDriver.Count(context.TODO(), &app, &datastore.FilterOptions{In: []datastore.InQueryOption{
+			{
+				Key:    "name",
+				Values: []string{"kubevela-app-3", "kubevela-app-2"},
+			},
+		}})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).Should(Equal(int64(2)))
+
+		app.Name = "kubevela-app-3"
+		count, err = sqliteDriver.Count(context.TODO(), &app, &datastore.FilterOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(count).Should(Equal(int64(1)))
+	})
+
+	It("Test transaction function", func() {
+		ctx := context.TODO()
+		Expect(sqliteDriver.Add(ctx, &model.Target{Name: "tx-target", Alias: "before"})).Should(Succeed())
+		err := sqliteDriver.Transaction(ctx, func(tx datastore.DataStore) error {
+			if err := tx.Add(ctx, &model.Target{Name: "tx-target-2"}); err != nil {
+				return err
+			}
+			if err := tx.Put(ctx, &model.Target{Name: "tx-target", Alias: "after"}); err != nil {
+				return err
+			}
+			return tx.Add(ctx, &model.Target{Name: "tx-target"})
+		})
+		equal := cmp.Equal(err, datastore.ErrRecordExist, cmpopts.EquateErrors())
+		Expect(equal).Should(BeTrue())
+		By("the writes are rolled back")
+		exist, err := sqliteDriver.IsExist(ctx, &model.Target{Name: "tx-target-2"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(exist).Should(BeFalse())
+		target := &model.Target{Name: "tx-target"}
+		Expect(sqliteDriver.Get(ctx, target)).Should(Succeed())
+		Expect(target.Alias).Should(Equal("before"))
+
+		Expect(sqliteDriver.Transaction(ctx, func(tx datastore.DataStore) error {
+			return tx.Delete(ctx, &model.Target{Name: "tx-target"})
+		})).Should(Succeed())
+		exist, err = sqliteDriver.IsExist(ctx, &model.Target{Name: "tx-target"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(exist).Should(BeFalse())
+	})
+
+	It("Test delete function", func() {
+		var app model.Application
+		app.Name = "kubevela-app-4"
+		err := sqliteDriver.Delete(context.TODO(), &app)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		err = sqliteDriver.Delete(context.TODO(), &app)
+		equal := cmp.Equal(err, datastore.ErrRecordNotExist, cmpopts.EquateErrors())
+		Expect(equal).Should(BeTrue())
+	})
+
+	It("Test search function", func() {
+		ctx := context.TODO()
+		Expect(sqliteDriver.Add(ctx, &model.Application{Name: "search-payment-api", Alias: "Payment API", Project: "search-project", Description: "handles the checkout payments"})).Should(Succeed())
+		Expect(sqliteDriver.Add(ctx, &model.Application{Name: "search-payroll", Project: "search-project", Description: "the monthly payroll"})).Should(Succeed())
+
+		list, err := sqliteDriver.Search(ctx, &model.Application{}, "Payment", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(1))
+		Expect(list[0].(*model.Application).Name).Should(Equal("search-payment-api"))
+
+		By("the keywords match the word prefixes")
+		list, err = sqliteDriver.Search(ctx, &model.Application{}, "pay", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(2))
+
+		By("all keywords are required")
+		list, err = sqliteDriver.Search(ctx, &model.Application{}, "payroll checkout", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(list).Should(BeEmpty())
+
+		By("the entities are filtered by the index of the query")
+		list, err = sqliteDriver.Search(ctx, &model.Application{Project: "other-project"}, "payroll", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(list).Should(BeEmpty())
+
+		By("the updated fields are searchable")
+		Expect(sqliteDriver.Put(ctx, &model.Application{Name: "search-payroll", Project: "search-project", Description: "the monthly salaries"})).Should(Succeed())
+		list, err = sqliteDriver.Search(ctx, &model.Application{}, "salaries", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(1))
+
+		_, err = sqliteDriver.Search(ctx, &model.Workflow{}, "payroll", nil)
+		Expect(err).Should(Equal(datastore.ErrEntityInvalid))
+		Expect(sqliteDriver.Delete(ctx, &model.Application{Name: "search-payment-api"})).Should(Succeed())
+		Expect(sqliteDriver.Delete(ctx, &model.Application{Name: "search-payroll"})).Should(Succeed())
+	})
+
+	It("Test watch function", func() {
+		interval := datastore.DefaultPollInterval
+		datastore.DefaultPollInterval = 100 * time.Millisecond
+		defer func() { datastore.DefaultPollInterval = interval }()
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		Expect(sqliteDriver.Add(ctx, &model.Application{Name: "watch-existing", Project: "watch-project"})).Should(Succeed())
+		events, err := sqliteDriver.Watch(ctx, &model.Application{Project: "watch-project"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		By("the entities out of the index of the query are not sent")
+		Expect(sqliteDriver.Add(ctx, &model.Application{Name: "watch-other", Project: "other-project"})).Should(Succeed())
+		Expect(sqliteDriver.Add(ctx, &model.Application{Name: "watch-app", Project: "watch-project"})).Should(Succeed())
+		var event datastore.WatchEvent
+		Eventually(events, time.Minute).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(datastore.WatchEventAdded))
+		Expect(event.Entity.PrimaryKey()).Should(Equal("watch-app"))
+
+		Expect(sqliteDriver.Put(ctx, &model.Application{Name: "watch-app", Project: "watch-project", Alias: "Watch App"})).Should(Succeed())
+		Eventually(events, time.Minute).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(datastore.WatchEventModified))
+		Expect(event.Entity.(*model.Application).Alias).Should(Equal("Watch App"))
+
+		Expect(sqliteDriver.Delete(ctx, &model.Application{Name: "watch-app"})).Should(Succeed())
+		Eventually(events, time.Minute).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(datastore.WatchEventDeleted))
+		Expect(event.Entity.PrimaryKey()).Should(Equal("watch-app"))
+
+		By("the channel is closed after the context is done")
+		cancel()
+		Eventually(events, time.Minute).Should(BeClosed())
+		Expect(sqliteDriver.Delete(context.TODO(), &model.Application{Name: "watch-existing"})).Should(Succeed())
+		Expect(sqliteDriver.Delete(context.TODO(), &model.Application{Name: "watch-other"})).Should(Succeed())
+	})
+
+	It("Test expire function", func() {
+		ctx := context.TODO()
+		expired := &model.Session{ID: "expired-session", Username: "expire-user"}
+		expired.SetExpireAt(time.Now().Add(-time.Minute))
+		Expect(sqliteDriver.Add(ctx, expired)).Should(Succeed())
+		living := &model.Session{ID: "living-session", Username: "expire-user"}
+		living.SetExpireAt(time.Now().Add(time.Hour))
+		Expect(sqliteDriver.Add(ctx, living)).Should(Succeed())
+		Expect(sqliteDriver.Add(ctx, &model.Session{ID: "forever-session", Username: "expire-user"})).Should(Succeed())
+
+		deleted, err := sqliteDriver.(*sqlite).deleteExpired(ctx)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).Should(Equal(1))
+		list, err := sqliteDriver.List(ctx, &model.Session{Username: "expire-user"}, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(len(list)).Should(Equal(2))
+
+		By("the extended expiry is honored")
+		living.SetExpireAt(time.Now().Add(-time.Minute))
+		Expect(sqliteDriver.Put(ctx, living)).Should(Succeed())
+		deleted, err = sqliteDriver.(*sqlite).deleteExpired(ctx)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).Should(Equal(1))
+		Expect(sqliteDriver.Delete(ctx, &model.Session{ID: "forever-session"})).Should(Succeed())
+	})
+})
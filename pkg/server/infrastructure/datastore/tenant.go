@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"context"
+	"regexp"
+)
+
+type tenantKey struct{}
+
+// tenantPattern the tenants are the parts of the namespaces, the table names and the database names, so they are DNS labels
+var tenantPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,30}[a-z0-9])?$`)
+
+// ValidTenant returns true if the tenant could be used to partition the datastore
+func ValidTenant(tenant string) bool {
+	return tenantPattern.MatchString(tenant)
+}
+
+// WithTenant returns the context whose operations are isolated in the partition of the tenant, every driver stores
+// the entities of a tenant apart from the others: the kubeapi driver in its namespaces, MongoDB in its database and
+// MySQL in its tables. The context without a tenant uses the default partition.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant of the context, it is empty for the default partition
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// TenantKey prefixes the key by the tenant of the context, the caches use it so the tenants never share the cached entities
+func TenantKey(ctx context.Context, key string) string {
+	if tenant := TenantFromContext(ctx); tenant != "" {
+		return tenant + "/" + key
+	}
+	return key
+}
+
+// Partitions returns the contexts of the default partition and the partitions of the tenants
+func Partitions(ctx context.Context, tenants []string) []context.Context {
+	partitions := []context.Context{ctx}
+	for _, tenant := range tenants {
+		partitions = append(partitions, WithTenant(ctx, tenant))
+	}
+	return partitions
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"k8s.io/klog/v2"
+)
+
+type transactionKey struct{}
+
+// InTransaction runs fn in a transaction of the store, the context given to fn carries the transaction, so the
+// services called by fn join it if their store is wrapped by JoinContextTransaction. The writes to the clusters and
+// the other systems by fn are not rolled back with the transaction.
+func InTransaction(ctx context.Context, store DataStore, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(transactionKey{}).(DataStore); ok {
+		return fn(ctx)
+	}
+	return store.Transaction(ctx, func(tx DataStore) error {
+		return fn(context.WithValue(ctx, transactionKey{}, tx))
+	})
+}
+
+// JoinContextTransaction returns the datastore whose operations join the transaction carried by the context,
+// the operations of the contexts without a transaction use the store.
+func JoinContextTransaction(store DataStore) DataStore {
+	return &contextTransactionStore{store: store}
+}
+
+type contextTransactionStore struct {
+	store DataStore
+}
+
+func (c *contextTransactionStore) current(ctx context.Context) DataStore {
+	if tx, ok := ctx.Value(transactionKey{}).(DataStore); ok {
+		return tx
+	}
+	return c.store
+}
+
+func (c *contextTransactionStore) Add(ctx context.Context, entity Entity) error {
+	return c.current(ctx).Add(ctx, entity)
+}
+
+func (c *contextTransactionStore) BatchAdd(ctx context.Context, entities []Entity) error {
+	return c.current(ctx).BatchAdd(ctx, entities)
+}
+
+func (c *contextTransactionStore) Put(ctx context.Context, entity Entity) error {
+	return c.current(ctx).Put(ctx, entity)
+}
+
+func (c *contextTransactionStore) BatchPut(ctx context.Context, entities []Entity) error {
+	return c.current(ctx).BatchPut(ctx, entities)
+}
+
+func (c *contextTransactionStore) Delete(ctx context.Context, entity Entity) error {
+	return c.current(ctx).Delete(ctx, entity)
+}
+
+func (c *contextTransactionStore) DeleteByFilter(ctx context.Context, query Entity, options *FilterOptions) (int64, error) {
+	return c.current(ctx).DeleteByFilter(ctx, query, options)
+}
+
+func (c *contextTransactionStore) Get(ctx context.Context, entity Entity) error {
+	return c.current(ctx).Get(ctx, entity)
+}
+
+func (c *contextTransactionStore) List(ctx context.Context, query Entity, options *ListOptions) ([]Entity, error) {
+	return c.current(ctx).List(ctx, query, options)
+}
+
+func (c *contextTransactionStore) Count(ctx context.Context, entity Entity, options *FilterOptions) (int64, error) {
+	return c.current(ctx).Count(ctx, entity, options)
+}
+
+func (c *contextTransactionStore) Search(ctx context.Context, query Entity, text string, options *ListOptions) ([]Entity, error) {
+	return c.current(ctx).Search(ctx, query, text, options)
+}
+
+func (c *contextTransactionStore) IsExist(ctx context.Context, entity Entity) (bool, error) {
+	return c.current(ctx).IsExist(ctx, entity)
+}
+
+func (c *contextTransactionStore) Transaction(ctx context.Context, fn func(tx DataStore) error) error {
+	return c.current(ctx).Transaction(ctx, fn)
+}
+
+// Watch never joins the transaction, the changes are sent after they are committed
+func (c *contextTransactionStore) Watch(ctx context.Context, query Entity) (<-chan WatchEvent, error) {
+	return c.store.Watch(ctx, query)
+}
+
+// CompensatingTransaction is the transaction of the drivers without the native transactions. The writes are applied
+// immediately, and the compensating writes are applied in the reverse order if fn returns an error. The other writers
+// could see the writes before they are rolled back, and a failed compensation is only logged.
+func CompensatingTransaction(ctx context.Context, store DataStore, fn func(tx DataStore) error) error {
+	if _, ok := store.(*compensatingStore); ok {
+		return fn(store)
+	}
+	tx := &compensatingStore{DataStore: store}
+	if err := fn(tx); err != nil {
+		tx.rollback(ctx)
+		return err
+	}
+	return nil
+}
+
+type compensatingStore struct {
+	DataStore
+	undo []func(ctx context.Context) error
+}
+
+func (c *compensatingStore) rollback(ctx context.Context) {
+	for i := len(c.undo) - 1; i >= 0; i-- {
+		if err := c.undo[i](ctx); err != nil && !errors.Is(err, ErrRecordNotExist) && !errors.Is(err, ErrRecordExist) {
+			klog.Errorf("rollback the transaction failure %s", err.Error())
+		}
+	}
+	c.undo = nil
+}
+
+// snapshot copies the stored entity, nil is returned if it does not exist
+func (c *compensatingStore) snapshot(ctx context.Context, entity Entity) (Entity, error) {
+	current, err := cloneEntity(entity)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.DataStore.Get(ctx, current); err != nil {
+		if errors.Is(err, ErrRecordNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return current, nil
+}
+
+func (c *compensatingStore) Add(ctx context.Context, entity Entity) error {
+	if err := c.DataStore.Add(ctx, entity); err != nil {
+		return err
+	}
+	return c.undoAdd(entity)
+}
+
+func (c *compensatingStore) undoAdd(entity Entity) error {
+	added, err := cloneEntity(entity)
+	if err != nil {
+		return err
+	}
+	c.undo = append(c.undo, func(ctx context.Context) error {
+		return c.DataStore.Delete(ctx, added)
+	})
+	return nil
+}
+
+func (c *compensatingStore) BatchAdd(ctx context.Context, entities []Entity) error {
+	if err := c.DataStore.BatchAdd(ctx, entities); err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		if err := c.undoAdd(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *compensatingStore) Put(ctx context.Context, entity Entity) error {
+	previous, err := c.snapshot(ctx, entity)
+	if err != nil {
+		return err
+	}
+	if err := c.DataStore.Put(ctx, entity); err != nil {
+		return err
+	}
+	if previous != nil {
+		// the previous entity is restored only if no one else updates it after this write
+		previous.SetResourceVersion(entity.GetResourceVersion())
+		c.undo = append(c.undo, func(ctx context.Context) error {
+			return c.DataStore.Put(ctx, previous)
+		})
+	}
+	return nil
+}
+
+func (c *compensatingStore) Delete(ctx context.Context, entity Entity) error {
+	previous, err := c.snapshot(ctx, entity)
+	if err != nil {
+		return err
+	}
+	if err := c.DataStore.Delete(ctx, entity); err != nil {
+		return err
+	}
+	if previous != nil {
+		c.undo = append(c.undo, func(ctx context.Context) error {
+			return c.DataStore.Add(ctx, previous)
+		})
+	}
+	return nil
+}
+
+func (c *compensatingStore) BatchPut(ctx context.Context, entities []Entity) error {
+	for _, entity := range entities {
+		if err := c.Put(ctx, entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteByFilter deletes the matched entities one by one, so every deleted entity could be restored
+func (c *compensatingStore) DeleteByFilter(ctx context.Context, query Entity, options *FilterOptions) (int64, error) {
+	listOptions := &ListOptions{}
+	if options != nil {
+		listOptions.FilterOptions = *options
+	}
+	entities, err := c.DataStore.List(ctx, query, listOptions)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, entity := range entities {
+		if err := c.Delete(ctx, entity); err != nil {
+			if errors.Is(err, ErrRecordNotExist) {
+				continue
+			}
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (c *compensatingStore) Transaction(_ context.Context, fn func(tx DataStore) error) error {
+	return fn(c)
+}
+
+// cloneEntity deep copies the entity by its JSON encoding, the same encoding as the datastores
+func cloneEntity(entity Entity) (Entity, error) {
+	clone, err := NewEntity(entity)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, NewDBError(fmt.Errorf("copy entity failure %w", err))
+	}
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, NewDBError(fmt.Errorf("copy entity failure %w", err))
+	}
+	return clone, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datastore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// WatchEventType the type of the change of an entity
+type WatchEventType string
+
+const (
+	// WatchEventAdded the entity is added
+	WatchEventAdded WatchEventType = "ADDED"
+	// WatchEventModified the entity is updated
+	WatchEventModified WatchEventType = "MODIFIED"
+	// WatchEventDeleted the entity is deleted, the entity of the event is the last known state of it
+	WatchEventDeleted WatchEventType = "DELETED"
+)
+
+// WatchBufferSize the buffer of the watch channels, the watchers are blocked if the receiver is slower than the writes
+const WatchBufferSize = 100
+
+// DefaultPollInterval the interval of listing the entities by the drivers without the change notifications
+var DefaultPollInterval = 5 * time.Second
+
+// WatchEvent is a change of an entity
+type WatchEvent struct {
+	Type   WatchEventType
+	Entity Entity
+}
+
+// SendWatchEvent sends the event unless the context is done, false is returned if the watch is stopped
+func SendWatchEvent(ctx context.Context, ch chan<- WatchEvent, event WatchEvent) bool {
+	select {
+	case ch <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// PollWatch is the watch of the drivers without the change notifications. It lists the entities matched by the index of
+// the query every interval and sends the differences from the last list, so the changes between two lists are merged and
+// an entity added and deleted in between is not seen.
+func PollWatch(ctx context.Context, store DataStore, query Entity, interval time.Duration) (<-chan WatchEvent, error) {
+	if query.TableName() == "" {
+		return nil, ErrTableNameEmpty
+	}
+	known, err := pollSnapshot(ctx, store, query)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan WatchEvent, WatchBufferSize)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current, err := pollSnapshot(ctx, store, query)
+			if err != nil {
+				if ctx.Err() == nil {
+					klog.Warningf("poll the changes of %s failure %s", query.TableName(), err.Error())
+				}
+				continue
+			}
+			for _, event := range diffSnapshots(known, current) {
+				if !SendWatchEvent(ctx, ch, event) {
+					return
+				}
+			}
+			known = current
+		}
+	}()
+	return ch, nil
+}
+
+// polledEntity the entity and its JSON, the JSON is compared to find the updates
+type polledEntity struct {
+	entity Entity
+	data   []byte
+}
+
+func pollSnapshot(ctx context.Context, store DataStore, query Entity) (map[string]polledEntity, error) {
+	entities, err := store.List(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(map[string]polledEntity, len(entities))
+	for _, entity := range entities {
+		data, err := json.Marshal(entity)
+		if err != nil {
+			return nil, err
+		}
+		snapshot[entity.PrimaryKey()] = polledEntity{entity: entity, data: data}
+	}
+	return snapshot, nil
+}
+
+func diffSnapshots(previous, current map[string]polledEntity) []WatchEvent {
+	var events []WatchEvent
+	for key, item := range current {
+		last, ok := previous[key]
+		switch {
+		case !ok:
+			events = append(events, WatchEvent{Type: WatchEventAdded, Entity: item.entity})
+		case !bytes.Equal(last.data, item.data):
+			events = append(events, WatchEvent{Type: WatchEventModified, Entity: item.entity})
+		}
+	}
+	for key, item := range previous {
+		if _, ok := current[key]; !ok {
+			events = append(events, WatchEvent{Type: WatchEventDeleted, Entity: item.entity})
+		}
+	}
+	return events
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// ErrNotConfigured means the SMTP server is not configured
+var ErrNotConfigured = errors.New("the email sender is not configured")
+
+// Config the SMTP server to send the emails
+type Config struct {
+	// Address the address of the SMTP server, in the format of host:port
+	Address  string
+	Username string
+	Password string
+	// From the address of the sender
+	From string
+}
+
+// Sender sends the emails to the users
+type Sender interface {
+	// Enabled returns false if the emails could not be sent
+	Enabled() bool
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// New creates the email sender, the emails are not sent if the SMTP server is not configured
+func New(cfg Config) Sender {
+	if cfg.Address == "" {
+		return disabledSender{}
+	}
+	return &smtpSender{cfg: cfg}
+}
+
+type disabledSender struct{}
+
+func (disabledSender) Enabled() bool {
+	return false
+}
+
+func (disabledSender) Send(_ context.Context, _, _, _ string) error {
+	return ErrNotConfigured
+}
+
+type smtpSender struct {
+	cfg Config
+}
+
+func (s *smtpSender) Enabled() bool {
+	return true
+}
+
+// Send sends the plain text email, the auth is skipped if the username is empty
+func (s *smtpSender) Send(_ context.Context, to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("invalid email header")
+	}
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		host, _, err := net.SplitHostPort(s.cfg.Address)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address %s: %w", s.cfg.Address, err)
+		}
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)
+	}
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(body)
+	return smtp.SendMail(s.cfg.Address, auth, s.cfg.From, []string{to}, []byte(msg.String()))
+}
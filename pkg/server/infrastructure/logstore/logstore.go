@@ -0,0 +1,240 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultThreshold the default size of the logs to be stored in the object storage
+const DefaultThreshold = 64 * 1024
+
+// ErrNotConfigured means the object storage is not configured
+var ErrNotConfigured = errors.New("the log object storage is not configured")
+
+// ErrObjectNotExist means the object does not exist in the bucket
+var ErrObjectNotExist = errors.New("the log object does not exist")
+
+// Config the S3 compatible object storage, such as AWS S3 and MinIO, to store the large logs
+type Config struct {
+	// Endpoint the URL of the object storage, such as https://s3.us-east-1.amazonaws.com or http://minio:9000
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	// Threshold the logs larger than the threshold(in bytes) are stored in the object storage
+	Threshold int
+}
+
+// Store stores the logs as the objects
+type Store interface {
+	// Enabled returns false if the logs could not be stored
+	Enabled() bool
+	// Threshold returns the size of the logs to be stored in the object storage
+	Threshold() int
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the log store, the logs are not stored if the endpoint is not configured
+func New(cfg Config) Store {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = DefaultThreshold
+	}
+	if cfg.Endpoint == "" {
+		return disabledStore{threshold: cfg.Threshold}
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	return &s3Store{cfg: cfg, client: &http.Client{Timeout: time.Minute}}
+}
+
+type disabledStore struct {
+	threshold int
+}
+
+func (d disabledStore) Enabled() bool {
+	return false
+}
+
+func (d disabledStore) Threshold() int {
+	return d.threshold
+}
+
+func (disabledStore) Put(_ context.Context, _ string, _ []byte) error {
+	return ErrNotConfigured
+}
+
+func (disabledStore) Get(_ context.Context, _ string) ([]byte, error) {
+	return nil, ErrNotConfigured
+}
+
+func (disabledStore) Delete(_ context.Context, _ string) error {
+	return ErrNotConfigured
+}
+
+// s3Store accesses the bucket with the path-style URLs, which are supported by both AWS S3 and MinIO
+type s3Store struct {
+	cfg    Config
+	client *http.Client
+	// now is replaced in the tests
+	now func() time.Time
+}
+
+func (s *s3Store) Enabled() bool {
+	return true
+}
+
+func (s *s3Store) Threshold() int {
+	return s.cfg.Threshold
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrObjectNotExist
+	default:
+		return nil, responseError(resp)
+	}
+}
+
+// Delete deletes the object, it succeeds if the object does not exist
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return responseError(resp)
+	}
+	return nil
+}
+
+func (s *s3Store) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	path := "/" + uriEncode(s.cfg.Bucket, false) + "/" + uriEncode(key, true)
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.Endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	s.sign(req, path, body, now().UTC())
+	return s.client.Do(req)
+}
+
+// sign signs the request with the AWS signature version 4
+func (s *s3Store) sign(req *http.Request, path string, body []byte, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		"",
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.cfg.Region)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+func responseError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("the object storage responds %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// uriEncode encodes all the characters except the unreserved ones, the slashes are kept if keepSlash is true
+func uriEncode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
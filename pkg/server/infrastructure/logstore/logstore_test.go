@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logstore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	auth    []string
+}
+
+func (f *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	key := r.URL.EscapedPath()
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+	case http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	bucket := &fakeBucket{objects: map[string][]byte{}}
+	server := httptest.NewServer(bucket)
+	defer server.Close()
+
+	store := New(Config{Endpoint: server.URL + "/", Bucket: "logs", AccessKey: "ak", SecretKey: "sk"})
+	assert.True(t, store.Enabled())
+	assert.Equal(t, DefaultThreshold, store.Threshold())
+	store.(*s3Store).now = func() time.Time {
+		return time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	ctx := context.Background()
+	assert.NoError(t, store.Put(ctx, "pipeline/default/run 1/step.log", []byte("hello")))
+	_, exist := bucket.objects["/logs/pipeline/default/run%201/step.log"]
+	assert.True(t, exist)
+	assert.True(t, strings.HasPrefix(bucket.auth[0], "AWS4-HMAC-SHA256 Credential=ak/20230501/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="))
+
+	data, err := store.Get(ctx, "pipeline/default/run 1/step.log")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	assert.NoError(t, store.Delete(ctx, "pipeline/default/run 1/step.log"))
+	_, err = store.Get(ctx, "pipeline/default/run 1/step.log")
+	assert.ErrorIs(t, err, ErrObjectNotExist)
+}
+
+func TestDisabledStore(t *testing.T) {
+	store := New(Config{Threshold: 10})
+	assert.False(t, store.Enabled())
+	assert.Equal(t, 10, store.Threshold())
+	assert.ErrorIs(t, store.Put(context.Background(), "key", nil), ErrNotConfigured)
+}
+
+func TestURIEncode(t *testing.T) {
+	assert.Equal(t, "a/b%20c~%2B", uriEncode("a/b c~+", true))
+	assert.Equal(t, "a%2Fb", uriEncode("a/b", false))
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package searchindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultIndexPrefix the default prefix of the indices
+const DefaultIndexPrefix = "velaux"
+
+// ErrNotConfigured means the search engine is not configured
+var ErrNotConfigured = errors.New("the search index is not configured")
+
+// Config the OpenSearch or Elasticsearch cluster to index the audits and the logs
+type Config struct {
+	// Endpoint the URL of the cluster, such as https://opensearch:9200
+	Endpoint string
+	Username string
+	Password string
+	// IndexPrefix the indices are named as <prefix>-<kind>-<project>
+	IndexPrefix string
+}
+
+// Document is a document to be indexed, the documents with the same ID are replaced
+type Document struct {
+	Index string
+	ID    string
+	Body  interface{}
+}
+
+// Query searches the documents of an index
+type Query struct {
+	// Text the query string in the simple query string syntax, all documents are matched if it is empty
+	Text string
+	// Terms only the documents with one of the values of the keyword fields are matched
+	Terms map[string][]string
+	// SortField the documents are sorted by the field in the descending order
+	SortField string
+	From      int
+	Size      int
+}
+
+// Hit a matched document
+type Hit struct {
+	ID     string          `json:"_id"`
+	Source json.RawMessage `json:"_source"`
+}
+
+// Result the matched documents
+type Result struct {
+	Total int64
+	Hits  []Hit
+}
+
+// Indexer indexes and searches the documents
+type Indexer interface {
+	// Enabled returns false if the documents could not be indexed
+	Enabled() bool
+	// IndexName returns the index of the kind of the documents in the project, each project has its own indices
+	IndexName(kind, project string) string
+	Bulk(ctx context.Context, docs []Document) error
+	// Search returns the empty result if the index does not exist
+	Search(ctx context.Context, index string, query Query) (*Result, error)
+}
+
+// New creates the indexer, nothing is indexed if the endpoint is not configured
+func New(cfg Config) Indexer {
+	if cfg.IndexPrefix == "" {
+		cfg.IndexPrefix = DefaultIndexPrefix
+	}
+	if cfg.Endpoint == "" {
+		return disabledIndexer{prefix: cfg.IndexPrefix}
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	return &openSearchIndexer{cfg: cfg, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func indexName(prefix, kind, project string) string {
+	return strings.ToLower(prefix + "-" + kind + "-" + project)
+}
+
+type disabledIndexer struct {
+	prefix string
+}
+
+func (disabledIndexer) Enabled() bool {
+	return false
+}
+
+func (d disabledIndexer) IndexName(kind, project string) string {
+	return indexName(d.prefix, kind, project)
+}
+
+func (disabledIndexer) Bulk(_ context.Context, _ []Document) error {
+	return ErrNotConfigured
+}
+
+func (disabledIndexer) Search(_ context.Context, _ string, _ Query) (*Result, error) {
+	return nil, ErrNotConfigured
+}
+
+// openSearchIndexer uses the REST APIs shared by OpenSearch and Elasticsearch
+type openSearchIndexer struct {
+	cfg    Config
+	client *http.Client
+}
+
+func (o *openSearchIndexer) Enabled() bool {
+	return true
+}
+
+func (o *openSearchIndexer) IndexName(kind, project string) string {
+	return indexName(o.cfg.IndexPrefix, kind, project)
+}
+
+// Bulk indexes the documents by the bulk API, the documents are replaced by the IDs so the retries are safe
+func (o *openSearchIndexer) Bulk(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	for _, doc := range docs {
+		action := map[string]map[string]string{"index": {"_index": doc.Index, "_id": doc.ID}}
+		if err := json.NewEncoder(&body).Encode(action); err != nil {
+			return err
+		}
+		if err := json.NewEncoder(&body).Encode(doc.Body); err != nil {
+			return err
+		}
+	}
+	resp, err := o.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", &body)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Error *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.Errors {
+		return nil
+	}
+	for _, item := range result.Items {
+		for _, r := range item {
+			if r.Error != nil {
+				return fmt.Errorf("failed to index the documents: %s: %s", r.Error.Type, r.Error.Reason)
+			}
+		}
+	}
+	return errors.New("failed to index the documents")
+}
+
+func (o *openSearchIndexer) Search(ctx context.Context, index string, query Query) (*Result, error) {
+	boolQuery := map[string]interface{}{}
+	if query.Text != "" {
+		boolQuery["must"] = []interface{}{map[string]interface{}{
+			"simple_query_string": map[string]interface{}{"query": query.Text, "default_operator": "and"},
+		}}
+	}
+	var filters []interface{}
+	for field, values := range query.Terms {
+		filters = append(filters, map[string]interface{}{"terms": map[string]interface{}{field: values}})
+	}
+	if len(filters) > 0 {
+		boolQuery["filter"] = filters
+	}
+	request := map[string]interface{}{
+		"from":             query.From,
+		"size":             query.Size,
+		"track_total_hits": true,
+		"query":            map[string]interface{}{"bool": boolQuery},
+	}
+	if query.SortField != "" {
+		request["sort"] = []interface{}{map[string]interface{}{query.SortField: map[string]string{"order": "desc", "unmapped_type": "date"}}}
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	// the missing indices are ignored, the projects without any documents have no index
+	resp, err := o.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search?ignore_unavailable=true", "application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []Hit `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &Result{Total: result.Hits.Total.Value, Hits: result.Hits.Hits}, nil
+}
+
+func (o *openSearchIndexer) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, o.cfg.Endpoint+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if o.cfg.Username != "" {
+		req.SetBasicAuth(o.cfg.Username, o.cfg.Password)
+	}
+	return o.client.Do(req)
+}
+
+func responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("the search engine responds %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package searchindex
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenSearchIndexer(t *testing.T) {
+	var bulkBody, searchPath string
+	var searchBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		assert.Equal(t, "elastic", username)
+		assert.Equal(t, "secret", password)
+		data, _ := io.ReadAll(r.Body)
+		switch {
+		case r.URL.Path == "/_bulk":
+			bulkBody = string(data)
+			_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+		case strings.HasSuffix(r.URL.Path, "/_search"):
+			searchPath = r.URL.RequestURI()
+			_ = json.Unmarshal(data, &searchBody)
+			_, _ = w.Write([]byte(`{"hits":{"total":{"value":1},"hits":[{"_id":"a1","_source":{"entity":"app1"}}]}}`))
+		}
+	}))
+	defer server.Close()
+
+	indexer := New(Config{Endpoint: server.URL + "/", Username: "elastic", Password: "secret"})
+	assert.True(t, indexer.Enabled())
+	index := indexer.IndexName("audit", "Default")
+	assert.Equal(t, "velaux-audit-default", index)
+
+	ctx := context.Background()
+	assert.NoError(t, indexer.Bulk(ctx, []Document{{Index: index, ID: "a1", Body: map[string]string{"entity": "app1"}}}))
+	assert.Equal(t, "{\"index\":{\"_id\":\"a1\",\"_index\":\"velaux-audit-default\"}}\n{\"entity\":\"app1\"}\n", bulkBody)
+
+	result, err := indexer.Search(ctx, index, Query{Text: "replicas", Terms: map[string][]string{"entity": {"app1"}}, SortField: "time", Size: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Total)
+	assert.Equal(t, "a1", result.Hits[0].ID)
+	assert.Equal(t, "/velaux-audit-default/_search?ignore_unavailable=true", searchPath)
+	assert.Contains(t, searchBody["query"], "bool")
+}
+
+func TestBulkErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":true,"items":[{"index":{"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}]}`))
+	}))
+	defer server.Close()
+	err := New(Config{Endpoint: server.URL}).Bulk(context.Background(), []Document{{Index: "i", ID: "1", Body: map[string]string{}}})
+	assert.ErrorContains(t, err, "mapper_parsing_exception")
+}
+
+func TestDisabledIndexer(t *testing.T) {
+	indexer := New(Config{})
+	assert.False(t, indexer.Enabled())
+	assert.ErrorIs(t, indexer.Bulk(context.Background(), nil), ErrNotConfigured)
+}
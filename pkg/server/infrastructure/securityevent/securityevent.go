@@ -0,0 +1,284 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securityevent
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// TypeLogin the login of the user, both the successful and the failed ones
+	TypeLogin = "login"
+	// TypePasswordChange the password of the user is changed by the user or an admin
+	TypePasswordChange = "password_change"
+	// TypePasswordReset the password of the user is reset by the token sent to the email
+	TypePasswordReset = "password_reset"
+	// TypeAPITokenCreate an API token is created
+	TypeAPITokenCreate = "api_token_create"
+)
+
+const (
+	// OutcomeSuccess the operation succeeded
+	OutcomeSuccess = "success"
+	// OutcomeFailure the operation failed
+	OutcomeFailure = "failure"
+)
+
+// HeaderSignature the header of the HMAC-SHA256 signature of the webhook body
+const HeaderSignature = "X-VelaUX-Signature"
+
+// queueSize the count of the events waiting to be sent, the new events are dropped if the queue is full
+const queueSize = 1024
+
+// Event is the structured authentication event sent to the SIEM
+type Event struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Outcome string    `json:"outcome"`
+	// Username the user that the event is about
+	Username string `json:"username,omitempty"`
+	// Operator the login user that performs the operation, it is empty for the anonymous requests
+	Operator  string            `json:"operator,omitempty"`
+	Reason    string            `json:"reason,omitempty"`
+	ClientIP  string            `json:"clientIP,omitempty"`
+	UserAgent string            `json:"userAgent,omitempty"`
+	RequestID string            `json:"requestID,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// Config the targets to stream the security events to
+type Config struct {
+	// Webhooks the URLs to post the events to as JSON
+	Webhooks []string
+	// WebhookSecret signs the body of the webhook requests, the signature is not sent if it is empty
+	WebhookSecret string
+	// Syslog the address of the syslog server, in the format of udp://host:port or tcp://host:port
+	Syslog string
+}
+
+// Validate checks the targets
+func (c Config) Validate() error {
+	for _, webhook := range c.Webhooks {
+		if u, err := url.Parse(webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("the security event webhook must be a http or https URL, got %s", webhook)
+		}
+	}
+	if c.Syslog != "" {
+		if _, _, err := parseSyslogAddress(c.Syslog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Emitter streams the security events to the configured targets
+type Emitter interface {
+	// Enabled returns false if no target is configured
+	Enabled() bool
+	// Emit queues the event to send, it never blocks the caller
+	Emit(event Event)
+}
+
+// New creates the emitter, the events are dropped if no target is configured
+func New(cfg Config) Emitter {
+	var sinks []sink
+	for _, webhook := range cfg.Webhooks {
+		sinks = append(sinks, &webhookSink{url: webhook, secret: cfg.WebhookSecret, client: &http.Client{Timeout: 10 * time.Second}})
+	}
+	if cfg.Syslog != "" {
+		network, address, err := parseSyslogAddress(cfg.Syslog)
+		if err != nil {
+			klog.Errorf("failed to init the syslog target of the security events: %s", err.Error())
+		} else {
+			hostname, _ := os.Hostname()
+			sinks = append(sinks, &syslogSink{network: network, address: address, hostname: hostname})
+		}
+	}
+	if len(sinks) == 0 {
+		return disabledEmitter{}
+	}
+	e := &asyncEmitter{sinks: sinks, queue: make(chan Event, queueSize)}
+	go e.run()
+	return e
+}
+
+type disabledEmitter struct{}
+
+func (disabledEmitter) Enabled() bool {
+	return false
+}
+
+func (disabledEmitter) Emit(_ Event) {}
+
+type sink interface {
+	name() string
+	send(ctx context.Context, event Event, body []byte) error
+}
+
+type asyncEmitter struct {
+	sinks []sink
+	queue chan Event
+}
+
+func (e *asyncEmitter) Enabled() bool {
+	return true
+}
+
+func (e *asyncEmitter) Emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	select {
+	case e.queue <- event:
+	default:
+		klog.Warningf("the security event queue is full, the %s event of the user %s is dropped", event.Type, event.Username)
+	}
+}
+
+func (e *asyncEmitter) run() {
+	for event := range e.queue {
+		body, err := json.Marshal(event)
+		if err != nil {
+			klog.Errorf("failed to marshal the security event: %s", err.Error())
+			continue
+		}
+		for _, s := range e.sinks {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := s.send(ctx, event, body); err != nil {
+				klog.Errorf("failed to send the %s security event to %s: %s", event.Type, s.name(), err.Error())
+			}
+			cancel()
+		}
+	}
+}
+
+type webhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func (w *webhookSink) name() string {
+	return w.url
+}
+
+func (w *webhookSink) send(ctx context.Context, _ Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+sign(w.secret, body))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("the webhook responds %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sign returns the hex encoded HMAC-SHA256 of the body
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// syslogSink sends the events in the RFC 5424 format, the TCP messages are framed by the octet counting of RFC 6587
+type syslogSink struct {
+	network  string
+	address  string
+	hostname string
+	conn     net.Conn
+}
+
+func (s *syslogSink) name() string {
+	return s.network + "://" + s.address
+}
+
+func (s *syslogSink) send(ctx context.Context, event Event, body []byte) error {
+	msg := formatSyslog(s.hostname, event, body)
+	if s.network == "tcp" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+	// the connection may be closed by the server, it is dialed again once
+	for attempt := 0; ; attempt++ {
+		if s.conn == nil {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, s.network, s.address)
+			if err != nil {
+				return err
+			}
+			s.conn = conn
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = s.conn.SetWriteDeadline(deadline)
+		}
+		_, err := s.conn.Write([]byte(msg))
+		if err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+		if attempt > 0 {
+			return err
+		}
+	}
+}
+
+// facilityAuth the security/authorization messages facility of syslog
+const facilityAuth = 4
+
+func formatSyslog(hostname string, event Event, body []byte) string {
+	// the failures are warnings(4), the others are notices(5)
+	severity := 5
+	if event.Outcome == OutcomeFailure {
+		severity = 4
+	}
+	if hostname == "" {
+		hostname = "-"
+	}
+	return fmt.Sprintf("<%d>1 %s %s velaux - %s - %s\n", facilityAuth*8+severity, event.Time.UTC().Format(time.RFC3339Nano), hostname, event.Type, body)
+}
+
+func parseSyslogAddress(address string) (string, string, error) {
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" {
+		return "", "", fmt.Errorf("the security event syslog address must be in the format of udp://host:port or tcp://host:port, got %s", address)
+	}
+	return u.Scheme, u.Host, nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securityevent
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmitToWebhookAndSyslog(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	emitter := New(Config{Webhooks: []string{server.URL}, WebhookSecret: "siem-secret", Syslog: "udp://" + conn.LocalAddr().String()})
+	assert.True(t, emitter.Enabled())
+	emitter.Emit(Event{Type: TypeLogin, Outcome: OutcomeFailure, Username: "alice", Reason: "the password is inconsistent", ClientIP: "10.0.0.1"})
+
+	select {
+	case req := <-received:
+		body := <-bodies
+		assert.Equal(t, "sha256="+sign("siem-secret", body), req.Header.Get(HeaderSignature))
+		var event Event
+		assert.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, TypeLogin, event.Type)
+		assert.Equal(t, "alice", event.Username)
+		assert.False(t, event.Time.IsZero())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook is not called")
+	}
+
+	buf := make([]byte, 4096)
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	msg := string(buf[:n])
+	assert.True(t, strings.HasPrefix(msg, "<36>1 "), msg)
+	assert.Contains(t, msg, " velaux - login - ")
+	assert.Contains(t, msg, `"clientIP":"10.0.0.1"`)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Webhooks: []string{"https://siem.example.com/events"}, Syslog: "tcp://siem.example.com:601"}.Validate())
+	assert.Error(t, Config{Webhooks: []string{"siem.example.com"}}.Validate())
+	assert.Error(t, Config{Syslog: "siem.example.com:514"}.Validate())
+
+	disabled := New(Config{})
+	assert.False(t, disabled.Enabled())
+	disabled.Emit(Event{Type: TypeLogin})
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultAPIURL the URL of the Slack Web API
+const defaultAPIURL = "https://slack.com/api"
+
+// ErrNotConfigured means the Slack bot token is not configured
+var ErrNotConfigured = errors.New("the slack sender is not configured")
+
+// ErrOffline means the message is not sent because the Slack API is not requested in the offline mode
+var ErrOffline = errors.New("the slack messages are not sent in the offline mode")
+
+// Config the Slack app to send the messages
+type Config struct {
+	// Token the bot token of the Slack app, it requires the chat:write scope
+	Token string
+	// APIURL the URL of the Slack Web API, it is replaced in the tests
+	APIURL string
+}
+
+// Sender sends the messages to the Slack users or channels
+type Sender interface {
+	// Enabled returns false if the messages could not be sent
+	Enabled() bool
+	// Send sends the text to the channel, the channel is the ID of a channel or the member ID of a user
+	Send(ctx context.Context, channel, text string) error
+}
+
+// New creates the Slack sender, the messages are not sent if the token is not configured
+func New(cfg Config) Sender {
+	if cfg.Token == "" {
+		return disabledSender{}
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = defaultAPIURL
+	}
+	cfg.APIURL = strings.TrimSuffix(cfg.APIURL, "/")
+	return &botSender{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type disabledSender struct{}
+
+func (disabledSender) Enabled() bool {
+	return false
+}
+
+func (disabledSender) Send(_ context.Context, _, _ string) error {
+	return ErrNotConfigured
+}
+
+type botSender struct {
+	cfg    Config
+	client *http.Client
+}
+
+func (s *botSender) Enabled() bool {
+	return true
+}
+
+// Send posts the message by the chat.postMessage API
+func (s *botSender) Send(ctx context.Context, channel, text string) error {
+	body, err := json.Marshal(map[string]string{"channel": channel, "text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.APIURL+"/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the slack API responds %d", resp.StatusCode)
+	}
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.OK {
+		return fmt.Errorf("failed to post the slack message: %s", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat.postMessage", r.URL.Path)
+		assert.Equal(t, "Bearer xoxb-test", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		if received["channel"] == "C-unknown" {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	sender := New(Config{Token: "xoxb-test", APIURL: server.URL})
+	assert.True(t, sender.Enabled())
+	assert.NoError(t, sender.Send(context.Background(), "U123", "hello"))
+	assert.Equal(t, map[string]string{"channel": "U123", "text": "hello"}, received)
+	assert.EqualError(t, sender.Send(context.Background(), "C-unknown", "hello"), "failed to post the slack message: channel_not_found")
+
+	disabled := New(Config{})
+	assert.False(t, disabled.Enabled())
+	assert.ErrorIs(t, disabled.Send(context.Background(), "U123", "hello"), ErrNotConfigured)
+}
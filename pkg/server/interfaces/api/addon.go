@@ -122,6 +122,46 @@ func (s *addon) GetWebServiceRoute() *restful.WebService {
 		Param(ws.PathParameter("addonName", "addon name to update").DataType("string").Required(true)).
 		Writes(apis.AddonStatusResponse{}))
 
+	// roll out the addon across the clusters
+	ws.Route(ws.POST("/{addonName}/rollouts").To(s.createAddonRollout).
+		Doc("enable or upgrade an addon across the clusters one by one").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.CreateAddonRolloutRequest{}).
+		Filter(s.RbacService.CheckPerm("addon", "enable")).
+		Returns(200, "OK", apis.AddonRolloutBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Param(ws.PathParameter("addonName", "addon name to roll out").DataType("string").Required(true)).
+		Writes(apis.AddonRolloutBase{}))
+
+	ws.Route(ws.GET("/{addonName}/rollouts").To(s.listAddonRollouts).
+		Doc("list the rollouts of an addon").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(s.RbacService.CheckPerm("addon", "detail")).
+		Returns(200, "OK", apis.ListAddonRolloutsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Param(ws.PathParameter("addonName", "addon name").DataType("string").Required(true)).
+		Writes(apis.ListAddonRolloutsResponse{}))
+
+	ws.Route(ws.GET("/{addonName}/rollouts/{rolloutName}").To(s.detailAddonRollout).
+		Doc("show the progress of each cluster of an addon rollout").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(s.RbacService.CheckPerm("addon", "detail")).
+		Returns(200, "OK", apis.AddonRolloutBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Param(ws.PathParameter("addonName", "addon name").DataType("string").Required(true)).
+		Param(ws.PathParameter("rolloutName", "the name of the rollout").DataType("string").Required(true)).
+		Writes(apis.AddonRolloutBase{}))
+
+	ws.Route(ws.POST("/{addonName}/rollouts/{rolloutName}/retry").To(s.retryAddonRollout).
+		Doc("retry the failed clusters of an addon rollout").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(s.RbacService.CheckPerm("addon", "enable")).
+		Returns(200, "OK", apis.AddonRolloutBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Param(ws.PathParameter("addonName", "addon name").DataType("string").Required(true)).
+		Param(ws.PathParameter("rolloutName", "the name of the rollout").DataType("string").Required(true)).
+		Writes(apis.AddonRolloutBase{}))
+
 	ws.Filter(authCheckFilter)
 	return ws
 }
@@ -310,3 +350,60 @@ func (s *enabledAddon) list(req *restful.Request, res *restful.Response) {
 		return
 	}
 }
+
+func (s *addon) createAddonRollout(req *restful.Request, res *restful.Response) {
+	var createReq apis.CreateAddonRolloutRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	rollout, err := s.AddonService.CreateAddonRollout(req.Request.Context(), req.PathParameter("addonName"), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(rollout); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (s *addon) listAddonRollouts(req *restful.Request, res *restful.Response) {
+	rollouts, err := s.AddonService.ListAddonRollouts(req.Request.Context(), req.PathParameter("addonName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(rollouts); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (s *addon) detailAddonRollout(req *restful.Request, res *restful.Response) {
+	rollout, err := s.AddonService.GetAddonRollout(req.Request.Context(), req.PathParameter("addonName"), req.PathParameter("rolloutName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(rollout); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (s *addon) retryAddonRollout(req *restful.Request, res *restful.Response) {
+	rollout, err := s.AddonService.RetryAddonRollout(req.Request.Context(), req.PathParameter("addonName"), req.PathParameter("rolloutName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(rollout); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+// publicRoutes the routes that could be requested without the authentication.
+// They are the endpoints of the login page, the webhook receivers, the status badges that have their own tokens, the avatars that have the random IDs and the health probes.
+// Every route that does not require the login must be declared here, otherwise the authCheckFilter rejects the request.
+var publicRoutes = newRouteSet(
+	routeKey(http.MethodPost, versionPrefix+"/auth/login"),
+	routeKey(http.MethodGet, versionPrefix+"/auth/dex_config"),
+	routeKey(http.MethodGet, versionPrefix+"/auth/refresh_token"),
+	routeKey(http.MethodGet, versionPrefix+"/auth/login_type"),
+	routeKey(http.MethodPost, versionPrefix+"/auth/password-reset"),
+	routeKey(http.MethodPost, versionPrefix+"/auth/password-reset/confirm"),
+	routeKey(http.MethodGet, versionPrefix+"/auth/invitations/{invitationID}"),
+	routeKey(http.MethodPost, versionPrefix+"/auth/invitations/{invitationID}/accept"),
+	routeKey(http.MethodGet, versionPrefix+"/auth/bootstrap"),
+	routeKey(http.MethodPost, versionPrefix+"/auth/bootstrap"),
+	routeKey(http.MethodPost, versionPrefix+"/webhook/{token}"),
+	routeKey(http.MethodGet, versionPrefix+"/badges/{badgeID}"),
+	routeKey(http.MethodGet, versionPrefix+"/users/avatars/{avatarID}"),
+	routeKey(http.MethodGet, healthzPath),
+	routeKey(http.MethodGet, readyzPath),
+)
+
+// permissionFreeRoutes the routes that only require the login, the RBAC checking is bypassed.
+// The handlers of these routes must filter the response by the login user by themselves.
+var permissionFreeRoutes = newRouteSet(
+	routeKey(http.MethodGet, versionPrefix+"/auth/user_info"),
+	routeKey(http.MethodGet, versionPrefix+"/auth/tokens"),
+	routeKey(http.MethodPost, versionPrefix+"/auth/tokens"),
+	routeKey(http.MethodDelete, versionPrefix+"/auth/tokens/{tokenName}"),
+	routeKey(http.MethodGet, versionPrefix+"/auth/sessions"),
+	routeKey(http.MethodDelete, versionPrefix+"/auth/sessions"),
+	routeKey(http.MethodDelete, versionPrefix+"/auth/sessions/{sessionID}"),
+	routeKey(http.MethodGet, versionPrefix+"/users/me/preferences"),
+	routeKey(http.MethodPut, versionPrefix+"/users/me/preferences"),
+	routeKey(http.MethodPost, versionPrefix+"/users/me/email"),
+	routeKey(http.MethodPost, versionPrefix+"/users/me/email/verify"),
+	routeKey(http.MethodPut, versionPrefix+"/users/me/password"),
+	routeKey(http.MethodPut, versionPrefix+"/users/me/avatar"),
+	routeKey(http.MethodDelete, versionPrefix+"/users/me/avatar"),
+	routeKey(http.MethodGet, versionPrefix+"/system_info/"),
+	routeKey(http.MethodGet, versionPrefix+"/events/"),
+	routeKey(http.MethodGet, versionPrefix+"/applications/"),
+	routeKey(http.MethodGet, versionPrefix+"/envs/"),
+	routeKey(http.MethodGet, versionPrefix+"/definitions/"),
+	routeKey(http.MethodGet, versionPrefix+"/definitions/{definitionName}"),
+	routeKey(http.MethodGet, versionPrefix+"/enabled_addon/"),
+	routeKey(http.MethodGet, versionPrefix+"/query/"),
+	routeKey(http.MethodGet, versionPrefix+"/payload_types/"),
+	routeKey(http.MethodGet, versionPrefix+"/pipelines/"),
+	routeKey(http.MethodGet, versionPrefix+"/repository/charts"),
+	routeKey(http.MethodGet, versionPrefix+"/repository/chart/versions"),
+	routeKey(http.MethodGet, versionPrefix+"/repository/charts/{chart}/versions"),
+	routeKey(http.MethodGet, versionPrefix+"/repository/chart/values"),
+	routeKey(http.MethodGet, versionPrefix+"/repository/charts/{chart}/versions/{version}/values"),
+	routeKey(http.MethodGet, versionPrefix+"/system/resource-actions"),
+	routeKey(http.MethodGet, versionPrefix+"/search/"),
+	routeKey(http.MethodGet, versionPrefix+"/trash/"),
+	routeKey(http.MethodPost, versionPrefix+"/trash/{trashName}/restore"),
+	routeKey(http.MethodGet, versionPrefix+"/inbox/"),
+)
+
+type routeSet map[string]struct{}
+
+func newRouteSet(keys ...string) routeSet {
+	set := make(routeSet, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+	return set
+}
+
+// contains checks whether the route that matches the request is in the set
+func (s routeSet) contains(req *restful.Request) bool {
+	_, exist := s[routeKey(req.Request.Method, req.SelectedRoutePath())]
+	return exist
+}
+
+// routeKey the key of the route, the path is the route path template, such as /api/v1/webhook/{token}
+func routeKey(method, path string) string {
+	return fmt.Sprintf("%s %s", method, path)
+}
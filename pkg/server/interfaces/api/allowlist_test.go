@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+	"gotest.tools/assert"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+type fakeRBACService struct {
+	service.RBACService
+}
+
+func (f *fakeRBACService) CheckPerm(resource string, actions ...string) func(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+	return fakeCheckPermFilter
+}
+
+func fakeCheckPermFilter(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+	chain.ProcessFilter(req, res)
+}
+
+// buildTestWebServices builds all registered web services with a fake RBAC service
+func buildTestWebServices(t *testing.T) []*restful.WebService {
+	registered := registeredAPI
+	registeredAPI = nil
+	defer func() {
+		registeredAPI = registered
+	}()
+	InitAPIBean()
+
+	rbacType := reflect.TypeOf((*service.RBACService)(nil)).Elem()
+	var webServices []*restful.WebService
+	for _, handler := range GetRegisteredAPI() {
+		value := reflect.Indirect(reflect.ValueOf(handler))
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			if field.Type() == rbacType && field.CanSet() {
+				field.Set(reflect.ValueOf(&fakeRBACService{}))
+			}
+		}
+		webServices = append(webServices, handler.GetWebServiceRoute())
+	}
+	assert.Assert(t, len(webServices) > 0)
+	return webServices
+}
+
+func TestRoutesRequireAuthentication(t *testing.T) {
+	container := restful.NewContainer()
+	for _, ws := range buildTestWebServices(t) {
+		container.Add(ws)
+	}
+	paramReg := regexp.MustCompile(`\{[^}]*\}`)
+	for _, ws := range container.RegisteredWebServices() {
+		for _, route := range ws.Routes() {
+			key := routeKey(route.Method, route.Path)
+			if _, exist := publicRoutes[key]; exist {
+				continue
+			}
+			req := httptest.NewRequest(route.Method, paramReg.ReplaceAllString(route.Path, "test"), nil)
+			req.Header.Set("Content-Type", restful.MIME_JSON)
+			req.Header.Set("Accept", restful.MIME_JSON)
+			res := httptest.NewRecorder()
+			container.ServeHTTP(res, req)
+			assert.Equal(t, res.Code, http.StatusUnauthorized, "the route %s is public, add it to the publicRoutes if it is expected", key)
+		}
+	}
+}
+
+func TestRoutesRequirePermission(t *testing.T) {
+	checkPerm := reflect.ValueOf(fakeCheckPermFilter).Pointer()
+	existRoutes := map[string]bool{}
+	for _, ws := range buildTestWebServices(t) {
+		for _, route := range ws.Routes() {
+			key := routeKey(route.Method, route.Path)
+			existRoutes[key] = true
+			var checked bool
+			for _, filter := range route.Filters {
+				if reflect.ValueOf(filter).Pointer() == checkPerm {
+					checked = true
+				}
+			}
+			_, public := publicRoutes[key]
+			_, permissionFree := permissionFreeRoutes[key]
+			if public || permissionFree {
+				assert.Assert(t, !checked, "the route %s checks the permission, remove it from the allowlist", key)
+				continue
+			}
+			assert.Assert(t, checked, "the route %s does not check the permission, add it to the permissionFreeRoutes if it is expected", key)
+		}
+	}
+	for key := range publicRoutes {
+		assert.Assert(t, existRoutes[key], "the public route %s is not registered", key)
+	}
+	for key := range permissionFreeRoutes {
+		assert.Assert(t, existRoutes[key], "the permission free route %s is not registered", key)
+	}
+}
+
+func TestQueryTokenOnlyForChangeEvents(t *testing.T) {
+	container := restful.NewContainer()
+	for _, ws := range buildTestWebServices(t) {
+		container.Add(ws)
+	}
+	request := func(path string) *bcode.Bcode {
+		req := httptest.NewRequest(http.MethodGet, path+"?token=invalid", nil)
+		req.Header.Set("Accept", restful.MIME_JSON)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		res := httptest.NewRecorder()
+		container.ServeHTTP(res, req)
+		assert.Equal(t, res.Code, http.StatusUnauthorized)
+		var code bcode.Bcode
+		assert.NilError(t, json.Unmarshal(res.Body.Bytes(), &code))
+		return &code
+	}
+	assert.Assert(t, request(versionPrefix+"/events/").BusinessCode != bcode.ErrNotAuthorized.BusinessCode, "the query token of the change events is not read")
+	assert.Equal(t, request(versionPrefix+"/applications/").BusinessCode, bcode.ErrNotAuthorized.BusinessCode)
+}
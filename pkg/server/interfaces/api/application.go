@@ -18,6 +18,7 @@ package api
 
 import (
 	"context"
+	"net/http"
 	"strconv"
 
 	"k8s.io/klog/v2"
@@ -38,6 +39,9 @@ type application struct {
 	RbacService        service.RBACService        `inject:""`
 	ApplicationService service.ApplicationService `inject:""`
 	EnvBindingService  service.EnvBindingService  `inject:""`
+	SpecAuditService   service.SpecAuditService   `inject:""`
+	StatusBadgeService service.StatusBadgeService `inject:""`
+	WebhookService     service.WebhookService     `inject:""`
 }
 
 // NewApplication new application manage
@@ -107,6 +111,93 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.ApplicationBase{}))
 
+	ws.Route(ws.PUT("/{appName}/notice").To(c.setApplicationNotice).
+		Doc("attach a notice to the application, the blocking notice must be acknowledged when deploying").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "notice")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Reads(apis.UpdateApplicationNoticeRequest{}).
+		Returns(200, "OK", model.ApplicationNotice{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(model.ApplicationNotice{}))
+
+	ws.Route(ws.DELETE("/{appName}/notice").To(c.deleteApplicationNotice).
+		Doc("remove the notice of the application").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "notice")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/{appName}/spec_audits").To(c.listSpecAudits).
+		Doc("list the spec changes of the application and its components and policies").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "detail")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Returns(200, "OK", apis.ListSpecAuditsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListSpecAuditsResponse{}))
+
+	ws.Route(ws.GET("/{appName}/lint").To(c.lintApplication).
+		Doc("evaluate the best-practice rules against the components of the application").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "detail")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Returns(200, "OK", apis.ApplicationLintResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ApplicationLintResponse{}))
+
+	ws.Route(ws.GET("/{appName}/badges").To(c.listStatusBadges).
+		Doc("list the public status badges of the application").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "detail")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Returns(200, "OK", apis.ListStatusBadgesResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListStatusBadgesResponse{}))
+
+	ws.Route(ws.POST("/{appName}/badges").To(c.createStatusBadge).
+		Doc("create a public status badge of the application health in an environment").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "update")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Reads(apis.CreateStatusBadgeRequest{}).
+		Returns(200, "OK", apis.CreateStatusBadgeResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.CreateStatusBadgeResponse{}))
+
+	ws.Route(ws.PUT("/{appName}/badges/{badgeID}").To(c.updateStatusBadge).
+		Doc("enable or disable a status badge of the application").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "update")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("badgeID", "identifier of the badge").DataType("string")).
+		Reads(apis.UpdateStatusBadgeRequest{}).
+		Returns(200, "OK", apis.StatusBadgeBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.StatusBadgeBase{}))
+
+	ws.Route(ws.DELETE("/{appName}/badges/{badgeID}").To(c.deleteStatusBadge).
+		Doc("delete a status badge of the application, its token is revoked").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "update")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("badgeID", "identifier of the badge").DataType("string")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
 	ws.Route(ws.GET("/{appName}/statistics").To(c.applicationStatistics).
 		Doc("detail one application ").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -160,6 +251,19 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes([]*apis.ApplicationTriggerBase{}))
 
+	ws.Route(ws.GET("/{appName}/triggers/{token}/rejected_deliveries").To(c.listTriggerRejectedDeliveries).
+		Doc("list the deliveries of the trigger rejected by the signature verification or the replay protection").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("trigger", "list")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("token", "identifier of the trigger").DataType("string")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Returns(200, "OK", apis.ListTriggerRejectedDeliveriesResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListTriggerRejectedDeliveriesResponse{}))
+
 	ws.Route(ws.POST("/{appName}/template").To(c.publishApplicationTemplate).
 		Doc("create one application template").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -432,6 +536,18 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.ApplicationStatusResponse{}))
 
+	ws.Route(ws.GET("/{appName}/envs/{envName}/changes").To(c.getApplicationChangeReport).
+		Doc("report the revisions, config changes, definition upgrades and warning events since the application was healthy last time").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("envBinding", "detail")).
+		Filter(c.appCheckFilter).
+		Filter(c.envCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("envName", "identifier of the application envbinding").DataType("string")).
+		Returns(200, "OK", apis.ApplicationChangeReport{}).
+		Returns(404, "Not Found", bcode.Bcode{}).
+		Writes(apis.ApplicationChangeReport{}))
+
 	ws.Route(ws.POST("/{appName}/envs/{envName}/recycle").To(c.recycleApplicationEnv).
 		Doc("recycle application env").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -444,6 +560,43 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.EmptyResponse{}))
 
+	ws.Route(ws.POST("/{appName}/envs/{envName}/scale").To(c.scaleApplicationEnv).
+		Doc("scale the components of the application in the env, only the workflow of the env is deployed").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("envBinding", "scale")).
+		Filter(c.appCheckFilter).
+		Filter(c.envCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string").Required(true)).
+		Param(ws.PathParameter("envName", "identifier of the application envbinding").DataType("string").Required(true)).
+		Reads(apis.ScaleApplicationRequest{}).
+		Returns(200, "OK", apis.ApplicationDeployResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ApplicationDeployResponse{}))
+
+	ws.Route(ws.POST("/{appName}/envs/{envName}/conflict/accept").To(c.acceptApplicationConflict).
+		Doc("take the application modified outside VelaUX in the env as the deployed revision, its components are taken as the ones of the application").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "reset")).
+		Filter(c.appCheckFilter).
+		Filter(c.envCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string").Required(true)).
+		Param(ws.PathParameter("envName", "identifier of the application envbinding").DataType("string").Required(true)).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(404, "Not Found", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.POST("/{appName}/envs/{envName}/conflict/overwrite").To(c.overwriteApplicationConflict).
+		Doc("restore the application modified outside VelaUX in the env to the deployed revision").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "deploy")).
+		Filter(c.appCheckFilter).
+		Filter(c.envCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string").Required(true)).
+		Param(ws.PathParameter("envName", "identifier of the application envbinding").DataType("string").Required(true)).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(404, "Not Found", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
 	ws.Route(ws.GET("/{appName}/workflows").To(c.WorkflowAPI.listApplicationWorkflows).
 		Doc("list application workflow").
 		Filter(c.RbacService.CheckPerm("application/workflow", "list")).
@@ -509,6 +662,9 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Filter(c.WorkflowAPI.workflowCheckFilter).
 		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
 		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Param(ws.QueryParameter("commit", "fuzzy search the records by the build commit").DataType("string")).
+		Param(ws.QueryParameter("branch", "fuzzy search the records by the build branch").DataType("string")).
+		Param(ws.QueryParameter("buildURL", "fuzzy search the records by the build URL").DataType("string")).
 		Returns(200, "OK", apis.ListWorkflowRecordsResponse{}).
 		Writes(apis.ListWorkflowRecordsResponse{}).Do(returns200, returns500))
 
@@ -524,6 +680,21 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", apis.DetailWorkflowRecordResponse{}).
 		Writes(apis.DetailWorkflowRecordResponse{}).Do(returns200, returns500))
 
+	ws.Route(ws.PUT("/{appName}/workflows/{workflowName}/records/{record}/metadata").To(c.WorkflowAPI.annotateWorkflowRecord).
+		Doc("attach the external build metadata to the workflow record").
+		Filter(c.RbacService.CheckPerm("application/workflow/record", "annotate")).
+		Param(ws.PathParameter("appName", "identifier of the application.").DataType("string").Required(true)).
+		Param(ws.PathParameter("workflowName", "identifier of the workflow").DataType("string")).
+		Param(ws.PathParameter("record", "identifier of the workflow record").DataType("string")).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.appCheckFilter).
+		Filter(c.WorkflowAPI.workflowCheckFilter).
+		Filter(c.WorkflowAPI.workflowRecordCheckFilter).
+		Reads(apis.AnnotateWorkflowRecordRequest{}).
+		Returns(200, "OK", apis.WorkflowRecord{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.WorkflowRecord{}))
+
 	ws.Route(ws.GET("/{appName}/workflows/{workflowName}/records/{record}/resume").To(c.WorkflowAPI.resumeWorkflowRecord).
 		Doc("resume suspend workflow record").
 		Filter(c.RbacService.CheckPerm("application/workflow/record", "resume")).
@@ -572,6 +743,8 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Param(ws.PathParameter("workflowName", "identifier of the workflow").DataType("string")).
 		Param(ws.PathParameter("record", "identifier of the workflow record").DataType("string")).
 		Param(ws.QueryParameter("step", "Specified the step filter").DataType("string").Required(true)).
+		Param(ws.QueryParameter("page", "the page of the log lines, starting from 1, the whole log is returned if it is not set").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "the number of the log lines per page").DataType("integer")).
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Filter(c.appCheckFilter).
 		Filter(c.WorkflowAPI.workflowCheckFilter).
@@ -777,6 +950,24 @@ func (c *application) updateApplicationTrigger(req *restful.Request, res *restfu
 	}
 }
 
+func (c *application) listTriggerRejectedDeliveries(req *restful.Request, res *restful.Response) {
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	deliveries, err := c.WebhookService.ListTriggerRejectedDeliveries(req.Request.Context(), app, req.PathParameter("token"), page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(deliveries); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *application) publishApplicationTemplate(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	base, err := c.ApplicationService.PublishApplicationTemplate(req.Request.Context(), app)
@@ -1026,6 +1217,40 @@ func (c *application) updateApplication(req *restful.Request, res *restful.Respo
 	}
 }
 
+func (c *application) setApplicationNotice(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	var noticeReq apis.UpdateApplicationNoticeRequest
+	if err := req.ReadEntity(&noticeReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&noticeReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	notice, err := c.ApplicationService.SetApplicationNotice(req.Request.Context(), app, noticeReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(notice); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) deleteApplicationNotice(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	if err := c.ApplicationService.DeleteApplicationNotice(req.Request.Context(), app); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *application) addApplicationTrait(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	var createReq apis.CreateApplicationTraitRequest
@@ -1100,6 +1325,19 @@ func (c *application) getApplicationStatus(req *restful.Request, res *restful.Re
 	}
 }
 
+func (c *application) getApplicationChangeReport(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	report, err := c.ApplicationService.GetChangeReport(req.Request.Context(), app, req.PathParameter("envName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(report); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *application) listApplicationRevisions(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
@@ -1203,12 +1441,29 @@ func (c *application) deleteApplicationEnv(req *restful.Request, res *restful.Re
 	}
 }
 
+// externallyManagedAppRoutes the writing routes allowed for the applications managed externally,
+// they do not change the spec of the application
+var externallyManagedAppRoutes = newRouteSet(
+	routeKey(http.MethodPost, versionPrefix+"/applications/{appName}/compare"),
+	routeKey(http.MethodPost, versionPrefix+"/applications/{appName}/dry-run"),
+	routeKey(http.MethodPut, versionPrefix+"/applications/{appName}/notice"),
+	routeKey(http.MethodDelete, versionPrefix+"/applications/{appName}/notice"),
+	routeKey(http.MethodPost, versionPrefix+"/applications/{appName}/badges"),
+	routeKey(http.MethodPut, versionPrefix+"/applications/{appName}/badges/{badgeID}"),
+	routeKey(http.MethodDelete, versionPrefix+"/applications/{appName}/badges/{badgeID}"),
+	routeKey(http.MethodPut, versionPrefix+"/applications/{appName}/workflows/{workflowName}/records/{record}/metadata"),
+)
+
 func (c *application) appCheckFilter(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
 	app, err := c.ApplicationService.GetApplication(req.Request.Context(), req.PathParameter("appName"))
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
 	}
+	if app.IsManagedExternally() && req.Request.Method != http.MethodGet && !externallyManagedAppRoutes.contains(req) {
+		bcode.ReturnError(req, res, bcode.ErrApplicationManagedExternally)
+		return
+	}
 	req.Request = req.Request.WithContext(context.WithValue(req.Request.Context(), &apis.CtxKeyApplication, app))
 	chain.ProcessFilter(req, res)
 }
@@ -1262,6 +1517,55 @@ func (c *application) recycleApplicationEnv(req *restful.Request, res *restful.R
 	}
 }
 
+func (c *application) acceptApplicationConflict(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	env := req.Request.Context().Value(&apis.CtxKeyApplicationEnvBinding).(*model.EnvBinding)
+	if err := c.ApplicationService.AcceptApplicationConflict(req.Request.Context(), app, env.Name); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) overwriteApplicationConflict(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	env := req.Request.Context().Value(&apis.CtxKeyApplicationEnvBinding).(*model.EnvBinding)
+	if err := c.ApplicationService.OverwriteApplicationConflict(req.Request.Context(), app, env.Name); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) scaleApplicationEnv(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	env := req.Request.Context().Value(&apis.CtxKeyApplicationEnvBinding).(*model.EnvBinding)
+	var scaleReq apis.ScaleApplicationRequest
+	if err := req.ReadEntity(&scaleReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&scaleReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	deployRes, err := c.ApplicationService.ScaleApplication(req.Request.Context(), app, env.Name, scaleReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(deployRes); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *application) listApplicationRecords(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	records, err := c.ApplicationService.ListRecords(req.Request.Context(), app.Name)
@@ -1348,3 +1652,95 @@ func (c *application) rollbackApplicationWithRevision(req *restful.Request, res
 		return
 	}
 }
+
+func (c *application) listSpecAudits(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	audits, err := c.SpecAuditService.ListSpecAudits(req.Request.Context(), service.SpecAuditResourceApplication, app.Project, app.Name, page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(audits); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) lintApplication(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	issues, err := c.ApplicationService.LintApplication(req.Request.Context(), app)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(issues); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) listStatusBadges(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	badges, err := c.StatusBadgeService.ListBadges(req.Request.Context(), service.StatusBadgeResourceApplication, app.Project, app.Name)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(badges); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) createStatusBadge(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	var createReq apis.CreateStatusBadgeRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	badge, err := c.StatusBadgeService.CreateBadge(req.Request.Context(), service.StatusBadgeResourceApplication, app.Project, app.Name, createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(badge); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) updateStatusBadge(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	var updateReq apis.UpdateStatusBadgeRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	badge, err := c.StatusBadgeService.UpdateBadge(req.Request.Context(), service.StatusBadgeResourceApplication, app.Project, app.Name, req.PathParameter("badgeID"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(badge); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) deleteStatusBadge(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	if err := c.StatusBadgeService.DeleteBadge(req.Request.Context(), service.StatusBadgeResourceApplication, app.Project, app.Name, req.PathParameter("badgeID")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
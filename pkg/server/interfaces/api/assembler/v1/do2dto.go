@@ -77,6 +77,12 @@ func ConvertAppModelToBase(app *model.Application, projects []*apisv1.ProjectBas
 		Labels:      app.Labels,
 		Project:     &apisv1.ProjectBase{Name: app.Project},
 		ReadOnly:    app.IsReadOnly(),
+		Notice:      app.Notice,
+		Ownership:   app.Ownership,
+
+		ManagedExternally: app.IsManagedExternally(),
+		SyncedStatus:      app.SyncedStatus,
+		Conflicts:         app.Conflicts,
 	}
 
 	for _, project := range projects {
@@ -126,17 +132,19 @@ func ConvertComponentModelToBase(componentModel *model.ApplicationComponent) *ap
 // ConvertRevisionModelToBase assemble the ApplicationRevision model to DTO
 func ConvertRevisionModelToBase(revision *model.ApplicationRevision, user *model.User) apisv1.ApplicationRevisionBase {
 	base := apisv1.ApplicationRevisionBase{
-		Version:      revision.Version,
-		Status:       revision.Status,
-		Reason:       revision.Reason,
-		Note:         revision.Note,
-		TriggerType:  revision.TriggerType,
-		CreateTime:   revision.CreateTime,
-		EnvName:      revision.EnvName,
-		WorkflowName: revision.WorkflowName,
-		CodeInfo:     revision.CodeInfo,
-		ImageInfo:    revision.ImageInfo,
-		DeployUser:   &apisv1.NameAlias{Name: revision.DeployUser},
+		Version:            revision.Version,
+		Status:             revision.Status,
+		Reason:             revision.Reason,
+		Note:               revision.Note,
+		TriggerType:        revision.TriggerType,
+		CreateTime:         revision.CreateTime,
+		EnvName:            revision.EnvName,
+		WorkflowName:       revision.WorkflowName,
+		CodeInfo:           revision.CodeInfo,
+		ImageInfo:          revision.ImageInfo,
+		RequestID:          revision.RequestID,
+		AcknowledgedNotice: revision.AcknowledgedNotice,
+		DeployUser:         &apisv1.NameAlias{Name: revision.DeployUser},
 	}
 	if user != nil {
 		base.DeployUser.Alias = user.Alias
@@ -150,6 +158,7 @@ func ConvertFromRecordModel(record *model.WorkflowRecord) *apisv1.WorkflowRecord
 		WorkflowRecordBase: apisv1.WorkflowRecordBase{
 			Name:                record.Name,
 			Namespace:           record.Namespace,
+			Cluster:             record.Cluster,
 			WorkflowName:        record.WorkflowName,
 			WorkflowAlias:       record.WorkflowAlias,
 			ApplicationRevision: record.RevisionPrimaryKey,
@@ -158,6 +167,8 @@ func ConvertFromRecordModel(record *model.WorkflowRecord) *apisv1.WorkflowRecord
 			Status:              record.Status,
 			Message:             record.Message,
 			Mode:                record.Mode,
+			RequestID:           record.RequestID,
+			BuildMetadata:       record.BuildMetadata,
 		},
 		Steps: record.Steps,
 	}
@@ -244,10 +255,13 @@ func ConvertPolicyModelToBase(policy *model.ApplicationPolicy) *apisv1.PolicyBas
 // ConvertRole2DTO convert role model to role base struct
 func ConvertRole2DTO(role *model.Role, policies []*model.Permission) *apisv1.RoleBase {
 	return &apisv1.RoleBase{
-		CreateTime: role.CreateTime,
-		UpdateTime: role.UpdateTime,
-		Name:       role.Name,
-		Alias:      role.Alias,
+		CreateTime:      role.CreateTime,
+		UpdateTime:      role.UpdateTime,
+		Name:            role.Name,
+		Alias:           role.Alias,
+		Projects:        role.Projects,
+		ProjectSelector: role.ProjectSelector,
+		ResourceVersion: role.ResourceVersion,
 		Permissions: func() (list []apisv1.NameAlias) {
 			for _, policy := range policies {
 				if policy != nil {
@@ -265,30 +279,44 @@ func ConvertPermission2DTO(permission *model.Permission) *apisv1.PermissionBase
 		return nil
 	}
 	return &apisv1.PermissionBase{
-		Name:       permission.Name,
-		Alias:      permission.Alias,
-		Resources:  permission.Resources,
-		Actions:    permission.Actions,
-		Effect:     permission.Effect,
-		CreateTime: permission.CreateTime,
-		UpdateTime: permission.UpdateTime,
+		Name:            permission.Name,
+		Alias:           permission.Alias,
+		Resources:       permission.Resources,
+		Actions:         permission.Actions,
+		Effect:          permission.Effect,
+		Priority:        permission.Priority,
+		CreateTime:      permission.CreateTime,
+		UpdateTime:      permission.UpdateTime,
+		ResourceVersion: permission.ResourceVersion,
+	}
+}
+
+// ConvertProjectRoleTemplate2DTO convert project role template model to the DTO
+func ConvertProjectRoleTemplate2DTO(template *model.ProjectRoleTemplate) *apisv1.ProjectRoleTemplateBase {
+	return &apisv1.ProjectRoleTemplateBase{
+		CreateTime:  template.CreateTime,
+		UpdateTime:  template.UpdateTime,
+		Name:        template.Name,
+		Alias:       template.Alias,
+		Permissions: template.Permissions,
 	}
 }
 
 // ConvertTrigger2DTO convert trigger model to the DTO
 func ConvertTrigger2DTO(trigger model.ApplicationTrigger) *apisv1.ApplicationTriggerBase {
 	return &apisv1.ApplicationTriggerBase{
-		WorkflowName:  trigger.WorkflowName,
-		Name:          trigger.Name,
-		Alias:         trigger.Alias,
-		Description:   trigger.Description,
-		Type:          trigger.Type,
-		PayloadType:   trigger.PayloadType,
-		Token:         trigger.Token,
-		Registry:      trigger.Registry,
-		ComponentName: trigger.ComponentName,
-		CreateTime:    trigger.CreateTime,
-		UpdateTime:    trigger.UpdateTime,
+		WorkflowName:      trigger.WorkflowName,
+		Name:              trigger.Name,
+		Alias:             trigger.Alias,
+		Description:       trigger.Description,
+		Type:              trigger.Type,
+		PayloadType:       trigger.PayloadType,
+		Token:             trigger.Token,
+		Registry:          trigger.Registry,
+		ComponentName:     trigger.ComponentName,
+		SignatureRequired: trigger.Secret != "",
+		CreateTime:        trigger.CreateTime,
+		UpdateTime:        trigger.UpdateTime,
 	}
 }
 
@@ -298,3 +326,20 @@ func convertBool(b *bool) bool {
 	}
 	return *b
 }
+
+// ConvertRBACApproval2DTO convert rbac approval model to the DTO
+func ConvertRBACApproval2DTO(approval *model.RBACApproval) *apisv1.RBACApprovalBase {
+	return &apisv1.RBACApprovalBase{
+		Name:         approval.Name,
+		Kind:         approval.Kind,
+		Operation:    approval.Operation,
+		ResourceName: approval.ResourceName,
+		Payload:      approval.Payload,
+		Requester:    approval.Requester,
+		Status:       approval.Status,
+		Reviewer:     approval.Reviewer,
+		Comment:      approval.Comment,
+		CreateTime:   approval.CreateTime,
+		UpdateTime:   approval.UpdateTime,
+	}
+}
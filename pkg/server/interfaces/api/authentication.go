@@ -25,12 +25,18 @@ import (
 
 	"github.com/kubevela/velaux/pkg/server/domain/service"
 	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
 
 type authentication struct {
 	AuthenticationService service.AuthenticationService `inject:""`
 	UserService           service.UserService           `inject:""`
+	PasswordResetService  service.PasswordResetService  `inject:""`
+	APITokenService       service.APITokenService       `inject:""`
+	SessionService        service.SessionService        `inject:""`
+	UserInvitationService service.UserInvitationService `inject:""`
+	BootstrapService      service.BootstrapService      `inject:""`
 }
 
 // NewAuthentication is the  of authentication
@@ -78,15 +84,114 @@ func (c *authentication) GetWebServiceRoute() *restful.WebService {
 
 	ws.Route(ws.GET("/user_info").To(c.getLoginUserInfo).
 		Doc("get login user detail info").
-		Filter(authCheckFilter).
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Returns(200, "", apis.LoginUserInfoResponse{}).
 		Returns(400, "", bcode.Bcode{}).
 		Writes(apis.LoginUserInfoResponse{}))
+
+	ws.Route(ws.POST("/password-reset").To(c.requestPasswordReset).
+		Doc("send the password reset token to the email of the user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.PasswordResetRequest{}).
+		Returns(200, "", apis.EmptyResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.POST("/password-reset/confirm").To(c.confirmPasswordReset).
+		Doc("set the new password with the password reset token").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.ConfirmPasswordResetRequest{}).
+		Returns(200, "", apis.EmptyResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/invitations/{invitationID}").To(c.getInvitation).
+		Doc("get the invitation of the new user by the token in the invite link").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("invitationID", "identifier of the invitation").DataType("string")).
+		Param(ws.QueryParameter("token", "the token in the invite link").DataType("string").Required(true)).
+		Returns(200, "", apis.UserInvitationBase{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.UserInvitationBase{}))
+
+	ws.Route(ws.POST("/invitations/{invitationID}/accept").To(c.acceptInvitation).
+		Doc("accept the invitation, the user is created with the invited email and roles").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("invitationID", "identifier of the invitation").DataType("string")).
+		Reads(apis.AcceptUserInvitationRequest{}).
+		Returns(200, "", apis.UserBase{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.UserBase{}))
+
+	ws.Route(ws.GET("/bootstrap").To(c.getBootstrapStatus).
+		Doc("get whether the admin user waits to be initialized with the bootstrap token").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "", apis.BootstrapStatusResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.BootstrapStatusResponse{}))
+
+	ws.Route(ws.POST("/bootstrap").To(c.bootstrap).
+		Doc("initialize the admin user and optionally the SSO with the one-time bootstrap token").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.BootstrapRequest{}).
+		Returns(200, "", apis.UserBase{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.UserBase{}))
+
+	ws.Route(ws.GET("/tokens").To(c.listAPITokens).
+		Doc("list the personal API tokens of the login user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "", apis.ListAPITokensResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ListAPITokensResponse{}))
+
+	ws.Route(ws.POST("/tokens").To(c.createAPIToken).
+		Doc("create a personal API token, the token is only returned once").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.CreateAPITokenRequest{}).
+		Returns(200, "", apis.CreateAPITokenResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.CreateAPITokenResponse{}))
+
+	ws.Route(ws.DELETE("/tokens/{tokenName}").To(c.revokeAPIToken).
+		Doc("revoke a personal API token").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("tokenName", "identifier of the API token").DataType("string")).
+		Returns(200, "", apis.EmptyResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/sessions").To(c.listSessions).
+		Doc("list the active sessions of the login user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "", apis.ListSessionsResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ListSessionsResponse{}))
+
+	ws.Route(ws.DELETE("/sessions").To(c.revokeSessions).
+		Doc("revoke all sessions of the login user except the current one").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "", apis.EmptyResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.DELETE("/sessions/{sessionID}").To(c.revokeSession).
+		Doc("revoke a session of the login user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("sessionID", "identifier of the session").DataType("string")).
+		Returns(200, "", apis.EmptyResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Filter(authCheckFilter)
 	return ws
 }
 
 func authCheckFilter(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+	if publicRoutes.contains(req) {
+		chain.ProcessFilter(req, res)
+		return
+	}
 	// support getting the token from the cookie
 	var tokenValue string
 	tokenHeader := req.HeaderParameter("Authorization")
@@ -99,7 +204,7 @@ func authCheckFilter(req *restful.Request, res *restful.Response, chain *restful
 		tokenValue = splitted[1]
 	}
 	if tokenValue == "" {
-		if strings.HasPrefix(req.Request.URL.Path, "/view") {
+		if strings.HasPrefix(req.Request.URL.Path, "/view") || isChangeEventRequest(req) {
 			tokenValue = req.QueryParameter("token")
 		}
 		if tokenValue == "" {
@@ -108,17 +213,14 @@ func authCheckFilter(req *restful.Request, res *restful.Response, chain *restful
 		}
 	}
 
-	token, err := service.ParseToken(tokenValue)
+	username, sessionID, err := authenticateToken(withClientInfo(req), tokenValue)
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
 	}
-	if token.GrantType != service.GrantTypeAccess {
-		bcode.ReturnError(req, res, bcode.ErrNotAccessToken)
-		return
-	}
-	req.Request = req.Request.WithContext(context.WithValue(req.Request.Context(), &apis.CtxKeyUser, token.Username))
+	req.Request = req.Request.WithContext(context.WithValue(req.Request.Context(), &apis.CtxKeyUser, username))
 	req.Request = req.Request.WithContext(context.WithValue(req.Request.Context(), &apis.CtxKeyToken, tokenValue))
+	req.Request = req.Request.WithContext(context.WithValue(req.Request.Context(), &apis.CtxKeySession, sessionID))
 
 	chain.ProcessFilter(req, res)
 }
@@ -129,7 +231,7 @@ func (c *authentication) login(req *restful.Request, res *restful.Response) {
 		bcode.ReturnError(req, res, err)
 		return
 	}
-	base, err := c.AuthenticationService.Login(req.Request.Context(), loginReq)
+	base, err := c.AuthenticationService.Login(withClientInfo(req), loginReq)
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -140,6 +242,31 @@ func (c *authentication) login(req *restful.Request, res *restful.Response) {
 	}
 }
 
+// authenticateToken checks the API token, or the access token and its session, and returns the login user
+func authenticateToken(ctx context.Context, tokenValue string) (username, sessionID string, err error) {
+	if service.IsAPIToken(tokenValue) {
+		username, err = service.ParseAPIToken(ctx, tokenValue)
+		return username, "", err
+	}
+	token, err := service.ParseToken(tokenValue)
+	if err != nil {
+		return "", "", err
+	}
+	if token.GrantType != service.GrantTypeAccess {
+		return "", "", bcode.ErrNotAccessToken
+	}
+	if err := service.CheckSession(ctx, token); err != nil {
+		return "", "", err
+	}
+	return token.Username, token.SessionID, nil
+}
+
+// withClientInfo carries the IP and the user agent of the client for the login and password reset throttles and the security events
+func withClientInfo(req *restful.Request) context.Context {
+	ctx := utils.WithClientIP(req.Request.Context(), utils.TrustedClientIP(req.Request))
+	return utils.WithUserAgent(ctx, req.Request.UserAgent())
+}
+
 func (c *authentication) getDexConfig(req *restful.Request, res *restful.Response) {
 	base, err := c.AuthenticationService.GetDexConfig(req.Request.Context())
 	if err != nil {
@@ -187,3 +314,190 @@ func (c *authentication) getLoginUserInfo(req *restful.Request, res *restful.Res
 		return
 	}
 }
+
+func (c *authentication) requestPasswordReset(req *restful.Request, res *restful.Response) {
+	var resetReq apis.PasswordResetRequest
+	if err := req.ReadEntity(&resetReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&resetReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := c.PasswordResetService.RequestPasswordReset(withClientInfo(req), resetReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) confirmPasswordReset(req *restful.Request, res *restful.Response) {
+	var confirmReq apis.ConfirmPasswordResetRequest
+	if err := req.ReadEntity(&confirmReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&confirmReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := c.PasswordResetService.ConfirmPasswordReset(withClientInfo(req), confirmReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) listAPITokens(req *restful.Request, res *restful.Response) {
+	tokens, err := c.APITokenService.ListAPITokens(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(tokens); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) createAPIToken(req *restful.Request, res *restful.Response) {
+	var createReq apis.CreateAPITokenRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	token, err := c.APITokenService.CreateAPIToken(withClientInfo(req), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(token); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) revokeAPIToken(req *restful.Request, res *restful.Response) {
+	if err := c.APITokenService.RevokeAPIToken(req.Request.Context(), req.PathParameter("tokenName")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) listSessions(req *restful.Request, res *restful.Response) {
+	username, _ := req.Request.Context().Value(&apis.CtxKeyUser).(string)
+	sessions, err := c.SessionService.ListSessions(req.Request.Context(), username)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(sessions); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) revokeSessions(req *restful.Request, res *restful.Response) {
+	username, _ := req.Request.Context().Value(&apis.CtxKeyUser).(string)
+	if err := c.SessionService.RevokeSessions(req.Request.Context(), username, true); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) revokeSession(req *restful.Request, res *restful.Response) {
+	username, _ := req.Request.Context().Value(&apis.CtxKeyUser).(string)
+	if err := c.SessionService.RevokeSession(req.Request.Context(), username, req.PathParameter("sessionID")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) getInvitation(req *restful.Request, res *restful.Response) {
+	invitation, err := c.UserInvitationService.GetInvitation(req.Request.Context(), req.PathParameter("invitationID"), req.QueryParameter("token"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(invitation); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) acceptInvitation(req *restful.Request, res *restful.Response) {
+	var acceptReq apis.AcceptUserInvitationRequest
+	if err := req.ReadEntity(&acceptReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&acceptReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	user, err := c.UserInvitationService.AcceptInvitation(req.Request.Context(), req.PathParameter("invitationID"), acceptReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(user); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) getBootstrapStatus(req *restful.Request, res *restful.Response) {
+	status, err := c.BootstrapService.GetBootstrapStatus(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(status); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *authentication) bootstrap(req *restful.Request, res *restful.Response) {
+	var bootstrapReq apis.BootstrapRequest
+	if err := req.ReadEntity(&bootstrapReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&bootstrapReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	user, err := c.BootstrapService.Bootstrap(req.Request.Context(), bootstrapReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(user); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"time"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+	"github.com/gorilla/websocket"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/changefeed"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	changeEventBuffer       = 64
+	changeEventPingInterval = 30 * time.Second
+	changeEventWriteTimeout = 10 * time.Second
+)
+
+var changeEventUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+type changeEvent struct {
+	ChangeFeed  *changefeed.Feed    `inject:"changeFeed"`
+	UserService service.UserService `inject:""`
+}
+
+// NewChangeEvent is the API to push the changes of the resources to the UI
+func NewChangeEvent() Interface {
+	return &changeEvent{}
+}
+
+func (c *changeEvent) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/events").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for the change events")
+
+	tags := []string{"event"}
+
+	ws.Route(ws.GET("/").To(c.watch).
+		Doc("watch the changes of the resources the login user could access by the websocket").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", changefeed.Event{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(changefeed.Event{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+func (c *changeEvent) watch(req *restful.Request, res *restful.Response) {
+	info, err := c.UserService.DetailLoginUserInfo(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	projects := make(map[string]bool, len(info.Projects))
+	for _, project := range info.Projects {
+		projects[project.Name] = true
+	}
+	platform := len(info.PlatformPermissions) > 0
+	token, _ := req.Request.Context().Value(&apis.CtxKeyToken).(string)
+	org := datastore.TenantFromContext(req.Request.Context())
+	visible := func(event changefeed.Event) bool {
+		if event.Org != org {
+			return false
+		}
+		if event.Project == "" {
+			return platform
+		}
+		return projects[event.Project]
+	}
+
+	conn, err := changeEventUpgrader.Upgrade(res.ResponseWriter, req.Request, nil)
+	if err != nil {
+		klog.Errorf("failed to upgrade the change event request: %s", err.Error())
+		return
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			klog.Warningf("failed to close the change event connection: %s", err.Error())
+		}
+	}()
+	events, cancel := c.ChangeFeed.Subscribe(changeEventBuffer)
+	defer cancel()
+
+	// the messages from the client are discarded, reading detects the closed connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	ticker := time.NewTicker(changeEventPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !visible(event) {
+				continue
+			}
+			if err := conn.SetWriteDeadline(time.Now().Add(changeEventWriteTimeout)); err != nil {
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			// the subscription is closed once the token expires or the session is revoked
+			if _, _, err := authenticateToken(req.Request.Context(), token); err != nil {
+				message := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "the login is expired or revoked")
+				_ = conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(changeEventWriteTimeout))
+				return
+			}
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(changeEventWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// isChangeEventRequest checks whether the request watches the change events by the websocket, the browsers could not set
+// the Authorization header of the websocket requests so the token is read from the query
+func isChangeEventRequest(req *restful.Request) bool {
+	return req.SelectedRoutePath() == versionPrefix+"/events/" && websocket.IsWebSocketUpgrade(req.Request)
+}
@@ -166,6 +166,42 @@ func (c *Cluster) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.CreateCloudClusterResponse{}))
 
+	ws.Route(ws.POST("/capi_clusters").To(c.createCAPICluster).
+		Doc("create a workload cluster with the cluster api").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.CreateCAPIClusterRequest{}).
+		Filter(c.RbacService.CheckPerm("cluster", "create")).
+		Returns(200, "OK", apis.CAPIClusterBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.CAPIClusterBase{}))
+
+	ws.Route(ws.GET("/capi_clusters").To(c.listCAPIClusters).
+		Doc("list the workload clusters created with the cluster api").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("cluster", "list")).
+		Returns(200, "OK", apis.ListCAPIClustersResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListCAPIClustersResponse{}))
+
+	ws.Route(ws.PUT("/capi_clusters/{clusterName}/scale").To(c.scaleCAPICluster).
+		Doc("scale the control plane or the workers of a workload cluster").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("cluster", "update")).
+		Param(ws.PathParameter("clusterName", "identifier of the cluster").DataType("string")).
+		Reads(apis.ScaleCAPIClusterRequest{}).
+		Returns(200, "OK", apis.CAPIClusterBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.CAPIClusterBase{}))
+
+	ws.Route(ws.DELETE("/capi_clusters/{clusterName}").To(c.deleteCAPICluster).
+		Doc("detach and delete a workload cluster").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("cluster", "delete")).
+		Param(ws.PathParameter("clusterName", "identifier of the cluster").DataType("string")).
+		Returns(200, "OK", apis.CAPIClusterBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.CAPIClusterBase{}))
+
 	ws.Filter(authCheckFilter)
 	return ws
 }
@@ -447,3 +483,79 @@ func (c *Cluster) deleteCloudClusterCreation(req *restful.Request, res *restful.
 		return
 	}
 }
+
+func (c *Cluster) createCAPICluster(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateCAPIClusterRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+
+	// Call the domain layer code
+	resp, err := c.ClusterService.CreateCAPICluster(req.Request.Context(), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+
+	// Write back response data
+	if err := res.WriteEntity(resp); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *Cluster) listCAPIClusters(req *restful.Request, res *restful.Response) {
+	resp, err := c.ClusterService.ListCAPIClusters(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(resp); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *Cluster) scaleCAPICluster(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var scaleReq apis.ScaleCAPIClusterRequest
+	if err := req.ReadEntity(&scaleReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&scaleReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+
+	// Call the domain layer code
+	resp, err := c.ClusterService.ScaleCAPICluster(req.Request.Context(), req.PathParameter("clusterName"), scaleReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+
+	// Write back response data
+	if err := res.WriteEntity(resp); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *Cluster) deleteCAPICluster(req *restful.Request, res *restful.Response) {
+	resp, err := c.ClusterService.DeleteCAPICluster(req.Request.Context(), req.PathParameter("clusterName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(resp); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
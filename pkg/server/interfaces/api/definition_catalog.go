@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// NewDefinitionCatalog returns the definition catalog web service
+func NewDefinitionCatalog() Interface {
+	return &definitionCatalog{}
+}
+
+type definitionCatalog struct {
+	DefinitionCatalogService service.DefinitionCatalogService `inject:""`
+	RbacService              service.RBACService              `inject:""`
+}
+
+func (d *definitionCatalog) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/definition_catalogs").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for definition catalog management")
+
+	tags := []string{"definition_catalog"}
+
+	ws.Route(ws.GET("/").To(d.listDefinitionCatalogs).
+		Doc("list all definition catalogs").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(d.RbacService.CheckPerm("definitionCatalog", "list")).
+		Returns(200, "OK", apis.ListDefinitionCatalogsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListDefinitionCatalogsResponse{}))
+
+	ws.Route(ws.POST("/").To(d.createDefinitionCatalog).
+		Doc("register a Git repository as a definition catalog").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.CreateDefinitionCatalogRequest{}).
+		Filter(d.RbacService.CheckPerm("definitionCatalog", "create")).
+		Returns(200, "OK", apis.DefinitionCatalogBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.DefinitionCatalogBase{}))
+
+	ws.Route(ws.GET("/{catalogName}").To(d.detailDefinitionCatalog).
+		Doc("detail a definition catalog").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("catalogName", "identifier of the definition catalog").DataType("string")).
+		Filter(d.RbacService.CheckPerm("definitionCatalog", "detail")).
+		Returns(200, "OK", apis.DefinitionCatalogBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.DefinitionCatalogBase{}))
+
+	ws.Route(ws.PUT("/{catalogName}").To(d.updateDefinitionCatalog).
+		Doc("update a definition catalog").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("catalogName", "identifier of the definition catalog").DataType("string")).
+		Reads(apis.UpdateDefinitionCatalogRequest{}).
+		Filter(d.RbacService.CheckPerm("definitionCatalog", "update")).
+		Returns(200, "OK", apis.DefinitionCatalogBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.DefinitionCatalogBase{}))
+
+	ws.Route(ws.DELETE("/{catalogName}").To(d.deleteDefinitionCatalog).
+		Doc("delete a definition catalog, the imported definitions are kept").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("catalogName", "identifier of the definition catalog").DataType("string")).
+		Filter(d.RbacService.CheckPerm("definitionCatalog", "delete")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.POST("/{catalogName}/sync").To(d.syncDefinitionCatalog).
+		Doc("import the definitions from the latest commit of the catalog").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("catalogName", "identifier of the definition catalog").DataType("string")).
+		Filter(d.RbacService.CheckPerm("definitionCatalog", "sync")).
+		Returns(200, "OK", apis.DefinitionCatalogBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.DefinitionCatalogBase{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+func (d *definitionCatalog) listDefinitionCatalogs(req *restful.Request, res *restful.Response) {
+	catalogs, err := d.DefinitionCatalogService.ListDefinitionCatalogs(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(catalogs); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (d *definitionCatalog) createDefinitionCatalog(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateDefinitionCatalogRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Call the domain layer code
+	catalog, err := d.DefinitionCatalogService.CreateDefinitionCatalog(req.Request.Context(), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Write back response data
+	if err := res.WriteEntity(catalog); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (d *definitionCatalog) detailDefinitionCatalog(req *restful.Request, res *restful.Response) {
+	catalog, err := d.DefinitionCatalogService.GetDefinitionCatalog(req.Request.Context(), req.PathParameter("catalogName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(catalog); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (d *definitionCatalog) updateDefinitionCatalog(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var updateReq apis.UpdateDefinitionCatalogRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Call the domain layer code
+	catalog, err := d.DefinitionCatalogService.UpdateDefinitionCatalog(req.Request.Context(), req.PathParameter("catalogName"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Write back response data
+	if err := res.WriteEntity(catalog); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (d *definitionCatalog) deleteDefinitionCatalog(req *restful.Request, res *restful.Response) {
+	if err := d.DefinitionCatalogService.DeleteDefinitionCatalog(req.Request.Context(), req.PathParameter("catalogName")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (d *definitionCatalog) syncDefinitionCatalog(req *restful.Request, res *restful.Response) {
+	catalog, err := d.DefinitionCatalogService.SyncDefinitionCatalog(req.Request.Context(), req.PathParameter("catalogName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(catalog); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
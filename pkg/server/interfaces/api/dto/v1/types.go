@@ -58,6 +58,8 @@ var (
 	CtxKeyProject = "project"
 	// CtxKeyToken request context key of request token
 	CtxKeyToken = "token"
+	// CtxKeySession request context key of the login session
+	CtxKeySession = "session"
 	// CtxKeyPipeline request context key of pipeline
 	CtxKeyPipeline = "pipeline"
 	// CtxKeyPipelineContext request context key of pipeline context
@@ -197,6 +199,37 @@ type AddonStatusResponse struct {
 	AllClusters []NameAlias                       `json:"allClusters,omitempty"`
 }
 
+// CreateAddonRolloutRequest the request to enable or upgrade an addon across the clusters one by one
+type CreateAddonRolloutRequest struct {
+	Action string `json:"action" validate:"oneof=enable upgrade"`
+	// Clusters the names of the clusters
+	Clusters []string `json:"clusters,omitempty" optional:"true"`
+	// ClusterSelector selects a group of the clusters by their labels, the selected clusters are added to the Clusters
+	ClusterSelector map[string]string      `json:"clusterSelector,omitempty" optional:"true"`
+	Args            map[string]interface{} `json:"args,omitempty" optional:"true"`
+	Version         string                 `json:"version,omitempty" optional:"true"`
+	RegistryName    string                 `json:"registryName,omitempty" optional:"true"`
+}
+
+// AddonRolloutBase the addon rollout and the progress of each cluster
+type AddonRolloutBase struct {
+	Name         string                      `json:"name"`
+	Addon        string                      `json:"addon"`
+	Action       string                      `json:"action"`
+	Version      string                      `json:"version,omitempty"`
+	RegistryName string                      `json:"registryName,omitempty"`
+	Clusters     []model.AddonRolloutCluster `json:"clusters"`
+	Phase        string                      `json:"phase"`
+	Creator      string                      `json:"creator,omitempty"`
+	CreateTime   time.Time                   `json:"createTime"`
+	UpdateTime   time.Time                   `json:"updateTime"`
+}
+
+// ListAddonRolloutsResponse the response of listing the rollouts of an addon
+type ListAddonRolloutsResponse struct {
+	Rollouts []*AddonRolloutBase `json:"rollouts"`
+}
+
 // EnablingProgress defines the progress of enabling an addon
 type EnablingProgress struct {
 	EnabledComponents int `json:"enabled_components"`
@@ -370,6 +403,58 @@ type ListCloudClusterCreationResponse struct {
 	Creations []CreateCloudClusterResponse `json:"creations"`
 }
 
+// CreateCAPIClusterRequest request parameters to create a workload cluster with the Cluster API
+type CreateCAPIClusterRequest struct {
+	Name                 string            `json:"name" validate:"checkname"`
+	Alias                string            `json:"alias" optional:"true" validate:"checkalias"`
+	Description          string            `json:"description,omitempty" optional:"true"`
+	Labels               map[string]string `json:"labels,omitempty" optional:"true"`
+	Namespace            string            `json:"namespace,omitempty" optional:"true"`
+	ClusterClass         string            `json:"clusterClass" validate:"required"`
+	KubernetesVersion    string            `json:"kubernetesVersion" validate:"required"`
+	ControlPlaneReplicas int64             `json:"controlPlaneReplicas" optional:"true"`
+	Workers              []CAPIWorkerPool  `json:"workers,omitempty" optional:"true"`
+	// Target creates a target of the project when the cluster is joined
+	Target *CAPIClusterTarget `json:"target,omitempty" optional:"true"`
+}
+
+// CAPIWorkerPool the machine deployment of the workload cluster
+type CAPIWorkerPool struct {
+	Name     string `json:"name" validate:"checkname"`
+	Class    string `json:"class,omitempty" optional:"true"`
+	Replicas int64  `json:"replicas"`
+}
+
+// CAPIClusterTarget the target to create when the workload cluster is joined
+type CAPIClusterTarget struct {
+	Project   string `json:"project" validate:"checkname"`
+	Namespace string `json:"namespace" validate:"checkname"`
+}
+
+// ScaleCAPIClusterRequest request parameters to scale a workload cluster
+type ScaleCAPIClusterRequest struct {
+	ControlPlaneReplicas *int64           `json:"controlPlaneReplicas,omitempty" optional:"true"`
+	Workers              []CAPIWorkerPool `json:"workers,omitempty" optional:"true"`
+}
+
+// CAPIClusterBase the lifecycle status of a workload cluster
+type CAPIClusterBase struct {
+	Name                 string           `json:"name"`
+	Namespace            string           `json:"namespace"`
+	ClusterClass         string           `json:"clusterClass"`
+	KubernetesVersion    string           `json:"kubernetesVersion"`
+	ControlPlaneReplicas int64            `json:"controlPlaneReplicas"`
+	Workers              []CAPIWorkerPool `json:"workers"`
+	Phase                string           `json:"phase"`
+	Joined               bool             `json:"joined"`
+	CreateTime           time.Time        `json:"createTime"`
+}
+
+// ListCAPIClustersResponse list the workload clusters
+type ListCAPIClustersResponse struct {
+	Clusters []CAPIClusterBase `json:"clusters"`
+}
+
 // ClusterBase cluster base model
 type ClusterBase struct {
 	Name        string            `json:"name"`
@@ -413,6 +498,27 @@ type ApplicationBase struct {
 	Icon        string            `json:"icon"`
 	Labels      map[string]string `json:"labels,omitempty"`
 	ReadOnly    bool              `json:"readOnly,omitempty"`
+	// Notice the maintenance notice of the application
+	Notice *model.ApplicationNotice `json:"notice,omitempty"`
+	// ManagedExternally the application is imported from the cluster and could only be updated by its source
+	ManagedExternally bool `json:"managedExternally,omitempty"`
+	// SyncedStatus the status of the imported application
+	SyncedStatus *model.SyncedApplicationStatus `json:"syncedStatus,omitempty"`
+	// Ownership who to contact about the application
+	Ownership *model.Ownership `json:"ownership,omitempty"`
+	// Conflicts the environments whose application CR is modified outside VelaUX
+	Conflicts []model.ApplicationConflict `json:"conflicts,omitempty"`
+}
+
+// ListApplicationConflictsResponse the applications whose CRs are modified outside VelaUX
+type ListApplicationConflictsResponse struct {
+	Applications []*ApplicationBase `json:"applications"`
+}
+
+// UpdateApplicationNoticeRequest the request body to attach a notice to the application
+type UpdateApplicationNoticeRequest struct {
+	Message string `json:"message" validate:"required,max=1024"`
+	Level   string `json:"level" validate:"oneof=warning blocking"`
 }
 
 // AppCompareResponse application compare result
@@ -473,6 +579,30 @@ type ApplicationStatusResponse struct {
 	Status  *common.AppStatus `json:"status"`
 }
 
+// ApplicationChangeReport the changes of the application in the env since it was healthy last time, the earliest change is the first
+type ApplicationChangeReport struct {
+	EnvName string                  `json:"envName"`
+	Phase   common.ApplicationPhase `json:"phase"`
+	Healthy bool                    `json:"healthy"`
+	// LastHealthyRevision the latest completed revision, it is empty if no revision is completed in the env
+	LastHealthyRevision string `json:"lastHealthyRevision,omitempty"`
+	// Since the changes after the time are reported, the completion time of the last healthy revision or the creation time of the application
+	Since   time.Time            `json:"since"`
+	Changes []*ApplicationChange `json:"changes"`
+}
+
+// ApplicationChange a revision, a spec update, a definition upgrade or a warning event in the cluster
+type ApplicationChange struct {
+	Time time.Time `json:"time"`
+	// Kind revision, config, definition or event
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Message  string `json:"message"`
+	Operator string `json:"operator,omitempty"`
+	// Changes the changed fields of the config changes
+	Changes []model.SpecChange `json:"changes,omitempty"`
+}
+
 // ApplicationStatisticsResponse application statistics response body
 type ApplicationStatisticsResponse struct {
 	EnvCount      int64 `json:"envCount"`
@@ -491,6 +621,7 @@ type CreateApplicationRequest struct {
 	Labels      map[string]string       `json:"labels,omitempty"`
 	EnvBinding  []*EnvBinding           `json:"envBinding,omitempty"`
 	Component   *CreateComponentRequest `json:"component"`
+	Ownership   *model.Ownership        `json:"ownership,omitempty" optional:"true"`
 }
 
 // UpdateApplicationRequest update application base config
@@ -499,6 +630,8 @@ type UpdateApplicationRequest struct {
 	Description string            `json:"description" optional:"true"`
 	Icon        string            `json:"icon" optional:"true"`
 	Labels      map[string]string `json:"labels,omitempty"`
+	// Ownership is kept if it is nil, and removed if all fields are empty
+	Ownership *model.Ownership `json:"ownership,omitempty" optional:"true"`
 }
 
 // CreateApplicationTriggerRequest create application trigger
@@ -511,6 +644,8 @@ type CreateApplicationTriggerRequest struct {
 	PayloadType   string `json:"payloadType" validate:"checkpayloadtype"`
 	ComponentName string `json:"componentName,omitempty" optional:"true"`
 	Registry      string `json:"registry,omitempty" optional:"true"`
+	// Secret the shared secret to sign the deliveries, the deliveries must be signed if it is set
+	Secret string `json:"secret,omitempty" optional:"true"`
 }
 
 // UpdateApplicationTriggerRequest update application trigger
@@ -521,21 +656,25 @@ type UpdateApplicationTriggerRequest struct {
 	PayloadType   string `json:"payloadType" validate:"checkpayloadtype"`
 	ComponentName string `json:"componentName,omitempty" optional:"true"`
 	Registry      string `json:"registry,omitempty" optional:"true"`
+	// Secret the secret is kept if it is nil and removed if it is empty
+	Secret *string `json:"secret,omitempty" optional:"true"`
 }
 
 // ApplicationTriggerBase application trigger base model
 type ApplicationTriggerBase struct {
-	Name          string    `json:"name"`
-	Alias         string    `json:"alias,omitempty"`
-	Description   string    `json:"description,omitempty"`
-	WorkflowName  string    `json:"workflowName"`
-	Type          string    `json:"type"`
-	PayloadType   string    `json:"payloadType"`
-	Token         string    `json:"token"`
-	ComponentName string    `json:"componentName,omitempty"`
-	Registry      string    `json:"registry"`
-	CreateTime    time.Time `json:"createTime"`
-	UpdateTime    time.Time `json:"updateTime"`
+	Name          string `json:"name"`
+	Alias         string `json:"alias,omitempty"`
+	Description   string `json:"description,omitempty"`
+	WorkflowName  string `json:"workflowName"`
+	Type          string `json:"type"`
+	PayloadType   string `json:"payloadType"`
+	Token         string `json:"token"`
+	ComponentName string `json:"componentName,omitempty"`
+	Registry      string `json:"registry"`
+	// SignatureRequired the deliveries must be signed by the secret of the trigger
+	SignatureRequired bool      `json:"signatureRequired"`
+	CreateTime        time.Time `json:"createTime"`
+	UpdateTime        time.Time `json:"updateTime"`
 }
 
 // ListApplicationTriggerResponse list application triggers response body
@@ -543,6 +682,20 @@ type ListApplicationTriggerResponse struct {
 	Triggers []*ApplicationTriggerBase `json:"triggers"`
 }
 
+// TriggerRejectedDeliveryBase a delivery of the trigger rejected by the signature verification or the replay protection
+type TriggerRejectedDeliveryBase struct {
+	Name       string    `json:"name"`
+	Reason     string    `json:"reason"`
+	ClientIP   string    `json:"clientIP,omitempty"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+// ListTriggerRejectedDeliveriesResponse the response body that list the rejected deliveries of a trigger
+type ListTriggerRejectedDeliveriesResponse struct {
+	Deliveries []*TriggerRejectedDeliveryBase `json:"deliveries"`
+	Total      int64                          `json:"total"`
+}
+
 // HandleApplicationTriggerWebhookRequest handles application trigger webhook request
 type HandleApplicationTriggerWebhookRequest struct {
 	Upgrade  map[string]*model.JSONStruct `json:"upgrade,omitempty"`
@@ -731,6 +884,24 @@ type ComponentBase struct {
 	Outputs       workflowv1alpha1.StepOutputs  `json:"outputs,omitempty"`
 	Traits        []*ApplicationTrait           `json:"traits"`
 	WorkloadType  common.WorkloadTypeDescriptor `json:"workloadType,omitempty"`
+	// LintIssues the violated lint rules, it is only returned when the component is saved
+	LintIssues []LintIssue `json:"lintIssues,omitempty"`
+}
+
+// LintIssue a violation of the best-practice rules of the component spec
+type LintIssue struct {
+	Rule string `json:"rule"`
+	// Severity is error, warning or info, the component with the error issues could not be saved
+	Severity  string `json:"severity"`
+	Component string `json:"component"`
+	// Path the path of the field that violates the rule, such as properties.image
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// ApplicationLintResponse the lint issues of all the components of the application
+type ApplicationLintResponse struct {
+	Issues []LintIssue `json:"issues"`
 }
 
 // ComponentListResponse list component
@@ -806,13 +977,50 @@ type ListProjectResponse struct {
 
 // ProjectBase project base model
 type ProjectBase struct {
-	Name        string    `json:"name"`
-	Alias       string    `json:"alias"`
-	Description string    `json:"description"`
-	CreateTime  time.Time `json:"createTime"`
-	UpdateTime  time.Time `json:"updateTime"`
-	Owner       NameAlias `json:"owner,omitempty"`
-	Namespace   string    `json:"namespace"`
+	Name        string            `json:"name"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description"`
+	CreateTime  time.Time         `json:"createTime"`
+	UpdateTime  time.Time         `json:"updateTime"`
+	Owner       NameAlias         `json:"owner,omitempty"`
+	Namespace   string            `json:"namespace"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Quota       *ProjectQuota     `json:"quota,omitempty"`
+	// Lock the active lock of the project, it is empty if the project is not locked
+	Lock *model.ProjectLock `json:"lock,omitempty"`
+}
+
+// LockProjectRequest the request body to lock a project
+type LockProjectRequest struct {
+	Reason string `json:"reason" validate:"required,max=256"`
+	// DurationMinutes how long the project is locked, at most 30 days
+	DurationMinutes int `json:"durationMinutes" validate:"min=1,max=43200"`
+}
+
+// ProjectLockEventBase a lock or an unlock of a project
+type ProjectLockEventBase struct {
+	Name       string    `json:"name"`
+	Project    string    `json:"project"`
+	Type       string    `json:"type"`
+	Reason     string    `json:"reason,omitempty"`
+	ExpireTime time.Time `json:"expireTime,omitempty"`
+	Operator   string    `json:"operator,omitempty"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+// ListProjectLockEventsResponse the response body that list the lock events of a project
+type ListProjectLockEventsResponse struct {
+	Events []*ProjectLockEventBase `json:"events"`
+	Total  int64                   `json:"total"`
+}
+
+// ProjectQuota the soft limits of the resources in a project, the zero value means unlimited
+type ProjectQuota struct {
+	Applications int64 `json:"applications,omitempty" validate:"min=0"`
+	Pipelines    int64 `json:"pipelines,omitempty" validate:"min=0"`
+	Targets      int64 `json:"targets,omitempty" validate:"min=0"`
+	// ServiceInstances the service instances requested from the catalog, the quota is enforced
+	ServiceInstances int64 `json:"serviceInstances,omitempty" validate:"min=0"`
 }
 
 // CreateProjectRequest create project request body
@@ -822,14 +1030,36 @@ type CreateProjectRequest struct {
 	Description string `json:"description" optional:"true"`
 	Owner       string `json:"owner" optional:"true"`
 	// the namespace to save the pipelines belong to this project.
-	Namespace string `json:"namespace" optional:"true"`
+	Namespace string            `json:"namespace" optional:"true"`
+	Labels    map[string]string `json:"labels,omitempty" optional:"true"`
+	Quota     *ProjectQuota     `json:"quota,omitempty" optional:"true"`
 }
 
 // UpdateProjectRequest update a project request body
 type UpdateProjectRequest struct {
-	Alias       string `json:"alias" validate:"checkalias" optional:"true"`
-	Description string `json:"description" optional:"true"`
-	Owner       string `json:"owner" optional:"true"`
+	Alias       string            `json:"alias" validate:"checkalias" optional:"true"`
+	Description string            `json:"description" optional:"true"`
+	Owner       string            `json:"owner" optional:"true"`
+	Labels      map[string]string `json:"labels,omitempty" optional:"true"`
+	Quota       *ProjectQuota     `json:"quota,omitempty" optional:"true"`
+}
+
+// ProjectResourceUsage the usage and the soft limit of a kind of resource in a project
+type ProjectResourceUsage struct {
+	// Resource option values: application, pipeline, target, serviceInstance
+	Resource string `json:"resource"`
+	Used     int64  `json:"used"`
+	// Limit the zero value means unlimited
+	Limit int64 `json:"limit"`
+	// Warning means the usage reaches the warning threshold of the limit
+	Warning bool `json:"warning"`
+}
+
+// ProjectQuotaUsageResponse the response body of the project quota usage
+type ProjectQuotaUsageResponse struct {
+	// WarningThreshold the percentage of the limit to warn the users
+	WarningThreshold int                    `json:"warningThreshold"`
+	Usages           []ProjectResourceUsage `json:"usages"`
 }
 
 // Env models the data of env in API
@@ -915,6 +1145,56 @@ type UpdateDefinitionStatusRequest struct {
 	HiddenInUI     bool   `json:"hiddenInUI"`
 }
 
+// CreateDefinitionCatalogRequest the request body to register a Git repository as a definition catalog
+type CreateDefinitionCatalogRequest struct {
+	Name        string `json:"name" validate:"checkname"`
+	Alias       string `json:"alias" validate:"checkalias" optional:"true"`
+	Description string `json:"description" optional:"true"`
+	URL         string `json:"url" validate:"required"`
+	Branch      string `json:"branch" optional:"true"`
+	Path        string `json:"path" optional:"true"`
+	Username    string `json:"username" optional:"true"`
+	Token       string `json:"token" optional:"true"`
+	// VerifyKeys the armored PGP public keys that sign the commits of the catalog
+	VerifyKeys string `json:"verifyKeys" optional:"true"`
+}
+
+// UpdateDefinitionCatalogRequest the request body to update a definition catalog
+type UpdateDefinitionCatalogRequest struct {
+	Alias       string `json:"alias" validate:"checkalias" optional:"true"`
+	Description string `json:"description" optional:"true"`
+	URL         string `json:"url" validate:"required"`
+	Branch      string `json:"branch" optional:"true"`
+	Path        string `json:"path" optional:"true"`
+	Username    string `json:"username" optional:"true"`
+	// Token keep the token if empty
+	Token      string `json:"token" optional:"true"`
+	VerifyKeys string `json:"verifyKeys" optional:"true"`
+}
+
+// DefinitionCatalogBase the definition catalog without the credentials
+type DefinitionCatalogBase struct {
+	Name         string                    `json:"name"`
+	Alias        string                    `json:"alias"`
+	Description  string                    `json:"description"`
+	URL          string                    `json:"url"`
+	Branch       string                    `json:"branch"`
+	Path         string                    `json:"path"`
+	Signed       bool                      `json:"signed"`
+	Revision     string                    `json:"revision"`
+	Definitions  []model.CatalogDefinition `json:"definitions"`
+	Status       string                    `json:"status"`
+	Message      string                    `json:"message"`
+	LastSyncTime time.Time                 `json:"lastSyncTime"`
+	CreateTime   time.Time                 `json:"createTime"`
+	UpdateTime   time.Time                 `json:"updateTime"`
+}
+
+// ListDefinitionCatalogsResponse the response body of listing the definition catalogs
+type ListDefinitionCatalogsResponse struct {
+	Catalogs []*DefinitionCatalogBase `json:"catalogs"`
+}
+
 // DefinitionBase is the definition base model
 type DefinitionBase struct {
 	Name        string            `json:"name"`
@@ -1135,6 +1415,29 @@ type WorkflowRecordBase struct {
 	Status              string    `json:"status"`
 	Message             string    `json:"message"`
 	Mode                string    `json:"mode"`
+	RequestID           string    `json:"requestID,omitempty"`
+	// BuildMetadata the metadata attached by the external CI system
+	BuildMetadata *model.BuildMetadata `json:"buildMetadata,omitempty"`
+	// Cluster the managed cluster running the workflow, it is empty if the workflow runs in the hub cluster
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// AnnotateWorkflowRecordRequest the request body to attach the external build metadata to the workflow record
+type AnnotateWorkflowRecordRequest struct {
+	Provider    string                  `json:"provider,omitempty" validate:"max=64"`
+	Commit      string                  `json:"commit,omitempty" validate:"max=128"`
+	Branch      string                  `json:"branch,omitempty" validate:"max=256"`
+	BuildURL    string                  `json:"buildURL,omitempty" validate:"omitempty,url"`
+	TestResults *model.BuildTestResults `json:"testResults,omitempty"`
+	// Labels merged into the existing labels, the empty value removes the label
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ListWorkflowRecordsOptions the options to search the workflow records by the build metadata
+type ListWorkflowRecordsOptions struct {
+	Commit   string `json:"commit"`
+	Branch   string `json:"branch"`
+	BuildURL string `json:"buildURL"`
 }
 
 // WorkflowRecord workflow record
@@ -1156,12 +1459,29 @@ type ApplicationDeployRequest struct {
 	CodeInfo *model.CodeInfo `json:"codeInfo,omitempty"`
 	// ImageInfo is the image code info of this deploy
 	ImageInfo *model.ImageInfo `json:"imageInfo,omitempty"`
+	// AcknowledgeNotice set to True to deploy the application with a blocking notice
+	AcknowledgeNotice bool `json:"acknowledgeNotice,omitempty"`
+}
+
+// ScaleApplicationRequest the request body to scale the components of the application in an env
+type ScaleApplicationRequest struct {
+	Components []ComponentReplicas `json:"components" validate:"required,min=1,dive"`
+	// Note the note message of the deploy triggered by the scale, optional
+	Note string `json:"note,omitempty" optional:"true"`
+}
+
+// ComponentReplicas the replicas of a component
+type ComponentReplicas struct {
+	Name     string `json:"name" validate:"checkname"`
+	Replicas int    `json:"replicas" validate:"min=0"`
 }
 
 // ApplicationDeployResponse application deploy response body
 type ApplicationDeployResponse struct {
 	ApplicationRevisionBase `json:",inline"`
 	WorkflowRecord          WorkflowRecordBase `json:"record"`
+	// Notice the notice of the application when deploying
+	Notice *model.ApplicationNotice `json:"notice,omitempty"`
 }
 
 // ApplicationRollbackResponse the response body that rollback with the revision
@@ -1220,6 +1540,47 @@ type ApplicationTrait struct {
 	UpdateTime time.Time         `json:"updateTime"`
 }
 
+// ApplicationDefaults the defaults applied to every new application created in the project
+type ApplicationDefaults struct {
+	Labels map[string]string `json:"labels,omitempty" optional:"true"`
+	// Annotations are added to the main component by the annotations trait
+	Annotations map[string]string `json:"annotations,omitempty" optional:"true"`
+	// Env the environment variables added to the main component by the env trait
+	Env map[string]string `json:"env,omitempty" optional:"true"`
+	// Traits the base traits added to the main component if it has no trait of the same type
+	Traits []ApplicationDefaultTrait `json:"traits,omitempty" optional:"true"`
+}
+
+// WorkflowRecordRetention the retention policy of the finished workflow records of a project, the zero value means unlimited
+type WorkflowRecordRetention struct {
+	// KeepRecords how many finished records of an application are kept
+	KeepRecords int `json:"keepRecords" validate:"min=0" optional:"true"`
+	// KeepDays how many days the finished records are kept
+	KeepDays int `json:"keepDays" validate:"min=0" optional:"true"`
+	// Archive exports the records to the object storage before they are deleted, the records are not pruned if the object storage is not configured
+	Archive bool `json:"archive" optional:"true"`
+}
+
+// ApplicationDefaultTrait the base trait of the new applications
+type ApplicationDefaultTrait struct {
+	Type string `json:"type" validate:"checkname"`
+	// Properties json data
+	Properties *model.JSONStruct `json:"properties,omitempty" optional:"true"`
+}
+
+// PreviewApplicationDefaultsRequest the application to preview the effective defaults of the project
+type PreviewApplicationDefaultsRequest struct {
+	Labels        map[string]string                `json:"labels,omitempty" optional:"true"`
+	ComponentType string                           `json:"componentType" validate:"checkname"`
+	Traits        []*CreateApplicationTraitRequest `json:"traits,omitempty" optional:"true"`
+}
+
+// PreviewApplicationDefaultsResponse the effective labels and main component traits of the new application
+type PreviewApplicationDefaultsResponse struct {
+	Labels map[string]string   `json:"labels,omitempty"`
+	Traits []*ApplicationTrait `json:"traits"`
+}
+
 // CreateTargetRequest  create delivery target request body
 type CreateTargetRequest struct {
 	Name        string                 `json:"name" validate:"checkname"`
@@ -1228,13 +1589,35 @@ type CreateTargetRequest struct {
 	Description string                 `json:"description,omitempty" optional:"true"`
 	Cluster     *ClusterTarget         `json:"cluster,omitempty"`
 	Variable    map[string]interface{} `json:"variable,omitempty"`
+	// NamespacePolicy is applied to the namespace of the target and kept reconciled
+	NamespacePolicy *TargetNamespacePolicy `json:"namespacePolicy,omitempty" optional:"true"`
 }
 
 // UpdateTargetRequest only support full quantity update
 type UpdateTargetRequest struct {
-	Alias       string                 `json:"alias,omitempty" validate:"checkalias" optional:"true"`
-	Description string                 `json:"description,omitempty" optional:"true"`
-	Variable    map[string]interface{} `json:"variable,omitempty"`
+	Alias           string                 `json:"alias,omitempty" validate:"checkalias" optional:"true"`
+	Description     string                 `json:"description,omitempty" optional:"true"`
+	Variable        map[string]interface{} `json:"variable,omitempty"`
+	NamespacePolicy *TargetNamespacePolicy `json:"namespacePolicy,omitempty" optional:"true"`
+}
+
+// TargetNamespacePolicy the labels, the annotations and the security presets of the namespace of the target
+type TargetNamespacePolicy struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// PodSecurity the Pod Security Standard enforced in the namespace, privileged, baseline or restricted
+	PodSecurity string `json:"podSecurity,omitempty"`
+	// NetworkPolicy the preset of the network policy created in the namespace, deny-all-ingress, deny-all or allow-same-namespace
+	NetworkPolicy string `json:"networkPolicy,omitempty"`
+}
+
+// NamespacePolicyDrift a difference between the namespace of the target and the namespace policy
+type NamespacePolicyDrift struct {
+	// Kind label, annotation or networkPolicy
+	Kind     string `json:"kind"`
+	Key      string `json:"key"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
 }
 
 // ClusterTarget kubernetes delivery target
@@ -1246,6 +1629,8 @@ type ClusterTarget struct {
 // DetailTargetResponse detail Target response
 type DetailTargetResponse struct {
 	TargetBase
+	// NamespaceDrift the differences from the namespace policy, they are fixed by the next reconciliation
+	NamespaceDrift []NamespacePolicyDrift `json:"namespaceDrift,omitempty"`
 }
 
 // ListTargetResponse list delivery target response body
@@ -1254,18 +1639,53 @@ type ListTargetResponse struct {
 	Total   int64        `json:"total"`
 }
 
+// WorkloadBase the workload that is not managed by any application in the target namespace
+type WorkloadBase struct {
+	// Kind option values: Deployment, StatefulSet
+	Kind       string    `json:"kind"`
+	Name       string    `json:"name"`
+	Namespace  string    `json:"namespace"`
+	Cluster    string    `json:"cluster"`
+	Replicas   int32     `json:"replicas"`
+	Images     []string  `json:"images"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+// ListWorkloadsResponse the response body of listing the unmanaged workloads
+type ListWorkloadsResponse struct {
+	Workloads []WorkloadBase `json:"workloads"`
+}
+
+// WorkloadRef the reference of a workload
+type WorkloadRef struct {
+	Kind string `json:"kind" validate:"oneof=Deployment StatefulSet"`
+	Name string `json:"name" validate:"required"`
+}
+
+// ImportWorkloadsRequest the request body to convert the existing workloads into an application,
+// each workload is converted to a component.
+type ImportWorkloadsRequest struct {
+	Name        string `json:"name" validate:"checkname"`
+	Alias       string `json:"alias" validate:"checkalias" optional:"true"`
+	Description string `json:"description" optional:"true"`
+	// EnvName the environment the application is bound to, it must contain the target
+	EnvName   string        `json:"envName" validate:"checkname"`
+	Workloads []WorkloadRef `json:"workloads" validate:"required,min=1,dive"`
+}
+
 // TargetBase Target base model
 type TargetBase struct {
-	Name         string                 `json:"name"`
-	Alias        string                 `json:"alias,omitempty" validate:"checkalias" optional:"true"`
-	Description  string                 `json:"description,omitempty" optional:"true"`
-	Cluster      *ClusterTarget         `json:"cluster,omitempty"`
-	ClusterAlias string                 `json:"clusterAlias,omitempty"`
-	Variable     map[string]interface{} `json:"variable,omitempty"`
-	CreateTime   time.Time              `json:"createTime"`
-	UpdateTime   time.Time              `json:"updateTime"`
-	AppNum       int64                  `json:"appNum,omitempty"`
-	Project      NameAlias              `json:"project"`
+	Name            string                 `json:"name"`
+	Alias           string                 `json:"alias,omitempty" validate:"checkalias" optional:"true"`
+	Description     string                 `json:"description,omitempty" optional:"true"`
+	Cluster         *ClusterTarget         `json:"cluster,omitempty"`
+	ClusterAlias    string                 `json:"clusterAlias,omitempty"`
+	Variable        map[string]interface{} `json:"variable,omitempty"`
+	CreateTime      time.Time              `json:"createTime"`
+	UpdateTime      time.Time              `json:"updateTime"`
+	AppNum          int64                  `json:"appNum,omitempty"`
+	Project         NameAlias              `json:"project"`
+	NamespacePolicy *TargetNamespacePolicy `json:"namespacePolicy,omitempty"`
 }
 
 // ApplicationRevisionBase application revision base spec
@@ -1285,6 +1705,10 @@ type ApplicationRevisionBase struct {
 	CodeInfo *model.CodeInfo `json:"codeInfo,omitempty"`
 	// ImageInfo is the image info of this application revision
 	ImageInfo *model.ImageInfo `json:"imageInfo,omitempty"`
+	// RequestID the ID of the API request that deploys the revision
+	RequestID string `json:"requestID,omitempty"`
+	// AcknowledgedNotice the blocking notice acknowledged when deploying the revision
+	AcknowledgedNotice string `json:"acknowledgedNotice,omitempty"`
 }
 
 // ListRevisionsResponse list application revisions
@@ -1297,6 +1721,17 @@ type ListRevisionsResponse struct {
 type DetailRevisionResponse struct {
 	model.ApplicationRevision
 	DeployUser NameAlias `json:"deployUser,omitempty"`
+	// Diff the operational changes compared with the previous revision of the same env, it is empty for the first revision
+	Diff *RevisionDiff `json:"diff,omitempty"`
+}
+
+// RevisionDiff the env values, the config references and the images changed by the revision,
+// the paths of the changes start with the component name
+type RevisionDiff struct {
+	PreviousVersion string             `json:"previousVersion"`
+	Env             []model.SpecChange `json:"env"`
+	ConfigRefs      []model.SpecChange `json:"configRefs"`
+	Images          []model.SpecChange `json:"images"`
 }
 
 // SystemInfoResponse get SystemInfo
@@ -1314,6 +1749,18 @@ type SystemInfo struct {
 	InstallTime                 time.Time          `json:"installTime,omitempty"`
 	DexUserDefaultProjects      []model.ProjectRef `json:"dexUserDefaultProjects,omitempty"`
 	DexUserDefaultPlatformRoles []string           `json:"dexUserDefaultPlatformRoles,omitempty"`
+	MaintenanceMode             bool               `json:"maintenanceMode"`
+	OfflineMode                 bool               `json:"offlineMode"`
+	// DegradedFeatures lists the features which are unavailable in the offline mode
+	DegradedFeatures   []string                  `json:"degradedFeatures,omitempty"`
+	OAuthConnectors    []OAuthConnector          `json:"oauthConnectors,omitempty"`
+	OAuthGroupMappings []model.OAuthGroupMapping `json:"oauthGroupMappings,omitempty"`
+	// SessionSettings the effective lifetime of the sessions, the defaults are filled
+	SessionSettings model.SessionSettings `json:"sessionSettings"`
+	// VelaAddress the address of VelaUX that the links in the emails point to
+	VelaAddress string `json:"velaAddress,omitempty"`
+	// LoginBanner the notice shown on the login page
+	LoginBanner *model.LoginBanner `json:"loginBanner,omitempty"`
 }
 
 // StatisticInfo generated by cronJob running in backend
@@ -1332,8 +1779,34 @@ type StatisticInfo struct {
 type SystemInfoRequest struct {
 	EnableCollection       bool               `json:"enableCollection"`
 	LoginType              string             `json:"loginType"`
-	VelaAddress            string             `json:"velaAddress,omitempty"`
+	VelaAddress            string             `json:"velaAddress,omitempty" validate:"omitempty,url"`
 	DexUserDefaultProjects []model.ProjectRef `json:"dexUserDefaultProjects,omitempty"`
+	// MaintenanceMode only allows the read requests during the upgrades and the incident freezes, the mode is kept if it is not set
+	MaintenanceMode *bool `json:"maintenanceMode,omitempty" optional:"true"`
+	// OfflineMode disables all outbound internet calls in the air-gapped environments, the mode is kept if it is not set
+	OfflineMode *bool `json:"offlineMode,omitempty" optional:"true"`
+	// OAuthConnectors replaces the GitHub and GitLab login connectors, the connectors are kept if it is not set
+	OAuthConnectors []OAuthConnector `json:"oauthConnectors,omitempty" validate:"dive" optional:"true"`
+	// OAuthGroupMappings replaces the group mappings, the mappings are kept if it is not set
+	OAuthGroupMappings []model.OAuthGroupMapping `json:"oauthGroupMappings,omitempty" optional:"true"`
+	// SessionSettings replaces the session settings, the settings are kept if it is not set
+	SessionSettings *model.SessionSettings `json:"sessionSettings,omitempty" optional:"true"`
+	// LoginBanner replaces the login notice, the notice is kept if it is not set and removed if the content is empty.
+	// The version is managed by the server.
+	LoginBanner *model.LoginBanner `json:"loginBanner,omitempty" optional:"true"`
+}
+
+// OAuthConnector the GitHub or GitLab login connector, the client secret is not returned
+type OAuthConnector struct {
+	Type string `json:"type" validate:"oneof=github gitlab"`
+	ID   string `json:"id" validate:"checkname"`
+	Name string `json:"name" optional:"true"`
+	// ClientID and ClientSecret the OAuth app registered in GitHub or GitLab, the callback URL is {velaAddress}/dex/callback
+	ClientID string `json:"clientID" validate:"required"`
+	// ClientSecret the secret of the existing connector is kept if it is empty
+	ClientSecret string   `json:"clientSecret,omitempty" optional:"true"`
+	BaseURL      string   `json:"baseURL,omitempty" optional:"true"`
+	Orgs         []string `json:"orgs,omitempty" optional:"true"`
 }
 
 // SystemVersion contains KubeVela version
@@ -1342,6 +1815,96 @@ type SystemVersion struct {
 	GitVersion  string `json:"gitVersion"`
 }
 
+// HealthResponse the status of VelaUX and its dependencies, it is reported by the /healthz and the /readyz
+type HealthResponse struct {
+	// Status is ok, degraded or down, it is down if any critical dependency is down
+	Status           string            `json:"status"`
+	Checks           []DependencyCheck `json:"checks"`
+	MaintenanceMode  bool              `json:"maintenanceMode"`
+	OfflineMode      bool              `json:"offlineMode"`
+	DegradedFeatures []string          `json:"degradedFeatures,omitempty"`
+	CheckTime        time.Time         `json:"checkTime"`
+}
+
+// DependencyCheck the status of a dependency
+type DependencyCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Critical VelaUX is not ready if the critical dependency is down
+	Critical  bool   `json:"critical"`
+	Message   string `json:"message,omitempty"`
+	LatencyMS int64  `json:"latencyMs"`
+}
+
+// VersionAdviceResponse the versions of the components across the clusters and the upgrade advices
+type VersionAdviceResponse struct {
+	VelaUXVersion string             `json:"velauxVersion"`
+	Components    []ComponentVersion `json:"components"`
+	Advices       []UpgradeAdvice    `json:"advices"`
+}
+
+// ComponentVersion the version of vela-core or a CRD in a cluster
+type ComponentVersion struct {
+	Cluster   string `json:"cluster"`
+	Component string `json:"component"`
+	Version   string `json:"version"`
+}
+
+// UpgradeAdvice a version skew which should be fixed, the level is error if the skew is not supported
+type UpgradeAdvice struct {
+	Level     string `json:"level"`
+	Cluster   string `json:"cluster"`
+	Component string `json:"component"`
+	Message   string `json:"message"`
+	Link      string `json:"link,omitempty"`
+}
+
+// SyncStatusResponse the status of the workers syncing the cluster state to VelaUX
+type SyncStatusResponse struct {
+	Workers []SyncWorkerStatus `json:"workers"`
+}
+
+// SyncWorkerStatus the status of a sync worker, the workers run on the leader replica only, so the worker is not running
+// on the other replicas
+type SyncWorkerStatus struct {
+	Name            string     `json:"name"`
+	Running         bool       `json:"running"`
+	LastSuccessTime *time.Time `json:"lastSuccessTime,omitempty"`
+	// Backlog the number of the resources pending to sync
+	Backlog int `json:"backlog"`
+	// LagSeconds how long the oldest pending resource has been waiting
+	LagSeconds float64             `json:"lagSeconds"`
+	ErrorCount int64               `json:"errorCount"`
+	Errors     []SyncResourceError `json:"errors"`
+}
+
+// SyncResourceError the failures of a resource since it is synced successfully last time
+type SyncResourceError struct {
+	Resource      string    `json:"resource"`
+	Count         int       `json:"count"`
+	Message       string    `json:"message"`
+	LastErrorTime time.Time `json:"lastErrorTime"`
+}
+
+// SyncScope the namespaces, the clusters and the labels of the external applications to sync, the empty lists match all
+// and the exclusions take precedence. The hub cluster is named local.
+type SyncScope struct {
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty" optional:"true"`
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty" optional:"true"`
+	IncludeClusters   []string `json:"includeClusters,omitempty" optional:"true"`
+	ExcludeClusters   []string `json:"excludeClusters,omitempty" optional:"true"`
+	LabelSelector     string   `json:"labelSelector,omitempty" optional:"true"`
+}
+
+// SyncScopeResponse the scope of the sync, it is set by the server config unless it is updated by the API
+type SyncScopeResponse struct {
+	SyncScope
+	// Source config or api
+	Source     string     `json:"source"`
+	UpdatedBy  string     `json:"updatedBy,omitempty"`
+	UpdateTime *time.Time `json:"updateTime,omitempty"`
+}
+
 // ChartVersionListResponse contains helm chart versions info
 type ChartVersionListResponse struct {
 	Versions repo.ChartVersions `json:"versions"`
@@ -1357,6 +1920,8 @@ type LoginRequest struct {
 	Code     string `json:"code,omitempty" optional:"true"`
 	Username string `json:"username,omitempty" optional:"true"`
 	Password string `json:"password,omitempty" optional:"true"`
+	// ConsentVersion the version of the login notice acknowledged by the user
+	ConsentVersion int `json:"consentVersion,omitempty" optional:"true"`
 }
 
 // LoginResponse is the response of login request
@@ -1387,10 +1952,66 @@ type DetailUserResponse struct {
 	Roles    []NameAlias    `json:"roles"`
 }
 
+// ChangeEmailRequest the request to send the verification token to the new email of the login user
+type ChangeEmailRequest struct {
+	Email string `json:"email" validate:"required,checkemail"`
+}
+
+// VerifyEmailRequest the request to change the email of the login user with the verification token
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// EmailChangeRecordBase the audit record of a verified email change
+type EmailChangeRecordBase struct {
+	Username    string    `json:"username"`
+	OldEmail    string    `json:"oldEmail,omitempty"`
+	NewEmail    string    `json:"newEmail"`
+	RequestTime time.Time `json:"requestTime"`
+	VerifyTime  time.Time `json:"verifyTime"`
+}
+
+// ListEmailChangeRecordsResponse the response of listing the email changes of a user
+type ListEmailChangeRecordsResponse struct {
+	Records []*EmailChangeRecordBase `json:"records"`
+}
+
+// LoginRecordBase is a login of the user
+type LoginRecordBase struct {
+	Time time.Time `json:"time"`
+	// Method is one of local, dex and token
+	Method    string `json:"method"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+}
+
+// ListLoginHistoryResponse the response of listing the login history of a user
+type ListLoginHistoryResponse struct {
+	Records []*LoginRecordBase `json:"records"`
+	Total   int64              `json:"total"`
+}
+
+// UserOwnedResource is a resource owned or created by a user
+type UserOwnedResource struct {
+	// Type is one of project, component, policy and statusBadge
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Project string `json:"project,omitempty"`
+	// Application is the primary key of the application that the component or the policy belongs to
+	Application string `json:"application,omitempty"`
+}
+
+// UserOwnedResourcesResponse is the response of listing the resources owned by a user
+type UserOwnedResourcesResponse struct {
+	Resources []UserOwnedResource `json:"resources"`
+	Total     int                 `json:"total"`
+}
+
 // ProjectUserBase project user base
 type ProjectUserBase struct {
 	UserName   string    `json:"name"`
 	UserAlias  string    `json:"alias"`
+	AvatarURL  string    `json:"avatarURL,omitempty"`
 	UserRoles  []string  `json:"userRoles"`
 	CreateTime time.Time `json:"createTime"`
 	UpdateTime time.Time `json:"updateTime"`
@@ -1402,6 +2023,201 @@ type ListProjectUsersResponse struct {
 	Total int64              `json:"total"`
 }
 
+// ProjectMemberEventBase a change of the project members or the project roles, it is also the payload of the project member webhook
+type ProjectMemberEventBase struct {
+	Name        string    `json:"name"`
+	Project     string    `json:"project"`
+	Type        string    `json:"type"`
+	Username    string    `json:"username,omitempty"`
+	Roles       []string  `json:"roles,omitempty"`
+	Permissions []string  `json:"permissions,omitempty"`
+	Operator    string    `json:"operator,omitempty"`
+	CreateTime  time.Time `json:"createTime"`
+}
+
+// ListProjectMemberEventsResponse the response body that list the member events of a project
+type ListProjectMemberEventsResponse struct {
+	Events []*ProjectMemberEventBase `json:"events"`
+	Total  int64                     `json:"total"`
+}
+
+// SpecAuditBase an update of the spec of an application or a pipeline
+type SpecAuditBase struct {
+	Name        string             `json:"name"`
+	Resource    string             `json:"resource"`
+	Project     string             `json:"project"`
+	Entity      string             `json:"entity"`
+	SubResource string             `json:"subResource,omitempty"`
+	Changes     []model.SpecChange `json:"changes"`
+	Operator    string             `json:"operator,omitempty"`
+	CreateTime  time.Time          `json:"createTime"`
+}
+
+// ListSpecAuditsResponse the response body that list the spec updates of an application or a pipeline
+type ListSpecAuditsResponse struct {
+	Audits []*SpecAuditBase `json:"audits"`
+	Total  int64            `json:"total"`
+}
+
+// SearchHit a document matched by the search, the source is the indexed spec audit or step log
+type SearchHit struct {
+	ID     string          `json:"id"`
+	Source json.RawMessage `json:"source"`
+}
+
+// SearchResponse the response of searching the audits or the logs of a project
+type SearchResponse struct {
+	Hits  []*SearchHit `json:"hits"`
+	Total int64        `json:"total"`
+}
+
+// SearchEntityHit an entity matched by the global search
+type SearchEntityHit struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Alias       string `json:"alias,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Project the project of the application, the pipeline or the config, it is empty for the users and the platform configs
+	Project string `json:"project,omitempty"`
+}
+
+// SearchEntitiesResponse the entities matched by the global search, the best matches of each kind are listed first
+type SearchEntitiesResponse struct {
+	Hits []*SearchEntityHit `json:"hits"`
+}
+
+// TrashItemBase a deleted entity in the trash
+type TrashItemBase struct {
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Project   string    `json:"project,omitempty"`
+	Entity    string    `json:"entity"`
+	Alias     string    `json:"alias,omitempty"`
+	DeletedAt time.Time `json:"deletedAt"`
+	DeletedBy string    `json:"deletedBy,omitempty"`
+	// PurgeAt the item is deleted permanently after the time
+	PurgeAt time.Time `json:"purgeAt"`
+}
+
+// ListTrashResponse the items in the trash that the login user could read, the latest deleted first
+type ListTrashResponse struct {
+	Items []*TrashItemBase `json:"items"`
+	Total int64            `json:"total"`
+}
+
+// InboxItem a pending work item that waits for the action of the login user
+type InboxItem struct {
+	// Kind option values: rbacApproval, serviceInstance, workflowRecord, pipelineRun, invitation
+	Kind    string `json:"kind"`
+	Project string `json:"project,omitempty"`
+	Name    string `json:"name"`
+	// Entity the role or the permission to change, the application of the service instance or the workflow record,
+	// the pipeline of the run or the email of the invitation
+	Entity     string    `json:"entity,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	Requester  string    `json:"requester,omitempty"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+// InboxResponse the pending work of the login user across all projects, the latest first
+type InboxResponse struct {
+	Items []*InboxItem `json:"items"`
+	Total int64        `json:"total"`
+}
+
+// CreateStatusBadgeRequest the request to create a public status badge
+type CreateStatusBadgeRequest struct {
+	// EnvName the environment of the application that the health is shown, it is required by the application badge
+	EnvName string `json:"envName,omitempty" optional:"true"`
+}
+
+// UpdateStatusBadgeRequest the request to enable or disable a status badge
+type UpdateStatusBadgeRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// StatusBadgeBase the public status badge of an application or a pipeline
+type StatusBadgeBase struct {
+	ID         string    `json:"id"`
+	Resource   string    `json:"resource"`
+	Project    string    `json:"project"`
+	Entity     string    `json:"entity"`
+	EnvName    string    `json:"envName,omitempty"`
+	Enabled    bool      `json:"enabled"`
+	Creator    string    `json:"creator,omitempty"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+// CreateStatusBadgeResponse the created badge, the token is only returned once and it is a part of the badge URL
+type CreateStatusBadgeResponse struct {
+	StatusBadgeBase `json:",inline"`
+	Token           string `json:"token"`
+}
+
+// ListStatusBadgesResponse the response body that list the status badges of an application or a pipeline
+type ListStatusBadgesResponse struct {
+	Badges []*StatusBadgeBase `json:"badges"`
+}
+
+// StatusBadgeResponse the content of the badge, the format is compatible with the shields.io endpoint badge
+type StatusBadgeResponse struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// ServiceClassBase a kind of the managed services in the catalog, based on a terraform or crossplane component definition
+type ServiceClassBase struct {
+	Name        string            `json:"name"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description"`
+	Icon        string            `json:"icon"`
+	Labels      map[string]string `json:"labels"`
+	// Provisioner option values: terraform, crossplane
+	Provisioner string `json:"provisioner"`
+}
+
+// ListServiceClassesResponse the service classes in the catalog
+type ListServiceClassesResponse struct {
+	ServiceClasses []*ServiceClassBase `json:"serviceClasses"`
+	// ApprovalRequired whether the service instances take effect after the approval
+	ApprovalRequired bool `json:"approvalRequired"`
+}
+
+// CreateServiceInstanceRequest the request body to request a managed service bound to an application
+type CreateServiceInstanceRequest struct {
+	Name         string `json:"name" validate:"checkname"`
+	ServiceClass string `json:"serviceClass" validate:"checkname"`
+	AppName      string `json:"appName" validate:"checkname"`
+	Properties   string `json:"properties,omitempty" optional:"true"`
+}
+
+// ReviewServiceInstanceRequest the request body to approve or reject a service instance
+type ReviewServiceInstanceRequest struct {
+	Comment string `json:"comment,omitempty" optional:"true"`
+}
+
+// ServiceInstanceBase the service instance base struct
+type ServiceInstanceBase struct {
+	Name         string    `json:"name"`
+	Project      string    `json:"project"`
+	ServiceClass string    `json:"serviceClass"`
+	AppName      string    `json:"appName"`
+	Properties   string    `json:"properties,omitempty"`
+	Status       string    `json:"status"`
+	Requester    string    `json:"requester"`
+	Reviewer     string    `json:"reviewer,omitempty"`
+	Comment      string    `json:"comment,omitempty"`
+	CreateTime   time.Time `json:"createTime"`
+	UpdateTime   time.Time `json:"updateTime"`
+}
+
+// ListServiceInstancesResponse the service instances of a project
+type ListServiceInstancesResponse struct {
+	ServiceInstances []*ServiceInstanceBase `json:"serviceInstances"`
+}
+
 // CreateUserRequest create user request
 type CreateUserRequest struct {
 	Name     string   `json:"name" validate:"checkname"`
@@ -1409,6 +2225,10 @@ type CreateUserRequest struct {
 	Email    string   `json:"email" validate:"checkemail"`
 	Password string   `json:"password" validate:"checkpassword"`
 	Roles    []string `json:"roles"`
+	// DeactivateAt disables the user automatically at the time
+	DeactivateAt *time.Time `json:"deactivateAt,omitempty" optional:"true"`
+	// MustChangePassword requires the user to change the password at the first login, default is true
+	MustChangePassword *bool `json:"mustChangePassword,omitempty" optional:"true"`
 }
 
 // UpdateUserRequest update user request
@@ -1417,6 +2237,154 @@ type UpdateUserRequest struct {
 	Password string    `json:"password,omitempty" validate:"checkpassword" optional:"true"`
 	Email    string    `json:"email,omitempty" validate:"checkemail" optional:"true"`
 	Roles    *[]string `json:"roles"`
+	// DeactivateAt schedules or reschedules the deactivation of the user
+	DeactivateAt *time.Time `json:"deactivateAt,omitempty" optional:"true"`
+	// CancelDeactivation cancels the scheduled deactivation
+	CancelDeactivation bool `json:"cancelDeactivation,omitempty" optional:"true"`
+	// MustChangePassword requires the user to change the new password at the next login, it only takes effect with the password
+	MustChangePassword bool `json:"mustChangePassword,omitempty" optional:"true"`
+}
+
+// ChangePasswordRequest the request of the login user to change the password
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"checkpassword"`
+}
+
+// PasswordResetRequest the request to send the password reset token to the email of the user
+type PasswordResetRequest struct {
+	Username string `json:"username,omitempty" validate:"required_without=Email" optional:"true"`
+	Email    string `json:"email,omitempty" validate:"checkemail" optional:"true"`
+}
+
+// ConfirmPasswordResetRequest the request to set the new password with the password reset token
+type ConfirmPasswordResetRequest struct {
+	Username string `json:"username" validate:"required"`
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,checkpassword"`
+}
+
+// CreateUserInvitationRequest the request to invite a new user by the email
+type CreateUserInvitationRequest struct {
+	Email         string                         `json:"email" validate:"required,checkemail"`
+	Alias         string                         `json:"alias,omitempty" validate:"checkalias" optional:"true"`
+	PlatformRoles []string                       `json:"platformRoles,omitempty" optional:"true"`
+	ProjectRoles  []model.InvitationProjectRoles `json:"projectRoles,omitempty" optional:"true"`
+	// ExpireDays how many days the invitation is valid, default is 7
+	ExpireDays int `json:"expireDays,omitempty" validate:"min=0,max=30" optional:"true"`
+}
+
+// UserInvitationBase the invitation of a new user, the token is only sent to the email
+type UserInvitationBase struct {
+	ID            string                         `json:"id"`
+	Email         string                         `json:"email"`
+	Alias         string                         `json:"alias,omitempty"`
+	PlatformRoles []string                       `json:"platformRoles,omitempty"`
+	ProjectRoles  []model.InvitationProjectRoles `json:"projectRoles,omitempty"`
+	Inviter       string                         `json:"inviter,omitempty"`
+	CreateTime    time.Time                      `json:"createTime"`
+	ExpireTime    time.Time                      `json:"expireTime"`
+}
+
+// ListUserInvitationsResponse the response body that list the pending invitations
+type ListUserInvitationsResponse struct {
+	Invitations []*UserInvitationBase `json:"invitations"`
+}
+
+// AcceptUserInvitationRequest the request to create the invited user with the chosen name and password
+type AcceptUserInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Name     string `json:"name" validate:"checkname"`
+	Alias    string `json:"alias,omitempty" validate:"checkalias" optional:"true"`
+	Password string `json:"password" validate:"checkpassword"`
+}
+
+// BootstrapRequest the request to initialize the admin user with the one-time bootstrap token
+type BootstrapRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Alias    string `json:"alias,omitempty" validate:"checkalias" optional:"true"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"checkpassword"`
+	// SSO switches the login type to dex, the dex connectors must be configured
+	SSO *BootstrapSSORequest `json:"sso,omitempty" optional:"true"`
+}
+
+// BootstrapSSORequest the SSO config to set during the bootstrap
+type BootstrapSSORequest struct {
+	VelaAddress            string             `json:"velaAddress" validate:"required"`
+	DexUserDefaultProjects []model.ProjectRef `json:"dexUserDefaultProjects,omitempty" optional:"true"`
+}
+
+// BootstrapStatusResponse the response body of the bootstrap status
+type BootstrapStatusResponse struct {
+	// Pending is true if the admin user waits to be initialized with the bootstrap token
+	Pending bool `json:"pending"`
+}
+
+// UserPreferenceBase the preferences of the login user
+type UserPreferenceBase struct {
+	// DefaultProject the project to open after the login
+	DefaultProject string `json:"defaultProject,omitempty"`
+	Language       string `json:"language,omitempty"`
+	Theme          string `json:"theme,omitempty"`
+	ItemsPerPage   int    `json:"itemsPerPage,omitempty"`
+	// Notifications the notifications that the user does not want to receive
+	Notifications model.NotificationPreference `json:"notifications"`
+}
+
+// UpdateUserPreferenceRequest the request to replace the preferences of the login user
+type UpdateUserPreferenceRequest struct {
+	DefaultProject string                       `json:"defaultProject,omitempty" optional:"true"`
+	Language       string                       `json:"language,omitempty" validate:"omitempty,oneof=en zh" optional:"true"`
+	Theme          string                       `json:"theme,omitempty" validate:"omitempty,oneof=light dark auto" optional:"true"`
+	ItemsPerPage   int                          `json:"itemsPerPage,omitempty" validate:"min=0,max=100" optional:"true"`
+	Notifications  model.NotificationPreference `json:"notifications" optional:"true"`
+}
+
+// CreateAPITokenRequest the request to create a personal API token
+type CreateAPITokenRequest struct {
+	Name string `json:"name" validate:"checkname"`
+	// ExpireDays how many days the token is valid, default is 30
+	ExpireDays int `json:"expireDays,omitempty" validate:"min=0,max=365" optional:"true"`
+}
+
+// APITokenBase the base info of the personal API token
+type APITokenBase struct {
+	Name       string    `json:"name"`
+	CreateTime time.Time `json:"createTime"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// CreateAPITokenResponse the response of creating the API token, the token is only returned once
+type CreateAPITokenResponse struct {
+	APITokenBase
+	Token string `json:"token"`
+}
+
+// ListAPITokensResponse the response of listing the API tokens
+type ListAPITokensResponse struct {
+	Tokens []*APITokenBase `json:"tokens"`
+}
+
+// SessionBase the login session of the user
+type SessionBase struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	CreateTime time.Time `json:"createTime"`
+	LastSeen   time.Time `json:"lastSeen"`
+	ExpireTime time.Time `json:"expireTime"`
+	// Current means the session is used by the request
+	Current bool `json:"current"`
+}
+
+// ListSessionsResponse the response of listing the sessions of the user
+type ListSessionsResponse struct {
+	Sessions []*SessionBase `json:"sessions"`
+	// MaxSessions how many active sessions the user could have, 0 means unlimited
+	MaxSessions int `json:"maxSessions"`
+	// EvictOldest the oldest sessions are revoked by the new login if the limit is reached
+	EvictOldest bool `json:"evictOldest"`
 }
 
 // ListUserResponse list user response
@@ -1427,12 +2395,26 @@ type ListUserResponse struct {
 
 // UserBase is the base info of user
 type UserBase struct {
-	CreateTime    time.Time `json:"createTime"`
-	LastLoginTime time.Time `json:"lastLoginTime"`
-	Name          string    `json:"name"`
-	Email         string    `json:"email"`
-	Alias         string    `json:"alias,omitempty"`
-	Disabled      bool      `json:"disabled"`
+	CreateTime    time.Time  `json:"createTime"`
+	LastLoginTime time.Time  `json:"lastLoginTime"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Alias         string     `json:"alias,omitempty"`
+	Disabled      bool       `json:"disabled"`
+	DeactivateAt  *time.Time `json:"deactivateAt,omitempty"`
+	// MustChangePassword the user must change the password before using the other APIs
+	MustChangePassword bool `json:"mustChangePassword,omitempty"`
+	// ConsentVersion and ConsentTime the version of the login notice that the user acknowledged at the last login
+	ConsentVersion int        `json:"consentVersion,omitempty"`
+	ConsentTime    *time.Time `json:"consentTime,omitempty"`
+	// AvatarURL the uploaded avatar or the Gravatar of the email, it is empty if the user has neither
+	AvatarURL string `json:"avatarURL,omitempty"`
+}
+
+// UploadAvatarRequest the request body to upload the avatar of the login user
+type UploadAvatarRequest struct {
+	// Image the base64 encoded PNG, JPEG, GIF or WebP image
+	Image []byte `json:"image" validate:"required"`
 }
 
 // ListUserOptions list user options
@@ -1445,6 +2427,8 @@ type ListUserOptions struct {
 // GetLoginTypeResponse get login type response
 type GetLoginTypeResponse struct {
 	LoginType string `json:"loginType"`
+	// LoginBanner the notice shown on the login page, it is empty if there is no notice
+	LoginBanner *model.LoginBanner `json:"loginBanner,omitempty"`
 }
 
 // AddProjectUserRequest the request body that add user to project
@@ -1463,21 +2447,39 @@ type CreateRoleRequest struct {
 	Name        string   `json:"name" validate:"checkname"`
 	Alias       string   `json:"alias" validate:"checkalias"`
 	Permissions []string `json:"permissions"`
+	// Projects and ProjectSelector only take effect for the cross-project roles
+	Projects        []string          `json:"projects,omitempty" optional:"true"`
+	ProjectSelector map[string]string `json:"projectSelector,omitempty" optional:"true"`
 }
 
 // UpdateRoleRequest the request body that update a role
 type UpdateRoleRequest struct {
-	Alias       string   `json:"alias" validate:"checkalias"`
-	Permissions []string `json:"permissions"`
+	Alias           string            `json:"alias" validate:"checkalias"`
+	Permissions     []string          `json:"permissions"`
+	Projects        []string          `json:"projects,omitempty" optional:"true"`
+	ProjectSelector map[string]string `json:"projectSelector,omitempty" optional:"true"`
+	// ResourceVersion the version of the role read by the client, the update is rejected if the role has been modified since then
+	ResourceVersion int64 `json:"resourceVersion,omitempty" optional:"true"`
+}
+
+// GrantTargetsRequest the request to grant a platform role the access to the targets and clusters
+type GrantTargetsRequest struct {
+	Targets  []string `json:"targets,omitempty" optional:"true"`
+	Clusters []string `json:"clusters,omitempty" optional:"true"`
+	// Actions the actions to grant, default is all actions
+	Actions []string `json:"actions,omitempty" optional:"true"`
 }
 
 // RoleBase the base struct of role
 type RoleBase struct {
-	CreateTime  time.Time   `json:"createTime"`
-	UpdateTime  time.Time   `json:"updateTime"`
-	Name        string      `json:"name"`
-	Alias       string      `json:"alias,omitempty"`
-	Permissions []NameAlias `json:"permissions"`
+	CreateTime      time.Time         `json:"createTime"`
+	UpdateTime      time.Time         `json:"updateTime"`
+	Name            string            `json:"name"`
+	Alias           string            `json:"alias,omitempty"`
+	Permissions     []NameAlias       `json:"permissions"`
+	Projects        []string          `json:"projects,omitempty"`
+	ProjectSelector map[string]string `json:"projectSelector,omitempty"`
+	ResourceVersion int64             `json:"resourceVersion,omitempty"`
 }
 
 // ListRolesResponse the response body of list roles
@@ -1486,6 +2488,104 @@ type ListRolesResponse struct {
 	Roles []*RoleBase `json:"roles"`
 }
 
+// ListRoleOptions list role options
+type ListRoleOptions struct {
+	Name string `json:"name"`
+	// SortBy the field to sort the roles, name or createTime, default is createTime
+	SortBy string `json:"sortBy"`
+	// Order the order of the sorting, asc or desc, default is desc
+	Order string `json:"order"`
+}
+
+// ProjectRoleTemplateBase the base struct of project role template
+type ProjectRoleTemplateBase struct {
+	CreateTime  time.Time `json:"createTime"`
+	UpdateTime  time.Time `json:"updateTime"`
+	Name        string    `json:"name"`
+	Alias       string    `json:"alias,omitempty"`
+	Permissions []string  `json:"permissions"`
+}
+
+// ListProjectRoleTemplatesResponse the response body of list project role templates
+type ListProjectRoleTemplatesResponse struct {
+	Templates []*ProjectRoleTemplateBase `json:"templates"`
+}
+
+// CreateProjectRoleTemplateRequest the request body that create a project role template
+type CreateProjectRoleTemplateRequest struct {
+	Name        string   `json:"name" validate:"checkname"`
+	Alias       string   `json:"alias" validate:"checkalias"`
+	Permissions []string `json:"permissions"`
+	// SyncToProjects means creating the role in all existing projects
+	SyncToProjects bool `json:"syncToProjects"`
+}
+
+// UpdateProjectRoleTemplateRequest the request body that update a project role template
+type UpdateProjectRoleTemplateRequest struct {
+	Alias       string   `json:"alias" validate:"checkalias"`
+	Permissions []string `json:"permissions"`
+	// SyncToProjects means updating the role in all existing projects
+	SyncToProjects bool `json:"syncToProjects"`
+}
+
+// RBACApprovalBase the sensitive change of the platform roles or permissions that waits for the approval
+type RBACApprovalBase struct {
+	Name string `json:"name"`
+	// Kind option values: role, permission
+	Kind string `json:"kind"`
+	// Operation option values: create, update
+	Operation    string `json:"operation"`
+	ResourceName string `json:"resourceName"`
+	// Payload the request body of the change
+	Payload    string    `json:"payload"`
+	Requester  string    `json:"requester"`
+	Status     string    `json:"status"`
+	Reviewer   string    `json:"reviewer,omitempty"`
+	Comment    string    `json:"comment,omitempty"`
+	CreateTime time.Time `json:"createTime"`
+	UpdateTime time.Time `json:"updateTime"`
+}
+
+// ListRBACApprovalsResponse the response body of listing the rbac approvals
+type ListRBACApprovalsResponse struct {
+	Approvals []*RBACApprovalBase `json:"approvals"`
+}
+
+// SimulateCapabilitiesRequest the roles and permissions to simulate, they are project level if the project name is set
+type SimulateCapabilitiesRequest struct {
+	ProjectName string   `json:"projectName" optional:"true"`
+	Roles       []string `json:"roles" optional:"true"`
+	Permissions []string `json:"permissions" optional:"true"`
+}
+
+// UICapability the menu of the UI and whether it is visible
+type UICapability struct {
+	Name     string `json:"name"`
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	Visible  bool   `json:"visible"`
+}
+
+// ResourceActionCapability whether the action of the resource is allowed
+type ResourceActionCapability struct {
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+	Allowed  bool   `json:"allowed"`
+}
+
+// SimulateCapabilitiesResponse the capabilities of the users with the simulated roles and permissions
+type SimulateCapabilitiesResponse struct {
+	// Permissions the permissions resolved from the roles and the permission names
+	Permissions     []PermissionBase           `json:"permissions"`
+	Menus           []UICapability             `json:"menus"`
+	ResourceActions []ResourceActionCapability `json:"resourceActions"`
+}
+
+// ReviewRBACApprovalRequest the request body of approving or rejecting a change
+type ReviewRBACApprovalRequest struct {
+	Comment string `json:"comment" optional:"true"`
+}
+
 // PermissionTemplateBase the perm policy template base struct
 type PermissionTemplateBase struct {
 	Name       string    `json:"name"`
@@ -1497,15 +2597,66 @@ type PermissionTemplateBase struct {
 	UpdateTime time.Time `json:"updateTime"`
 }
 
+// ListPermissionOptions list perm policy options
+type ListPermissionOptions struct {
+	Name string `json:"name"`
+	// SortBy the field to sort the perm policies, name or createTime, default is createTime
+	SortBy string `json:"sortBy"`
+	// Order the order of the sorting, asc or desc, default is desc
+	Order string `json:"order"`
+}
+
+// ListPermissionsResponse the response body of list perm policies
+type ListPermissionsResponse struct {
+	Total       int64            `json:"total"`
+	Permissions []PermissionBase `json:"permissions"`
+}
+
 // PermissionBase the perm policy base struct
 type PermissionBase struct {
-	Name       string    `json:"name"`
-	Alias      string    `json:"alias"`
-	Resources  []string  `json:"resources"`
-	Actions    []string  `json:"actions"`
-	Effect     string    `json:"effect"`
-	CreateTime time.Time `json:"createTime"`
-	UpdateTime time.Time `json:"updateTime"`
+	Name            string    `json:"name"`
+	Alias           string    `json:"alias"`
+	Resources       []string  `json:"resources"`
+	Actions         []string  `json:"actions"`
+	Effect          string    `json:"effect"`
+	Priority        int       `json:"priority"`
+	CreateTime      time.Time `json:"createTime"`
+	UpdateTime      time.Time `json:"updateTime"`
+	ResourceVersion int64     `json:"resourceVersion,omitempty"`
+}
+
+// UserPermissionSnapshotResponse the effective roles and permissions of a user at a point in time
+type UserPermissionSnapshotResponse struct {
+	Username string    `json:"username"`
+	Time     time.Time `json:"time"`
+	// SnapshotTime the time of the snapshot that was effective at the time
+	SnapshotTime  time.Time                    `json:"snapshotTime"`
+	Disabled      bool                         `json:"disabled"`
+	Removed       bool                         `json:"removed"`
+	PlatformRoles []string                     `json:"platformRoles"`
+	ProjectRoles  []model.ProjectRolesSnapshot `json:"projectRoles"`
+	Permissions   []model.PermissionRecord     `json:"permissions"`
+}
+
+// PermissionReferencesResponse the roles and users that depend on a permission
+type PermissionReferencesResponse struct {
+	Roles []PermissionReferenceRole `json:"roles"`
+	Users []PermissionReferenceUser `json:"users"`
+}
+
+// PermissionReferenceRole the role that includes the permission
+type PermissionReferenceRole struct {
+	Name    string `json:"name"`
+	Alias   string `json:"alias"`
+	Project string `json:"project,omitempty"`
+}
+
+// PermissionReferenceUser the user that is granted the permission, the indirect users are granted by the platform roles in the project
+type PermissionReferenceUser struct {
+	Name     string   `json:"name"`
+	Alias    string   `json:"alias"`
+	Roles    []string `json:"roles"`
+	Indirect bool     `json:"indirect"`
 }
 
 // UpdatePermissionRequest the request body that updating a permission policy
@@ -1514,6 +2665,10 @@ type UpdatePermissionRequest struct {
 	Resources []string `json:"resources"`
 	Actions   []string `json:"actions"`
 	Effect    string   `json:"effect" validate:"oneof=Allow Deny"`
+	// Priority the policies with the higher priority are evaluated first, it is kept if not set
+	Priority *int `json:"priority,omitempty" optional:"true"`
+	// ResourceVersion the version of the permission read by the client, the update is rejected if the permission has been modified since then
+	ResourceVersion int64 `json:"resourceVersion,omitempty" optional:"true"`
 }
 
 // CreatePermissionRequest the request body that creating a permission policy
@@ -1523,6 +2678,20 @@ type CreatePermissionRequest struct {
 	Resources []string `json:"resources"`
 	Actions   []string `json:"actions"`
 	Effect    string   `json:"effect" validate:"oneof=Allow Deny"`
+	// Priority the policies with the higher priority are evaluated first
+	Priority int `json:"priority,omitempty" optional:"true"`
+}
+
+// ResourceActionBase the resource registered to the RBAC and the valid actions of it.
+// The action "*" is not listed, it matches all actions of the resource.
+type ResourceActionBase struct {
+	Resource string   `json:"resource"`
+	Actions  []string `json:"actions"`
+}
+
+// ListResourceActionsResponse the response body of listing the resource actions
+type ListResourceActionsResponse struct {
+	ResourceActions []ResourceActionBase `json:"resourceActions"`
 }
 
 // LoginUserInfoResponse the response body of login user info
@@ -1624,6 +2793,8 @@ type PipelineMeta struct {
 	Project     NameAlias `json:"project"`
 	Description string    `json:"description"`
 	CreateTime  time.Time `json:"createTime"`
+	// Ownership who to contact about the pipeline
+	Ownership *model.Ownership `json:"ownership,omitempty"`
 }
 
 // PipelineBase is the base info of pipeline
@@ -1652,6 +2823,7 @@ type CreatePipelineRequest struct {
 	Alias       string             `json:"alias" validate:"checkalias" optional:"true"`
 	Description string             `json:"description" optional:"true"`
 	Spec        model.WorkflowSpec `json:"spec"`
+	Ownership   *model.Ownership   `json:"ownership,omitempty" optional:"true"`
 }
 
 // PipelineMetaResponse is the response body contains PipelineMeta
@@ -1683,6 +2855,8 @@ type UpdatePipelineRequest struct {
 	Alias       string             `json:"alias" validate:"checkalias" optional:"true"`
 	Description string             `json:"description" optional:"true"`
 	Spec        model.WorkflowSpec `json:"spec" optional:"true"`
+	// Ownership is kept if it is nil, and removed if all fields are empty
+	Ownership *model.Ownership `json:"ownership,omitempty" optional:"true"`
 }
 
 // GetPipelineResponse is the response body of getting pipeline
@@ -1695,6 +2869,19 @@ type GetPipelineResponse struct {
 type PipelineInfo struct {
 	LastRun *PipelineRun `json:"lastRun"`
 	RunStat RunStat      `json:"runStat"`
+	// StepDurations the duration baselines of the steps
+	StepDurations []StepDurationStat `json:"stepDurations,omitempty"`
+	// Regressions the recent step executions that are slower than the baselines
+	Regressions []model.StepRegression `json:"regressions,omitempty"`
+}
+
+// StepDurationStat is the duration statistics of a pipeline step, the durations are in seconds
+type StepDurationStat struct {
+	Name string `json:"name"`
+	// Baseline the median duration of the recent executions, it is zero if the samples are not enough
+	Baseline     int64 `json:"baseline"`
+	LastDuration int64 `json:"lastDuration"`
+	Samples      int   `json:"samples"`
 }
 
 /***********************/
@@ -1755,6 +2942,11 @@ type GetPipelineRunLogResponse struct {
 	StepBase  `json:",inline"`
 	LogSource string `json:"source"`
 	Log       string `json:"log"`
+	// Page the page of the log lines, the pages start from 1 and the whole log is returned if it is not set
+	Page       int  `json:"page,omitempty"`
+	PageSize   int  `json:"pageSize,omitempty"`
+	TotalLines int  `json:"totalLines,omitempty"`
+	HasMore    bool `json:"hasMore,omitempty"`
 }
 
 // GetPipelineRunOutputResponse is the response body of getting pipeline run output
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// healthzPath the liveness probe, it succeeds as long as the server could respond
+	healthzPath = "/healthz"
+	// readyzPath the readiness probe, it fails if any critical dependency is down
+	readyzPath = "/readyz"
+)
+
+type health struct {
+	HealthService service.HealthService `inject:""`
+}
+
+// NewHealth is the public API of the health probes
+func NewHealth() Interface {
+	return &health{}
+}
+
+func (h *health) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path("").
+		Produces(restful.MIME_JSON).
+		Doc("api for the health probes")
+
+	tags := []string{"health"}
+
+	ws.Route(ws.GET(healthzPath).To(h.healthz).
+		Doc("report the status of the dependencies, it always returns 200 while the server is alive").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.HealthResponse{}).
+		Writes(apis.HealthResponse{}))
+
+	ws.Route(ws.GET(readyzPath).To(h.readyz).
+		Doc("report the status of the dependencies, it returns 503 if any critical dependency is down").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.HealthResponse{}).
+		Returns(503, "Service Unavailable", apis.HealthResponse{}).
+		Writes(apis.HealthResponse{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+func (h *health) healthz(req *restful.Request, res *restful.Response) {
+	report := h.HealthService.CheckHealth(req.Request.Context())
+	if err := res.WriteEntity(report); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (h *health) readyz(req *restful.Request, res *restful.Response) {
+	report := h.HealthService.CheckHealth(req.Request.Context())
+	code := http.StatusOK
+	if report.Status == service.HealthStatusDown {
+		code = http.StatusServiceUnavailable
+	}
+	if err := res.WriteHeaderAndEntity(code, report); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// NewInbox returns the web service of the inbox
+func NewInbox() Interface {
+	return &inbox{}
+}
+
+type inbox struct {
+	InboxService service.InboxService `inject:""`
+}
+
+func (i *inbox) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/inbox").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for the pending work of the login user")
+
+	tags := []string{"inbox"}
+
+	// the items are checked by the permissions of the actions that resolve them
+	ws.Route(ws.GET("/").To(i.listInbox).
+		Doc("list the pending approvals, the suspended workflows and pipeline runs, and the invitations not accepted across all projects").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.InboxResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.InboxResponse{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+func (i *inbox) listInbox(req *restful.Request, res *restful.Response) {
+	items, err := i.InboxService.ListInbox(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(items); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -29,7 +29,7 @@ var versionPrefix = "/api/v1"
 
 // GetAPIPrefix return the prefix of the api route path
 func GetAPIPrefix() []string {
-	return []string{versionPrefix, viewPrefix, "/v1"}
+	return []string{versionPrefix, viewPrefix, "/v1", healthzPath, readyzPath}
 }
 
 // viewPrefix the path prefix for view page
@@ -71,6 +71,7 @@ func InitAPIBean() []interface{} {
 
 	// Extension
 	RegisterAPI(NewDefinition())
+	RegisterAPI(NewDefinitionCatalog())
 	RegisterAPI(NewAddon())
 	RegisterAPI(NewEnabledAddon())
 	RegisterAPI(NewAddonRegistry())
@@ -97,6 +98,15 @@ func InitAPIBean() []interface{} {
 
 	// RBAC
 	RegisterAPI(NewRBAC())
+	RegisterAPI(NewChangeEvent())
+	RegisterAPI(NewStatusBadge())
+	RegisterAPI(NewSearch())
+	RegisterAPI(NewTrash())
+	RegisterAPI(NewInbox())
+
+	// Health
+	RegisterAPI(NewHealth())
+	RegisterAPI(NewSyncStatus())
 	var beans []interface{}
 	for i := range registeredAPI {
 		beans = append(beans, registeredAPI[i])
@@ -29,6 +29,7 @@ import (
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/domain/service"
 	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
 
@@ -90,6 +91,46 @@ func initPipelineRoutes(ws *restful.WebService, n *project) {
 		Filter(n.RBACService.CheckPerm("project/pipeline", "update")).
 		Writes(apis.PipelineBase{}).Do(meta, projParam, pipelineParam))
 
+	ws.Route(ws.GET("/{projectName}/pipelines/{pipelineName}/spec_audits").To(n.listPipelineSpecAudits).
+		Doc("list the spec changes of the pipeline").
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Returns(200, "OK", apis.ListSpecAuditsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "detail")).
+		Writes(apis.ListSpecAuditsResponse{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.GET("/{projectName}/pipelines/{pipelineName}/badges").To(n.listPipelineBadges).
+		Doc("list the public status badges of the pipeline").
+		Returns(200, "OK", apis.ListStatusBadgesResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "detail")).
+		Writes(apis.ListStatusBadgesResponse{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.POST("/{projectName}/pipelines/{pipelineName}/badges").To(n.createPipelineBadge).
+		Doc("create a public status badge of the last run of the pipeline").
+		Returns(200, "OK", apis.CreateStatusBadgeResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "update")).
+		Writes(apis.CreateStatusBadgeResponse{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.PUT("/{projectName}/pipelines/{pipelineName}/badges/{badgeID}").To(n.updatePipelineBadge).
+		Doc("enable or disable a status badge of the pipeline").
+		Param(ws.PathParameter("badgeID", "identifier of the badge").DataType("string")).
+		Reads(apis.UpdateStatusBadgeRequest{}).
+		Returns(200, "OK", apis.StatusBadgeBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "update")).
+		Writes(apis.StatusBadgeBase{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.DELETE("/{projectName}/pipelines/{pipelineName}/badges/{badgeID}").To(n.deletePipelineBadge).
+		Doc("delete a status badge of the pipeline, its token is revoked").
+		Param(ws.PathParameter("badgeID", "identifier of the badge").DataType("string")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "update")).
+		Writes(apis.EmptyResponse{}).Do(meta, projParam, pipelineParam))
+
 	ws.Route(ws.DELETE("/{projectName}/pipelines/{pipelineName}").To(n.deletePipeline).
 		Doc("delete pipeline").
 		Returns(200, "OK", apis.PipelineMetaResponse{}).
@@ -176,6 +217,8 @@ func initPipelineRoutes(ws *restful.WebService, n *project) {
 	ws.Route(ws.GET("/{projectName}/pipelines/{pipelineName}/runs/{runName}/log").To(n.getPipelineRunLog).
 		Doc("get pipeline run log").
 		Param(ws.QueryParameter("step", "query by specific step name").DataType("string")).
+		Param(ws.QueryParameter("page", "the page of the log lines, starting from 1, the whole log is returned if it is not set").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "the number of the log lines per page").DataType("integer")).
 		Returns(200, "OK", apis.GetPipelineRunLogResponse{}).
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Filter(n.RBACService.CheckPerm("project/pipeline/pipelineRun", "detail")).
@@ -293,6 +336,75 @@ func (n *project) getPipeline(req *restful.Request, res *restful.Response) {
 	}
 }
 
+func (n *project) listPipelineSpecAudits(req *restful.Request, res *restful.Response) {
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	audits, err := n.SpecAuditService.ListSpecAudits(req.Request.Context(), service.SpecAuditResourcePipeline, req.PathParameter(Project), req.PathParameter(Pipeline), page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(audits); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) listPipelineBadges(req *restful.Request, res *restful.Response) {
+	badges, err := n.StatusBadgeService.ListBadges(req.Request.Context(), service.StatusBadgeResourcePipeline, req.PathParameter(Project), req.PathParameter(Pipeline))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(badges); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) createPipelineBadge(req *restful.Request, res *restful.Response) {
+	badge, err := n.StatusBadgeService.CreateBadge(req.Request.Context(), service.StatusBadgeResourcePipeline, req.PathParameter(Project), req.PathParameter(Pipeline), apis.CreateStatusBadgeRequest{})
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(badge); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) updatePipelineBadge(req *restful.Request, res *restful.Response) {
+	var updateReq apis.UpdateStatusBadgeRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	badge, err := n.StatusBadgeService.UpdateBadge(req.Request.Context(), service.StatusBadgeResourcePipeline, req.PathParameter(Project), req.PathParameter(Pipeline), req.PathParameter("badgeID"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(badge); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) deletePipelineBadge(req *restful.Request, res *restful.Response) {
+	if err := n.StatusBadgeService.DeleteBadge(req.Request.Context(), service.StatusBadgeResourcePipeline, req.PathParameter(Project), req.PathParameter(Pipeline), req.PathParameter("badgeID")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (n *project) createPipeline(req *restful.Request, res *restful.Response) {
 	var createReq apis.CreatePipelineRequest
 	if err := req.ReadEntity(&createReq); err != nil {
@@ -434,6 +546,10 @@ func (n *project) getPipelineRunLog(req *restful.Request, res *restful.Response)
 		bcode.ReturnError(req, res, err)
 		return
 	}
+	if err := pageStepLog(req, &logs); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
 	if err := res.WriteEntity(logs); err != nil {
 		bcode.ReturnError(req, res, err)
 		return
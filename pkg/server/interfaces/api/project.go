@@ -38,6 +38,12 @@ type project struct {
 	PipelineRunService service.PipelineRunService `inject:""`
 	ContextService     service.ContextService     `inject:""`
 	RBACService        service.RBACService        `inject:""`
+	ApplicationService service.ApplicationService `inject:""`
+	// ServiceCatalogService the managed services requested by the project members
+	ServiceCatalogService service.ServiceCatalogService `inject:""`
+	SpecAuditService      service.SpecAuditService      `inject:""`
+	StatusBadgeService    service.StatusBadgeService    `inject:""`
+	SearchService         service.SearchService         `inject:""`
 }
 
 // NewProject new project
@@ -119,6 +125,106 @@ func (n *project) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", apis.ListProjectUsersResponse{}).
 		Writes(apis.ListProjectUsersResponse{}))
 
+	ws.Route(ws.GET("/{projectName}/member_events").To(n.listProjectMemberEvents).
+		Doc("list the changes of the members and the roles of a project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Filter(n.RbacService.CheckPerm("project/projectUser", "list")).
+		Returns(200, "OK", apis.ListProjectMemberEventsResponse{}).
+		Writes(apis.ListProjectMemberEventsResponse{}))
+
+	// the project admins who could manage the roles of the project could lock it
+	ws.Route(ws.PUT("/{projectName}/lock").To(n.lockProject).
+		Doc("lock the project temporarily, the deploys and the spec changes are rejected until the lock expires or is removed").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/role", "lock")).
+		Reads(apis.LockProjectRequest{}).
+		Returns(200, "OK", apis.ProjectBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ProjectBase{}))
+
+	ws.Route(ws.DELETE("/{projectName}/lock").To(n.unlockProject).
+		Doc("remove the lock of the project before it expires").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/role", "unlock")).
+		Returns(200, "OK", apis.ProjectBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ProjectBase{}))
+
+	ws.Route(ws.GET("/{projectName}/lock_events").To(n.listProjectLockEvents).
+		Doc("list the locks and the unlocks of a project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Filter(n.RbacService.CheckPerm("project", "detail")).
+		Returns(200, "OK", apis.ListProjectLockEventsResponse{}).
+		Writes(apis.ListProjectLockEventsResponse{}))
+
+	// the hits are filtered by the applications and the pipelines that the user could read
+	ws.Route(ws.GET("/{projectName}/search").To(n.searchProject).
+		Doc("search the audits or the step logs of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.QueryParameter("kind", "the kind of the documents, audit or log").DataType("string").Required(true)).
+		Param(ws.QueryParameter("q", "the query in the simple query string syntax, all documents are matched if it is empty").DataType("string")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Filter(n.RbacService.CheckPerm("project", "detail")).
+		Returns(200, "OK", apis.SearchResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.SearchResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/application_defaults").To(n.getApplicationDefaults).
+		Doc("get the defaults applied to every new application of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project", "detail")).
+		Returns(200, "OK", apis.ApplicationDefaults{}).
+		Writes(apis.ApplicationDefaults{}))
+
+	ws.Route(ws.PUT("/{projectName}/application_defaults").To(n.updateApplicationDefaults).
+		Doc("update the defaults applied to every new application of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project", "update")).
+		Reads(apis.ApplicationDefaults{}).
+		Returns(200, "OK", apis.ApplicationDefaults{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ApplicationDefaults{}))
+
+	ws.Route(ws.GET("/{projectName}/workflow_record_retention").To(n.getRecordRetention).
+		Doc("get the retention policy of the workflow records of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project", "detail")).
+		Returns(200, "OK", apis.WorkflowRecordRetention{}).
+		Writes(apis.WorkflowRecordRetention{}))
+
+	ws.Route(ws.PUT("/{projectName}/workflow_record_retention").To(n.updateRecordRetention).
+		Doc("update the retention policy of the workflow records of the project, the finished records out of the retention are pruned periodically").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project", "update")).
+		Reads(apis.WorkflowRecordRetention{}).
+		Returns(200, "OK", apis.WorkflowRecordRetention{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.WorkflowRecordRetention{}))
+
+	ws.Route(ws.POST("/{projectName}/application_defaults/preview").To(n.previewApplicationDefaults).
+		Doc("preview the effective labels and main component traits of a new application of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project", "detail")).
+		Reads(apis.PreviewApplicationDefaultsRequest{}).
+		Returns(200, "OK", apis.PreviewApplicationDefaultsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.PreviewApplicationDefaultsResponse{}))
+
 	ws.Route(ws.PUT("/{projectName}/users/{userName}").To(n.updateProjectUser).
 		Doc("update a user from a project").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -144,6 +250,11 @@ func (n *project) GetWebServiceRoute() *restful.WebService {
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
 		Filter(n.RbacService.CheckPerm("project/role", "list")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Param(ws.QueryParameter("name", "fuzzy search based on name").DataType("string")).
+		Param(ws.QueryParameter("sortBy", "the field to sort by, name or createTime").DataType("string")).
+		Param(ws.QueryParameter("order", "the order of the sorting, asc or desc").DataType("string")).
 		Returns(200, "OK", apis.ListRolesResponse{}).
 		Writes(apis.ListRolesResponse{}))
 
@@ -175,13 +286,27 @@ func (n *project) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", apis.EmptyResponse{}).
 		Writes(apis.EmptyResponse{}))
 
+	ws.Route(ws.POST("/{projectName}/roles/simulate").To(n.simulateProjectCapabilities).
+		Doc("simulate the menus and actions that the users with the project roles or permissions could access").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/role", "list")).
+		Reads(apis.SimulateCapabilitiesRequest{}).
+		Returns(200, "OK", apis.SimulateCapabilitiesResponse{}).
+		Writes(apis.SimulateCapabilitiesResponse{}))
+
 	ws.Route(ws.GET("/{projectName}/permissions").To(n.listProjectPermissions).
 		Doc("list all project level perm policies").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
 		Filter(n.RbacService.CheckPerm("project/permission", "list")).
-		Returns(200, "OK", []apis.PermissionBase{}).
-		Writes([]apis.PermissionBase{}))
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Param(ws.QueryParameter("name", "fuzzy search based on name").DataType("string")).
+		Param(ws.QueryParameter("sortBy", "the field to sort by, name or createTime").DataType("string")).
+		Param(ws.QueryParameter("order", "the order of the sorting, asc or desc").DataType("string")).
+		Returns(200, "OK", apis.ListPermissionsResponse{}).
+		Writes(apis.ListPermissionsResponse{}))
 
 	ws.Route(ws.POST("/{projectName}/permissions").To(n.createProjectPermission).
 		Doc("create a project level perm policy").
@@ -200,6 +325,75 @@ func (n *project) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", []apis.PermissionBase{}).
 		Writes([]apis.PermissionBase{}))
 
+	ws.Route(ws.GET("/{projectName}/permissions/{permissionName}/references").To(n.listProjectPermissionReferences).
+		Doc("list the roles and users that depend on a project level perm policy").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("permissionName", "identifier of the permission").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/permission", "list")).
+		Returns(200, "OK", apis.PermissionReferencesResponse{}).
+		Writes(apis.PermissionReferencesResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/service_classes").To(n.listServiceClasses).
+		Doc("list the managed services that could be requested from the catalog").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/serviceInstance", "list")).
+		Returns(200, "OK", apis.ListServiceClassesResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListServiceClassesResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/service_instances").To(n.listServiceInstances).
+		Doc("list the service instances of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/serviceInstance", "list")).
+		Returns(200, "OK", apis.ListServiceInstancesResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListServiceInstancesResponse{}))
+
+	ws.Route(ws.POST("/{projectName}/service_instances").To(n.createServiceInstance).
+		Doc("request a managed service bound to an application of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Reads(apis.CreateServiceInstanceRequest{}).
+		Filter(n.RbacService.CheckPerm("project/serviceInstance", "create")).
+		Returns(200, "OK", apis.ServiceInstanceBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ServiceInstanceBase{}))
+
+	ws.Route(ws.PUT("/{projectName}/service_instances/{instanceName}/approve").To(n.approveServiceInstance).
+		Doc("approve and provision a pending service instance").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("instanceName", "identifier of the service instance").DataType("string")).
+		Reads(apis.ReviewServiceInstanceRequest{}).
+		Filter(n.RbacService.CheckPerm("project/serviceInstance", "approve")).
+		Returns(200, "OK", apis.ServiceInstanceBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ServiceInstanceBase{}))
+
+	ws.Route(ws.PUT("/{projectName}/service_instances/{instanceName}/reject").To(n.rejectServiceInstance).
+		Doc("reject a pending service instance").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("instanceName", "identifier of the service instance").DataType("string")).
+		Reads(apis.ReviewServiceInstanceRequest{}).
+		Filter(n.RbacService.CheckPerm("project/serviceInstance", "approve")).
+		Returns(200, "OK", apis.ServiceInstanceBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ServiceInstanceBase{}))
+
+	ws.Route(ws.DELETE("/{projectName}/service_instances/{instanceName}").To(n.deleteServiceInstance).
+		Doc("delete a service instance and remove it from the application").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("instanceName", "identifier of the service instance").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/serviceInstance", "delete")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
 	ws.Route(ws.GET("/{projectName}/config_templates").To(n.getConfigTemplates).
 		Doc("get the templates which are in a project").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -310,6 +504,36 @@ func (n *project) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.ListTerraformProviderResponse{}))
 
+	ws.Route(ws.GET("/{projectName}/quota_usage").To(n.getQuotaUsage).
+		Doc("get the resource usage and the quota of a project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.RbacService.CheckPerm("project", "detail")).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string").Required(true)).
+		Returns(200, "OK", apis.ProjectQuotaUsageResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ProjectQuotaUsageResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/targets/{targetName}/workloads").To(n.listUnmanagedWorkloads).
+		Doc("list the workloads that are not managed by any application in the target namespace").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.RbacService.CheckPerm("application", "create")).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string").Required(true)).
+		Param(ws.PathParameter("targetName", "identifier of the target").DataType("string").Required(true)).
+		Returns(200, "OK", apis.ListWorkloadsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListWorkloadsResponse{}))
+
+	ws.Route(ws.POST("/{projectName}/targets/{targetName}/workloads/import").To(n.importWorkloads).
+		Doc("convert the workloads in the target namespace into an application").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.RbacService.CheckPerm("application", "create")).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string").Required(true)).
+		Param(ws.PathParameter("targetName", "identifier of the target").DataType("string").Required(true)).
+		Reads(apis.ImportWorkloadsRequest{}).
+		Returns(200, "OK", apis.ApplicationBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ApplicationBase{}))
+
 	initPipelineRoutes(ws, n)
 	ws.Filter(authCheckFilter)
 	return ws
@@ -479,6 +703,177 @@ func (n *project) listProjectUser(req *restful.Request, res *restful.Response) {
 	}
 }
 
+func (n *project) listProjectMemberEvents(req *restful.Request, res *restful.Response) {
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	events, err := n.ProjectService.ListProjectMemberEvents(req.Request.Context(), req.PathParameter("projectName"), page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(events); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) searchProject(req *restful.Request, res *restful.Response) {
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	result, err := n.SearchService.Search(req.Request.Context(), req.PathParameter("projectName"), req.QueryParameter("kind"), req.QueryParameter("q"), page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(result); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) lockProject(req *restful.Request, res *restful.Response) {
+	var lockReq apis.LockProjectRequest
+	if err := req.ReadEntity(&lockReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&lockReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	project, err := n.ProjectService.LockProject(req.Request.Context(), req.PathParameter("projectName"), lockReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(project); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) unlockProject(req *restful.Request, res *restful.Response) {
+	project, err := n.ProjectService.UnlockProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(project); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) getApplicationDefaults(req *restful.Request, res *restful.Response) {
+	defaults, err := n.ProjectService.GetApplicationDefaults(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(defaults); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) updateApplicationDefaults(req *restful.Request, res *restful.Response) {
+	var defaultsReq apis.ApplicationDefaults
+	if err := req.ReadEntity(&defaultsReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&defaultsReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	defaults, err := n.ProjectService.UpdateApplicationDefaults(req.Request.Context(), req.PathParameter("projectName"), defaultsReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(defaults); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) getRecordRetention(req *restful.Request, res *restful.Response) {
+	retention, err := n.ProjectService.GetRecordRetention(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(retention); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) updateRecordRetention(req *restful.Request, res *restful.Response) {
+	var retentionReq apis.WorkflowRecordRetention
+	if err := req.ReadEntity(&retentionReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&retentionReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	retention, err := n.ProjectService.UpdateRecordRetention(req.Request.Context(), req.PathParameter("projectName"), retentionReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(retention); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) previewApplicationDefaults(req *restful.Request, res *restful.Response) {
+	var previewReq apis.PreviewApplicationDefaultsRequest
+	if err := req.ReadEntity(&previewReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&previewReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	preview, err := n.ApplicationService.PreviewApplicationDefaults(req.Request.Context(), req.PathParameter("projectName"), previewReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(preview); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) listProjectLockEvents(req *restful.Request, res *restful.Response) {
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	events, err := n.ProjectService.ListProjectLockEvents(req.Request.Context(), req.PathParameter("projectName"), page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(events); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (n *project) updateProjectUser(req *restful.Request, res *restful.Response) {
 	// Verify the validity of parameters
 	var updateReq apis.UpdateProjectUserRequest
@@ -535,7 +930,11 @@ func (n *project) listProjectRoles(req *restful.Request, res *restful.Response)
 		bcode.ReturnError(req, res, err)
 		return
 	}
-	roles, err := n.RbacService.ListRole(req.Request.Context(), req.PathParameter("projectName"), page, pageSize)
+	roles, err := n.RbacService.ListRole(req.Request.Context(), req.PathParameter("projectName"), page, pageSize, apis.ListRoleOptions{
+		Name:   req.QueryParameter("name"),
+		SortBy: req.QueryParameter("sortBy"),
+		Order:  req.QueryParameter("order"),
+	})
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -628,7 +1027,16 @@ func (n *project) listProjectPermissions(req *restful.Request, res *restful.Resp
 		bcode.ReturnError(req, res, bcode.ErrProjectIsNotExist)
 		return
 	}
-	policies, err := n.RbacService.ListPermissions(req.Request.Context(), req.PathParameter("projectName"))
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	policies, err := n.RbacService.ListPermissions(req.Request.Context(), req.PathParameter("projectName"), page, pageSize, apis.ListPermissionOptions{
+		Name:   req.QueryParameter("name"),
+		SortBy: req.QueryParameter("sortBy"),
+		Order:  req.QueryParameter("order"),
+	})
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -678,6 +1086,111 @@ func (n *project) deleteProjectPermission(req *restful.Request, res *restful.Res
 	}
 }
 
+func (n *project) listProjectPermissionReferences(req *restful.Request, res *restful.Response) {
+	references, err := n.RbacService.ListPermissionReferences(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("permissionName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(references); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) listServiceClasses(req *restful.Request, res *restful.Response) {
+	classes, err := n.ServiceCatalogService.ListServiceClasses(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(classes); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) listServiceInstances(req *restful.Request, res *restful.Response) {
+	instances, err := n.ServiceCatalogService.ListServiceInstances(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(instances); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) createServiceInstance(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateServiceInstanceRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Call the domain layer code
+	instance, err := n.ServiceCatalogService.CreateServiceInstance(req.Request.Context(), req.PathParameter("projectName"), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Write back response data
+	if err := res.WriteEntity(instance); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) approveServiceInstance(req *restful.Request, res *restful.Response) {
+	var reviewReq apis.ReviewServiceInstanceRequest
+	if err := req.ReadEntity(&reviewReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	instance, err := n.ServiceCatalogService.ApproveServiceInstance(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("instanceName"), reviewReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(instance); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) rejectServiceInstance(req *restful.Request, res *restful.Response) {
+	var reviewReq apis.ReviewServiceInstanceRequest
+	if err := req.ReadEntity(&reviewReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	instance, err := n.ServiceCatalogService.RejectServiceInstance(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("instanceName"), reviewReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(instance); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) deleteServiceInstance(req *restful.Request, res *restful.Response) {
+	if err := n.ServiceCatalogService.DeleteServiceInstance(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("instanceName")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (n *project) getConfigTemplates(req *restful.Request, res *restful.Response) {
 	templates, err := n.ConfigService.ListTemplates(req.Request.Context(), req.PathParameter("projectName"), "project")
 	if err != nil {
@@ -856,3 +1369,67 @@ func (n *project) deleteDistribution(req *restful.Request, res *restful.Response
 		return
 	}
 }
+
+func (n *project) getQuotaUsage(req *restful.Request, res *restful.Response) {
+	usage, err := n.ProjectService.GetProjectQuotaUsage(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(usage); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) listUnmanagedWorkloads(req *restful.Request, res *restful.Response) {
+	workloads, err := n.ApplicationService.ListUnmanagedWorkloads(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("targetName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(workloads); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) importWorkloads(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var importReq apis.ImportWorkloadsRequest
+	if err := req.ReadEntity(&importReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&importReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app, err := n.ApplicationService.ImportWorkloads(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("targetName"), importReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(app); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) simulateProjectCapabilities(req *restful.Request, res *restful.Response) {
+	var simulateReq apis.SimulateCapabilitiesRequest
+	if err := req.ReadEntity(&simulateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	simulateReq.ProjectName = req.PathParameter("projectName")
+	capabilities, err := n.RbacService.SimulateCapabilities(req.Request.Context(), simulateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(capabilities); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/emicklei/go-restful/v3"
 	"k8s.io/klog/v2"
 
+	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/domain/service"
 	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	"github.com/kubevela/velaux/pkg/server/utils"
@@ -49,6 +50,11 @@ func (r *rbac) GetWebServiceRoute() *restful.WebService {
 		Doc("list all platform level roles").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Filter(r.RbacService.CheckPerm("role", "list")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Param(ws.QueryParameter("name", "fuzzy search based on name").DataType("string")).
+		Param(ws.QueryParameter("sortBy", "the field to sort by, name or createTime").DataType("string")).
+		Param(ws.QueryParameter("order", "the order of the sorting, asc or desc").DataType("string")).
 		Returns(200, "OK", apis.ListRolesResponse{}).
 		Writes(apis.ListRolesResponse{}))
 
@@ -69,6 +75,16 @@ func (r *rbac) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", apis.RoleBase{}).
 		Writes(apis.RoleBase{}))
 
+	ws.Route(ws.POST("/roles/{roleName}/target_grants").To(r.grantTargets).
+		Doc("grant a platform role the access to the targets and clusters, a permission is generated for each of them").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("roleName", "identifier of the role").DataType("string")).
+		Filter(r.RbacService.CheckPerm("role", "update")).
+		Reads(apis.GrantTargetsRequest{}).
+		Returns(200, "OK", apis.RoleBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.RoleBase{}))
+
 	ws.Route(ws.DELETE("/roles/{roleName}").To(r.deletePlatformRole).
 		Doc("update platform level role").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -77,12 +93,49 @@ func (r *rbac) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", apis.EmptyResponse{}).
 		Writes(apis.EmptyResponse{}))
 
+	ws.Route(ws.GET("/cross_project_roles").To(r.listCrossProjectRoles).
+		Doc("list the roles that could be bound in several projects").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(r.RbacService.CheckPerm("role", "list")).
+		Returns(200, "OK", apis.ListRolesResponse{}).
+		Writes(apis.ListRolesResponse{}))
+
+	ws.Route(ws.POST("/cross_project_roles").To(r.createCrossProjectRole).
+		Doc("create a role that could be bound in the projects selected by the names or the labels").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(r.RbacService.CheckPerm("role", "create")).
+		Returns(200, "OK", apis.RoleBase{}).
+		Reads(apis.CreateRoleRequest{}).
+		Writes(apis.RoleBase{}))
+
+	ws.Route(ws.PUT("/cross_project_roles/{roleName}").To(r.updateCrossProjectRole).
+		Doc("update a cross-project role").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("roleName", "identifier of the role").DataType("string")).
+		Filter(r.RbacService.CheckPerm("role", "update")).
+		Reads(apis.UpdateRoleRequest{}).
+		Returns(200, "OK", apis.RoleBase{}).
+		Writes(apis.RoleBase{}))
+
+	ws.Route(ws.DELETE("/cross_project_roles/{roleName}").To(r.deleteCrossProjectRole).
+		Doc("delete a cross-project role").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("roleName", "identifier of the role").DataType("string")).
+		Filter(r.RbacService.CheckPerm("role", "delete")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Writes(apis.EmptyResponse{}))
+
 	ws.Route(ws.GET("/permissions").To(r.listPlatformPermissions).
 		Doc("list all platform level perm policies").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Filter(r.RbacService.CheckPerm("permission", "list")).
-		Returns(200, "OK", []apis.PermissionBase{}).
-		Writes([]apis.PermissionBase{}))
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Param(ws.QueryParameter("name", "fuzzy search based on name").DataType("string")).
+		Param(ws.QueryParameter("sortBy", "the field to sort by, name or createTime").DataType("string")).
+		Param(ws.QueryParameter("order", "the order of the sorting, asc or desc").DataType("string")).
+		Returns(200, "OK", apis.ListPermissionsResponse{}).
+		Writes(apis.ListPermissionsResponse{}))
 
 	ws.Route(ws.POST("/permissions").To(r.createPlatformPermission).
 		Doc("create the platform perm policy").
@@ -100,6 +153,86 @@ func (r *rbac) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", apis.EmptyResponse{}).
 		Writes(apis.EmptyResponse{}))
 
+	ws.Route(ws.GET("/permissions/{permissionName}/references").To(r.listPlatformPermissionReferences).
+		Doc("list the roles and users that depend on a platform perm policy").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("permissionName", "identifier of the permission").DataType("string")).
+		Filter(r.RbacService.CheckPerm("permission", "list")).
+		Returns(200, "OK", apis.PermissionReferencesResponse{}).
+		Writes(apis.PermissionReferencesResponse{}))
+
+	ws.Route(ws.GET("/project_role_templates").To(r.listProjectRoleTemplates).
+		Doc("list the templates of the roles that created in every new project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(r.RbacService.CheckPerm("role", "list")).
+		Returns(200, "OK", apis.ListProjectRoleTemplatesResponse{}).
+		Writes(apis.ListProjectRoleTemplatesResponse{}))
+
+	ws.Route(ws.POST("/project_role_templates").To(r.createProjectRoleTemplate).
+		Doc("create a project role template").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(r.RbacService.CheckPerm("role", "create")).
+		Reads(apis.CreateProjectRoleTemplateRequest{}).
+		Returns(200, "OK", apis.ProjectRoleTemplateBase{}).
+		Writes(apis.ProjectRoleTemplateBase{}))
+
+	ws.Route(ws.PUT("/project_role_templates/{templateName}").To(r.updateProjectRoleTemplate).
+		Doc("update a project role template").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("templateName", "identifier of the project role template").DataType("string")).
+		Filter(r.RbacService.CheckPerm("role", "update")).
+		Reads(apis.UpdateProjectRoleTemplateRequest{}).
+		Returns(200, "OK", apis.ProjectRoleTemplateBase{}).
+		Writes(apis.ProjectRoleTemplateBase{}))
+
+	ws.Route(ws.DELETE("/project_role_templates/{templateName}").To(r.deleteProjectRoleTemplate).
+		Doc("delete a project role template").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("templateName", "identifier of the project role template").DataType("string")).
+		Filter(r.RbacService.CheckPerm("role", "delete")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.POST("/roles/simulate").To(r.simulatePlatformCapabilities).
+		Doc("simulate the menus and actions that the users with the platform roles or permissions could access").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(r.RbacService.CheckPerm("role", "list")).
+		Reads(apis.SimulateCapabilitiesRequest{}).
+		Returns(200, "OK", apis.SimulateCapabilitiesResponse{}).
+		Writes(apis.SimulateCapabilitiesResponse{}))
+
+	ws.Route(ws.GET("/rbac_approvals").To(r.listRBACApprovals).
+		Doc("list the sensitive changes of the platform roles and permissions").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.QueryParameter("status", "filter the approvals by the status, such as pending").DataType("string")).
+		Filter(r.RbacService.CheckPerm("rbacApproval", "list")).
+		Returns(200, "OK", apis.ListRBACApprovalsResponse{}).
+		Writes(apis.ListRBACApprovalsResponse{}))
+
+	ws.Route(ws.POST("/rbac_approvals/{approvalName}/approve").To(r.approveRBACChange).
+		Doc("approve a sensitive change, the change takes effect after being approved").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("approvalName", "identifier of the rbac approval").DataType("string")).
+		Filter(r.RbacService.CheckPerm("rbacApproval", "approve")).
+		Reads(apis.ReviewRBACApprovalRequest{}).
+		Returns(200, "OK", apis.RBACApprovalBase{}).
+		Writes(apis.RBACApprovalBase{}))
+
+	ws.Route(ws.POST("/rbac_approvals/{approvalName}/reject").To(r.rejectRBACChange).
+		Doc("reject a sensitive change").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("approvalName", "identifier of the rbac approval").DataType("string")).
+		Filter(r.RbacService.CheckPerm("rbacApproval", "reject")).
+		Reads(apis.ReviewRBACApprovalRequest{}).
+		Returns(200, "OK", apis.RBACApprovalBase{}).
+		Writes(apis.RBACApprovalBase{}))
+
+	ws.Route(ws.GET("/system/resource-actions").To(r.listResourceActions).
+		Doc("list all resources and the valid actions that could be used in the permission policies").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.ListResourceActionsResponse{}).
+		Writes(apis.ListResourceActionsResponse{}))
+
 	ws.Filter(authCheckFilter)
 	return ws
 }
@@ -110,7 +243,11 @@ func (r *rbac) listPlatformRoles(req *restful.Request, res *restful.Response) {
 		bcode.ReturnError(req, res, err)
 		return
 	}
-	roles, err := r.RbacService.ListRole(req.Request.Context(), "", page, pageSize)
+	roles, err := r.RbacService.ListRole(req.Request.Context(), "", page, pageSize, apis.ListRoleOptions{
+		Name:   req.QueryParameter("name"),
+		SortBy: req.QueryParameter("sortBy"),
+		Order:  req.QueryParameter("order"),
+	})
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -147,6 +284,23 @@ func (r *rbac) createPlatformRole(req *restful.Request, res *restful.Response) {
 	}
 }
 
+func (r *rbac) grantTargets(req *restful.Request, res *restful.Response) {
+	var grantReq apis.GrantTargetsRequest
+	if err := req.ReadEntity(&grantReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	roleBase, err := r.RbacService.GrantTargets(req.Request.Context(), req.PathParameter("roleName"), grantReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(roleBase); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (r *rbac) updatePlatformRole(req *restful.Request, res *restful.Response) {
 	// Verify the validity of parameters
 	var updateReq apis.UpdateRoleRequest
@@ -186,8 +340,90 @@ func (r *rbac) deletePlatformRole(req *restful.Request, res *restful.Response) {
 	}
 }
 
+func (r *rbac) listCrossProjectRoles(req *restful.Request, res *restful.Response) {
+	roles, err := r.RbacService.ListRole(req.Request.Context(), model.RoleScopeCrossProject, 0, 0, apis.ListRoleOptions{})
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(roles); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) createCrossProjectRole(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateRoleRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Call the domain layer code
+	roleBase, err := r.RbacService.CreateRole(req.Request.Context(), model.RoleScopeCrossProject, createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+
+	// Write back response data
+	if err := res.WriteEntity(roleBase); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) updateCrossProjectRole(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var updateReq apis.UpdateRoleRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Call the domain layer code
+	roleBase, err := r.RbacService.UpdateRole(req.Request.Context(), model.RoleScopeCrossProject, req.PathParameter("roleName"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+
+	// Write back response data
+	if err := res.WriteEntity(roleBase); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) deleteCrossProjectRole(req *restful.Request, res *restful.Response) {
+	if err := r.RbacService.DeleteRole(req.Request.Context(), model.RoleScopeCrossProject, req.PathParameter("roleName")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (r *rbac) listPlatformPermissions(req *restful.Request, res *restful.Response) {
-	policies, err := r.RbacService.ListPermissions(req.Request.Context(), "")
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	policies, err := r.RbacService.ListPermissions(req.Request.Context(), "", page, pageSize, apis.ListPermissionOptions{
+		Name:   req.QueryParameter("name"),
+		SortBy: req.QueryParameter("sortBy"),
+		Order:  req.QueryParameter("order"),
+	})
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -236,3 +472,169 @@ func (r *rbac) deletePlatformPermission(req *restful.Request, res *restful.Respo
 		return
 	}
 }
+
+func (r *rbac) listPlatformPermissionReferences(req *restful.Request, res *restful.Response) {
+	references, err := r.RbacService.ListPermissionReferences(req.Request.Context(), "", req.PathParameter("permissionName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(references); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) listProjectRoleTemplates(req *restful.Request, res *restful.Response) {
+	templates, err := r.RbacService.ListProjectRoleTemplates(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(templates); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) createProjectRoleTemplate(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateProjectRoleTemplateRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Call the domain layer code
+	template, err := r.RbacService.CreateProjectRoleTemplate(req.Request.Context(), createReq)
+	if err != nil {
+		klog.Errorf("create the project role template failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+
+	// Write back response data
+	if err := res.WriteEntity(template); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) updateProjectRoleTemplate(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var updateReq apis.UpdateProjectRoleTemplateRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Call the domain layer code
+	template, err := r.RbacService.UpdateProjectRoleTemplate(req.Request.Context(), req.PathParameter("templateName"), updateReq)
+	if err != nil {
+		klog.Errorf("update the project role template failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+
+	// Write back response data
+	if err := res.WriteEntity(template); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) deleteProjectRoleTemplate(req *restful.Request, res *restful.Response) {
+	err := r.RbacService.DeleteProjectRoleTemplate(req.Request.Context(), req.PathParameter("templateName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// Write back response data
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) listResourceActions(req *restful.Request, res *restful.Response) {
+	resourceActions, err := r.RbacService.ListResourceActions(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(resourceActions); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) listRBACApprovals(req *restful.Request, res *restful.Response) {
+	approvals, err := r.RbacService.ListRBACApprovals(req.Request.Context(), req.QueryParameter("status"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(approvals); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) approveRBACChange(req *restful.Request, res *restful.Response) {
+	var reviewReq apis.ReviewRBACApprovalRequest
+	if err := req.ReadEntity(&reviewReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	approval, err := r.RbacService.ApproveRBACChange(req.Request.Context(), req.PathParameter("approvalName"), reviewReq)
+	if err != nil {
+		klog.Errorf("approve the rbac change failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(approval); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) rejectRBACChange(req *restful.Request, res *restful.Response) {
+	var reviewReq apis.ReviewRBACApprovalRequest
+	if err := req.ReadEntity(&reviewReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	approval, err := r.RbacService.RejectRBACChange(req.Request.Context(), req.PathParameter("approvalName"), reviewReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(approval); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *rbac) simulatePlatformCapabilities(req *restful.Request, res *restful.Response) {
+	var simulateReq apis.SimulateCapabilitiesRequest
+	if err := req.ReadEntity(&simulateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	simulateReq.ProjectName = ""
+	capabilities, err := r.RbacService.SimulateCapabilities(req.Request.Context(), simulateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(capabilities); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// NewSearch returns the global search web service
+func NewSearch() Interface {
+	return &search{}
+}
+
+type search struct {
+	SearchService service.SearchService `inject:""`
+}
+
+func (s *search) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/search").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for the global search")
+
+	tags := []string{"search"}
+
+	// the results are filtered by the permissions of the login user
+	ws.Route(ws.GET("/").To(s.searchEntities).
+		Doc("search the applications, the pipelines, the users and the configs by the keywords").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.QueryParameter("q", "the keywords, each keyword must match a word or a word prefix of the name, the alias or the description").DataType("string").Required(true)).
+		Param(ws.QueryParameter("kinds", "the comma separated kinds of the entities, application, pipeline, user or config, all kinds are searched if it is empty").DataType("string")).
+		Param(ws.QueryParameter("limit", "the max count of the entities of each kind, 10 by default and at most 50").DataType("integer")).
+		Returns(200, "OK", apis.SearchEntitiesResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.SearchEntitiesResponse{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+func (s *search) searchEntities(req *restful.Request, res *restful.Response) {
+	var kinds []string
+	if req.QueryParameter("kinds") != "" {
+		kinds = strings.Split(req.QueryParameter("kinds"), ",")
+	}
+	limit, _ := strconv.Atoi(req.QueryParameter("limit"))
+	result, err := s.SearchService.SearchEntities(req.Request.Context(), req.QueryParameter("q"), kinds, limit)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(result); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
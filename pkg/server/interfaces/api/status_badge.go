@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"html"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const mimeSVG = "image/svg+xml"
+
+// badgeRateLimiter limits the anonymous badge requests of each client IP
+var badgeRateLimiter = utils.NewRateLimiter(1, 30, 10000)
+
+// badgeColors the hex values of the badge colors
+var badgeColors = map[string]string{
+	"brightgreen": "#4c1",
+	"red":         "#e05d44",
+	"yellow":      "#dfb317",
+	"blue":        "#007ec6",
+	"lightgrey":   "#9f9f9f",
+}
+
+type statusBadge struct {
+	StatusBadgeService service.StatusBadgeService `inject:""`
+}
+
+// NewStatusBadge is the public API of the status badges embedded in the READMEs
+func NewStatusBadge() Interface {
+	return &statusBadge{}
+}
+
+func (s *statusBadge) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/badges").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(mimeSVG, restful.MIME_JSON).
+		Doc("api for the public status badges")
+
+	tags := []string{"badge"}
+
+	ws.Route(ws.GET("/{badgeID}").To(s.getBadge).
+		Doc("render the status badge, the login is not required").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("badgeID", "identifier of the badge").DataType("string")).
+		Param(ws.QueryParameter("token", "the token of the badge").DataType("string").Required(true)).
+		Param(ws.QueryParameter("format", "the format of the badge, svg or json, default is svg").DataType("string")).
+		Returns(200, "OK", apis.StatusBadgeResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.StatusBadgeResponse{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+func (s *statusBadge) getBadge(req *restful.Request, res *restful.Response) {
+	if !badgeRateLimiter.Allow(utils.TrustedClientIP(req.Request)) {
+		s.writeError(req, res, bcode.ErrStatusBadgeRateLimited)
+		return
+	}
+	status, err := s.StatusBadgeService.GetBadgeStatus(req.Request.Context(), req.PathParameter("badgeID"), req.QueryParameter("token"))
+	if err != nil {
+		s.writeError(req, res, err)
+		return
+	}
+	// the badges are proxied by the image caches of the code hosting services, such as GitHub camo
+	res.AddHeader("Cache-Control", "no-cache, max-age=0")
+	if req.QueryParameter("format") == "json" {
+		if err := res.WriteAsJson(status); err != nil {
+			klog.Errorf("write the status badge failure %s", err.Error())
+		}
+		return
+	}
+	res.AddHeader(restful.HEADER_ContentType, mimeSVG)
+	if _, err := res.Write(renderBadgeSVG(status)); err != nil {
+		klog.Errorf("write the status badge failure %s", err.Error())
+	}
+}
+
+// writeError writes the error as JSON even if the client only accepts the images
+func (s *statusBadge) writeError(req *restful.Request, res *restful.Response, err error) {
+	res.SetRequestAccepts(restful.MIME_JSON)
+	bcode.ReturnError(req, res, err)
+}
+
+// renderBadgeSVG renders the badge in the flat style of shields.io, the width of the text is estimated
+func renderBadgeSVG(status *apis.StatusBadgeResponse) []byte {
+	color, ok := badgeColors[status.Color]
+	if !ok {
+		color = badgeColors["lightgrey"]
+	}
+	labelWidth := badgeTextWidth(status.Label)
+	messageWidth := badgeTextWidth(status.Message)
+	width := labelWidth + messageWidth
+	label := html.EscapeString(status.Label)
+	message := html.EscapeString(status.Message)
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">`+
+		`<title>%[4]s: %[5]s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="14">%[4]s</text><text x="%[8]d" y="14">%[5]s</text></g></svg>`,
+		width, labelWidth, messageWidth, label, message, color, labelWidth/2, labelWidth+messageWidth/2))
+}
+
+func badgeTextWidth(text string) int {
+	return len([]rune(text))*7 + 10
+}
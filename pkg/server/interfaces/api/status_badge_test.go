@@ -0,0 +1,37 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+func TestRenderBadgeSVG(t *testing.T) {
+	svg := string(renderBadgeSVG(&apis.StatusBadgeResponse{Label: "<app>", Message: "running", Color: "brightgreen"}))
+	assert.Assert(t, strings.HasPrefix(svg, "<svg "))
+	assert.Assert(t, strings.Contains(svg, "&lt;app&gt;: running"))
+	assert.Assert(t, !strings.Contains(svg, "<app>"))
+	assert.Assert(t, strings.Contains(svg, `fill="#4c1"`))
+
+	svg = string(renderBadgeSVG(&apis.StatusBadgeResponse{Label: "app", Message: "unknown", Color: "purple"}))
+	assert.Assert(t, strings.Contains(svg, `fill="#9f9f9f"`))
+}
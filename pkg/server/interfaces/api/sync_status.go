@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+type syncStatus struct {
+	SyncStatusService  service.SyncStatusService  `inject:""`
+	ApplicationService service.ApplicationService `inject:""`
+	RbacService        service.RBACService        `inject:""`
+}
+
+// NewSyncStatus is the API reporting the status of the workers syncing the cluster state
+func NewSyncStatus() Interface {
+	return &syncStatus{}
+}
+
+func (s *syncStatus) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/system").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for the status of the system")
+
+	tags := []string{"systemInfo"}
+
+	ws.Route(ws.GET("/sync-status").To(s.getSyncStatus).
+		Doc("report the last successful sync, the backlog, the lag and the errors of the sync workers, the workers run on the leader replica only").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(s.RbacService.CheckPerm("systemSetting", "detail")).
+		Returns(200, "OK", apis.SyncStatusResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.SyncStatusResponse{}))
+
+	ws.Route(ws.GET("/sync-conflicts").To(s.listSyncConflicts).
+		Doc("report the applications deployed by VelaUX whose CRs are modified outside VelaUX, such as by kubectl").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(s.RbacService.CheckPerm("systemSetting", "detail")).
+		Returns(200, "OK", apis.ListApplicationConflictsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListApplicationConflictsResponse{}))
+
+	ws.Route(ws.GET("/sync-scope").To(s.getSyncScope).
+		Doc("get the namespaces, the clusters and the labels of the external applications synced to VelaUX").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(s.RbacService.CheckPerm("systemSetting", "detail")).
+		Returns(200, "OK", apis.SyncScopeResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.SyncScopeResponse{}))
+
+	ws.Route(ws.PUT("/sync-scope").To(s.updateSyncScope).
+		Doc("update the scope of the external applications to sync, it replaces the scope of the server config and is applied within a minute").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(s.RbacService.CheckPerm("systemSetting", "update")).
+		Reads(apis.SyncScope{}).
+		Returns(200, "OK", apis.SyncScopeResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.SyncScopeResponse{}))
+
+	ws.Route(ws.DELETE("/sync-scope").To(s.resetSyncScope).
+		Doc("reset the scope of the external applications to sync to the server config").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(s.RbacService.CheckPerm("systemSetting", "update")).
+		Returns(200, "OK", apis.SyncScopeResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.SyncScopeResponse{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+func (s *syncStatus) getSyncStatus(req *restful.Request, res *restful.Response) {
+	if err := res.WriteEntity(s.SyncStatusService.GetSyncStatus(req.Request.Context())); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (s *syncStatus) listSyncConflicts(req *restful.Request, res *restful.Response) {
+	conflicts, err := s.ApplicationService.ListApplicationConflicts(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(conflicts); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (s *syncStatus) getSyncScope(req *restful.Request, res *restful.Response) {
+	scope, err := s.SyncStatusService.GetSyncScope(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(scope); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (s *syncStatus) updateSyncScope(req *restful.Request, res *restful.Response) {
+	var updateReq apis.SyncScope
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	scope, err := s.SyncStatusService.UpdateSyncScope(req.Request.Context(), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(scope); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (s *syncStatus) resetSyncScope(req *restful.Request, res *restful.Response) {
+	scope, err := s.SyncStatusService.ResetSyncScope(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(scope); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
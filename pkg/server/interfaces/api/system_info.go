@@ -60,6 +60,14 @@ func (u systemInfo) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.SystemInfoResponse{}))
 
+	ws.Route(ws.GET("/version_advice").To(u.getVersionAdvice).
+		Doc("compare the versions of VelaUX, vela-core and the CRDs across the clusters").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(u.RbacService.CheckPerm("systemSetting", "detail")).
+		Returns(200, "OK", apis.VersionAdviceResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.VersionAdviceResponse{}))
+
 	ws.Filter(authCheckFilter)
 	return ws
 }
@@ -102,3 +110,15 @@ func (u systemInfo) updateSystemInfo(req *restful.Request, res *restful.Response
 		return
 	}
 }
+
+func (u systemInfo) getVersionAdvice(req *restful.Request, res *restful.Response) {
+	advice, err := u.SystemInfoService.GetVersionAdvice(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(advice); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
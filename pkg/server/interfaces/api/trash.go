@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// NewTrash returns the web service of the trash
+func NewTrash() Interface {
+	return &trash{}
+}
+
+type trash struct {
+	TrashService service.TrashService `inject:""`
+}
+
+func (t *trash) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/trash").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for the trash of the deleted entities")
+
+	tags := []string{"trash"}
+
+	// the items are checked by the permissions of their deleted entities
+	ws.Route(ws.GET("/").To(t.listTrash).
+		Doc("list the deleted applications, environments, pipelines and roles that could be restored").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.QueryParameter("project", "filter the items by the project").DataType("string")).
+		Param(ws.QueryParameter("kind", "filter the items by the kind, application, environment, pipeline or role").DataType("string")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Returns(200, "OK", apis.ListTrashResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListTrashResponse{}))
+
+	ws.Route(ws.POST("/{trashName}/restore").To(t.restoreTrash).
+		Doc("restore the deleted entity, the login user must be allowed to create it").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("trashName", "identifier of the trash item").DataType("string")).
+		Returns(200, "OK", apis.TrashItemBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.TrashItemBase{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+func (t *trash) listTrash(req *restful.Request, res *restful.Response) {
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	items, err := t.TrashService.ListTrash(req.Request.Context(), req.QueryParameter("project"), req.QueryParameter("kind"), page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(items); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (t *trash) restoreTrash(req *restful.Request, res *restful.Response) {
+	item, err := t.TrashService.RestoreTrash(req.Request.Context(), req.PathParameter("trashName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(item); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -18,9 +18,11 @@ package api
 
 import (
 	"context"
+	"time"
 
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
 	"github.com/emicklei/go-restful/v3"
+	"k8s.io/klog/v2"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/domain/service"
@@ -30,8 +32,13 @@ import (
 )
 
 type user struct {
-	UserService service.UserService `inject:""`
-	RbacService service.RBACService `inject:""`
+	UserService               service.UserService               `inject:""`
+	RbacService               service.RBACService               `inject:""`
+	SessionService            service.SessionService            `inject:""`
+	UserInvitationService     service.UserInvitationService     `inject:""`
+	UserPreferenceService     service.UserPreferenceService     `inject:""`
+	EmailChangeService        service.EmailChangeService        `inject:""`
+	PermissionSnapshotService service.PermissionSnapshotService `inject:""`
 }
 
 // NewUser is the  of user
@@ -92,11 +99,30 @@ func (c *user) GetWebServiceRoute() *restful.WebService {
 	ws.Route(ws.DELETE("/{username}").To(c.deleteUser).
 		Doc("delete a user").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.QueryParameter("transferTo", "the user to transfer the owned resources to").DataType("string")).
 		Filter(c.RbacService.CheckPerm("user", "delete")).
 		Returns(200, "OK", apis.EmptyResponse{}).
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.EmptyResponse{}))
 
+	ws.Route(ws.GET("/{username}/owned_resources").To(c.listOwnedResources).
+		Doc("list the resources owned by a user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("user", "detail")).
+		Filter(c.userCheckFilter).
+		Returns(200, "OK", apis.UserOwnedResourcesResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.UserOwnedResourcesResponse{}))
+
+	ws.Route(ws.GET("/{username}/permission_snapshot").To(c.getPermissionSnapshot).
+		Doc("get the effective roles and permissions of a user at a point in time, the deleted users are included").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.QueryParameter("time", "the time in the RFC3339 format, defaults to now").DataType("string")).
+		Filter(c.RbacService.CheckPerm("user", "detail")).
+		Returns(200, "OK", apis.UserPermissionSnapshotResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.UserPermissionSnapshotResponse{}))
+
 	ws.Route(ws.GET("/{username}/disable").To(c.disableUser).
 		Doc("disable a user").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -115,6 +141,141 @@ func (c *user) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.EmptyResponse{}))
 
+	ws.Route(ws.GET("/{username}/unlock").To(c.unlockUser).
+		Doc("unlock a user that is locked because of too many failed login attempts").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("user", "unlock")).
+		Filter(c.userCheckFilter).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.POST("/invitations").To(c.createInvitation).
+		Doc("invite a new user by the email, the roles are granted when the invitation is accepted").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("user", "create")).
+		Reads(apis.CreateUserInvitationRequest{}).
+		Returns(200, "OK", apis.UserInvitationBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.UserInvitationBase{}))
+
+	ws.Route(ws.GET("/invitations").To(c.listInvitations).
+		Doc("list the pending invitations").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("user", "list")).
+		Returns(200, "OK", apis.ListUserInvitationsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListUserInvitationsResponse{}))
+
+	ws.Route(ws.DELETE("/invitations/{invitationID}").To(c.deleteInvitation).
+		Doc("revoke an invitation").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("user", "create")).
+		Param(ws.PathParameter("invitationID", "identifier of the invitation").DataType("string")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/{username}/sessions").To(c.listUserSessions).
+		Doc("list the active sessions of a user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("user", "detail")).
+		Filter(c.userCheckFilter).
+		Returns(200, "OK", apis.ListSessionsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListSessionsResponse{}))
+
+	ws.Route(ws.GET("/{username}/login-history").To(c.listLoginHistory).
+		Doc("list the logins of a user in the retention, the latest first").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("user", "detail")).
+		Filter(c.userCheckFilter).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Returns(200, "OK", apis.ListLoginHistoryResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListLoginHistoryResponse{}))
+
+	ws.Route(ws.DELETE("/{username}/sessions").To(c.revokeUserSessions).
+		Doc("revoke all sessions of a user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("user", "update")).
+		Filter(c.userCheckFilter).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/me/preferences").To(c.getPreference).
+		Doc("get the preferences of the login user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.UserPreferenceBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.UserPreferenceBase{}))
+
+	ws.Route(ws.PUT("/me/preferences").To(c.updatePreference).
+		Doc("replace the preferences of the login user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.UpdateUserPreferenceRequest{}).
+		Returns(200, "OK", apis.UserPreferenceBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.UserPreferenceBase{}))
+
+	ws.Route(ws.POST("/me/email").To(c.changeEmail).
+		Doc("send the verification token to the new email of the login user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.ChangeEmailRequest{}).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.POST("/me/email/verify").To(c.verifyEmail).
+		Doc("change the email of the login user with the verification token").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.VerifyEmailRequest{}).
+		Returns(200, "OK", apis.UserBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.UserBase{}))
+
+	ws.Route(ws.PUT("/me/password").To(c.changePassword).
+		Doc("change the password of the login user, it is required before using the other APIs if the password must be changed").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.ChangePasswordRequest{}).
+		Returns(200, "OK", apis.UserBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.UserBase{}))
+
+	ws.Route(ws.PUT("/me/avatar").To(c.uploadAvatar).
+		Doc("upload the avatar of the login user, it replaces the Gravatar of the email").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.UploadAvatarRequest{}).
+		Returns(200, "OK", apis.UserBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.UserBase{}))
+
+	ws.Route(ws.DELETE("/me/avatar").To(c.deleteAvatar).
+		Doc("delete the uploaded avatar of the login user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.UserBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.UserBase{}))
+
+	ws.Route(ws.GET("/avatars/{avatarID}").To(c.getAvatar).
+		Doc("render the uploaded avatar, the login is not required because the images are loaded by the browsers").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("avatarID", "identifier of the avatar").DataType("string")).
+		Produces("image/png", "image/jpeg", "image/gif", "image/webp", restful.MIME_JSON).
+		Returns(200, "OK", nil).
+		Returns(404, "Not Found", bcode.Bcode{}))
+
+	ws.Route(ws.GET("/{username}/email_changes").To(c.listEmailChanges).
+		Doc("list the verified email changes of a user").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("user", "detail")).
+		Filter(c.userCheckFilter).
+		Returns(200, "OK", apis.ListEmailChangeRecordsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListEmailChangeRecordsResponse{}))
+
 	ws.Filter(authCheckFilter)
 	return ws
 }
@@ -164,7 +325,7 @@ func (c *user) detailUser(req *restful.Request, res *restful.Response) {
 }
 
 func (c *user) deleteUser(req *restful.Request, res *restful.Response) {
-	err := c.UserService.DeleteUser(req.Request.Context(), req.PathParameter("username"))
+	err := c.UserService.DeleteUser(req.Request.Context(), req.PathParameter("username"), req.QueryParameter("transferTo"))
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -175,6 +336,19 @@ func (c *user) deleteUser(req *restful.Request, res *restful.Response) {
 	}
 }
 
+func (c *user) listOwnedResources(req *restful.Request, res *restful.Response) {
+	user := req.Request.Context().Value(&apis.CtxKeyUser).(*model.User)
+	resp, err := c.UserService.ListOwnedResources(req.Request.Context(), user.Name)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(resp); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *user) listUser(req *restful.Request, res *restful.Response) {
 	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
 	if err != nil {
@@ -207,7 +381,7 @@ func (c *user) updateUser(req *restful.Request, res *restful.Response) {
 		bcode.ReturnError(req, res, err)
 		return
 	}
-	resp, err := c.UserService.UpdateUser(req.Request.Context(), user, updateReq)
+	resp, err := c.UserService.UpdateUser(withClientInfo(req), user, updateReq)
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -231,6 +405,18 @@ func (c *user) disableUser(req *restful.Request, res *restful.Response) {
 	}
 }
 
+func (c *user) unlockUser(req *restful.Request, res *restful.Response) {
+	user := req.Request.Context().Value(&apis.CtxKeyUser).(*model.User)
+	if err := c.UserService.UnlockUser(req.Request.Context(), user); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *user) enableUser(req *restful.Request, res *restful.Response) {
 	user := req.Request.Context().Value(&apis.CtxKeyUser).(*model.User)
 	err := c.UserService.EnableUser(req.Request.Context(), user)
@@ -243,3 +429,272 @@ func (c *user) enableUser(req *restful.Request, res *restful.Response) {
 		return
 	}
 }
+
+func (c *user) listUserSessions(req *restful.Request, res *restful.Response) {
+	user := req.Request.Context().Value(&apis.CtxKeyUser).(*model.User)
+	sessions, err := c.SessionService.ListSessions(req.Request.Context(), user.Name)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(sessions); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) listLoginHistory(req *restful.Request, res *restful.Response) {
+	user := req.Request.Context().Value(&apis.CtxKeyUser).(*model.User)
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	records, err := c.UserService.ListLoginHistory(req.Request.Context(), user, page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(records); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) revokeUserSessions(req *restful.Request, res *restful.Response) {
+	user := req.Request.Context().Value(&apis.CtxKeyUser).(*model.User)
+	if err := c.SessionService.RevokeSessions(req.Request.Context(), user.Name, false); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) createInvitation(req *restful.Request, res *restful.Response) {
+	var createReq apis.CreateUserInvitationRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	invitation, err := c.UserInvitationService.CreateInvitation(req.Request.Context(), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(invitation); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) listInvitations(req *restful.Request, res *restful.Response) {
+	invitations, err := c.UserInvitationService.ListInvitations(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(invitations); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) deleteInvitation(req *restful.Request, res *restful.Response) {
+	if err := c.UserInvitationService.DeleteInvitation(req.Request.Context(), req.PathParameter("invitationID")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) getPreference(req *restful.Request, res *restful.Response) {
+	username, _ := req.Request.Context().Value(&apis.CtxKeyUser).(string)
+	preference, err := c.UserPreferenceService.GetPreference(req.Request.Context(), username)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(preference); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) updatePreference(req *restful.Request, res *restful.Response) {
+	var updateReq apis.UpdateUserPreferenceRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	username, _ := req.Request.Context().Value(&apis.CtxKeyUser).(string)
+	preference, err := c.UserPreferenceService.UpdatePreference(req.Request.Context(), username, updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(preference); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) changeEmail(req *restful.Request, res *restful.Response) {
+	var changeReq apis.ChangeEmailRequest
+	if err := req.ReadEntity(&changeReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&changeReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	username, _ := req.Request.Context().Value(&apis.CtxKeyUser).(string)
+	if err := c.EmailChangeService.RequestEmailChange(req.Request.Context(), username, changeReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) verifyEmail(req *restful.Request, res *restful.Response) {
+	var verifyReq apis.VerifyEmailRequest
+	if err := req.ReadEntity(&verifyReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&verifyReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	username, _ := req.Request.Context().Value(&apis.CtxKeyUser).(string)
+	user, err := c.EmailChangeService.VerifyEmailChange(req.Request.Context(), username, verifyReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(user); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) changePassword(req *restful.Request, res *restful.Response) {
+	var changeReq apis.ChangePasswordRequest
+	if err := req.ReadEntity(&changeReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&changeReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	user, err := c.UserService.ChangePassword(withClientInfo(req), changeReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(user); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) listEmailChanges(req *restful.Request, res *restful.Response) {
+	user := req.Request.Context().Value(&apis.CtxKeyUser).(*model.User)
+	resp, err := c.EmailChangeService.ListEmailChanges(req.Request.Context(), user.Name)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(resp); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) getPermissionSnapshot(req *restful.Request, res *restful.Response) {
+	at := time.Now()
+	if t := req.QueryParameter("time"); t != "" {
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			bcode.ReturnError(req, res, bcode.ErrInvalidSnapshotTime)
+			return
+		}
+		at = parsed
+	}
+	resp, err := c.PermissionSnapshotService.GetUserPermissionsAt(req.Request.Context(), req.PathParameter("username"), at)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(resp); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) uploadAvatar(req *restful.Request, res *restful.Response) {
+	var uploadReq apis.UploadAvatarRequest
+	if err := req.ReadEntity(&uploadReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&uploadReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	user, err := c.UserService.UploadAvatar(req.Request.Context(), uploadReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(user); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) deleteAvatar(req *restful.Request, res *restful.Response) {
+	user, err := c.UserService.DeleteAvatar(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(user); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *user) getAvatar(req *restful.Request, res *restful.Response) {
+	avatar, err := c.UserService.GetAvatar(req.Request.Context(), req.PathParameter("avatarID"))
+	if err != nil {
+		res.SetRequestAccepts(restful.MIME_JSON)
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	// a new ID is generated for every upload, so the image never changes
+	res.AddHeader("Cache-Control", "public, max-age=86400, immutable")
+	res.AddHeader("X-Content-Type-Options", "nosniff")
+	res.AddHeader(restful.HEADER_ContentType, avatar.ContentType)
+	if _, err := res.Write(avatar.Data); err != nil {
+		klog.Errorf("write the avatar failure %s", err.Error())
+	}
+}
@@ -35,6 +35,9 @@ var (
 const (
 	minPageSize = 5
 	maxPageSize = 100
+	// the pages of the step logs are counted by the lines
+	minLogPageSize = 100
+	maxLogPageSize = 5000
 )
 
 func init() {
@@ -52,6 +52,8 @@ func (c *webhook) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", apis.ApplicationDeployResponse{}).
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.ApplicationDeployResponse{}))
+
+	ws.Filter(authCheckFilter)
 	return ws
 }
 
@@ -157,7 +157,11 @@ func (w *Workflow) listWorkflowRecords(req *restful.Request, res *restful.Respon
 		return
 	}
 	workflow := req.Request.Context().Value(&apis.CtxKeyWorkflow).(*model.Workflow)
-	records, err := w.WorkflowService.ListWorkflowRecords(req.Request.Context(), workflow, page, pageSize)
+	records, err := w.WorkflowService.ListWorkflowRecords(req.Request.Context(), workflow, page, pageSize, apis.ListWorkflowRecordsOptions{
+		Commit:   req.QueryParameter("commit"),
+		Branch:   req.QueryParameter("branch"),
+		BuildURL: req.QueryParameter("buildURL"),
+	})
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -183,6 +187,28 @@ func (w *Workflow) detailWorkflowRecord(req *restful.Request, res *restful.Respo
 	}
 }
 
+func (w *Workflow) annotateWorkflowRecord(req *restful.Request, res *restful.Response) {
+	var annotateReq apis.AnnotateWorkflowRecordRequest
+	if err := req.ReadEntity(&annotateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&annotateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	record := req.Request.Context().Value(&apis.CtxKeyWorkflowRecord).(*model.WorkflowRecord)
+	resp, err := w.WorkflowService.AnnotateWorkflowRecord(req.Request.Context(), record, annotateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(resp); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (w *Workflow) resumeWorkflowRecord(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	workflow := req.Request.Context().Value(&apis.CtxKeyWorkflow).(*model.Workflow)
@@ -232,6 +258,10 @@ func (w *Workflow) getWorkflowRecordLogs(req *restful.Request, res *restful.Resp
 		bcode.ReturnError(req, res, err)
 		return
 	}
+	if err := pageStepLog(req, &logResponse); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
 	if err := res.WriteEntity(logResponse); err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -265,3 +295,19 @@ func (w *Workflow) getWorkflowRecordOutputs(req *restful.Request, res *restful.R
 		return
 	}
 }
+
+// pageStepLog cuts the page of the log lines if the page is queried, the whole log is returned otherwise
+func pageStepLog(req *restful.Request, logs *apis.GetPipelineRunLogResponse) error {
+	if req.QueryParameter("page") == "" {
+		return nil
+	}
+	page, pageSize, err := utils.ExtractPagingParams(req, minLogPageSize, maxLogPageSize)
+	if err != nil {
+		return err
+	}
+	if page == 0 {
+		page = 1
+	}
+	service.PageStepLog(logs, page, pageSize)
+	return nil
+}
@@ -26,6 +26,7 @@ import (
 	restfulSpec "github.com/emicklei/go-restful-openapi/v2"
 	"github.com/emicklei/go-restful/v3"
 	"github.com/go-openapi/spec"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog/v2"
@@ -41,12 +42,23 @@ import (
 	"github.com/kubevela/velaux/pkg/server/config"
 	"github.com/kubevela/velaux/pkg/server/domain/service"
 	"github.com/kubevela/velaux/pkg/server/event"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/changefeed"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/clients"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/cloudevent"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/cache"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/encryption"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/kubeapi"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/mongodb"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/mysql"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/sqlite"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/email"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/logstore"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/searchindex"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/slack"
 	"github.com/kubevela/velaux/pkg/server/interfaces/api"
 	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 	"github.com/kubevela/velaux/pkg/server/utils/container"
 )
 
@@ -92,6 +104,9 @@ func (s *restServer) buildIoCContainer() error {
 	if err != nil {
 		return err
 	}
+	if err := utils.SetTrustedProxies(s.cfg.TrustedProxies); err != nil {
+		return fmt.Errorf("fail to parse the trusted proxies: %w", err)
+	}
 	kubeConfig, err := clients.GetKubeConfig()
 	if err != nil {
 		return err
@@ -109,15 +124,40 @@ func (s *restServer) buildIoCContainer() error {
 		if err != nil {
 			return fmt.Errorf("create mongodb datastore instance failure %w", err)
 		}
+	case "mysql":
+		ds, err = mysql.New(context.Background(), s.cfg.Datastore)
+		if err != nil {
+			return fmt.Errorf("create mysql datastore instance failure %w", err)
+		}
 	case "kubeapi":
 		ds, err = kubeapi.New(context.Background(), s.cfg.Datastore, kubeClient)
 		if err != nil {
 			return fmt.Errorf("create kubeapi datastore instance failure %w", err)
 		}
+	case "sqlite":
+		ds, err = sqlite.New(context.Background(), s.cfg.Datastore)
+		if err != nil {
+			return fmt.Errorf("create sqlite datastore instance failure %w", err)
+		}
 	default:
 		return fmt.Errorf("not support datastore type %s", s.cfg.Datastore.Type)
 	}
-	s.dataStore = ds
+	// the cache is wrapped by the encryption, so the sensitive fields are cached encrypted
+	cacheBackend, err := cache.New(s.cfg.DatastoreCache)
+	if err != nil {
+		return fmt.Errorf("create the datastore cache failure %w", err)
+	}
+	cachedTables, err := cache.ParseTables(s.cfg.DatastoreCache.Tables, s.cfg.DatastoreCache.TTL)
+	if err != nil {
+		return err
+	}
+	ds = cache.Wrap(ds, cacheBackend, cachedTables)
+	keys, err := encryption.NewKeyProvider(s.cfg.DatastoreEncryption, kubeClient)
+	if err != nil {
+		return fmt.Errorf("create the datastore encryption key provider failure %w", err)
+	}
+	// the services join the transactions carried by the contexts, so a multi-entity write across them is atomic
+	s.dataStore = datastore.JoinContextTransaction(encryption.Wrap(ds, keys))
 	if err := s.beanContainer.ProvideWithName("datastore", s.dataStore); err != nil {
 		return fmt.Errorf("fail to provides the datastore bean to the container: %w", err)
 	}
@@ -132,6 +172,30 @@ func (s *restServer) buildIoCContainer() error {
 		return fmt.Errorf("fail to provides the apply bean to the container: %w", err)
 	}
 
+	if err := s.beanContainer.ProvideWithName("emailSender", email.New(s.cfg.Email)); err != nil {
+		return fmt.Errorf("fail to provides the email sender bean to the container: %w", err)
+	}
+
+	if err := s.beanContainer.ProvideWithName("slackSender", slack.New(s.cfg.Slack)); err != nil {
+		return fmt.Errorf("fail to provides the slack sender bean to the container: %w", err)
+	}
+
+	if err := s.beanContainer.ProvideWithName("logStore", logstore.New(s.cfg.LogStore)); err != nil {
+		return fmt.Errorf("fail to provides the log store bean to the container: %w", err)
+	}
+
+	if err := s.beanContainer.ProvideWithName("searchIndexer", searchindex.New(s.cfg.SearchIndex)); err != nil {
+		return fmt.Errorf("fail to provides the search indexer bean to the container: %w", err)
+	}
+
+	if err := s.beanContainer.ProvideWithName("cloudEventPublisher", cloudevent.New(s.cfg.SyncEvents)); err != nil {
+		return fmt.Errorf("fail to provides the cloud event publisher bean to the container: %w", err)
+	}
+
+	if err := s.beanContainer.ProvideWithName("changeFeed", changefeed.New()); err != nil {
+		return fmt.Errorf("fail to provides the change feed bean to the container: %w", err)
+	}
+
 	factory := pkgconfig.NewConfigFactory(authClient)
 	if err := s.beanContainer.ProvideWithName("configFactory", factory); err != nil {
 		return fmt.Errorf("fail to provides the config factory bean to the container: %w", err)
@@ -244,8 +308,8 @@ func (s *restServer) RegisterAPIRoute() restfulSpec.Config {
 	/* **************************************************************  */
 	// Add container filter to enable CORS
 	cors := restful.CrossOriginResourceSharing{
-		ExposeHeaders:  []string{},
-		AllowedHeaders: []string{"Content-Type", "Accept", "Authorization", "RefreshToken"},
+		ExposeHeaders:  []string{utils.HeaderRequestID},
+		AllowedHeaders: []string{"Content-Type", "Accept", "Authorization", "RefreshToken", utils.HeaderRequestID, utils.HeaderOrg},
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
 		CookiesAllowed: true,
 		Container:      s.webContainer}
@@ -255,8 +319,10 @@ func (s *restServer) RegisterAPIRoute() restfulSpec.Config {
 	s.webContainer.Filter(s.webContainer.OPTIONSFilter)
 	s.webContainer.Filter(s.OPTIONSFilter)
 
-	// Add request log
+	// Add request ID and request log
+	s.webContainer.Filter(s.requestID)
 	s.webContainer.Filter(s.requestLog)
+	s.webContainer.Filter(s.tenant)
 
 	// Register all custom api
 	for _, handler := range api.GetRegisteredAPI() {
@@ -271,6 +337,33 @@ func (s *restServer) RegisterAPIRoute() restfulSpec.Config {
 	return config
 }
 
+// requestID carries the request ID in the context, it is propagated to the kube-apiserver and the deployed applications
+func (s *restServer) requestID(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	requestID := utils.RequestID(req.Request)
+	req.Request = req.Request.WithContext(utils.WithRequestID(req.Request.Context(), requestID))
+	resp.AddHeader(utils.HeaderRequestID, requestID)
+	chain.ProcessFilter(req, resp)
+}
+
+// tenant carries the organization of the request in the context, the datastore isolates the data of the organizations.
+// The event streams and the websockets could not set the header, they use the org query parameter.
+func (s *restServer) tenant(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	org := req.HeaderParameter(utils.HeaderOrg)
+	if org == "" {
+		org = req.QueryParameter("org")
+	}
+	if org == "" {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	if !pkgUtils.StringsContain(s.cfg.Datastore.Tenants, org) {
+		bcode.ReturnError(req, resp, bcode.ErrOrgNotExist)
+		return
+	}
+	req.Request = req.Request.WithContext(datastore.WithTenant(req.Request.Context(), org))
+	chain.ProcessFilter(req, resp)
+}
+
 func (s *restServer) requestLog(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
 	if req.HeaderParameter("Upgrade") == "websocket" && req.HeaderParameter("Connection") == "Upgrade" {
 		chain.ProcessFilter(req, resp)
@@ -281,6 +374,7 @@ func (s *restServer) requestLog(req *restful.Request, resp *restful.Response, ch
 	resp.ResponseWriter = c
 	chain.ProcessFilter(req, resp)
 	takeTime := time.Since(start)
+	requestID, _ := utils.RequestIDFrom(req.Request.Context())
 	klog.InfoS("request log",
 		"clientIP", pkgUtils.Sanitize(utils.ClientIP(req.Request)),
 		"path", pkgUtils.Sanitize(req.Request.URL.Path),
@@ -288,6 +382,7 @@ func (s *restServer) requestLog(req *restful.Request, resp *restful.Response, ch
 		"status", c.StatusCode(),
 		"time", takeTime.String(),
 		"responseSize", len(c.Bytes()),
+		"requestID", requestID,
 	)
 }
 
@@ -331,6 +426,9 @@ func (s *restServer) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	case strings.HasPrefix(req.URL.Path, BuildPublicRoutePath):
 		s.staticFiles(res, req, "./")
 		return
+	case s.cfg.MetricPath != "" && req.URL.Path == s.cfg.MetricPath:
+		promhttp.Handler().ServeHTTP(res, req)
+		return
 	default:
 		for _, pre := range api.GetAPIPrefix() {
 			if strings.HasPrefix(req.URL.Path, pre) {
@@ -102,3 +102,42 @@ var ErrApplicationDryRunFailed = NewBcode(400, 10027, "The application dry run f
 
 // ErrApplicationRevisionConflict -
 var ErrApplicationRevisionConflict = NewBcode(400, 10028, "The current revision of the application is equal to the requested revision")
+
+// ErrApplicationNoticeNotAcknowledged means the application has a blocking notice that is not acknowledged when deploying
+var ErrApplicationNoticeNotAcknowledged = NewBcode(400, 10029, "the application has a blocking notice, acknowledge it to deploy")
+
+// ErrApplicationNoticeNotExist means the application has no notice
+var ErrApplicationNoticeNotExist = NewBcode(404, 10030, "the application has no notice")
+
+// ErrApplicationLintFailed means the component violates the lint rules with the error severity
+var ErrApplicationLintFailed = NewBcode(400, 10031, "the component violates the lint rules")
+
+// ErrApplicationManagedExternally means the application is imported from the cluster as read-only
+var ErrApplicationManagedExternally = NewBcode(403, 10032, "the application is managed outside VelaUX, update it by its source such as kubectl or the GitOps repository")
+
+// ErrInvalidWebhookSecret means the secret of the trigger is too short
+var ErrInvalidWebhookSecret = NewBcode(400, 10033, "the secret of the trigger must have at least 16 characters")
+
+// ErrInvalidWebhookSignature means the signature of the delivery is missing or does not match the secret of the trigger
+var ErrInvalidWebhookSignature = NewBcode(401, 10034, "the signature of the webhook delivery is invalid")
+
+// ErrWebhookDeliveryExpired means the timestamp of the delivery is out of the tolerance
+var ErrWebhookDeliveryExpired = NewBcode(401, 10035, "the timestamp of the webhook delivery is missing or out of the tolerance")
+
+// ErrWebhookDeliveryReplayed means the nonce of the delivery has been used
+var ErrWebhookDeliveryReplayed = NewBcode(409, 10036, "the nonce of the webhook delivery has been used")
+
+// ErrInvalidOwnership means the on-call URL or the Slack channel of the ownership is invalid
+var ErrInvalidOwnership = NewBcode(400, 10037, "the on-call URL must be an http(s) URL and the Slack channel must be a channel name or ID")
+
+// ErrApplicationNotDeployedInEnv means the application has not been deployed in the env
+var ErrApplicationNotDeployedInEnv = NewBcode(404, 10038, "the application has not been deployed in the environment")
+
+// ErrInvalidScaleRequest means the components to scale are missing or the replicas are invalid
+var ErrInvalidScaleRequest = NewBcode(400, 10039, "the components to scale are required and the replicas must not be negative")
+
+// ErrApplicationConflictNotExist means the application CR in the env does not diverge from the deployed revision
+var ErrApplicationConflictNotExist = NewBcode(404, 10040, "the application in the environment does not conflict with the revision deployed by VelaUX")
+
+// ErrInvalidSyncScope means the label selector of the sync scope could not be parsed
+var ErrInvalidSyncScope = NewBcode(400, 10041, "the label selector of the sync scope is invalid")
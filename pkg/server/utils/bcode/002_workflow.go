@@ -33,3 +33,6 @@ var ErrWorkflowNoEnv = NewBcode(400, 20006, "workflow must set env name")
 
 // ErrWorkflowRecordNotExist workflow record is not exist
 var ErrWorkflowRecordNotExist = NewBcode(404, 20007, "workflow record is not exist")
+
+// ErrInvalidBuildMetadata the build metadata of the workflow record is invalid
+var ErrInvalidBuildMetadata = NewBcode(400, 20008, "the build metadata is invalid, it must not be empty and the test counts must not exceed the total")
@@ -45,3 +45,27 @@ var ErrProjectUserExist = NewBcode(400, 30009, "the user is already exist in thi
 
 // ErrProjectOwnerIsNotExist means the project owner name is invalid
 var ErrProjectOwnerIsNotExist = NewBcode(400, 30010, "the project owner name is invalid")
+
+// ErrProjectLocked means the write requests are rejected because the project is locked
+var ErrProjectLocked = NewBcode(403, 30011, "the project is locked, only the read requests are allowed until the lock expires or is removed")
+
+// ErrProjectNotLocked means the project is not locked
+var ErrProjectNotLocked = NewBcode(400, 30012, "the project is not locked")
+
+// ErrSearchNotEnabled means the search index is not configured
+var ErrSearchNotEnabled = NewBcode(400, 30013, "the search is not enabled, the search index endpoint is not configured")
+
+// ErrInvalidSearchKind means the kind of the searched documents is not supported
+var ErrInvalidSearchKind = NewBcode(400, 30014, "the kind of the searched documents must be audit or log")
+
+// ErrSearchKeywordRequired means the global search has no keyword
+var ErrSearchKeywordRequired = NewBcode(400, 30015, "the keywords of the search are required")
+
+// ErrInvalidSearchEntityKind means the kind of the searched entities is not supported
+var ErrInvalidSearchEntityKind = NewBcode(400, 30016, "the kind of the searched entities must be application, pipeline, user or config")
+
+// ErrTrashItemNotExist means the item is not in the trash, it has been restored or purged
+var ErrTrashItemNotExist = NewBcode(404, 30017, "the item is not in the trash, it may have been restored or purged")
+
+// ErrTrashRestoreConflict means the entity could not be restored because another one of the same name exists
+var ErrTrashRestoreConflict = NewBcode(400, 30018, "an entity of the same name exists, delete it before restoring the item")
@@ -60,3 +60,12 @@ var ErrInvalidAccessKeyOrSecretKey = NewBcode(400, 40013, "access key or secret
 
 // ErrClusterCreateNamespaceNoPermission cluster create namespace is forbidden
 var ErrClusterCreateNamespaceNoPermission = NewBcode(401, 40014, "no permission to create namespace in cluster")
+
+// ErrCAPINotInstalled the Cluster API is not installed in the control plane
+var ErrCAPINotInstalled = NewBcode(400, 40015, "the cluster api is not installed, please install it first")
+
+// ErrCAPIClusterNotFound the cluster api cluster not found
+var ErrCAPIClusterNotFound = NewBcode(404, 40016, "the cluster api cluster not found")
+
+// ErrCAPIClusterWorkerPoolNotFound the worker pool to scale not found
+var ErrCAPIClusterWorkerPoolNotFound = NewBcode(400, 40017, "the worker pool of the cluster not found")
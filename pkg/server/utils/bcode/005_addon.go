@@ -76,6 +76,24 @@ var (
 
 	// ErrRegistryNotExist means the specified registry not exist
 	ErrRegistryNotExist = NewBcode(400, 50022, "The specified not exist")
+
+	// ErrAddonRegistryOffline means the addon registries can not be reached in the offline mode
+	ErrAddonRegistryOffline = NewBcode(503, 50023, "the addon registries are unavailable in the offline mode")
+
+	// ErrAddonRolloutNoCluster means no cluster is selected by the addon rollout
+	ErrAddonRolloutNoCluster = NewBcode(400, 50024, "no cluster is selected to roll out the addon")
+
+	// ErrAddonRolloutNotExist means the addon rollout does not exist
+	ErrAddonRolloutNotExist = NewBcode(404, 50025, "the addon rollout does not exist")
+
+	// ErrAddonRolloutInProgress means another rollout of the addon is running
+	ErrAddonRolloutInProgress = NewBcode(400, 50026, "the addon rollout is in progress")
+
+	// ErrAddonRolloutNotFailed means the addon rollout has no failed cluster to retry
+	ErrAddonRolloutNotFailed = NewBcode(400, 50027, "the addon rollout has no failed cluster to retry")
+
+	// ErrAddonNotEnabled means the addon must be enabled before upgrading
+	ErrAddonNotEnabled = NewBcode(400, 50028, "the addon is not enabled")
 )
 
 // isGithubRateLimit check if error is github rate limit
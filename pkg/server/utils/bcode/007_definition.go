@@ -27,3 +27,18 @@ var ErrDefinitionTypeNotSupport = NewBcode(400, 70003, "definition type not supp
 
 // ErrInvalidDefinitionUISchema invalid custom definition ui schema
 var ErrInvalidDefinitionUISchema = NewBcode(400, 70004, "invalid custom defnition ui schema")
+
+// ErrDefinitionCatalogNotExist the definition catalog is not exist
+var ErrDefinitionCatalogNotExist = NewBcode(404, 70005, "the definition catalog is not exist")
+
+// ErrDefinitionCatalogExist the definition catalog is exist
+var ErrDefinitionCatalogExist = NewBcode(400, 70006, "the definition catalog is exist")
+
+// ErrDefinitionCatalogSignature the commit of the catalog is not signed by the trusted keys
+var ErrDefinitionCatalogSignature = NewBcode(400, 70007, "the commit of the definition catalog is not signed by the trusted keys")
+
+// ErrDefinitionCatalogFetch failed to fetch the definition catalog repository
+var ErrDefinitionCatalogFetch = NewBcode(400, 70008, "failed to fetch the definition catalog repository")
+
+// ErrDefinitionCatalogOffline the definition catalog can not be synced in the offline mode
+var ErrDefinitionCatalogOffline = NewBcode(503, 70009, "the definition catalogs can not be synced in the offline mode")
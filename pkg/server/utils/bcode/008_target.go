@@ -30,3 +30,15 @@ var ErrTargetNamespaceAlreadyBound = NewBcode(400, 80004, "the namespace specifi
 
 // ErrTargetInvalidWithEmptyClusterOrNamespace indicates the namespace/cluster of target is empty
 var ErrTargetInvalidWithEmptyClusterOrNamespace = NewBcode(400, 80005, "the namespace or cluster of target should not be empty")
+
+// ErrTargetNotBelongToEnv the target is not one of the targets of the environment
+var ErrTargetNotBelongToEnv = NewBcode(400, 80006, "the target does not belong to the environment")
+
+// ErrWorkloadNotExist the workload is not exist in the target namespace
+var ErrWorkloadNotExist = NewBcode(404, 80007, "the workload is not exist in the target")
+
+// ErrWorkloadAlreadyManaged the workload is already managed by an application
+var ErrWorkloadAlreadyManaged = NewBcode(400, 80008, "the workload is already managed by an application")
+
+// ErrInvalidNamespacePolicy the namespace policy of the target is invalid
+var ErrInvalidNamespacePolicy = NewBcode(400, 80009, "the namespace policy is invalid, the labels and annotations must be valid and not managed by KubeVela, the pod security must be privileged, baseline or restricted, the network policy must be deny-all-ingress, deny-all or allow-same-namespace")
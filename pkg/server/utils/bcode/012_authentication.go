@@ -39,4 +39,48 @@ var (
 	ErrRefreshTokenExpired = NewBcode(400, 12010, "the refresh token is expired")
 	// ErrNoDexConnector is the error of no dex connector
 	ErrNoDexConnector = NewBcode(400, 12011, "there is no dex connector")
+	// ErrPasswordResetDisabled means the password could not be reset because the email sender is not configured
+	ErrPasswordResetDisabled = NewBcode(400, 12012, "the password reset is disabled, please contact the administrator")
+	// ErrPasswordResetTokenInvalid is the error of invalid password reset token
+	ErrPasswordResetTokenInvalid = NewBcode(400, 12013, "the password reset token is invalid")
+	// ErrPasswordResetTokenExpired is the error of expired password reset token
+	ErrPasswordResetTokenExpired = NewBcode(400, 12014, "the password reset token is expired")
+	// ErrPasswordResetEmailFailure is the error of failing to send the password reset email
+	ErrPasswordResetEmailFailure = NewBcode(500, 12015, "failed to send the password reset email")
+	// ErrLoginLocked means the user or the client is locked because of too many failed login attempts
+	ErrLoginLocked = NewBcode(429, 12016, "too many failed login attempts, please try again later")
+	// ErrAPITokenInvalid is the error of invalid API token
+	ErrAPITokenInvalid = NewBcode(401, 12017, "the API token is invalid")
+	// ErrAPITokenExpired is the error of expired API token
+	ErrAPITokenExpired = NewBcode(401, 12018, "the API token is expired")
+	// ErrAPITokenExist means the user already has an API token with the same name
+	ErrAPITokenExist = NewBcode(400, 12019, "the API token is exist")
+	// ErrAPITokenNotExist is the error of API token not exist
+	ErrAPITokenNotExist = NewBcode(404, 12020, "the API token is not exist")
+	// ErrAPITokenNotAllowed means the API tokens could only be created by the login session
+	ErrAPITokenNotAllowed = NewBcode(403, 12021, "the API token could not be used to create the API tokens")
+	// ErrSessionRevoked means the session of the token is revoked or expired
+	ErrSessionRevoked = NewBcode(401, 12022, "the session is revoked, please login again")
+	// ErrSessionNotExist is the error of session not exist
+	ErrSessionNotExist = NewBcode(404, 12023, "the session is not exist")
+	// ErrOAuthConnectorExist means the ID of the OAuth connector is duplicated
+	ErrOAuthConnectorExist = NewBcode(400, 12024, "the OAuth connector ID is duplicated")
+	// ErrOAuthConnectorSecretRequired means the new OAuth connector has no client secret
+	ErrOAuthConnectorSecretRequired = NewBcode(400, 12025, "the client secret of the new OAuth connector is required")
+	// ErrInvalidOAuthGroupMapping means the group of the mapping is empty
+	ErrInvalidOAuthGroupMapping = NewBcode(400, 12026, "the group of the OAuth group mapping is required")
+	// ErrInvalidSessionSettings means the token lifetime or the idle timeout is out of range
+	ErrInvalidSessionSettings = NewBcode(400, 12027, "the session settings are invalid, the access token TTL must be 5 to 1440 minutes and not longer than the refresh token TTL, the refresh token TTL must be at most 43200 minutes, the idle timeout must be 0 or 5 to 43200 minutes and the max concurrent sessions must be 0 to 100")
+	// ErrSessionIdleTimeout means the session is revoked because it is not used for the idle timeout
+	ErrSessionIdleTimeout = NewBcode(401, 12028, "the session is expired because of inactivity, please login again")
+	// ErrPasswordResetRateLimited means the client requests the password reset too frequently
+	ErrPasswordResetRateLimited = NewBcode(429, 12029, "too many password reset requests, please try again later")
+	// ErrLoginConsentRequired means the user has not acknowledged the current version of the login notice
+	ErrLoginConsentRequired = NewBcode(403, 12030, "the login notice must be acknowledged before login, please reload the login page")
+	// ErrInvalidLoginBanner means the consent is required but the login notice is empty
+	ErrInvalidLoginBanner = NewBcode(400, 12031, "the content of the login notice is required if the consent is required")
+	// ErrSessionLimitExceeded means the user has reached the max concurrent sessions and the oldest sessions are not evicted
+	ErrSessionLimitExceeded = NewBcode(403, 12032, "the user has too many active sessions, please logout or revoke the other sessions first")
+	// ErrOrgNotExist means the organization of the request is not hosted by the datastore
+	ErrOrgNotExist = NewBcode(404, 12033, "the organization is not exist")
 )
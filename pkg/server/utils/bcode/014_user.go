@@ -18,7 +18,7 @@ package bcode
 
 var (
 	// ErrUnsupportedEmailModification is the error of unsupported email modification
-	ErrUnsupportedEmailModification = NewBcode(400, 14001, "the user already has an email address and cannot modify it again")
+	ErrUnsupportedEmailModification = NewBcode(400, 14001, "the user already has an email address, change it with the email verification")
 	// ErrUserAlreadyDisabled is the error of user already disabled
 	ErrUserAlreadyDisabled = NewBcode(400, 14002, "the user is already disabled")
 	// ErrUserAlreadyEnabled is the error of user already enabled
@@ -37,4 +37,54 @@ var (
 	ErrDexNotFound = NewBcode(200, 14009, "the dex is not found")
 	// ErrEmptyAdminEmail is the error of empty admin email
 	ErrEmptyAdminEmail = NewBcode(400, 14010, "the admin email is empty, please set the admin email before using sso login")
+	// ErrUserNotLocked is the error of unlocking a user that is not locked
+	ErrUserNotLocked = NewBcode(400, 14011, "the user is not locked")
+	// ErrInvitationDisabled means the users could not be invited because the email sender is not configured
+	ErrInvitationDisabled = NewBcode(400, 14012, "the invitation is disabled, please configure the email sender")
+	// ErrInvitationInvalid is the error of invalid invitation token
+	ErrInvitationInvalid = NewBcode(400, 14013, "the invitation is invalid")
+	// ErrInvitationExpired is the error of expired invitation
+	ErrInvitationExpired = NewBcode(400, 14014, "the invitation is expired")
+	// ErrInvitationEmailFailure is the error of failing to send the invitation email
+	ErrInvitationEmailFailure = NewBcode(500, 14015, "failed to send the invitation email")
+	// ErrInvitationUserExist means the invited email or the chosen username is used by another user
+	ErrInvitationUserExist = NewBcode(400, 14016, "the user with the same name or email is exist")
+	// ErrInvitationNotExist is the error of invitation not exist
+	ErrInvitationNotExist = NewBcode(404, 14017, "the invitation is not exist")
+	// ErrBootstrapTokenInvalid is the error of invalid bootstrap token
+	ErrBootstrapTokenInvalid = NewBcode(401, 14018, "the bootstrap token is invalid")
+	// ErrBootstrapCompleted means the admin user is already initialized and the bootstrap token is consumed
+	ErrBootstrapCompleted = NewBcode(400, 14019, "the bootstrap is completed")
+	// ErrUnknownNotificationEvent is the error of disabling an unknown notification event in the user preferences
+	ErrUnknownNotificationEvent = NewBcode(400, 14020, "the notification event is unknown")
+	// ErrInvalidNotificationPreference is the error of the invalid notification destinations or rules
+	ErrInvalidNotificationPreference = NewBcode(400, 14021, "the notification destinations or rules are invalid")
+	// ErrUserOwnsResources means the user can not be deleted before transferring the owned resources
+	ErrUserOwnsResources = NewBcode(400, 14022, "the user owns some resources, transfer them to another user before deleting")
+	// ErrInvalidTransferUser is the error of transferring the owned resources to a disabled, missing or the same user
+	ErrInvalidTransferUser = NewBcode(400, 14023, "the user to transfer the owned resources to is invalid")
+	// ErrEmailChangeDisabled means the email could not be changed because the email sender is not configured
+	ErrEmailChangeDisabled = NewBcode(400, 14024, "the email change is disabled, please contact the administrator")
+	// ErrEmailVerificationTokenInvalid is the error of invalid email verification token
+	ErrEmailVerificationTokenInvalid = NewBcode(400, 14025, "the email verification token is invalid")
+	// ErrEmailVerificationTokenExpired is the error of expired email verification token
+	ErrEmailVerificationTokenExpired = NewBcode(400, 14026, "the email verification token is expired")
+	// ErrEmailVerificationFailure is the error of failing to send the email verification token
+	ErrEmailVerificationFailure = NewBcode(500, 14027, "failed to send the email verification token")
+	// ErrUserEmailExist means the email is used by another user
+	ErrUserEmailExist = NewBcode(400, 14028, "the email is used by another user")
+	// ErrInvalidDeactivateTime means the scheduled deactivation time is not in the future
+	ErrInvalidDeactivateTime = NewBcode(400, 14029, "the deactivation time must be in the future")
+	// ErrInvitationAddressRequired means the invite link could not be built because the VelaUX address is not configured
+	ErrInvitationAddressRequired = NewBcode(400, 14030, "the VelaUX address is required to invite the users, please set it in the platform settings")
+	// ErrPasswordChangeRequired means the user must change the password before using the other APIs
+	ErrPasswordChangeRequired = NewBcode(403, 14031, "the password must be changed before using the other APIs")
+	// ErrPasswordNotChanged means the new password is the same as the current password
+	ErrPasswordNotChanged = NewBcode(400, 14032, "the new password must be different from the current password")
+	// ErrInvalidAvatar means the uploaded avatar is not a PNG, JPEG, GIF or WebP image
+	ErrInvalidAvatar = NewBcode(400, 14033, "the avatar must be a PNG, JPEG, GIF or WebP image")
+	// ErrAvatarTooLarge means the uploaded avatar is larger than the limit
+	ErrAvatarTooLarge = NewBcode(400, 14034, "the avatar must not be larger than 256KB")
+	// ErrAvatarNotExist means the avatar is not exist or has been replaced
+	ErrAvatarNotExist = NewBcode(404, 14035, "the avatar is not exist")
 )
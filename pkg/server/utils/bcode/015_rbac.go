@@ -29,4 +29,28 @@ var (
 	ErrPermissionIsExist = NewBcode(400, 15005, "the permission name is exist")
 	// ErrPermissionIsUsed means the permission is bound by role, can not be deleted
 	ErrPermissionIsUsed = NewBcode(400, 15006, "the permission have been used")
+	// ErrProjectRoleTemplateIsExist means the project role template or the default project role with the same name is exist
+	ErrProjectRoleTemplateIsExist = NewBcode(400, 15007, "the project role template name is exist")
+	// ErrProjectRoleTemplateIsNotExist means the project role template is not exist
+	ErrProjectRoleTemplateIsNotExist = NewBcode(404, 15008, "the project role template is not exist")
+	// ErrInvalidResourceSelector means the label selector in the permission resources is invalid
+	ErrInvalidResourceSelector = NewBcode(400, 15009, "the label selector of the resource is invalid")
+	// ErrRBACChangePendingApproval means the change has admin-equivalent reach and takes effect after being approved by another user
+	ErrRBACChangePendingApproval = NewBcode(202, 15010, "the change is pending approval by another user with the rbac-approver permission")
+	// ErrRBACApprovalNotExist means the approval is not exist
+	ErrRBACApprovalNotExist = NewBcode(404, 15011, "the rbac approval is not exist")
+	// ErrRBACApprovalBySelf means the requester can not review the change by self
+	ErrRBACApprovalBySelf = NewBcode(403, 15012, "the change must be reviewed by another user")
+	// ErrRBACApprovalIsClosed means the approval has been approved or rejected
+	ErrRBACApprovalIsClosed = NewBcode(400, 15013, "the rbac approval has been closed")
+	// ErrMaintenanceMode means the write requests are rejected because the platform is in the maintenance mode
+	ErrMaintenanceMode = NewBcode(503, 15014, "the platform is in the maintenance mode, only the read requests are allowed")
+	// ErrRoleProjectScopeRequired means the cross-project role must select the projects by the names or the labels
+	ErrRoleProjectScopeRequired = NewBcode(400, 15015, "the projects or the project selector of the cross-project role is required")
+	// ErrPermissionSnapshotNotExist means the permissions of the user are not snapshotted before the time
+	ErrPermissionSnapshotNotExist = NewBcode(404, 15016, "the permissions of the user are not snapshotted before the time")
+	// ErrInvalidSnapshotTime means the time of the permission snapshot query is not in the RFC3339 format
+	ErrInvalidSnapshotTime = NewBcode(400, 15017, "the time must be in the RFC3339 format, such as 2006-01-02T15:04:05Z")
+	// ErrInvalidSortOptions means the roles or the perm policies can not be sorted by the field or the order
+	ErrInvalidSortOptions = NewBcode(400, 15018, "the sort field must be name or createTime, and the order must be asc or desc")
 )
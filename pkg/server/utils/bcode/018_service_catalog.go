@@ -0,0 +1,32 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bcode
+
+var (
+	// ErrServiceClassNotExist means the definition is not a service class of the catalog
+	ErrServiceClassNotExist = NewBcode(404, 18001, "the service class is not exist in the catalog")
+	// ErrServiceInstanceNotExist means the service instance is not exist
+	ErrServiceInstanceNotExist = NewBcode(404, 18002, "the service instance is not exist")
+	// ErrServiceInstanceExist means the service instance is exist
+	ErrServiceInstanceExist = NewBcode(400, 18003, "the service instance is exist")
+	// ErrServiceInstanceQuotaExceeded means the service instances of the project reach the quota
+	ErrServiceInstanceQuotaExceeded = NewBcode(403, 18004, "the service instances of the project reach the quota")
+	// ErrServiceInstanceIsNotPending means the service instance has been approved or rejected
+	ErrServiceInstanceIsNotPending = NewBcode(400, 18005, "the service instance is not pending approval")
+	// ErrServiceInstanceApprovalBySelf means the requester can not approve the service instance by self
+	ErrServiceInstanceApprovalBySelf = NewBcode(403, 18006, "the service instance must be reviewed by another user")
+)
@@ -0,0 +1,28 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bcode
+
+var (
+	// ErrStatusBadgeNotExist means the badge is not exist or the token of the badge is invalid
+	ErrStatusBadgeNotExist = NewBcode(404, 19001, "the status badge is not exist")
+	// ErrStatusBadgeDisabled means the badge is disabled by the owner
+	ErrStatusBadgeDisabled = NewBcode(403, 19002, "the status badge is disabled")
+	// ErrStatusBadgeRateLimited means the client requests the badges too frequently
+	ErrStatusBadgeRateLimited = NewBcode(429, 19003, "too many requests of the status badges, please try again later")
+	// ErrStatusBadgeEnvRequired means the environment is required by the badge of the application
+	ErrStatusBadgeEnvRequired = NewBcode(400, 19004, "the environment is required by the application status badge")
+)
@@ -42,6 +42,9 @@ var ErrForbidden = NewBcode(403, 403, "403 Forbidden")
 // ErrUnauthorized check user auth failure
 var ErrUnauthorized = NewBcode(401, 401, "401 Unauthorized")
 
+// ErrConflict the record is modified by others since it was read, the clients should get it again and retry
+var ErrConflict = NewBcode(409, 409, "the record has been modified by others, please refresh and retry")
+
 // Bcode business error code
 type Bcode struct {
 	HTTPCode     int32 `json:"-"`
@@ -93,6 +96,12 @@ func ReturnError(req *restful.Request, res *restful.Response, err error) {
 		}
 		return
 	}
+	if errors.Is(err, datastore.ErrRecordConflict) {
+		if err := res.WriteHeaderAndEntity(int(ErrConflict.HTTPCode), ErrConflict); err != nil {
+			klog.Errorf("write entity failure %s", err.Error())
+		}
+		return
+	}
 	var restfulerr restful.ServiceError
 	if errors.As(err, &restfulerr) {
 		if err := res.WriteHeaderAndEntity(restfulerr.Code, Bcode{HTTPCode: int32(restfulerr.Code), BusinessCode: int32(restfulerr.Code), Message: restfulerr.Message}); err != nil {
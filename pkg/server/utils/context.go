@@ -25,6 +25,9 @@ type contextKey int
 const (
 	projectKey contextKey = iota
 	usernameKey
+	requestIDKey
+	clientIPKey
+	userAgentKey
 )
 
 // WithProject carries project in context
@@ -48,3 +51,36 @@ func UsernameFrom(ctx context.Context) (string, bool) {
 	username, ok := ctx.Value(usernameKey).(string)
 	return username, ok
 }
+
+// WithRequestID carries the ID of the API request in context
+func WithRequestID(parent context.Context, requestID string) context.Context {
+	return context.WithValue(parent, requestIDKey, requestID)
+}
+
+// RequestIDFrom extract the ID of the API request from context
+func RequestIDFrom(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey).(string)
+	return requestID, ok && requestID != ""
+}
+
+// WithClientIP carries the IP of the client in context
+func WithClientIP(parent context.Context, ip string) context.Context {
+	return context.WithValue(parent, clientIPKey, ip)
+}
+
+// ClientIPFrom extract the IP of the client from context
+func ClientIPFrom(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey).(string)
+	return ip, ok && ip != ""
+}
+
+// WithUserAgent carries the user agent of the client in context
+func WithUserAgent(parent context.Context, userAgent string) context.Context {
+	return context.WithValue(parent, userAgentKey, userAgent)
+}
+
+// UserAgentFrom extract the user agent of the client from context
+func UserAgentFrom(ctx context.Context) (string, bool) {
+	userAgent, ok := ctx.Value(userAgentKey).(string)
+	return userAgent, ok && userAgent != ""
+}
@@ -20,7 +20,10 @@ import (
 	"bytes"
 	"net"
 	"net/http"
+	"regexp"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 // ClientIP get client ip
@@ -43,6 +46,61 @@ func ClientIP(r *http.Request) string {
 	return ""
 }
 
+// trustedProxies the networks of the reverse proxies whose forwarded headers are trusted
+var trustedProxies []*net.IPNet
+
+// SetTrustedProxies sets the CIDRs of the reverse proxies whose X-Forwarded-For headers are trusted
+func SetTrustedProxies(cidrs []string) error {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return err
+		}
+		networks = append(networks, network)
+	}
+	trustedProxies = networks
+	return nil
+}
+
+func isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// TrustedClientIP returns the IP of the client that could not be spoofed, it is used as the key of the throttles.
+// The X-Forwarded-For header is only used if the request comes from a trusted proxy, and the addresses are
+// read from the right, the first one that is not a trusted proxy is the client.
+func TrustedClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		ip = strings.TrimSpace(r.RemoteAddr)
+	}
+	if !isTrustedProxy(ip) {
+		return ip
+	}
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if hop == "" {
+			continue
+		}
+		if !isTrustedProxy(hop) {
+			return hop
+		}
+		ip = hop
+	}
+	return ip
+}
+
 // ResponseCapture capture response and get response info
 type ResponseCapture struct {
 	http.ResponseWriter
@@ -90,3 +148,44 @@ func (c ResponseCapture) Bytes() []byte {
 func (c ResponseCapture) StatusCode() int {
 	return c.status
 }
+
+const (
+	// HeaderRequestID the header of the request ID, it is read from the API requests and set to the responses
+	HeaderRequestID = "X-Request-Id"
+	// HeaderOrg the header of the organization of the request, the requests without it use the default partition of the datastore
+	HeaderOrg = "X-VelaUX-Org"
+	// HeaderAuditID the header that the kube-apiserver uses as the ID of the audit events
+	HeaderAuditID = "Audit-ID"
+)
+
+var requestIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID returns the request ID set by the client or the proxy, a new ID is generated if it is absent or malformed
+func RequestID(r *http.Request) string {
+	if requestID := r.Header.Get(HeaderRequestID); requestIDRegexp.MatchString(requestID) {
+		return requestID
+	}
+	return uuid.New().String()
+}
+
+// requestIDRoundTripper sets the ID of the API request to the requests to the kube-apiserver
+type requestIDRoundTripper struct {
+	rt http.RoundTripper
+}
+
+// NewRequestIDRoundTripper propagates the request ID from the context, so the audit events
+// of the control plane and the managed clusters could be correlated with the API request
+func NewRequestIDRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	return &requestIDRoundTripper{rt: rt}
+}
+
+func (r *requestIDRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestID, ok := RequestIDFrom(req.Context())
+	if !ok {
+		return r.rt.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(HeaderRequestID, requestID)
+	req.Header.Set(HeaderAuditID, requestID)
+	return r.rt.RoundTrip(req)
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package utils
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -37,4 +39,55 @@ var _ = Describe("Test http utils", func() {
 		clientIP = ClientIP(req)
 		Expect(cmp.Diff(clientIP, "198.23.1.2")).Should(BeEmpty())
 	})
+
+	It("Test get the trusted client IP", func() {
+		defer func() {
+			Expect(SetTrustedProxies(nil)).Should(BeNil())
+		}()
+		req, err := http.NewRequest("POST", "/api/v1/auth/login", nil)
+		Expect(err).Should(BeNil())
+		req.RemoteAddr = "10.0.0.5:34567"
+		req.Header.Set("X-Forwarded-For", "1.1.1.1, 203.0.113.7")
+		Expect(TrustedClientIP(req)).Should(Equal("10.0.0.5"))
+
+		Expect(SetTrustedProxies([]string{"10.0.0.0/8"})).Should(BeNil())
+		Expect(TrustedClientIP(req)).Should(Equal("203.0.113.7"))
+		req.Header.Set("X-Forwarded-For", "1.1.1.1, 203.0.113.7, 10.0.0.4")
+		Expect(TrustedClientIP(req)).Should(Equal("203.0.113.7"))
+
+		req.RemoteAddr = "198.51.100.1:34567"
+		Expect(TrustedClientIP(req)).Should(Equal("198.51.100.1"))
+		Expect(SetTrustedProxies([]string{"invalid"})).ShouldNot(BeNil())
+	})
+
+	It("Test propagate the request ID", func() {
+		req, err := http.NewRequest("GET", "/api/v1/applications", nil)
+		Expect(err).Should(BeNil())
+		Expect(RequestID(req)).ShouldNot(BeEmpty())
+		req.Header.Set(HeaderRequestID, "bad id\n")
+		Expect(RequestID(req)).ShouldNot(Equal("bad id\n"))
+		req.Header.Set(HeaderRequestID, "req-123")
+		Expect(RequestID(req)).Should(Equal("req-123"))
+
+		var received http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header
+		}))
+		defer server.Close()
+		cli := &http.Client{Transport: NewRequestIDRoundTripper(http.DefaultTransport)}
+
+		kubeReq, err := http.NewRequestWithContext(WithRequestID(context.TODO(), "req-123"), "GET", server.URL, nil)
+		Expect(err).Should(BeNil())
+		_, err = cli.Do(kubeReq)
+		Expect(err).Should(BeNil())
+		Expect(received.Get(HeaderRequestID)).Should(Equal("req-123"))
+		Expect(received.Get(HeaderAuditID)).Should(Equal("req-123"))
+		Expect(kubeReq.Header.Get(HeaderRequestID)).Should(BeEmpty())
+
+		kubeReq, err = http.NewRequest("GET", server.URL, nil)
+		Expect(err).Should(BeNil())
+		_, err = cli.Do(kubeReq)
+		Expect(err).Should(BeNil())
+		Expect(received.Get(HeaderAuditID)).Should(BeEmpty())
+	})
 })
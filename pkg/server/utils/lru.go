@@ -0,0 +1,148 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRUCache is a memory cache bounded by the number of the entries, the least recently used entry is evicted
+// when the cache is full, and the entries expire after the TTL. A nil cache never caches anything.
+type LRUCache struct {
+	mutex    sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  *list.List
+	items    map[interface{}]*list.Element
+	stats    LRUCacheStats
+}
+
+// LRUCacheStats the metrics of a LRU cache
+type LRUCacheStats struct {
+	Size      int    `json:"size"`
+	Capacity  int    `json:"capacity"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
+
+type lruEntry struct {
+	key      interface{}
+	value    interface{}
+	expireAt time.Time
+}
+
+// NewLRUCache creates a LRU cache, the TTL less than or equal to 0 means the entries never expire
+func NewLRUCache(capacity int, ttl time.Duration) *LRUCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &LRUCache{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  list.New(),
+		items:    make(map[interface{}]*list.Element, capacity),
+	}
+}
+
+// Get returns the value of the key, the second value is false if the key does not exist or has expired
+func (c *LRUCache) Get(key interface{}) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if c.ttl > 0 && time.Now().After(entry.expireAt) {
+		c.removeElement(element)
+		c.stats.Misses++
+		return nil, false
+	}
+	c.entries.MoveToFront(element)
+	c.stats.Hits++
+	return entry.value, true
+}
+
+// Put adds or replaces the value of the key, and evicts the least recently used entry if the cache is full
+func (c *LRUCache) Put(key, value interface{}) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	expireAt := time.Now().Add(c.ttl)
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expireAt = expireAt
+		c.entries.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.entries.PushFront(&lruEntry{key: key, value: value, expireAt: expireAt})
+	for c.entries.Len() > c.capacity {
+		c.removeElement(c.entries.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Delete removes the key from the cache
+func (c *LRUCache) Delete(key interface{}) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+// Purge removes all entries from the cache
+func (c *LRUCache) Purge() {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries.Init()
+	c.items = make(map[interface{}]*list.Element, c.capacity)
+}
+
+// Stats returns the metrics of the cache
+func (c *LRUCache) Stats() LRUCacheStats {
+	if c == nil {
+		return LRUCacheStats{}
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	stats := c.stats
+	stats.Size = c.entries.Len()
+	stats.Capacity = c.capacity
+	return stats
+}
+
+func (c *LRUCache) removeElement(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.items, element.Value.(*lruEntry).key)
+}
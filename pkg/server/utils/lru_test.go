@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test LRU cache", func() {
+	It("Test evict the least recently used entry", func() {
+		cache := NewLRUCache(2, 0)
+		cache.Put("a", 1)
+		cache.Put("b", 2)
+		_, ok := cache.Get("a")
+		Expect(ok).Should(BeTrue())
+		cache.Put("c", 3)
+		_, ok = cache.Get("b")
+		Expect(ok).Should(BeFalse())
+		value, ok := cache.Get("c")
+		Expect(ok).Should(BeTrue())
+		Expect(value).Should(Equal(3))
+		Expect(cache.Stats()).Should(Equal(LRUCacheStats{Size: 2, Capacity: 2, Hits: 2, Misses: 1, Evictions: 1}))
+
+		cache.Delete("c")
+		_, ok = cache.Get("c")
+		Expect(ok).Should(BeFalse())
+		cache.Purge()
+		Expect(cache.Stats().Size).Should(Equal(0))
+	})
+
+	It("Test the entries expire after the TTL", func() {
+		cache := NewLRUCache(10, time.Millisecond*50)
+		cache.Put("a", 1)
+		_, ok := cache.Get("a")
+		Expect(ok).Should(BeTrue())
+		time.Sleep(time.Millisecond * 100)
+		_, ok = cache.Get("a")
+		Expect(ok).Should(BeFalse())
+		Expect(cache.Stats().Size).Should(Equal(0))
+
+		var empty *LRUCache
+		empty.Put("a", 1)
+		_, ok = empty.Get("a")
+		Expect(ok).Should(BeFalse())
+	})
+})
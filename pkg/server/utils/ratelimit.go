@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter limits the requests of each key, such as the client IP, by the token bucket
+type RateLimiter struct {
+	limit    rate.Limit
+	burst    int
+	limiters *LRUCache
+}
+
+// NewRateLimiter creates a rate limiter that allows the qps requests per second and the burst requests at once for each key.
+// The limiters of the inactive keys are evicted, at most capacity keys are tracked.
+func NewRateLimiter(qps float64, burst, capacity int) *RateLimiter {
+	return &RateLimiter{
+		limit:    rate.Limit(qps),
+		burst:    burst,
+		limiters: NewLRUCache(capacity, 10*time.Minute),
+	}
+}
+
+// Allow reports whether the request of the key could happen now
+func (r *RateLimiter) Allow(key string) bool {
+	if value, ok := r.limiters.Get(key); ok {
+		return value.(*rate.Limiter).Allow()
+	}
+	limiter := rate.NewLimiter(r.limit, r.burst)
+	r.limiters.Put(key, limiter)
+	return limiter.Allow()
+}
@@ -0,0 +1,32 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test rate limiter", func() {
+	It("Test limit the requests of each key", func() {
+		limiter := NewRateLimiter(0.001, 2, 10)
+		Expect(limiter.Allow("10.0.0.1")).Should(BeTrue())
+		Expect(limiter.Allow("10.0.0.1")).Should(BeTrue())
+		Expect(limiter.Allow("10.0.0.1")).Should(BeFalse())
+		Expect(limiter.Allow("10.0.0.2")).Should(BeTrue())
+	})
+})
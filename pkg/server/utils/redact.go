@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces the secrets found by the redactor
+const RedactedValue = "******"
+
+// Redactor masks the secrets in the workflow logs, messages and step parameters.
+// The patterns are the regular expressions whose matches are masked, and the fields are the names
+// of the parameters whose values are masked, such as `password: xxx` or `"token": "xxx"` in the text.
+type Redactor struct {
+	patterns     []*regexp.Regexp
+	fields       map[string]bool
+	fieldPattern *regexp.Regexp
+}
+
+// NewRedactor creates a redactor with the regular expressions and the field names
+func NewRedactor(patterns, fields []string) (*Redactor, error) {
+	r := &Redactor{fields: make(map[string]bool, len(fields))}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	var quoted []string
+	for _, field := range fields {
+		if field == "" {
+			continue
+		}
+		r.fields[strings.ToLower(field)] = true
+		quoted = append(quoted, regexp.QuoteMeta(field))
+	}
+	if len(quoted) > 0 {
+		r.fieldPattern = regexp.MustCompile(`(?i)(["']?\b(?:` + strings.Join(quoted, "|") + `)\b["']?\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s,;&}]+)`)
+	}
+	return r, nil
+}
+
+// Redact masks the secrets in the text, it returns the text directly if the redactor is nil
+func (r *Redactor) Redact(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	for _, re := range r.patterns {
+		text = re.ReplaceAllString(text, RedactedValue)
+	}
+	if r.fieldPattern != nil {
+		// keep the quotes so that the JSON or CUE text is still valid after masking
+		text = r.fieldPattern.ReplaceAllStringFunc(text, func(match string) string {
+			sub := r.fieldPattern.FindStringSubmatch(match)
+			if quote := sub[2][0]; quote == '"' || quote == '\'' {
+				return sub[1] + string(quote) + RedactedValue + string(quote)
+			}
+			return sub[1] + RedactedValue
+		})
+	}
+	return text
+}
+
+// RedactField masks the whole value if the name is one of the fields, otherwise masks the secrets in the value.
+// The name could be a path like `properties.password`, only the last segment is compared.
+func (r *Redactor) RedactField(name, value string) string {
+	if r == nil {
+		return value
+	}
+	if r.fields[strings.ToLower(name[strings.LastIndex(name, ".")+1:])] {
+		return RedactedValue
+	}
+	return r.Redact(value)
+}
+
+// RedactMap masks the secrets in the values of the map in place
+func (r *Redactor) RedactMap(values map[string]string) {
+	if r == nil {
+		return
+	}
+	for k, v := range values {
+		values[k] = r.RedactField(k, v)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test redact utils", func() {
+	It("Test redact the secrets", func() {
+		_, err := NewRedactor([]string{"("}, nil)
+		Expect(err).ShouldNot(BeNil())
+
+		r, err := NewRedactor([]string{`ghp_[A-Za-z0-9]+`}, []string{"password", "token"})
+		Expect(err).Should(BeNil())
+		Expect(r.Redact("clone with ghp_abc123 done")).Should(Equal("clone with ****** done"))
+		Expect(r.Redact(`{"Password": "p@ss word", "user": "admin"}`)).Should(Equal(`{"Password": "******", "user": "admin"}`))
+		Expect(r.Redact("login token=abc&user=admin")).Should(Equal("login token=******&user=admin"))
+		Expect(r.Redact("the tokens are rotated")).Should(Equal("the tokens are rotated"))
+
+		Expect(r.RedactField("token", "abc")).Should(Equal(RedactedValue))
+		Expect(r.RedactField("properties.Token", "abc")).Should(Equal(RedactedValue))
+		Expect(r.RedactField("message", "use ghp_xyz")).Should(Equal("use ******"))
+		values := map[string]string{"password": "abc", "log": "password: abc"}
+		r.RedactMap(values)
+		Expect(values).Should(Equal(map[string]string{"password": RedactedValue, "log": "password: ******"}))
+
+		var empty *Redactor
+		Expect(empty.Redact("password: abc")).Should(Equal("password: abc"))
+	})
+})
@@ -0,0 +1,153 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SyncTracker tracks the resources queued and synced by a sync worker, so the drift of VelaUX from the cluster could be
+// reported. A resource is pending from it is queued until it is synced, the failed ones are pending until they are retried
+// successfully. The nil SyncTracker tracks nothing.
+type SyncTracker struct {
+	name string
+
+	mu          sync.Mutex
+	started     time.Time
+	lastSuccess time.Time
+	pending     map[string]time.Time
+	failures    map[string]*SyncFailure
+	errorCount  int64
+}
+
+// SyncFailure the failures of a resource since it is synced successfully last time
+type SyncFailure struct {
+	Resource  string
+	Count     int
+	Message   string
+	LastError time.Time
+}
+
+// SyncTrackerStatus the snapshot of the status of a sync worker
+type SyncTrackerStatus struct {
+	Name        string
+	Started     time.Time
+	LastSuccess time.Time
+	// Backlog the number of the resources pending to sync
+	Backlog int
+	// Lag how long the oldest pending resource has been waiting, it is 0 if nothing is pending
+	Lag time.Duration
+	// ErrorCount the number of the failures since the start
+	ErrorCount int64
+	// Failures the resources failing to sync, the most failed ones are listed first
+	Failures []SyncFailure
+}
+
+// NewSyncTracker creates the tracker of the sync worker
+func NewSyncTracker(name string) *SyncTracker {
+	return &SyncTracker{name: name, pending: map[string]time.Time{}, failures: map[string]*SyncFailure{}}
+}
+
+// Start records the worker is started, the workers run on the leader replica only
+func (t *SyncTracker) Start() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.started = time.Now()
+}
+
+// Queued records the resource is queued, the resource queued again before it is synced keeps its first queued time
+func (t *SyncTracker) Queued(resource string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.pending[resource]; !ok {
+		t.pending[resource] = time.Now()
+	}
+}
+
+// Succeeded records the resource is synced
+func (t *SyncTracker) Succeeded(resource string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pending, resource)
+	delete(t.failures, resource)
+	t.lastSuccess = time.Now()
+}
+
+// Failed records the resource failed to sync, it is still pending because it will be retried
+func (t *SyncTracker) Failed(resource string, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.pending[resource]; !ok {
+		t.pending[resource] = time.Now()
+	}
+	failure, ok := t.failures[resource]
+	if !ok {
+		failure = &SyncFailure{Resource: resource}
+		t.failures[resource] = failure
+	}
+	failure.Count++
+	failure.Message = err.Error()
+	failure.LastError = time.Now()
+	t.errorCount++
+}
+
+// Status returns the snapshot of the status
+func (t *SyncTracker) Status() SyncTrackerStatus {
+	if t == nil {
+		return SyncTrackerStatus{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status := SyncTrackerStatus{
+		Name:        t.name,
+		Started:     t.started,
+		LastSuccess: t.lastSuccess,
+		Backlog:     len(t.pending),
+		ErrorCount:  t.errorCount,
+		Failures:    make([]SyncFailure, 0, len(t.failures)),
+	}
+	now := time.Now()
+	for _, queued := range t.pending {
+		if lag := now.Sub(queued); lag > status.Lag {
+			status.Lag = lag
+		}
+	}
+	for _, failure := range t.failures {
+		status.Failures = append(status.Failures, *failure)
+	}
+	sort.Slice(status.Failures, func(i, j int) bool {
+		if status.Failures[i].Count != status.Failures[j].Count {
+			return status.Failures[i].Count > status.Failures[j].Count
+		}
+		return status.Failures[i].Resource < status.Failures[j].Resource
+	})
+	return status
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test sync tracker", func() {
+	It("Test the backlog, the lag and the failures", func() {
+		tracker := NewSyncTracker("test")
+		tracker.Start()
+		status := tracker.Status()
+		Expect(status.Started.IsZero()).Should(BeFalse())
+		Expect(status.LastSuccess.IsZero()).Should(BeTrue())
+		Expect(status.Backlog).Should(Equal(0))
+		Expect(status.Lag).Should(Equal(time.Duration(0)))
+
+		tracker.Queued("default/app-1")
+		time.Sleep(20 * time.Millisecond)
+		tracker.Queued("default/app-2")
+		tracker.Queued("default/app-1")
+		status = tracker.Status()
+		Expect(status.Backlog).Should(Equal(2))
+		Expect(status.Lag).Should(BeNumerically(">=", 20*time.Millisecond))
+
+		By("the failed resources are pending until they are synced")
+		tracker.Failed("default/app-1", errors.New("the cluster is unreachable"))
+		tracker.Failed("default/app-1", errors.New("the cluster is unreachable"))
+		tracker.Failed("default/app-2", errors.New("the namespace is terminating"))
+		tracker.Succeeded("default/app-2")
+		status = tracker.Status()
+		Expect(status.Backlog).Should(Equal(1))
+		Expect(status.ErrorCount).Should(Equal(int64(3)))
+		Expect(status.LastSuccess.IsZero()).Should(BeFalse())
+		Expect(len(status.Failures)).Should(Equal(1))
+		Expect(status.Failures[0].Resource).Should(Equal("default/app-1"))
+		Expect(status.Failures[0].Count).Should(Equal(2))
+		Expect(status.Failures[0].Message).Should(Equal("the cluster is unreachable"))
+
+		tracker.Succeeded("default/app-1")
+		status = tracker.Status()
+		Expect(status.Backlog).Should(Equal(0))
+		Expect(status.Lag).Should(Equal(time.Duration(0)))
+		Expect(status.Failures).Should(BeEmpty())
+		Expect(status.ErrorCount).Should(Equal(int64(3)))
+	})
+
+	It("Test the nil tracker", func() {
+		var tracker *SyncTracker
+		tracker.Start()
+		tracker.Queued("default/app")
+		tracker.Failed("default/app", errors.New("failed"))
+		tracker.Succeeded("default/app")
+		Expect(tracker.Status().Backlog).Should(Equal(0))
+	})
+})
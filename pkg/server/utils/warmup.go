@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/klog/v2"
+)
+
+// warmUpReportInterval how often the progress of the warm-up is logged
+var warmUpReportInterval = 10 * time.Second
+
+// WarmUp throttles the resync of the existing objects after the restart, so the API server is not flooded by
+// the requests of all objects at once. The throttling stops once the objects listed at the start are synced.
+// The nil WarmUp does not throttle anything.
+type WarmUp struct {
+	name    string
+	limiter *rate.Limiter
+
+	mu         sync.Mutex
+	total      int
+	synced     int
+	start      time.Time
+	lastReport time.Time
+}
+
+// NewWarmUp creates the warm-up that syncs at most qps objects per second, 0 disables the throttling
+func NewWarmUp(name string, qps float64) *WarmUp {
+	limit := rate.Inf
+	if qps > 0 {
+		limit = rate.Limit(qps)
+	}
+	now := time.Now()
+	return &WarmUp{name: name, limiter: rate.NewLimiter(limit, 1), total: -1, start: now, lastReport: now}
+}
+
+// SetTotal sets the number of the objects to sync in the warm-up
+func (w *WarmUp) SetTotal(total int) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.total = total
+	klog.Infof("warm-up resync of the %s started, %d to sync", w.name, total)
+	w.finishIfDone()
+}
+
+// Wait blocks until the next object could be synced, it returns at once after the warm-up finished
+func (w *WarmUp) Wait(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	limiter := w.limiter
+	w.mu.Unlock()
+	if limiter == nil {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+// Done records an object is synced and reports the progress periodically
+func (w *WarmUp) Done() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.limiter == nil {
+		return
+	}
+	w.synced++
+	if w.finishIfDone() {
+		return
+	}
+	if time.Since(w.lastReport) >= warmUpReportInterval {
+		w.lastReport = time.Now()
+		if w.total >= 0 {
+			klog.Infof("warm-up resync of the %s: %d/%d synced", w.name, w.synced, w.total)
+		} else {
+			klog.Infof("warm-up resync of the %s: %d synced", w.name, w.synced)
+		}
+	}
+}
+
+// Finished reports whether all the objects of the warm-up are synced
+func (w *WarmUp) Finished() bool {
+	if w == nil {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.limiter == nil
+}
+
+// finishIfDone drops the limiter once the synced objects reach the total, the caller must hold the lock
+func (w *WarmUp) finishIfDone() bool {
+	if w.limiter == nil {
+		return true
+	}
+	if w.total < 0 || w.synced < w.total {
+		return false
+	}
+	w.limiter = nil
+	klog.Infof("warm-up resync of the %s finished, %d synced in %s", w.name, w.synced, time.Since(w.start).Round(time.Second))
+	return true
+}
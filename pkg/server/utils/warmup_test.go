@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test warm-up", func() {
+	It("Test throttle the sync until the objects at the start are synced", func() {
+		ctx := context.TODO()
+		warmUp := NewWarmUp("test objects", 20)
+		warmUp.SetTotal(3)
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			Expect(warmUp.Finished()).Should(BeFalse())
+			Expect(warmUp.Wait(ctx)).Should(BeNil())
+			warmUp.Done()
+		}
+		Expect(time.Since(start)).Should(BeNumerically(">=", 90*time.Millisecond))
+		Expect(warmUp.Finished()).Should(BeTrue())
+
+		By("the sync is not throttled after the warm-up")
+		start = time.Now()
+		for i := 0; i < 100; i++ {
+			Expect(warmUp.Wait(ctx)).Should(BeNil())
+		}
+		Expect(time.Since(start)).Should(BeNumerically("<", 50*time.Millisecond))
+	})
+
+	It("Test the warm-up without any object", func() {
+		warmUp := NewWarmUp("test objects", 1)
+		Expect(warmUp.Finished()).Should(BeFalse())
+		warmUp.SetTotal(0)
+		Expect(warmUp.Finished()).Should(BeTrue())
+
+		var nilWarmUp *WarmUp
+		Expect(nilWarmUp.Wait(context.TODO())).Should(BeNil())
+		nilWarmUp.Done()
+		Expect(nilWarmUp.Finished()).Should(BeTrue())
+	})
+})